@@ -1,28 +1,49 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"flag"
 	"fmt"
 	"io/fs"
 	"log/slog"
 	"os"
+	"os/signal"
 	"path/filepath"
+	"syscall"
 	"time"
 
 	"github.com/leengari/mini-rdbms/databases"
 	"github.com/leengari/mini-rdbms/internal/domain/transaction"
+	queryEngine "github.com/leengari/mini-rdbms/internal/engine"
 	"github.com/leengari/mini-rdbms/internal/infrastructure/logging"
 	"github.com/leengari/mini-rdbms/internal/network"
 	"github.com/leengari/mini-rdbms/internal/repl"
+	"github.com/leengari/mini-rdbms/internal/storage/convert"
 	"github.com/leengari/mini-rdbms/internal/storage/engine"
 	"github.com/leengari/mini-rdbms/internal/storage/manager"
+	"github.com/leengari/mini-rdbms/internal/storage/metadata"
 )
 
 func main() {
+	os.Exit(run())
+}
+
+// run starts JoyDB and returns the process exit code
+// Deferred cleanup (stopping the checkpointer, saving databases) runs before main exits.
+func run() int {
 	serverMode := flag.Bool("server", false, "Run in server mode")
 	port := flag.Int("port", 4444, "Port to listen on")
+	pgPort := flag.Int("pg-port", 0, "Port for the PostgreSQL wire protocol in server mode (0 disables it)")
+	storage := flag.String("storage", metadata.StorageJSON, "Storage format for databases: json or page")
+	convertDB := flag.String("convert", "", "Convert the named database to the -storage format and exit")
+	checkpointInterval := flag.Duration("checkpoint-interval", 30*time.Second, "How often to write dirty tables to disk (0 disables timed checkpoints)")
+	checkpointRows := flag.Int("checkpoint-rows", 10000, "Write a database's dirty tables once this many row changes are logged (0 disables)")
+	idleTxTimeout := flag.Duration("idle-tx-timeout", queryEngine.IdleTransactionTimeout, "Roll back a transaction left idle this long between statements (0 disables)")
 	flag.Parse()
 
+	queryEngine.IdleTransactionTimeout = *idleTxTimeout
+
 	logger, closeFn := logging.SetupLogger()
 	defer closeFn()
 
@@ -36,11 +57,25 @@ func main() {
 	// Ensure database directory exists
 	if err := os.MkdirAll(basePath, 0755); err != nil {
 		slog.Error("failed to create databases directory", "error", err)
-		os.Exit(1)
+		return 1
 	}
 
-	// Create storage engine (currently JSON, can be swapped for binary)
-	storageEngine := engine.NewJSONEngine()
+	// Create storage engine for the chosen format
+	storageEngine, err := engine.New(*storage)
+	if err != nil {
+		slog.Error("invalid storage format", "error", err)
+		return 1
+	}
+
+	if *convertDB != "" {
+		if err := convert.Database(basePath, *convertDB, *storage); err != nil {
+			slog.Error("conversion failed", "database", *convertDB, "error", err)
+			fmt.Println("Conversion failed:", err)
+			return 1
+		}
+		fmt.Printf("Converted database '%s' to %s storage\n", *convertDB, *storage)
+		return 0
+	}
 
 	// Create Database Registry with storage engine
 	registry := manager.NewRegistry(basePath, storageEngine)
@@ -53,34 +88,103 @@ func main() {
 		registry.SaveAll(tx)
 	}()
 
+	// Flush dirty tables in the background; stopped before the final save above
+	registry.AddObserver(queryEngine.NewLoggingObserver())
+	if *checkpointInterval > 0 || *checkpointRows > 0 {
+		if err := registry.StartCheckpointer(manager.CheckpointConfig{
+			Interval:  *checkpointInterval,
+			DirtyRows: *checkpointRows,
+		}); err != nil {
+			slog.Error("failed to start checkpointer", "error", err)
+		}
+		defer registry.StopCheckpointer()
+	}
+
 	// Seed 'main' from embedded FS
-	if err := ensureDatabaseSeeded(basePath, databases.Content, "main"); err != nil {
+	seeded, err := ensureDatabaseSeeded(basePath, databases.Content, "main")
+	if err != nil {
 		slog.Error("Failed to seed main database", "error", err)
 	}
 
+	// The embedded seed is JSON; bring a fresh copy into the chosen format
+	if seeded && *storage != metadata.StorageJSON {
+		if err := convert.Database(basePath, "main", *storage); err != nil {
+			slog.Error("Failed to convert seeded main database", "error", err)
+		}
+	}
+
 	slog.Info("Application ready!", "base_path", basePath)
 
+	// Ctrl-C or SIGTERM stops the server or REPL so the deferred saves above run;
+	// a second signal kills the process
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
+
 	if *serverMode {
 		slog.Info("Starting Server mode...")
-		network.Start(*port, registry)
+		if err := runServers(ctx, *port, *pgPort, registry); err != nil {
+			slog.Error("server failed", "error", err)
+			fmt.Println("Server failed:", err)
+			return 1
+		}
 	} else {
 		slog.Info("Starting REPL mode...")
-		repl.Start(registry)
+		repl.Start(ctx, registry)
+	}
+	return 0
+}
+
+// runServers runs the JSON server and, if pgPort is set, the PostgreSQL server
+// until ctx is cancelled or one of them fails, which stops the other
+func runServers(ctx context.Context, port, pgPort int, registry *manager.Registry) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	servers := []func(context.Context) error{
+		func(ctx context.Context) error { return network.Start(ctx, port, registry) },
+	}
+	if pgPort > 0 {
+		servers = append(servers, func(ctx context.Context) error { return network.StartPG(ctx, pgPort, registry) })
+	}
+
+	results := make(chan error, len(servers))
+	for _, server := range servers {
+		go func() {
+			err := server(ctx)
+			if err != nil {
+				cancel()
+			}
+			results <- err
+		}()
+	}
+
+	var errs []error
+	for range servers {
+		if err := <-results; err != nil {
+			errs = append(errs, err)
+		}
 	}
+	return errors.Join(errs...)
 }
 
-func ensureDatabaseSeeded(basePath string, seedFS fs.FS, dbName string) error {
+// ensureDatabaseSeeded copies a database from the embedded filesystem if it does not exist
+// Reports whether it was copied.
+func ensureDatabaseSeeded(basePath string, seedFS fs.FS, dbName string) (bool, error) {
 	targetDir := filepath.Join(basePath, dbName)
 
 	// Check if target exists
 	if _, err := os.Stat(targetDir); !os.IsNotExist(err) {
-		return nil // Already exists
+		return false, nil // Already exists
 	}
 
 	slog.Info("Seeding database...", "database", dbName)
 
 	// Walk the embedded filesystem
-	return fs.WalkDir(seedFS, ".", func(path string, d fs.DirEntry, err error) error {
+	err := fs.WalkDir(seedFS, ".", func(path string, d fs.DirEntry, err error) error {
 		if err != nil {
 			return err
 		}
@@ -111,4 +215,5 @@ func ensureDatabaseSeeded(basePath string, seedFS fs.FS, dbName string) error {
 		// Write to disk
 		return os.WriteFile(targetPath, data, 0644)
 	})
+	return err == nil, err
 }
@@ -0,0 +1,401 @@
+package data
+
+import (
+	"sort"
+	"strings"
+)
+
+// btreeDegree is the minimum degree of a BTree: every node but the root holds
+// between btreeDegree-1 and 2*btreeDegree-1 entries
+const btreeDegree = 32
+
+// IndexEntry is one entry of an ordered index: a row's key and its row id
+type IndexEntry struct {
+	Key   []interface{}
+	RowID int64
+}
+
+// BTree is an in-memory B-tree of index entries, ordered by key and then row id
+// Row ids make every entry distinct, so equal keys (in non-unique indexes) are allowed.
+type BTree struct {
+	root *btreeNode
+	size int
+}
+
+type btreeNode struct {
+	entries  []IndexEntry
+	children []*btreeNode // nil for leaves
+}
+
+// NewBTree creates an empty B-tree
+func NewBTree() *BTree {
+	return &BTree{}
+}
+
+// Len returns the number of entries in the tree
+func (t *BTree) Len() int {
+	return t.size
+}
+
+// Insert adds an entry to the tree
+func (t *BTree) Insert(entry IndexEntry) {
+	if t.root == nil {
+		t.root = &btreeNode{}
+	}
+	if len(t.root.entries) == 2*btreeDegree-1 {
+		root := &btreeNode{children: []*btreeNode{t.root}}
+		root.splitChild(0)
+		t.root = root
+	}
+	t.root.insertNonFull(entry)
+	t.size++
+}
+
+// Delete removes an entry from the tree
+// Returns false if the tree does not hold it.
+func (t *BTree) Delete(entry IndexEntry) bool {
+	if t.root == nil {
+		return false
+	}
+	deleted := t.root.delete(entry)
+	if len(t.root.entries) == 0 && !t.root.leaf() {
+		t.root = t.root.children[0]
+	}
+	if deleted {
+		t.size--
+	}
+	return deleted
+}
+
+// Ascend calls fn for each entry in ascending order, starting at the first entry whose
+// key begins with a prefix at or after pivot (strictly after it when inclusive is false)
+// A nil pivot starts at the smallest entry. Iteration stops when fn returns false.
+func (t *BTree) Ascend(pivot []interface{}, inclusive bool, fn func(IndexEntry) bool) {
+	if t.root != nil {
+		t.root.ascend(pivot, inclusive, fn)
+	}
+}
+
+// Descend calls fn for each entry in descending order, starting at the last entry whose
+// key begins with a prefix at or before pivot (strictly before it when inclusive is false)
+// A nil pivot starts at the largest entry. Iteration stops when fn returns false.
+func (t *BTree) Descend(pivot []interface{}, inclusive bool, fn func(IndexEntry) bool) {
+	if t.root != nil {
+		t.root.descend(pivot, inclusive, fn)
+	}
+}
+
+func (n *btreeNode) leaf() bool {
+	return n.children == nil
+}
+
+// search returns the position of the first entry not less than entry
+func (n *btreeNode) search(entry IndexEntry) int {
+	return sort.Search(len(n.entries), func(i int) bool {
+		return compareEntries(n.entries[i], entry) >= 0
+	})
+}
+
+// splitChild splits the full child at i, moving its median entry up into n
+func (n *btreeNode) splitChild(i int) {
+	child := n.children[i]
+	median := child.entries[btreeDegree-1]
+
+	sibling := &btreeNode{entries: append([]IndexEntry(nil), child.entries[btreeDegree:]...)}
+	if !child.leaf() {
+		sibling.children = append([]*btreeNode(nil), child.children[btreeDegree:]...)
+		child.children = child.children[:btreeDegree:btreeDegree]
+	}
+	child.entries = child.entries[: btreeDegree-1 : btreeDegree-1]
+
+	n.entries = append(n.entries, IndexEntry{})
+	copy(n.entries[i+1:], n.entries[i:])
+	n.entries[i] = median
+
+	n.children = append(n.children, nil)
+	copy(n.children[i+2:], n.children[i+1:])
+	n.children[i+1] = sibling
+}
+
+// insertNonFull inserts an entry into the subtree of a node that is not full
+func (n *btreeNode) insertNonFull(entry IndexEntry) {
+	i := n.search(entry)
+	if n.leaf() {
+		n.entries = append(n.entries, IndexEntry{})
+		copy(n.entries[i+1:], n.entries[i:])
+		n.entries[i] = entry
+		return
+	}
+
+	if len(n.children[i].entries) == 2*btreeDegree-1 {
+		n.splitChild(i)
+		if compareEntries(entry, n.entries[i]) > 0 {
+			i++
+		}
+	}
+	n.children[i].insertNonFull(entry)
+}
+
+// delete removes an entry from the subtree of n
+// Every node it descends into is first topped up to at least btreeDegree entries,
+// so removing one never leaves a node underfull.
+func (n *btreeNode) delete(entry IndexEntry) bool {
+	i := n.search(entry)
+	found := i < len(n.entries) && compareEntries(n.entries[i], entry) == 0
+
+	if n.leaf() {
+		if !found {
+			return false
+		}
+		n.entries = append(n.entries[:i], n.entries[i+1:]...)
+		return true
+	}
+
+	if found {
+		switch {
+		case len(n.children[i].entries) >= btreeDegree:
+			pred := n.children[i].max()
+			n.entries[i] = pred
+			return n.children[i].delete(pred)
+		case len(n.children[i+1].entries) >= btreeDegree:
+			succ := n.children[i+1].min()
+			n.entries[i] = succ
+			return n.children[i+1].delete(succ)
+		default:
+			n.merge(i)
+			return n.children[i].delete(entry)
+		}
+	}
+
+	if len(n.children[i].entries) < btreeDegree {
+		switch {
+		case i > 0 && len(n.children[i-1].entries) >= btreeDegree:
+			n.borrowFromLeft(i)
+		case i < len(n.entries) && len(n.children[i+1].entries) >= btreeDegree:
+			n.borrowFromRight(i)
+		case i < len(n.entries):
+			n.merge(i)
+		default:
+			n.merge(i - 1)
+			i--
+		}
+	}
+	return n.children[i].delete(entry)
+}
+
+// merge joins child i, entry i and child i+1 into child i
+func (n *btreeNode) merge(i int) {
+	left, right := n.children[i], n.children[i+1]
+	left.entries = append(left.entries, n.entries[i])
+	left.entries = append(left.entries, right.entries...)
+	if !left.leaf() {
+		left.children = append(left.children, right.children...)
+	}
+
+	n.entries = append(n.entries[:i], n.entries[i+1:]...)
+	n.children = append(n.children[:i+1], n.children[i+2:]...)
+}
+
+// borrowFromLeft moves an entry from child i-1 through n into child i
+func (n *btreeNode) borrowFromLeft(i int) {
+	child, left := n.children[i], n.children[i-1]
+
+	child.entries = append(child.entries, IndexEntry{})
+	copy(child.entries[1:], child.entries)
+	child.entries[0] = n.entries[i-1]
+	n.entries[i-1] = left.entries[len(left.entries)-1]
+	left.entries = left.entries[:len(left.entries)-1]
+
+	if !child.leaf() {
+		child.children = append(child.children, nil)
+		copy(child.children[1:], child.children)
+		child.children[0] = left.children[len(left.children)-1]
+		left.children = left.children[:len(left.children)-1]
+	}
+}
+
+// borrowFromRight moves an entry from child i+1 through n into child i
+func (n *btreeNode) borrowFromRight(i int) {
+	child, right := n.children[i], n.children[i+1]
+
+	child.entries = append(child.entries, n.entries[i])
+	n.entries[i] = right.entries[0]
+	right.entries = append(right.entries[:0], right.entries[1:]...)
+
+	if !child.leaf() {
+		child.children = append(child.children, right.children[0])
+		right.children = append(right.children[:0], right.children[1:]...)
+	}
+}
+
+// min returns the smallest entry of the subtree
+func (n *btreeNode) min() IndexEntry {
+	for !n.leaf() {
+		n = n.children[0]
+	}
+	return n.entries[0]
+}
+
+// max returns the largest entry of the subtree
+func (n *btreeNode) max() IndexEntry {
+	for !n.leaf() {
+		n = n.children[len(n.children)-1]
+	}
+	return n.entries[len(n.entries)-1]
+}
+
+func (n *btreeNode) ascend(pivot []interface{}, inclusive bool, fn func(IndexEntry) bool) bool {
+	start := 0
+	if pivot != nil {
+		start = sort.Search(len(n.entries), func(i int) bool {
+			c := comparePrefix(n.entries[i].Key, pivot)
+			return c > 0 || (c == 0 && inclusive)
+		})
+	}
+
+	for i := start; i < len(n.entries); i++ {
+		if !n.leaf() && !n.children[i].ascend(pivot, inclusive, fn) {
+			return false
+		}
+		if !fn(n.entries[i]) {
+			return false
+		}
+	}
+	if !n.leaf() {
+		return n.children[len(n.entries)].ascend(pivot, inclusive, fn)
+	}
+	return true
+}
+
+func (n *btreeNode) descend(pivot []interface{}, inclusive bool, fn func(IndexEntry) bool) bool {
+	end := len(n.entries)
+	if pivot != nil {
+		end = sort.Search(len(n.entries), func(i int) bool {
+			c := comparePrefix(n.entries[i].Key, pivot)
+			return c > 0 || (c == 0 && !inclusive)
+		})
+	}
+
+	if !n.leaf() && !n.children[end].descend(pivot, inclusive, fn) {
+		return false
+	}
+	for i := end - 1; i >= 0; i-- {
+		if !fn(n.entries[i]) {
+			return false
+		}
+		if !n.leaf() && !n.children[i].descend(pivot, inclusive, fn) {
+			return false
+		}
+	}
+	return true
+}
+
+// compareEntries orders entries by key, then by row id
+func compareEntries(a, b IndexEntry) int {
+	if c := CompareKeys(a.Key, b.Key); c != 0 {
+		return c
+	}
+	switch {
+	case a.RowID < b.RowID:
+		return -1
+	case a.RowID > b.RowID:
+		return 1
+	}
+	return 0
+}
+
+// comparePrefix compares the leading values of key with prefix
+func comparePrefix(key, prefix []interface{}) int {
+	if len(key) > len(prefix) {
+		key = key[:len(prefix)]
+	}
+	return CompareKeys(key, prefix)
+}
+
+// CompareKeys compares two index keys column by column
+// Returns -1, 0 or 1. A key that is a prefix of the other sorts first.
+func CompareKeys(a, b []interface{}) int {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		if c := CompareKeyValues(a[i], b[i]); c != 0 {
+			return c
+		}
+	}
+	switch {
+	case len(a) < len(b):
+		return -1
+	case len(a) > len(b):
+		return 1
+	}
+	return 0
+}
+
+// CompareKeyValues compares two column values the way ORDER BY sorts them
+// Numbers compare numerically whatever their Go type (JSON loads INTs as float64),
+// strings lexically and false before true. NULL sorts after every value, and values
+// of different kinds are ordered numbers, strings, booleans.
+func CompareKeyValues(a, b interface{}) int {
+	ka, kb := keyKind(a), keyKind(b)
+	if ka != kb {
+		if ka < kb {
+			return -1
+		}
+		return 1
+	}
+
+	switch ka {
+	case kindNumber:
+		fa, fb := keyNumber(a), keyNumber(b)
+		switch {
+		case fa < fb:
+			return -1
+		case fa > fb:
+			return 1
+		}
+	case kindString:
+		return strings.Compare(a.(string), b.(string))
+	case kindBool:
+		ba, bb := a.(bool), b.(bool)
+		switch {
+		case !ba && bb:
+			return -1
+		case ba && !bb:
+			return 1
+		}
+	}
+	return 0
+}
+
+// Kinds of key values, in their sort order
+const (
+	kindNumber = iota
+	kindString
+	kindBool
+	kindOther
+	kindNull
+)
+
+func keyKind(v interface{}) int {
+	switch v.(type) {
+	case nil:
+		return kindNull
+	case int, int64, float64:
+		return kindNumber
+	case string:
+		return kindString
+	case bool:
+		return kindBool
+	}
+	return kindOther
+}
+
+func keyNumber(v interface{}) float64 {
+	switch n := v.(type) {
+	case int:
+		return float64(n)
+	case int64:
+		return float64(n)
+	case float64:
+		return n
+	}
+	return 0
+}
@@ -0,0 +1,139 @@
+package data
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"testing"
+)
+
+// collect returns the row ids an ordered index scan yields
+func collect(idx *OrderedIndex, r IndexRange, descending bool) string {
+	var ids []int64
+	idx.Scan(r, descending, func(id int64) bool {
+		ids = append(ids, id)
+		return true
+	})
+	return fmt.Sprint(ids)
+}
+
+func TestBTree(t *testing.T) {
+	tree := NewBTree()
+	rng := rand.New(rand.NewSource(1))
+
+	// Enough entries for several levels, with many duplicate keys
+	var want []IndexEntry
+	for id := int64(1); id <= 5000; id++ {
+		e := IndexEntry{Key: []interface{}{int64(rng.Intn(500))}, RowID: id}
+		tree.Insert(e)
+		want = append(want, e)
+	}
+
+	check := func() {
+		t.Helper()
+		sort.Slice(want, func(i, j int) bool { return compareEntries(want[i], want[j]) < 0 })
+		if tree.Len() != len(want) {
+			t.Fatalf("Expected %d entries, got %d", len(want), tree.Len())
+		}
+		i := 0
+		tree.Ascend(nil, true, func(e IndexEntry) bool {
+			if compareEntries(e, want[i]) != 0 {
+				t.Fatalf("Entry %d: expected %v, got %v", i, want[i], e)
+			}
+			i++
+			return true
+		})
+		tree.Descend(nil, true, func(e IndexEntry) bool {
+			i--
+			if compareEntries(e, want[i]) != 0 {
+				t.Fatalf("Entry %d descending: expected %v, got %v", i, want[i], e)
+			}
+			return true
+		})
+	}
+	check()
+
+	// Delete a random half, including entries that are not there
+	rng.Shuffle(len(want), func(i, j int) { want[i], want[j] = want[j], want[i] })
+	for _, e := range want[:2500] {
+		if !tree.Delete(e) {
+			t.Fatalf("Failed to delete %v", e)
+		}
+	}
+	if tree.Delete(want[0]) {
+		t.Error("Expected deleting a missing entry to fail")
+	}
+	want = want[2500:]
+	check()
+
+	for _, e := range want {
+		tree.Delete(e)
+	}
+	want = nil
+	check()
+}
+
+func TestCompareKeyValues(t *testing.T) {
+	ordered := []interface{}{int64(-3), 1.5, 2, "a", "b", false, true, nil}
+	for i := range ordered {
+		for j := range ordered {
+			want := 0
+			if i < j {
+				want = -1
+			} else if i > j {
+				want = 1
+			}
+			if got := CompareKeyValues(ordered[i], ordered[j]); got != want {
+				t.Errorf("Compare(%v, %v): expected %d, got %d", ordered[i], ordered[j], want, got)
+			}
+		}
+	}
+	if CompareKeyValues(int64(2), 2.0) != 0 {
+		t.Error("Expected INT and FLOAT keys of equal value to compare equal")
+	}
+}
+
+func TestOrderedIndexScan(t *testing.T) {
+	idx := NewOrderedIndex("by_city_age", []string{"city", "age"}, false)
+	rows := []struct {
+		city string
+		age  interface{}
+	}{
+		{"Oslo", 30}, {"Lima", 25}, {"Oslo", 25}, {"Oslo", nil}, {"Oslo", 41}, {"Lima", 30}, {"Oslo", 30},
+	}
+	for i, r := range rows {
+		row := NewRow(map[string]interface{}{"city": r.city, "age": r.age})
+		row.ID = int64(i + 1)
+		idx.Insert(row)
+	}
+
+	oslo := []interface{}{"Oslo"}
+	tests := []struct {
+		name       string
+		r          IndexRange
+		descending bool
+		want       string
+	}{
+		{"Everything", IndexRange{}, false, "[2 6 3 1 7 5 4]"},
+		{"Everything descending", IndexRange{}, true, "[4 5 1 7 3 6 2]"},
+		{"Prefix", IndexRange{Equal: oslo}, false, "[3 1 7 5 4]"},
+		{"Prefix descending", IndexRange{Equal: oslo}, true, "[4 5 1 7 3]"},
+		{"Low bound", IndexRange{Equal: oslo, Low: &Bound{Value: 30, Inclusive: false}}, false, "[5]"},
+		{"Closed range", IndexRange{Equal: oslo, Low: &Bound{Value: 25, Inclusive: true}, High: &Bound{Value: 30.0, Inclusive: true}}, false, "[3 1 7]"},
+		{"High bound descending", IndexRange{Equal: oslo, High: &Bound{Value: 41, Inclusive: false}}, true, "[1 7 3]"},
+		{"Low bound descending", IndexRange{Equal: oslo, Low: &Bound{Value: 26, Inclusive: true}}, true, "[5 1 7]"},
+		{"Leading range", IndexRange{Low: &Bound{Value: "M", Inclusive: true}}, false, "[3 1 7 5 4]"},
+		{"Empty", IndexRange{Equal: []interface{}{"Rome"}}, false, "[]"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := collect(idx, tt.r, tt.descending); got != tt.want {
+				t.Errorf("Expected %s, got %s", tt.want, got)
+			}
+		})
+	}
+
+	if got := fmt.Sprint(idx.Lookup([]interface{}{"Oslo", 30})); got != "[1 7]" {
+		t.Errorf("Expected lookup [1 7], got %s", got)
+	}
+}
@@ -3,6 +3,6 @@ package data
 // Index is an in-memory index on a single column
 type Index struct {
 	Column string
-	Data   map[interface{}][]int // value → row positions
+	Data   map[interface{}][]int64 // value → row ids
 	Unique bool
 }
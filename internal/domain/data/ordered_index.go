@@ -0,0 +1,188 @@
+package data
+
+// OrderedIndex is a named index over one or more columns, kept in key order in a BTree
+// Unlike Index it holds every row (keys may contain NULLs), so it can serve range
+// scans and return rows already sorted by its columns.
+type OrderedIndex struct {
+	Name    string
+	Columns []string // Key columns, most significant first
+	Unique  bool     // No two rows share a key without NULLs
+	Tree    *BTree
+}
+
+// Bound is one end of a range over an index column
+type Bound struct {
+	Value     interface{}
+	Inclusive bool
+}
+
+// IndexRange selects the entries of an ordered index whose leading columns equal Equal
+// and whose next column lies between Low and High (either may be nil for an open end)
+// Range bounds never match NULL.
+type IndexRange struct {
+	Equal []interface{}
+	Low   *Bound
+	High  *Bound
+}
+
+// NewOrderedIndex creates an empty ordered index
+func NewOrderedIndex(name string, columns []string, unique bool) *OrderedIndex {
+	return &OrderedIndex{
+		Name:    name,
+		Columns: columns,
+		Unique:  unique,
+		Tree:    NewBTree(),
+	}
+}
+
+// Key returns the row's values of the index columns (missing columns are NULL)
+func (idx *OrderedIndex) Key(row Row) []interface{} {
+	key := make([]interface{}, len(idx.Columns))
+	for i, col := range idx.Columns {
+		key[i] = row.Data[col]
+	}
+	return key
+}
+
+// Insert adds the row's entry
+func (idx *OrderedIndex) Insert(row Row) {
+	idx.Tree.Insert(IndexEntry{Key: idx.Key(row), RowID: row.ID})
+}
+
+// Delete removes the row's entry
+func (idx *OrderedIndex) Delete(row Row) {
+	idx.Tree.Delete(IndexEntry{Key: idx.Key(row), RowID: row.ID})
+}
+
+// Reset removes every entry
+func (idx *OrderedIndex) Reset() {
+	idx.Tree = NewBTree()
+}
+
+// Lookup returns the ids of the rows whose key equals key
+func (idx *OrderedIndex) Lookup(key []interface{}) []int64 {
+	var ids []int64
+	idx.Tree.Ascend(key, true, func(e IndexEntry) bool {
+		if comparePrefix(e.Key, key) != 0 {
+			return false
+		}
+		ids = append(ids, e.RowID)
+		return true
+	})
+	return ids
+}
+
+// HasNull reports whether a key contains a NULL (such keys never conflict in a unique index)
+func HasNull(key []interface{}) bool {
+	for _, v := range key {
+		if v == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// CompareRows compares two rows by their index keys
+func (idx *OrderedIndex) CompareRows(a, b Row) int {
+	return CompareKeys(idx.Key(a), idx.Key(b))
+}
+
+// Scan calls fn with the id of each row in the range, in key order (descending keys
+// when descending is set); stops when fn returns false
+// Rows with equal keys always come in ascending row id order, as a stable sort of
+// the table's rows would leave them.
+func (idx *OrderedIndex) Scan(r IndexRange, descending bool, fn func(id int64) bool) {
+	col := len(r.Equal) // position of the range column in the key
+	bounded := r.Low != nil || r.High != nil
+
+	// inRange reports whether an entry belongs to the range (skip) and whether the scan
+	// has moved past it (stop)
+	inRange := func(e IndexEntry) (ok, stop bool) {
+		if comparePrefix(e.Key, r.Equal) != 0 {
+			return false, true
+		}
+		if !bounded || col >= len(e.Key) {
+			return true, false
+		}
+		v := e.Key[col]
+		if v == nil {
+			// NULLs sort last: the end of an ascending scan, skipped by a descending one
+			return false, !descending
+		}
+		if r.High != nil && !descending {
+			if c := CompareKeyValues(v, r.High.Value); c > 0 || (c == 0 && !r.High.Inclusive) {
+				return false, true
+			}
+		}
+		if r.Low != nil && descending {
+			if c := CompareKeyValues(v, r.Low.Value); c < 0 || (c == 0 && !r.Low.Inclusive) {
+				return false, true
+			}
+		}
+		return true, false
+	}
+
+	if !descending {
+		pivot, inclusive := r.Equal, true
+		if r.Low != nil {
+			pivot, inclusive = appendKey(r.Equal, r.Low.Value), r.Low.Inclusive
+		}
+		if len(pivot) == 0 {
+			pivot = nil
+		}
+		idx.Tree.Ascend(pivot, inclusive, func(e IndexEntry) bool {
+			ok, stop := inRange(e)
+			if stop {
+				return false
+			}
+			return !ok || fn(e.RowID)
+		})
+		return
+	}
+
+	pivot, inclusive := r.Equal, true
+	if r.High != nil {
+		pivot, inclusive = appendKey(r.Equal, r.High.Value), r.High.Inclusive
+	}
+	if len(pivot) == 0 {
+		pivot = nil
+	}
+
+	// Entries with equal keys arrive in descending row id order; emit each run reversed
+	var run []IndexEntry
+	flush := func() bool {
+		for i := len(run) - 1; i >= 0; i-- {
+			if !fn(run[i].RowID) {
+				return false
+			}
+		}
+		run = run[:0]
+		return true
+	}
+	stopped := false
+	idx.Tree.Descend(pivot, inclusive, func(e IndexEntry) bool {
+		ok, stop := inRange(e)
+		if stop {
+			return false
+		}
+		if !ok {
+			return true
+		}
+		if len(run) > 0 && CompareKeys(run[0].Key, e.Key) != 0 && !flush() {
+			stopped = true
+			return false
+		}
+		run = append(run, e)
+		return true
+	})
+	if !stopped {
+		flush()
+	}
+}
+
+// appendKey returns key followed by v, without modifying key
+func appendKey(key []interface{}, v interface{}) []interface{} {
+	out := make([]interface{}, len(key), len(key)+1)
+	copy(out, key)
+	return append(out, v)
+}
@@ -9,6 +9,9 @@ import (
 // Key = column name, Value = cell value
 type Row struct {
 	Data map[string]interface{}
+	// ID is the row's hidden row id, assigned by its table on insert and never reused.
+	// Zero for rows that are not stored in a table (query results, new rows).
+	ID int64
 	// mu is a placeholder for future row-level locking implementation
 	// Currently unused but reserved for fine-grained concurrency control
 	mu *sync.Mutex
@@ -30,6 +33,7 @@ func (r Row) Copy() Row {
 	}
 	return Row{
 		Data: copy,
+		ID:   r.ID,
 		mu:   &sync.Mutex{},
 	}
 }
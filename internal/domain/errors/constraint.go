@@ -14,7 +14,7 @@ type ConstraintError struct {
 	Constraint string      // "unique", "primary_key", "not_null", "type_mismatch", etc.
 	Reason     string      // human-readable explanation (optional)
 	RowIndex   int         // row number (0-based) where violation occurred (-1 if unknown)
-	Rows       []int       // for unique violations: all conflicting row positions
+	Rows       []int64     // for unique violations: ids of all conflicting rows
 }
 
 func (e *ConstraintError) Error() string {
@@ -42,7 +42,7 @@ func (e *ConstraintError) Error() string {
 }
 
 // NewUniqueViolation creates a unique constraint violation error
-func NewUniqueViolation(table, column string, value interface{}, rows []int) *ConstraintError {
+func NewUniqueViolation(table, column string, value interface{}, rows []int64) *ConstraintError {
 	return &ConstraintError{
 		Table:      table,
 		Column:     column,
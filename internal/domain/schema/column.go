@@ -1,5 +1,10 @@
 package schema
 
+import (
+	"fmt"
+	"strings"
+)
+
 type ColumnType string
 
 const (
@@ -13,10 +18,55 @@ const (
 )
 
 type Column struct {
-	Name          string     `json:"name"`
-	Type          ColumnType `json:"type"`
-	PrimaryKey    bool       `json:"primary_key"`
-	Unique        bool       `json:"unique"`
-	NotNull       bool       `json:"not_null"`
-	AutoIncrement bool       `json:"auto_increment,omitempty"`
+	Name          string      `json:"name"`
+	Type          ColumnType  `json:"type"`
+	PrimaryKey    bool        `json:"primary_key"`
+	Unique        bool        `json:"unique"`
+	NotNull       bool        `json:"not_null"`
+	AutoIncrement bool        `json:"auto_increment,omitempty"`
+	Default       interface{} `json:"default,omitempty"` // value used when an INSERT omits the column (nil = no default)
+}
+
+// StoredValue converts a value to the form a column of the given type holds it in:
+// INT values as int64 and FLOAT values as float64. Index keys are stored values, so
+// lookups must convert their keys the same way. Values that do not fit the type
+// (e.g. 2.5 for INT) are returned unchanged.
+func StoredValue(colType ColumnType, value interface{}) interface{} {
+	switch colType {
+	case ColumnTypeInt:
+		if n, ok := normalizeToInt64(value); ok {
+			return n
+		}
+	case ColumnTypeFloat:
+		switch v := value.(type) {
+		case int:
+			return float64(v)
+		case int64:
+			return float64(v)
+		}
+	}
+	return value
+}
+
+// ParseColumnType converts a SQL type name (case-insensitive) into a ColumnType
+// Accepts the canonical names plus the common aliases INTEGER and BOOLEAN
+func ParseColumnType(name string) (ColumnType, error) {
+	switch strings.ToUpper(name) {
+	case "INT", "INTEGER":
+		return ColumnTypeInt, nil
+	case "FLOAT":
+		return ColumnTypeFloat, nil
+	case "TEXT":
+		return ColumnTypeText, nil
+	case "BOOL", "BOOLEAN":
+		return ColumnTypeBool, nil
+	case "DATE":
+		return ColumnTypeDate, nil
+	case "TIME":
+		return ColumnTypeTime, nil
+	case "EMAIL":
+		return ColumnTypeEmail, nil
+	default:
+		return "", fmt.Errorf("unknown column type: %s", name)
+	}
 }
@@ -1,5 +1,7 @@
 package schema
 
+import "github.com/leengari/mini-rdbms/internal/domain/transaction"
+
 // Database represents a single database on disk
 // (a directory containing table subdirectories)
 type Database struct {
@@ -7,3 +9,23 @@ type Database struct {
 	Path   string // filesystem path to database directory
 	Tables map[string]*Table
 }
+
+// RevertChanges undoes a transaction's recorded changes across all affected tables
+// Changes to tables that no longer exist are ignored.
+func (db *Database) RevertChanges(changes []transaction.Change) error {
+	byTable := make(map[string][]transaction.Change)
+	for _, change := range changes {
+		byTable[change.Table] = append(byTable[change.Table], change)
+	}
+
+	for name, tableChanges := range byTable {
+		table, ok := db.Tables[name]
+		if !ok {
+			continue
+		}
+		if err := table.RevertChanges(tableChanges); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -11,15 +11,20 @@ import (
 )
 
 // Table represents a database table with its schema, data, and indexes
+// Rows are kept in ascending order of their hidden row ids (data.Row.ID); indexes
+// and recorded changes refer to rows by id, so positions are free to shift.
 type Table struct {
-	mu           sync.RWMutex
-	Name         string
-	Path         string // filesystem path to table directory
-	Schema       *TableSchema
-	Rows         []data.Row
-	Indexes      map[string]*data.Index
-	LastInsertID int64
-	Dirty        bool // tracks if table has unsaved changes
+	mu             sync.RWMutex
+	Name           string
+	Path           string // filesystem path to table directory
+	Schema         *TableSchema
+	Rows           []data.Row
+	Indexes        map[string]*data.Index
+	OrderedIndexes map[string]*data.OrderedIndex // CREATE INDEX indexes, by index name
+	LastInsertID   int64
+	NextRowID      int64 // row id the next inserted row receives (see data.Row.ID)
+	LastLSN        int64 // LSN of the last write-ahead log record reflected in Rows
+	Dirty          bool  // tracks if table has unsaved changes
 }
 
 // MarkDirty marks the table as having unsaved changes
@@ -82,8 +87,8 @@ func (t *Table) Insert(mutRow data.Row, tx *transaction.Transaction) error {
 		// Generate next ID
 		nextID := t.LastInsertID + 1
 
-		// Allow user to override auto-increment
-		if val, exists := row.Data[autoIncCol.Name]; exists {
+		// Allow user to override auto-increment (an explicit NULL still generates the next ID)
+		if val, exists := row.Data[autoIncCol.Name]; exists && val != nil {
 			userID, ok := normalizeToInt64(val)
 			if !ok {
 				return &errors.ConstraintError{
@@ -107,14 +112,13 @@ func (t *Table) Insert(mutRow data.Row, tx *transaction.Transaction) error {
 			nextID = userID
 		}
 
-		// Set the auto-increment value
+		// Set the auto-increment value (the sequence only advances once the row is accepted)
 		row.Data[autoIncCol.Name] = nextID
-		t.LastInsertID = nextID
 	} else {
 		// If PK is not auto-increment, it must be provided
 		pkCol := t.Schema.GetPrimaryKeyColumn()
 		if pkCol != nil {
-			if _, exists := row.Data[pkCol.Name]; !exists {
+			if val, exists := row.Data[pkCol.Name]; !exists || val == nil {
 				return &errors.ConstraintError{
 					Table:      t.Name,
 					Column:     pkCol.Name,
@@ -125,15 +129,26 @@ func (t *Table) Insert(mutRow data.Row, tx *transaction.Transaction) error {
 		}
 	}
 
-	// 2. Validate the row (types, NOT NULL, etc.)
+	// Fill in DEFAULT values for omitted columns
+	for _, col := range t.Schema.Columns {
+		if col.Default == nil {
+			continue
+		}
+		if _, exists := row.Data[col.Name]; !exists {
+			row.Data[col.Name] = col.Default
+		}
+	}
+
+	// 2. Validate the row (types, NOT NULL, etc.) and convert numbers to the column types
 	if err := t.validateRow(row); err != nil {
 		return err
 	}
 
 	// 3. Check unique/primary constraints using current indexes
+	// NULLs are never equal to each other, so any number of them may share a unique column
 	for colName, idx := range t.Indexes {
 		val, exists := row.Data[colName]
-		if !exists {
+		if !exists || val == nil {
 			continue
 		}
 
@@ -150,17 +165,32 @@ func (t *Table) Insert(mutRow data.Row, tx *transaction.Transaction) error {
 		}
 	}
 
-	// 4. Get new position (BEFORE append)
-	newRowPos := len(t.Rows)
+	// Composite keys of unique ordered indexes (CREATE UNIQUE INDEX)
+	if err := t.checkOrderedUniqueUnsafe(row, nil); err != nil {
+		return err
+	}
+
+	// 4. Assign the row id (always the largest, so the row goes last)
+	row.ID = t.newRowIDUnsafe()
+	prevInsertID := t.LastInsertID
+	if autoIncCol != nil {
+		t.LastInsertID, _ = normalizeToInt64(row.Data[autoIncCol.Name])
+	}
 
 	// 5. Everything passed → safe to append
 	t.Rows = append(t.Rows, row)
 
 	// 6. Update all indexes
-	for colName, idx := range t.Indexes {
-		if val, exists := row.Data[colName]; exists {
-			idx.Data[val] = append(idx.Data[val], newRowPos)
-		}
+	t.indexRowUnsafe(row)
+
+	if tx != nil {
+		tx.Record(transaction.Change{
+			Type:         transaction.ChangeTypeInsert,
+			Table:        t.Name,
+			RowID:        row.ID,
+			Data:         row.Copy().Data,
+			PrevInsertID: prevInsertID,
+		})
 	}
 
 	// 7. Mark table as dirty (has unsaved changes)
@@ -184,7 +214,8 @@ func (t *Table) SelectAll(tx *transaction.Transaction) []data.Row {
 }
 
 // Select returns rows that match the given predicate
-func (t *Table) Select(predicate func(data.Row) bool, tx *transaction.Transaction) []data.Row {
+// Stops at the first error the predicate returns.
+func (t *Table) Select(predicate func(data.Row) (bool, error), tx *transaction.Transaction) ([]data.Row, error) {
 	t.RLock()
 	defer t.RUnlock()
 
@@ -194,11 +225,15 @@ func (t *Table) Select(predicate func(data.Row) bool, tx *transaction.Transactio
 
 	var result []data.Row
 	for _, row := range t.Rows {
-		if predicate(row) {
+		match, err := predicate(row)
+		if err != nil {
+			return nil, err
+		}
+		if match {
 			result = append(result, row)
 		}
 	}
-	return result
+	return result, nil
 }
 
 // SelectByIndex retrieves a row using a unique index
@@ -216,22 +251,46 @@ func (t *Table) SelectByIndex(colName string, value interface{}, tx *transaction
 		return data.Row{}, false
 	}
 
-	// Convert value to int64 if it's an integer type for comparison
-	if intVal, ok := value.(int); ok {
-		value = int64(intVal)
+	ids, found := idx.Data[StoredValue(t.Schema.Columns[t.columnIndexUnsafe(colName)].Type, value)]
+	if !found || len(ids) == 0 {
+		return data.Row{}, false
 	}
 
-	positions, found := idx.Data[value]
-	if !found || len(positions) == 0 {
+	pos, found := t.RowPositionUnsafe(ids[0])
+	if !found {
 		return data.Row{}, false
 	}
+	return t.Rows[pos], true
+}
+
+// Assignment computes a column's new value from the current values of the row being updated
+type Assignment func(row data.Row) (interface{}, error)
 
-	return t.Rows[positions[0]], true
+// Assignments maps each column an UPDATE sets to the computation of its new value
+type Assignments map[string]Assignment
+
+// ConstantAssignments sets every column in updates to its fixed value
+func ConstantAssignments(updates data.Row) Assignments {
+	assignments := make(Assignments, len(updates.Data))
+	for colName, value := range updates.Data {
+		value := value
+		assignments[colName] = func(data.Row) (interface{}, error) {
+			return value, nil
+		}
+	}
+	return assignments
 }
 
 // Update modifies rows that match the given predicate
 // Returns the number of rows updated
-func (t *Table) Update(predicate func(data.Row) bool, updates data.Row, tx *transaction.Transaction) (int, error) {
+func (t *Table) Update(predicate func(data.Row) (bool, error), updates data.Row, tx *transaction.Transaction) (int, error) {
+	return t.UpdateWith(predicate, ConstantAssignments(updates), tx)
+}
+
+// UpdateWith modifies rows that match the given predicate, computing each new value
+// from the row's current values (e.g. SET visits = visits + 1)
+// Returns the number of rows updated
+func (t *Table) UpdateWith(predicate func(data.Row) (bool, error), assignments Assignments, tx *transaction.Transaction) (int, error) {
 	t.Lock()
 	defer t.Unlock()
 
@@ -239,36 +298,99 @@ func (t *Table) Update(predicate func(data.Row) bool, updates data.Row, tx *tran
 		slog.Debug("Update operation", "table", t.Name, "tx_id", tx.ID)
 	}
 
-	count := 0
-	for i, row := range t.Rows {
-		if predicate(row) {
-			// Validate each update value against schema
-			for colName, newValue := range updates.Data {
-				// Find column in schema
-				var col *Column
-				for i := range t.Schema.Columns {
-					if t.Schema.Columns[i].Name == colName {
-						col = &t.Schema.Columns[i]
-						break
-					}
-				}
-				if col == nil {
-					return 0, &errors.ColumnNotFoundError{
-						TableName:  t.Name,
-						ColumnName: colName,
-					}
-				}
+	return t.updateUnsafe(nil, predicate, assignments, tx)
+}
+
+// updateUnsafe updates the rows at positions (all rows if nil) that match the predicate
+// Every new value is computed from the rows as they were before the statement, and all
+// of them are validated before any row changes, so an error leaves the table unchanged.
+// IMPORTANT: Must be called while holding write lock!
+func (t *Table) updateUnsafe(positions []int, predicate func(data.Row) (bool, error), assignments Assignments, tx *transaction.Transaction) (int, error) {
+	// Validate every target column against the schema before touching any row
+	for colName := range assignments {
+		if t.columnIndexUnsafe(colName) < 0 {
+			return 0, &errors.ColumnNotFoundError{
+				TableName:  t.Name,
+				ColumnName: colName,
+			}
+		}
+	}
+
+	if positions == nil {
+		positions = allPositions(len(t.Rows))
+	}
+
+	// Compute the new values of every matching row
+	type rowUpdate struct {
+		pos    int
+		values map[string]interface{}
+	}
+	var pending []rowUpdate
+	for _, i := range positions {
+		row := t.Rows[i]
+		match, err := predicate(row)
+		if err != nil {
+			return 0, err
+		}
+		if !match {
+			continue
+		}
 
-				// Type validation would go here if needed
-				t.Rows[i].Data[colName] = newValue
+		values := make(map[string]interface{}, len(assignments))
+		for colName, assign := range assignments {
+			newValue, err := assign(row)
+			if err != nil {
+				return 0, fmt.Errorf("column '%s': %w", colName, err)
+			}
+			col := t.Schema.Columns[t.columnIndexUnsafe(colName)]
+			if newValue == nil && (col.NotNull || col.PrimaryKey) {
+				return 0, &errors.ConstraintError{
+					Table:      t.Name,
+					Column:     colName,
+					Constraint: "not_null",
+					Reason:     "cannot set NOT NULL column to NULL",
+				}
 			}
-			count++
+			values[colName] = StoredValue(col.Type, newValue)
 		}
+		pending = append(pending, rowUpdate{pos: i, values: values})
 	}
 
+	// Check unique constraints against the rows as they will be after the statement
+	updated := make([]data.Row, len(pending))
+	for n, update := range pending {
+		updated[n] = t.Rows[update.pos].Copy()
+		for colName, newValue := range update.values {
+			updated[n].Data[colName] = newValue
+		}
+	}
+	if err := t.checkUniqueUpdateUnsafe(updated, assignments); err != nil {
+		return 0, err
+	}
+
+	for _, update := range pending {
+		i := update.pos
+		oldData := t.Rows[i].Copy().Data
+
+		t.unindexRowUnsafe(t.Rows[i])
+		for colName, newValue := range update.values {
+			t.Rows[i].Data[colName] = newValue
+		}
+		t.indexRowUnsafe(t.Rows[i])
+
+		if tx != nil {
+			tx.Record(transaction.Change{
+				Type:    transaction.ChangeTypeUpdate,
+				Table:   t.Name,
+				RowID:   t.Rows[i].ID,
+				Data:    t.Rows[i].Copy().Data,
+				OldData: oldData,
+			})
+		}
+	}
+
+	count := len(pending)
 	if count > 0 {
-		// Rebuild indexes after update
-		t.rebuildIndexesUnsafe()
 		t.MarkDirtyUnsafe()
 	}
 
@@ -277,7 +399,7 @@ func (t *Table) Update(predicate func(data.Row) bool, updates data.Row, tx *tran
 
 // Delete removes rows that match the given predicate
 // Returns the number of rows deleted
-func (t *Table) Delete(predicate func(data.Row) bool, tx *transaction.Transaction) (int, error) {
+func (t *Table) Delete(predicate func(data.Row) (bool, error), tx *transaction.Transaction) (int, error) {
 	t.Lock()
 	defer t.Unlock()
 
@@ -285,11 +407,43 @@ func (t *Table) Delete(predicate func(data.Row) bool, tx *transaction.Transactio
 		slog.Debug("Delete operation", "table", t.Name, "tx_id", tx.ID)
 	}
 
+	return t.deleteUnsafe(nil, predicate, tx)
+}
+
+// deleteUnsafe deletes the rows at positions (all rows if nil) that match the predicate
+// The predicate is evaluated for every candidate before any row is removed, so an
+// error leaves the table unchanged.
+// IMPORTANT: Must be called while holding write lock!
+func (t *Table) deleteUnsafe(positions []int, predicate func(data.Row) (bool, error), tx *transaction.Transaction) (int, error) {
+	if positions == nil {
+		positions = allPositions(len(t.Rows))
+	}
+
+	matched := make(map[int]bool, len(positions))
+	for _, pos := range positions {
+		match, err := predicate(t.Rows[pos])
+		if err != nil {
+			return 0, err
+		}
+		if match {
+			matched[pos] = true
+		}
+	}
+
 	var newRows []data.Row
 	deleted := 0
 
-	for _, row := range t.Rows {
-		if predicate(row) {
+	for i, row := range t.Rows {
+		if matched[i] {
+			t.unindexRowUnsafe(row)
+			if tx != nil {
+				tx.Record(transaction.Change{
+					Type:    transaction.ChangeTypeDelete,
+					Table:   t.Name,
+					RowID:   row.ID,
+					OldData: row.Copy().Data,
+				})
+			}
 			deleted++
 		} else {
 			newRows = append(newRows, row)
@@ -298,7 +452,6 @@ func (t *Table) Delete(predicate func(data.Row) bool, tx *transaction.Transactio
 
 	if deleted > 0 {
 		t.Rows = newRows
-		t.rebuildIndexesUnsafe()
 		t.MarkDirtyUnsafe()
 	}
 
@@ -306,13 +459,15 @@ func (t *Table) Delete(predicate func(data.Row) bool, tx *transaction.Transactio
 }
 
 // validateRow validates a row against the table schema
+// Numbers are converted in place to the form their columns store (see StoredValue),
+// so the row must not be shared with the caller.
 // Must be called while holding a lock
 func (t *Table) validateRow(row data.Row) error {
 	for _, col := range t.Schema.Columns {
 		value, exists := row.Data[col.Name]
 
-		// Check NOT NULL constraint
-		if col.NotNull && !exists {
+		// Check NOT NULL constraint (an explicit NULL counts as missing)
+		if col.NotNull && (!exists || value == nil) {
 			return &errors.ConstraintError{
 				Table:      t.Name,
 				Column:     col.Name,
@@ -321,12 +476,14 @@ func (t *Table) validateRow(row data.Row) error {
 			}
 		}
 
-		// Skip type validation if value doesn't exist
-		if !exists {
+		// Skip type validation if value doesn't exist or is NULL
+		if !exists || value == nil {
 			continue
 		}
 
 		// Type validation
+		value = StoredValue(col.Type, value)
+		row.Data[col.Name] = value
 		if err := t.validateType(col.Name, value, col.Type); err != nil {
 			return err
 		}
@@ -359,24 +516,6 @@ func (t *Table) validateType(colName string, value interface{}, expectedType Col
 	return nil
 }
 
-// rebuildIndexesUnsafe rebuilds all indexes
-// IMPORTANT: Must be called while holding write lock!
-func (t *Table) rebuildIndexesUnsafe() {
-	// Clear existing indexes
-	for _, idx := range t.Indexes {
-		idx.Data = make(map[interface{}][]int)
-	}
-
-	// Rebuild from current rows
-	for rowPos, row := range t.Rows {
-		for colName, idx := range t.Indexes {
-			if val, exists := row.Data[colName]; exists {
-				idx.Data[val] = append(idx.Data[val], rowPos)
-			}
-		}
-	}
-}
-
 // normalizeToInt64 converts various numeric types to int64
 // Returns the int64 value and true if successful, 0 and false otherwise
 func normalizeToInt64(val interface{}) (int64, bool) {
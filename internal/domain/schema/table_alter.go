@@ -0,0 +1,149 @@
+package schema
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/leengari/mini-rdbms/internal/domain/data"
+	"github.com/leengari/mini-rdbms/internal/domain/errors"
+)
+
+// AddColumn appends a new column to the table schema and backfills existing rows
+// Existing rows receive the column's Default value (if any), converted like an inserted value.
+// A NOT NULL or PRIMARY KEY column without a default cannot be added to a non-empty table,
+// and a UNIQUE or PRIMARY KEY column with a default only to a table with at most one row.
+func (t *Table) AddColumn(col Column) error {
+	t.Lock()
+	defer t.Unlock()
+
+	if t.columnIndexUnsafe(col.Name) >= 0 {
+		return fmt.Errorf("column '%s' already exists in table '%s'", col.Name, t.Name)
+	}
+
+	// The value every existing row receives, in the form the column stores
+	value := StoredValue(col.Type, col.Default)
+
+	if len(t.Rows) > 0 {
+		if (col.NotNull || col.PrimaryKey) && value == nil {
+			return &errors.ConstraintError{
+				Table:      t.Name,
+				Column:     col.Name,
+				Constraint: "not_null",
+				Reason:     "cannot add NOT NULL column without DEFAULT to a non-empty table",
+				RowIndex:   -1,
+			}
+		}
+		if col.AutoIncrement {
+			return fmt.Errorf("column '%s': AUTO_INCREMENT can only be added to an empty table", col.Name)
+		}
+		// Every existing row would receive the same default value (NULLs never conflict)
+		if (col.Unique || col.PrimaryKey) && value != nil && len(t.Rows) > 1 {
+			return errors.NewUniqueViolation(t.Name, col.Name, value, nil)
+		}
+	}
+
+	if value != nil {
+		if err := t.validateType(col.Name, value, col.Type); err != nil {
+			return err
+		}
+		for i := range t.Rows {
+			t.Rows[i].Data[col.Name] = value
+		}
+	}
+
+	t.Schema.Columns = append(t.Schema.Columns, col)
+
+	if col.PrimaryKey || col.Unique {
+		idx := &data.Index{
+			Column: col.Name,
+			Data:   make(map[interface{}][]int64),
+			Unique: true,
+		}
+		if value != nil {
+			for _, row := range t.Rows {
+				idx.Data[value] = append(idx.Data[value], row.ID)
+			}
+		}
+		t.Indexes[col.Name] = idx
+	}
+
+	t.MarkDirtyUnsafe()
+	return nil
+}
+
+// DropColumn removes a column from the schema, every row, and its index (if any)
+// Ordered indexes that include the column are dropped with it.
+func (t *Table) DropColumn(name string) error {
+	t.Lock()
+	defer t.Unlock()
+
+	pos := t.columnIndexUnsafe(name)
+	if pos < 0 {
+		return &errors.ColumnNotFoundError{TableName: t.Name, ColumnName: name}
+	}
+	if len(t.Schema.Columns) == 1 {
+		return fmt.Errorf("cannot drop column '%s': table '%s' must have at least one column", name, t.Name)
+	}
+
+	t.Schema.Columns = append(t.Schema.Columns[:pos:pos], t.Schema.Columns[pos+1:]...)
+	for i := range t.Rows {
+		delete(t.Rows[i].Data, name)
+	}
+	delete(t.Indexes, name)
+	for idxName, idx := range t.OrderedIndexes {
+		if slices.Contains(idx.Columns, name) {
+			delete(t.OrderedIndexes, idxName)
+		}
+	}
+
+	t.MarkDirtyUnsafe()
+	return nil
+}
+
+// RenameColumn renames a column in the schema, every row, and the indexes that use it
+func (t *Table) RenameColumn(oldName, newName string) error {
+	t.Lock()
+	defer t.Unlock()
+
+	pos := t.columnIndexUnsafe(oldName)
+	if pos < 0 {
+		return &errors.ColumnNotFoundError{TableName: t.Name, ColumnName: oldName}
+	}
+	if t.columnIndexUnsafe(newName) >= 0 {
+		return fmt.Errorf("column '%s' already exists in table '%s'", newName, t.Name)
+	}
+
+	t.Schema.Columns[pos].Name = newName
+	for i := range t.Rows {
+		if val, exists := t.Rows[i].Data[oldName]; exists {
+			t.Rows[i].Data[newName] = val
+			delete(t.Rows[i].Data, oldName)
+		}
+	}
+	if idx, exists := t.Indexes[oldName]; exists {
+		idx.Column = newName
+		t.Indexes[newName] = idx
+		delete(t.Indexes, oldName)
+	}
+	for _, idx := range t.OrderedIndexes {
+		for i, col := range idx.Columns {
+			if col == oldName {
+				idx.Columns[i] = newName
+			}
+		}
+	}
+
+	t.MarkDirtyUnsafe()
+	return nil
+}
+
+// columnIndexUnsafe returns the position of the named column in the schema, or -1
+// Must be called while holding a lock
+func (t *Table) columnIndexUnsafe(name string) int {
+	for i, col := range t.Schema.Columns {
+		if col.Name == name {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,139 @@
+package schema
+
+import (
+	"fmt"
+
+	"github.com/leengari/mini-rdbms/internal/domain/data"
+	"github.com/leengari/mini-rdbms/internal/domain/transaction"
+)
+
+// ApplyChanges re-applies recorded changes to the table in order
+// Used to replay the write-ahead log over a loaded snapshot. Rows are written
+// exactly as recorded, under their recorded row ids (no constraint checks).
+func (t *Table) ApplyChanges(changes []transaction.Change) error {
+	t.Lock()
+	defer t.Unlock()
+
+	autoIncCol := t.autoIncrementColumnUnsafe()
+	for i, change := range changes {
+		if err := t.applyChangeUnsafe(change, autoIncCol); err != nil {
+			return fmt.Errorf("change %d: %w", i, err)
+		}
+	}
+
+	if len(changes) > 0 {
+		t.MarkDirtyUnsafe()
+	}
+	return nil
+}
+
+// applyChangeUnsafe applies one recorded change, keeping the indexes up to date
+// Recorded values are converted to the column types first: the log is JSON, so INT
+// values come back as float64, and indexes are keyed by stored values.
+// IMPORTANT: Must be called while holding write lock!
+func (t *Table) applyChangeUnsafe(change transaction.Change, autoIncCol string) error {
+	switch change.Type {
+	case transaction.ChangeTypeInsert:
+		if change.RowID <= 0 {
+			return fmt.Errorf("insert into %s has no row id", t.Name)
+		}
+		row := t.storedRowUnsafe(change.Data)
+		row.ID = change.RowID
+		if err := t.placeRowUnsafe(row); err != nil {
+			return err
+		}
+
+		if autoIncCol != "" {
+			if id, ok := normalizeToInt64(row.Data[autoIncCol]); ok && id > t.LastInsertID {
+				t.LastInsertID = id
+			}
+		}
+
+	case transaction.ChangeTypeUpdate:
+		pos, found := t.RowPositionUnsafe(change.RowID)
+		if !found {
+			return fmt.Errorf("update of %s row id %d: no such row", t.Name, change.RowID)
+		}
+		t.replaceRowUnsafe(pos, t.storedRowUnsafe(change.Data).Data)
+
+	case transaction.ChangeTypeDelete:
+		pos, found := t.RowPositionUnsafe(change.RowID)
+		if !found {
+			return fmt.Errorf("delete of %s row id %d: no such row", t.Name, change.RowID)
+		}
+		t.removeRowUnsafe(pos)
+
+	default:
+		return fmt.Errorf("unknown change type %q", change.Type)
+	}
+	return nil
+}
+
+// storedRowUnsafe builds a row from recorded values, converting each to the form its
+// column stores (see StoredValue)
+// Must be called while holding a lock
+func (t *Table) storedRowUnsafe(values map[string]interface{}) data.Row {
+	row := data.NewRow(values).Copy()
+	for _, col := range t.Schema.Columns {
+		if value, exists := row.Data[col.Name]; exists && value != nil {
+			row.Data[col.Name] = StoredValue(col.Type, value)
+		}
+	}
+	return row
+}
+
+// RevertChanges undoes recorded changes to the table, newest first
+// Restores the previous rows (under their original row ids), LastInsertID and
+// indexes. Used by ROLLBACK.
+func (t *Table) RevertChanges(changes []transaction.Change) error {
+	t.Lock()
+	defer t.Unlock()
+
+	for i := len(changes) - 1; i >= 0; i-- {
+		change := changes[i]
+
+		switch change.Type {
+		case transaction.ChangeTypeInsert:
+			pos, found := t.RowPositionUnsafe(change.RowID)
+			if !found {
+				return fmt.Errorf("change %d: cannot undo insert into %s of row id %d", i, t.Name, change.RowID)
+			}
+			t.removeRowUnsafe(pos)
+			t.LastInsertID = change.PrevInsertID
+
+		case transaction.ChangeTypeUpdate:
+			pos, found := t.RowPositionUnsafe(change.RowID)
+			if !found {
+				return fmt.Errorf("change %d: cannot undo update of %s row id %d", i, t.Name, change.RowID)
+			}
+			t.replaceRowUnsafe(pos, change.OldData)
+
+		case transaction.ChangeTypeDelete:
+			row := data.NewRow(change.OldData).Copy()
+			row.ID = change.RowID
+			if err := t.placeRowUnsafe(row); err != nil {
+				return fmt.Errorf("change %d: cannot undo delete: %w", i, err)
+			}
+
+		default:
+			return fmt.Errorf("change %d: unknown change type %q", i, change.Type)
+		}
+	}
+
+	if len(changes) > 0 {
+		t.MarkDirtyUnsafe()
+	}
+
+	return nil
+}
+
+// autoIncrementColumnUnsafe returns the name of the auto-increment primary key column, or ""
+// Must be called while holding a lock
+func (t *Table) autoIncrementColumnUnsafe() string {
+	for _, col := range t.Schema.Columns {
+		if col.AutoIncrement && col.PrimaryKey {
+			return col.Name
+		}
+	}
+	return ""
+}
@@ -0,0 +1,185 @@
+package schema
+
+import (
+	"log/slog"
+	"sort"
+
+	"github.com/leengari/mini-rdbms/internal/domain/data"
+	"github.com/leengari/mini-rdbms/internal/domain/errors"
+	"github.com/leengari/mini-rdbms/internal/domain/transaction"
+)
+
+// HasIndex reports whether the column has an index
+func (t *Table) HasIndex(colName string) bool {
+	t.RLock()
+	defer t.RUnlock()
+
+	_, ok := t.Indexes[colName]
+	return ok
+}
+
+// IndexLookup selects the keys read from an index: those equal to any of Values,
+// or, when InRange is set, every key it accepts (a range scan over the index keys)
+type IndexLookup struct {
+	Values  []interface{}
+	InRange func(key interface{}) bool
+}
+
+// SelectIndexed returns the rows whose indexed column matches the lookup and that match the predicate
+// Rows come straight from the column's index; a nil predicate accepts every indexed match.
+// If the column is no longer indexed, all rows are scanned instead.
+func (t *Table) SelectIndexed(colName string, lookup IndexLookup, predicate func(data.Row) (bool, error), tx *transaction.Transaction) ([]data.Row, error) {
+	t.RLock()
+	defer t.RUnlock()
+
+	if tx != nil {
+		slog.Debug("SelectIndexed operation", "table", t.Name, "column", colName, "tx_id", tx.ID)
+	}
+
+	return t.selectPositionsUnsafe(t.indexPositionsUnsafe(colName, lookup), predicate)
+}
+
+// UpdateIndexed updates the rows whose indexed column matches the lookup and that match the predicate
+// New values are computed as in UpdateWith.
+// Returns the number of rows updated
+func (t *Table) UpdateIndexed(colName string, lookup IndexLookup, predicate func(data.Row) (bool, error), assignments Assignments, tx *transaction.Transaction) (int, error) {
+	t.Lock()
+	defer t.Unlock()
+
+	if tx != nil {
+		slog.Debug("UpdateIndexed operation", "table", t.Name, "column", colName, "tx_id", tx.ID)
+	}
+
+	return t.updateUnsafe(t.indexPositionsUnsafe(colName, lookup), predicate, assignments, tx)
+}
+
+// DeleteIndexed deletes the rows whose indexed column matches the lookup and that match the predicate
+// Returns the number of rows deleted
+func (t *Table) DeleteIndexed(colName string, lookup IndexLookup, predicate func(data.Row) (bool, error), tx *transaction.Transaction) (int, error) {
+	t.Lock()
+	defer t.Unlock()
+
+	if tx != nil {
+		slog.Debug("DeleteIndexed operation", "table", t.Name, "column", colName, "tx_id", tx.ID)
+	}
+
+	return t.deleteUnsafe(t.indexPositionsUnsafe(colName, lookup), predicate, tx)
+}
+
+// selectPositionsUnsafe returns the rows at positions that match the predicate (all of them if nil)
+// Stops at the first error the predicate returns.
+// IMPORTANT: Must be called while holding a lock!
+func (t *Table) selectPositionsUnsafe(positions []int, predicate func(data.Row) (bool, error)) ([]data.Row, error) {
+	var result []data.Row
+	for _, pos := range positions {
+		row := t.Rows[pos]
+		if predicate != nil {
+			match, err := predicate(row)
+			if err != nil {
+				return nil, err
+			}
+			if !match {
+				continue
+			}
+		}
+		result = append(result, row)
+	}
+	return result, nil
+}
+
+// indexPositionsUnsafe returns the ascending row positions whose column matches the lookup
+// The index yields row ids, which are mapped to their current positions.
+// Falls back to every position when the column has no index.
+// IMPORTANT: Must be called while holding a lock!
+func (t *Table) indexPositionsUnsafe(colName string, lookup IndexLookup) []int {
+	idx, ok := t.Indexes[colName]
+	if !ok {
+		return allPositions(len(t.Rows))
+	}
+
+	seen := make(map[int64]bool)
+	positions := []int{}
+	add := func(ids []int64) {
+		for _, id := range ids {
+			if seen[id] {
+				continue
+			}
+			seen[id] = true
+			if pos, found := t.RowPositionUnsafe(id); found {
+				positions = append(positions, pos)
+			}
+		}
+	}
+
+	if lookup.InRange != nil {
+		for key, ids := range idx.Data {
+			if lookup.InRange(key) {
+				add(ids)
+			}
+		}
+	} else {
+		colType := t.Schema.Columns[t.columnIndexUnsafe(colName)].Type
+		for _, value := range lookup.Values {
+			add(idx.Data[StoredValue(colType, value)])
+		}
+	}
+
+	// Keep storage order, like a sequential scan
+	sort.Ints(positions)
+	return positions
+}
+
+// allPositions returns the positions 0..n-1
+func allPositions(n int) []int {
+	positions := make([]int, n)
+	for i := range positions {
+		positions[i] = i
+	}
+	return positions
+}
+
+// checkUniqueUpdateUnsafe checks the rows an UPDATE is about to write, holding their new
+// values, against the table's unique indexes and unique ordered indexes
+// The table is checked as it will be after the statement: a value may move from one
+// updated row to another, but it must not be held by a row the statement leaves alone
+// or by two updated rows. Hash indexes are only checked for assigned columns, and NULLs
+// never conflict.
+// IMPORTANT: Must be called while holding a lock!
+func (t *Table) checkUniqueUpdateUnsafe(rows []data.Row, assignments Assignments) error {
+	updating := make(map[int64]bool, len(rows))
+	for _, row := range rows {
+		updating[row.ID] = true
+	}
+
+	for colName, idx := range t.Indexes {
+		if _, assigned := assignments[colName]; !assigned || !idx.Unique {
+			continue
+		}
+
+		seen := make(map[interface{}]int64, len(rows))
+		for _, row := range rows {
+			val := row.Data[colName]
+			if val == nil {
+				continue
+			}
+			if other, dup := seen[val]; dup {
+				return errors.NewUniqueViolation(t.Name, colName, val, []int64{other, row.ID})
+			}
+			seen[val] = row.ID
+
+			for _, id := range idx.Data[val] {
+				if !updating[id] {
+					return errors.NewUniqueViolation(t.Name, colName, val, []int64{id, row.ID})
+				}
+			}
+		}
+	}
+
+	// Composite keys of unique ordered indexes (CREATE UNIQUE INDEX)
+	for _, row := range rows {
+		if err := t.checkOrderedUniqueUnsafe(row, updating); err != nil {
+			return err
+		}
+	}
+	return t.checkOrderedUniqueAmongUnsafe(rows)
+}
@@ -0,0 +1,276 @@
+package schema
+
+import (
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+
+	"github.com/leengari/mini-rdbms/internal/domain/data"
+	"github.com/leengari/mini-rdbms/internal/domain/errors"
+	"github.com/leengari/mini-rdbms/internal/domain/transaction"
+)
+
+// CreateIndex adds an ordered index over the given columns and fills it from the table's rows
+// A unique index is refused if two rows already share a key without NULLs.
+func (t *Table) CreateIndex(name string, columns []string, unique bool) error {
+	t.Lock()
+	defer t.Unlock()
+
+	if _, exists := t.OrderedIndexes[name]; exists {
+		return fmt.Errorf("index '%s' already exists on table '%s'", name, t.Name)
+	}
+	if len(columns) == 0 {
+		return fmt.Errorf("index '%s' must have at least one column", name)
+	}
+	seen := make(map[string]bool, len(columns))
+	for _, col := range columns {
+		if t.columnIndexUnsafe(col) < 0 {
+			return &errors.ColumnNotFoundError{TableName: t.Name, ColumnName: col}
+		}
+		if seen[col] {
+			return fmt.Errorf("column '%s' appears more than once in index '%s'", col, name)
+		}
+		seen[col] = true
+	}
+
+	idx := data.NewOrderedIndex(name, append([]string(nil), columns...), unique)
+	if err := t.fillOrderedIndexUnsafe(idx); err != nil {
+		return err
+	}
+
+	if t.OrderedIndexes == nil {
+		t.OrderedIndexes = make(map[string]*data.OrderedIndex)
+	}
+	t.OrderedIndexes[name] = idx
+	t.MarkDirtyUnsafe()
+	return nil
+}
+
+// DropIndex removes an ordered index
+func (t *Table) DropIndex(name string) error {
+	t.Lock()
+	defer t.Unlock()
+
+	if _, exists := t.OrderedIndexes[name]; !exists {
+		return fmt.Errorf("index '%s' does not exist on table '%s'", name, t.Name)
+	}
+	delete(t.OrderedIndexes, name)
+	t.MarkDirtyUnsafe()
+	return nil
+}
+
+// OrderedIndex returns the ordered index with the given name
+func (t *Table) OrderedIndex(name string) (*data.OrderedIndex, bool) {
+	t.RLock()
+	defer t.RUnlock()
+
+	idx, ok := t.OrderedIndexes[name]
+	return idx, ok
+}
+
+// OrderedIndexList returns the table's ordered indexes, sorted by name
+func (t *Table) OrderedIndexList() []*data.OrderedIndex {
+	t.RLock()
+	defer t.RUnlock()
+
+	indexes := make([]*data.OrderedIndex, 0, len(t.OrderedIndexes))
+	for _, idx := range t.OrderedIndexes {
+		indexes = append(indexes, idx)
+	}
+	sort.Slice(indexes, func(i, j int) bool { return indexes[i].Name < indexes[j].Name })
+	return indexes
+}
+
+// RebuildOrderedIndexesUnsafe refills every ordered index from the table's rows
+// IMPORTANT: Must be called while holding write lock!
+func (t *Table) RebuildOrderedIndexesUnsafe() error {
+	for _, idx := range t.OrderedIndexes {
+		if err := t.fillOrderedIndexUnsafe(idx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// fillOrderedIndexUnsafe replaces the entries of an ordered index with the table's rows
+// and checks the uniqueness of a unique index
+// IMPORTANT: Must be called while holding a lock!
+func (t *Table) fillOrderedIndexUnsafe(idx *data.OrderedIndex) error {
+	idx.Reset()
+	for _, row := range t.Rows {
+		idx.Insert(row)
+	}
+	if !idx.Unique {
+		return nil
+	}
+
+	// Duplicates are neighbours in key order
+	var err error
+	var prev data.IndexEntry
+	idx.Tree.Ascend(nil, true, func(e data.IndexEntry) bool {
+		if prev.Key != nil && !data.HasNull(e.Key) && data.CompareKeys(prev.Key, e.Key) == 0 {
+			err = orderedUniqueViolation(t.Name, idx, e.Key, idx.Lookup(e.Key))
+			return false
+		}
+		prev = e
+		return true
+	})
+	return err
+}
+
+// checkOrderedUniqueUnsafe checks a row about to be inserted or updated against the
+// table's unique ordered indexes. Rows whose ids are in skip (the rows an UPDATE is
+// writing, including this one) do not conflict. Keys containing NULL never conflict.
+// IMPORTANT: Must be called while holding a lock!
+func (t *Table) checkOrderedUniqueUnsafe(row data.Row, skip map[int64]bool) error {
+	for _, idx := range t.OrderedIndexes {
+		if !idx.Unique {
+			continue
+		}
+		key := idx.Key(row)
+		if data.HasNull(key) {
+			continue
+		}
+		var conflicts []int64
+		for _, id := range idx.Lookup(key) {
+			if !skip[id] {
+				conflicts = append(conflicts, id)
+			}
+		}
+		if len(conflicts) > 0 {
+			return orderedUniqueViolation(t.Name, idx, key, conflicts)
+		}
+	}
+	return nil
+}
+
+// checkOrderedUniqueAmongUnsafe checks that no two of the rows share a key of a unique
+// ordered index. Keys containing NULL never conflict.
+// IMPORTANT: Must be called while holding a lock!
+func (t *Table) checkOrderedUniqueAmongUnsafe(rows []data.Row) error {
+	for _, idx := range t.OrderedIndexes {
+		if !idx.Unique {
+			continue
+		}
+
+		// Duplicates are neighbours in key order
+		entries := make([]data.IndexEntry, 0, len(rows))
+		for _, row := range rows {
+			if key := idx.Key(row); !data.HasNull(key) {
+				entries = append(entries, data.IndexEntry{Key: key, RowID: row.ID})
+			}
+		}
+		sort.Slice(entries, func(i, j int) bool {
+			return data.CompareKeys(entries[i].Key, entries[j].Key) < 0
+		})
+		for i := 1; i < len(entries); i++ {
+			if data.CompareKeys(entries[i-1].Key, entries[i].Key) == 0 {
+				return orderedUniqueViolation(t.Name, idx, entries[i].Key, []int64{entries[i-1].RowID, entries[i].RowID})
+			}
+		}
+	}
+	return nil
+}
+
+// orderedUniqueViolation reports rows sharing a key of a unique ordered index
+func orderedUniqueViolation(table string, idx *data.OrderedIndex, key []interface{}, ids []int64) error {
+	var value interface{} = key
+	if len(key) == 1 {
+		value = key[0]
+	}
+	return errors.NewUniqueViolation(table, strings.Join(idx.Columns, ", "), value, ids)
+}
+
+// OrderedScan reads an ordered index: the entries in Range, in key order
+// (descending keys when Descending is set)
+type OrderedScan struct {
+	Index      string
+	Range      data.IndexRange
+	Descending bool
+	Ordered    bool // Return rows in index order (otherwise storage order, like a sequential scan)
+}
+
+// SelectOrdered returns the rows of an ordered index scan that match the predicate
+// A nil predicate accepts every row in the range. If the index no longer exists, all
+// rows are scanned in storage order instead.
+func (t *Table) SelectOrdered(scan OrderedScan, predicate func(data.Row) (bool, error), tx *transaction.Transaction) ([]data.Row, error) {
+	t.RLock()
+	defer t.RUnlock()
+
+	if tx != nil {
+		slog.Debug("SelectOrdered operation", "table", t.Name, "index", scan.Index, "tx_id", tx.ID)
+	}
+
+	positions := t.orderedPositionsUnsafe(scan)
+	if !scan.Ordered {
+		sort.Ints(positions)
+	}
+
+	return t.selectPositionsUnsafe(positions, predicate)
+}
+
+// UpdateOrdered updates the rows of an ordered index scan that match the predicate
+// New values are computed as in UpdateWith.
+// Returns the number of rows updated
+func (t *Table) UpdateOrdered(scan OrderedScan, predicate func(data.Row) (bool, error), assignments Assignments, tx *transaction.Transaction) (int, error) {
+	t.Lock()
+	defer t.Unlock()
+
+	if tx != nil {
+		slog.Debug("UpdateOrdered operation", "table", t.Name, "index", scan.Index, "tx_id", tx.ID)
+	}
+
+	positions := t.orderedPositionsUnsafe(scan)
+	sort.Ints(positions)
+	return t.updateUnsafe(positions, predicate, assignments, tx)
+}
+
+// DeleteOrdered deletes the rows of an ordered index scan that match the predicate
+// Returns the number of rows deleted
+func (t *Table) DeleteOrdered(scan OrderedScan, predicate func(data.Row) (bool, error), tx *transaction.Transaction) (int, error) {
+	t.Lock()
+	defer t.Unlock()
+
+	if tx != nil {
+		slog.Debug("DeleteOrdered operation", "table", t.Name, "index", scan.Index, "tx_id", tx.ID)
+	}
+
+	return t.deleteUnsafe(t.orderedPositionsUnsafe(scan), predicate, tx)
+}
+
+// SortByIndex sorts rows of the table into the order an ordered index scan returns them
+// Rows with equal keys keep their relative order. Rows are left as they are if the
+// index no longer exists.
+func (t *Table) SortByIndex(rows []data.Row, name string, descending bool) {
+	idx, ok := t.OrderedIndex(name)
+	if !ok {
+		return
+	}
+	sort.SliceStable(rows, func(i, j int) bool {
+		c := idx.CompareRows(rows[i], rows[j])
+		if descending {
+			return c > 0
+		}
+		return c < 0
+	})
+}
+
+// orderedPositionsUnsafe returns the row positions of an ordered index scan, in index order
+// Falls back to every position when the index does not exist.
+// IMPORTANT: Must be called while holding a lock!
+func (t *Table) orderedPositionsUnsafe(scan OrderedScan) []int {
+	idx, ok := t.OrderedIndexes[scan.Index]
+	if !ok {
+		return allPositions(len(t.Rows))
+	}
+
+	positions := []int{}
+	idx.Scan(scan.Range, scan.Descending, func(id int64) bool {
+		if pos, found := t.RowPositionUnsafe(id); found {
+			positions = append(positions, pos)
+		}
+		return true
+	})
+	return positions
+}
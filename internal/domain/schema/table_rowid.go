@@ -0,0 +1,152 @@
+package schema
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/leengari/mini-rdbms/internal/domain/data"
+)
+
+// RowIDColumn is the reserved name under which a row's hidden id is stored on disk
+// It cannot be used as a column name.
+const RowIDColumn = "_rowid"
+
+// InitRowIDs prepares the ids of rows loaded from disk
+// Rows stored without ids (written before row ids existed) are numbered 1..n in their
+// stored order. Otherwise every row must have a distinct id; rows are sorted by id and
+// NextRowID is raised above the largest one.
+func (t *Table) InitRowIDs() error {
+	t.Lock()
+	defer t.Unlock()
+
+	missing := 0
+	for _, row := range t.Rows {
+		if row.ID == 0 {
+			missing++
+		}
+	}
+	switch {
+	case missing == len(t.Rows):
+		for i := range t.Rows {
+			t.Rows[i].ID = int64(i + 1)
+		}
+	case missing > 0:
+		return fmt.Errorf("table %s: %d of %d rows have no row id", t.Name, missing, len(t.Rows))
+	default:
+		sort.SliceStable(t.Rows, func(a, b int) bool { return t.Rows[a].ID < t.Rows[b].ID })
+		for i := 1; i < len(t.Rows); i++ {
+			if t.Rows[i].ID == t.Rows[i-1].ID {
+				return fmt.Errorf("table %s: duplicate row id %d", t.Name, t.Rows[i].ID)
+			}
+		}
+	}
+
+	if n := len(t.Rows); n > 0 && t.Rows[n-1].ID >= t.NextRowID {
+		t.NextRowID = t.Rows[n-1].ID + 1
+	}
+	if t.NextRowID < 1 {
+		t.NextRowID = 1
+	}
+	return nil
+}
+
+// RowPositionUnsafe returns the position in Rows of the row with the given id
+// Rows are kept in ascending id order, so this is a binary search.
+// IMPORTANT: Must be called while holding a lock!
+func (t *Table) RowPositionUnsafe(id int64) (int, bool) {
+	pos := sort.Search(len(t.Rows), func(i int) bool { return t.Rows[i].ID >= id })
+	return pos, pos < len(t.Rows) && t.Rows[pos].ID == id
+}
+
+// newRowIDUnsafe allocates the id of a row being inserted
+// Ids only grow: an id is never handed out twice, even if its row is deleted.
+// IMPORTANT: Must be called while holding write lock!
+func (t *Table) newRowIDUnsafe() int64 {
+	id := t.NextRowID
+	if id < 1 {
+		id = 1
+	}
+	if n := len(t.Rows); n > 0 && t.Rows[n-1].ID >= id {
+		id = t.Rows[n-1].ID + 1
+	}
+	t.NextRowID = id + 1
+	return id
+}
+
+// placeRowUnsafe inserts a row at the position its id sorts to and indexes it
+// IMPORTANT: Must be called while holding write lock!
+func (t *Table) placeRowUnsafe(row data.Row) error {
+	pos, exists := t.RowPositionUnsafe(row.ID)
+	if exists {
+		return fmt.Errorf("table %s already has row id %d", t.Name, row.ID)
+	}
+
+	t.Rows = append(t.Rows, data.Row{})
+	copy(t.Rows[pos+1:], t.Rows[pos:])
+	t.Rows[pos] = row
+	t.indexRowUnsafe(row)
+
+	if row.ID >= t.NextRowID {
+		t.NextRowID = row.ID + 1
+	}
+	return nil
+}
+
+// removeRowUnsafe removes the row at pos and its index entries
+// IMPORTANT: Must be called while holding write lock!
+func (t *Table) removeRowUnsafe(pos int) {
+	t.unindexRowUnsafe(t.Rows[pos])
+	t.Rows = append(t.Rows[:pos], t.Rows[pos+1:]...)
+}
+
+// replaceRowUnsafe replaces the values of the row at pos, keeping its id, and
+// moves its index entries to the new values
+// IMPORTANT: Must be called while holding write lock!
+func (t *Table) replaceRowUnsafe(pos int, values map[string]interface{}) {
+	t.unindexRowUnsafe(t.Rows[pos])
+	row := data.NewRow(values).Copy()
+	row.ID = t.Rows[pos].ID
+	t.Rows[pos] = row
+	t.indexRowUnsafe(row)
+}
+
+// indexRowUnsafe adds the row's id to every index, under its value of the indexed column,
+// and its entry to every ordered index
+// IMPORTANT: Must be called while holding write lock!
+func (t *Table) indexRowUnsafe(row data.Row) {
+	for colName, idx := range t.Indexes {
+		if val, exists := row.Data[colName]; exists && val != nil {
+			idx.Data[val] = append(idx.Data[val], row.ID)
+		}
+	}
+	for _, idx := range t.OrderedIndexes {
+		idx.Insert(row)
+	}
+}
+
+// unindexRowUnsafe removes the row's id from every index
+// IMPORTANT: Must be called while holding write lock!
+func (t *Table) unindexRowUnsafe(row data.Row) {
+	for _, idx := range t.OrderedIndexes {
+		idx.Delete(row)
+	}
+	for colName, idx := range t.Indexes {
+		val, exists := row.Data[colName]
+		if !exists || val == nil {
+			continue
+		}
+
+		ids := idx.Data[val]
+		for i, id := range ids {
+			if id == row.ID {
+				ids = append(ids[:i:i], ids[i+1:]...)
+				break
+			}
+		}
+		if len(ids) == 0 {
+			delete(idx.Data, val)
+		} else {
+			idx.Data[val] = ids
+		}
+	}
+}
@@ -16,12 +16,16 @@ const (
 )
 
 // Change represents a single modification within a transaction
+// RowID is the hidden id of the changed row (data.Row.ID), which stays the same for
+// the row's lifetime, so changes can be replayed or undone whatever the row's position
 type Change struct {
-	Type    ChangeType
-	Table   string
-	RowID   int64
-	Data    map[string]interface{} // New data for INSERT/UPDATE
-	OldData map[string]interface{} // Old data for UPDATE/DELETE
+	Type    ChangeType             `json:"type"`
+	Table   string                 `json:"table"`
+	RowID   int64                  `json:"row_id"`
+	Data    map[string]interface{} `json:"data,omitempty"`     // New data for INSERT/UPDATE
+	OldData map[string]interface{} `json:"old_data,omitempty"` // Old data for UPDATE/DELETE
+
+	PrevInsertID int64 `json:"-"` // Table.LastInsertID before an INSERT (restored on rollback)
 }
 
 // Transaction represents a database transaction context
@@ -42,6 +46,11 @@ func NewTransaction() *Transaction {
 	}
 }
 
+// Record appends a change to the transaction's change list
+func (tx *Transaction) Record(change Change) {
+	tx.Changes = append(tx.Changes, change)
+}
+
 // Close marks the transaction as inactive
 func (tx *Transaction) Close() {
 	tx.Active = false
@@ -2,23 +2,44 @@ package engine
 
 import (
 	"fmt"
+	"sync"
 	"time"
 
+	"github.com/leengari/mini-rdbms/internal/domain/errors"
 	"github.com/leengari/mini-rdbms/internal/domain/schema"
 	"github.com/leengari/mini-rdbms/internal/domain/transaction"
 	"github.com/leengari/mini-rdbms/internal/executor"
 	"github.com/leengari/mini-rdbms/internal/parser"
 	"github.com/leengari/mini-rdbms/internal/parser/ast"
 	"github.com/leengari/mini-rdbms/internal/parser/lexer"
+	"github.com/leengari/mini-rdbms/internal/plan"
 	"github.com/leengari/mini-rdbms/internal/planner"
+	storageEngine "github.com/leengari/mini-rdbms/internal/storage/engine"
 	"github.com/leengari/mini-rdbms/internal/storage/manager"
+	"github.com/leengari/mini-rdbms/internal/storage/wal"
 )
 
+// IdleTransactionTimeout is how long an explicit transaction may stay open between
+// statements. An open transaction blocks other writers and checkpoints of its database,
+// so one left idle longer is rolled back and the session's next statement fails.
+// 0 disables the timeout.
+var IdleTransactionTimeout = time.Minute
+
 // Engine is the main entry point for the database system
+// Each Engine is one session: it holds at most one open explicit transaction.
 type Engine struct {
+	mu        sync.Mutex // serializes statements with the idle-transaction timer
 	db        *schema.Database
 	registry  *manager.Registry
 	observers []Observer // Observers for lifecycle events
+	config    *executor.ExecutionConfig
+
+	tx    *transaction.Transaction // open explicit transaction (nil in autocommit mode)
+	txLog *wal.Log                 // WAL write access held by the open transaction (nil until its first write)
+
+	idleTimer *time.Timer // rolls back the open transaction once it has been idle too long
+	idleGen   uint64      // identifies the latest idle timer, so a stale one does nothing
+	idleErr   error       // reported by the next statement after an idle transaction was rolled back
 }
 
 // New creates a new Engine instance
@@ -27,20 +48,45 @@ func New(db *schema.Database, registry *manager.Registry) *Engine {
 		db:        db,
 		registry:  registry,
 		observers: make([]Observer, 0),
+		config:    executor.DefaultExecutionConfig(),
 	}
 }
 
+// Config returns the execution configuration used for this session's statements
+// Changes (e.g. forcing a JoinAlgorithm) apply to subsequent statements.
+func (e *Engine) Config() *executor.ExecutionConfig {
+	return e.config
+}
+
 // Execute processes a SQL string and returns the result
 func (e *Engine) Execute(sql string) (*executor.Result, error) {
-	// 0. Start Transaction
-	tx := transaction.NewTransaction()
-	defer tx.Close()
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.stopIdleTimer()
+	defer e.startIdleTimer()
+	if err := e.idleErr; err != nil {
+		e.idleErr = nil
+		return nil, err
+	}
+	return e.execute(sql)
+}
+
+// execute runs one statement; the caller holds e.mu
+func (e *Engine) execute(sql string) (*executor.Result, error) {
+	// 0. Use the session's open transaction, or start one for this statement (autocommit)
+	tx := e.tx
+	autocommit := tx == nil
+	if autocommit {
+		tx = transaction.NewTransaction()
+		defer tx.Close()
+	}
 
 	// 1. Tokenize
 	e.notify(Event{Type: EventLexStart, TxID: tx.ID, Data: sql})
 	tokens, err := lexer.Tokenize(sql)
 	if err != nil {
-		return nil, fmt.Errorf("lexer error: %w", err)
+		return nil, errors.NewParseErrorWithCause(err.Error(), err)
 	}
 	e.notify(Event{Type: EventLexEnd, TxID: tx.ID, Data: len(tokens)})
 
@@ -49,11 +95,26 @@ func (e *Engine) Execute(sql string) (*executor.Result, error) {
 	p := parser.New(tokens)
 	stmt, err := p.Parse()
 	if err != nil {
-		return nil, fmt.Errorf("parse error: %w", err)
+		return nil, errors.NewParseErrorWithCause(err.Error(), err)
 	}
 	e.notify(Event{Type: EventParseEnd, TxID: tx.ID, Data: fmt.Sprintf("%T", stmt)})
 
-	// 3. Handle Database Management Statements
+	// 3. Handle Transaction Control Statements
+	switch stmt.(type) {
+	case *ast.BeginStatement:
+		return e.begin()
+	case *ast.CommitStatement:
+		return e.commit()
+	case *ast.RollbackStatement:
+		return e.rollback()
+	case *ast.CheckpointStatement:
+		return e.checkpoint(autocommit)
+	}
+
+	// 4. Handle Database Management Statements
+	if !autocommit && isDatabaseManagement(stmt) {
+		return nil, fmt.Errorf("%s DATABASE cannot run inside a transaction", stmt.TokenLiteral())
+	}
 	switch s := stmt.(type) {
 	case *ast.CreateDatabaseStatement:
 		if err := e.registry.Create(s.Name); err != nil {
@@ -82,21 +143,18 @@ func (e *Engine) Execute(sql string) (*executor.Result, error) {
 		return &executor.Result{Message: fmt.Sprintf("Database renamed from '%s' to '%s'", s.Name, s.NewName)}, nil
 
 	case *ast.UseDatabaseStatement:
-		// Load/Get new DB from registry
-		newDB, err := e.registry.Get(s.Name)
-		if err != nil {
-			return nil, fmt.Errorf("failed to load database '%s': %w", s.Name, err)
+		if err := e.use(s.Name); err != nil {
+			return nil, err
 		}
-		e.db = newDB
 		return &executor.Result{Message: fmt.Sprintf("Switched to database '%s'", s.Name)}, nil
 	}
 
-	// 4. Ensure Database is Selected
+	// 5. Ensure Database is Selected
 	if e.db == nil {
 		return nil, fmt.Errorf("no database selected. Use 'USE <database_name>' to select one")
 	}
 
-	// 5. Plan (for DML/DQL)
+	// 6. Plan (for DML/DQL)
 	e.notify(Event{Type: EventPlanStart, TxID: tx.ID})
 	planNode, err := planner.Plan(stmt, e.db, tx)
 	if err != nil {
@@ -104,12 +162,23 @@ func (e *Engine) Execute(sql string) (*executor.Result, error) {
 	}
 	e.notify(Event{Type: EventPlanEnd, TxID: tx.ID, Data: fmt.Sprintf("%T", planNode)})
 
-	// 6. Execute
+	if !autocommit && isDDL(planNode) {
+		return nil, fmt.Errorf("%s cannot run inside a transaction", planNode.NodeType())
+	}
+
+	// 7. Execute
 	e.notify(Event{Type: EventExecStart, TxID: tx.ID})
-	result, err := executor.Execute(planNode, e.db, tx)
+	result, err := e.run(planNode, tx, autocommit)
 	if err != nil {
 		return nil, fmt.Errorf("execution error: %w", err)
 	}
+
+	// Schema changes are not logged; checkpoint so the WAL never spans a DDL statement
+	if isDDL(planNode) && e.registry != nil {
+		if err := e.registry.Checkpoint(e.db); err != nil {
+			return nil, fmt.Errorf("checkpoint after %s failed: %w", planNode.NodeType(), err)
+		}
+	}
 	e.notify(Event{Type: EventExecEnd, TxID: tx.ID, Data: map[string]interface{}{
 		"rows_affected": result.RowsAffected,
 		"rows_returned": len(result.Rows),
@@ -118,6 +187,150 @@ func (e *Engine) Execute(sql string) (*executor.Result, error) {
 	return result, nil
 }
 
+// Use selects the session's database by name, like USE, without building SQL from the name
+// (e.g. for a database named in a connection's startup parameters)
+func (e *Engine) Use(name string) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.tx != nil {
+		return fmt.Errorf("USE DATABASE cannot run inside a transaction")
+	}
+	if !isIdentifier(name) {
+		return fmt.Errorf("invalid database name %q", name)
+	}
+	return e.use(name)
+}
+
+// use loads a database from the registry and makes it the session's database
+func (e *Engine) use(name string) error {
+	if e.registry == nil {
+		return fmt.Errorf("USE requires a database registry")
+	}
+	newDB, err := e.registry.Get(name)
+	if err != nil {
+		return fmt.Errorf("failed to load database '%s': %w", name, err)
+	}
+	e.db = newDB
+	return nil
+}
+
+// isIdentifier reports whether name lexes as a single identifier, as USE requires,
+// so it cannot name a path outside the registry's base directory
+func isIdentifier(name string) bool {
+	tokens, err := lexer.Tokenize(name)
+	return err == nil && len(tokens) == 1 && tokens[0].Type == lexer.IDENTIFIER && tokens[0].Literal == name
+}
+
+// run executes a plan node as an atomic statement
+// In autocommit mode writes are logged to the WAL before returning; inside an
+// explicit transaction they are logged at COMMIT. If the statement fails, any
+// changes it already made are undone.
+func (e *Engine) run(node plan.Node, tx *transaction.Transaction, autocommit bool) (*executor.Result, error) {
+	var walLog *wal.Log
+	if isWrite(node) {
+		if autocommit {
+			walLog = e.wal()
+			if walLog != nil {
+				walLog.BeginWrite()
+				defer walLog.EndWrite()
+			}
+		} else if e.txLog == nil {
+			// Held until COMMIT/ROLLBACK
+			e.txLog = e.wal()
+			if e.txLog != nil {
+				e.txLog.BeginWrite()
+			}
+		}
+	}
+
+	mark := len(tx.Changes)
+	ctx := executor.NewExecutionContext(e.db, tx, e.storage())
+	ctx.Config = e.config
+	result, err := executor.Execute(node, ctx)
+	if err == nil && walLog != nil {
+		// Make the committed changes durable before returning
+		if walErr := walLog.Commit(e.db, tx); walErr != nil {
+			err = fmt.Errorf("failed to write WAL: %w", walErr)
+		}
+	}
+	if err != nil {
+		if undoErr := e.db.RevertChanges(tx.Changes[mark:]); undoErr != nil {
+			return nil, fmt.Errorf("%w (undo failed: %v)", err, undoErr)
+		}
+		tx.Changes = tx.Changes[:mark]
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// checkpoint writes the dirty tables of every loaded database to disk (CHECKPOINT)
+// Not allowed inside a transaction: the checkpoint waits for open writers to finish.
+func (e *Engine) checkpoint(autocommit bool) (*executor.Result, error) {
+	if !autocommit {
+		return nil, fmt.Errorf("CHECKPOINT cannot run inside a transaction")
+	}
+	if e.registry == nil {
+		return nil, fmt.Errorf("CHECKPOINT requires a database registry")
+	}
+
+	tables, err := e.registry.Flush()
+	if err != nil {
+		return nil, fmt.Errorf("checkpoint failed: %w", err)
+	}
+	return &executor.Result{Message: fmt.Sprintf("CHECKPOINT (%d tables written)", tables)}, nil
+}
+
+// storage returns the registry's storage engine, or nil when running without a registry
+func (e *Engine) storage() storageEngine.StorageEngine {
+	if e.registry == nil {
+		return nil
+	}
+	return e.registry.StorageEngine()
+}
+
+// wal returns the write-ahead log of the current database, or nil when
+// the database is not managed by the registry (e.g. loaded directly in tests)
+func (e *Engine) wal() *wal.Log {
+	if e.registry == nil {
+		return nil
+	}
+	return e.registry.WAL(e.db)
+}
+
+// isWrite reports whether a plan node modifies table data or schema
+func isWrite(node plan.Node) bool {
+	switch node.(type) {
+	case *plan.InsertNode, *plan.UpdateNode, *plan.DeleteNode:
+		return true
+	default:
+		return isDDL(node)
+	}
+}
+
+// isDatabaseManagement reports whether a statement creates, drops, renames or switches databases
+func isDatabaseManagement(stmt ast.Statement) bool {
+	switch stmt.(type) {
+	case *ast.CreateDatabaseStatement, *ast.DropDatabaseStatement,
+		*ast.AlterDatabaseStatement, *ast.UseDatabaseStatement:
+		return true
+	default:
+		return false
+	}
+}
+
+// isDDL reports whether a plan node changes the database schema
+func isDDL(node plan.Node) bool {
+	switch node.(type) {
+	case *plan.CreateTableNode, *plan.DropTableNode, *plan.AlterTableNode,
+		*plan.CreateIndexNode, *plan.DropIndexNode:
+		return true
+	default:
+		return false
+	}
+}
+
 // ListTables returns a list of tables in the currently selected database
 func (e *Engine) ListTables() ([]string, error) {
 	if e.db == nil {
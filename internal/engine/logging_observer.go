@@ -1,8 +1,13 @@
 package engine
 
-import "log/slog"
+import (
+	"log/slog"
+
+	"github.com/leengari/mini-rdbms/internal/storage/manager"
+)
 
 // LoggingObserver is a simple observer that logs all events using structured logging
+// It also implements manager.CheckpointObserver for checkpoint events.
 type LoggingObserver struct {
 	logger *slog.Logger
 }
@@ -24,3 +29,25 @@ func (lo *LoggingObserver) OnEvent(event Event) {
 		"data", event.Data,
 	)
 }
+
+// OnCheckpoint implements the manager.CheckpointObserver interface
+func (lo *LoggingObserver) OnCheckpoint(event manager.CheckpointEvent) {
+	if event.Err != nil {
+		lo.logger.Error("checkpoint",
+			"event", event.Type,
+			"reason", event.Reason,
+			"database", event.Database,
+			"error", event.Err,
+		)
+		return
+	}
+	lo.logger.Info("checkpoint",
+		"event", event.Type,
+		"reason", event.Reason,
+		"database", event.Database,
+		"timestamp", event.Timestamp,
+		"tables", event.Tables,
+		"rows", event.Rows,
+		"duration", event.Duration,
+	)
+}
@@ -0,0 +1,129 @@
+package engine
+
+import (
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/leengari/mini-rdbms/internal/domain/transaction"
+	"github.com/leengari/mini-rdbms/internal/executor"
+)
+
+// InTransaction reports whether the session has an open explicit transaction
+func (e *Engine) InTransaction() bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.tx != nil
+}
+
+// Close ends the session, rolling back any open transaction
+func (e *Engine) Close() error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.stopIdleTimer()
+	e.idleErr = nil
+	if e.tx == nil {
+		return nil
+	}
+	_, err := e.rollback()
+	return err
+}
+
+// begin opens an explicit transaction for this session
+func (e *Engine) begin() (*executor.Result, error) {
+	if e.tx != nil {
+		return nil, fmt.Errorf("a transaction is already in progress")
+	}
+	if e.db == nil {
+		return nil, fmt.Errorf("no database selected. Use 'USE <database_name>' to select one")
+	}
+
+	e.tx = transaction.NewTransaction()
+	return &executor.Result{Message: "BEGIN"}, nil
+}
+
+// commit makes the open transaction's changes durable as a single WAL record
+// If the log write fails, the transaction is rolled back.
+func (e *Engine) commit() (*executor.Result, error) {
+	if e.tx == nil {
+		return nil, fmt.Errorf("no transaction in progress")
+	}
+
+	if e.txLog != nil {
+		if err := e.txLog.Commit(e.db, e.tx); err != nil {
+			undoErr := e.db.RevertChanges(e.tx.Changes)
+			e.endTransaction()
+			if undoErr != nil {
+				return nil, fmt.Errorf("commit failed: %w (rollback failed: %v)", err, undoErr)
+			}
+			return nil, fmt.Errorf("commit failed, transaction rolled back: %w", err)
+		}
+	}
+
+	e.endTransaction()
+	return &executor.Result{Message: "COMMIT"}, nil
+}
+
+// rollback undoes every change made by the open transaction
+func (e *Engine) rollback() (*executor.Result, error) {
+	if e.tx == nil {
+		return nil, fmt.Errorf("no transaction in progress")
+	}
+
+	err := e.db.RevertChanges(e.tx.Changes)
+	e.endTransaction()
+	if err != nil {
+		return nil, fmt.Errorf("rollback failed: %w", err)
+	}
+
+	return &executor.Result{Message: "ROLLBACK"}, nil
+}
+
+// endTransaction releases WAL write access and returns the session to autocommit mode
+func (e *Engine) endTransaction() {
+	if e.txLog != nil {
+		e.txLog.EndWrite()
+		e.txLog = nil
+	}
+	e.tx.Close()
+	e.tx = nil
+}
+
+// startIdleTimer arms the idle-transaction timeout if a transaction is open
+// after a statement; the caller holds e.mu
+func (e *Engine) startIdleTimer() {
+	timeout := IdleTransactionTimeout
+	if e.tx == nil || timeout <= 0 {
+		return
+	}
+
+	e.idleGen++
+	gen := e.idleGen
+	e.idleTimer = time.AfterFunc(timeout, func() { e.rollbackIdle(gen, timeout) })
+}
+
+// stopIdleTimer disarms the idle-transaction timeout; the caller holds e.mu
+func (e *Engine) stopIdleTimer() {
+	if e.idleTimer != nil {
+		e.idleTimer.Stop()
+		e.idleTimer = nil
+	}
+}
+
+// rollbackIdle rolls back a transaction that stayed idle for the timeout,
+// unless a statement started (and stopped the timer) in the meantime
+func (e *Engine) rollbackIdle(gen uint64, timeout time.Duration) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.idleTimer == nil || e.idleGen != gen || e.tx == nil {
+		return
+	}
+	e.idleTimer = nil
+
+	if _, err := e.rollback(); err != nil {
+		slog.Error("failed to roll back idle transaction", "error", err)
+	}
+	e.idleErr = fmt.Errorf("transaction rolled back after being idle for %s", timeout)
+}
@@ -0,0 +1,224 @@
+package executor
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/leengari/mini-rdbms/internal/domain/data"
+	"github.com/leengari/mini-rdbms/internal/domain/schema"
+	"github.com/leengari/mini-rdbms/internal/plan"
+	"github.com/leengari/mini-rdbms/internal/util/types"
+)
+
+// aggregateState accumulates one aggregate over the rows of a group
+type aggregateState struct {
+	spec     plan.AggregateSpec
+	count    int64
+	intSum   int64
+	floatSum float64
+	min, max interface{}
+	seen     map[string]bool // DISTINCT values already aggregated
+}
+
+// group is one GROUP BY bucket
+type group struct {
+	values []interface{} // Group column values, in GROUP BY order
+	states []*aggregateState
+}
+
+// executeAggregateNode groups the child's rows and computes each aggregate per group
+// Works on plain scans and JOIN output alike: columns are looked up by qualified or bare name.
+// Groups are returned in order of first appearance.
+func executeAggregateNode(node *plan.AggregateNode, ctx *ExecutionContext) (*IntermediateResult, error) {
+	childResult, err := executeNode(node.Child(), ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	newGroup := func(values []interface{}) *group {
+		g := &group{values: values, states: make([]*aggregateState, len(node.Aggregates))}
+		for i, spec := range node.Aggregates {
+			g.states[i] = &aggregateState{spec: spec}
+			if spec.Distinct {
+				g.states[i].seen = make(map[string]bool)
+			}
+		}
+		return g
+	}
+
+	var groups []*group
+	byKey := make(map[string]*group)
+
+	for _, row := range childResult.Rows {
+		values := make([]interface{}, len(node.GroupBy))
+		keyParts := make([]string, len(node.GroupBy))
+		for i, key := range node.GroupBy {
+			values[i] = lookupColumn(row, key.Table, key.Column)
+			keyParts[i] = valueKey(values[i])
+		}
+		groupKey := strings.Join(keyParts, "\x00")
+
+		g, ok := byKey[groupKey]
+		if !ok {
+			g = newGroup(values)
+			byKey[groupKey] = g
+			groups = append(groups, g)
+		}
+
+		for _, state := range g.states {
+			if err := state.add(row); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	// Without GROUP BY an aggregate over no rows still yields one row (e.g. COUNT(*) = 0)
+	if len(node.GroupBy) == 0 && len(groups) == 0 {
+		groups = append(groups, newGroup(nil))
+	}
+
+	having := outerPredicate(node.Having, ctx)
+	rows := make([]data.Row, 0, len(groups))
+	for _, g := range groups {
+		out := make(map[string]interface{})
+		for i, key := range node.GroupBy {
+			out[key.Table+"."+key.Column] = g.values[i]
+			if _, exists := out[key.Column]; !exists {
+				out[key.Column] = g.values[i]
+			}
+		}
+		for _, state := range g.states {
+			out[state.spec.Name] = state.result()
+		}
+
+		row := data.NewRow(out)
+		if having != nil {
+			match, err := having(row)
+			if err != nil {
+				return nil, err
+			}
+			if !match {
+				continue
+			}
+		}
+		rows = append(rows, row)
+	}
+
+	return &IntermediateResult{
+		Rows:   rows,
+		Schema: aggregateSchema(node),
+		Metadata: map[string]interface{}{
+			"groups":    len(groups),
+			"row_count": len(rows),
+		},
+	}, nil
+}
+
+// add folds one input row into the aggregate
+// Returns the error of an argument that fails to evaluate (e.g. division by zero).
+func (s *aggregateState) add(row data.Row) error {
+	if s.spec.Arg == nil {
+		// COUNT(*)
+		s.count++
+		return nil
+	}
+
+	val, err := s.spec.Arg(row)
+	if err != nil {
+		return fmt.Errorf("failed to compute %s: %w", s.spec.Name, err)
+	}
+	if val == nil {
+		return nil // Aggregates ignore NULLs
+	}
+	if s.seen != nil {
+		k := valueKey(val)
+		if s.seen[k] {
+			return nil
+		}
+		s.seen[k] = true
+	}
+
+	s.count++
+	switch s.spec.Func {
+	case "SUM", "AVG":
+		if n, ok := types.NormalizeToInt64(val); ok && s.spec.ArgType == schema.ColumnTypeInt {
+			s.intSum += n
+		}
+		if f, ok := types.NormalizeToFloat(val); ok {
+			s.floatSum += f
+		}
+	case "MIN":
+		if s.min == nil || types.CompareValues(val, "<", s.min) {
+			s.min = val
+		}
+	case "MAX":
+		if s.max == nil || types.CompareValues(val, ">", s.max) {
+			s.max = val
+		}
+	}
+	return nil
+}
+
+// result returns the final aggregate value
+// COUNT is int64; SUM keeps the column's numeric type; AVG is float64; MIN/MAX keep the
+// column's type. Aggregates other than COUNT over no values are NULL.
+func (s *aggregateState) result() interface{} {
+	if s.spec.Func == "COUNT" {
+		return s.count
+	}
+	if s.count == 0 {
+		return nil
+	}
+
+	switch s.spec.Func {
+	case "SUM":
+		if s.spec.ArgType == schema.ColumnTypeInt {
+			return s.intSum
+		}
+		return s.floatSum
+	case "AVG":
+		return s.floatSum / float64(s.count)
+	case "MIN":
+		return normalizeAggregateValue(s.min, s.spec.ArgType)
+	case "MAX":
+		return normalizeAggregateValue(s.max, s.spec.ArgType)
+	}
+	return nil
+}
+
+// normalizeAggregateValue returns INT values as int64 (JSON-loaded rows hold float64)
+func normalizeAggregateValue(val interface{}, colType schema.ColumnType) interface{} {
+	if colType == schema.ColumnTypeInt {
+		if n, ok := types.NormalizeToInt64(val); ok {
+			return n
+		}
+	}
+	return val
+}
+
+// aggregateSchema describes the aggregated rows so result metadata reports real types
+func aggregateSchema(node *plan.AggregateNode) *schema.TableSchema {
+	result := &schema.TableSchema{}
+	for _, key := range node.GroupBy {
+		result.Columns = append(result.Columns,
+			schema.Column{Name: key.Table + "." + key.Column, Type: key.Type},
+			schema.Column{Name: key.Column, Type: key.Type},
+		)
+	}
+	for _, spec := range node.Aggregates {
+		result.Columns = append(result.Columns, schema.Column{Name: spec.Name, Type: spec.ResultType()})
+	}
+	return result
+}
+
+// valueKey returns a comparable key for grouping and DISTINCT
+// Numbers compare by value, so 2, int64(2) and 2.0 share a key.
+func valueKey(val interface{}) string {
+	if val == nil {
+		return "null"
+	}
+	if f, ok := types.NormalizeToFloat(val); ok {
+		return fmt.Sprintf("n:%v", f)
+	}
+	return fmt.Sprintf("%T:%v", val, val)
+}
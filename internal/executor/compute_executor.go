@@ -0,0 +1,54 @@
+package executor
+
+import (
+	"fmt"
+
+	"github.com/leengari/mini-rdbms/internal/domain/data"
+	"github.com/leengari/mini-rdbms/internal/domain/schema"
+	"github.com/leengari/mini-rdbms/internal/plan"
+)
+
+// executeComputeNode evaluates the node's computed columns for every row of the child
+// Each output row is a copy of the input row with the computed values added under their names.
+func executeComputeNode(node *plan.ComputeNode, ctx *ExecutionContext) (*IntermediateResult, error) {
+	childResult, err := executeNode(node.Child(), ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	rows := make([]data.Row, len(childResult.Rows))
+	for i, row := range childResult.Rows {
+		computed := row.Copy()
+		for _, col := range node.Columns {
+			val, err := col.Eval(withOuter(row, ctx))
+			if err != nil {
+				return nil, fmt.Errorf("failed to compute %s: %w", col.Name, err)
+			}
+			computed.Data[col.Name] = val
+		}
+		rows[i] = computed
+	}
+
+	return &IntermediateResult{
+		Rows:   rows,
+		Schema: computedSchema(childResult.Schema, node.Columns),
+		Metadata: map[string]interface{}{
+			"computed_columns": len(node.Columns),
+			"row_count":        len(rows),
+		},
+	}, nil
+}
+
+// computedSchema extends the child's schema with the computed columns
+// Computed columns come first so an alias that shadows a source column resolves to the computed type.
+func computedSchema(childSchema *schema.TableSchema, columns []plan.ComputedColumn) *schema.TableSchema {
+	result := &schema.TableSchema{}
+	for _, col := range columns {
+		result.Columns = append(result.Columns, schema.Column{Name: col.Name, Type: col.Type})
+	}
+	if childSchema != nil {
+		result.TableName = childSchema.TableName
+		result.Columns = append(result.Columns, childSchema.Columns...)
+	}
+	return result
+}
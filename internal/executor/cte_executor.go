@@ -0,0 +1,119 @@
+package executor
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/leengari/mini-rdbms/internal/domain/data"
+	"github.com/leengari/mini-rdbms/internal/domain/errors"
+	"github.com/leengari/mini-rdbms/internal/domain/schema"
+	"github.com/leengari/mini-rdbms/internal/plan"
+)
+
+// bindCommonTables binds the CTEs of a WITH clause, and the subqueries and CTEs they contain, to ctx
+func bindCommonTables(tables []*plan.CommonTable, ctx *ExecutionContext) error {
+	for _, ct := range tables {
+		ct.Bind(commonTableRunner(ct, ctx))
+		if err := bindSubqueries(ct.Anchor, ctx); err != nil {
+			return err
+		}
+		if ct.Recursive != nil {
+			if err := bindSubqueries(ct.Recursive, ctx); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// commonTableRunner returns the function that computes a CTE's rows
+// A recursive term that reads the working table runs until an iteration adds no
+// rows; adding rows in more than Config.MaxRecursion iterations fails with an ExecutionError.
+// With UNION (rather than UNION ALL) duplicate rows are discarded, so a recursive
+// walk over a cyclic graph stops once it has seen every row.
+func commonTableRunner(ct *plan.CommonTable, ctx *ExecutionContext) func() ([]data.Row, error) {
+	return func() ([]data.Row, error) {
+		// CTEs are not correlated with the query reading them
+		cteCtx := *ctx
+		cteCtx.Outer = data.Row{}
+
+		seen := make(map[string]bool)
+		addRows := func(result, rows []data.Row) ([]data.Row, []data.Row) {
+			if ct.UnionAll {
+				return append(result, rows...), rows
+			}
+			added := make([]data.Row, 0, len(rows))
+			for _, row := range rows {
+				key := rowKey(row, ct.Columns)
+				if !seen[key] {
+					seen[key] = true
+					added = append(added, row)
+				}
+			}
+			return append(result, added...), added
+		}
+
+		anchor, err := executeNode(ct.Anchor, &cteCtx)
+		if err != nil {
+			return nil, err
+		}
+		rows, working := addRows(nil, renameColumns(anchor.Rows, ct.AnchorKeys, ct.Columns))
+		if ct.Recursive == nil {
+			return rows, nil
+		}
+
+		maxIterations := DefaultMaxRecursion
+		if ctx.Config != nil && ctx.Config.MaxRecursion > 0 {
+			maxIterations = ctx.Config.MaxRecursion
+		}
+
+		for iteration := 1; ; iteration++ {
+			ct.SetWorking(working)
+			result, err := executeNode(ct.Recursive, &cteCtx)
+			if err != nil {
+				return nil, err
+			}
+			rows, working = addRows(rows, renameColumns(result.Rows, ct.RecursiveKeys, ct.Columns))
+			if len(working) == 0 || !ct.SelfReference {
+				break
+			}
+			if iteration > maxIterations {
+				return nil, errors.NewExecutionError("SELECT", ct.Name,
+					fmt.Sprintf("recursive query still adding rows after %d iterations", maxIterations))
+			}
+		}
+		ct.SetWorking(nil)
+
+		return rows, nil
+	}
+}
+
+// executeCTEScan executes a CTEScanNode, reading a CTE's rows (or its working table)
+func executeCTEScan(node *plan.CTEScanNode, ctx *ExecutionContext) (*IntermediateResult, error) {
+	rows := node.Table.Working()
+	if !node.Working {
+		var err error
+		if rows, err = node.Table.Rows(); err != nil {
+			return nil, err
+		}
+	}
+
+	return &IntermediateResult{
+		Rows:   rows,
+		Schema: &schema.TableSchema{TableName: node.Name, Columns: node.Table.Columns},
+		Metadata: map[string]interface{}{
+			"table":     node.Name,
+			"scan_type": "cte",
+			"row_count": len(rows),
+		},
+	}, nil
+}
+
+// rowKey returns a comparable key for a row's values in column order (see valueKey)
+func rowKey(row data.Row, columns []schema.Column) string {
+	parts := make([]string, len(columns))
+	for i, col := range columns {
+		parts[i] = valueKey(row.Data[col.Name])
+	}
+	return strings.Join(parts, "\x00")
+}
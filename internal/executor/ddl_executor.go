@@ -0,0 +1,188 @@
+package executor
+
+import (
+	"fmt"
+
+	"github.com/leengari/mini-rdbms/internal/domain/data"
+	"github.com/leengari/mini-rdbms/internal/domain/schema"
+	"github.com/leengari/mini-rdbms/internal/plan"
+	"github.com/leengari/mini-rdbms/internal/query/indexing"
+)
+
+// executeCreateTableNode creates a new empty table and persists it through the storage engine
+func executeCreateTableNode(node *plan.CreateTableNode, ctx *ExecutionContext) (*IntermediateResult, error) {
+	if _, exists := ctx.Database.Tables[node.TableName]; exists {
+		return nil, fmt.Errorf("table '%s' already exists", node.TableName)
+	}
+
+	table := &schema.Table{
+		Name:    node.TableName,
+		Schema:  node.Schema,
+		Rows:    []data.Row{},
+		Indexes: make(map[string]*data.Index),
+	}
+
+	// Build (empty) indexes for primary key / unique columns
+	if err := indexing.BuildIndexes(table); err != nil {
+		return nil, err
+	}
+
+	if ctx.Storage != nil {
+		if err := ctx.Storage.CreateTable(ctx.Database, table); err != nil {
+			return nil, err
+		}
+	} else {
+		ctx.Database.Tables[node.TableName] = table
+	}
+
+	return &IntermediateResult{
+		Rows:   []data.Row{},
+		Schema: node.Schema,
+		Metadata: map[string]interface{}{
+			"operation": "CREATE TABLE",
+			"message":   fmt.Sprintf("Table '%s' created", node.TableName),
+		},
+	}, nil
+}
+
+// executeDropTableNode removes a table from the database and its storage
+func executeDropTableNode(node *plan.DropTableNode, ctx *ExecutionContext) (*IntermediateResult, error) {
+	if _, exists := ctx.Database.Tables[node.TableName]; !exists {
+		if node.IfExists {
+			return &IntermediateResult{
+				Rows: []data.Row{},
+				Metadata: map[string]interface{}{
+					"operation": "DROP TABLE",
+					"message":   fmt.Sprintf("Table '%s' does not exist, skipping", node.TableName),
+				},
+			}, nil
+		}
+		return nil, newTableNotFoundError(node.TableName)
+	}
+
+	if ctx.Storage != nil {
+		if err := ctx.Storage.DropTable(ctx.Database, node.TableName); err != nil {
+			return nil, err
+		}
+	} else {
+		delete(ctx.Database.Tables, node.TableName)
+	}
+
+	return &IntermediateResult{
+		Rows: []data.Row{},
+		Metadata: map[string]interface{}{
+			"operation": "DROP TABLE",
+			"message":   fmt.Sprintf("Table '%s' dropped", node.TableName),
+		},
+	}, nil
+}
+
+// executeAlterTableNode applies a schema change to an existing table
+// Column changes rewrite rows and indexes in memory and mark the table dirty;
+// RENAME TO moves the table's storage immediately
+func executeAlterTableNode(node *plan.AlterTableNode, ctx *ExecutionContext) (*IntermediateResult, error) {
+	table, exists := ctx.Database.Tables[node.TableName]
+	if !exists {
+		return nil, newTableNotFoundError(node.TableName)
+	}
+
+	var message string
+	switch node.Action {
+	case "ADD COLUMN":
+		if err := table.AddColumn(*node.Column); err != nil {
+			return nil, err
+		}
+		message = fmt.Sprintf("Column '%s' added to table '%s'", node.Column.Name, node.TableName)
+
+	case "DROP COLUMN":
+		if err := table.DropColumn(node.OldName); err != nil {
+			return nil, err
+		}
+		message = fmt.Sprintf("Column '%s' dropped from table '%s'", node.OldName, node.TableName)
+
+	case "RENAME COLUMN":
+		if err := table.RenameColumn(node.OldName, node.NewName); err != nil {
+			return nil, err
+		}
+		message = fmt.Sprintf("Column '%s' renamed to '%s' in table '%s'", node.OldName, node.NewName, node.TableName)
+
+	case "RENAME TO":
+		if _, exists := ctx.Database.Tables[node.NewName]; exists {
+			return nil, fmt.Errorf("table '%s' already exists", node.NewName)
+		}
+		if ctx.Storage != nil {
+			if err := ctx.Storage.RenameTable(ctx.Database, node.TableName, node.NewName); err != nil {
+				return nil, err
+			}
+		} else {
+			table.Lock()
+			table.Name = node.NewName
+			table.Schema.TableName = node.NewName
+			table.Unlock()
+			delete(ctx.Database.Tables, node.TableName)
+			ctx.Database.Tables[node.NewName] = table
+		}
+		message = fmt.Sprintf("Table '%s' renamed to '%s'", node.TableName, node.NewName)
+
+	default:
+		return nil, fmt.Errorf("unsupported ALTER TABLE action: %s", node.Action)
+	}
+
+	return &IntermediateResult{
+		Rows: []data.Row{},
+		Metadata: map[string]interface{}{
+			"operation": "ALTER TABLE",
+			"message":   message,
+		},
+	}, nil
+}
+
+// executeCreateIndexNode builds an ordered index over existing rows
+// Like column changes, the new definition reaches meta.json when the table is next saved.
+func executeCreateIndexNode(node *plan.CreateIndexNode, ctx *ExecutionContext) (*IntermediateResult, error) {
+	table, exists := ctx.Database.Tables[node.TableName]
+	if !exists {
+		return nil, newTableNotFoundError(node.TableName)
+	}
+
+	if err := table.CreateIndex(node.IndexName, node.Columns, node.Unique); err != nil {
+		return nil, err
+	}
+
+	return &IntermediateResult{
+		Rows: []data.Row{},
+		Metadata: map[string]interface{}{
+			"operation": "CREATE INDEX",
+			"message":   fmt.Sprintf("Index '%s' created on table '%s'", node.IndexName, node.TableName),
+		},
+	}, nil
+}
+
+// executeDropIndexNode removes an ordered index from its table
+func executeDropIndexNode(node *plan.DropIndexNode, ctx *ExecutionContext) (*IntermediateResult, error) {
+	table, exists := ctx.Database.Tables[node.TableName]
+	if !exists {
+		if node.IfExists {
+			return &IntermediateResult{
+				Rows: []data.Row{},
+				Metadata: map[string]interface{}{
+					"operation": "DROP INDEX",
+					"message":   fmt.Sprintf("Index '%s' does not exist, skipping", node.IndexName),
+				},
+			}, nil
+		}
+		return nil, newTableNotFoundError(node.TableName)
+	}
+
+	if err := table.DropIndex(node.IndexName); err != nil {
+		return nil, err
+	}
+
+	return &IntermediateResult{
+		Rows: []data.Row{},
+		Metadata: map[string]interface{}{
+			"operation": "DROP INDEX",
+			"message":   fmt.Sprintf("Index '%s' dropped", node.IndexName),
+		},
+	}, nil
+}
@@ -12,15 +12,33 @@ func executeDeleteNode(node *plan.DeleteNode, ctx *ExecutionContext) (*Intermedi
 		return nil, newTableNotFoundError(node.TableName)
 	}
 
-	// Use domain model to delete
-	rowsAffected, err := table.Delete(node.Predicate, ctx.Transaction)
+	// Use domain model to delete, through the index when the plan has an index scan
+	tx, mark := statementLog(ctx, node.Returning)
+	var rowsAffected int
+	var err error
+	indexScan := indexScanChild(node)
+	switch {
+	case indexScan == nil || !ctx.Config.UseIndexes:
+		rowsAffected, err = table.Delete(node.Predicate, tx)
+	case indexScan.Index != "":
+		rowsAffected, err = table.DeleteOrdered(orderedScan(indexScan), node.Predicate, tx)
+	default:
+		rowsAffected, err = table.DeleteIndexed(indexScan.Column, indexLookup(indexScan), node.Predicate, tx)
+	}
 	if err != nil {
 		return nil, err
 	}
 
+	returned := []data.Row{}
+	if tx != nil {
+		if returned, err = returningRows(node.Returning, tx.Changes[mark:], true); err != nil {
+			return nil, err
+		}
+	}
+
 	return &IntermediateResult{
-		Rows:   []data.Row{},
-		Schema: nil,
+		Rows:   returned,
+		Schema: returningSchema(node.TableName, node.Returning),
 		Metadata: map[string]interface{}{
 			"operation":     "DELETE",
 			"rows_affected": rowsAffected,
@@ -4,8 +4,8 @@ import (
 	"fmt"
 
 	"github.com/leengari/mini-rdbms/internal/domain/data"
+	"github.com/leengari/mini-rdbms/internal/domain/errors"
 	"github.com/leengari/mini-rdbms/internal/domain/schema"
-	"github.com/leengari/mini-rdbms/internal/domain/transaction"
 	"github.com/leengari/mini-rdbms/internal/plan"
 )
 
@@ -35,16 +35,20 @@ type IntermediateResult struct {
 
 // newTableNotFoundError creates a consistent error for missing tables
 func newTableNotFoundError(tableName string) error {
-	return fmt.Errorf("table not found: %s", tableName)
+	return errors.NewTableNotFoundError(tableName)
 }
 
 // Execute is the main entry point for executing execution plans
 // It dispatches to the appropriate executor based on node type using tree walking
-func Execute(node plan.Node, db *schema.Database, tx *transaction.Transaction) (*Result, error) {
-	ctx := &ExecutionContext{
-		Database:    db,
-		Transaction: tx,
-		Config:      DefaultExecutionConfig(),
+func Execute(node plan.Node, ctx *ExecutionContext) (*Result, error) {
+	if ctx.Config == nil {
+		ctx.Config = DefaultExecutionConfig()
+	}
+	db := ctx.Database
+
+	// Bind subqueries, running the uncorrelated ones
+	if err := bindSubqueries(node, ctx); err != nil {
+		return nil, err
 	}
 
 	// Execute the plan tree recursively
@@ -58,11 +62,14 @@ func Execute(node plan.Node, db *schema.Database, tx *transaction.Transaction) (
 	case *plan.SelectNode:
 		return formatSelectResult(n, intermediate, db), nil
 	case *plan.InsertNode:
-		return formatInsertResult(intermediate), nil
+		return withReturning(formatInsertResult(intermediate), n.Returning, intermediate), nil
 	case *plan.UpdateNode:
-		return formatUpdateResult(intermediate), nil
+		return withReturning(formatUpdateResult(intermediate), n.Returning, intermediate), nil
 	case *plan.DeleteNode:
-		return formatDeleteResult(intermediate), nil
+		return withReturning(formatDeleteResult(intermediate), n.Returning, intermediate), nil
+	case *plan.CreateTableNode, *plan.DropTableNode, *plan.AlterTableNode,
+		*plan.CreateIndexNode, *plan.DropIndexNode:
+		return formatDDLResult(intermediate), nil
 	default:
 		return nil, fmt.Errorf("unsupported plan node type: %T", node)
 	}
@@ -81,8 +88,28 @@ func executeNode(node plan.Node, ctx *ExecutionContext) (*IntermediateResult, er
 	switch n := node.(type) {
 	case *plan.ScanNode:
 		return executeScan(n, ctx)
+	case *plan.IndexScanNode:
+		return executeIndexScan(n, ctx)
+	case *plan.SubqueryScanNode:
+		return executeSubqueryScan(n, ctx)
+	case *plan.CTEScanNode:
+		return executeCTEScan(n, ctx)
 	case *plan.JoinNode:
 		return executeJoinNode(n, ctx)
+	case *plan.SetOperationNode:
+		return executeSetOperation(n, ctx)
+	case *plan.FilterNode:
+		return executeFilterNode(n, ctx)
+	case *plan.AggregateNode:
+		return executeAggregateNode(n, ctx)
+	case *plan.WindowNode:
+		return executeWindowNode(n, ctx)
+	case *plan.ComputeNode:
+		return executeComputeNode(n, ctx)
+	case *plan.SortNode:
+		return executeSortNode(n, ctx)
+	case *plan.LimitNode:
+		return executeLimitNode(n, ctx)
 	case *plan.SelectNode:
 		return executeSelectNode(n, ctx)
 	case *plan.InsertNode:
@@ -91,6 +118,16 @@ func executeNode(node plan.Node, ctx *ExecutionContext) (*IntermediateResult, er
 		return executeUpdateNode(n, ctx)
 	case *plan.DeleteNode:
 		return executeDeleteNode(n, ctx)
+	case *plan.CreateTableNode:
+		return executeCreateTableNode(n, ctx)
+	case *plan.DropTableNode:
+		return executeDropTableNode(n, ctx)
+	case *plan.AlterTableNode:
+		return executeAlterTableNode(n, ctx)
+	case *plan.CreateIndexNode:
+		return executeCreateIndexNode(n, ctx)
+	case *plan.DropIndexNode:
+		return executeDropIndexNode(n, ctx)
 	default:
 		return nil, fmt.Errorf("unsupported plan node type: %T", node)
 	}
@@ -1,28 +1,77 @@
 package executor
 
 import (
+	"fmt"
+
 	"github.com/leengari/mini-rdbms/internal/domain/data"
 	"github.com/leengari/mini-rdbms/internal/plan"
+	"github.com/leengari/mini-rdbms/internal/util/types"
 )
 
 // executeInsertNode handles INSERT using tree-walking pattern
+// The rows are inserted one by one in the statement's transaction; when one fails,
+// the caller reverts the rows already inserted.
 func executeInsertNode(node *plan.InsertNode, ctx *ExecutionContext) (*IntermediateResult, error) {
 	table, ok := ctx.Database.Tables[node.TableName]
 	if !ok {
 		return nil, newTableNotFoundError(node.TableName)
 	}
 
-	// Insert the row using domain model
-	if err := table.Insert(node.Row, ctx.Transaction); err != nil {
-		return nil, err
+	rows := node.Rows
+	if len(node.Children()) > 0 {
+		var err error
+		if rows, err = insertQueryRows(node.Children()[0], ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	// Insert the rows using domain model
+	tx, mark := statementLog(ctx, node.Returning)
+	for i, row := range rows {
+		if err := table.Insert(row, tx); err != nil {
+			if len(rows) > 1 {
+				return nil, fmt.Errorf("row %d: %w", i+1, err)
+			}
+			return nil, err
+		}
+	}
+
+	returned := []data.Row{}
+	if tx != nil {
+		var err error
+		if returned, err = returningRows(node.Returning, tx.Changes[mark:], false); err != nil {
+			return nil, err
+		}
 	}
 
 	return &IntermediateResult{
-		Rows:   []data.Row{},
-		Schema: nil,
+		Rows:   returned,
+		Schema: returningSchema(node.TableName, node.Returning),
 		Metadata: map[string]interface{}{
 			"operation":     "INSERT",
-			"rows_affected": 1,
+			"rows_affected": len(rows),
 		},
 	}, nil
 }
+
+// insertQueryRows runs the query of INSERT ... SELECT and converts its rows to the
+// types of the target columns
+func insertQueryRows(source plan.Node, ctx *ExecutionContext) ([]data.Row, error) {
+	result, err := executeNode(source, ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	rows := make([]data.Row, len(result.Rows))
+	for i, row := range result.Rows {
+		for _, col := range result.Schema.Columns {
+			value, err := types.ConvertValueToSchemaType(row.Data[col.Name], col.Type)
+			if err != nil {
+				return nil, fmt.Errorf("row %d: column '%s': %w", i+1, col.Name, err)
+			}
+			row.Data[col.Name] = value
+		}
+		rows[i] = row
+	}
+	return rows, nil
+}
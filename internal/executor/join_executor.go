@@ -18,22 +18,43 @@ func executeJoinNode(node *plan.JoinNode, ctx *ExecutionContext) (*IntermediateR
 		return nil, fmt.Errorf("left child execution failed: %w", err)
 	}
 
-	// Recursively execute right child
-	rightResult, err := executeNode(node.Right(), ctx)
-	if err != nil {
-		return nil, fmt.Errorf("right child execution failed: %w", err)
-	}
-
 	// Get table names from metadata (for qualified column names)
 	leftTableName := extractTableName(node.Left())
 	rightTableName := extractTableName(node.Right())
 
 	// Create temporary in-memory tables from child results using the propagated schema
 	leftTable := createTempTable(leftTableName, leftResult.Rows, leftResult.Schema)
-	rightTable := createTempTable(rightTableName, rightResult.Rows, rightResult.Schema)
+
+	// An unfiltered scan on the right joins against the stored table itself,
+	// so the join can probe its indexes instead of copying its rows
+	rightTable := baseTable(node.Right(), ctx)
+	var rightRows int
+	if rightTable != nil {
+		rightTable.RLock()
+		rightRows = len(rightTable.Rows)
+		rightTable.RUnlock()
+	} else {
+		// Recursively execute right child
+		rightResult, err := executeNode(node.Right(), ctx)
+		if err != nil {
+			return nil, fmt.Errorf("right child execution failed: %w", err)
+		}
+		rightTable = createTempTable(rightTableName, rightResult.Rows, rightResult.Schema)
+		rightRows = len(rightResult.Rows)
+	}
+
+	// The configured algorithm, if any, overrides the planner's choice
+	algorithm := node.Algorithm
+	if ctx.Config.JoinAlgorithm != "" {
+		algorithm, err = join.ParseAlgorithm(ctx.Config.JoinAlgorithm)
+		if err != nil {
+			return nil, err
+		}
+	}
 
 	// Execute JOIN using existing join operations
-	joinedRows, err := join.ExecuteJoin(
+	joinedRows, err := join.ExecuteJoinWithAlgorithm(
+		algorithm,
 		leftTable,
 		rightTable,
 		node.LeftOnCol,
@@ -74,10 +95,11 @@ func executeJoinNode(node *plan.JoinNode, ctx *ExecutionContext) (*IntermediateR
 		Rows:   rows,
 		Schema: joinedSchema,
 		Metadata: map[string]interface{}{
-			"join_type":   node.JoinType,
-			"left_rows":   len(leftResult.Rows),
-			"right_rows":  len(rightResult.Rows),
-			"result_rows": len(rows),
+			"join_type":      node.JoinType,
+			"join_algorithm": algorithm,
+			"left_rows":      len(leftResult.Rows),
+			"right_rows":     rightRows,
+			"result_rows":    len(rows),
 		},
 	}, nil
 }
@@ -89,6 +111,10 @@ func extractTableName(node plan.Node) string {
 		return n.TableName
 	case *plan.SelectNode:
 		return n.TableName
+	case *plan.SubqueryScanNode:
+		return n.Alias
+	case *plan.CTEScanNode:
+		return n.Name
 	case *plan.JoinNode:
 		// Recursive join names can be complex, use a placeholder
 		return fmt.Sprintf("join_%p", n)
@@ -97,6 +123,15 @@ func extractTableName(node plan.Node) string {
 	}
 }
 
+// baseTable returns the stored table read by an unfiltered ScanNode, or nil for any other node
+func baseTable(node plan.Node, ctx *ExecutionContext) *schema.Table {
+	scan, ok := node.(*plan.ScanNode)
+	if !ok || scan.Predicate != nil {
+		return nil
+	}
+	return ctx.Database.Tables[scan.TableName]
+}
+
 // createTempTable creates an in-memory table from rows and explicit schema
 func createTempTable(tableName string, rows []data.Row, tableSchema *schema.TableSchema) *schema.Table {
 	if tableSchema == nil {
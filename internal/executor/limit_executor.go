@@ -0,0 +1,70 @@
+package executor
+
+import (
+	"github.com/leengari/mini-rdbms/internal/domain/data"
+	"github.com/leengari/mini-rdbms/internal/plan"
+)
+
+// executeLimitNode skips the first Offset rows of the child and keeps at most Limit rows
+func executeLimitNode(node *plan.LimitNode, ctx *ExecutionContext) (*IntermediateResult, error) {
+	childResult, err := executeNode(node.Child(), ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	rows := childResult.Rows
+	if node.Offset >= len(rows) {
+		rows = []data.Row{}
+	} else {
+		rows = rows[node.Offset:]
+	}
+	if node.Limit >= 0 && node.Limit < len(rows) {
+		rows = rows[:node.Limit]
+	}
+
+	return &IntermediateResult{
+		Rows:   rows,
+		Schema: childResult.Schema,
+		Metadata: map[string]interface{}{
+			"limit":     node.Limit,
+			"offset":    node.Offset,
+			"row_count": len(rows),
+		},
+	}, nil
+}
+
+// executeFilterNode keeps the child's rows that match the node's predicate
+func executeFilterNode(node *plan.FilterNode, ctx *ExecutionContext) (*IntermediateResult, error) {
+	childResult, err := executeNode(node.Child(), ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := filterRows(childResult.Rows, outerPredicate(node.Predicate, ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	return &IntermediateResult{
+		Rows:   rows,
+		Schema: childResult.Schema,
+		Metadata: map[string]interface{}{
+			"row_count": len(rows),
+		},
+	}, nil
+}
+
+// filterRows returns the rows that match the predicate, stopping at the first error
+func filterRows(rows []data.Row, pred func(data.Row) (bool, error)) ([]data.Row, error) {
+	filtered := make([]data.Row, 0, len(rows))
+	for _, row := range rows {
+		match, err := pred(row)
+		if err != nil {
+			return nil, err
+		}
+		if match {
+			filtered = append(filtered, row)
+		}
+	}
+	return filtered, nil
+}
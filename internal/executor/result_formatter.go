@@ -3,6 +3,7 @@ package executor
 import (
 	"fmt"
 	"sort"
+	"strings"
 
 	"github.com/leengari/mini-rdbms/internal/domain/data"
 	"github.com/leengari/mini-rdbms/internal/domain/schema"
@@ -22,12 +23,9 @@ func formatResult(intermediate *IntermediateResult, columns []string, metadata [
 // formatInsertResult creates a Result for INSERT operations
 func formatInsertResult(intermediate *IntermediateResult) *Result {
 	rowsAffected, _ := intermediate.Metadata["rows_affected"].(int)
-	if rowsAffected == 0 {
-		rowsAffected = 1
-	}
 
 	return &Result{
-		Message:      "INSERT 1",
+		Message:      fmt.Sprintf("INSERT %d", rowsAffected),
 		RowsAffected: rowsAffected,
 	}
 }
@@ -52,6 +50,33 @@ func formatDeleteResult(intermediate *IntermediateResult) *Result {
 	}
 }
 
+// withReturning adds the rows returned by a RETURNING clause to an INSERT, UPDATE or
+// DELETE result, with column metadata as for a SELECT
+// Results of statements without RETURNING are left as they are.
+func withReturning(result *Result, columns []plan.ComputedColumn, intermediate *IntermediateResult) *Result {
+	if len(columns) == 0 {
+		return result
+	}
+
+	result.Columns = make([]string, len(columns))
+	result.Metadata = make([]ColumnMetadata, len(columns))
+	for i, col := range columns {
+		result.Columns[i] = col.Name
+		result.Metadata[i] = ColumnMetadata{Name: col.Name, Type: string(col.Type)}
+	}
+	result.Rows = intermediate.Rows
+	return result
+}
+
+// formatDDLResult creates a Result for CREATE TABLE / DROP TABLE operations
+func formatDDLResult(intermediate *IntermediateResult) *Result {
+	message, _ := intermediate.Metadata["message"].(string)
+
+	return &Result{
+		Message: message,
+	}
+}
+
 // formatSelectResult handles column and metadata calculation for SELECT queries
 func formatSelectResult(node *plan.SelectNode, intermediate *IntermediateResult, db *schema.Database) *Result {
 	var columns []string
@@ -63,7 +88,12 @@ func formatSelectResult(node *plan.SelectNode, intermediate *IntermediateResult,
 	table, hasTable := db.Tables[node.TableName]
 
 	if proj.SelectAll {
-		if hasTable && len(node.Children()) == 0 {
+		if !hasJoin(node) && intermediate.Schema != nil {
+			// The schema of the rows read: a derived table's or CTE's columns in SELECT-list
+			// order (a CTE may share its name with a stored table)
+			table, hasTable = &schema.Table{Schema: intermediate.Schema}, true
+		}
+		if hasTable && !hasJoin(node) {
 			// Simple SELECT *
 			for _, col := range table.Schema.Columns {
 				columns = append(columns, col.Name)
@@ -78,7 +108,7 @@ func formatSelectResult(node *plan.SelectNode, intermediate *IntermediateResult,
 			for _, colName := range columns {
 				metadata = append(metadata, ColumnMetadata{
 					Name: colName,
-					Type: "TEXT", // Default for complex results
+					Type: resolveColumnType(intermediate.Schema, "", colName),
 				})
 			}
 		}
@@ -93,17 +123,9 @@ func formatSelectResult(node *plan.SelectNode, intermediate *IntermediateResult,
 			}
 			columns = append(columns, colName)
 
-			// Try to find type if table is known
-			var colType = "TEXT"
-			if hasTable && colRef.Table == node.TableName {
-				for _, c := range table.Schema.Columns {
-					if c.Name == colRef.Column {
-						colType = string(c.Type)
-						break
-					}
-				}
-			}
-			
+			// Resolve the type from the schema of the rows being projected
+			colType := resolveColumnType(intermediate.Schema, colRef.Table, colRef.Column)
+
 			metadata = append(metadata, ColumnMetadata{
 				Name: colName,
 				Type: colType,
@@ -119,6 +141,43 @@ func formatSelectResult(node *plan.SelectNode, intermediate *IntermediateResult,
 	}
 }
 
+// resolveColumnType finds a column's type in the result schema
+// Accepts "table.column" or bare names (matching a qualified schema column by suffix).
+// Falls back to TEXT when the column is unknown.
+func resolveColumnType(resultSchema *schema.TableSchema, table, column string) string {
+	if resultSchema == nil {
+		return "TEXT"
+	}
+
+	name := column
+	if table != "" {
+		name = table + "." + column
+	}
+	for _, c := range resultSchema.Columns {
+		if c.Name == name {
+			return string(c.Type)
+		}
+	}
+	for _, c := range resultSchema.Columns {
+		if c.Name == column || strings.HasSuffix(c.Name, "."+name) {
+			return string(c.Type)
+		}
+	}
+	return "TEXT"
+}
+
+// hasJoin reports whether the plan tree below a node contains a JOIN
+func hasJoin(node plan.Node) bool {
+	found := false
+	plan.WalkTree(node, func(n plan.Node) error {
+		if _, ok := n.(*plan.JoinNode); ok {
+			found = true
+		}
+		return nil
+	})
+	return found
+}
+
 // extractColumnsFromRows extracts column names from rows
 // Used when columns aren't explicitly provided
 func extractColumnsFromRows(rows []data.Row) []string {
@@ -0,0 +1,64 @@
+package executor
+
+import (
+	"fmt"
+
+	"github.com/leengari/mini-rdbms/internal/domain/data"
+	"github.com/leengari/mini-rdbms/internal/domain/schema"
+	"github.com/leengari/mini-rdbms/internal/domain/transaction"
+	"github.com/leengari/mini-rdbms/internal/plan"
+)
+
+// statementLog returns the transaction an INSERT, UPDATE or DELETE records its changes in,
+// and the number of changes it already holds
+// The rows a statement modified are read back from its changes for RETURNING, so a
+// statement with RETURNING run outside a transaction records them in a throwaway one.
+func statementLog(ctx *ExecutionContext, returning []plan.ComputedColumn) (*transaction.Transaction, int) {
+	tx := ctx.Transaction
+	if tx == nil && len(returning) > 0 {
+		tx = transaction.NewTransaction()
+	}
+	if tx == nil {
+		return nil, 0
+	}
+	return tx, len(tx.Changes)
+}
+
+// returningRows evaluates the RETURNING fields over the rows a statement modified
+// Uses the new row of each change, or the old row when old is set (DELETE).
+func returningRows(columns []plan.ComputedColumn, changes []transaction.Change, old bool) ([]data.Row, error) {
+	if len(columns) == 0 {
+		return []data.Row{}, nil
+	}
+
+	rows := make([]data.Row, 0, len(changes))
+	for _, change := range changes {
+		image := data.NewRow(change.Data)
+		if old {
+			image = data.NewRow(change.OldData)
+		}
+
+		out := make(map[string]interface{}, len(columns))
+		for _, col := range columns {
+			value, err := col.Eval(image)
+			if err != nil {
+				return nil, fmt.Errorf("RETURNING %s: %w", col.Name, err)
+			}
+			out[col.Name] = value
+		}
+		rows = append(rows, data.NewRow(out))
+	}
+	return rows, nil
+}
+
+// returningSchema describes the rows returned by RETURNING (nil without RETURNING)
+func returningSchema(tableName string, columns []plan.ComputedColumn) *schema.TableSchema {
+	if len(columns) == 0 {
+		return nil
+	}
+	result := &schema.TableSchema{TableName: tableName}
+	for _, col := range columns {
+		result.Columns = append(result.Columns, schema.Column{Name: col.Name, Type: col.Type})
+	}
+	return result
+}
@@ -2,7 +2,9 @@ package executor
 
 import (
 	"github.com/leengari/mini-rdbms/internal/domain/data"
+	"github.com/leengari/mini-rdbms/internal/domain/schema"
 	"github.com/leengari/mini-rdbms/internal/plan"
+	"github.com/leengari/mini-rdbms/internal/util/types"
 )
 
 // executeScan executes a ScanNode (leaf operation)
@@ -17,7 +19,11 @@ func executeScan(node *plan.ScanNode, ctx *ExecutionContext) (*IntermediateResul
 	if node.Predicate == nil {
 		rows = table.SelectAll(ctx.Transaction)
 	} else {
-		rows = table.Select(node.Predicate, ctx.Transaction)
+		var err error
+		rows, err = table.Select(outerPredicate(node.Predicate, ctx), ctx.Transaction)
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	return &IntermediateResult{
@@ -30,3 +36,95 @@ func executeScan(node *plan.ScanNode, ctx *ExecutionContext) (*IntermediateResul
 		},
 	}, nil
 }
+
+// executeIndexScan executes an IndexScanNode (leaf operation)
+// Looks up row positions in the column's index (or reads the range of an ordered
+// index) instead of reading every row.
+// Falls back to a sequential scan when indexes are disabled in the config; rows the
+// plan needs in index order are then sorted into it.
+func executeIndexScan(node *plan.IndexScanNode, ctx *ExecutionContext) (*IntermediateResult, error) {
+	if !ctx.Config.UseIndexes {
+		result, err := executeScan(&plan.ScanNode{
+			TableName:   node.TableName,
+			Predicate:   node.Predicate,
+			Transaction: node.Transaction,
+		}, ctx)
+		if err == nil && node.Ordered {
+			ctx.Database.Tables[node.TableName].SortByIndex(result.Rows, node.Index, node.Descending)
+		}
+		return result, err
+	}
+
+	table, ok := ctx.Database.Tables[node.TableName]
+	if !ok {
+		return nil, newTableNotFoundError(node.TableName)
+	}
+
+	if node.Index != "" {
+		rows, err := table.SelectOrdered(orderedScan(node), outerPredicate(node.Predicate, ctx), ctx.Transaction)
+		if err != nil {
+			return nil, err
+		}
+		return &IntermediateResult{
+			Rows:   rows,
+			Schema: table.Schema,
+			Metadata: map[string]interface{}{
+				"table":     node.TableName,
+				"scan_type": "index",
+				"index":     node.Index,
+				"row_count": len(rows),
+			},
+		}, nil
+	}
+
+	rows, err := table.SelectIndexed(node.Column, indexLookup(node), outerPredicate(node.Predicate, ctx), ctx.Transaction)
+	if err != nil {
+		return nil, err
+	}
+
+	return &IntermediateResult{
+		Rows:   rows,
+		Schema: table.Schema,
+		Metadata: map[string]interface{}{
+			"table":        node.TableName,
+			"scan_type":    "index",
+			"index_column": node.Column,
+			"row_count":    len(rows),
+		},
+	}, nil
+}
+
+// orderedScan converts an IndexScanNode over an ordered index into a table scan request
+func orderedScan(node *plan.IndexScanNode) schema.OrderedScan {
+	return schema.OrderedScan{
+		Index:      node.Index,
+		Range:      node.IndexRange,
+		Descending: node.Descending,
+		Ordered:    node.Ordered,
+	}
+}
+
+// indexLookup converts an IndexScanNode's keys into a table index lookup
+// Range bounds are compared like WHERE values, so INT and FLOAT keys mix freely.
+func indexLookup(node *plan.IndexScanNode) schema.IndexLookup {
+	if node.Range == nil {
+		return schema.IndexLookup{Values: node.Values}
+	}
+
+	low, high := node.Range.Low, node.Range.High
+	return schema.IndexLookup{
+		InRange: func(key interface{}) bool {
+			return types.CompareValues(key, ">=", low) && types.CompareValues(key, "<=", high)
+		},
+	}
+}
+
+// indexScanChild returns the node's IndexScanNode child, or nil if it reads sequentially
+func indexScanChild(node plan.Node) *plan.IndexScanNode {
+	for _, child := range node.Children() {
+		if indexScan, ok := child.(*plan.IndexScanNode); ok {
+			return indexScan
+		}
+	}
+	return nil
+}
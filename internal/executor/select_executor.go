@@ -38,11 +38,9 @@ func executeSelectNode(node *plan.SelectNode, ctx *ExecutionContext) (*Intermedi
 
 	// Apply predicate
 	if node.Predicate != nil {
-		filteredRows := make([]data.Row, 0)
-		for _, row := range rows {
-			if node.Predicate(row) {
-				filteredRows = append(filteredRows, row)
-			}
+		filteredRows, err := filterRows(rows, outerPredicate(node.Predicate, ctx))
+		if err != nil {
+			return nil, err
 		}
 		rows = filteredRows
 	}
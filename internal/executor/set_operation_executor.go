@@ -0,0 +1,81 @@
+package executor
+
+import (
+	"fmt"
+
+	"github.com/leengari/mini-rdbms/internal/domain/data"
+	"github.com/leengari/mini-rdbms/internal/domain/schema"
+	"github.com/leengari/mini-rdbms/internal/plan"
+)
+
+// executeSetOperation executes a SetOperationNode, combining the rows of its two queries
+// Rows are compared by value across all columns, with NULLs equal to each other. The
+// left query's rows keep their order, followed by the right query's for UNION.
+func executeSetOperation(node *plan.SetOperationNode, ctx *ExecutionContext) (*IntermediateResult, error) {
+	leftResult, err := executeNode(node.Left(), ctx)
+	if err != nil {
+		return nil, err
+	}
+	rightResult, err := executeNode(node.Right(), ctx)
+	if err != nil {
+		return nil, err
+	}
+	left := renameColumns(leftResult.Rows, node.LeftKeys, node.Columns)
+	right := renameColumns(rightResult.Rows, node.RightKeys, node.Columns)
+
+	var rows []data.Row
+	switch node.Operator {
+	case plan.SetUnion:
+		rows = append(left, right...)
+	case plan.SetIntersect, plan.SetExcept:
+		// With ALL each right row matches one left row, so duplicates are kept
+		// min(m, n) times by INTERSECT ALL and max(m-n, 0) times by EXCEPT ALL
+		counts := make(map[string]int, len(right))
+		for _, row := range right {
+			counts[rowKey(row, node.Columns)]++
+		}
+		keep := node.Operator == plan.SetIntersect
+		rows = make([]data.Row, 0, len(left))
+		for _, row := range left {
+			key := rowKey(row, node.Columns)
+			matched := counts[key] > 0
+			if matched && node.All {
+				counts[key]--
+			}
+			if matched == keep {
+				rows = append(rows, row)
+			}
+		}
+	default:
+		return nil, fmt.Errorf("unsupported set operation: %s", node.Operator)
+	}
+
+	if !node.All {
+		rows = distinctRows(rows, node.Columns)
+	}
+
+	return &IntermediateResult{
+		Rows:   rows,
+		Schema: &schema.TableSchema{Columns: node.Columns},
+		Metadata: map[string]interface{}{
+			"operation":  node.NodeType(),
+			"left_rows":  len(left),
+			"right_rows": len(right),
+			"row_count":  len(rows),
+		},
+	}, nil
+}
+
+// distinctRows returns the first row of each set of rows with equal values in columns
+func distinctRows(rows []data.Row, columns []schema.Column) []data.Row {
+	seen := make(map[string]bool, len(rows))
+	distinct := make([]data.Row, 0, len(rows))
+	for _, row := range rows {
+		key := rowKey(row, columns)
+		if !seen[key] {
+			seen[key] = true
+			distinct = append(distinct, row)
+		}
+	}
+	return distinct
+}
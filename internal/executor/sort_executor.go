@@ -0,0 +1,108 @@
+package executor
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/leengari/mini-rdbms/internal/domain/data"
+	"github.com/leengari/mini-rdbms/internal/plan"
+	"github.com/leengari/mini-rdbms/internal/util/types"
+)
+
+// executeSortNode orders the child's rows by the node's keys
+// The sort is stable, so rows with equal keys keep their storage order.
+func executeSortNode(node *plan.SortNode, ctx *ExecutionContext) (*IntermediateResult, error) {
+	childResult, err := executeNode(node.Child(), ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	rows := make([]data.Row, len(childResult.Rows))
+	copy(rows, childResult.Rows)
+
+	sort.SliceStable(rows, func(i, j int) bool {
+		for _, key := range node.Keys {
+			if c := compareSortKey(rows[i], rows[j], key); c != 0 {
+				return c < 0
+			}
+		}
+		return false
+	})
+
+	return &IntermediateResult{
+		Rows:   rows,
+		Schema: childResult.Schema,
+		Metadata: map[string]interface{}{
+			"sort_keys": len(node.Keys),
+			"row_count": len(rows),
+		},
+	}, nil
+}
+
+// compareSortKey compares two rows on a single key, honoring direction and NULL placement
+// Returns -1 if a sorts before b, 1 if after, 0 if equal
+func compareSortKey(a, b data.Row, key plan.SortKey) int {
+	va := lookupColumn(a, key.Table, key.Column)
+	vb := lookupColumn(b, key.Table, key.Column)
+
+	// NULL placement is independent of direction
+	switch {
+	case va == nil && vb == nil:
+		return 0
+	case va == nil:
+		if key.NullsFirst {
+			return -1
+		}
+		return 1
+	case vb == nil:
+		if key.NullsFirst {
+			return 1
+		}
+		return -1
+	}
+
+	c := 0
+	if types.CompareValues(va, "<", vb) {
+		c = -1
+	} else if types.CompareValues(va, ">", vb) {
+		c = 1
+	}
+	if key.Descending {
+		c = -c
+	}
+	return c
+}
+
+// lookupColumn returns the value of a (possibly qualified) column in a row
+// Joined rows key columns as "table.column" (nested joins add a further prefix), plain
+// scans key them as "column"; both forms are accepted. Missing columns are treated as NULL.
+func lookupColumn(row data.Row, table, column string) interface{} {
+	if table != "" {
+		qualified := table + "." + column
+		if val, ok := row.Data[qualified]; ok {
+			return val
+		}
+		if val, ok := findBySuffix(row, "."+qualified); ok {
+			return val
+		}
+	}
+	if val, ok := row.Data[column]; ok {
+		return val
+	}
+	if table == "" {
+		if val, ok := findBySuffix(row, "."+column); ok {
+			return val
+		}
+	}
+	return nil
+}
+
+// findBySuffix returns the value of the first column whose name ends with suffix
+func findBySuffix(row data.Row, suffix string) (interface{}, bool) {
+	for key, val := range row.Data {
+		if strings.HasSuffix(key, suffix) {
+			return val, true
+		}
+	}
+	return nil, false
+}
@@ -1,9 +1,11 @@
 package executor
 
 import (
+	"github.com/leengari/mini-rdbms/internal/domain/data"
 	"github.com/leengari/mini-rdbms/internal/domain/schema"
 	"github.com/leengari/mini-rdbms/internal/domain/transaction"
 	"github.com/leengari/mini-rdbms/internal/plan"
+	"github.com/leengari/mini-rdbms/internal/storage/engine"
 )
 
 // ExecutionStrategy defines how a plan node is executed
@@ -17,23 +19,44 @@ type ExecutionContext struct {
 	Database    *schema.Database
 	Transaction *transaction.Transaction
 	Config      *ExecutionConfig
+	// Storage persists DDL changes (CREATE/DROP TABLE). If nil, DDL only
+	// affects the in-memory database.
+	Storage engine.StorageEngine
+	// Outer is the row of the enclosing query while a correlated subquery runs,
+	// with qualified column names; the subquery's conditions see its columns.
+	Outer data.Row
+}
+
+// NewExecutionContext creates an execution context with default configuration
+func NewExecutionContext(db *schema.Database, tx *transaction.Transaction, storage engine.StorageEngine) *ExecutionContext {
+	return &ExecutionContext{
+		Database:    db,
+		Transaction: tx,
+		Config:      DefaultExecutionConfig(),
+		Storage:     storage,
+	}
 }
 
 // ExecutionConfig holds execution parameters
 type ExecutionConfig struct {
 	UseIndexes    bool
 	ParallelScans bool
-	JoinAlgorithm string // "hash", "nested_loop", "merge"
+	JoinAlgorithm string // "hash", "nested_loop", "merge"; empty uses the planner's choice
 	BufferSize    int
+	MaxRecursion  int // Iterations a recursive CTE may run before failing; 0 uses the default
 }
 
+// DefaultMaxRecursion is the iteration cap for recursive CTEs when the config sets none
+const DefaultMaxRecursion = 1000
+
 // DefaultExecutionConfig returns default configuration
 func DefaultExecutionConfig() *ExecutionConfig {
 	return &ExecutionConfig{
-		UseIndexes:    false, // Scaffold: always false
+		UseIndexes:    true,
 		ParallelScans: false,
-		JoinAlgorithm: "nested_loop", // Scaffold: always nested loop
+		JoinAlgorithm: "",
 		BufferSize:    4096,
+		MaxRecursion:  DefaultMaxRecursion,
 	}
 }
 
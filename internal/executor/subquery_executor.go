@@ -0,0 +1,135 @@
+package executor
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/leengari/mini-rdbms/internal/domain/data"
+	"github.com/leengari/mini-rdbms/internal/domain/schema"
+	"github.com/leengari/mini-rdbms/internal/plan"
+)
+
+// bindSubqueries binds every subquery and CTE in a plan tree (and in its subqueries) to ctx
+// Uncorrelated subqueries run here, before the statement reads or locks any table,
+// so their rows are ready when the statement's predicates are evaluated. CTEs run
+// when first read.
+func bindSubqueries(node plan.Node, ctx *ExecutionContext) error {
+	return plan.WalkTree(node, func(n plan.Node) error {
+		var subqueries []*plan.Subquery
+		switch n := n.(type) {
+		case *plan.SelectNode:
+			if err := bindCommonTables(n.CommonTables, ctx); err != nil {
+				return err
+			}
+			subqueries = n.Subqueries
+		case *plan.UpdateNode:
+			subqueries = n.Subqueries
+		case *plan.DeleteNode:
+			subqueries = n.Subqueries
+		}
+
+		for _, sub := range subqueries {
+			sub.Bind(subqueryRunner(sub, ctx))
+			if err := bindSubqueries(sub.Root, ctx); err != nil {
+				return err
+			}
+			if !sub.Correlated {
+				if _, err := sub.Rows(data.Row{}); err != nil {
+					return fmt.Errorf("subquery failed: %w", err)
+				}
+			}
+		}
+		return nil
+	})
+}
+
+// subqueryRunner returns the function that executes a subquery for an outer row
+// A correlated subquery sees the outer row's columns under qualified names.
+func subqueryRunner(sub *plan.Subquery, ctx *ExecutionContext) func(data.Row) ([]data.Row, error) {
+	return func(outer data.Row) ([]data.Row, error) {
+		subCtx := *ctx
+		subCtx.Outer = data.Row{}
+		if sub.Correlated {
+			subCtx.Outer = qualifyRow(outer, sub.OuterTable)
+		}
+
+		result, err := executeNode(sub.Root, &subCtx)
+		if err != nil {
+			return nil, err
+		}
+		return renameColumns(result.Rows, sub.Keys, sub.Columns), nil
+	}
+}
+
+// executeSubqueryScan executes a SubqueryScanNode, reading a derived table
+// The child's projected rows are renamed to the derived table's column names.
+func executeSubqueryScan(node *plan.SubqueryScanNode, ctx *ExecutionContext) (*IntermediateResult, error) {
+	childResult, err := executeNode(node.Child(), ctx)
+	if err != nil {
+		return nil, err
+	}
+	rows := renameColumns(childResult.Rows, node.Keys, node.Columns)
+
+	return &IntermediateResult{
+		Rows:   rows,
+		Schema: &schema.TableSchema{TableName: node.Alias, Columns: node.Columns},
+		Metadata: map[string]interface{}{
+			"table":     node.Alias,
+			"scan_type": "subquery",
+			"row_count": len(rows),
+		},
+	}, nil
+}
+
+// qualifyRow copies a row, qualifying its bare column names with tableName
+// Names that are already qualified (e.g. from a JOIN) are kept as they are.
+func qualifyRow(row data.Row, tableName string) data.Row {
+	qualified := make(map[string]interface{}, len(row.Data))
+	for key, val := range row.Data {
+		if !strings.Contains(key, ".") {
+			key = tableName + "." + key
+		}
+		qualified[key] = val
+	}
+	return data.NewRow(qualified)
+}
+
+// renameColumns returns rows holding the values at keys under the names of columns
+func renameColumns(rows []data.Row, keys []string, columns []schema.Column) []data.Row {
+	renamed := make([]data.Row, len(rows))
+	for i, row := range rows {
+		out := make(map[string]interface{}, len(columns))
+		for j, col := range columns {
+			out[col.Name] = row.Data[keys[j]]
+		}
+		renamed[i] = data.NewRow(out)
+	}
+	return renamed
+}
+
+// withOuter returns a row with the columns of the enclosing query's row added,
+// so conditions in a correlated subquery can reference them
+// The row is returned unchanged outside correlated subqueries.
+func withOuter(row data.Row, ctx *ExecutionContext) data.Row {
+	if len(ctx.Outer.Data) == 0 {
+		return row
+	}
+	merged := make(map[string]interface{}, len(row.Data)+len(ctx.Outer.Data))
+	for key, val := range ctx.Outer.Data {
+		merged[key] = val
+	}
+	for key, val := range row.Data {
+		merged[key] = val
+	}
+	return data.NewRow(merged)
+}
+
+// outerPredicate wraps a predicate to evaluate it with the outer row's columns (see withOuter)
+func outerPredicate(pred func(data.Row) (bool, error), ctx *ExecutionContext) func(data.Row) (bool, error) {
+	if pred == nil || len(ctx.Outer.Data) == 0 {
+		return pred
+	}
+	return func(row data.Row) (bool, error) {
+		return pred(withOuter(row, ctx))
+	}
+}
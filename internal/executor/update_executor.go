@@ -12,15 +12,33 @@ func executeUpdateNode(node *plan.UpdateNode, ctx *ExecutionContext) (*Intermedi
 		return nil, newTableNotFoundError(node.TableName)
 	}
 
-	// Use domain model to update
-	rowsAffected, err := table.Update(node.Predicate, node.Updates, ctx.Transaction)
+	// Use domain model to update, through the index when the plan has an index scan
+	tx, mark := statementLog(ctx, node.Returning)
+	var rowsAffected int
+	var err error
+	indexScan := indexScanChild(node)
+	switch {
+	case indexScan == nil || !ctx.Config.UseIndexes:
+		rowsAffected, err = table.UpdateWith(node.Predicate, node.Updates, tx)
+	case indexScan.Index != "":
+		rowsAffected, err = table.UpdateOrdered(orderedScan(indexScan), node.Predicate, node.Updates, tx)
+	default:
+		rowsAffected, err = table.UpdateIndexed(indexScan.Column, indexLookup(indexScan), node.Predicate, node.Updates, tx)
+	}
 	if err != nil {
 		return nil, err
 	}
 
+	returned := []data.Row{}
+	if tx != nil {
+		if returned, err = returningRows(node.Returning, tx.Changes[mark:], false); err != nil {
+			return nil, err
+		}
+	}
+
 	return &IntermediateResult{
-		Rows:   []data.Row{},
-		Schema: nil,
+		Rows:   returned,
+		Schema: returningSchema(node.TableName, node.Returning),
 		Metadata: map[string]interface{}{
 			"operation":     "UPDATE",
 			"rows_affected": rowsAffected,
@@ -0,0 +1,260 @@
+package executor
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/leengari/mini-rdbms/internal/domain/data"
+	"github.com/leengari/mini-rdbms/internal/domain/schema"
+	"github.com/leengari/mini-rdbms/internal/plan"
+	"github.com/leengari/mini-rdbms/internal/util/types"
+)
+
+// executeWindowNode computes the node's window functions for every row of the child
+// Each output row is a copy of the input row with the results added under their names;
+// rows keep the child's order.
+func executeWindowNode(node *plan.WindowNode, ctx *ExecutionContext) (*IntermediateResult, error) {
+	childResult, err := executeNode(node.Child(), ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	rows := make([]data.Row, len(childResult.Rows))
+	for i, row := range childResult.Rows {
+		rows[i] = row.Copy()
+	}
+
+	partitions := 0
+	for _, fn := range node.Functions {
+		groups := partitionRows(childResult.Rows, fn)
+		for _, indexes := range groups {
+			if err := computeWindow(fn, childResult.Rows, indexes, rows); err != nil {
+				return nil, err
+			}
+		}
+		partitions += len(groups)
+	}
+
+	return &IntermediateResult{
+		Rows:   rows,
+		Schema: windowSchema(childResult.Schema, node.Functions),
+		Metadata: map[string]interface{}{
+			"window_functions": len(node.Functions),
+			"partitions":       partitions,
+			"row_count":        len(rows),
+		},
+	}, nil
+}
+
+// partitionRows splits row indexes into the function's partitions, in order of first
+// appearance, and orders each partition by the function's ORDER BY keys
+// The sort is stable, so peers keep their storage order.
+func partitionRows(rows []data.Row, fn plan.WindowFunction) [][]int {
+	var partitions [][]int
+	byKey := make(map[string]int)
+	for i, row := range rows {
+		keyParts := make([]string, len(fn.PartitionBy))
+		for j, key := range fn.PartitionBy {
+			keyParts[j] = valueKey(lookupColumn(row, key.Table, key.Column))
+		}
+		partitionKey := strings.Join(keyParts, "\x00")
+
+		p, ok := byKey[partitionKey]
+		if !ok {
+			p = len(partitions)
+			byKey[partitionKey] = p
+			partitions = append(partitions, nil)
+		}
+		partitions[p] = append(partitions[p], i)
+	}
+
+	for _, indexes := range partitions {
+		sort.SliceStable(indexes, func(a, b int) bool {
+			return compareWindowOrder(rows[indexes[a]], rows[indexes[b]], fn.OrderBy) < 0
+		})
+	}
+	return partitions
+}
+
+// compareWindowOrder compares two rows on all ORDER BY keys of a window
+// Rows comparing equal are peers.
+func compareWindowOrder(a, b data.Row, keys []plan.SortKey) int {
+	for _, key := range keys {
+		if c := compareSortKey(a, b, key); c != 0 {
+			return c
+		}
+	}
+	return 0
+}
+
+// computeWindow computes a window function over one ordered partition of rows,
+// storing each row's result in the matching output row
+// Returns the error of an argument that fails to evaluate.
+func computeWindow(fn plan.WindowFunction, rows []data.Row, partition []int, out []data.Row) error {
+	value := func(pos int) (interface{}, error) {
+		val, err := fn.Arg(rows[partition[pos]])
+		if err != nil {
+			return nil, fmt.Errorf("failed to compute %s: %w", fn.Name, err)
+		}
+		return normalizeAggregateValue(val, fn.ArgType), nil
+	}
+	peers := func(a, b int) bool {
+		return compareWindowOrder(rows[partition[a]], rows[partition[b]], fn.OrderBy) == 0
+	}
+
+	var running *aggregateState // Aggregate over a frame that starts at the partition's first row
+	runningEnd := -1
+	rank := int64(0)
+	denseRank := int64(0)
+
+	for pos, index := range partition {
+		var result interface{}
+		switch fn.Func {
+		case "ROW_NUMBER":
+			result = int64(pos + 1)
+
+		case "RANK", "DENSE_RANK":
+			if pos == 0 || !peers(pos-1, pos) {
+				rank = int64(pos + 1)
+				denseRank++
+			}
+			result = rank
+			if fn.Func == "DENSE_RANK" {
+				result = denseRank
+			}
+
+		case "LAG", "LEAD":
+			target := pos - fn.Offset
+			if fn.Func == "LEAD" {
+				target = pos + fn.Offset
+			}
+			result = fn.Default
+			if target >= 0 && target < len(partition) {
+				val, err := value(target)
+				if err != nil {
+					return err
+				}
+				result = val
+			}
+
+		case "FIRST_VALUE", "LAST_VALUE":
+			start, end := windowFrame(fn, rows, partition, pos)
+			if start <= end {
+				at := start
+				if fn.Func == "LAST_VALUE" {
+					at = end
+				}
+				val, err := value(at)
+				if err != nil {
+					return err
+				}
+				result = val
+			}
+
+		default:
+			spec := plan.AggregateSpec{Func: fn.Func, Arg: fn.Arg, Name: fn.Name, ArgType: fn.ArgType}
+			start, end := windowFrame(fn, rows, partition, pos)
+
+			// Frames that start at the first row only grow, so their aggregate is kept
+			// and extended instead of recomputed for every row
+			state := &aggregateState{spec: spec}
+			if fn.Frame.Start.Unbounded {
+				if running == nil {
+					running = state
+				}
+				state = running
+			}
+			from := start
+			if state == running {
+				from = runningEnd + 1
+			}
+			for i := from; i <= end; i++ {
+				if err := state.add(rows[partition[i]]); err != nil {
+					return err
+				}
+			}
+			if state == running && end > runningEnd {
+				runningEnd = end
+			}
+			result = state.result()
+		}
+		out[index].Data[fn.Name] = result
+	}
+	return nil
+}
+
+// windowFrame returns the first and last position in the partition of the current
+// row's frame (start > end for an empty frame)
+func windowFrame(fn plan.WindowFunction, rows []data.Row, partition []int, pos int) (int, int) {
+	frame := fn.Frame
+	last := len(partition) - 1
+
+	if !frame.Range {
+		start, end := 0, last
+		if !frame.Start.Unbounded {
+			start = max(pos+frame.Start.Offset, 0)
+		}
+		if !frame.End.Unbounded {
+			end = min(pos+frame.End.Offset, last)
+		}
+		return start, end
+	}
+
+	// RANGE: the partition is ordered, so the rows at or after the start bound, and
+	// the rows at or before the end bound, are contiguous
+	current := rows[partition[pos]]
+	distance := func(i int) float64 {
+		return rangeDistance(rows[partition[i]], current, fn.OrderBy)
+	}
+	start, end := 0, last
+	if !frame.Start.Unbounded {
+		for start <= last && distance(start) < float64(frame.Start.Offset) {
+			start++
+		}
+	}
+	if !frame.End.Unbounded {
+		for end >= 0 && distance(end) > float64(frame.End.Offset) {
+			end--
+		}
+	}
+	return start, end
+}
+
+// rangeDistance returns how far row's ORDER BY value lies after current's, in sort order
+// Peers are at distance 0. With a single numeric key the distance is the difference of
+// the values; otherwise (and for NULLs, which are peers only of each other) rows before
+// or after the current row are infinitely far.
+func rangeDistance(row, current data.Row, keys []plan.SortKey) float64 {
+	c := compareWindowOrder(row, current, keys)
+	if c == 0 {
+		return 0
+	}
+	if len(keys) == 1 {
+		key := keys[0]
+		a, aok := types.NormalizeToFloat(lookupColumn(row, key.Table, key.Column))
+		b, bok := types.NormalizeToFloat(lookupColumn(current, key.Table, key.Column))
+		if aok && bok {
+			if key.Descending {
+				return b - a
+			}
+			return a - b
+		}
+	}
+	return math.Inf(c)
+}
+
+// windowSchema extends the child's schema with the window function results
+// Results come first so an alias that shadows a source column resolves to the result's type.
+func windowSchema(childSchema *schema.TableSchema, functions []plan.WindowFunction) *schema.TableSchema {
+	result := &schema.TableSchema{}
+	for _, fn := range functions {
+		result.Columns = append(result.Columns, schema.Column{Name: fn.Name, Type: fn.Type})
+	}
+	if childSchema != nil {
+		result.TableName = childSchema.TableName
+		result.Columns = append(result.Columns, childSchema.Columns...)
+	}
+	return result
+}
@@ -0,0 +1,175 @@
+package integration
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/leengari/mini-rdbms/internal/engine"
+	"github.com/leengari/mini-rdbms/internal/query/indexing"
+	"github.com/leengari/mini-rdbms/internal/storage/loader"
+)
+
+// TestSQLAggregates tests aggregate functions, GROUP BY and HAVING end-to-end via SQL
+func TestSQLAggregates(t *testing.T) {
+	// Load test database
+	db, err := loader.LoadDatabase("../../databases/testdb")
+	if err != nil {
+		t.Fatalf("Failed to load database: %v", err)
+	}
+
+	// Build indexes
+	if err := indexing.BuildDatabaseIndexes(db); err != nil {
+		t.Fatalf("Failed to build indexes: %v", err)
+	}
+
+	eng := engine.New(db, nil)
+
+	// rowsAsStrings renders each result row as "v1|v2|..." in column order
+	rowsAsStrings := func(t *testing.T, sql string) []string {
+		t.Helper()
+		result, err := eng.Execute(sql)
+		if err != nil {
+			t.Fatalf("%s failed: %v", sql, err)
+		}
+		out := make([]string, len(result.Rows))
+		for i, row := range result.Rows {
+			parts := make([]string, len(result.Columns))
+			for j, col := range result.Columns {
+				parts[j] = fmt.Sprint(row.Data[col])
+			}
+			out[i] = strings.Join(parts, "|")
+		}
+		return out
+	}
+
+	tests := []struct {
+		name     string
+		sql      string
+		expected []string
+	}{
+		{
+			name:     "COUNT(*)",
+			sql:      "SELECT COUNT(*) FROM users",
+			expected: []string{"5"},
+		},
+		{
+			name:     "COUNT(col) skips NULLs",
+			sql:      "SELECT COUNT(is_active) FROM users",
+			expected: []string{"4"},
+		},
+		{
+			name:     "COUNT(DISTINCT col)",
+			sql:      "SELECT COUNT(DISTINCT user_id) FROM orders",
+			expected: []string{"3"},
+		},
+		{
+			name:     "SUM of INT column stays integral",
+			sql:      "SELECT SUM(id), MIN(product), MAX(product) FROM orders",
+			expected: []string{"10|keyboard|mouse"},
+		},
+		{
+			name:     "GROUP BY with ORDER BY",
+			sql:      "SELECT user_id, COUNT(*), MAX(amount) FROM orders GROUP BY user_id ORDER BY user_id DESC",
+			expected: []string{"6|1|299.99", "5|1|79.99", "2|2|999.99"},
+		},
+		{
+			name:     "HAVING on an aggregate not in the select list",
+			sql:      "SELECT user_id FROM orders GROUP BY user_id HAVING COUNT(*) > 1",
+			expected: []string{"2"},
+		},
+		{
+			name:     "HAVING combined with WHERE",
+			sql:      "SELECT user_id, COUNT(*) FROM orders WHERE amount < 500 GROUP BY user_id HAVING user_id >= 5 ORDER BY user_id",
+			expected: []string{"5|1", "6|1"},
+		},
+		{
+			name:     "GROUP BY over JOIN ordered by aggregate",
+			sql:      "SELECT users.username, COUNT(orders.id) FROM users JOIN orders ON users.id = orders.user_id GROUP BY users.username ORDER BY COUNT(orders.id) DESC, users.username",
+			expected: []string{"bob|2", "eve|1", "frank|1"},
+		},
+		{
+			name:     "Aggregates over no rows",
+			sql:      "SELECT COUNT(*), SUM(amount), AVG(amount) FROM orders WHERE amount > 5000",
+			expected: []string{"0|<nil>|<nil>"},
+		},
+		{
+			name:     "Aggregates over expressions",
+			sql:      "SELECT SUM(id * 2), MAX(UPPER(product)), COUNT(DISTINCT user_id % 2) FROM orders",
+			expected: []string{"20|MOUSE|2"},
+		},
+		{
+			name:     "HAVING on an aggregate over an expression",
+			sql:      "SELECT user_id FROM orders GROUP BY user_id HAVING SUM(amount * 2) > 1000 ORDER BY user_id",
+			expected: []string{"2"},
+		},
+		{
+			name:     "GROUP BY over no rows",
+			sql:      "SELECT user_id, COUNT(*) FROM orders WHERE amount > 5000 GROUP BY user_id",
+			expected: []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := rowsAsStrings(t, tt.sql)
+			if fmt.Sprint(got) != fmt.Sprint(tt.expected) {
+				t.Errorf("Expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+
+	t.Run("AVG", func(t *testing.T) {
+		result, err := eng.Execute("SELECT AVG(amount) FROM orders WHERE user_id = 2")
+		if err != nil {
+			t.Fatalf("Select failed: %v", err)
+		}
+		avg, ok := result.Rows[0].Data["AVG(amount)"].(float64)
+		if !ok || avg < 514.98 || avg > 514.995 {
+			t.Errorf("Expected AVG 514.99, got %v", result.Rows[0].Data["AVG(amount)"])
+		}
+	})
+
+	t.Run("Result metadata types", func(t *testing.T) {
+		result, err := eng.Execute("SELECT user_id, COUNT(*), SUM(amount), AVG(user_id), MIN(product) FROM orders GROUP BY user_id")
+		if err != nil {
+			t.Fatalf("Select failed: %v", err)
+		}
+		expected := []struct{ Name, Type string }{
+			{"user_id", "INT"},
+			{"COUNT(*)", "INT"},
+			{"SUM(amount)", "FLOAT"},
+			{"AVG(user_id)", "FLOAT"},
+			{"MIN(product)", "TEXT"},
+		}
+		if len(result.Metadata) != len(expected) {
+			t.Fatalf("Expected %d columns, got %d", len(expected), len(result.Metadata))
+		}
+		for i, want := range expected {
+			got := result.Metadata[i]
+			if got.Name != want.Name || got.Type != want.Type {
+				t.Errorf("Column %d: expected %s %s, got %s %s", i, want.Name, want.Type, got.Name, got.Type)
+			}
+		}
+	})
+
+	t.Run("Invalid aggregate queries", func(t *testing.T) {
+		invalid := []string{
+			"SELECT username, COUNT(*) FROM users",
+			"SELECT * FROM users GROUP BY id",
+			"SELECT SUM(username) FROM users",
+			"SELECT MEDIAN(id) FROM users",
+			"SELECT SUM(*) FROM users",
+			"SELECT COUNT(missing) FROM users",
+			"SELECT SUM(username || 'x') FROM users",
+			"SELECT SUM(COUNT(*)) FROM users",
+			"SELECT MAX(id / 0) FROM users",
+			"SELECT user_id FROM orders GROUP BY user_id ORDER BY amount",
+		}
+		for _, sql := range invalid {
+			if _, err := eng.Execute(sql); err == nil {
+				t.Errorf("Expected error for %q", sql)
+			}
+		}
+	})
+}
@@ -0,0 +1,162 @@
+package integration
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/leengari/mini-rdbms/internal/engine"
+	storageEngine "github.com/leengari/mini-rdbms/internal/storage/engine"
+	"github.com/leengari/mini-rdbms/internal/storage/manager"
+	"github.com/leengari/mini-rdbms/internal/storage/wal"
+)
+
+// checkpointRecorder forwards the end event of every checkpoint to a channel
+type checkpointRecorder struct {
+	ends chan manager.CheckpointEvent
+}
+
+func (c *checkpointRecorder) OnCheckpoint(event manager.CheckpointEvent) {
+	if event.Type == manager.EventCheckpointEnd {
+		c.ends <- event
+	}
+}
+
+// next waits for the next completed checkpoint
+func (c *checkpointRecorder) next(t *testing.T) manager.CheckpointEvent {
+	t.Helper()
+	select {
+	case event := <-c.ends:
+		if event.Err != nil {
+			t.Fatalf("Checkpoint failed: %v", event.Err)
+		}
+		return event
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for a checkpoint")
+		return manager.CheckpointEvent{}
+	}
+}
+
+func TestCheckpoint(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "rdbms_checkpoint_test")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	walPath := filepath.Join(tmpDir, "shop", wal.FileName)
+	walSize := func() int64 {
+		info, err := os.Stat(walPath)
+		if err != nil {
+			t.Fatalf("Failed to stat wal: %v", err)
+		}
+		return info.Size()
+	}
+
+	registry := manager.NewRegistry(tmpDir, storageEngine.NewJSONEngine())
+	recorder := &checkpointRecorder{ends: make(chan manager.CheckpointEvent, 16)}
+	registry.AddObserver(recorder)
+	eng := engine.New(nil, registry)
+	for _, sql := range []string{
+		"CREATE DATABASE shop",
+		"USE shop",
+		"CREATE TABLE items (id INT PRIMARY KEY AUTO_INCREMENT, name TEXT NOT NULL, qty INT)",
+		"CREATE TABLE tags (label TEXT)",
+		"INSERT INTO items (name, qty) VALUES ('apple', 5), ('pear', 2)",
+	} {
+		if _, err := eng.Execute(sql); err != nil {
+			t.Fatalf("%s failed: %v", sql, err)
+		}
+	}
+
+	t.Run("Command writes only dirty tables", func(t *testing.T) {
+		res, err := eng.Execute("CHECKPOINT")
+		if err != nil {
+			t.Fatalf("CHECKPOINT failed: %v", err)
+		}
+		if res.Message != "CHECKPOINT (1 tables written)" {
+			t.Errorf("Unexpected message %q", res.Message)
+		}
+
+		event := recorder.next(t)
+		if event.Reason != manager.ReasonCommand || event.Database != "shop" {
+			t.Errorf("Expected a command checkpoint of shop, got %+v", event)
+		}
+		if fmt.Sprint(event.Tables) != "[items]" || event.Rows != 2 {
+			t.Errorf("Expected items written with 2 logged rows, got tables %v and %d rows", event.Tables, event.Rows)
+		}
+		if size := walSize(); size != 0 {
+			t.Errorf("Expected an empty wal after CHECKPOINT, got %d bytes", size)
+		}
+
+		// Nothing left to write
+		if res, err := eng.Execute("CHECKPOINT;"); err != nil || res.Message != "CHECKPOINT (0 tables written)" {
+			t.Errorf("Expected an idle CHECKPOINT, got %v, %v", res, err)
+		}
+
+		// The table files alone hold the rows
+		reopened, _ := openShop(t, tmpDir)
+		assertItems(t, reopened, map[string]string{"1": "apple:5", "2": "pear:2"})
+	})
+
+	t.Run("Not inside a transaction", func(t *testing.T) {
+		if _, err := eng.Execute("BEGIN"); err != nil {
+			t.Fatalf("BEGIN failed: %v", err)
+		}
+		if _, err := eng.Execute("CHECKPOINT"); err == nil {
+			t.Error("Expected CHECKPOINT inside a transaction to fail")
+		}
+		if _, err := eng.Execute("ROLLBACK"); err != nil {
+			t.Fatalf("ROLLBACK failed: %v", err)
+		}
+	})
+
+	t.Run("Dirty-row threshold", func(t *testing.T) {
+		if err := registry.StartCheckpointer(manager.CheckpointConfig{DirtyRows: 3}); err != nil {
+			t.Fatalf("Failed to start checkpointer: %v", err)
+		}
+		defer registry.StopCheckpointer()
+		if err := registry.StartCheckpointer(manager.CheckpointConfig{DirtyRows: 3}); err == nil {
+			t.Error("Expected a second checkpointer to be refused")
+		}
+
+		for _, sql := range []string{
+			"INSERT INTO tags (label) VALUES ('fruit')",
+			"INSERT INTO items (name, qty) VALUES ('plum', 7), ('fig', 1)",
+		} {
+			if _, err := eng.Execute(sql); err != nil {
+				t.Fatalf("%s failed: %v", sql, err)
+			}
+		}
+
+		event := recorder.next(t)
+		if event.Reason != manager.ReasonThreshold || fmt.Sprint(event.Tables) != "[items tags]" || event.Rows != 3 {
+			t.Errorf("Expected a threshold checkpoint of items and tags, got %+v", event)
+		}
+		if size := walSize(); size != 0 {
+			t.Errorf("Expected an empty wal after the checkpoint, got %d bytes", size)
+		}
+	})
+
+	t.Run("Interval", func(t *testing.T) {
+		if err := registry.StartCheckpointer(manager.CheckpointConfig{Interval: 20 * time.Millisecond}); err != nil {
+			t.Fatalf("Failed to start checkpointer: %v", err)
+		}
+		if _, err := eng.Execute("UPDATE items SET qty = 0 WHERE name = 'fig'"); err != nil {
+			t.Fatalf("Update failed: %v", err)
+		}
+
+		event := recorder.next(t)
+		if event.Reason != manager.ReasonInterval || fmt.Sprint(event.Tables) != "[items]" {
+			t.Errorf("Expected an interval checkpoint of items, got %+v", event)
+		}
+
+		registry.StopCheckpointer()
+		registry.StopCheckpointer() // stopping twice is harmless
+
+		reopened, _ := openShop(t, tmpDir)
+		assertItems(t, reopened, map[string]string{"1": "apple:5", "2": "pear:2", "3": "plum:7", "4": "fig:0"})
+	})
+}
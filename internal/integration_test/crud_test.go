@@ -61,11 +61,13 @@ func TestCRUDOperations(t *testing.T) {
 		tx := transaction.NewTransaction()
 		defer tx.Close()
 		// Find users with specific username
-		rows := usersTable.Select(func(row data.Row) bool {
+		rows, err := usersTable.Select(func(row data.Row) (bool, error) {
 			username, ok := row.Data["username"].(string)
-			return ok && username == "guest"
+			return ok && username == "guest", nil
 		}, tx)
 
+		testutil.AssertNoError(t, err, "Select operation")
+
 		if len(rows) != 1 {
 			t.Errorf("Expected 1 user named guest, got %d", len(rows))
 		}
@@ -134,9 +136,9 @@ func TestCRUDOperations(t *testing.T) {
 		tx := transaction.NewTransaction()
 		defer tx.Close()
 		// Update a user's email
-		updated, err := usersTable.Update(func(row data.Row) bool {
+		updated, err := usersTable.Update(func(row data.Row) (bool, error) {
 			id, ok := row.Data["id"].(int64)
-			return ok && id == int64(2)
+			return ok && id == int64(2), nil
 		}, data.NewRow(map[string]interface{}{
 			"email": "newemail@example.com",
 		}), tx)
@@ -164,9 +166,9 @@ func TestCRUDOperations(t *testing.T) {
 		initialCount := len(initialRows)
 		
 		// Delete a specific user (use ID 1 which should exist in fresh DB)
-		deleted, err := usersTable.Delete(func(row data.Row) bool {
+		deleted, err := usersTable.Delete(func(row data.Row) (bool, error) {
 			id, ok := row.Data["id"].(int64)
-			return ok && id == int64(1)
+			return ok && id == int64(1), nil
 		}, tx)
 		
 		testutil.AssertNoError(t, err, "Delete operation")
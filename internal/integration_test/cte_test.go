@@ -0,0 +1,138 @@
+package integration
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"testing"
+
+	domainErrors "github.com/leengari/mini-rdbms/internal/domain/errors"
+	"github.com/leengari/mini-rdbms/internal/engine"
+	storageEngine "github.com/leengari/mini-rdbms/internal/storage/engine"
+	"github.com/leengari/mini-rdbms/internal/storage/manager"
+)
+
+// TestCommonTableExpressions verifies WITH queries: CTEs read from FROM, JOINs and
+// subqueries, recursive tree walks, UNION deduplication over a cyclic graph and the
+// recursion cap
+func TestCommonTableExpressions(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "rdbms_cte_test")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	registry := manager.NewRegistry(tmpDir, storageEngine.NewJSONEngine())
+	eng := engine.New(nil, registry)
+	for _, sql := range []string{
+		"CREATE DATABASE catalog",
+		"USE catalog",
+		"CREATE TABLE categories (id INT PRIMARY KEY AUTO_INCREMENT, name TEXT NOT NULL, parent_id INT)",
+		"CREATE TABLE employees (id INT PRIMARY KEY AUTO_INCREMENT, name TEXT NOT NULL, manager_id INT)",
+		"CREATE TABLE edges (src INT, dst INT)",
+		"INSERT INTO categories (name) VALUES ('All')",
+		"INSERT INTO categories (name, parent_id) VALUES ('Books', 1)",
+		"INSERT INTO categories (name, parent_id) VALUES ('Music', 1)",
+		"INSERT INTO categories (name, parent_id) VALUES ('Fiction', 2)",
+		"INSERT INTO categories (name, parent_id) VALUES ('SciFi', 4)",
+		"INSERT INTO employees (name) VALUES ('Ada')",
+		"INSERT INTO employees (name, manager_id) VALUES ('Ben', 1)",
+		"INSERT INTO employees (name, manager_id) VALUES ('Cat', 1)",
+		"INSERT INTO employees (name, manager_id) VALUES ('Dan', 3)",
+		"INSERT INTO edges (src, dst) VALUES (1, 2)",
+		"INSERT INTO edges (src, dst) VALUES (2, 3)",
+		"INSERT INTO edges (src, dst) VALUES (3, 1)",
+	} {
+		if _, err := eng.Execute(sql); err != nil {
+			t.Fatalf("%s failed: %v", sql, err)
+		}
+	}
+
+	tree := "WITH RECURSIVE tree (id, name, depth) AS (" +
+		"SELECT id, name, 0 FROM categories WHERE parent_id IS NULL " +
+		"UNION ALL SELECT categories.id, categories.name, tree.depth + 1 FROM categories JOIN tree ON categories.parent_id = tree.id) "
+
+	tests := []struct {
+		name     string
+		sql      string
+		column   string
+		expected string
+	}{
+		{"Simple CTE", "WITH books AS (SELECT id, name FROM categories WHERE parent_id = 2) SELECT name FROM books", "name", "[Fiction]"},
+		{"Column names", "WITH c (cid, label) AS (SELECT id, name FROM categories WHERE parent_id = 1) SELECT label FROM c ORDER BY cid DESC", "label", "[Music Books]"},
+		{"CTE over CTE in JOIN", "WITH top AS (SELECT id FROM categories WHERE parent_id = 1), kids AS (SELECT categories.name FROM categories JOIN top ON categories.parent_id = top.id) SELECT name FROM kids", "name", "[Fiction]"},
+		{"CTE read twice", "WITH parents AS (SELECT parent_id FROM categories WHERE parent_id IS NOT NULL) SELECT COUNT(*) AS n FROM parents WHERE parent_id IN (SELECT parent_id FROM parents WHERE parent_id > 1)", "n", "[2]"},
+		{"CTE in subquery", "WITH parents AS (SELECT parent_id FROM categories WHERE parent_id IS NOT NULL) SELECT name FROM categories WHERE id NOT IN (SELECT parent_id FROM parents) ORDER BY id", "name", "[Music SciFi]"},
+		{"Aggregate over CTE", "WITH c AS (SELECT parent_id, COUNT(*) AS kids FROM categories GROUP BY parent_id) SELECT MAX(kids) AS most FROM c", "most", "[2]"},
+		{"CTE shadows a table", "WITH categories AS (SELECT name FROM categories WHERE parent_id = 1) SELECT name FROM categories ORDER BY name", "name", "[Books Music]"},
+		{"Tree walk", tree + "SELECT name FROM tree ORDER BY depth, id", "name", "[All Books Music Fiction SciFi]"},
+		{"Tree depth", tree + "SELECT depth FROM tree ORDER BY id", "depth", "[0 1 1 2 3]"},
+		{"Subtree", "WITH RECURSIVE sub AS (SELECT id, name FROM categories WHERE id = 2 UNION ALL SELECT categories.id, categories.name FROM categories JOIN sub ON categories.parent_id = sub.id) SELECT name FROM sub ORDER BY id", "name", "[Books Fiction SciFi]"},
+		{"Org chart", "WITH RECURSIVE reports (id, level) AS (SELECT id, 1 FROM employees WHERE manager_id IS NULL UNION ALL SELECT employees.id, reports.level + 1 FROM employees JOIN reports ON employees.manager_id = reports.id) SELECT employees.name FROM employees JOIN reports ON employees.id = reports.id WHERE reports.level = 3", "employees.name", "[Dan]"},
+		{"UNION reaches a fixpoint on a cycle", "WITH RECURSIVE reach (node) AS (SELECT src FROM edges WHERE src = 1 UNION SELECT edges.dst FROM edges JOIN reach ON edges.src = reach.node) SELECT node FROM reach ORDER BY node", "node", "[1 2 3]"},
+		{"UNION without self-reference", "WITH ids AS (SELECT src FROM edges UNION SELECT dst FROM edges) SELECT COUNT(*) AS n FROM ids", "n", "[3]"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := queryColumn(t, eng, tt.sql, tt.column)
+			if fmt.Sprint(got) != tt.expected {
+				t.Errorf("Expected %s, got %v", tt.expected, got)
+			}
+		})
+	}
+
+	t.Run("SELECT * from CTE", func(t *testing.T) {
+		res, err := eng.Execute(tree + "SELECT * FROM tree")
+		if err != nil {
+			t.Fatalf("Query failed: %v", err)
+		}
+		if fmt.Sprint(res.Columns) != "[id name depth]" {
+			t.Errorf("Expected columns [id name depth], got %v", res.Columns)
+		}
+		if len(res.Rows) != 5 {
+			t.Errorf("Expected 5 rows, got %d", len(res.Rows))
+		}
+	})
+
+	t.Run("Recursion cap", func(t *testing.T) {
+		sql := "WITH RECURSIVE walk (node) AS (SELECT src FROM edges WHERE src = 1 UNION ALL SELECT edges.dst FROM edges JOIN walk ON edges.src = walk.node) SELECT node FROM walk"
+		eng.Config().MaxRecursion = 10
+		defer func() { eng.Config().MaxRecursion = 0 }()
+
+		_, err := eng.Execute(sql)
+		var execErr *domainErrors.ExecutionError
+		if !errors.As(err, &execErr) {
+			t.Fatalf("Expected ExecutionError, got %v", err)
+		}
+		if execErr.Table != "walk" {
+			t.Errorf("Expected error on walk, got %q", execErr.Table)
+		}
+
+		// A recursion adding rows in exactly MaxRecursion iterations succeeds
+		eng.Config().MaxRecursion = 3
+		if got := queryColumn(t, eng, tree+"SELECT name FROM tree", "name"); len(got) != 5 {
+			t.Errorf("Expected 5 rows, got %v", got)
+		}
+		eng.Config().MaxRecursion = 2
+		if _, err := eng.Execute(tree + "SELECT name FROM tree"); !errors.As(err, &execErr) {
+			t.Errorf("Expected ExecutionError, got %v", err)
+		}
+	})
+
+	t.Run("Errors", func(t *testing.T) {
+		for _, sql := range []string{
+			"WITH t AS (SELECT id FROM categories UNION ALL SELECT id FROM t) SELECT id FROM t",
+			"WITH RECURSIVE t AS (SELECT id FROM categories UNION ALL SELECT id, name FROM t) SELECT id FROM t",
+			"WITH RECURSIVE t AS (SELECT id FROM categories UNION ALL SELECT id FROM categories WHERE id IN (SELECT id FROM t)) SELECT id FROM t",
+			"WITH t (a, b) AS (SELECT id FROM categories) SELECT a FROM t",
+			"WITH t AS (SELECT id FROM categories), t AS (SELECT id FROM edges) SELECT id FROM t",
+			"WITH t AS (SELECT id, id FROM categories) SELECT id FROM t",
+			"WITH a AS (SELECT id FROM b), b AS (SELECT id FROM categories) SELECT id FROM a",
+		} {
+			if _, err := eng.Execute(sql); err == nil {
+				t.Errorf("Expected error for %s", sql)
+			}
+		}
+	})
+}
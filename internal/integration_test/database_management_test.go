@@ -1,10 +1,12 @@
 package integration
 
 import (
+	stdErrors "errors"
 	"os"
 	"path/filepath"
 	"testing"
 
+	"github.com/leengari/mini-rdbms/internal/domain/errors"
 	"github.com/leengari/mini-rdbms/internal/engine"
 	storageEngine "github.com/leengari/mini-rdbms/internal/storage/engine"
 	"github.com/leengari/mini-rdbms/internal/storage/manager"
@@ -52,27 +54,184 @@ func TestDatabaseManagement(t *testing.T) {
 
 	// 5. Create Table in db1
 	t.Run("Create Table in db1", func(t *testing.T) {
-		_, err := eng.Execute("CREATE TABLE users (id INT, name STRING)")
-		// Note: CREATE TABLE might fail if not supported yet via SQL (it seems it IS supported via bootstrap/loader but maybe not SQL? Let's check parser).
-		// Wait, looking at parser/lexer, I see CREATE but not TABLE token in lexer?
-		// Ah, lexer has CREATE (I added it). Does it have TABLE?
-		// I didn't add TABLE token.
-		// Existing lexer had IDENTIFIER.
-		// Let's check if CREATE TABLE is supported.
-		// Existing parser had `parseSelect`, `parseInsert`, `parseUpdate`, `parseDelete`.
-		// It did NOT have `parseCreate` before I added it.
-		// And I only implemented `CREATE DATABASE`.
-		// So `CREATE TABLE` is NOT supported via SQL yet.
-		// That's fine, I can only test DB management.
-
-		// If CREATE TABLE is not supported, I can't verify data persistence easily via SQL.
-		// But I can verify "USE" works.
+		res, err := eng.Execute("CREATE TABLE users (id INT PRIMARY KEY AUTO_INCREMENT, name TEXT NOT NULL)")
+		if err != nil {
+			t.Fatalf("Failed to create table: %v", err)
+		}
+		if res.Message != "Table 'users' created" {
+			t.Errorf("Unexpected message: %s", res.Message)
+		}
 
-		if err == nil {
-			// If it somehow worked, great.
-		} else {
-			// Expected failure for now as I didn't implement CREATE TABLE
-			// t.Logf("CREATE TABLE failed as expected: %v", err)
+		// Verify table files exist
+		for _, file := range []string{"meta.json", "data.json"} {
+			if _, err := os.Stat(filepath.Join(tmpDir, "db1", "users", file)); os.IsNotExist(err) {
+				t.Errorf("Table file %s not created", file)
+			}
+		}
+
+		// Table should be usable immediately
+		if _, err := eng.Execute("INSERT INTO users (name) VALUES ('alice')"); err != nil {
+			t.Fatalf("Failed to insert into new table: %v", err)
+		}
+		res, err = eng.Execute("SELECT * FROM users")
+		if err != nil {
+			t.Fatalf("Failed to select from new table: %v", err)
+		}
+		if len(res.Rows) != 1 || res.Rows[0].Data["id"] != int64(1) {
+			t.Errorf("Expected one row with auto-increment id 1, got %+v", res.Rows)
+		}
+
+		// Creating it again must fail
+		if _, err := eng.Execute("CREATE TABLE users (id INT)"); err == nil {
+			t.Error("Expected error when creating a duplicate table")
+		}
+	})
+
+	// 5b. Drop Table in db1
+	t.Run("Drop Table in db1", func(t *testing.T) {
+		if _, err := eng.Execute("CREATE TABLE scratch (id INT)"); err != nil {
+			t.Fatalf("Failed to create table: %v", err)
+		}
+
+		res, err := eng.Execute("DROP TABLE scratch")
+		if err != nil {
+			t.Fatalf("Failed to drop table: %v", err)
+		}
+		if res.Message != "Table 'scratch' dropped" {
+			t.Errorf("Unexpected message: %s", res.Message)
+		}
+
+		if _, err := os.Stat(filepath.Join(tmpDir, "db1", "scratch")); !os.IsNotExist(err) {
+			t.Errorf("Table directory scratch still exists")
+		}
+
+		if _, err := eng.Execute("DROP TABLE scratch"); err == nil {
+			t.Error("Expected error when dropping a missing table")
+		}
+		if _, err := eng.Execute("DROP TABLE IF EXISTS scratch"); err != nil {
+			t.Errorf("DROP TABLE IF EXISTS should not fail: %v", err)
+		}
+	})
+
+	// 5c. Alter Table in db1 (users holds one row from 5.)
+	t.Run("Alter Table in db1", func(t *testing.T) {
+		// NOT NULL without a default cannot backfill existing rows
+		_, err := eng.Execute("ALTER TABLE users ADD COLUMN age INT NOT NULL")
+		var constraintErr *errors.ConstraintError
+		if !stdErrors.As(err, &constraintErr) {
+			t.Fatalf("Expected ConstraintError, got %v", err)
+		}
+
+		res, err := eng.Execute("ALTER TABLE users ADD COLUMN age INT NOT NULL DEFAULT 18")
+		if err != nil {
+			t.Fatalf("Failed to add column: %v", err)
+		}
+		if res.Message != "Column 'age' added to table 'users'" {
+			t.Errorf("Unexpected message: %s", res.Message)
+		}
+
+		// Existing rows are backfilled; new rows pick up the default
+		if _, err := eng.Execute("INSERT INTO users (name) VALUES ('bob')"); err != nil {
+			t.Fatalf("Failed to insert after ADD COLUMN: %v", err)
+		}
+		res, err = eng.Execute("SELECT name, age FROM users")
+		if err != nil {
+			t.Fatalf("Failed to select added column: %v", err)
+		}
+		for _, row := range res.Rows {
+			if row.Data["age"] != int64(18) {
+				t.Errorf("Expected age 18 for %v, got %v", row.Data["name"], row.Data["age"])
+			}
+		}
+
+		if _, err := eng.Execute("ALTER TABLE users RENAME COLUMN name TO full_name"); err != nil {
+			t.Fatalf("Failed to rename column: %v", err)
+		}
+		res, err = eng.Execute("SELECT full_name FROM users WHERE full_name = 'alice'")
+		if err != nil {
+			t.Fatalf("Failed to select renamed column: %v", err)
+		}
+		if len(res.Rows) != 1 {
+			t.Errorf("Expected 1 row for renamed column, got %d", len(res.Rows))
+		}
+
+		if _, err := eng.Execute("ALTER TABLE users DROP COLUMN age"); err != nil {
+			t.Fatalf("Failed to drop column: %v", err)
+		}
+		res, err = eng.Execute("SELECT * FROM users")
+		if err != nil {
+			t.Fatalf("Failed to select after DROP COLUMN: %v", err)
+		}
+		for _, row := range res.Rows {
+			if _, exists := row.Data["age"]; exists {
+				t.Errorf("Dropped column still present in row %v", row.Data)
+			}
+		}
+
+		// A UNIQUE column without a default backfills NULLs, which never conflict;
+		// a default would repeat the same value in both rows
+		if _, err := eng.Execute("ALTER TABLE users ADD COLUMN tag TEXT UNIQUE"); err != nil {
+			t.Fatalf("Failed to add UNIQUE column without default: %v", err)
+		}
+		if _, err := eng.Execute("ALTER TABLE users DROP COLUMN tag"); err != nil {
+			t.Fatalf("Failed to drop column: %v", err)
+		}
+		if _, err := eng.Execute("ALTER TABLE users ADD COLUMN code INT UNIQUE DEFAULT 5"); !stdErrors.As(err, &constraintErr) {
+			t.Errorf("Expected ConstraintError for a UNIQUE default on two rows, got %v", err)
+		}
+
+		// The backfilled default is indexed under its stored form
+		if _, err := eng.Execute("CREATE TABLE solo (id INT)"); err != nil {
+			t.Fatalf("Failed to create table: %v", err)
+		}
+		if _, err := eng.Execute("INSERT INTO solo (id) VALUES (1)"); err != nil {
+			t.Fatalf("Failed to insert: %v", err)
+		}
+		// PRIMARY KEY implies NOT NULL, so even one row needs a default
+		if _, err := eng.Execute("ALTER TABLE solo ADD COLUMN ref INT PRIMARY KEY"); !stdErrors.As(err, &constraintErr) {
+			t.Errorf("Expected ConstraintError for a PRIMARY KEY without default, got %v", err)
+		}
+		if _, err := eng.Execute("ALTER TABLE solo ADD COLUMN code INT UNIQUE DEFAULT 5"); err != nil {
+			t.Fatalf("Failed to add UNIQUE column to a single row: %v", err)
+		}
+		res, err = eng.Execute("SELECT id FROM solo WHERE code = 5")
+		if err != nil {
+			t.Fatalf("Failed to select by added column: %v", err)
+		}
+		if len(res.Rows) != 1 {
+			t.Errorf("Expected 1 row by backfilled default, got %d", len(res.Rows))
+		}
+		if _, err := eng.Execute("INSERT INTO solo (id, code) VALUES (2, 5)"); !stdErrors.As(err, &constraintErr) {
+			t.Errorf("Expected ConstraintError for a duplicate of the backfilled default, got %v", err)
+		}
+		if _, err := eng.Execute("DROP TABLE solo"); err != nil {
+			t.Fatalf("Failed to drop table: %v", err)
+		}
+
+		// Primary key index must survive column changes
+		if _, err := eng.Execute("INSERT INTO users (id, full_name) VALUES (1, 'dup')"); err == nil {
+			t.Error("Expected duplicate primary key error after ALTER")
+		}
+
+		res, err = eng.Execute("ALTER TABLE users RENAME TO members")
+		if err != nil {
+			t.Fatalf("Failed to rename table: %v", err)
+		}
+		if res.Message != "Table 'users' renamed to 'members'" {
+			t.Errorf("Unexpected message: %s", res.Message)
+		}
+		if _, err := os.Stat(filepath.Join(tmpDir, "db1", "members", "meta.json")); os.IsNotExist(err) {
+			t.Error("Renamed table directory not found")
+		}
+		if _, err := eng.Execute("SELECT * FROM users"); err == nil {
+			t.Error("Expected error selecting from old table name")
+		}
+		res, err = eng.Execute("SELECT * FROM members")
+		if err != nil {
+			t.Fatalf("Failed to select from renamed table: %v", err)
+		}
+		if len(res.Rows) != 2 {
+			t.Errorf("Expected 2 rows in renamed table, got %d", len(res.Rows))
 		}
 	})
 
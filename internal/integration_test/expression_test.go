@@ -0,0 +1,172 @@
+package integration
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/leengari/mini-rdbms/internal/engine"
+	storageEngine "github.com/leengari/mini-rdbms/internal/storage/engine"
+	"github.com/leengari/mini-rdbms/internal/storage/manager"
+)
+
+// queryColumn runs a query and returns one column of every row, formatted with fmt.Sprint
+func queryColumn(t *testing.T, eng *engine.Engine, sql, column string) []string {
+	t.Helper()
+
+	res, err := eng.Execute(sql)
+	if err != nil {
+		t.Fatalf("%s failed: %v", sql, err)
+	}
+
+	values := make([]string, 0, len(res.Rows))
+	for _, row := range res.Rows {
+		values = append(values, fmt.Sprint(row.Data[column]))
+	}
+	return values
+}
+
+// TestScalarExpressions verifies arithmetic, concatenation and column-to-column comparisons
+// in WHERE, computed SELECT columns with aliases, and UPDATE ... SET col = expression
+func TestScalarExpressions(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "rdbms_expression_test")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	registry := manager.NewRegistry(tmpDir, storageEngine.NewJSONEngine())
+	eng := engine.New(nil, registry)
+	for _, sql := range []string{
+		"CREATE DATABASE shop",
+		"USE shop",
+		"CREATE TABLE items (id INT PRIMARY KEY AUTO_INCREMENT, name TEXT NOT NULL, price FLOAT, qty INT, discount INT)",
+		"CREATE TABLE stock (item_id INT, extra INT)",
+		"INSERT INTO items (name, price, qty, discount) VALUES ('pen', 1.5, 10, 2)",
+		"INSERT INTO items (name, price, qty, discount) VALUES ('book', 12.0, 2, 5)",
+		"INSERT INTO items (name, price, qty, discount) VALUES ('bag', 30.0, 1, NULL)",
+		"INSERT INTO items (name, price, qty, discount) VALUES ('cap', 2 * 5, 3 - 1, -0)",
+		"INSERT INTO stock (item_id, extra) VALUES (1, 5)",
+		"INSERT INTO stock (item_id, extra) VALUES (2, 7)",
+	} {
+		if _, err := eng.Execute(sql); err != nil {
+			t.Fatalf("%s failed: %v", sql, err)
+		}
+	}
+
+	tests := []struct {
+		name     string
+		sql      string
+		column   string
+		expected string
+	}{
+		{"Constant expressions in VALUES", "SELECT price, qty FROM items WHERE name = 'cap'", "price", "[10]"},
+		{"Arithmetic in WHERE", "SELECT name FROM items WHERE price * qty > 20", "name", "[book bag]"},
+		{"Column to column", "SELECT name FROM items WHERE qty > discount", "name", "[pen cap]"},
+		{"Guarded division in WHERE", "SELECT name FROM items WHERE discount <> 0 AND qty / discount > 1", "name", "[pen]"},
+		{"Arithmetic in IN", "SELECT name FROM items WHERE qty + 1 IN (3, discount)", "name", "[book cap]"},
+		{"Computed column with alias", "SELECT name, price * qty AS total FROM items ORDER BY total DESC", "total", "[30 24 20 15]"},
+		{"ORDER BY alias", "SELECT name, price * qty AS total FROM items ORDER BY total DESC", "name", "[bag book cap pen]"},
+		{"Integer division", "SELECT qty / 3 AS third FROM items WHERE id = 1", "third", "[3]"},
+		{"Modulo", "SELECT qty % 3 AS rest FROM items WHERE id = 1", "rest", "[1]"},
+		{"Unary minus", "SELECT -qty AS neg FROM items WHERE id = 1", "neg", "[-10]"},
+		{"NULL propagates", "SELECT qty - discount AS net FROM items WHERE name = 'bag'", "net", "[<nil>]"},
+		{"Concatenation", "SELECT name || '#' || id AS label FROM items WHERE id <= 2", "label", "[pen#1 book#2]"},
+		{"Plain column alias", "SELECT name AS item FROM items WHERE id = 2", "item", "[book]"},
+		{"Unaliased expression", "SELECT qty + 1 FROM items WHERE id = 1", "(qty + 1)", "[11]"},
+		{"Aggregate in expression", "SELECT SUM(qty) * 2 AS twice FROM items", "twice", "[30]"},
+		{"Grouped expression", "SELECT qty, COUNT(*) * 10 AS c FROM items GROUP BY qty ORDER BY qty", "c", "[10 20 10]"},
+		{"Computed column over JOIN", "SELECT items.name, items.qty + stock.extra AS total FROM items JOIN stock ON items.id = stock.item_id ORDER BY total", "total", "[9 15]"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := queryColumn(t, eng, tt.sql, tt.column)
+			if fmt.Sprint(got) != tt.expected {
+				t.Errorf("Expected %s, got %v", tt.expected, got)
+			}
+		})
+	}
+
+	t.Run("Result metadata", func(t *testing.T) {
+		res, err := eng.Execute("SELECT name AS item, price * qty AS total, qty * 2 AS twice, name || 'x' AS tag FROM items WHERE id = 1")
+		if err != nil {
+			t.Fatalf("Query failed: %v", err)
+		}
+		if fmt.Sprint(res.Columns) != "[item total twice tag]" {
+			t.Errorf("Expected columns [item total twice tag], got %v", res.Columns)
+		}
+		types := make([]string, len(res.Metadata))
+		for i, m := range res.Metadata {
+			types[i] = m.Type
+		}
+		if fmt.Sprint(types) != "[TEXT FLOAT INT TEXT]" {
+			t.Errorf("Expected types [TEXT FLOAT INT TEXT], got %v", types)
+		}
+		if _, ok := res.Rows[0].Data["twice"].(int64); !ok {
+			t.Errorf("Expected int64 for integer arithmetic, got %T", res.Rows[0].Data["twice"])
+		}
+	})
+
+	t.Run("Expression errors", func(t *testing.T) {
+		for _, sql := range []string{
+			"SELECT qty / 0 AS boom FROM items",
+			"SELECT name FROM items WHERE qty / discount > 1",
+			"SELECT name FROM items GROUP BY name HAVING COUNT(*) / 0 = 1",
+			"UPDATE items SET qty = 0 WHERE qty / discount > 1",
+			"DELETE FROM items WHERE NOT (id / 0 = 1)",
+			"SELECT name * 2 FROM items",
+			"SELECT nosuch + 1 FROM items",
+			"SELECT qty + 1 AS x FROM items GROUP BY name",
+			"INSERT INTO items (name, qty) VALUES ('x', qty + 1)",
+		} {
+			if _, err := eng.Execute(sql); err == nil {
+				t.Errorf("Expected error for %s", sql)
+			}
+		}
+
+		// The failed UPDATE and DELETE left every row as it was
+		if got := queryColumn(t, eng, "SELECT qty FROM items ORDER BY id", "qty"); fmt.Sprint(got) != "[10 2 1 2]" {
+			t.Errorf("Expected qty [10 2 1 2], got %v", got)
+		}
+	})
+
+	t.Run("UPDATE with expressions", func(t *testing.T) {
+		res, err := eng.Execute("UPDATE items SET qty = qty + 1 WHERE qty < 5")
+		if err != nil {
+			t.Fatalf("Update failed: %v", err)
+		}
+		if res.RowsAffected != 3 {
+			t.Errorf("Expected 3 rows affected, got %d", res.RowsAffected)
+		}
+		if got := queryColumn(t, eng, "SELECT qty FROM items ORDER BY id", "qty"); fmt.Sprint(got) != "[10 3 2 3]" {
+			t.Errorf("Expected qty [10 3 2 3], got %v", got)
+		}
+
+		// Every SET expression reads the row's values from before the update
+		if _, err := eng.Execute("UPDATE items SET qty = discount, discount = qty WHERE name = 'pen'"); err != nil {
+			t.Fatalf("Swap failed: %v", err)
+		}
+		if got := queryColumn(t, eng, "SELECT qty || '/' || discount AS pair FROM items WHERE name = 'pen'", "pair"); fmt.Sprint(got) != "[2/10]" {
+			t.Errorf("Expected swapped values [2/10], got %v", got)
+		}
+
+		// The result is converted to the column type: INT accepts integral numbers only
+		if _, err := eng.Execute("UPDATE items SET qty = price * 2 WHERE name = 'book'"); err != nil {
+			t.Errorf("Expected integral FLOAT result to fit INT column: %v", err)
+		}
+		if _, err := eng.Execute("UPDATE items SET qty = price / 4"); err == nil {
+			t.Error("Expected error storing 0.375 in INT column")
+		}
+		if got := queryColumn(t, eng, "SELECT qty FROM items ORDER BY id", "qty"); fmt.Sprint(got) != "[2 24 2 3]" {
+			t.Errorf("Failed UPDATE must not change rows, got qty %v", got)
+		}
+
+		if _, err := eng.Execute("UPDATE items SET name = name || NULL WHERE id = 1"); err == nil {
+			t.Error("Expected NOT NULL violation for computed NULL")
+		}
+		if _, err := eng.Execute("UPDATE items SET qty = nosuch + 1"); err == nil {
+			t.Error("Expected error for unknown column in SET expression")
+		}
+	})
+}
@@ -0,0 +1,153 @@
+package integration
+
+import (
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/leengari/mini-rdbms/internal/engine"
+	storageEngine "github.com/leengari/mini-rdbms/internal/storage/engine"
+	"github.com/leengari/mini-rdbms/internal/storage/manager"
+)
+
+// TestScalarFunctions verifies the built-in function library in SELECT, WHERE, SET and VALUES,
+// the result types reported in column metadata, and plan-time argument checking
+func TestScalarFunctions(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "rdbms_function_test")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	registry := manager.NewRegistry(tmpDir, storageEngine.NewJSONEngine())
+	eng := engine.New(nil, registry)
+	for _, sql := range []string{
+		"CREATE DATABASE club",
+		"USE club",
+		"CREATE TABLE people (id INT PRIMARY KEY AUTO_INCREMENT, name TEXT NOT NULL, nick TEXT, score FLOAT, born DATE, wake TIME, age INT)",
+		"INSERT INTO people (name, nick, score, born, wake, age) VALUES ('Ann', NULL, 3.456, '2000-02-29', '08:30:00', 24)",
+		"INSERT INTO people (name, nick, score, born, wake, age) VALUES ('bob', 'bobby', -2.5, '1990-12-31', '23:15:00', 34)",
+		"INSERT INTO people (name) VALUES ('Cy')",
+	} {
+		if _, err := eng.Execute(sql); err != nil {
+			t.Fatalf("%s failed: %v", sql, err)
+		}
+	}
+
+	tests := []struct {
+		name     string
+		sql      string
+		column   string
+		expected string
+	}{
+		{"UPPER", "SELECT UPPER(name) AS u FROM people ORDER BY id", "u", "[ANN BOB CY]"},
+		{"LOWER in WHERE", "SELECT name FROM people WHERE LOWER(name) = 'cy'", "name", "[Cy]"},
+		{"LENGTH", "SELECT LENGTH(name) AS n FROM people ORDER BY id", "n", "[3 3 2]"},
+		{"SUBSTR", "SELECT SUBSTR(name, 2, 2) AS s FROM people WHERE id = 2", "s", "[ob]"},
+		{"SUBSTR from before the start", "SELECT SUBSTR(name, 0, 2) AS s FROM people WHERE id = 2", "s", "[b]"},
+		{"TRIM", "SELECT TRIM('  x  ') || '|' AS s FROM people WHERE id = 1", "s", "[x|]"},
+		{"REPLACE", "SELECT REPLACE(name, 'b', 'B') AS s FROM people WHERE id = 2", "s", "[BoB]"},
+		{"ABS", "SELECT ABS(score) AS a FROM people WHERE id = 2", "a", "[2.5]"},
+		{"ROUND to digits", "SELECT ROUND(score, 2) AS r FROM people WHERE id = 1", "r", "[3.46]"},
+		{"ROUND half away from zero", "SELECT ROUND(score) AS r FROM people WHERE id = 2", "r", "[-3]"},
+		{"ROUND integer to tens", "SELECT ROUND(age, -1) AS r FROM people WHERE id = 1", "r", "[20]"},
+		{"NULL argument gives NULL", "SELECT UPPER(nick) AS u FROM people ORDER BY id", "u", "[<nil> BOBBY <nil>]"},
+		{"COALESCE", "SELECT COALESCE(nick, name) AS n FROM people ORDER BY id", "n", "[Ann bobby Cy]"},
+		{"NULLIF", "SELECT NULLIF(age, 24) AS a FROM people ORDER BY id", "a", "[<nil> 34 <nil>]"},
+		{"CAST to INT rounds", "SELECT CAST(score AS INT) AS i FROM people WHERE id = 1", "i", "[3]"},
+		{"CAST text to INT", "SELECT CAST('42' AS INT) + 1 AS i FROM people WHERE id = 1", "i", "[43]"},
+		{"CAST to TEXT", "SELECT CAST(age AS TEXT) || 'y' AS s FROM people WHERE id = 1", "s", "[24y]"},
+		{"CAST NOW() to DATE", "SELECT name FROM people WHERE CAST(NOW() AS DATE) = CURRENT_DATE ORDER BY id", "name", "[Ann bob Cy]"},
+		{"DATE_PART year", "SELECT DATE_PART('year', born) AS y FROM people ORDER BY id", "y", "[2000 1990 <nil>]"},
+		{"DATE_PART day of week", "SELECT DATE_PART('dow', born) AS d FROM people WHERE id = 1", "d", "[2]"},
+		{"DATE_PART in WHERE", "SELECT name FROM people WHERE DATE_PART('month', born) = 12", "name", "[bob]"},
+		{"DATE_ADD days", "SELECT DATE_ADD(born, 1) AS d FROM people WHERE id = 1", "d", "[2000-03-01]"},
+		{"DATE_ADD months", "SELECT DATE_ADD(born, 1, 'month') AS d FROM people WHERE id = 2", "d", "[1991-01-31]"},
+		{"DATE_ADD to TIME", "SELECT DATE_ADD(wake, 1, 'hour') AS t FROM people WHERE id = 2", "t", "[00:15:00]"},
+		{"DATE_DIFF", "SELECT DATE_DIFF(DATE '2000-03-10', born) AS d FROM people WHERE id = 1", "d", "[10]"},
+		{"Function over aggregate", "SELECT ROUND(AVG(score), 1) AS a FROM people", "a", "[0.5]"},
+		{"Function over group key", "SELECT UPPER(nick) AS u, COUNT(*) AS c FROM people GROUP BY nick ORDER BY u", "c", "[1 2]"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := queryColumn(t, eng, tt.sql, tt.column)
+			if fmt.Sprint(got) != tt.expected {
+				t.Errorf("Expected %s, got %v", tt.expected, got)
+			}
+		})
+	}
+
+	t.Run("Result metadata", func(t *testing.T) {
+		res, err := eng.Execute("SELECT LENGTH(name) AS l, ROUND(score) AS r, CURRENT_DATE AS d, CAST(age AS FLOAT) AS f, COALESCE(age, score) AS c, NOW() FROM people WHERE id = 1")
+		if err != nil {
+			t.Fatalf("Query failed: %v", err)
+		}
+		if fmt.Sprint(res.Columns) != "[l r d f c NOW()]" {
+			t.Errorf("Expected columns [l r d f c NOW()], got %v", res.Columns)
+		}
+		types := make([]string, len(res.Metadata))
+		for i, m := range res.Metadata {
+			types[i] = m.Type
+		}
+		if fmt.Sprint(types) != "[INT FLOAT DATE FLOAT FLOAT TEXT]" {
+			t.Errorf("Expected types [INT FLOAT DATE FLOAT FLOAT TEXT], got %v", types)
+		}
+	})
+
+	t.Run("Plan-time errors", func(t *testing.T) {
+		// The WHERE clauses match no rows, so these fail before any row is evaluated
+		for _, sql := range []string{
+			"SELECT UPPER(age) AS u FROM people WHERE id = 999",
+			"SELECT LENGTH() AS n FROM people WHERE id = 999",
+			"SELECT SUBSTR(name) AS s FROM people WHERE id = 999",
+			"SELECT NOSUCH(name) AS s FROM people WHERE id = 999",
+			"SELECT CAST(age AS BLOB) AS c FROM people WHERE id = 999",
+			"SELECT COALESCE(age, name) AS c FROM people WHERE id = 999",
+			"SELECT name FROM people WHERE id = 999 AND ABS(name) > 1",
+			"SELECT name FROM people WHERE COUNT(*) > 1",
+			"SELECT name FROM people ORDER BY UPPER(name)",
+			"UPDATE people SET age = UPPER(name) WHERE id = 999",
+			"DELETE FROM people WHERE id = 999 AND LOWER(age) = 'x'",
+			"INSERT INTO people (name, age) VALUES ('x', LENGTH(5))",
+		} {
+			if _, err := eng.Execute(sql); err == nil {
+				t.Errorf("Expected error for %s", sql)
+			}
+		}
+	})
+
+	t.Run("Runtime errors", func(t *testing.T) {
+		for _, sql := range []string{
+			"SELECT CAST(name AS INT) AS i FROM people",
+			"SELECT DATE_PART('eon', born) AS p FROM people",
+		} {
+			if _, err := eng.Execute(sql); err == nil {
+				t.Errorf("Expected error for %s", sql)
+			}
+		}
+	})
+
+	t.Run("Functions in SET and VALUES", func(t *testing.T) {
+		res, err := eng.Execute("UPDATE people SET nick = LOWER(name) || '!' WHERE nick IS NULL")
+		if err != nil {
+			t.Fatalf("Update failed: %v", err)
+		}
+		if res.RowsAffected != 2 {
+			t.Errorf("Expected 2 rows affected, got %d", res.RowsAffected)
+		}
+		if got := queryColumn(t, eng, "SELECT nick FROM people ORDER BY id", "nick"); fmt.Sprint(got) != "[ann! bobby cy!]" {
+			t.Errorf("Expected nicks [ann! bobby cy!], got %v", got)
+		}
+
+		if _, err := eng.Execute("INSERT INTO people (name, born, age) VALUES (UPPER('dee'), CURRENT_DATE, LENGTH('four'))"); err != nil {
+			t.Fatalf("Insert failed: %v", err)
+		}
+		today := time.Now().Format("2006-01-02")
+		got := queryColumn(t, eng, "SELECT name || ' ' || born || ' ' || age AS s FROM people WHERE id = 4", "s")
+		if fmt.Sprint(got) != "[DEE "+today+" 4]" {
+			t.Errorf("Expected [DEE %s 4], got %v", today, got)
+		}
+	})
+}
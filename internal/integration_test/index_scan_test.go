@@ -0,0 +1,190 @@
+package integration
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/leengari/mini-rdbms/internal/domain/transaction"
+	"github.com/leengari/mini-rdbms/internal/engine"
+	"github.com/leengari/mini-rdbms/internal/parser"
+	"github.com/leengari/mini-rdbms/internal/parser/lexer"
+	"github.com/leengari/mini-rdbms/internal/plan"
+	"github.com/leengari/mini-rdbms/internal/planner"
+	"github.com/leengari/mini-rdbms/internal/query/indexing"
+	storageEngine "github.com/leengari/mini-rdbms/internal/storage/engine"
+	"github.com/leengari/mini-rdbms/internal/storage/loader"
+	"github.com/leengari/mini-rdbms/internal/storage/manager"
+)
+
+// TestIndexScanPlanning verifies the planner picks index scans for indexed equality, IN and BETWEEN predicates
+func TestIndexScanPlanning(t *testing.T) {
+	db, err := loader.LoadDatabase("../../databases/testdb")
+	if err != nil {
+		t.Fatalf("Failed to load database: %v", err)
+	}
+	if err := indexing.BuildDatabaseIndexes(db); err != nil {
+		t.Fatalf("Failed to build indexes: %v", err)
+	}
+
+	tests := []struct {
+		sql      string
+		scanType string
+	}{
+		{"SELECT * FROM users WHERE id = 5", "index"},
+		{"SELECT * FROM users WHERE id IN (2, 6)", "index"},
+		{"SELECT * FROM users WHERE is_active = true AND id = 2", "index"},
+		{"SELECT * FROM users WHERE username = 'bob'", "index"},
+		{"SELECT * FROM users WHERE is_active = true", "sequential"},
+		{"SELECT * FROM users WHERE id > 5", "sequential"},
+		{"SELECT * FROM users WHERE id = 2 OR id = 5", "sequential"},
+		{"SELECT * FROM users WHERE id BETWEEN 2 AND 6", "index"},
+		{"SELECT * FROM users WHERE id NOT BETWEEN 2 AND 6", "sequential"},
+		{"SELECT * FROM users WHERE id NOT IN (2, 6)", "sequential"},
+		{"SELECT * FROM users WHERE is_active BETWEEN false AND true", "sequential"},
+		{"DELETE FROM users WHERE id BETWEEN 5 AND 6", "index"},
+		{"UPDATE users SET username = 'x' WHERE id = 5", "index"},
+		{"DELETE FROM users WHERE id IN (5, 6)", "index"},
+		{"DELETE FROM users WHERE is_active = false", "sequential"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.sql, func(t *testing.T) {
+			tokens, err := lexer.Tokenize(tt.sql)
+			if err != nil {
+				t.Fatalf("Lexer error: %v", err)
+			}
+			stmt, err := parser.New(tokens).Parse()
+			if err != nil {
+				t.Fatalf("Parse error: %v", err)
+			}
+			node, err := planner.Plan(stmt, db, transaction.NewTransaction())
+			if err != nil {
+				t.Fatalf("Plan error: %v", err)
+			}
+
+			if got := scanTypeOf(node); got != tt.scanType {
+				t.Errorf("Expected %s scan, got %s\n%s", tt.scanType, got, plan.PrintTree(node))
+			}
+		})
+	}
+}
+
+// scanTypeOf returns "index" if the plan tree contains an index scan, "sequential" otherwise
+func scanTypeOf(node plan.Node) string {
+	if _, ok := node.(*plan.IndexScanNode); ok {
+		return "index"
+	}
+	for _, child := range node.Children() {
+		if scanTypeOf(child) == "index" {
+			return "index"
+		}
+	}
+	return "sequential"
+}
+
+// TestIndexScanResults verifies index-backed reads return the same rows as sequential scans
+func TestIndexScanResults(t *testing.T) {
+	db, err := loader.LoadDatabase("../../databases/testdb")
+	if err != nil {
+		t.Fatalf("Failed to load database: %v", err)
+	}
+	if err := indexing.BuildDatabaseIndexes(db); err != nil {
+		t.Fatalf("Failed to build indexes: %v", err)
+	}
+
+	eng := engine.New(db, nil)
+
+	tests := []struct {
+		sql      string
+		expected []string
+	}{
+		{"SELECT id FROM users WHERE id = 5", []string{"5"}},
+		{"SELECT id FROM users WHERE id = 999", []string{}},
+		{"SELECT id FROM users WHERE id IN (6, 2, 999)", []string{"2", "6"}},
+		{"SELECT id FROM users WHERE id IN (2, 5) AND username = 'eve'", []string{"5"}},
+		{"SELECT id FROM users WHERE id IN (2, 5, 6) ORDER BY id DESC LIMIT 2", []string{"6", "5"}},
+		{"SELECT COUNT(*) FROM users WHERE id IN (2, 5, 6)", []string{"3"}},
+		{"SELECT id FROM users WHERE username IN ('bob', 'frank')", []string{"2", "6"}},
+		{"SELECT id FROM users WHERE id BETWEEN 3 AND 18", []string{"5", "6", "18"}},
+		{"SELECT id FROM users WHERE id BETWEEN 5.5 AND 6", []string{"6"}},
+		{"SELECT id FROM users WHERE id BETWEEN 18 AND 3", []string{}},
+		{"SELECT id FROM users WHERE username BETWEEN 'c' AND 'f'", []string{"5"}},
+		{"SELECT id FROM users WHERE id BETWEEN 1 AND 100 AND id IN (6, 888)", []string{"6"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.sql, func(t *testing.T) {
+			result, err := eng.Execute(tt.sql)
+			if err != nil {
+				t.Fatalf("Execute failed: %v", err)
+			}
+
+			got := make([]string, 0, len(result.Rows))
+			for _, row := range result.Rows {
+				for _, col := range result.Columns {
+					got = append(got, fmt.Sprint(row.Data[col]))
+				}
+			}
+			if fmt.Sprint(got) != fmt.Sprint(tt.expected) {
+				t.Errorf("Expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+// TestIndexedUpdateDelete verifies UPDATE and DELETE through an index touch only the matching rows
+func TestIndexedUpdateDelete(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "rdbms_index_scan_test")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	registry := manager.NewRegistry(tmpDir, storageEngine.NewJSONEngine())
+	eng := engine.New(nil, registry)
+	for _, sql := range []string{
+		"CREATE DATABASE shop",
+		"USE shop",
+		"CREATE TABLE items (id INT PRIMARY KEY AUTO_INCREMENT, name TEXT NOT NULL, qty INT)",
+		"INSERT INTO items (name, qty) VALUES ('apple', 5)",
+		"INSERT INTO items (name, qty) VALUES ('pear', 2)",
+		"INSERT INTO items (name, qty) VALUES ('plum', 7)",
+		"INSERT INTO items (name, qty) VALUES ('fig', 1)",
+	} {
+		if _, err := eng.Execute(sql); err != nil {
+			t.Fatalf("%s failed: %v", sql, err)
+		}
+	}
+
+	result, err := eng.Execute("UPDATE items SET qty = 0 WHERE id IN (2, 4) AND qty > 1")
+	if err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+	if result.RowsAffected != 1 {
+		t.Errorf("Expected 1 row updated, got %d", result.RowsAffected)
+	}
+
+	result, err = eng.Execute("DELETE FROM items WHERE id IN (1, 3)")
+	if err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if result.RowsAffected != 2 {
+		t.Errorf("Expected 2 rows deleted, got %d", result.RowsAffected)
+	}
+
+	assertItems(t, eng, map[string]string{"2": "pear:0", "4": "fig:1"})
+
+	// The index must still point at the right rows after the positions shifted
+	result, err = eng.Execute("SELECT name FROM items WHERE id = 4")
+	if err != nil {
+		t.Fatalf("Select failed: %v", err)
+	}
+	if len(result.Rows) != 1 || result.Rows[0].Data["name"] != "fig" {
+		t.Errorf("Expected fig for id 4, got %v", result.Rows)
+	}
+
+	// Changes made through the index are replayed from the WAL after a restart
+	restarted, _ := openShop(t, tmpDir)
+	assertItems(t, restarted, map[string]string{"2": "pear:0", "4": "fig:1"})
+}
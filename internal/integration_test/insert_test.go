@@ -0,0 +1,140 @@
+package integration
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/leengari/mini-rdbms/internal/engine"
+	storageEngine "github.com/leengari/mini-rdbms/internal/storage/engine"
+	"github.com/leengari/mini-rdbms/internal/storage/manager"
+)
+
+// TestMultiRowInsert verifies INSERT with several VALUES rows and INSERT ... SELECT,
+// the rows affected they report, and that a failing row undoes the whole statement
+func TestMultiRowInsert(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "rdbms_insert_test")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	registry := manager.NewRegistry(tmpDir, storageEngine.NewJSONEngine())
+	eng := engine.New(nil, registry)
+	for _, sql := range []string{
+		"CREATE DATABASE shop",
+		"USE shop",
+		"CREATE TABLE items (id INT PRIMARY KEY AUTO_INCREMENT, name TEXT NOT NULL UNIQUE, price FLOAT)",
+		"CREATE TABLE archive (id INT PRIMARY KEY AUTO_INCREMENT, label TEXT UNIQUE, cost FLOAT)",
+	} {
+		if _, err := eng.Execute(sql); err != nil {
+			t.Fatalf("%s failed: %v", sql, err)
+		}
+	}
+
+	t.Run("VALUES rows", func(t *testing.T) {
+		res, err := eng.Execute("INSERT INTO items (name, price) VALUES ('apple', 1.5), ('pear', 2 * 1.5), ('plum', NULL)")
+		if err != nil {
+			t.Fatalf("Insert failed: %v", err)
+		}
+		if res.RowsAffected != 3 || res.Message != "INSERT 3" {
+			t.Errorf("Expected INSERT 3 with 3 rows affected, got %q with %d", res.Message, res.RowsAffected)
+		}
+		got := queryColumn(t, eng, "SELECT id, name FROM items ORDER BY id", "name")
+		if fmt.Sprint(got) != "[apple pear plum]" {
+			t.Errorf("Expected [apple pear plum], got %v", got)
+		}
+	})
+
+	t.Run("Single row", func(t *testing.T) {
+		res, err := eng.Execute("INSERT INTO items (name, price) VALUES ('fig', 4.0)")
+		if err != nil {
+			t.Fatalf("Insert failed: %v", err)
+		}
+		if res.RowsAffected != 1 || res.Message != "INSERT 1" {
+			t.Errorf("Expected INSERT 1 with 1 row affected, got %q with %d", res.Message, res.RowsAffected)
+		}
+	})
+
+	t.Run("Failing row undoes the statement", func(t *testing.T) {
+		if _, err := eng.Execute("INSERT INTO items (name, price) VALUES ('kiwi', 1.0), ('lime', 1.0), ('apple', 9.0)"); err == nil {
+			t.Fatal("Expected unique constraint violation")
+		}
+		got := queryColumn(t, eng, "SELECT id FROM items ORDER BY id", "id")
+		if fmt.Sprint(got) != "[1 2 3 4]" {
+			t.Errorf("Expected rows [1 2 3 4] to remain, got %v", got)
+		}
+
+		// The auto-increment sequence is rolled back too
+		if _, err := eng.Execute("INSERT INTO items (name) VALUES ('kiwi')"); err != nil {
+			t.Fatalf("Insert failed: %v", err)
+		}
+		got = queryColumn(t, eng, "SELECT id FROM items WHERE name = 'kiwi'", "id")
+		if fmt.Sprint(got) != "[5]" {
+			t.Errorf("Expected kiwi to get id 5, got %v", got)
+		}
+	})
+
+	t.Run("INSERT SELECT", func(t *testing.T) {
+		res, err := eng.Execute("INSERT INTO archive (label, cost) SELECT UPPER(name), price FROM items WHERE price IS NOT NULL ORDER BY id")
+		if err != nil {
+			t.Fatalf("Insert failed: %v", err)
+		}
+		if res.RowsAffected != 3 || res.Message != "INSERT 3" {
+			t.Errorf("Expected INSERT 3 with 3 rows affected, got %q with %d", res.Message, res.RowsAffected)
+		}
+		got := queryColumn(t, eng, "SELECT id, label, cost FROM archive ORDER BY id", "cost")
+		if fmt.Sprint(got) != "[1.5 3 4]" {
+			t.Errorf("Expected costs [1.5 3 4], got %v", got)
+		}
+		got = queryColumn(t, eng, "SELECT id, label FROM archive ORDER BY id", "label")
+		if fmt.Sprint(got) != "[APPLE PEAR FIG]" {
+			t.Errorf("Expected labels [APPLE PEAR FIG], got %v", got)
+		}
+	})
+
+	t.Run("INSERT SELECT from the same table", func(t *testing.T) {
+		res, err := eng.Execute("INSERT INTO archive (label) SELECT label || '2' FROM archive")
+		if err != nil {
+			t.Fatalf("Insert failed: %v", err)
+		}
+		if res.RowsAffected != 3 {
+			t.Errorf("Expected 3 rows affected, got %d", res.RowsAffected)
+		}
+	})
+
+	t.Run("INSERT SELECT with no rows", func(t *testing.T) {
+		res, err := eng.Execute("INSERT INTO archive (label) SELECT name FROM items WHERE id > 100")
+		if err != nil {
+			t.Fatalf("Insert failed: %v", err)
+		}
+		if res.RowsAffected != 0 || res.Message != "INSERT 0" {
+			t.Errorf("Expected INSERT 0, got %q with %d", res.Message, res.RowsAffected)
+		}
+	})
+
+	t.Run("Failing INSERT SELECT undoes the statement", func(t *testing.T) {
+		if _, err := eng.Execute("INSERT INTO archive (label) SELECT UPPER(name) FROM items ORDER BY id DESC"); err == nil {
+			t.Fatal("Expected unique constraint violation")
+		}
+		got := queryColumn(t, eng, "SELECT id FROM archive", "id")
+		if len(got) != 6 {
+			t.Errorf("Expected 6 archive rows to remain, got %v", got)
+		}
+	})
+
+	t.Run("Errors", func(t *testing.T) {
+		for _, sql := range []string{
+			"INSERT INTO items (name, price) VALUES ('a', 1.0), ('b')",
+			"INSERT INTO items (name, price) VALUES ('a', 1.0), ('b', 'cheap')",
+			"INSERT INTO items (name) SELECT name, price FROM items",
+			"INSERT INTO items (name, price) SELECT name, name FROM items",
+			"INSERT INTO items (missing) SELECT name FROM items",
+			"INSERT INTO items (name) SELECT name FROM missing",
+		} {
+			if _, err := eng.Execute(sql); err == nil {
+				t.Errorf("Expected error for %s", sql)
+			}
+		}
+	})
+}
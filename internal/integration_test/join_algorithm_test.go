@@ -0,0 +1,185 @@
+package integration
+
+import (
+	"fmt"
+	"sort"
+	"testing"
+
+	"github.com/leengari/mini-rdbms/internal/domain/data"
+	"github.com/leengari/mini-rdbms/internal/domain/transaction"
+	"github.com/leengari/mini-rdbms/internal/engine"
+	"github.com/leengari/mini-rdbms/internal/parser"
+	"github.com/leengari/mini-rdbms/internal/parser/lexer"
+	"github.com/leengari/mini-rdbms/internal/plan"
+	"github.com/leengari/mini-rdbms/internal/planner"
+	"github.com/leengari/mini-rdbms/internal/query/indexing"
+	"github.com/leengari/mini-rdbms/internal/query/operations/join"
+	"github.com/leengari/mini-rdbms/internal/query/operations/testutil"
+	"github.com/leengari/mini-rdbms/internal/storage/loader"
+)
+
+// joinedRowStrings renders joined rows as sorted strings so results can be compared regardless of order
+func joinedRowStrings(rows []data.JoinedRow) []string {
+	out := make([]string, len(rows))
+	for i, row := range rows {
+		out[i] = fmt.Sprintf("%v|%v|%v",
+			row.Data["users.username"], row.Data["orders.product"], row.Data["orders.amount"])
+	}
+	sort.Strings(out)
+	return out
+}
+
+// TestJoinAlgorithms verifies nested-loop, hash and merge joins return the same rows for every JOIN type
+func TestJoinAlgorithms(t *testing.T) {
+	users := testutil.CreateUsersTable()
+	orders := testutil.CreateOrdersTable()
+
+	// An order with no user, a user with no orders, a NULL key and a float64 key equal to an int64 one
+	orders.Rows = append(orders.Rows,
+		data.NewRow(map[string]interface{}{"id": int64(4), "user_id": int64(9), "product": "Cable", "amount": 5.0}),
+		data.NewRow(map[string]interface{}{"id": int64(5), "user_id": nil, "product": "Gift", "amount": 0.0}),
+		data.NewRow(map[string]interface{}{"id": int64(6), "user_id": float64(2), "product": "Pen", "amount": 1.5}),
+	)
+	indexing.BuildIndexes(orders)
+
+	expected := map[join.JoinType]int{
+		join.JoinTypeInner: 4, // alice×2, bob×2
+		join.JoinTypeLeft:  5, // + charlie
+		join.JoinTypeRight: 6, // + Cable, Gift
+		join.JoinTypeFull:  7, // + charlie, Cable, Gift
+	}
+
+	for joinType, count := range expected {
+		var reference []string
+		for _, algorithm := range []join.Algorithm{join.AlgorithmNestedLoop, join.AlgorithmHash, join.AlgorithmMerge} {
+			t.Run(fmt.Sprintf("%s/%s", joinType, algorithm), func(t *testing.T) {
+				results, err := join.ExecuteJoinWithAlgorithm(
+					algorithm, users, orders,
+					"id", "user_id",
+					joinType,
+					nil, nil, transaction.NewTransaction(),
+				)
+				testutil.AssertNoError(t, err, "JOIN")
+				testutil.AssertRowCount(t, len(results), count, "JOIN results")
+
+				got := joinedRowStrings(results)
+				if reference == nil {
+					reference = got
+				} else if fmt.Sprint(got) != fmt.Sprint(reference) {
+					t.Errorf("Expected same rows as nested loop:\n%v\ngot:\n%v", reference, got)
+				}
+			})
+		}
+	}
+
+	if _, err := join.ExecuteJoinWithAlgorithm("bogus", users, orders, "id", "user_id", join.JoinTypeInner, nil, nil, nil); err == nil {
+		t.Error("Expected error for unknown algorithm")
+	}
+}
+
+// TestJoinAlgorithmSelection verifies the planner's algorithm choice and the ExecutionConfig override
+func TestJoinAlgorithmSelection(t *testing.T) {
+	db, err := loader.LoadDatabase("../../databases/testdb")
+	if err != nil {
+		t.Fatalf("Failed to load database: %v", err)
+	}
+	if err := indexing.BuildDatabaseIndexes(db); err != nil {
+		t.Fatalf("Failed to build indexes: %v", err)
+	}
+
+	tests := []struct {
+		sql       string
+		algorithm join.Algorithm
+	}{
+		// orders.user_id is not indexed and both tables are tiny
+		{"SELECT * FROM users JOIN orders ON users.id = orders.user_id", join.AlgorithmNestedLoop},
+		// users.id is indexed: probe the index
+		{"SELECT * FROM orders JOIN users ON orders.user_id = users.id", join.AlgorithmHash},
+	}
+
+	for _, tt := range tests {
+		tokens, err := lexer.Tokenize(tt.sql)
+		if err != nil {
+			t.Fatalf("Lexer error: %v", err)
+		}
+		stmt, err := parser.New(tokens).Parse()
+		if err != nil {
+			t.Fatalf("Parse error: %v", err)
+		}
+		node, err := planner.Plan(stmt, db, transaction.NewTransaction())
+		if err != nil {
+			t.Fatalf("Plan error: %v", err)
+		}
+
+		joinNode := findJoinNode(node)
+		if joinNode == nil {
+			t.Fatalf("No JOIN node in plan for %s", tt.sql)
+		}
+		if joinNode.Algorithm != tt.algorithm || joinNode.Metadata()["join_algorithm"] != string(tt.algorithm) {
+			t.Errorf("%s: expected %s, got %s", tt.sql, tt.algorithm, joinNode.Algorithm)
+		}
+	}
+
+	// Every forced algorithm returns the same rows through the engine
+	eng := engine.New(db, nil)
+	sql := "SELECT users.username, orders.product FROM users FULL JOIN orders ON users.id = orders.user_id ORDER BY orders.product"
+	var reference string
+	for _, algorithm := range []string{"", "nested_loop", "hash", "merge"} {
+		eng.Config().JoinAlgorithm = algorithm
+		result, err := eng.Execute(sql)
+		if err != nil {
+			t.Fatalf("JoinAlgorithm %q: %v", algorithm, err)
+		}
+		got := fmt.Sprint(len(result.Rows))
+		for _, row := range result.Rows {
+			got += fmt.Sprintf(" %v/%v", row.Data["users.username"], row.Data["orders.product"])
+		}
+		if reference == "" {
+			reference = got
+		} else if got != reference {
+			t.Errorf("JoinAlgorithm %q: expected %s, got %s", algorithm, reference, got)
+		}
+	}
+
+	eng.Config().JoinAlgorithm = "bogus"
+	if _, err := eng.Execute(sql); err == nil {
+		t.Error("Expected error for unknown JoinAlgorithm")
+	}
+}
+
+// findJoinNode returns the first JOIN node in a plan tree
+func findJoinNode(node plan.Node) *plan.JoinNode {
+	if j, ok := node.(*plan.JoinNode); ok {
+		return j
+	}
+	for _, child := range node.Children() {
+		if j := findJoinNode(child); j != nil {
+			return j
+		}
+	}
+	return nil
+}
+
+// TestJoinProbingIndex verifies that a hash join probing the right table's index finds
+// the rows of join values held as another numeric Go type (the float64 user_id)
+func TestJoinProbingIndex(t *testing.T) {
+	users := testutil.CreateUsersTable()
+	orders := testutil.CreateOrdersTable()
+	orders.Rows = append(orders.Rows,
+		data.Row{ID: 4, Data: map[string]interface{}{"id": int64(4), "user_id": float64(2), "product": "Pen", "amount": 1.5}},
+	)
+
+	var reference []string
+	for _, algorithm := range []join.Algorithm{join.AlgorithmNestedLoop, join.AlgorithmHash, join.AlgorithmMerge} {
+		results, err := join.ExecuteJoinWithAlgorithm(algorithm, orders, users, "user_id", "id", join.JoinTypeInner, nil, nil, nil)
+		testutil.AssertNoError(t, err, "JOIN")
+		testutil.AssertRowCount(t, len(results), 4, string(algorithm)+" JOIN results")
+
+		got := joinedRowStrings(results)
+		if reference == nil {
+			reference = got
+		} else if fmt.Sprint(got) != fmt.Sprint(reference) {
+			t.Errorf("%s: expected same rows as nested loop:\n%v\ngot:\n%v", algorithm, reference, got)
+		}
+	}
+}
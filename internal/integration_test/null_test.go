@@ -0,0 +1,142 @@
+package integration
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/leengari/mini-rdbms/internal/domain/transaction"
+	"github.com/leengari/mini-rdbms/internal/engine"
+	storageEngine "github.com/leengari/mini-rdbms/internal/storage/engine"
+	"github.com/leengari/mini-rdbms/internal/storage/manager"
+)
+
+// contactNames runs a query and returns the sorted names it selects
+func contactNames(t *testing.T, eng *engine.Engine, sql string) []string {
+	t.Helper()
+
+	res, err := eng.Execute(sql)
+	if err != nil {
+		t.Fatalf("%s failed: %v", sql, err)
+	}
+
+	names := make([]string, 0, len(res.Rows))
+	for _, row := range res.Rows {
+		name, _ := row.Data["name"].(string)
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func TestNullSupport(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "rdbms_null_test")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	registry := manager.NewRegistry(tmpDir, storageEngine.NewJSONEngine())
+	eng := engine.New(nil, registry)
+	for _, sql := range []string{
+		"CREATE DATABASE shop",
+		"USE shop",
+		"CREATE TABLE contacts (id INT PRIMARY KEY AUTO_INCREMENT, name TEXT NOT NULL, email TEXT UNIQUE, age INT)",
+		"INSERT INTO contacts (name, email, age) VALUES ('ann', 'ann@example.com', 30)",
+		"INSERT INTO contacts (name, email, age) VALUES ('ben', NULL, 40)",
+		"INSERT INTO contacts (name, email, age) VALUES ('cat', NULL, NULL)",
+		"INSERT INTO contacts (name, age) VALUES ('dan', 25)",
+	} {
+		if _, err := eng.Execute(sql); err != nil {
+			t.Fatalf("%s failed: %v", sql, err)
+		}
+	}
+
+	t.Run("Unique column allows multiple NULLs", func(t *testing.T) {
+		if _, err := eng.Execute("INSERT INTO contacts (name, email) VALUES ('dup', 'ann@example.com')"); err == nil {
+			t.Error("Expected unique violation for duplicate non-NULL email")
+		}
+		got := contactNames(t, eng, "SELECT * FROM contacts")
+		if strings.Join(got, ",") != "ann,ben,cat,dan" {
+			t.Errorf("Expected 4 contacts, got %v", got)
+		}
+	})
+
+	t.Run("NOT NULL rejects NULL", func(t *testing.T) {
+		if _, err := eng.Execute("INSERT INTO contacts (name, email) VALUES (NULL, 'x@example.com')"); err == nil {
+			t.Error("Expected NOT NULL violation on INSERT")
+		}
+		if _, err := eng.Execute("UPDATE contacts SET name = NULL WHERE id = 1"); err == nil {
+			t.Error("Expected NOT NULL violation on UPDATE")
+		}
+	})
+
+	tests := []struct {
+		name     string
+		sql      string
+		expected string
+	}{
+		{"IS NULL", "SELECT * FROM contacts WHERE email IS NULL", "ben,cat,dan"},
+		{"IS NOT NULL", "SELECT * FROM contacts WHERE email IS NOT NULL", "ann"},
+		{"Equals NULL is never true", "SELECT * FROM contacts WHERE email = NULL", ""},
+		{"Not equals NULL is never true", "SELECT * FROM contacts WHERE email != NULL", ""},
+		{"Comparison skips NULL rows", "SELECT * FROM contacts WHERE age < 100", "ann,ben,dan"},
+		{"NOT of unknown stays unknown", "SELECT * FROM contacts WHERE NOT (age > 30)", "ann,dan"},
+		{"NOT of comparison", "SELECT * FROM contacts WHERE NOT email = 'ann@example.com'", ""},
+		{"OR with unknown and true", "SELECT * FROM contacts WHERE age > 35 OR email IS NULL", "ben,cat,dan"},
+		{"AND with unknown and false", "SELECT * FROM contacts WHERE NOT (age > 35 AND name = 'zed')", "ann,ben,cat,dan"},
+		{"AND with unknown and true", "SELECT * FROM contacts WHERE NOT (age > 35 AND name = 'cat')", "ann,ben,dan"},
+		{"IN ignores NULL element", "SELECT * FROM contacts WHERE age IN (30, NULL)", "ann"},
+		{"NOT IN with NULL element", "SELECT * FROM contacts WHERE NOT age IN (30, NULL)", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := contactNames(t, eng, tt.sql)
+			if strings.Join(got, ",") != tt.expected {
+				t.Errorf("Expected [%s], got %v", tt.expected, got)
+			}
+		})
+	}
+
+	t.Run("UPDATE sets NULL", func(t *testing.T) {
+		res, err := eng.Execute("UPDATE contacts SET email = NULL, age = NULL WHERE name = 'ann'")
+		if err != nil {
+			t.Fatalf("Update failed: %v", err)
+		}
+		if res.RowsAffected != 1 {
+			t.Errorf("Expected 1 row affected, got %d", res.RowsAffected)
+		}
+		got := contactNames(t, eng, "SELECT * FROM contacts WHERE email IS NULL AND age IS NULL")
+		if strings.Join(got, ",") != "ann,cat" {
+			t.Errorf("Expected [ann cat], got %v", got)
+		}
+	})
+
+	t.Run("NULLs persist as explicit null", func(t *testing.T) {
+		tx := transaction.NewTransaction()
+		defer tx.Close()
+		registry.SaveAll(tx)
+
+		content, err := os.ReadFile(filepath.Join(tmpDir, "shop", "contacts", "data.json"))
+		if err != nil {
+			t.Fatalf("Failed to read table data: %v", err)
+		}
+		if !strings.Contains(string(content), `"email": null`) {
+			t.Errorf("Expected explicit null in data.json, got:\n%s", content)
+		}
+
+		eng, _ := openShop(t, tmpDir)
+		got := contactNames(t, eng, "SELECT * FROM contacts WHERE email IS NULL")
+		if strings.Join(got, ",") != "ann,ben,cat,dan" {
+			t.Errorf("Expected all contacts to have NULL email after reload, got %v", got)
+		}
+
+		// The reloaded unique index still accepts more NULLs
+		if _, err := eng.Execute("INSERT INTO contacts (name, email) VALUES ('eve', NULL)"); err != nil {
+			t.Errorf("Insert of NULL email after reload failed: %v", err)
+		}
+	})
+}
@@ -0,0 +1,127 @@
+package integration
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/leengari/mini-rdbms/internal/engine"
+	"github.com/leengari/mini-rdbms/internal/query/indexing"
+	"github.com/leengari/mini-rdbms/internal/storage/loader"
+)
+
+// TestSQLOrderByLimit tests ORDER BY, LIMIT and OFFSET end-to-end via SQL
+func TestSQLOrderByLimit(t *testing.T) {
+	// Load test database
+	db, err := loader.LoadDatabase("../../databases/testdb")
+	if err != nil {
+		t.Fatalf("Failed to load database: %v", err)
+	}
+
+	// Build indexes
+	if err := indexing.BuildDatabaseIndexes(db); err != nil {
+		t.Fatalf("Failed to build indexes: %v", err)
+	}
+
+	eng := engine.New(db, nil)
+
+	// column returns one column of the result as strings, in row order
+	column := func(t *testing.T, sql, col string) []string {
+		t.Helper()
+		result, err := eng.Execute(sql)
+		if err != nil {
+			t.Fatalf("%s failed: %v", sql, err)
+		}
+		values := make([]string, len(result.Rows))
+		for i, row := range result.Rows {
+			values[i] = fmt.Sprint(row.Data[col])
+		}
+		return values
+	}
+
+	tests := []struct {
+		name     string
+		sql      string
+		column   string
+		expected []string
+	}{
+		{
+			name:     "ORDER BY DESC",
+			sql:      "SELECT id FROM users ORDER BY id DESC",
+			column:   "id",
+			expected: []string{"888", "18", "6", "5", "2"},
+		},
+		{
+			name:     "LIMIT with OFFSET",
+			sql:      "SELECT username FROM users ORDER BY username LIMIT 2 OFFSET 1;",
+			column:   "username",
+			expected: []string{"eve", "frank"},
+		},
+		{
+			name:     "OFFSET past the end",
+			sql:      "SELECT username FROM users ORDER BY username OFFSET 10",
+			column:   "username",
+			expected: []string{},
+		},
+		{
+			name:     "ORDER BY column not in select list",
+			sql:      "SELECT username FROM users WHERE id < 100 ORDER BY email DESC",
+			column:   "username",
+			expected: []string{"repl_user", "frank", "eve", "bob"},
+		},
+		{
+			name:     "NULLs sort last ascending by default",
+			sql:      "SELECT id FROM users ORDER BY is_active, id",
+			column:   "id",
+			expected: []string{"2", "5", "6", "18", "888"},
+		},
+		{
+			name:     "NULLS FIRST with secondary key",
+			sql:      "SELECT id FROM users ORDER BY is_active ASC NULLS FIRST, id DESC",
+			column:   "id",
+			expected: []string{"888", "18", "6", "5", "2"},
+		},
+		{
+			name:     "ORDER BY qualified column over JOIN",
+			sql:      "SELECT orders.product FROM users JOIN orders ON users.id = orders.user_id ORDER BY orders.amount DESC LIMIT 2",
+			column:   "orders.product",
+			expected: []string{"laptop", "monitor"},
+		},
+		{
+			name:     "WHERE applies before LIMIT over JOIN",
+			sql:      "SELECT orders.product FROM users JOIN orders ON users.id = orders.user_id WHERE orders.amount < 100 ORDER BY amount LIMIT 5",
+			column:   "orders.product",
+			expected: []string{"mouse", "keyboard"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := column(t, tt.sql, tt.column)
+			if fmt.Sprint(got) != fmt.Sprint(tt.expected) {
+				t.Errorf("Expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+
+	t.Run("SELECT * keeps schema column order", func(t *testing.T) {
+		result, err := eng.Execute("SELECT * FROM users ORDER BY id LIMIT 1")
+		if err != nil {
+			t.Fatalf("Select failed: %v", err)
+		}
+		table := db.Tables["users"]
+		if len(result.Columns) != len(table.Schema.Columns) {
+			t.Fatalf("Expected %d columns, got %v", len(table.Schema.Columns), result.Columns)
+		}
+		for i, col := range table.Schema.Columns {
+			if result.Columns[i] != col.Name {
+				t.Errorf("Column %d: expected %s, got %s", i, col.Name, result.Columns[i])
+			}
+		}
+	})
+
+	t.Run("Unknown ORDER BY column", func(t *testing.T) {
+		if _, err := eng.Execute("SELECT id FROM users ORDER BY missing"); err == nil {
+			t.Error("Expected error for unknown ORDER BY column")
+		}
+	})
+}
@@ -0,0 +1,284 @@
+package integration
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/leengari/mini-rdbms/internal/domain/schema"
+	"github.com/leengari/mini-rdbms/internal/domain/transaction"
+	"github.com/leengari/mini-rdbms/internal/engine"
+	"github.com/leengari/mini-rdbms/internal/parser"
+	"github.com/leengari/mini-rdbms/internal/parser/lexer"
+	"github.com/leengari/mini-rdbms/internal/plan"
+	"github.com/leengari/mini-rdbms/internal/planner"
+	storageEngine "github.com/leengari/mini-rdbms/internal/storage/engine"
+	"github.com/leengari/mini-rdbms/internal/storage/manager"
+	"github.com/leengari/mini-rdbms/internal/storage/metadata"
+)
+
+// planSQL parses and plans a statement against db
+func planSQL(t *testing.T, db *schema.Database, sql string) plan.Node {
+	t.Helper()
+
+	tokens, err := lexer.Tokenize(sql)
+	if err != nil {
+		t.Fatalf("Lexer error: %v", err)
+	}
+	stmt, err := parser.New(tokens).Parse()
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	node, err := planner.Plan(stmt, db, transaction.NewTransaction())
+	if err != nil {
+		t.Fatalf("Plan error: %v", err)
+	}
+	return node
+}
+
+// findNode returns the first node of the plan tree with the given type, or nil
+func findNode(node plan.Node, nodeType string) plan.Node {
+	if node.NodeType() == nodeType {
+		return node
+	}
+	for _, child := range node.Children() {
+		if found := findNode(child, nodeType); found != nil {
+			return found
+		}
+	}
+	return nil
+}
+
+// names runs a query and returns its name column, in result order
+func names(t *testing.T, eng *engine.Engine, sql string) string {
+	t.Helper()
+
+	res, err := eng.Execute(sql)
+	if err != nil {
+		t.Fatalf("%s failed: %v", sql, err)
+	}
+	got := make([]string, len(res.Rows))
+	for i, row := range res.Rows {
+		got[i] = fmt.Sprint(row.Data["name"])
+	}
+	return fmt.Sprint(got)
+}
+
+// peopleIndexes returns the index definitions in the people table's meta.json
+func peopleIndexes(t *testing.T, basePath string) string {
+	t.Helper()
+
+	content, err := os.ReadFile(filepath.Join(basePath, "shop", "people", "meta.json"))
+	if err != nil {
+		t.Fatalf("Failed to read meta.json: %v", err)
+	}
+	var meta metadata.TableMeta
+	if err := json.Unmarshal(content, &meta); err != nil {
+		t.Fatalf("Failed to decode meta.json: %v", err)
+	}
+	return fmt.Sprint(meta.Indexes)
+}
+
+// TestOrderedIndexes verifies CREATE/DROP INDEX, range scans and sorts served by
+// ordered indexes, index maintenance on writes and persistence of index definitions
+func TestOrderedIndexes(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "rdbms_ordered_index_test")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	open := func() (*engine.Engine, *schema.Database) {
+		registry := manager.NewRegistry(tmpDir, storageEngine.NewJSONEngine())
+		eng := engine.New(nil, registry)
+		if _, err := eng.Execute("USE shop"); err != nil {
+			t.Fatalf("Failed to use shop: %v", err)
+		}
+		db, err := registry.Get("shop")
+		if err != nil {
+			t.Fatalf("Failed to get shop: %v", err)
+		}
+		return eng, db
+	}
+	exec := func(eng *engine.Engine, sqls ...string) {
+		t.Helper()
+		for _, sql := range sqls {
+			if _, err := eng.Execute(sql); err != nil {
+				t.Fatalf("%s failed: %v", sql, err)
+			}
+		}
+	}
+
+	eng := engine.New(nil, manager.NewRegistry(tmpDir, storageEngine.NewJSONEngine()))
+	exec(eng,
+		"CREATE DATABASE shop",
+		"USE shop",
+		"CREATE TABLE people (id INT PRIMARY KEY AUTO_INCREMENT, name TEXT NOT NULL, city TEXT, age INT)",
+		"INSERT INTO people (name, city, age) VALUES ('ann', 'Oslo', 30), ('bob', 'Lima', 25), ('cid', 'Oslo', 25), ('dan', 'Oslo', NULL)",
+		"CREATE INDEX by_city_age ON people (city, age)",
+		"CREATE UNIQUE INDEX by_name ON people (name)",
+		"CREATE INDEX by_age ON people (age)",
+		"INSERT INTO people (name, city, age) VALUES ('eve', 'Oslo', 41), ('fay', 'Lima', 30), ('gus', 'Oslo', 30)",
+	)
+	eng, db := open()
+
+	t.Run("Planning", func(t *testing.T) {
+		tests := []struct {
+			sql    string
+			index  string // ordered index read ("" for none)
+			sorted bool   // a Sort node remains
+		}{
+			{"SELECT name FROM people WHERE age > 28", "by_age", false},
+			{"SELECT name FROM people WHERE city = 'Oslo' AND age >= 25", "by_city_age", false},
+			{"SELECT name FROM people WHERE 'Oslo' = city ORDER BY age DESC", "by_city_age", false},
+			{"SELECT name FROM people ORDER BY age", "by_age", false},
+			{"SELECT name FROM people ORDER BY city, age LIMIT 2", "by_city_age", false},
+			{"SELECT name FROM people WHERE name = 'eve'", "by_name", false},
+			{"SELECT name FROM people ORDER BY city", "", true},
+			{"SELECT name FROM people ORDER BY age NULLS FIRST", "", true},
+			{"SELECT name FROM people ORDER BY age, name", "", true},
+			{"SELECT name FROM people WHERE id = 3 ORDER BY age", "", true},
+			{"SELECT city, COUNT(*) FROM people WHERE age > 1 GROUP BY city ORDER BY city", "by_age", true},
+			{"SELECT name FROM people WHERE age = 30 OR age = 41", "", false},
+			{"UPDATE people SET age = 1 WHERE age BETWEEN 26 AND 35", "by_age", false},
+			{"DELETE FROM people WHERE city = 'Lima' AND age < 30", "by_city_age", false},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.sql, func(t *testing.T) {
+				node := planSQL(t, db, tt.sql)
+				index := ""
+				if scan, ok := findNode(node, "INDEX_SCAN").(*plan.IndexScanNode); ok {
+					index = scan.Index
+				}
+				if index != tt.index {
+					t.Errorf("Expected index %q, got %q\n%s", tt.index, index, plan.PrintTree(node))
+				}
+				if sorted := findNode(node, "SORT") != nil; sorted != tt.sorted {
+					t.Errorf("Expected sort=%v, got %v\n%s", tt.sorted, sorted, plan.PrintTree(node))
+				}
+			})
+		}
+	})
+
+	t.Run("Results", func(t *testing.T) {
+		tests := []struct {
+			sql      string
+			expected string
+		}{
+			{"SELECT name FROM people WHERE age > 28 ORDER BY age", "[ann fay gus eve]"},
+			{"SELECT name FROM people WHERE age >= 25 ORDER BY age DESC", "[eve ann fay gus bob cid]"},
+			{"SELECT name FROM people ORDER BY age", "[bob cid ann fay gus eve dan]"},
+			{"SELECT name FROM people ORDER BY people.age DESC", "[dan eve ann fay gus bob cid]"},
+			{"SELECT name FROM people WHERE city = 'Oslo' ORDER BY age", "[cid ann gus eve dan]"},
+			{"SELECT name FROM people WHERE city = 'Oslo' AND age < 41 AND age > 1 ORDER BY age DESC", "[ann gus cid]"},
+			{"SELECT name FROM people WHERE city = 'Lima' AND age BETWEEN 20 AND 26", "[bob]"},
+			{"SELECT name FROM people WHERE 30 <= age ORDER BY age LIMIT 2", "[ann fay]"},
+			{"SELECT name FROM people WHERE age > 100", "[]"},
+			{"SELECT name FROM people WHERE name = 'gus'", "[gus]"},
+		}
+
+		for _, useIndexes := range []bool{true, false} {
+			eng.Config().UseIndexes = useIndexes
+			for _, tt := range tests {
+				t.Run(fmt.Sprintf("%s (indexes %v)", tt.sql, useIndexes), func(t *testing.T) {
+					if got := names(t, eng, tt.sql); got != tt.expected {
+						t.Errorf("Expected %s, got %s", tt.expected, got)
+					}
+				})
+			}
+		}
+		eng.Config().UseIndexes = true
+	})
+
+	t.Run("Writes keep indexes current", func(t *testing.T) {
+		exec(eng,
+			"UPDATE people SET age = 50 WHERE age BETWEEN 29 AND 31",
+			"DELETE FROM people WHERE city = 'Lima' AND age < 30",
+			"BEGIN",
+			"DELETE FROM people WHERE age = 50",
+			"INSERT INTO people (name, city, age) VALUES ('hal', 'Oslo', 60)",
+			"ROLLBACK",
+		)
+		if got := names(t, eng, "SELECT name FROM people ORDER BY age DESC"); got != "[dan ann fay gus eve cid]" {
+			t.Errorf("Unexpected order %s", got)
+		}
+		if got := names(t, eng, "SELECT name FROM people WHERE age >= 41"); got != "[ann eve fay gus]" {
+			t.Errorf("Unexpected range %s", got)
+		}
+	})
+
+	t.Run("Unique indexes", func(t *testing.T) {
+		if _, err := eng.Execute("INSERT INTO people (name, city, age) VALUES ('eve', 'Rome', 20)"); err == nil {
+			t.Error("Expected a duplicate name to be refused")
+		}
+		if _, err := eng.Execute("CREATE UNIQUE INDEX by_city ON people (city)"); err == nil {
+			t.Error("Expected a unique index over duplicate cities to be refused")
+		}
+		// Keys containing NULL never conflict
+		exec(eng,
+			"CREATE UNIQUE INDEX by_city_name ON people (city, name)",
+			"INSERT INTO people (name, age) VALUES ('ivy', 1), ('jon', 2)",
+			"DROP INDEX by_city_name",
+		)
+	})
+
+	t.Run("Invalid statements", func(t *testing.T) {
+		for _, sql := range []string{
+			"CREATE INDEX by_age ON people (city)",
+			"CREATE INDEX by_missing ON people (nope)",
+			"CREATE INDEX by_twice ON people (age, age)",
+			"CREATE INDEX by_nothing ON nowhere (age)",
+			"DROP INDEX by_missing",
+		} {
+			if _, err := eng.Execute(sql); err == nil {
+				t.Errorf("Expected %s to fail", sql)
+			}
+		}
+		exec(eng, "DROP INDEX IF EXISTS by_missing", "BEGIN")
+		if _, err := eng.Execute("CREATE INDEX by_city ON people (city)"); err == nil {
+			t.Error("Expected CREATE INDEX inside a transaction to fail")
+		}
+		exec(eng, "ROLLBACK")
+	})
+
+	t.Run("Definitions persist", func(t *testing.T) {
+		if got := peopleIndexes(t, tmpDir); got != "[{by_age [age] false} {by_city_age [city age] false} {by_name [name] true}]" {
+			t.Errorf("Unexpected indexes in meta.json: %s", got)
+		}
+
+		// Rows logged after the last checkpoint are replayed into the rebuilt indexes
+		eng, db = open()
+		if scan, ok := findNode(planSQL(t, db, "SELECT name FROM people WHERE age > 40"), "INDEX_SCAN").(*plan.IndexScanNode); !ok || scan.Index != "by_age" {
+			t.Error("Expected by_age to be used after a restart")
+		}
+		if got := names(t, eng, "SELECT name FROM people WHERE age > 40 ORDER BY age"); got != "[eve ann fay gus]" {
+			t.Errorf("Unexpected range after restart %s", got)
+		}
+		if _, err := eng.Execute("INSERT INTO people (name) VALUES ('ann')"); err == nil {
+			t.Error("Expected the unique index to hold after a restart")
+		}
+
+		exec(eng, "DROP INDEX by_age")
+		if got := peopleIndexes(t, tmpDir); got != "[{by_city_age [city age] false} {by_name [name] true}]" {
+			t.Errorf("Unexpected indexes after DROP INDEX: %s", got)
+		}
+	})
+
+	t.Run("Column changes", func(t *testing.T) {
+		exec(eng, "ALTER TABLE people RENAME COLUMN age TO years")
+		if got := peopleIndexes(t, tmpDir); got != "[{by_city_age [city years] false} {by_name [name] true}]" {
+			t.Errorf("Unexpected indexes after RENAME COLUMN: %s", got)
+		}
+		if got := names(t, eng, "SELECT name FROM people WHERE city = 'Oslo' AND years > 40"); got != "[ann eve gus]" {
+			t.Errorf("Unexpected rows through the renamed column %s", got)
+		}
+
+		exec(eng, "ALTER TABLE people DROP COLUMN years")
+		if got := peopleIndexes(t, tmpDir); got != "[{by_name [name] true}]" {
+			t.Errorf("Unexpected indexes after DROP COLUMN: %s", got)
+		}
+	})
+}
@@ -0,0 +1,347 @@
+package integration
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/leengari/mini-rdbms/internal/network"
+	storageEngine "github.com/leengari/mini-rdbms/internal/storage/engine"
+	"github.com/leengari/mini-rdbms/internal/storage/manager"
+)
+
+// pgClient is a minimal PostgreSQL protocol client for testing
+type pgClient struct {
+	t      *testing.T
+	conn   net.Conn
+	reader *bufio.Reader
+}
+
+// pgMessage is a backend message
+type pgMessage struct {
+	Type byte
+	Body []byte
+}
+
+// pgResponse collects the messages answering one Query, up to ReadyForQuery
+type pgResponse struct {
+	Columns  []string
+	TypeOIDs []int32
+	Rows     [][]*string
+	Tags     []string
+	Errors   []string
+	Codes    []string // SQLSTATE of each error
+	Notices  []string
+	TxStatus byte
+}
+
+func dialPG(t *testing.T, port int, params map[string]string) (*pgClient, []pgMessage) {
+	t.Helper()
+
+	conn, err := net.Dial("tcp", fmt.Sprintf("localhost:%d", port))
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	c := &pgClient{t: t, conn: conn, reader: bufio.NewReader(conn)}
+
+	// SSLRequest is refused with a single 'N'
+	sslRequest := binary.BigEndian.AppendUint32(binary.BigEndian.AppendUint32(nil, 8), 80877103)
+	if _, err := conn.Write(sslRequest); err != nil {
+		t.Fatalf("Failed to send SSLRequest: %v", err)
+	}
+	if b, err := c.reader.ReadByte(); err != nil || b != 'N' {
+		t.Fatalf("Expected 'N' for SSLRequest, got %q (%v)", b, err)
+	}
+
+	body := binary.BigEndian.AppendUint32(nil, 196608)
+	for k, v := range params {
+		body = append(append(append(append(body, k...), 0), v...), 0)
+	}
+	body = append(body, 0)
+	startup := append(binary.BigEndian.AppendUint32(nil, uint32(len(body)+4)), body...)
+	if _, err := conn.Write(startup); err != nil {
+		t.Fatalf("Failed to send startup: %v", err)
+	}
+
+	var msgs []pgMessage
+	for {
+		msg, err := c.read()
+		if err != nil {
+			return c, msgs
+		}
+		msgs = append(msgs, msg)
+		if msg.Type == 'Z' || msg.Type == 'E' {
+			return c, msgs
+		}
+	}
+}
+
+func (c *pgClient) read() (pgMessage, error) {
+	c.conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	var header [5]byte
+	if _, err := io.ReadFull(c.reader, header[:]); err != nil {
+		return pgMessage{}, err
+	}
+	body := make([]byte, binary.BigEndian.Uint32(header[1:])-4)
+	if _, err := io.ReadFull(c.reader, body); err != nil {
+		return pgMessage{}, err
+	}
+	return pgMessage{Type: header[0], Body: body}, nil
+}
+
+func (c *pgClient) send(typ byte, body []byte) {
+	msg := append([]byte{typ}, binary.BigEndian.AppendUint32(nil, uint32(len(body)+4))...)
+	if _, err := c.conn.Write(append(msg, body...)); err != nil {
+		c.t.Fatalf("Failed to send message: %v", err)
+	}
+}
+
+// query sends a simple Query and decodes the response
+func (c *pgClient) query(sql string) *pgResponse {
+	c.t.Helper()
+	c.send('Q', append([]byte(sql), 0))
+	return c.collect()
+}
+
+// collect reads messages until ReadyForQuery
+func (c *pgClient) collect() *pgResponse {
+	c.t.Helper()
+	res := &pgResponse{}
+	for {
+		msg, err := c.read()
+		if err != nil {
+			c.t.Fatalf("Failed to read response: %v", err)
+		}
+		body := msg.Body
+		switch msg.Type {
+		case 'T':
+			n := int(binary.BigEndian.Uint16(body))
+			body = body[2:]
+			for i := 0; i < n; i++ {
+				end := strings.IndexByte(string(body), 0)
+				res.Columns = append(res.Columns, string(body[:end]))
+				body = body[end+1:]
+				res.TypeOIDs = append(res.TypeOIDs, int32(binary.BigEndian.Uint32(body[6:])))
+				body = body[18:]
+			}
+		case 'D':
+			n := int(binary.BigEndian.Uint16(body))
+			body = body[2:]
+			row := make([]*string, n)
+			for i := 0; i < n; i++ {
+				length := int32(binary.BigEndian.Uint32(body))
+				body = body[4:]
+				if length >= 0 {
+					v := string(body[:length])
+					row[i] = &v
+					body = body[length:]
+				}
+			}
+			res.Rows = append(res.Rows, row)
+		case 'C':
+			res.Tags = append(res.Tags, strings.TrimSuffix(string(body), "\x00"))
+		case 'E':
+			res.Errors = append(res.Errors, pgField(body, 'M'))
+			res.Codes = append(res.Codes, pgField(body, 'C'))
+		case 'N':
+			res.Notices = append(res.Notices, pgField(body, 'M'))
+		case 'Z':
+			res.TxStatus = body[0]
+			return res
+		}
+	}
+}
+
+// pgField extracts one field from an ErrorResponse/NoticeResponse body
+func pgField(body []byte, code byte) string {
+	for len(body) > 0 && body[0] != 0 {
+		end := strings.IndexByte(string(body[1:]), 0) + 1
+		if body[0] == code {
+			return string(body[1:end])
+		}
+		body = body[end+1:]
+	}
+	return ""
+}
+
+func TestServerPostgres(t *testing.T) {
+	db := setupTestDB(t)
+	defer teardownTestDB(t, db)
+
+	port := 54322
+
+	registry := manager.NewRegistry(filepath.Dir(testDBPath), storageEngine.NewJSONEngine())
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go network.StartPG(ctx, port, registry)
+	time.Sleep(100 * time.Millisecond)
+
+	client, startup := dialPG(t, port, map[string]string{"user": "joy", "database": "testdb_integration"})
+	defer client.conn.Close()
+
+	types := ""
+	for _, msg := range startup {
+		types += string(msg.Type)
+	}
+	if !strings.HasPrefix(types, "R") || !strings.HasSuffix(types, "KZ") {
+		t.Fatalf("Unexpected startup messages %q", types)
+	}
+
+	t.Run("Select", func(t *testing.T) {
+		res := client.query("SELECT * FROM users")
+		if len(res.Errors) > 0 {
+			t.Fatalf("Unexpected error: %v", res.Errors)
+		}
+		if len(res.Columns) == 0 || res.Columns[0] != "id" {
+			t.Fatalf("Unexpected columns: %v", res.Columns)
+		}
+		if res.TypeOIDs[0] != 20 {
+			t.Errorf("Expected int8 OID 20 for id, got %d", res.TypeOIDs[0])
+		}
+		if len(res.Tags) != 1 || res.Tags[0] != fmt.Sprintf("SELECT %d", len(res.Rows)) {
+			t.Errorf("Unexpected command tags: %v", res.Tags)
+		}
+		foundAdmin := false
+		for _, row := range res.Rows {
+			for _, v := range row {
+				if v != nil && *v == "admin" {
+					foundAdmin = true
+				}
+			}
+		}
+		if !foundAdmin {
+			t.Errorf("Expected a row with 'admin'")
+		}
+		if res.TxStatus != 'I' {
+			t.Errorf("Expected idle status, got %q", res.TxStatus)
+		}
+	})
+
+	t.Run("MultipleStatementsAndTransactions", func(t *testing.T) {
+		res := client.query("BEGIN; INSERT INTO users (username, email) VALUES ('pg;user', 'pg@example.com'); SELECT COUNT(*) FROM users WHERE username = 'pg;user'")
+		if len(res.Errors) > 0 {
+			t.Fatalf("Unexpected error: %v", res.Errors)
+		}
+		if fmt.Sprint(res.Tags) != "[BEGIN INSERT 0 1 SELECT 1]" {
+			t.Errorf("Unexpected command tags: %v", res.Tags)
+		}
+		if len(res.Rows) != 1 || res.Rows[0][0] == nil || *res.Rows[0][0] != "1" {
+			t.Errorf("Expected COUNT 1, got %v", res.Rows)
+		}
+		if res.TypeOIDs[0] != 20 {
+			t.Errorf("Expected int8 OID for COUNT, got %d", res.TypeOIDs[0])
+		}
+		if res.TxStatus != 'T' {
+			t.Errorf("Expected in-transaction status, got %q", res.TxStatus)
+		}
+
+		res = client.query("ROLLBACK")
+		if fmt.Sprint(res.Tags) != "[ROLLBACK]" || res.TxStatus != 'I' {
+			t.Errorf("Unexpected ROLLBACK response: %+v", res)
+		}
+	})
+
+	t.Run("Returning", func(t *testing.T) {
+		res := client.query("BEGIN; INSERT INTO users (username, email) VALUES ('pg_ret', 'ret@example.com') RETURNING id, username")
+		if len(res.Errors) > 0 {
+			t.Fatalf("Unexpected error: %v", res.Errors)
+		}
+		if fmt.Sprint(res.Tags) != "[BEGIN INSERT 0 1]" {
+			t.Errorf("Unexpected command tags: %v", res.Tags)
+		}
+		if fmt.Sprint(res.Columns) != "[id username]" || res.TypeOIDs[0] != 20 {
+			t.Errorf("Unexpected row description: %v %v", res.Columns, res.TypeOIDs)
+		}
+		if len(res.Rows) != 1 || res.Rows[0][0] == nil || res.Rows[0][1] == nil || *res.Rows[0][1] != "pg_ret" {
+			t.Errorf("Expected the inserted row, got %v", res.Rows)
+		}
+		client.query("ROLLBACK")
+	})
+
+	t.Run("ErrorStopsQuery", func(t *testing.T) {
+		res := client.query("SELECT * FROM non_existent_table; SELECT * FROM users")
+		if len(res.Errors) != 1 || !strings.Contains(res.Errors[0], "table not found") {
+			t.Errorf("Expected table not found error, got %v", res.Errors)
+		}
+		if len(res.Tags) != 0 {
+			t.Errorf("Expected no completed statements, got %v", res.Tags)
+		}
+	})
+
+	t.Run("ErrorCodes", func(t *testing.T) {
+		tests := []struct {
+			sql  string
+			code string
+		}{
+			{"SELECT * FROM non_existent_table", "42P01"},
+			{"SELEC * FROM users", "42601"},
+			{"SELECT * FROM users WHERE", "42601"},
+			{"INSERT INTO users (username, email) VALUES ('admin', 'other@example.com')", "23505"},
+			{"INSERT INTO users (username) VALUES ('no_email')", "23502"},
+			{"SELECT UNKNOWN_FUNCTION(id) FROM users", "42000"},
+		}
+		for _, tt := range tests {
+			res := client.query(tt.sql)
+			if fmt.Sprint(res.Codes) != "["+tt.code+"]" {
+				t.Errorf("%s: expected SQLSTATE %s, got %v (%v)", tt.sql, tt.code, res.Codes, res.Errors)
+			}
+		}
+	})
+
+	t.Run("FailedTransaction", func(t *testing.T) {
+		res := client.query("BEGIN; INSERT INTO users (username, email) VALUES ('pg_failed', 'failed@example.com'); SELECT * FROM non_existent_table")
+		if fmt.Sprint(res.Tags) != "[BEGIN INSERT 0 1]" || res.TxStatus != 'E' {
+			t.Fatalf("Expected failed transaction status after an error, got %+v", res)
+		}
+
+		// Only the end of the transaction is accepted until then
+		res = client.query("SELECT COUNT(*) FROM users")
+		if fmt.Sprint(res.Codes) != "[25P02]" || res.TxStatus != 'E' {
+			t.Errorf("Expected 25P02 in a failed transaction, got %+v", res)
+		}
+		res = client.query("COMMIT")
+		if fmt.Sprint(res.Tags) != "[ROLLBACK]" || res.TxStatus != 'I' {
+			t.Errorf("Expected COMMIT of a failed transaction to roll back, got %+v", res)
+		}
+		res = client.query("SELECT COUNT(*) FROM users WHERE username = 'pg_failed'")
+		if len(res.Rows) != 1 || res.Rows[0][0] == nil || *res.Rows[0][0] != "0" {
+			t.Errorf("Expected the failed transaction's insert to be rolled back, got %v", res.Rows)
+		}
+	})
+
+	t.Run("EmptyQuery", func(t *testing.T) {
+		res := client.query(" ; ")
+		if len(res.Errors) != 0 || len(res.Tags) != 0 {
+			t.Errorf("Unexpected response to empty query: %+v", res)
+		}
+	})
+
+	t.Run("ExtendedProtocolRejected", func(t *testing.T) {
+		client.send('P', []byte("\x00SELECT 1\x00\x00\x00"))
+		client.send('S', nil)
+		res := client.collect()
+		if len(res.Errors) != 1 {
+			t.Errorf("Expected one error for Parse, got %v", res.Errors)
+		}
+	})
+
+	t.Run("UnknownDatabase", func(t *testing.T) {
+		// The name is not spliced into SQL or a path
+		for _, name := range []string{"nope", "testdb_integration; DROP DATABASE testdb_integration", "../integration_test"} {
+			other, msgs := dialPG(t, port, map[string]string{"user": "joy", "database": name})
+			other.conn.Close()
+			if len(msgs) == 0 || msgs[len(msgs)-1].Type != 'E' {
+				t.Errorf("%s: expected FATAL error for unknown database, got %v", name, msgs)
+			}
+		}
+	})
+
+	client.send('X', nil)
+}
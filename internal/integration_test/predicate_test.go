@@ -0,0 +1,71 @@
+package integration
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/leengari/mini-rdbms/internal/engine"
+	"github.com/leengari/mini-rdbms/internal/query/indexing"
+	"github.com/leengari/mini-rdbms/internal/storage/loader"
+)
+
+// TestPredicateOperators verifies NOT, [NOT] IN, [NOT] BETWEEN and [NOT] LIKE/ILIKE filters
+func TestPredicateOperators(t *testing.T) {
+	db, err := loader.LoadDatabase("../../databases/testdb")
+	if err != nil {
+		t.Fatalf("Failed to load database: %v", err)
+	}
+	if err := indexing.BuildDatabaseIndexes(db); err != nil {
+		t.Fatalf("Failed to build indexes: %v", err)
+	}
+
+	eng := engine.New(db, nil)
+
+	tests := []struct {
+		sql      string
+		expected []string
+	}{
+		{"SELECT id FROM users WHERE NOT id = 2", []string{"5", "6", "18", "888"}},
+		{"SELECT id FROM users WHERE id NOT IN (2, 5, 888)", []string{"6", "18"}},
+		{"SELECT id FROM users WHERE id NOT BETWEEN 3 AND 18", []string{"2", "888"}},
+		{"SELECT id FROM users WHERE username LIKE '%e%'", []string{"5", "18"}},
+		{"SELECT id FROM users WHERE username LIKE 'fr_nk'", []string{"6"}},
+		{"SELECT id FROM users WHERE username LIKE 'fr_n'", []string{}},
+		{"SELECT id FROM users WHERE username NOT LIKE '%e%'", []string{"2", "6", "888"}},
+		{"SELECT id FROM users WHERE username LIKE 'BOB'", []string{}},
+		{"SELECT id FROM users WHERE username ILIKE 'BO_'", []string{"2"}},
+		{"SELECT id FROM users WHERE username NOT ILIKE '%E%'", []string{"2", "6", "888"}},
+		{"SELECT id FROM users WHERE username LIKE 'repl\\_%'", []string{"18"}},
+		{"SELECT id FROM users WHERE username LIKE 'repl!_user' ESCAPE '!'", []string{"18"}},
+		{"SELECT id FROM users WHERE username LIKE 'repl!%' ESCAPE '!'", []string{}},
+		{"SELECT id FROM users WHERE id LIKE '8%'", []string{"888"}},
+		{"SELECT id FROM orders WHERE amount BETWEEN 50 AND 300 AND NOT product LIKE 'm%'", []string{"3"}},
+		{"SELECT id FROM orders WHERE NOT (amount < 100 OR product ILIKE 'LAP%')", []string{"4"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.sql, func(t *testing.T) {
+			result, err := eng.Execute(tt.sql)
+			if err != nil {
+				t.Fatalf("Execute failed: %v", err)
+			}
+
+			got := make([]string, 0, len(result.Rows))
+			for _, row := range result.Rows {
+				got = append(got, fmt.Sprint(row.Data["id"]))
+			}
+			if fmt.Sprint(got) != fmt.Sprint(tt.expected) {
+				t.Errorf("Expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+
+	t.Run("Invalid ESCAPE", func(t *testing.T) {
+		if _, err := eng.Execute("SELECT id FROM users WHERE username LIKE 'a' ESCAPE 'ab'"); err == nil {
+			t.Error("Expected error for multi-character ESCAPE")
+		}
+		if _, err := eng.Execute("SELECT id FROM users WHERE username LIKE 'a!' ESCAPE '!'"); err == nil {
+			t.Error("Expected error for pattern ending in the escape character")
+		}
+	})
+}
@@ -0,0 +1,134 @@
+package integration
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/leengari/mini-rdbms/internal/engine"
+	storageEngine "github.com/leengari/mini-rdbms/internal/storage/engine"
+	"github.com/leengari/mini-rdbms/internal/storage/manager"
+)
+
+// TestReturning verifies RETURNING on INSERT, UPDATE and DELETE: the rows returned,
+// their column metadata, and that the statement's own result is unchanged
+func TestReturning(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "rdbms_returning_test")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	registry := manager.NewRegistry(tmpDir, storageEngine.NewJSONEngine())
+	eng := engine.New(nil, registry)
+	for _, sql := range []string{
+		"CREATE DATABASE shop",
+		"USE shop",
+		"CREATE TABLE items (id INT PRIMARY KEY AUTO_INCREMENT, name TEXT NOT NULL UNIQUE, price FLOAT, stock INT DEFAULT 10)",
+	} {
+		if _, err := eng.Execute(sql); err != nil {
+			t.Fatalf("%s failed: %v", sql, err)
+		}
+	}
+
+	// rowValues formats a result's rows in column order
+	rowValues := func(columns []string, rows []map[string]interface{}) string {
+		out := ""
+		for _, row := range rows {
+			for i, col := range columns {
+				if i > 0 {
+					out += ","
+				}
+				out += fmt.Sprint(row[col])
+			}
+			out += ";"
+		}
+		return out
+	}
+
+	tests := []struct {
+		name     string
+		sql      string
+		message  string
+		columns  string
+		types    string
+		expected string
+	}{
+		{"INSERT generated id", "INSERT INTO items (name, price) VALUES ('apple', 1.5), ('pear', 2.0) RETURNING id",
+			"INSERT 2", "[id]", "[INT]", "1;2;"},
+		{"INSERT star includes defaults", "INSERT INTO items (name) VALUES ('plum') RETURNING *",
+			"INSERT 1", "[id name price stock]", "[INT TEXT FLOAT INT]", "3,plum,<nil>,10;"},
+		{"INSERT SELECT", "INSERT INTO items (name, price) SELECT name || '2', price FROM items WHERE price IS NOT NULL RETURNING id, name",
+			"INSERT 2", "[id name]", "[INT TEXT]", "4,apple2;5,pear2;"},
+		{"UPDATE post-image with expressions", "UPDATE items SET price = price * 2 WHERE id <= 2 RETURNING id, price AS new_price, UPPER(name)",
+			"UPDATE 2", "[id new_price UPPER(name)]", "[INT FLOAT TEXT]", "1,3,APPLE;2,4,PEAR;"},
+		{"UPDATE no rows", "UPDATE items SET price = 1.0 WHERE id > 100 RETURNING id",
+			"UPDATE 0", "[id]", "[INT]", ""},
+		{"DELETE pre-image", "DELETE FROM items WHERE name LIKE '%2' RETURNING items.id, name",
+			"DELETE 2", "[id name]", "[INT TEXT]", "4,apple2;5,pear2;"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := eng.Execute(tt.sql)
+			if err != nil {
+				t.Fatalf("%s failed: %v", tt.sql, err)
+			}
+			if res.Message != tt.message {
+				t.Errorf("Expected message %q, got %q", tt.message, res.Message)
+			}
+			if fmt.Sprint(res.Columns) != tt.columns {
+				t.Errorf("Expected columns %s, got %v", tt.columns, res.Columns)
+			}
+			types := make([]string, len(res.Metadata))
+			for i, m := range res.Metadata {
+				types[i] = m.Type
+			}
+			if fmt.Sprint(types) != tt.types {
+				t.Errorf("Expected types %s, got %v", tt.types, types)
+			}
+			rows := make([]map[string]interface{}, len(res.Rows))
+			for i, row := range res.Rows {
+				rows[i] = row.Data
+			}
+			if got := rowValues(res.Columns, rows); got != tt.expected {
+				t.Errorf("Expected rows %q, got %q", tt.expected, got)
+			}
+		})
+	}
+
+	t.Run("Without RETURNING", func(t *testing.T) {
+		res, err := eng.Execute("INSERT INTO items (name) VALUES ('fig')")
+		if err != nil {
+			t.Fatalf("Insert failed: %v", err)
+		}
+		if res.Columns != nil || len(res.Rows) != 0 {
+			t.Errorf("Expected no result set, got %v %v", res.Columns, res.Rows)
+		}
+	})
+
+	t.Run("Failed statement returns nothing", func(t *testing.T) {
+		if _, err := eng.Execute("INSERT INTO items (name) VALUES ('kiwi'), ('fig') RETURNING id"); err == nil {
+			t.Fatal("Expected unique constraint violation")
+		}
+		got := queryColumn(t, eng, "SELECT id FROM items WHERE name = 'kiwi'", "id")
+		if len(got) != 0 {
+			t.Errorf("Expected kiwi not to be inserted, got %v", got)
+		}
+	})
+
+	t.Run("Errors", func(t *testing.T) {
+		for _, sql := range []string{
+			"INSERT INTO items (name) VALUES ('x') RETURNING missing",
+			"UPDATE items SET price = 1.0 RETURNING COUNT(*)",
+			"DELETE FROM items RETURNING id, id",
+			"DELETE FROM items RETURNING other.id",
+			"UPDATE items SET price = 1.0 RETURNING (SELECT MAX(id) FROM items)",
+			"DELETE FROM items RETURNING",
+		} {
+			if _, err := eng.Execute(sql); err == nil {
+				t.Errorf("Expected error for %s", sql)
+			}
+		}
+	})
+}
@@ -0,0 +1,196 @@
+package integration
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/leengari/mini-rdbms/internal/engine"
+	storageEngine "github.com/leengari/mini-rdbms/internal/storage/engine"
+	"github.com/leengari/mini-rdbms/internal/storage/manager"
+	"github.com/leengari/mini-rdbms/internal/storage/wal"
+)
+
+// itemRowIDs returns the row ids of the items table, in storage order
+func itemRowIDs(t *testing.T, registry *manager.Registry) string {
+	t.Helper()
+
+	db, err := registry.Get("shop")
+	if err != nil {
+		t.Fatalf("Failed to get shop: %v", err)
+	}
+	table := db.Tables["items"]
+	table.RLock()
+	defer table.RUnlock()
+
+	ids := make([]int64, len(table.Rows))
+	for i, row := range table.Rows {
+		ids[i] = row.ID
+	}
+	return fmt.Sprint(ids)
+}
+
+// lastWALRecord returns the newest record of shop's write-ahead log
+func lastWALRecord(t *testing.T, basePath string) wal.Record {
+	t.Helper()
+
+	content, err := os.ReadFile(filepath.Join(basePath, "shop", wal.FileName))
+	if err != nil {
+		t.Fatalf("Failed to read wal: %v", err)
+	}
+	lines := bytes.Split(bytes.TrimSpace(content), []byte("\n"))
+	var rec wal.Record
+	if err := json.Unmarshal(lines[len(lines)-1], &rec); err != nil {
+		t.Fatalf("Failed to decode wal record: %v", err)
+	}
+	return rec
+}
+
+// TestRowIDs verifies that rows keep their hidden row ids through deletes, rollbacks,
+// WAL replay and restarts, under both storage engines
+func TestRowIDs(t *testing.T) {
+	engines := map[string]func() storageEngine.StorageEngine{
+		"json": func() storageEngine.StorageEngine { return storageEngine.NewJSONEngine() },
+		"page": func() storageEngine.StorageEngine { return storageEngine.NewPageEngine(16) },
+	}
+
+	for name, newEngine := range engines {
+		t.Run(name, func(t *testing.T) {
+			tmpDir, err := os.MkdirTemp("", "rdbms_rowid_test")
+			if err != nil {
+				t.Fatalf("Failed to create temp dir: %v", err)
+			}
+			defer os.RemoveAll(tmpDir)
+
+			open := func() (*engine.Engine, *manager.Registry) {
+				registry := manager.NewRegistry(tmpDir, newEngine())
+				return engine.New(nil, registry), registry
+			}
+			exec := func(eng *engine.Engine, sqls ...string) {
+				t.Helper()
+				for _, sql := range sqls {
+					if _, err := eng.Execute(sql); err != nil {
+						t.Fatalf("%s failed: %v", sql, err)
+					}
+				}
+			}
+
+			eng, registry := open()
+			exec(eng,
+				"CREATE DATABASE shop",
+				"USE shop",
+				"CREATE TABLE items (id INT PRIMARY KEY AUTO_INCREMENT, name TEXT NOT NULL, qty INT)",
+				"INSERT INTO items (name, qty) VALUES ('apple', 5), ('pear', 2), ('plum', 7)",
+				"DELETE FROM items WHERE name = 'pear'",
+			)
+
+			// Ids are not reused and the log names the deleted row by id
+			if rec := lastWALRecord(t, tmpDir); rec.Version != wal.RecordVersion || rec.Changes[0].RowID != 2 {
+				t.Errorf("Expected a version %d delete of row id 2, got %+v", wal.RecordVersion, rec)
+			}
+			exec(eng, "INSERT INTO items (name, qty) VALUES ('fig', 1)")
+			if ids := itemRowIDs(t, registry); ids != "[1 3 4]" {
+				t.Errorf("Expected row ids [1 3 4], got %s", ids)
+			}
+
+			t.Run("Rollback restores ids and indexes", func(t *testing.T) {
+				exec(eng, "BEGIN", "DELETE FROM items WHERE id = 1", "UPDATE items SET qty = 0 WHERE id = 3", "ROLLBACK")
+				if ids := itemRowIDs(t, registry); ids != "[1 3 4]" {
+					t.Errorf("Expected row ids [1 3 4], got %s", ids)
+				}
+				// Lookups through the primary key index find the restored rows
+				assertItems(t, eng, map[string]string{"1": "apple:5", "3": "plum:7", "4": "fig:1"})
+				res, err := eng.Execute("SELECT name FROM items WHERE id = 1")
+				if err != nil || len(res.Rows) != 1 || res.Rows[0].Data["name"] != "apple" {
+					t.Errorf("Expected apple by primary key, got %v, %v", res, err)
+				}
+			})
+
+			t.Run("WAL replay", func(t *testing.T) {
+				eng, registry = open()
+				exec(eng, "USE shop")
+				if ids := itemRowIDs(t, registry); ids != "[1 3 4]" {
+					t.Errorf("Expected row ids [1 3 4] after replay, got %s", ids)
+				}
+			})
+
+			t.Run("Table files", func(t *testing.T) {
+				saveAll(registry)
+				eng, registry = open()
+				exec(eng, "USE shop", "INSERT INTO items (name, qty) VALUES ('kiwi', 6)")
+				if ids := itemRowIDs(t, registry); ids != "[1 3 4 5]" {
+					t.Errorf("Expected row ids [1 3 4 5] after restart, got %s", ids)
+				}
+				assertItems(t, eng, map[string]string{"1": "apple:5", "3": "plum:7", "4": "fig:1", "5": "kiwi:6"})
+			})
+
+			t.Run("Reserved column name", func(t *testing.T) {
+				if _, err := eng.Execute("CREATE TABLE bad (_rowid INT)"); err == nil {
+					t.Error("Expected _rowid to be refused as a column name")
+				}
+				if _, err := eng.Execute("ALTER TABLE items RENAME COLUMN qty TO _rowid"); err == nil {
+					t.Error("Expected renaming a column to _rowid to be refused")
+				}
+			})
+		})
+	}
+}
+
+// TestLegacyRowFormat verifies that table files written before rows had ids still load
+// (rows are numbered in file order), while WAL records without a version are refused
+func TestLegacyRowFormat(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "rdbms_legacy_rows_test")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	eng := engine.New(nil, manager.NewRegistry(tmpDir, storageEngine.NewJSONEngine()))
+	for _, sql := range []string{
+		"CREATE DATABASE shop",
+		"USE shop",
+		"CREATE TABLE items (id INT PRIMARY KEY AUTO_INCREMENT, name TEXT NOT NULL, qty INT)",
+	} {
+		if _, err := eng.Execute(sql); err != nil {
+			t.Fatalf("%s failed: %v", sql, err)
+		}
+	}
+
+	tableDir := filepath.Join(tmpDir, "shop", "items")
+	legacyRows := `[{"id": 1, "name": "apple", "qty": 5}, {"id": 2, "name": "pear", "qty": 2}, {"id": 3, "name": "plum", "qty": 7}]`
+	if err := os.WriteFile(filepath.Join(tableDir, "data.json"), []byte(legacyRows), 0644); err != nil {
+		t.Fatalf("Failed to write data.json: %v", err)
+	}
+	walPath := filepath.Join(tmpDir, "shop", wal.FileName)
+	if err := os.WriteFile(walPath, nil, 0644); err != nil {
+		t.Fatalf("Failed to reset wal: %v", err)
+	}
+
+	eng, registry := openShop(t, tmpDir)
+	assertItems(t, eng, map[string]string{"1": "apple:5", "2": "pear:2", "3": "plum:7"})
+	if ids := itemRowIDs(t, registry); ids != "[1 2 3]" {
+		t.Errorf("Expected row ids [1 2 3], got %s", ids)
+	}
+
+	// New changes are logged by id and replay over the same numbering
+	if _, err := eng.Execute("DELETE FROM items WHERE name = 'pear'"); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	eng, _ = openShop(t, tmpDir)
+	assertItems(t, eng, map[string]string{"1": "apple:5", "3": "plum:7"})
+
+	// A record without a version refers to rows by position and cannot be replayed
+	legacyWAL := `{"lsn":9,"tx_id":"a","changes":[{"type":"DELETE","table":"items","row_id":1,"old_data":{"id":3,"name":"plum","qty":7}}]}
+`
+	if err := os.WriteFile(walPath, []byte(legacyWAL), 0644); err != nil {
+		t.Fatalf("Failed to write wal: %v", err)
+	}
+	_, err = manager.NewRegistry(tmpDir, storageEngine.NewJSONEngine()).Get("shop")
+	if err == nil || !strings.Contains(err.Error(), "unsupported version 0") {
+		t.Errorf("Expected recovery to refuse a record without a version, got %v", err)
+	}
+}
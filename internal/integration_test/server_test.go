@@ -1,8 +1,10 @@
 package integration
 
 import (
+	"context"
 	"fmt"
 	"net"
+	"os"
 	"path/filepath"
 	"strings"
 	"testing"
@@ -11,6 +13,7 @@ import (
 	"encoding/json"
 
 	"github.com/leengari/mini-rdbms/internal/domain/data"
+	"github.com/leengari/mini-rdbms/internal/engine"
 	"github.com/leengari/mini-rdbms/internal/network"
 	storageEngine "github.com/leengari/mini-rdbms/internal/storage/engine"
 	"github.com/leengari/mini-rdbms/internal/storage/manager"
@@ -42,7 +45,9 @@ func TestServerJSON(t *testing.T) {
 	registry := manager.NewRegistry(basePath, storageEng)
 
 	// Start server in goroutine
-	go network.Start(port, registry)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go network.Start(ctx, port, registry)
 
 	// Wait a bit for server
 	time.Sleep(100 * time.Millisecond)
@@ -133,3 +138,97 @@ func TestServerJSON(t *testing.T) {
 		}
 	}
 }
+
+// TestServerShutdown verifies that cancelling the server's context ends open sessions,
+// rolls back their transactions and leaves committed changes to be saved
+func TestServerShutdown(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "rdbms_shutdown_test")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	registry := manager.NewRegistry(tmpDir, storageEngine.NewJSONEngine())
+	eng := engine.New(nil, registry)
+	for _, sql := range []string{
+		"CREATE DATABASE shop",
+		"USE shop",
+		"CREATE TABLE items (id INT PRIMARY KEY AUTO_INCREMENT, name TEXT NOT NULL, qty INT)",
+	} {
+		if _, err := eng.Execute(sql); err != nil {
+			t.Fatalf("%s failed: %v", sql, err)
+		}
+	}
+
+	port := 54324
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	stopped := make(chan error, 1)
+	go func() { stopped <- network.Start(ctx, port, registry) }()
+	time.Sleep(100 * time.Millisecond)
+
+	if err := network.Start(context.Background(), port, registry); err == nil {
+		t.Error("Expected binding a port in use to fail")
+	}
+
+	type session struct {
+		conn    net.Conn
+		encoder *json.Encoder
+		decoder *json.Decoder
+	}
+	dial := func() *session {
+		conn, err := net.Dial("tcp", fmt.Sprintf("localhost:%d", port))
+		if err != nil {
+			t.Fatalf("Failed to connect: %v", err)
+		}
+		return &session{conn: conn, encoder: json.NewEncoder(conn), decoder: json.NewDecoder(conn)}
+	}
+	receive := func(s *session) Result {
+		var res Result
+		if err := s.decoder.Decode(&res); err != nil {
+			t.Fatalf("Failed to decode JSON: %v", err)
+		}
+		return res
+	}
+	send := func(s *session, queries ...string) {
+		for _, query := range queries {
+			if err := s.encoder.Encode(network.Request{Query: query}); err != nil {
+				t.Fatalf("Failed to send query: %v", err)
+			}
+			if res := receive(s); res.Error != "" {
+				t.Fatalf("%s failed: %s", query, res.Error)
+			}
+		}
+	}
+
+	committed, pending := dial(), dial()
+	defer committed.conn.Close()
+	defer pending.conn.Close()
+	send(committed, "USE shop", "INSERT INTO items (name, qty) VALUES ('apple', 5)")
+	send(pending, "USE shop", "BEGIN", "INSERT INTO items (name, qty) VALUES ('pear', 2)")
+
+	cancel()
+	select {
+	case err := <-stopped:
+		if err != nil {
+			t.Errorf("Expected a clean shutdown, got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for the server to stop")
+	}
+
+	// Idle sessions are told why they were disconnected
+	for _, s := range []*session{committed, pending} {
+		if res := receive(s); res.Error != "server is shutting down" {
+			t.Errorf("Expected a shutdown error, got %+v", res)
+		}
+	}
+	if _, err := net.Dial("tcp", fmt.Sprintf("localhost:%d", port)); err == nil {
+		t.Error("Expected the listener to be closed")
+	}
+
+	// The open transaction was rolled back, so the final save does not wait for it
+	saveAll(registry)
+	reopened, _ := openShop(t, tmpDir)
+	assertItems(t, reopened, map[string]string{"1": "apple:5"})
+}
@@ -0,0 +1,148 @@
+package integration
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/leengari/mini-rdbms/internal/engine"
+	"github.com/leengari/mini-rdbms/internal/parser"
+	"github.com/leengari/mini-rdbms/internal/parser/lexer"
+	"github.com/leengari/mini-rdbms/internal/plan"
+	"github.com/leengari/mini-rdbms/internal/planner"
+	storageEngine "github.com/leengari/mini-rdbms/internal/storage/engine"
+	"github.com/leengari/mini-rdbms/internal/storage/loader"
+	"github.com/leengari/mini-rdbms/internal/storage/manager"
+)
+
+// TestSetOperations verifies UNION, INTERSECT and EXCEPT with and without ALL,
+// ORDER BY and LIMIT over the combined rows, and plan-time column checks
+func TestSetOperations(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "rdbms_set_operation_test")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	registry := manager.NewRegistry(tmpDir, storageEngine.NewJSONEngine())
+	eng := engine.New(nil, registry)
+	for _, sql := range []string{
+		"CREATE DATABASE sets",
+		"USE sets",
+		"CREATE TABLE staff (id INT PRIMARY KEY AUTO_INCREMENT, name TEXT NOT NULL, city TEXT, salary INT)",
+		"CREATE TABLE customers (id INT PRIMARY KEY AUTO_INCREMENT, name TEXT NOT NULL, city TEXT, balance FLOAT)",
+		"INSERT INTO staff (name, city, salary) VALUES ('Ann', 'Oslo', 100)",
+		"INSERT INTO staff (name, city, salary) VALUES ('Bob', 'Rome', 200)",
+		"INSERT INTO staff (name, city, salary) VALUES ('Cy', 'Oslo', 300)",
+		"INSERT INTO staff (name, salary) VALUES ('Dee', 400)",
+		"INSERT INTO customers (name, city, balance) VALUES ('Bob', 'Rome', 1.5)",
+		"INSERT INTO customers (name, city, balance) VALUES ('Eve', 'Oslo', 2.5)",
+		"INSERT INTO customers (name, city, balance) VALUES ('Fay', 'Lima', 3.5)",
+		"INSERT INTO customers (name, balance) VALUES ('Gus', 4.5)",
+	} {
+		if _, err := eng.Execute(sql); err != nil {
+			t.Fatalf("%s failed: %v", sql, err)
+		}
+	}
+
+	tests := []struct {
+		name     string
+		sql      string
+		column   string
+		expected string
+	}{
+		{"UNION", "SELECT city FROM staff UNION SELECT city FROM customers ORDER BY city", "city", "[Lima Oslo Rome <nil>]"},
+		{"UNION ALL", "SELECT city FROM staff UNION ALL SELECT city FROM customers ORDER BY city", "city", "[Lima Oslo Oslo Oslo Rome Rome <nil> <nil>]"},
+		{"INTERSECT", "SELECT city FROM staff INTERSECT SELECT city FROM customers ORDER BY city", "city", "[Oslo Rome <nil>]"},
+		{"INTERSECT ALL", "SELECT city FROM staff INTERSECT ALL SELECT city FROM customers ORDER BY city", "city", "[Oslo Rome <nil>]"},
+		{"EXCEPT", "SELECT city FROM customers EXCEPT SELECT city FROM staff", "city", "[Lima]"},
+		{"EXCEPT ALL", "SELECT city FROM staff EXCEPT ALL SELECT city FROM customers", "city", "[Oslo]"},
+		{"Several columns", "SELECT name, city FROM staff INTERSECT SELECT name, city FROM customers", "name", "[Bob]"},
+		{"Output named after the left query", "SELECT name AS who FROM staff WHERE salary > 250 UNION SELECT name FROM customers WHERE balance > 3 ORDER BY who DESC", "who", "[Gus Fay Dee Cy]"},
+		{"LIMIT and OFFSET", "SELECT name FROM staff UNION ALL SELECT name FROM customers ORDER BY name LIMIT 3 OFFSET 2", "name", "[Bob Cy Dee]"},
+		{"INTERSECT binds tighter", "SELECT name FROM staff WHERE id = 1 UNION SELECT name FROM staff INTERSECT SELECT name FROM customers ORDER BY name", "name", "[Ann Bob]"},
+		{"Left to right", "SELECT name FROM staff UNION SELECT name FROM customers EXCEPT SELECT name FROM customers ORDER BY name", "name", "[Ann Cy Dee]"},
+		{"INT and FLOAT", "SELECT salary FROM staff WHERE id = 1 UNION SELECT balance FROM customers WHERE id = 1 ORDER BY salary", "salary", "[1.5 100]"},
+		{"NULL column", "SELECT name, city FROM staff WHERE id = 1 UNION ALL SELECT name, NULL FROM customers WHERE id = 2", "city", "[Oslo <nil>]"},
+		{"Aggregates", "SELECT COUNT(*) AS n FROM staff UNION ALL SELECT COUNT(*) FROM customers WHERE city IS NOT NULL", "n", "[4 3]"},
+		{"WITH", "WITH oslo AS (SELECT name FROM staff WHERE city = 'Oslo') SELECT name FROM oslo EXCEPT SELECT name FROM staff WHERE salary > 200", "name", "[Ann]"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := queryColumn(t, eng, tt.sql, tt.column)
+			if fmt.Sprint(got) != tt.expected {
+				t.Errorf("Expected %s, got %v", tt.expected, got)
+			}
+		})
+	}
+
+	t.Run("Result metadata", func(t *testing.T) {
+		res, err := eng.Execute("SELECT name, salary FROM staff UNION SELECT name, balance FROM customers")
+		if err != nil {
+			t.Fatalf("Query failed: %v", err)
+		}
+		if fmt.Sprint(res.Columns) != "[name salary]" {
+			t.Errorf("Expected columns [name salary], got %v", res.Columns)
+		}
+		types := make([]string, len(res.Metadata))
+		for i, m := range res.Metadata {
+			types[i] = m.Type
+		}
+		if fmt.Sprint(types) != "[TEXT FLOAT]" {
+			t.Errorf("Expected types [TEXT FLOAT], got %v", types)
+		}
+		if len(res.Rows) != 8 {
+			t.Errorf("Expected 8 rows, got %d", len(res.Rows))
+		}
+	})
+
+	t.Run("Errors", func(t *testing.T) {
+		for _, sql := range []string{
+			"SELECT name, city FROM staff UNION SELECT name FROM customers",
+			"SELECT name FROM staff UNION SELECT salary FROM staff",
+			"SELECT id, id FROM staff UNION SELECT id, salary FROM staff",
+			"SELECT name FROM staff UNION SELECT name FROM customers ORDER BY city",
+			"SELECT name FROM staff UNION SELECT name FROM customers ORDER BY staff.name",
+			"SELECT name FROM staff EXCEPT SELECT name FROM missing",
+		} {
+			if _, err := eng.Execute(sql); err == nil {
+				t.Errorf("Expected error for %s", sql)
+			}
+		}
+	})
+}
+
+// TestSetOperationPlan verifies the plan tree of a set operation
+func TestSetOperationPlan(t *testing.T) {
+	db, err := loader.LoadDatabase("../../databases/testdb")
+	if err != nil {
+		t.Fatalf("Failed to load database: %v", err)
+	}
+
+	tokens, err := lexer.Tokenize("SELECT id FROM users UNION ALL SELECT user_id FROM orders ORDER BY id LIMIT 5")
+	if err != nil {
+		t.Fatalf("Lexer error: %v", err)
+	}
+	stmt, err := parser.New(tokens).Parse()
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	node, err := planner.Plan(stmt, db, nil)
+	if err != nil {
+		t.Fatalf("Planner error: %v", err)
+	}
+
+	expected := []string{"SELECT", "  LIMIT", "    SORT", "      UNION_ALL", "        SELECT", "        SELECT"}
+	got := strings.Split(strings.TrimRight(plan.PrintTree(node), "\n"), "\n")
+	if len(got) < len(expected) {
+		t.Fatalf("Expected tree starting with %v, got %v", expected, got)
+	}
+	for i, line := range expected {
+		if got[i] != line {
+			t.Errorf("Expected tree starting with %v, got %v", expected, got)
+			break
+		}
+	}
+}
@@ -248,7 +248,7 @@ func TestSQLCombinedOperations(t *testing.T) {
 		}
 
 		// UPDATE
-		updateSQL := "UPDATE users SET email = 'updated@example.com' WHERE id = 997;"
+		updateSQL := "UPDATE users SET email = 'updated997@example.com' WHERE id = 997;"
 		result, err = eng.Execute(updateSQL)
 		if err != nil {
 			t.Fatalf("UPDATE failed: %v", err)
@@ -260,7 +260,7 @@ func TestSQLCombinedOperations(t *testing.T) {
 		// Verify UPDATE
 		selectSQL := "SELECT email FROM users WHERE id = 997;"
 		result, _ = eng.Execute(selectSQL)
-		if len(result.Rows) > 0 && result.Rows[0].Data["email"] != "updated@example.com" {
+		if len(result.Rows) > 0 && result.Rows[0].Data["email"] != "updated997@example.com" {
 			t.Errorf("Email was not updated correctly")
 		}
 
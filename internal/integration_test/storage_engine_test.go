@@ -0,0 +1,210 @@
+package integration
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/leengari/mini-rdbms/internal/domain/transaction"
+	"github.com/leengari/mini-rdbms/internal/engine"
+	"github.com/leengari/mini-rdbms/internal/storage/convert"
+	storageEngine "github.com/leengari/mini-rdbms/internal/storage/engine"
+	"github.com/leengari/mini-rdbms/internal/storage/manager"
+	"github.com/leengari/mini-rdbms/internal/storage/metadata"
+)
+
+// snapshotItems returns every items row, in storage order, as one string
+func snapshotItems(t *testing.T, eng *engine.Engine) string {
+	t.Helper()
+
+	res, err := eng.Execute("SELECT * FROM items")
+	if err != nil {
+		t.Fatalf("Failed to select items: %v", err)
+	}
+	var rows []string
+	for _, row := range res.Rows {
+		rows = append(rows, fmt.Sprintf("%v|%v|%v|%v|%v", row.Data["id"], row.Data["name"], row.Data["price"], row.Data["active"], row.Data["added"]))
+	}
+	return strings.Join(rows, "\n")
+}
+
+// saveAll checkpoints every loaded database of a registry
+func saveAll(registry *manager.Registry) {
+	tx := transaction.NewTransaction()
+	defer tx.Close()
+	registry.SaveAll(tx)
+}
+
+// TestPageStorage verifies that the page engine keeps rows, their order, NULLs and
+// long values across restarts while tables change between checkpoints
+func TestPageStorage(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "rdbms_page_storage_test")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	// A tiny buffer pool forces pages to be evicted and read back
+	open := func() (*engine.Engine, *manager.Registry) {
+		registry := manager.NewRegistry(tmpDir, storageEngine.NewPageEngine(4))
+		return engine.New(nil, registry), registry
+	}
+
+	eng, registry := open()
+	for _, sql := range []string{
+		"CREATE DATABASE shop",
+		"USE shop",
+		"CREATE TABLE items (id INT PRIMARY KEY AUTO_INCREMENT, name TEXT NOT NULL, price FLOAT, active BOOL, added DATE)",
+	} {
+		if _, err := eng.Execute(sql); err != nil {
+			t.Fatalf("%s failed: %v", sql, err)
+		}
+	}
+	for i := 0; i < 300; i++ {
+		sql := fmt.Sprintf("INSERT INTO items (name, price, active, added) VALUES ('item %d', %d.5, %v, '2024-01-%02d')", i, i, i%2 == 0, i%28+1)
+		if i%7 == 0 {
+			sql = fmt.Sprintf("INSERT INTO items (name) VALUES ('item %d')", i)
+		}
+		if _, err := eng.Execute(sql); err != nil {
+			t.Fatalf("%s failed: %v", sql, err)
+		}
+	}
+	long := strings.Repeat("long text ", 3000)
+	if _, err := eng.Execute(fmt.Sprintf("INSERT INTO items (name) VALUES ('%s')", long)); err != nil {
+		t.Fatalf("Insert failed: %v", err)
+	}
+
+	steps := []struct {
+		name string
+		sql  []string
+	}{
+		{"Initial rows", nil},
+		{"Updates and deletes", []string{
+			"UPDATE items SET price = price * 2 WHERE id % 3 = 0",
+			"DELETE FROM items WHERE id % 5 = 0",
+			"UPDATE items SET name = 'renamed' WHERE id = 2",
+		}},
+		{"Inserts after deletes", []string{
+			"INSERT INTO items (name, price) VALUES ('late 1', 1.0), ('late 2', 2.0)",
+			"DELETE FROM items WHERE id = 301",
+		}},
+		{"Everything deleted", []string{
+			"DELETE FROM items",
+			"INSERT INTO items (name) VALUES ('only')",
+		}},
+	}
+	for _, step := range steps {
+		t.Run(step.name, func(t *testing.T) {
+			for _, sql := range step.sql {
+				if _, err := eng.Execute(sql); err != nil {
+					t.Fatalf("%s failed: %v", sql, err)
+				}
+			}
+			want := snapshotItems(t, eng)
+			saveAll(registry)
+
+			eng, registry = open()
+			if _, err := eng.Execute("USE shop"); err != nil {
+				t.Fatalf("Failed to use shop: %v", err)
+			}
+			if got := snapshotItems(t, eng); got != want {
+				t.Errorf("Rows changed across restart:\nexpected\n%.300s\ngot\n%.300s", want, got)
+			}
+		})
+	}
+
+	t.Run("Files", func(t *testing.T) {
+		tableDir := filepath.Join(tmpDir, "shop", "items")
+		if _, err := os.Stat(filepath.Join(tableDir, "data.pages")); err != nil {
+			t.Errorf("Expected data.pages: %v", err)
+		}
+		if _, err := os.Stat(filepath.Join(tableDir, "data.json")); !os.IsNotExist(err) {
+			t.Error("Expected no data.json under page storage")
+		}
+	})
+
+	t.Run("Wrong engine", func(t *testing.T) {
+		if _, err := manager.NewRegistry(tmpDir, storageEngine.NewJSONEngine()).Get("shop"); err == nil {
+			t.Error("Expected the JSON engine to refuse a page database")
+		}
+	})
+}
+
+// TestConvertStorage verifies converting a database from JSON to page storage and back,
+// including changes only recorded in its write-ahead log
+func TestConvertStorage(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "rdbms_convert_test")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	registry := manager.NewRegistry(tmpDir, storageEngine.NewJSONEngine())
+	eng := engine.New(nil, registry)
+	for _, sql := range []string{
+		"CREATE DATABASE shop",
+		"USE shop",
+		"CREATE TABLE items (id INT PRIMARY KEY AUTO_INCREMENT, name TEXT NOT NULL, price FLOAT, active BOOL, added DATE)",
+		"CREATE TABLE tags (label TEXT UNIQUE)",
+		"INSERT INTO items (name, price, active, added) VALUES ('apple', 1.5, true, '2024-01-01'), ('pear', NULL, false, NULL)",
+		"INSERT INTO tags (label) VALUES ('fruit'), ('fresh')",
+	} {
+		if _, err := eng.Execute(sql); err != nil {
+			t.Fatalf("%s failed: %v", sql, err)
+		}
+	}
+	saveAll(registry)
+
+	// Not checkpointed: only in the write-ahead log
+	if _, err := eng.Execute("INSERT INTO items (name, price) VALUES ('plum', 3.0)"); err != nil {
+		t.Fatalf("Insert failed: %v", err)
+	}
+	want := snapshotItems(t, eng)
+	wantTags := fmt.Sprint(queryColumn(t, eng, "SELECT label FROM tags", "label"))
+
+	for _, storage := range []string{metadata.StoragePage, metadata.StorageJSON} {
+		t.Run("To "+storage, func(t *testing.T) {
+			if err := convert.Database(tmpDir, "shop", storage); err != nil {
+				t.Fatalf("Conversion failed: %v", err)
+			}
+			if err := convert.Database(tmpDir, "shop", storage); err == nil {
+				t.Error("Expected converting to the current format to fail")
+			}
+
+			target, err := storageEngine.New(storage)
+			if err != nil {
+				t.Fatalf("Failed to create engine: %v", err)
+			}
+			eng := engine.New(nil, manager.NewRegistry(tmpDir, target))
+			if _, err := eng.Execute("USE shop"); err != nil {
+				t.Fatalf("Failed to use shop: %v", err)
+			}
+			if got := snapshotItems(t, eng); got != want {
+				t.Errorf("Expected items\n%s\ngot\n%s", want, got)
+			}
+			if got := fmt.Sprint(queryColumn(t, eng, "SELECT label FROM tags", "label")); got != wantTags {
+				t.Errorf("Expected tags %s, got %s", wantTags, got)
+			}
+
+			// The auto-increment sequence carries over
+			for _, sql := range []string{"BEGIN", "INSERT INTO items (name) VALUES ('next')"} {
+				if _, err := eng.Execute(sql); err != nil {
+					t.Fatalf("%s failed: %v", sql, err)
+				}
+			}
+			if got := queryColumn(t, eng, "SELECT id FROM items WHERE name = 'next'", "id"); fmt.Sprint(got) != "[4]" {
+				t.Errorf("Expected the next id to be 4, got %v", got)
+			}
+			if _, err := eng.Execute("ROLLBACK"); err != nil {
+				t.Fatalf("Rollback failed: %v", err)
+			}
+		})
+	}
+
+	entries, _ := os.ReadDir(tmpDir)
+	if len(entries) != 1 {
+		t.Errorf("Expected only the shop directory to remain, got %d entries", len(entries))
+	}
+}
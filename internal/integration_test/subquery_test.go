@@ -0,0 +1,218 @@
+package integration
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/leengari/mini-rdbms/internal/engine"
+	"github.com/leengari/mini-rdbms/internal/parser"
+	"github.com/leengari/mini-rdbms/internal/parser/lexer"
+	"github.com/leengari/mini-rdbms/internal/plan"
+	"github.com/leengari/mini-rdbms/internal/planner"
+	storageEngine "github.com/leengari/mini-rdbms/internal/storage/engine"
+	"github.com/leengari/mini-rdbms/internal/storage/loader"
+	"github.com/leengari/mini-rdbms/internal/storage/manager"
+)
+
+// TestSubqueries verifies IN, EXISTS and scalar subqueries (correlated and uncorrelated),
+// derived tables in FROM and JOIN, and subqueries in UPDATE and DELETE
+func TestSubqueries(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "rdbms_subquery_test")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	registry := manager.NewRegistry(tmpDir, storageEngine.NewJSONEngine())
+	eng := engine.New(nil, registry)
+	for _, sql := range []string{
+		"CREATE DATABASE shop",
+		"USE shop",
+		"CREATE TABLE users (id INT PRIMARY KEY AUTO_INCREMENT, name TEXT NOT NULL, dept TEXT)",
+		"CREATE TABLE orders (id INT PRIMARY KEY AUTO_INCREMENT, user_id INT, amount FLOAT)",
+		"INSERT INTO users (name, dept) VALUES ('Ann', 'eng')",
+		"INSERT INTO users (name, dept) VALUES ('Bob', 'eng')",
+		"INSERT INTO users (name, dept) VALUES ('Cy', 'ops')",
+		"INSERT INTO users (name) VALUES ('Dee')",
+		"INSERT INTO orders (user_id, amount) VALUES (1, 10.0)",
+		"INSERT INTO orders (user_id, amount) VALUES (1, 20.0)",
+		"INSERT INTO orders (user_id, amount) VALUES (2, 5.0)",
+		"INSERT INTO orders (amount) VALUES (7.0)",
+	} {
+		if _, err := eng.Execute(sql); err != nil {
+			t.Fatalf("%s failed: %v", sql, err)
+		}
+	}
+
+	tests := []struct {
+		name     string
+		sql      string
+		column   string
+		expected string
+	}{
+		{"IN", "SELECT name FROM users WHERE id IN (SELECT user_id FROM orders) ORDER BY id", "name", "[Ann Bob]"},
+		{"NOT IN with NULL in subquery", "SELECT name FROM users WHERE id NOT IN (SELECT user_id FROM orders)", "name", "[]"},
+		{"NOT IN", "SELECT name FROM users WHERE id NOT IN (SELECT user_id FROM orders WHERE user_id IS NOT NULL) ORDER BY id", "name", "[Cy Dee]"},
+		{"Correlated IN", "SELECT name FROM users WHERE 20 IN (SELECT amount FROM orders WHERE orders.user_id = users.id)", "name", "[Ann]"},
+		{"EXISTS", "SELECT name FROM users WHERE EXISTS (SELECT id FROM orders WHERE orders.user_id = users.id) ORDER BY id", "name", "[Ann Bob]"},
+		{"NOT EXISTS", "SELECT name FROM users WHERE NOT EXISTS (SELECT * FROM orders WHERE user_id = users.id) ORDER BY id", "name", "[Cy Dee]"},
+		{"Uncorrelated EXISTS", "SELECT name FROM users WHERE EXISTS (SELECT id FROM orders WHERE amount > 100)", "name", "[]"},
+		{"Correlated inequality", "SELECT name FROM users WHERE EXISTS (SELECT id FROM orders WHERE orders.user_id = users.id AND amount > users.id * 10)", "name", "[Ann]"},
+		{"Scalar in WHERE", "SELECT name FROM users WHERE id = (SELECT MAX(user_id) FROM orders)", "name", "[Bob]"},
+		{"Scalar over aggregate", "SELECT id FROM orders WHERE amount > (SELECT AVG(amount) FROM orders) ORDER BY id", "id", "[2]"},
+		{"Scalar with no rows is NULL", "SELECT name FROM users WHERE id = (SELECT user_id FROM orders WHERE amount > 100)", "name", "[]"},
+		{"Correlated scalar in SELECT", "SELECT name, (SELECT SUM(amount) FROM orders WHERE orders.user_id = users.id) AS total FROM users ORDER BY id", "total", "[30 5 <nil> <nil>]"},
+		{"Correlated scalar in ORDER BY alias", "SELECT name, (SELECT COUNT(*) FROM orders WHERE orders.user_id = users.id) AS n FROM users ORDER BY n DESC, id", "name", "[Ann Bob Cy Dee]"},
+		{"Nested subqueries", "SELECT name FROM users WHERE id IN (SELECT user_id FROM orders WHERE amount > (SELECT MIN(amount) FROM orders)) ORDER BY id", "name", "[Ann]"},
+		{"Derived table", "SELECT user_id, total FROM (SELECT user_id, SUM(amount) AS total FROM orders GROUP BY user_id) AS t WHERE total > 6 ORDER BY total", "user_id", "[<nil> 1]"},
+		{"Aggregate over derived table", "SELECT COUNT(*) AS n FROM (SELECT id FROM users WHERE dept = 'eng') e", "n", "[2]"},
+		{"Derived table in JOIN", "SELECT users.name, t.total FROM users JOIN (SELECT user_id, SUM(amount) AS total FROM orders GROUP BY user_id) AS t ON users.id = t.user_id ORDER BY t.total", "users.name", "[Bob Ann]"},
+		{"Subquery over derived table", "SELECT name FROM users WHERE id IN (SELECT user_id FROM (SELECT user_id, amount FROM orders) AS o WHERE amount >= 20)", "name", "[Ann]"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := queryColumn(t, eng, tt.sql, tt.column)
+			if fmt.Sprint(got) != tt.expected {
+				t.Errorf("Expected %s, got %v", tt.expected, got)
+			}
+		})
+	}
+
+	t.Run("SELECT * from derived table", func(t *testing.T) {
+		res, err := eng.Execute("SELECT * FROM (SELECT name, id * 10 AS ten FROM users WHERE dept = 'eng') AS e")
+		if err != nil {
+			t.Fatalf("Query failed: %v", err)
+		}
+		if fmt.Sprint(res.Columns) != "[name ten]" {
+			t.Errorf("Expected columns [name ten], got %v", res.Columns)
+		}
+		types := make([]string, len(res.Metadata))
+		for i, m := range res.Metadata {
+			types[i] = m.Type
+		}
+		if fmt.Sprint(types) != "[TEXT INT]" {
+			t.Errorf("Expected types [TEXT INT], got %v", types)
+		}
+		if len(res.Rows) != 2 {
+			t.Errorf("Expected 2 rows, got %d", len(res.Rows))
+		}
+	})
+
+	t.Run("Errors", func(t *testing.T) {
+		for _, sql := range []string{
+			"SELECT (SELECT user_id FROM orders) AS u FROM users",
+			"SELECT name FROM users WHERE id IN (SELECT id, user_id FROM orders)",
+			"SELECT name FROM users WHERE id = (SELECT id, user_id FROM orders)",
+			"SELECT name FROM (SELECT name FROM users)",
+			"SELECT id FROM (SELECT id, id FROM users) AS t",
+			"SELECT name FROM users WHERE id IN (SELECT user_id FROM missing)",
+		} {
+			if _, err := eng.Execute(sql); err == nil {
+				t.Errorf("Expected error for %s", sql)
+			}
+		}
+
+		// A scalar subquery returning several rows fails the statement wherever it is used
+		for _, sql := range []string{
+			"SELECT (SELECT user_id FROM orders) AS u FROM users",
+			"SELECT name FROM users WHERE id = (SELECT user_id FROM orders)",
+			"SELECT name FROM users WHERE id = (SELECT user_id FROM orders WHERE orders.user_id = users.id)",
+			"DELETE FROM orders WHERE user_id = (SELECT id FROM users)",
+		} {
+			expectError(t, eng, sql, "more than one row returned by a subquery")
+		}
+
+		// Stored tables cannot be aliased, and a qualifier naming no table in scope is not
+		// matched to a column of another table: neither query may return every user
+		expectError(t, eng, "SELECT name FROM users u WHERE EXISTS (SELECT 1 FROM orders o WHERE o.user_id = u.id)", "unexpected u")
+		expectError(t, eng, "SELECT name FROM users WHERE EXISTS (SELECT id FROM orders WHERE orders.user_id = u.id)", "references table 'u'")
+		expectError(t, eng, "DELETE FROM users WHERE NOT EXISTS (SELECT id FROM orders WHERE orders.user_id = u.id)", "references table 'u'")
+	})
+
+	t.Run("UPDATE and DELETE", func(t *testing.T) {
+		res, err := eng.Execute("UPDATE users SET dept = 'buyers' WHERE id IN (SELECT user_id FROM orders)")
+		if err != nil {
+			t.Fatalf("Update failed: %v", err)
+		}
+		if res.RowsAffected != 2 {
+			t.Errorf("Expected 2 rows affected, got %d", res.RowsAffected)
+		}
+
+		if _, err := eng.Execute("UPDATE orders SET amount = amount + (SELECT COUNT(*) FROM users WHERE users.id = orders.user_id) WHERE user_id IS NOT NULL"); err != nil {
+			t.Fatalf("Update failed: %v", err)
+		}
+		if got := queryColumn(t, eng, "SELECT amount FROM orders ORDER BY id", "amount"); fmt.Sprint(got) != "[11 21 6 7]" {
+			t.Errorf("Expected amounts [11 21 6 7], got %v", got)
+		}
+
+		// A correlated subquery reading the modified table would run under its write lock
+		sql := "DELETE FROM users WHERE EXISTS (SELECT id FROM orders WHERE orders.user_id > users.id AND EXISTS (SELECT id FROM users WHERE dept = 'ops'))"
+		if _, err := eng.Execute(sql); err == nil {
+			t.Errorf("Expected error for %s", sql)
+		}
+
+		res, err = eng.Execute("DELETE FROM users WHERE NOT EXISTS (SELECT id FROM orders WHERE orders.user_id = users.id) AND dept IS NOT NULL")
+		if err != nil {
+			t.Fatalf("Delete failed: %v", err)
+		}
+		if res.RowsAffected != 1 {
+			t.Errorf("Expected 1 row affected, got %d", res.RowsAffected)
+		}
+		if got := queryColumn(t, eng, "SELECT name FROM users ORDER BY id", "name"); fmt.Sprint(got) != "[Ann Bob Dee]" {
+			t.Errorf("Expected users [Ann Bob Dee], got %v", got)
+		}
+	})
+}
+
+// TestSubqueryPlanning verifies correlated IN and EXISTS subqueries with equality
+// correlation are planned as semi-joins that run once, and other correlations are not
+func TestSubqueryPlanning(t *testing.T) {
+	db, err := loader.LoadDatabase("../../databases/testdb")
+	if err != nil {
+		t.Fatalf("Failed to load database: %v", err)
+	}
+
+	tests := []struct {
+		sql        string
+		semiJoin   bool
+		correlated bool
+	}{
+		{"SELECT username FROM users WHERE EXISTS (SELECT id FROM orders WHERE orders.user_id = users.id)", true, false},
+		{"SELECT username FROM users WHERE id NOT IN (SELECT user_id FROM orders WHERE amount > 10)", true, false},
+		{"SELECT username FROM users WHERE EXISTS (SELECT id FROM orders WHERE orders.user_id > users.id)", false, true},
+		{"SELECT username FROM users WHERE EXISTS (SELECT id FROM orders WHERE amount > 10)", false, false},
+		{"SELECT username, (SELECT COUNT(*) FROM orders WHERE orders.user_id = users.id) AS n FROM users", false, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.sql, func(t *testing.T) {
+			tokens, err := lexer.Tokenize(tt.sql)
+			if err != nil {
+				t.Fatalf("Lexer error: %v", err)
+			}
+			stmt, err := parser.New(tokens).Parse()
+			if err != nil {
+				t.Fatalf("Parse error: %v", err)
+			}
+			node, err := planner.Plan(stmt, db, nil)
+			if err != nil {
+				t.Fatalf("Planner error: %v", err)
+			}
+
+			subqueries := node.(*plan.SelectNode).Subqueries
+			if len(subqueries) != 1 {
+				t.Fatalf("Expected 1 subquery, got %d", len(subqueries))
+			}
+			sub := subqueries[0]
+			semiJoin, _ := sub.Root.Metadata()["semi_join"].(bool)
+			if semiJoin != tt.semiJoin {
+				t.Errorf("Expected semi_join=%v, got %v", tt.semiJoin, semiJoin)
+			}
+			if sub.Correlated != tt.correlated {
+				t.Errorf("Expected correlated=%v, got %v", tt.correlated, sub.Correlated)
+			}
+		})
+	}
+}
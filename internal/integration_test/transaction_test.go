@@ -0,0 +1,182 @@
+package integration
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/leengari/mini-rdbms/internal/engine"
+	storageEngine "github.com/leengari/mini-rdbms/internal/storage/engine"
+	"github.com/leengari/mini-rdbms/internal/storage/manager"
+)
+
+func TestTransactions(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "rdbms_tx_test")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	registry := manager.NewRegistry(tmpDir, storageEngine.NewJSONEngine())
+	eng := engine.New(nil, registry)
+	defer eng.Close()
+
+	exec := func(t *testing.T, eng *engine.Engine, sql string) {
+		t.Helper()
+		if _, err := eng.Execute(sql); err != nil {
+			t.Fatalf("%s failed: %v", sql, err)
+		}
+	}
+
+	for _, sql := range []string{
+		"CREATE DATABASE shop",
+		"USE shop",
+		"CREATE TABLE items (id INT PRIMARY KEY AUTO_INCREMENT, name TEXT NOT NULL UNIQUE, qty INT)",
+		"INSERT INTO items (name, qty) VALUES ('apple', 5)",
+		"INSERT INTO items (name, qty) VALUES ('pear', 2)",
+	} {
+		exec(t, eng, sql)
+	}
+
+	expected := map[string]string{"1": "apple:5", "2": "pear:2"}
+
+	t.Run("Rollback restores rows, indexes and auto-increment", func(t *testing.T) {
+		exec(t, eng, "BEGIN")
+		if !eng.InTransaction() {
+			t.Fatal("Expected open transaction after BEGIN")
+		}
+		exec(t, eng, "INSERT INTO items (name, qty) VALUES ('plum', 7)")
+		exec(t, eng, "UPDATE items SET qty = 0 WHERE name = 'apple'")
+		exec(t, eng, "DELETE FROM items WHERE name = 'pear'")
+
+		// The transaction sees its own changes
+		assertItems(t, eng, map[string]string{"1": "apple:0", "3": "plum:7"})
+
+		exec(t, eng, "ROLLBACK")
+		if eng.InTransaction() {
+			t.Fatal("Expected no open transaction after ROLLBACK")
+		}
+		assertItems(t, eng, expected)
+
+		// Unique index no longer holds 'plum' and still holds 'pear'
+		if _, err := eng.Execute("INSERT INTO items (name, qty) VALUES ('pear', 1)"); err == nil {
+			t.Error("Expected unique violation for restored 'pear'")
+		}
+		// Auto-increment continues from before the transaction
+		exec(t, eng, "INSERT INTO items (name, qty) VALUES ('plum', 4)")
+		expected["3"] = "plum:4"
+		assertItems(t, eng, expected)
+	})
+
+	t.Run("Commit survives restart", func(t *testing.T) {
+		exec(t, eng, "BEGIN TRANSACTION")
+		exec(t, eng, "INSERT INTO items (name, qty) VALUES ('fig', 1)")
+		exec(t, eng, "UPDATE items SET qty = 8 WHERE name = 'apple'")
+		exec(t, eng, "COMMIT")
+		expected["4"] = "fig:1"
+		expected["1"] = "apple:8"
+
+		restarted, _ := openShop(t, tmpDir)
+		assertItems(t, restarted, expected)
+	})
+
+	t.Run("Failed statement is undone without ending the transaction", func(t *testing.T) {
+		exec(t, eng, "BEGIN")
+		exec(t, eng, "INSERT INTO items (name, qty) VALUES ('kiwi', 3)")
+		if _, err := eng.Execute("INSERT INTO items (name, qty) VALUES ('apple', 1)"); err == nil {
+			t.Fatal("Expected unique violation")
+		}
+		if !eng.InTransaction() {
+			t.Fatal("Expected transaction to stay open after a failed statement")
+		}
+		exec(t, eng, "COMMIT")
+		expected["5"] = "kiwi:3"
+		assertItems(t, eng, expected)
+	})
+
+	t.Run("Sessions have separate transaction scopes", func(t *testing.T) {
+		other := engine.New(nil, registry)
+		exec(t, other, "USE shop")
+
+		exec(t, eng, "BEGIN")
+		if other.InTransaction() {
+			t.Error("BEGIN in one session opened a transaction in another")
+		}
+		if _, err := other.Execute("COMMIT"); err == nil {
+			t.Error("Expected COMMIT without BEGIN to fail")
+		}
+		exec(t, eng, "ROLLBACK")
+
+		// Closing a session rolls back its open transaction
+		exec(t, other, "BEGIN")
+		exec(t, other, "DELETE FROM items")
+		if err := other.Close(); err != nil {
+			t.Fatalf("Close failed: %v", err)
+		}
+		assertItems(t, eng, expected)
+	})
+
+	t.Run("Idle transaction is rolled back", func(t *testing.T) {
+		defer func(timeout time.Duration) { engine.IdleTransactionTimeout = timeout }(engine.IdleTransactionTimeout)
+		engine.IdleTransactionTimeout = 50 * time.Millisecond
+
+		exec(t, eng, "BEGIN")
+		exec(t, eng, "DELETE FROM items")
+
+		// Another session's write waits for the write lock until the timeout frees it
+		other := engine.New(nil, registry)
+		defer other.Close()
+		exec(t, other, "USE shop")
+		done := make(chan error, 1)
+		go func() {
+			_, err := other.Execute("UPDATE items SET qty = 6 WHERE name = 'fig'")
+			done <- err
+		}()
+		select {
+		case err := <-done:
+			if err != nil {
+				t.Fatalf("UPDATE in other session failed: %v", err)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("Other session still blocked by the idle transaction")
+		}
+		expected["4"] = "fig:6"
+
+		if eng.InTransaction() {
+			t.Error("Expected idle transaction to be rolled back")
+		}
+		if _, err := eng.Execute("COMMIT"); err == nil || !strings.Contains(err.Error(), "idle") {
+			t.Errorf("Expected next statement to report the idle rollback, got %v", err)
+		}
+		assertItems(t, eng, expected)
+	})
+
+	t.Run("Invalid transaction usage", func(t *testing.T) {
+		if _, err := eng.Execute("COMMIT"); err == nil {
+			t.Error("Expected COMMIT without BEGIN to fail")
+		}
+		if _, err := eng.Execute("ROLLBACK"); err == nil {
+			t.Error("Expected ROLLBACK without BEGIN to fail")
+		}
+
+		exec(t, eng, "BEGIN")
+		defer eng.Execute("ROLLBACK")
+
+		tests := []struct {
+			sql     string
+			wantErr string
+		}{
+			{"BEGIN", "already in progress"},
+			{"CREATE TABLE tags (id INT)", "inside a transaction"},
+			{"DROP TABLE items", "inside a transaction"},
+			{"USE shop", "inside a transaction"},
+		}
+		for _, tt := range tests {
+			_, err := eng.Execute(tt.sql)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("%s: expected error containing %q, got %v", tt.sql, tt.wantErr, err)
+			}
+		}
+	})
+}
@@ -0,0 +1,227 @@
+package integration
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/leengari/mini-rdbms/internal/engine"
+	storageEngine "github.com/leengari/mini-rdbms/internal/storage/engine"
+	"github.com/leengari/mini-rdbms/internal/storage/manager"
+)
+
+// storageEngines creates a storage engine of each kind
+var storageEngines = map[string]func() storageEngine.StorageEngine{
+	"json": func() storageEngine.StorageEngine { return storageEngine.NewJSONEngine() },
+	"page": func() storageEngine.StorageEngine { return storageEngine.NewPageEngine(16) },
+}
+
+// expectError runs a statement that must fail with an error containing want
+func expectError(t *testing.T, eng *engine.Engine, sql, want string) {
+	t.Helper()
+
+	_, err := eng.Execute(sql)
+	if err == nil {
+		t.Errorf("Expected %s to fail", sql)
+	} else if !strings.Contains(err.Error(), want) {
+		t.Errorf("%s: expected error containing %q, got %v", sql, want, err)
+	}
+}
+
+// TestUniqueAcrossRestart verifies that unique constraints and index lookups hold for
+// rows loaded from disk, whose values were written by an earlier process
+func TestUniqueAcrossRestart(t *testing.T) {
+	for storage, newStorage := range storageEngines {
+		t.Run(storage, func(t *testing.T) {
+			tmpDir, err := os.MkdirTemp("", "rdbms_unique_test")
+			if err != nil {
+				t.Fatalf("Failed to create temp dir: %v", err)
+			}
+			defer os.RemoveAll(tmpDir)
+
+			open := func() *engine.Engine {
+				return engine.New(nil, manager.NewRegistry(tmpDir, newStorage()))
+			}
+			exec := func(eng *engine.Engine, sqls ...string) {
+				t.Helper()
+				for _, sql := range sqls {
+					if _, err := eng.Execute(sql); err != nil {
+						t.Fatalf("%s failed: %v", sql, err)
+					}
+				}
+			}
+
+			exec(open(),
+				"CREATE DATABASE shop",
+				"USE shop",
+				"CREATE TABLE a (id INT PRIMARY KEY, s TEXT, price FLOAT UNIQUE)",
+				"INSERT INTO a (id, s, price) VALUES (1, 'one', 1.5), (2, 'two', 2), (3, 'three', NULL)",
+				"CHECKPOINT",
+			)
+
+			eng := open()
+			exec(eng, "USE shop")
+
+			expectError(t, eng, "INSERT INTO a (id, s) VALUES (1, 'dup')", "duplicate value")
+			expectError(t, eng, "INSERT INTO a (id, s, price) VALUES (4, 'dup', 2)", "duplicate value")
+			expectError(t, eng, "INSERT INTO a (id, s, price) SELECT id, s, NULL FROM a", "row 1:")
+
+			tests := []struct {
+				sql      string
+				expected int
+			}{
+				{"SELECT * FROM a WHERE id = 1", 1},
+				{"SELECT * FROM a WHERE id IN (2, 3.0)", 2},
+				{"SELECT * FROM a WHERE id = 2.5", 0},
+				{"SELECT * FROM a WHERE price = 2", 1},
+				{"SELECT * FROM a", 3},
+			}
+			for _, tt := range tests {
+				res, err := eng.Execute(tt.sql)
+				if err != nil {
+					t.Fatalf("%s failed: %v", tt.sql, err)
+				}
+				if len(res.Rows) != tt.expected {
+					t.Errorf("%s: expected %d rows, got %d", tt.sql, tt.expected, len(res.Rows))
+				}
+			}
+
+			// The rejected rows were never stored, so the database still opens
+			eng = open()
+			exec(eng, "USE shop")
+		})
+	}
+}
+
+// TestUniqueAfterReplay verifies that rows replayed from the write-ahead log keep
+// their column types, so unique constraints hold against them
+func TestUniqueAfterReplay(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "rdbms_unique_replay_test")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	eng := engine.New(nil, manager.NewRegistry(tmpDir, storageEngine.NewJSONEngine()))
+	for _, sql := range []string{
+		"CREATE DATABASE shop",
+		"USE shop",
+		"CREATE TABLE orders (oid INT PRIMARY KEY, qty INT UNIQUE)",
+		"INSERT INTO orders (oid, qty) VALUES (1, 10)",
+		"UPDATE orders SET qty = 20 WHERE oid = 1",
+	} {
+		if _, err := eng.Execute(sql); err != nil {
+			t.Fatalf("%s failed: %v", sql, err)
+		}
+	}
+
+	// Only the WAL holds the row: it is replayed on each open
+	eng, _ = openShop(t, tmpDir)
+	expectError(t, eng, "INSERT INTO orders (oid, qty) VALUES (1, 30)", "duplicate value")
+	expectError(t, eng, "INSERT INTO orders (oid, qty) VALUES (2, 20)", "duplicate value")
+
+	res, err := eng.Execute("SELECT * FROM orders WHERE oid = 1")
+	if err != nil {
+		t.Fatalf("Select failed: %v", err)
+	}
+	if len(res.Rows) != 1 {
+		t.Fatalf("Expected 1 row, got %d", len(res.Rows))
+	}
+	if oid, ok := res.Rows[0].Data["oid"].(int64); !ok || oid != 1 {
+		t.Errorf("Expected oid int64(1), got %T(%v)", res.Rows[0].Data["oid"], res.Rows[0].Data["oid"])
+	}
+
+	openShop(t, tmpDir)
+}
+
+// TestUniqueOnUpdate verifies that UPDATE cannot give a row the primary key or unique
+// value of another row, checking the table as it will be after the statement
+func TestUniqueOnUpdate(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "rdbms_unique_update_test")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	eng := engine.New(nil, manager.NewRegistry(tmpDir, storageEngine.NewJSONEngine()))
+	for _, sql := range []string{
+		"CREATE DATABASE shop",
+		"USE shop",
+		"CREATE TABLE k (id INT PRIMARY KEY, code TEXT UNIQUE, a INT, b INT)",
+		"INSERT INTO k (id, code, a, b) VALUES (1, 'x', 1, 1), (2, 'y', 1, 2), (3, 'z', 2, 1)",
+	} {
+		if _, err := eng.Execute(sql); err != nil {
+			t.Fatalf("%s failed: %v", sql, err)
+		}
+	}
+
+	expectError(t, eng, "UPDATE k SET id = 1 WHERE id = 2", "duplicate value")
+	expectError(t, eng, "UPDATE k SET code = 'x' WHERE id = 3", "duplicate value")
+	expectError(t, eng, "UPDATE k SET code = 'q' WHERE id >= 2", "duplicate value")
+
+	// A row may keep its own value, and values may move between the updated rows
+	for _, sql := range []string{
+		"UPDATE k SET code = 'x' WHERE id = 1",
+		"UPDATE k SET id = 4 - id",
+	} {
+		if _, err := eng.Execute(sql); err != nil {
+			t.Fatalf("%s failed: %v", sql, err)
+		}
+	}
+	if got := queryColumn(t, eng, "SELECT code FROM k ORDER BY id", "code"); fmt.Sprint(got) != "[z y x]" {
+		t.Errorf("Expected codes [z y x], got %v", got)
+	}
+
+	// No duplicate was stored, so the database still opens
+	eng, _ = openShop(t, tmpDir)
+	if got := queryColumn(t, eng, "SELECT id FROM k WHERE code = 'x'", "id"); fmt.Sprint(got) != "[3]" {
+		t.Errorf("Expected id [3] for code x, got %v", got)
+	}
+}
+
+// TestUniqueIndexOnUpdate verifies that UPDATE keeps the composite keys of a unique
+// ordered index (CREATE UNIQUE INDEX) unique
+func TestUniqueIndexOnUpdate(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "rdbms_unique_index_update_test")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	eng := engine.New(nil, manager.NewRegistry(tmpDir, storageEngine.NewJSONEngine()))
+	for _, sql := range []string{
+		"CREATE DATABASE shop",
+		"USE shop",
+		"CREATE TABLE k (id INT PRIMARY KEY, a INT, b INT)",
+		"INSERT INTO k (id, a, b) VALUES (1, 1, 1), (2, 1, 2), (3, 2, 1), (4, NULL, 1)",
+		"CREATE UNIQUE INDEX ab ON k (a, b)",
+	} {
+		if _, err := eng.Execute(sql); err != nil {
+			t.Fatalf("%s failed: %v", sql, err)
+		}
+	}
+
+	expectError(t, eng, "UPDATE k SET a = 1, b = 1 WHERE id = 2", "duplicate value")
+	expectError(t, eng, "UPDATE k SET b = 1 WHERE id = 2", "duplicate value")
+	expectError(t, eng, "UPDATE k SET a = 5, b = 5 WHERE id >= 2", "duplicate value")
+
+	// A row may keep its own key, keys may move between the updated rows, and keys
+	// containing NULL never conflict
+	for _, sql := range []string{
+		"UPDATE k SET a = 1, b = 1 WHERE id = 1",
+		"UPDATE k SET b = 3 - b WHERE a = 1",
+		"UPDATE k SET b = 1, a = NULL WHERE id = 3",
+	} {
+		if _, err := eng.Execute(sql); err != nil {
+			t.Fatalf("%s failed: %v", sql, err)
+		}
+	}
+	if got := queryColumn(t, eng, "SELECT b FROM k ORDER BY id", "b"); fmt.Sprint(got) != "[2 1 1 1]" {
+		t.Errorf("Expected b [2 1 1 1], got %v", got)
+	}
+
+	// No duplicate key was stored, so the index is rebuilt when the database opens
+	eng, _ = openShop(t, tmpDir)
+	expectError(t, eng, "INSERT INTO k (id, a, b) VALUES (5, 1, 2)", "duplicate value")
+}
@@ -0,0 +1,211 @@
+package integration
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/leengari/mini-rdbms/internal/domain/transaction"
+	"github.com/leengari/mini-rdbms/internal/engine"
+	storageEngine "github.com/leengari/mini-rdbms/internal/storage/engine"
+	"github.com/leengari/mini-rdbms/internal/storage/manager"
+	"github.com/leengari/mini-rdbms/internal/storage/wal"
+	"github.com/leengari/mini-rdbms/internal/storage/writer"
+)
+
+// openShop starts a fresh registry over basePath (simulating a process restart) and selects 'shop'
+func openShop(t *testing.T, basePath string) (*engine.Engine, *manager.Registry) {
+	t.Helper()
+
+	registry := manager.NewRegistry(basePath, storageEngine.NewJSONEngine())
+	eng := engine.New(nil, registry)
+	if _, err := eng.Execute("USE shop"); err != nil {
+		t.Fatalf("Failed to use shop: %v", err)
+	}
+	return eng, registry
+}
+
+// itemsByID returns the items table as id → "name:qty"
+func itemsByID(t *testing.T, eng *engine.Engine) map[string]string {
+	t.Helper()
+
+	res, err := eng.Execute("SELECT * FROM items")
+	if err != nil {
+		t.Fatalf("Failed to select items: %v", err)
+	}
+
+	items := make(map[string]string)
+	for _, row := range res.Rows {
+		items[fmt.Sprint(row.Data["id"])] = fmt.Sprintf("%v:%v", row.Data["name"], row.Data["qty"])
+	}
+	return items
+}
+
+func assertItems(t *testing.T, eng *engine.Engine, expected map[string]string) {
+	t.Helper()
+
+	got := itemsByID(t, eng)
+	if len(got) != len(expected) {
+		t.Fatalf("Expected %d items, got %d: %v", len(expected), len(got), got)
+	}
+	for id, want := range expected {
+		if got[id] != want {
+			t.Errorf("Item %s: expected %s, got %s", id, want, got[id])
+		}
+	}
+}
+
+func TestWALCrashRecovery(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "rdbms_wal_test")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	walPath := filepath.Join(tmpDir, "shop", wal.FileName)
+
+	// Initial session: schema is checkpointed, DML goes only to the WAL
+	registry := manager.NewRegistry(tmpDir, storageEngine.NewJSONEngine())
+	eng := engine.New(nil, registry)
+	for _, sql := range []string{
+		"CREATE DATABASE shop",
+		"USE shop",
+		"CREATE TABLE items (id INT PRIMARY KEY AUTO_INCREMENT, name TEXT NOT NULL, qty INT)",
+		"INSERT INTO items (name, qty) VALUES ('apple', 5)",
+		"INSERT INTO items (name, qty) VALUES ('pear', 2)",
+		"INSERT INTO items (name, qty) VALUES ('plum', 7)",
+		"UPDATE items SET qty = 9 WHERE name = 'pear'",
+		"DELETE FROM items WHERE name = 'apple'",
+	} {
+		if _, err := eng.Execute(sql); err != nil {
+			t.Fatalf("%s failed: %v", sql, err)
+		}
+	}
+
+	expected := map[string]string{"2": "pear:9", "3": "plum:7"}
+
+	t.Run("Replay after crash", func(t *testing.T) {
+		// No SaveAll: the table files still hold the empty CREATE TABLE snapshot
+		info, err := os.Stat(walPath)
+		if err != nil || info.Size() == 0 {
+			t.Fatalf("Expected non-empty WAL, got err=%v", err)
+		}
+
+		eng, _ := openShop(t, tmpDir)
+		assertItems(t, eng, expected)
+
+		// Auto-increment sequence is recovered as well
+		if _, err := eng.Execute("INSERT INTO items (name, qty) VALUES ('fig', 1)"); err != nil {
+			t.Fatalf("Insert after recovery failed: %v", err)
+		}
+		expected["4"] = "fig:1"
+		assertItems(t, eng, expected)
+	})
+
+	t.Run("Checkpoint truncates log", func(t *testing.T) {
+		eng, registry := openShop(t, tmpDir)
+		assertItems(t, eng, expected)
+
+		tx := transaction.NewTransaction()
+		defer tx.Close()
+		registry.SaveAll(tx)
+
+		info, err := os.Stat(walPath)
+		if err != nil {
+			t.Fatalf("WAL missing after checkpoint: %v", err)
+		}
+		if info.Size() != 0 {
+			t.Errorf("Expected empty WAL after checkpoint, got %d bytes", info.Size())
+		}
+
+		eng, _ = openShop(t, tmpDir)
+		assertItems(t, eng, expected)
+	})
+
+	t.Run("Records already in table files are not replayed twice", func(t *testing.T) {
+		eng, registry := openShop(t, tmpDir)
+		if _, err := eng.Execute("UPDATE items SET qty = 3 WHERE name = 'plum'"); err != nil {
+			t.Fatalf("Update failed: %v", err)
+		}
+		if _, err := eng.Execute("INSERT INTO items (name, qty) VALUES ('kiwi', 6)"); err != nil {
+			t.Fatalf("Insert failed: %v", err)
+		}
+		expected["3"] = "plum:3"
+		expected["5"] = "kiwi:6"
+
+		// Crash between rewriting the table files and truncating the log
+		db, err := registry.Get("shop")
+		if err != nil {
+			t.Fatalf("Failed to get shop: %v", err)
+		}
+		if err := writer.SaveDatabase(db, nil); err != nil {
+			t.Fatalf("Failed to save database: %v", err)
+		}
+
+		eng, _ = openShop(t, tmpDir)
+		assertItems(t, eng, expected)
+	})
+
+	t.Run("Save interrupted between its renames is finished", func(t *testing.T) {
+		eng, registry := openShop(t, tmpDir)
+		if _, err := eng.Execute("INSERT INTO items (name, qty) VALUES ('lime', 4)"); err != nil {
+			t.Fatalf("Insert failed: %v", err)
+		}
+		expected["6"] = "lime:4"
+
+		// Crash after data.json was replaced but before meta.json (and its last_lsn) was
+		metaPath := filepath.Join(tmpDir, "shop", "items", "meta.json")
+		oldMeta, err := os.ReadFile(metaPath)
+		if err != nil {
+			t.Fatalf("Failed to read meta.json: %v", err)
+		}
+		db, err := registry.Get("shop")
+		if err != nil {
+			t.Fatalf("Failed to get shop: %v", err)
+		}
+		if err := writer.SaveTable(db.Tables["items"], nil); err != nil {
+			t.Fatalf("Failed to save table: %v", err)
+		}
+		if err := os.Rename(metaPath, metaPath+".tmp"); err != nil {
+			t.Fatalf("Failed to move meta.json: %v", err)
+		}
+		if err := os.WriteFile(metaPath, oldMeta, 0644); err != nil {
+			t.Fatalf("Failed to restore meta.json: %v", err)
+		}
+
+		eng, _ = openShop(t, tmpDir)
+		assertItems(t, eng, expected)
+		if _, err := os.Stat(metaPath + ".tmp"); !os.IsNotExist(err) {
+			t.Errorf("Expected meta.json.tmp to be moved into place, got err=%v", err)
+		}
+
+		// A crash before either rename leaves the old files, which the log completes
+		if err := os.WriteFile(filepath.Join(tmpDir, "shop", "items", "data.json.tmp"), []byte("[{"), 0644); err != nil {
+			t.Fatalf("Failed to write data.json.tmp: %v", err)
+		}
+		eng, _ = openShop(t, tmpDir)
+		assertItems(t, eng, expected)
+	})
+
+	t.Run("Torn final record is discarded", func(t *testing.T) {
+		f, err := os.OpenFile(walPath, os.O_APPEND|os.O_WRONLY, 0644)
+		if err != nil {
+			t.Fatalf("Failed to open WAL: %v", err)
+		}
+		f.WriteString(`{"lsn":999,"tx_id":"torn","changes":[{"type":"INS`)
+		f.Close()
+
+		eng, _ := openShop(t, tmpDir)
+		assertItems(t, eng, expected)
+
+		// New records are appended cleanly after the discarded tail
+		if _, err := eng.Execute("DELETE FROM items WHERE name = 'fig'"); err != nil {
+			t.Fatalf("Delete failed: %v", err)
+		}
+		delete(expected, "4")
+
+		eng, _ = openShop(t, tmpDir)
+		assertItems(t, eng, expected)
+	})
+}
@@ -0,0 +1,155 @@
+package integration
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/leengari/mini-rdbms/internal/engine"
+	"github.com/leengari/mini-rdbms/internal/parser"
+	"github.com/leengari/mini-rdbms/internal/parser/lexer"
+	"github.com/leengari/mini-rdbms/internal/plan"
+	"github.com/leengari/mini-rdbms/internal/planner"
+	storageEngine "github.com/leengari/mini-rdbms/internal/storage/engine"
+	"github.com/leengari/mini-rdbms/internal/storage/loader"
+	"github.com/leengari/mini-rdbms/internal/storage/manager"
+)
+
+// TestWindowFunctions verifies ranking, LAG/LEAD, FIRST_VALUE/LAST_VALUE and aggregates
+// over windows with partitions, ordering and ROWS/RANGE frames
+func TestWindowFunctions(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "rdbms_window_test")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	registry := manager.NewRegistry(tmpDir, storageEngine.NewJSONEngine())
+	eng := engine.New(nil, registry)
+	for _, sql := range []string{
+		"CREATE DATABASE analytics",
+		"USE analytics",
+		"CREATE TABLE users (id INT PRIMARY KEY AUTO_INCREMENT, name TEXT NOT NULL)",
+		"CREATE TABLE orders (id INT PRIMARY KEY AUTO_INCREMENT, user_id INT, amount FLOAT, created_at DATE)",
+		"INSERT INTO users (name) VALUES ('Ann')",
+		"INSERT INTO users (name) VALUES ('Bob')",
+		"INSERT INTO users (name) VALUES ('Cy')",
+		"INSERT INTO orders (user_id, amount, created_at) VALUES (1, 10.0, '2024-01-03')",
+		"INSERT INTO orders (user_id, amount, created_at) VALUES (2, 5.0, '2024-01-01')",
+		"INSERT INTO orders (user_id, amount, created_at) VALUES (1, 20.0, '2024-01-01')",
+		"INSERT INTO orders (user_id, amount, created_at) VALUES (2, 5.0, '2024-01-02')",
+		"INSERT INTO orders (user_id, amount, created_at) VALUES (1, 30.0, '2024-01-05')",
+		"INSERT INTO orders (user_id, amount, created_at) VALUES (3, 7.5, '2024-01-04')",
+	} {
+		if _, err := eng.Execute(sql); err != nil {
+			t.Fatalf("%s failed: %v", sql, err)
+		}
+	}
+
+	tests := []struct {
+		name     string
+		sql      string
+		column   string
+		expected string
+	}{
+		{"ROW_NUMBER", "SELECT id, ROW_NUMBER() OVER (PARTITION BY user_id ORDER BY created_at) AS n FROM orders ORDER BY id", "n", "[2 1 1 2 3 1]"},
+		{"RANK", "SELECT id, RANK() OVER (ORDER BY amount) AS r FROM orders ORDER BY id", "r", "[4 1 5 1 6 3]"},
+		{"DENSE_RANK", "SELECT id, DENSE_RANK() OVER (ORDER BY amount) AS r FROM orders ORDER BY id", "r", "[3 1 4 1 5 2]"},
+		{"LAG", "SELECT id, LAG(amount) OVER (PARTITION BY user_id ORDER BY created_at) AS prev FROM orders ORDER BY id", "prev", "[20 <nil> <nil> 5 10 <nil>]"},
+		{"LEAD with default", "SELECT id, LEAD(amount, 1, 0) OVER (PARTITION BY user_id ORDER BY created_at) AS next FROM orders ORDER BY id", "next", "[30 5 10 0 0 0]"},
+		{"LAG offset", "SELECT id, LAG(id, 2) OVER (ORDER BY id) AS back FROM orders ORDER BY id", "back", "[<nil> <nil> 1 2 3 4]"},
+		{"FIRST_VALUE", "SELECT id, FIRST_VALUE(amount) OVER (PARTITION BY user_id ORDER BY created_at) AS opening FROM orders ORDER BY id", "opening", "[20 5 20 5 20 7.5]"},
+		{"LAST_VALUE default frame", "SELECT id, LAST_VALUE(amount) OVER (PARTITION BY user_id ORDER BY created_at) AS closing FROM orders ORDER BY id", "closing", "[10 5 20 5 30 7.5]"},
+		{"LAST_VALUE whole partition", "SELECT id, LAST_VALUE(amount) OVER (PARTITION BY user_id ORDER BY created_at ROWS BETWEEN UNBOUNDED PRECEDING AND UNBOUNDED FOLLOWING) AS closing FROM orders ORDER BY id", "closing", "[30 5 30 5 30 7.5]"},
+		{"Running SUM", "SELECT id, SUM(amount) OVER (ORDER BY id) AS total FROM orders ORDER BY id", "total", "[10 15 35 40 70 77.5]"},
+		{"Running SUM per partition", "SELECT id, SUM(amount) OVER (PARTITION BY user_id ORDER BY created_at) AS total FROM orders ORDER BY id", "total", "[30 5 20 10 60 7.5]"},
+		{"RANGE includes peers", "SELECT id, SUM(amount) OVER (ORDER BY created_at) AS total FROM orders ORDER BY id", "total", "[40 25 25 30 77.5 47.5]"},
+		{"ROWS excludes peers", "SELECT id, SUM(amount) OVER (ORDER BY created_at ROWS UNBOUNDED PRECEDING) AS total FROM orders ORDER BY id", "total", "[40 5 25 30 77.5 47.5]"},
+		{"ROWS sliding frame", "SELECT id, SUM(amount) OVER (ORDER BY id ROWS BETWEEN 1 PRECEDING AND 1 FOLLOWING) AS total FROM orders ORDER BY id", "total", "[15 35 30 55 42.5 37.5]"},
+		{"RANGE offset", "SELECT id, COUNT(*) OVER (ORDER BY amount RANGE BETWEEN 5 PRECEDING AND CURRENT ROW) AS n FROM orders ORDER BY id", "n", "[4 2 1 2 1 3]"},
+		{"Whole table", "SELECT id, COUNT(*) OVER () AS n FROM orders ORDER BY id", "n", "[6 6 6 6 6 6]"},
+		{"Aggregate over an expression", "SELECT id, SUM(amount * 2) OVER (PARTITION BY user_id) AS total FROM orders ORDER BY id", "total", "[120 20 120 20 120 15]"},
+		{"LAG over an expression", "SELECT id, LAG(UPPER(name)) OVER (ORDER BY id) AS prev FROM users ORDER BY id", "prev", "[<nil> ANN BOB]"},
+		{"Partition only", "SELECT id, MAX(amount) OVER (PARTITION BY user_id) AS top FROM orders ORDER BY id", "top", "[30 5 30 5 30 7.5]"},
+		{"Filtered before the window", "SELECT id, ROW_NUMBER() OVER (ORDER BY id) AS n FROM orders WHERE user_id = 1 ORDER BY id", "n", "[1 2 3]"},
+		{"ORDER BY window alias", "SELECT id, ROW_NUMBER() OVER (ORDER BY amount DESC, id) AS n FROM orders ORDER BY n LIMIT 3", "id", "[5 3 1]"},
+		{"JOIN", "SELECT orders.id, RANK() OVER (PARTITION BY users.name ORDER BY orders.amount DESC) AS r FROM orders JOIN users ON orders.user_id = users.id ORDER BY orders.id", "r", "[3 1 2 1 1 1]"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := queryColumn(t, eng, tt.sql, tt.column)
+			if fmt.Sprint(got) != tt.expected {
+				t.Errorf("Expected %s, got %v", tt.expected, got)
+			}
+		})
+	}
+
+	t.Run("Result metadata", func(t *testing.T) {
+		res, err := eng.Execute("SELECT id, ROW_NUMBER() OVER (ORDER BY id), LAG(amount) OVER (ORDER BY id) AS prev, AVG(amount) OVER () AS mean FROM orders")
+		if err != nil {
+			t.Fatalf("Query failed: %v", err)
+		}
+		if fmt.Sprint(res.Columns) != "[id ROW_NUMBER() OVER (ORDER BY id ASC) prev mean]" {
+			t.Errorf("Unexpected columns %v", res.Columns)
+		}
+		types := make([]string, len(res.Metadata))
+		for i, m := range res.Metadata {
+			types[i] = m.Type
+		}
+		if fmt.Sprint(types) != "[INT INT FLOAT FLOAT]" {
+			t.Errorf("Expected types [INT INT FLOAT FLOAT], got %v", types)
+		}
+	})
+
+	t.Run("Errors", func(t *testing.T) {
+		for _, sql := range []string{
+			"SELECT id FROM orders WHERE ROW_NUMBER() OVER (ORDER BY id) = 1",
+			"SELECT ROW_NUMBER() OVER (ORDER BY id) + 1 FROM orders",
+			"SELECT user_id, COUNT(*), ROW_NUMBER() OVER (ORDER BY user_id) FROM orders GROUP BY user_id",
+			"SELECT ROW_NUMBER(id) OVER () FROM orders",
+			"SELECT RANK() OVER (ORDER BY id ROWS 1 PRECEDING) FROM orders",
+			"SELECT LAG(amount, -1) OVER (ORDER BY id) FROM orders",
+			"SELECT LAG(amount, 1, 'none') OVER (ORDER BY id) FROM orders",
+			"SELECT SUM(amount) OVER (ORDER BY created_at RANGE 1 PRECEDING) FROM orders",
+			"SELECT SUM(DISTINCT amount) OVER () FROM orders",
+			"SELECT SUM(missing) OVER () FROM orders",
+			"SELECT MAX(SUM(amount)) OVER () FROM orders",
+			"SELECT FIRST_VALUE(amount / 0) OVER (ORDER BY id) FROM orders",
+			"SELECT NTILE(2) OVER (ORDER BY id) FROM orders",
+			"SELECT SUM(amount) OVER (ROWS BETWEEN CURRENT ROW AND 1 PRECEDING) FROM orders",
+		} {
+			if _, err := eng.Execute(sql); err == nil {
+				t.Errorf("Expected error for %s", sql)
+			}
+		}
+	})
+}
+
+// TestWindowPlan verifies the WINDOW node sits above the filter and below ORDER BY
+func TestWindowPlan(t *testing.T) {
+	db, err := loader.LoadDatabase("../../databases/testdb")
+	if err != nil {
+		t.Fatalf("Failed to load database: %v", err)
+	}
+
+	tokens, err := lexer.Tokenize("SELECT id, ROW_NUMBER() OVER (PARTITION BY user_id ORDER BY id) AS n FROM orders WHERE user_id > 1 ORDER BY n")
+	if err != nil {
+		t.Fatalf("Lexer error: %v", err)
+	}
+	stmt, err := parser.New(tokens).Parse()
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	node, err := planner.Plan(stmt, db, nil)
+	if err != nil {
+		t.Fatalf("Planner error: %v", err)
+	}
+
+	expected := []string{"SELECT", "  SORT", "    WINDOW", "      SCAN"}
+	got := strings.Split(strings.TrimRight(plan.PrintTree(node), "\n"), "\n")
+	if fmt.Sprint(got) != fmt.Sprint(expected) {
+		t.Errorf("Expected tree %v, got %v", expected, got)
+	}
+}
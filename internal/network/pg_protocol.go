@@ -0,0 +1,355 @@
+package network
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	domainErrors "github.com/leengari/mini-rdbms/internal/domain/errors"
+	"github.com/leengari/mini-rdbms/internal/domain/schema"
+	"github.com/leengari/mini-rdbms/internal/executor"
+)
+
+// PostgreSQL v3 frontend/backend protocol constants
+// See https://www.postgresql.org/docs/current/protocol-message-formats.html
+const (
+	pgProtocolVersion = 196608   // 3.0
+	pgSSLRequestCode  = 80877103 // SSLRequest: we answer 'N' (no TLS)
+	pgGSSRequestCode  = 80877104 // GSSENCRequest: we answer 'N'
+	pgCancelCode      = 80877102 // CancelRequest: not supported, connection is closed
+	pgMaxMessageSize  = 64 << 20 // Largest frontend message we accept
+)
+
+// Frontend message types
+const (
+	pgMsgQuery     = 'Q'
+	pgMsgTerminate = 'X'
+	pgMsgParse     = 'P'
+	pgMsgBind      = 'B'
+	pgMsgDescribe  = 'D'
+	pgMsgExecute   = 'E'
+	pgMsgClose     = 'C'
+	pgMsgFlush     = 'H'
+	pgMsgSync      = 'S'
+)
+
+// Type OIDs (from pg_type) reported in RowDescription
+const (
+	pgOIDBool    = 16
+	pgOIDInt8    = 20
+	pgOIDText    = 25
+	pgOIDFloat8  = 701
+	pgOIDVarchar = 1043
+	pgOIDDate    = 1082
+	pgOIDTime    = 1083
+)
+
+// pgType maps a result column type (executor.ColumnMetadata.Type) to a PostgreSQL type OID and size
+// Unknown types are reported as text.
+func pgType(columnType string) (oid int32, size int16) {
+	switch schema.ColumnType(columnType) {
+	case schema.ColumnTypeInt:
+		return pgOIDInt8, 8
+	case schema.ColumnTypeFloat:
+		return pgOIDFloat8, 8
+	case schema.ColumnTypeBool:
+		return pgOIDBool, 1
+	case schema.ColumnTypeDate:
+		return pgOIDDate, 4
+	case schema.ColumnTypeTime:
+		return pgOIDTime, 8
+	case schema.ColumnTypeEmail:
+		return pgOIDVarchar, -1
+	default:
+		return pgOIDText, -1
+	}
+}
+
+// pgText renders a value in the PostgreSQL text format; ok is false for NULL
+// INT values are int64 (the loaders and INSERT convert them), FLOAT values float64.
+func pgText(value interface{}) (text string, ok bool) {
+	switch v := value.(type) {
+	case nil:
+		return "", false
+	case bool:
+		if v {
+			return "t", true
+		}
+		return "f", true
+	case float64:
+		return strconv.FormatFloat(v, 'g', -1, 64), true
+	case int64:
+		return strconv.FormatInt(v, 10), true
+	case int:
+		return strconv.Itoa(v), true
+	case string:
+		return v, true
+	default:
+		return fmt.Sprint(v), true
+	}
+}
+
+// pgBuffer builds the body of a backend message
+type pgBuffer []byte
+
+func (b pgBuffer) int16(n int16) pgBuffer {
+	return binary.BigEndian.AppendUint16(b, uint16(n))
+}
+
+func (b pgBuffer) int32(n int32) pgBuffer {
+	return binary.BigEndian.AppendUint32(b, uint32(n))
+}
+
+// string appends a null-terminated string
+func (b pgBuffer) string(s string) pgBuffer {
+	return append(append(b, s...), 0)
+}
+
+func (b pgBuffer) byte(c byte) pgBuffer {
+	return append(b, c)
+}
+
+// pgWriter buffers backend messages until Flush
+type pgWriter struct {
+	w *bufio.Writer
+}
+
+// message writes one backend message: type byte, length (including itself), body
+func (pw *pgWriter) message(typ byte, body pgBuffer) error {
+	header := pgBuffer{typ}.int32(int32(len(body) + 4))
+	if _, err := pw.w.Write(header); err != nil {
+		return err
+	}
+	_, err := pw.w.Write(body)
+	return err
+}
+
+func (pw *pgWriter) Flush() error {
+	return pw.w.Flush()
+}
+
+func (pw *pgWriter) authenticationOK() error {
+	return pw.message('R', pgBuffer{}.int32(0))
+}
+
+func (pw *pgWriter) parameterStatus(name, value string) error {
+	return pw.message('S', pgBuffer{}.string(name).string(value))
+}
+
+func (pw *pgWriter) backendKeyData(pid, secret int32) error {
+	return pw.message('K', pgBuffer{}.int32(pid).int32(secret))
+}
+
+// readyForQuery reports the transaction status: 'I' idle, 'T' in a transaction,
+// 'E' in a transaction that failed
+func (pw *pgWriter) readyForQuery(status byte) error {
+	return pw.message('Z', pgBuffer{status})
+}
+
+// rowDescription describes the columns of a result set (all in text format)
+func (pw *pgWriter) rowDescription(result *executor.Result) error {
+	body := pgBuffer{}.int16(int16(len(result.Columns)))
+	for i, name := range result.Columns {
+		oid, size := pgType(columnType(result, i))
+		body = body.string(name).
+			int32(0). // table OID
+			int16(0). // column attribute number
+			int32(oid).
+			int16(size).
+			int32(-1). // type modifier
+			int16(0)   // text format
+	}
+	return pw.message('T', body)
+}
+
+// dataRow sends one row of a result set
+func (pw *pgWriter) dataRow(result *executor.Result, values map[string]interface{}) error {
+	body := pgBuffer{}.int16(int16(len(result.Columns)))
+	for _, name := range result.Columns {
+		text, ok := pgText(values[name])
+		if !ok {
+			body = body.int32(-1) // NULL
+			continue
+		}
+		body = append(body.int32(int32(len(text))), text...)
+	}
+	return pw.message('D', body)
+}
+
+func (pw *pgWriter) commandComplete(tag string) error {
+	return pw.message('C', pgBuffer{}.string(tag))
+}
+
+func (pw *pgWriter) emptyQueryResponse() error {
+	return pw.message('I', pgBuffer{})
+}
+
+// SQLSTATE codes reported in ErrorResponse
+const (
+	pgCodeUniqueViolation  = "23505"
+	pgCodeNotNullViolation = "23502"
+	pgCodeUndefinedTable   = "42P01"
+	pgCodeSyntaxError      = "42601"
+	pgCodeInFailedTx       = "25P02"
+	pgCodeOther            = "42000" // any other statement error
+)
+
+// pgErrorCode returns the SQLSTATE code for an error returned by the engine
+func pgErrorCode(err error) string {
+	var constraintErr *domainErrors.ConstraintError
+	var tableErr *domainErrors.TableNotFoundError
+	var parseErr *domainErrors.ParseError
+	switch {
+	case errors.As(err, &constraintErr):
+		switch constraintErr.Constraint {
+		case "unique":
+			return pgCodeUniqueViolation
+		case "primary_key":
+			// A duplicate key carries its value; a missing key has none
+			if constraintErr.Value != nil {
+				return pgCodeUniqueViolation
+			}
+			return pgCodeNotNullViolation
+		case "not_null":
+			return pgCodeNotNullViolation
+		}
+	case errors.As(err, &tableErr):
+		return pgCodeUndefinedTable
+	case errors.As(err, &parseErr):
+		return pgCodeSyntaxError
+	}
+	return pgCodeOther
+}
+
+// errorResponse sends an error with the given severity (ERROR or FATAL) and SQLSTATE code
+func (pw *pgWriter) errorResponse(severity, code, msg string) error {
+	body := pgBuffer{}.
+		byte('S').string(severity).
+		byte('V').string(severity).
+		byte('C').string(code).
+		byte('M').string(msg).
+		byte(0)
+	return pw.message('E', body)
+}
+
+// notice sends an informational NoticeResponse (used for status messages such as "Switched to database")
+func (pw *pgWriter) notice(msg string) error {
+	body := pgBuffer{}.
+		byte('S').string("NOTICE").
+		byte('V').string("NOTICE").
+		byte('C').string("00000").
+		byte('M').string(msg).
+		byte(0)
+	return pw.message('N', body)
+}
+
+// columnType returns the type name of a result column, or "" if unknown
+func columnType(result *executor.Result, i int) string {
+	if i < len(result.Metadata) {
+		return result.Metadata[i].Type
+	}
+	return ""
+}
+
+// readStartupMessage reads an untyped startup-phase message: length, protocol code, body
+func readStartupMessage(r io.Reader) (code int32, body []byte, err error) {
+	var header [8]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return 0, nil, err
+	}
+	length := int32(binary.BigEndian.Uint32(header[:4]))
+	if length < 8 || length > pgMaxMessageSize {
+		return 0, nil, fmt.Errorf("invalid startup message length %d", length)
+	}
+	code = int32(binary.BigEndian.Uint32(header[4:]))
+	body = make([]byte, length-8)
+	if _, err := io.ReadFull(r, body); err != nil {
+		return 0, nil, err
+	}
+	return code, body, nil
+}
+
+// parseStartupParams decodes the null-terminated name/value pairs of a StartupMessage
+func parseStartupParams(body []byte) map[string]string {
+	params := make(map[string]string)
+	fields := strings.Split(string(body), "\x00")
+	for i := 0; i+1 < len(fields); i += 2 {
+		if fields[i] == "" {
+			break
+		}
+		params[fields[i]] = fields[i+1]
+	}
+	return params
+}
+
+// readMessage reads a typed frontend message
+func readMessage(r io.Reader) (typ byte, body []byte, err error) {
+	var header [5]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return 0, nil, err
+	}
+	length := int32(binary.BigEndian.Uint32(header[1:]))
+	if length < 4 || length > pgMaxMessageSize {
+		return 0, nil, fmt.Errorf("invalid message length %d", length)
+	}
+	body = make([]byte, length-4)
+	if _, err := io.ReadFull(r, body); err != nil {
+		return 0, nil, err
+	}
+	return header[0], body, nil
+}
+
+// splitStatements splits a simple-query string on semicolons outside quotes
+// Blank statements are dropped.
+func splitStatements(query string) []string {
+	var statements []string
+	var quote rune
+	start := 0
+	for i, c := range query {
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0 // a doubled quote ('') closes and reopens, which is equivalent
+			}
+		case c == '\'' || c == '"':
+			quote = c
+		case c == ';':
+			if stmt := strings.TrimSpace(query[start:i]); stmt != "" {
+				statements = append(statements, stmt)
+			}
+			start = i + 1
+		}
+	}
+	if stmt := strings.TrimSpace(query[start:]); stmt != "" {
+		statements = append(statements, stmt)
+	}
+	return statements
+}
+
+// commandTag builds the CommandComplete tag for a statement, e.g. "SELECT 3", "INSERT 0 1"
+func commandTag(sql string, result *executor.Result) string {
+	words := strings.Fields(strings.ToUpper(sql))
+	if len(words) == 0 {
+		return ""
+	}
+
+	switch words[0] {
+	case "SELECT":
+		return fmt.Sprintf("SELECT %d", len(result.Rows))
+	case "INSERT":
+		return fmt.Sprintf("INSERT 0 %d", result.RowsAffected)
+	case "UPDATE", "DELETE":
+		return fmt.Sprintf("%s %d", words[0], result.RowsAffected)
+	case "CREATE", "DROP", "ALTER":
+		if len(words) > 1 {
+			return words[0] + " " + words[1]
+		}
+	case "BEGIN", "START":
+		return "BEGIN"
+	}
+	return words[0]
+}
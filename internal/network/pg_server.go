@@ -0,0 +1,255 @@
+package network
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"math/rand"
+	"net"
+	"os"
+	"strings"
+
+	"github.com/leengari/mini-rdbms/internal/engine"
+	"github.com/leengari/mini-rdbms/internal/storage/manager"
+)
+
+// StartPG runs a TCP server speaking the PostgreSQL v3 wire protocol until ctx is cancelled
+// Supports the startup handshake (no TLS, no authentication) and the simple
+// query protocol, so psql and stock Postgres drivers can run SQL against JoyDB.
+// The "database" startup parameter selects the database, like USE.
+// Returns an error if the port cannot be bound; shuts down like Start.
+func StartPG(ctx context.Context, port int, registry *manager.Registry) error {
+	addr := fmt.Sprintf(":%d", port)
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("failed to bind PostgreSQL port %d: %w", port, err)
+	}
+
+	slog.Info("PostgreSQL protocol running on port", "port", port)
+
+	err = serve(ctx, listener, func(conn net.Conn) {
+		handlePGConnection(conn, registry)
+	})
+	slog.Info("PostgreSQL server stopped", "port", port)
+	return err
+}
+
+func handlePGConnection(conn net.Conn, registry *manager.Registry) {
+	defer conn.Close()
+
+	reader := bufio.NewReader(conn)
+	writer := &pgWriter{w: bufio.NewWriter(conn)}
+
+	params, err := pgStartup(reader, conn)
+	if err != nil {
+		if !errors.Is(err, io.EOF) && !isShutdown(err) {
+			slog.Error("postgres startup failed", "error", err)
+		}
+		return
+	}
+
+	dbEngine := engine.New(nil, registry)
+	defer dbEngine.Close() // a dropped connection rolls back its open transaction
+
+	// Register logging observer for lifecycle tracing
+	dbEngine.AddObserver(engine.NewLoggingObserver())
+	session := &pgSession{engine: dbEngine}
+
+	if dbName := params["database"]; dbName != "" {
+		if err := dbEngine.Use(dbName); err != nil {
+			_ = writer.errorResponse("FATAL", "3D000", err.Error())
+			_ = writer.Flush()
+			return
+		}
+	}
+
+	if err := pgSendStartupReply(writer); err != nil {
+		slog.Error("postgres write error", "error", err)
+		return
+	}
+
+	// After an unsupported extended-protocol message, skip messages until Sync
+	skipUntilSync := false
+
+	for {
+		typ, body, err := readMessage(reader)
+		if err != nil {
+			if isShutdown(err) {
+				_ = writer.errorResponse("FATAL", "57P01", "terminating connection due to server shutdown")
+				_ = writer.Flush()
+				return
+			}
+			if !errors.Is(err, io.EOF) {
+				slog.Error("postgres read error", "error", err)
+			}
+			return
+		}
+
+		switch typ {
+		case pgMsgQuery:
+			query := strings.TrimSuffix(string(body), "\x00")
+			err = session.simpleQuery(writer, query)
+
+		case pgMsgTerminate:
+			return
+
+		case pgMsgSync:
+			skipUntilSync = false
+			err = writer.readyForQuery(session.txStatus())
+
+		case pgMsgParse, pgMsgBind, pgMsgDescribe, pgMsgExecute, pgMsgClose, pgMsgFlush:
+			if !skipUntilSync {
+				skipUntilSync = true
+				err = writer.errorResponse("ERROR", "0A000", "extended query protocol is not supported; use simple queries")
+			}
+
+		default:
+			err = writer.errorResponse("ERROR", "08P01", fmt.Sprintf("unsupported message type %q", typ))
+		}
+
+		if err == nil {
+			err = writer.Flush()
+		}
+		if err != nil {
+			slog.Error("postgres write error", "error", err)
+			return
+		}
+	}
+}
+
+// pgStartup handles SSL/GSS negotiation and returns the StartupMessage parameters
+func pgStartup(reader *bufio.Reader, conn net.Conn) (map[string]string, error) {
+	for {
+		code, body, err := readStartupMessage(reader)
+		if err != nil {
+			return nil, err
+		}
+
+		switch code {
+		case pgSSLRequestCode, pgGSSRequestCode:
+			// Encryption is not supported: the client continues in plain text
+			if _, err := conn.Write([]byte{'N'}); err != nil {
+				return nil, err
+			}
+		case pgCancelCode:
+			return nil, io.EOF
+		case pgProtocolVersion:
+			return parseStartupParams(body), nil
+		default:
+			return nil, fmt.Errorf("unsupported protocol version %d.%d", code>>16, code&0xffff)
+		}
+	}
+}
+
+// pgSendStartupReply completes the handshake: no authentication, server parameters, ready
+func pgSendStartupReply(writer *pgWriter) error {
+	if err := writer.authenticationOK(); err != nil {
+		return err
+	}
+	for _, p := range [][2]string{
+		{"server_version", "14.0 (JoyDB)"},
+		{"server_encoding", "UTF8"},
+		{"client_encoding", "UTF8"},
+		{"DateStyle", "ISO, MDY"},
+		{"integer_datetimes", "on"},
+		{"standard_conforming_strings", "on"},
+	} {
+		if err := writer.parameterStatus(p[0], p[1]); err != nil {
+			return err
+		}
+	}
+	if err := writer.backendKeyData(int32(os.Getpid()), rand.Int31()); err != nil {
+		return err
+	}
+	if err := writer.readyForQuery('I'); err != nil {
+		return err
+	}
+	return writer.Flush()
+}
+
+// pgSession is the state of one PostgreSQL connection
+type pgSession struct {
+	engine *engine.Engine
+	// failed is set when a statement fails inside a transaction: as in PostgreSQL, the
+	// transaction then only accepts ROLLBACK (or COMMIT, which rolls back)
+	failed bool
+}
+
+// simpleQuery runs every statement of a Query message and ends with ReadyForQuery
+// Execution stops at the first failing statement.
+func (s *pgSession) simpleQuery(writer *pgWriter, query string) error {
+	statements := splitStatements(query)
+	if len(statements) == 0 {
+		if err := writer.emptyQueryResponse(); err != nil {
+			return err
+		}
+	}
+
+	for _, sql := range statements {
+		if s.failed && !s.engine.InTransaction() {
+			s.failed = false // rolled back meanwhile (idle timeout)
+		}
+		if s.failed {
+			if !endsTransaction(sql) {
+				if err := writer.errorResponse("ERROR", pgCodeInFailedTx, "current transaction is aborted, commands ignored until end of transaction block"); err != nil {
+					return err
+				}
+				break
+			}
+			sql = "ROLLBACK"
+		}
+
+		result, err := s.engine.Execute(sql)
+		if err != nil {
+			s.failed = s.engine.InTransaction()
+			if err := writer.errorResponse("ERROR", pgErrorCode(err), err.Error()); err != nil {
+				return err
+			}
+			break
+		}
+		s.failed = false
+
+		tag := commandTag(sql, result)
+		if result.Columns != nil || strings.HasPrefix(tag, "SELECT") {
+			if err := writer.rowDescription(result); err != nil {
+				return err
+			}
+			for _, row := range result.Rows {
+				if err := writer.dataRow(result, row.Data); err != nil {
+					return err
+				}
+			}
+		} else if result.Message != "" {
+			if err := writer.notice(result.Message); err != nil {
+				return err
+			}
+		}
+
+		if err := writer.commandComplete(tag); err != nil {
+			return err
+		}
+	}
+
+	return writer.readyForQuery(s.txStatus())
+}
+
+// txStatus returns the ReadyForQuery transaction status of the session
+func (s *pgSession) txStatus() byte {
+	switch {
+	case !s.engine.InTransaction():
+		return 'I'
+	case s.failed:
+		return 'E'
+	default:
+		return 'T'
+	}
+}
+
+// endsTransaction reports whether a statement is COMMIT or ROLLBACK
+func endsTransaction(sql string) bool {
+	words := strings.Fields(strings.ToUpper(sql))
+	return len(words) > 0 && (words[0] == "COMMIT" || words[0] == "ROLLBACK")
+}
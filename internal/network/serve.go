@@ -0,0 +1,112 @@
+package network
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"os"
+	"sync"
+	"time"
+)
+
+// ShutdownTimeout bounds how long a server waits for its sessions to end once its
+// context is cancelled. Sessions still running after that have their connections closed.
+var ShutdownTimeout = 10 * time.Second
+
+// sessions tracks the open connections of one listener so they can be drained
+type sessions struct {
+	mu    sync.Mutex
+	conns map[net.Conn]struct{}
+	wg    sync.WaitGroup
+}
+
+// serve accepts connections and runs handle for each one until ctx is cancelled,
+// then closes the listener and drains the open sessions
+//
+// Sessions waiting for their next request are woken by an expired read deadline;
+// a session running a statement finishes it (and sends the result) first. Handlers
+// roll back their open transaction when they return.
+func serve(ctx context.Context, listener net.Listener, handle func(net.Conn)) error {
+	s := &sessions{conns: make(map[net.Conn]struct{})}
+
+	stop := context.AfterFunc(ctx, func() { listener.Close() })
+	defer stop()
+
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			if ctx.Err() != nil {
+				return s.drain(listener.Addr())
+			}
+			if errors.Is(err, net.ErrClosed) {
+				return err
+			}
+			slog.Error("Failed to accept connection", "error", err)
+			continue
+		}
+
+		s.add(conn)
+		go func() {
+			defer s.remove(conn)
+			handle(conn)
+		}()
+	}
+}
+
+// add registers a new session
+func (s *sessions) add(conn net.Conn) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.conns[conn] = struct{}{}
+	s.wg.Add(1)
+}
+
+// remove unregisters a session once its handler has returned
+func (s *sessions) remove(conn net.Conn) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.conns, conn)
+	s.wg.Done()
+}
+
+// drain wakes every open session and waits up to ShutdownTimeout for them to end
+func (s *sessions) drain(addr net.Addr) error {
+	s.mu.Lock()
+	open := len(s.conns)
+	for conn := range s.conns {
+		conn.SetReadDeadline(time.Now())
+	}
+	s.mu.Unlock()
+
+	if open > 0 {
+		slog.Info("Waiting for sessions to end", "addr", addr.String(), "sessions", open)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-time.After(ShutdownTimeout):
+	}
+
+	// Force the rest out; their handlers still roll back when they return
+	s.mu.Lock()
+	open = len(s.conns)
+	for conn := range s.conns {
+		conn.Close()
+	}
+	s.mu.Unlock()
+	return fmt.Errorf("%d sessions on %s did not end within %s", open, addr, ShutdownTimeout)
+}
+
+// isShutdown reports whether a read failed because the server is draining its sessions
+func isShutdown(err error) bool {
+	return errors.Is(err, os.ErrDeadlineExceeded)
+}
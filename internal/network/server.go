@@ -1,6 +1,7 @@
 package network
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -16,32 +17,30 @@ type Request struct {
 	Query string `json:"query"`
 }
 
-// Start starts the TCP database server
-func Start(port int, registry *manager.Registry) {
+// Start runs the TCP database server until ctx is cancelled
+// Returns an error if the port cannot be bound. On cancellation it stops accepting
+// connections and drains open sessions (see serve); open transactions are rolled back.
+func Start(ctx context.Context, port int, registry *manager.Registry) error {
 	addr := fmt.Sprintf(":%d", port)
 	listener, err := net.Listen("tcp", addr)
 	if err != nil {
-		slog.Error("Failed to bind to port", "port", port, "error", err)
-		return
+		return fmt.Errorf("failed to bind to port %d: %w", port, err)
 	}
-	defer listener.Close()
 
 	slog.Info("Running on port", "port", port)
 
-	for {
-		conn, err := listener.Accept()
-		if err != nil {
-			slog.Error("Failed to accept connection", "error", err)
-			continue
-		}
-		go handleConnection(conn, registry)
-	}
+	err = serve(ctx, listener, func(conn net.Conn) {
+		handleConnection(conn, registry)
+	})
+	slog.Info("Server stopped", "port", port)
+	return err
 }
 
 func handleConnection(conn net.Conn, registry *manager.Registry) {
 	defer conn.Close()
 
 	dbEngine := engine.New(nil, registry)
+	defer dbEngine.Close() // a dropped connection rolls back its open transaction
 	
 	// Register logging observer for lifecycle tracing
 	loggingObserver := engine.NewLoggingObserver()
@@ -58,6 +57,10 @@ func handleConnection(conn net.Conn, registry *manager.Registry) {
 			if err == io.EOF {
 				return // Connection closed gracefully
 			}
+			if isShutdown(err) {
+				_ = encoder.Encode(&executor.Result{Error: "server is shutting down"})
+				return
+			}
 			slog.Error("decode error", "error", err)
 			
 			// Send error back to client
@@ -1,5 +1,10 @@
 package ast
 
+import (
+	"strconv"
+	"strings"
+)
+
 // Identifier represents a column or table name
 // Can be qualified (table.column) or unqualified (column)
 type Identifier struct {
@@ -28,16 +33,148 @@ const (
 	LiteralDate   LiteralKind = "DATE"
 	LiteralTime   LiteralKind = "TIME"
 	LiteralEmail  LiteralKind = "EMAIL"
+	LiteralNull   LiteralKind = "NULL"
 )
 
-// Literal represents a fixed value (string, number, boolean, date, time, email)
-// Examples: 'hello', 42, 3.14, true, DATE '2024-01-13', TIME '14:30:00', EMAIL 'user@example.com'
+// Literal represents a fixed value (string, number, boolean, date, time, email, NULL)
+// Examples: 'hello', 42, 3.14, true, DATE '2024-01-13', TIME '14:30:00', EMAIL 'user@example.com', NULL
 type Literal struct {
 	TokenLiteralValue string      // The original token text
-	Value             interface{} // The parsed value (string, int, float64, bool)
+	Value             interface{} // The parsed value (string, int, float64, bool, nil for NULL)
 	Kind              LiteralKind // The type of literal
 }
 
 func (l *Literal) expressionNode()      {}
 func (l *Literal) TokenLiteral() string { return l.TokenLiteralValue }
 func (l *Literal) String() string       { return l.TokenLiteralValue }
+
+// FunctionCall represents a function applied to arguments
+// Examples: COUNT(*), COUNT(DISTINCT user_id), SUM(orders.amount)
+type FunctionCall struct {
+	Name     string       // Upper-cased function name (e.g. "COUNT")
+	Args     []Expression // Arguments (empty for COUNT(*))
+	Star     bool         // Called with * (COUNT(*))
+	Distinct bool         // DISTINCT applied to the arguments
+}
+
+func (f *FunctionCall) expressionNode()      {}
+func (f *FunctionCall) TokenLiteral() string { return f.Name }
+func (f *FunctionCall) String() string {
+	if f.Star {
+		return f.Name + "(*)"
+	}
+	args := make([]string, len(f.Args))
+	for i, arg := range f.Args {
+		args[i] = arg.String()
+	}
+	prefix := ""
+	if f.Distinct {
+		prefix = "DISTINCT "
+	}
+	return f.Name + "(" + prefix + strings.Join(args, ", ") + ")"
+}
+
+// WindowExpression applies a function to the window of rows related to the current row
+// Examples: ROW_NUMBER() OVER (PARTITION BY user_id ORDER BY created_at),
+// SUM(amount) OVER (ORDER BY id ROWS BETWEEN 2 PRECEDING AND CURRENT ROW)
+type WindowExpression struct {
+	Function    *FunctionCall
+	PartitionBy []Expression   // Columns splitting the rows into partitions (none: one partition)
+	OrderBy     []*OrderByItem // Order of the rows within each partition
+	Frame       *WindowFrame   // Rows of the partition the function reads (nil for the default frame)
+}
+
+func (w *WindowExpression) expressionNode()      {}
+func (w *WindowExpression) TokenLiteral() string { return "OVER" }
+func (w *WindowExpression) String() string {
+	var clauses []string
+	if len(w.PartitionBy) > 0 {
+		keys := make([]string, len(w.PartitionBy))
+		for i, key := range w.PartitionBy {
+			keys[i] = key.String()
+		}
+		clauses = append(clauses, "PARTITION BY "+strings.Join(keys, ", "))
+	}
+	if len(w.OrderBy) > 0 {
+		items := make([]string, len(w.OrderBy))
+		for i, item := range w.OrderBy {
+			items[i] = item.String()
+		}
+		clauses = append(clauses, "ORDER BY "+strings.Join(items, ", "))
+	}
+	if w.Frame != nil {
+		clauses = append(clauses, w.Frame.String())
+	}
+	return w.Function.String() + " OVER (" + strings.Join(clauses, " ") + ")"
+}
+
+// WindowFrame limits a window to the rows between two bounds around the current row
+// ROWS bounds count rows; RANGE bounds compare ORDER BY values, so peers (rows with
+// equal ORDER BY values) are always in or out of the frame together.
+// Example: ROWS BETWEEN 2 PRECEDING AND CURRENT ROW
+type WindowFrame struct {
+	Mode  string // "ROWS" or "RANGE"
+	Start FrameBound
+	End   FrameBound
+}
+
+func (f *WindowFrame) String() string {
+	return f.Mode + " BETWEEN " + f.Start.String() + " AND " + f.End.String()
+}
+
+// FrameBoundKind identifies the position of a window frame bound
+type FrameBoundKind string
+
+const (
+	UnboundedPreceding FrameBoundKind = "UNBOUNDED PRECEDING"
+	Preceding          FrameBoundKind = "PRECEDING"
+	CurrentRow         FrameBoundKind = "CURRENT ROW"
+	Following          FrameBoundKind = "FOLLOWING"
+	UnboundedFollowing FrameBoundKind = "UNBOUNDED FOLLOWING"
+)
+
+// FrameBound is one end of a window frame
+// Examples: UNBOUNDED PRECEDING, 3 PRECEDING, CURRENT ROW, 1 FOLLOWING
+type FrameBound struct {
+	Kind   FrameBoundKind
+	Offset int // Distance from the current row for PRECEDING and FOLLOWING
+}
+
+func (b FrameBound) String() string {
+	if b.Kind == Preceding || b.Kind == Following {
+		return strconv.Itoa(b.Offset) + " " + string(b.Kind)
+	}
+	return string(b.Kind)
+}
+
+// AliasExpression names a SELECT field (e.g. price * quantity AS total)
+type AliasExpression struct {
+	Expr  Expression
+	Alias string // Lower-cased output column name
+}
+
+func (a *AliasExpression) expressionNode()      {}
+func (a *AliasExpression) TokenLiteral() string { return "AS" }
+func (a *AliasExpression) String() string       { return a.Expr.String() + " AS " + a.Alias }
+
+// CastExpression converts a value to a column type
+// Example: CAST(price AS INT)
+type CastExpression struct {
+	Expr Expression
+	Type string // Upper-cased target type name (e.g. "INT")
+}
+
+func (c *CastExpression) expressionNode()      {}
+func (c *CastExpression) TokenLiteral() string { return "CAST" }
+func (c *CastExpression) String() string       { return "CAST(" + c.Expr.String() + " AS " + c.Type + ")" }
+
+// SubqueryExpression is a SELECT used as a value (a scalar subquery)
+// It must return one column and at most one row; no rows is NULL.
+// Example: (SELECT MAX(total) FROM orders)
+type SubqueryExpression struct {
+	Query *SelectStatement
+}
+
+func (s *SubqueryExpression) expressionNode()      {}
+func (s *SubqueryExpression) TokenLiteral() string { return "SELECT" }
+func (s *SubqueryExpression) String() string       { return "(" + s.Query.String() + ")" }
@@ -1,8 +1,12 @@
 package ast
 
-import "fmt"
+import (
+	"fmt"
+	"strings"
+)
 
-// BinaryExpression: Left Operator Right (e.g. id = 1)
+// BinaryExpression: Left Operator Right (e.g. id = 1, price * 2, first || last)
+// Operator is a comparison (=, <, >, <=, >=, !=, <>) or a scalar operator (+, -, *, /, %, ||)
 type BinaryExpression struct {
 	Left     Expression
 	Operator string
@@ -15,6 +19,19 @@ func (e *BinaryExpression) String() string {
 	return fmt.Sprintf("(%s %s %s)", e.Left.String(), e.Operator, e.Right.String())
 }
 
+// UnaryExpression: Operator Operand (e.g. -balance)
+// Operator is "-" or "+"
+type UnaryExpression struct {
+	Operator string
+	Operand  Expression
+}
+
+func (e *UnaryExpression) expressionNode()      {}
+func (e *UnaryExpression) TokenLiteral() string { return e.Operator }
+func (e *UnaryExpression) String() string {
+	return fmt.Sprintf("(%s%s)", e.Operator, e.Operand.String())
+}
+
 // LogicalExpression: Left Operator Right (e.g. age > 18 AND active = true)
 // Represents logical operations (AND, OR) that combine multiple conditions
 // Used in WHERE clauses to create complex predicates
@@ -29,3 +46,114 @@ func (e *LogicalExpression) TokenLiteral() string { return e.Operator }
 func (e *LogicalExpression) String() string {
 	return fmt.Sprintf("(%s %s %s)", e.Left.String(), e.Operator, e.Right.String())
 }
+
+// InExpression: Left [NOT] IN (v1, v2, ...) (e.g. id IN (1, 2, 3))
+// True when Left equals any of the listed values, or any row of Subquery when it is set
+// (e.g. user_id IN (SELECT id FROM users WHERE is_active = true))
+type InExpression struct {
+	Left     Expression
+	Values   []Expression
+	Subquery *SelectStatement // IN (SELECT ...) instead of a value list
+	Not      bool             // NOT IN
+}
+
+func (e *InExpression) expressionNode()      {}
+func (e *InExpression) TokenLiteral() string { return "IN" }
+func (e *InExpression) String() string {
+	if e.Subquery != nil {
+		return fmt.Sprintf("(%s %s (%s))", e.Left.String(), negated("IN", e.Not), e.Subquery.String())
+	}
+	values := make([]string, len(e.Values))
+	for i, v := range e.Values {
+		values[i] = v.String()
+	}
+	return fmt.Sprintf("(%s %s (%s))", e.Left.String(), negated("IN", e.Not), strings.Join(values, ", "))
+}
+
+// BetweenExpression: Expr [NOT] BETWEEN Low AND High (e.g. age BETWEEN 18 AND 65)
+// Inclusive on both ends: the same as Expr >= Low AND Expr <= High
+type BetweenExpression struct {
+	Expr Expression
+	Low  Expression
+	High Expression
+	Not  bool // NOT BETWEEN
+}
+
+func (e *BetweenExpression) expressionNode()      {}
+func (e *BetweenExpression) TokenLiteral() string { return "BETWEEN" }
+func (e *BetweenExpression) String() string {
+	return fmt.Sprintf("(%s %s %s AND %s)", e.Expr.String(), negated("BETWEEN", e.Not), e.Low.String(), e.High.String())
+}
+
+// LikeExpression: Expr [NOT] LIKE|ILIKE Pattern [ESCAPE Escape] (e.g. name LIKE 'jo%')
+// In the pattern % matches any sequence of characters and _ exactly one;
+// the escape character (backslash unless ESCAPE is given) makes them literal.
+type LikeExpression struct {
+	Expr            Expression
+	Pattern         Expression
+	Escape          Expression // nil when no ESCAPE clause is given
+	Not             bool       // NOT LIKE / NOT ILIKE
+	CaseInsensitive bool       // ILIKE
+}
+
+func (e *LikeExpression) expressionNode() {}
+func (e *LikeExpression) TokenLiteral() string {
+	if e.CaseInsensitive {
+		return "ILIKE"
+	}
+	return "LIKE"
+}
+func (e *LikeExpression) String() string {
+	s := fmt.Sprintf("%s %s %s", e.Expr.String(), negated(e.TokenLiteral(), e.Not), e.Pattern.String())
+	if e.Escape != nil {
+		s += " ESCAPE " + e.Escape.String()
+	}
+	return "(" + s + ")"
+}
+
+// negated prefixes an operator with NOT when not is set
+func negated(op string, not bool) string {
+	if not {
+		return "NOT " + op
+	}
+	return op
+}
+
+// NotExpression: NOT Expr (e.g. NOT (age > 18))
+// Negates a condition; NOT of an unknown (NULL) result stays unknown
+type NotExpression struct {
+	Expr Expression
+}
+
+func (e *NotExpression) expressionNode()      {}
+func (e *NotExpression) TokenLiteral() string { return "NOT" }
+func (e *NotExpression) String() string {
+	return fmt.Sprintf("(NOT %s)", e.Expr.String())
+}
+
+// IsNullExpression: Expr IS [NOT] NULL (e.g. email IS NULL)
+// Always true or false, never unknown
+type IsNullExpression struct {
+	Expr Expression
+	Not  bool // IS NOT NULL
+}
+
+func (e *IsNullExpression) expressionNode()      {}
+func (e *IsNullExpression) TokenLiteral() string { return "IS" }
+func (e *IsNullExpression) String() string {
+	if e.Not {
+		return fmt.Sprintf("(%s IS NOT NULL)", e.Expr.String())
+	}
+	return fmt.Sprintf("(%s IS NULL)", e.Expr.String())
+}
+
+// ExistsExpression: EXISTS (subquery) (e.g. EXISTS (SELECT id FROM orders WHERE orders.user_id = users.id))
+// True when the subquery returns at least one row; never unknown. NOT EXISTS is a
+// NotExpression around it.
+type ExistsExpression struct {
+	Query *SelectStatement
+}
+
+func (e *ExistsExpression) expressionNode()      {}
+func (e *ExistsExpression) TokenLiteral() string { return "EXISTS" }
+func (e *ExistsExpression) String() string       { return "EXISTS (" + e.Query.String() + ")" }
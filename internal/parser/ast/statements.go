@@ -1,20 +1,36 @@
 package ast
 
-import "bytes"
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
 
-// SelectStatement: SELECT fields FROM table [JOIN ...] [WHERE condition]
-// Represents a SELECT SQL query with optional JOINs and WHERE clause
+// SelectStatement: SELECT fields FROM table [JOIN ...] [WHERE condition] [GROUP BY ...] [HAVING condition]
+// [ORDER BY ...] [LIMIT n] [OFFSET m]
+// Represents a SELECT SQL query with optional JOINs, WHERE, grouping, ORDER BY and paging clauses.
+// The FROM table may be a subquery (a derived table): FROM (SELECT ...) AS alias.
 type SelectStatement struct {
-	Fields    []*Identifier
-	TableName *Identifier
-	Joins     []*JoinClause // Optional JOIN clauses
-	Where     Expression    // Optional WHERE clause
+	With      *WithClause      // Optional WITH clause naming common table expressions
+	Fields    []Expression     // * or scalar expressions, each optionally wrapped in *AliasExpression
+	TableName *Identifier      // FROM table, or the alias of the derived table
+	Derived   *SelectStatement // Optional subquery read as the FROM table
+	Joins     []*JoinClause    // Optional JOIN clauses
+	Where     Expression       // Optional WHERE clause
+	GroupBy   []*Identifier    // Optional GROUP BY columns
+	Having    Expression       // Optional HAVING clause (may reference aggregates)
+	OrderBy   []*OrderByItem   // Optional ORDER BY keys, in priority order
+	Limit     *int             // Optional LIMIT (nil = no limit)
+	Offset    *int             // Optional OFFSET (nil = 0)
 }
 
 func (s *SelectStatement) statementNode()       {}
 func (s *SelectStatement) TokenLiteral() string { return "SELECT" }
 func (s *SelectStatement) String() string {
 	var out bytes.Buffer
+	if s.With != nil {
+		out.WriteString(s.With.String() + " ")
+	}
 	out.WriteString("SELECT ")
 	for i, f := range s.Fields {
 		if i > 0 {
@@ -23,6 +39,9 @@ func (s *SelectStatement) String() string {
 		out.WriteString(f.String())
 	}
 	out.WriteString(" FROM ")
+	if s.Derived != nil {
+		out.WriteString("(" + s.Derived.String() + ") AS ")
+	}
 	out.WriteString(s.TableName.String())
 	
 	// Add JOINs if present
@@ -35,32 +54,179 @@ func (s *SelectStatement) String() string {
 		out.WriteString(" WHERE ")
 		out.WriteString(s.Where.String())
 	}
+	if len(s.GroupBy) > 0 {
+		out.WriteString(" GROUP BY ")
+		for i, g := range s.GroupBy {
+			if i > 0 {
+				out.WriteString(", ")
+			}
+			out.WriteString(g.String())
+		}
+	}
+	if s.Having != nil {
+		out.WriteString(" HAVING ")
+		out.WriteString(s.Having.String())
+	}
+	writeOrdering(&out, s.OrderBy, s.Limit, s.Offset)
+	return out.String()
+}
+
+// writeOrdering writes the ORDER BY, LIMIT and OFFSET clauses of a query
+func writeOrdering(out *bytes.Buffer, orderBy []*OrderByItem, limit, offset *int) {
+	if len(orderBy) > 0 {
+		out.WriteString(" ORDER BY ")
+		for i, item := range orderBy {
+			if i > 0 {
+				out.WriteString(", ")
+			}
+			out.WriteString(item.String())
+		}
+	}
+	if limit != nil {
+		out.WriteString(fmt.Sprintf(" LIMIT %d", *limit))
+	}
+	if offset != nil {
+		out.WriteString(fmt.Sprintf(" OFFSET %d", *offset))
+	}
+}
+
+// SetOperationStatement: query UNION|INTERSECT|EXCEPT [ALL] query [ORDER BY ...] [LIMIT n] [OFFSET m]
+// Combines the rows of two queries; either side may itself be a set operation. INTERSECT
+// binds tighter than UNION and EXCEPT, which associate to the left. ORDER BY, LIMIT and
+// OFFSET apply to the combined rows.
+type SetOperationStatement struct {
+	With     *WithClause    // Optional WITH clause naming common table expressions
+	Left     Statement      // *SelectStatement or *SetOperationStatement
+	Operator string         // "UNION", "INTERSECT" or "EXCEPT"
+	All      bool           // Keep duplicate rows (ALL) instead of returning distinct rows
+	Right    Statement      // *SelectStatement or *SetOperationStatement
+	OrderBy  []*OrderByItem // Optional ORDER BY keys, naming output columns
+	Limit    *int           // Optional LIMIT (nil = no limit)
+	Offset   *int           // Optional OFFSET (nil = 0)
+}
+
+func (s *SetOperationStatement) statementNode()       {}
+func (s *SetOperationStatement) TokenLiteral() string { return s.Operator }
+func (s *SetOperationStatement) String() string {
+	var out bytes.Buffer
+	if s.With != nil {
+		out.WriteString(s.With.String() + " ")
+	}
+	out.WriteString(s.Left.String())
+	out.WriteString(" " + s.Operator + " ")
+	if s.All {
+		out.WriteString("ALL ")
+	}
+	out.WriteString(s.Right.String())
+	writeOrdering(&out, s.OrderBy, s.Limit, s.Offset)
+	return out.String()
+}
+
+// WithClause lists the common table expressions (CTEs) defined for a SELECT
+// Example: WITH RECURSIVE tree AS (...), leaves AS (...)
+type WithClause struct {
+	Recursive bool                     // WITH RECURSIVE: a CTE's recursive term may read the CTE itself
+	Tables    []*CommonTableExpression // In definition order; each may reference the ones before it
+}
+
+func (w *WithClause) String() string {
+	var out bytes.Buffer
+	out.WriteString("WITH ")
+	if w.Recursive {
+		out.WriteString("RECURSIVE ")
+	}
+	for i, cte := range w.Tables {
+		if i > 0 {
+			out.WriteString(", ")
+		}
+		out.WriteString(cte.String())
+	}
+	return out.String()
+}
+
+// CommonTableExpression is one named query in a WITH clause
+// Example: tree (id, depth) AS (SELECT id, 0 FROM nodes WHERE parent_id IS NULL
+// UNION ALL SELECT nodes.id, tree.depth + 1 FROM nodes JOIN tree ON nodes.parent_id = tree.id)
+type CommonTableExpression struct {
+	Name      *Identifier
+	Columns   []string         // Optional column names (default: the query's output names)
+	Query     *SelectStatement // The query, or the anchor (non-recursive term) of a UNION
+	Recursive *SelectStatement // Optional term after UNION [ALL]
+	UnionAll  bool             // UNION ALL keeps duplicate rows; UNION discards them
+}
+
+func (c *CommonTableExpression) String() string {
+	var out bytes.Buffer
+	out.WriteString(c.Name.String())
+	if len(c.Columns) > 0 {
+		out.WriteString(" (" + strings.Join(c.Columns, ", ") + ")")
+	}
+	out.WriteString(" AS (" + c.Query.String())
+	if c.Recursive != nil {
+		out.WriteString(" UNION ")
+		if c.UnionAll {
+			out.WriteString("ALL ")
+		}
+		out.WriteString(c.Recursive.String())
+	}
+	out.WriteString(")")
+	return out.String()
+}
+
+// OrderByItem is one sort key in an ORDER BY clause
+// Examples: users.name DESC NULLS LAST, COUNT(*) DESC
+type OrderByItem struct {
+	Expr       Expression // *Identifier or aggregate *FunctionCall
+	Descending bool   // DESC (default ASC)
+	Nulls      string // "FIRST", "LAST", or "" for the default (LAST for ASC, FIRST for DESC)
+}
+
+func (o *OrderByItem) String() string {
+	var out bytes.Buffer
+	out.WriteString(o.Expr.String())
+	if o.Descending {
+		out.WriteString(" DESC")
+	} else {
+		out.WriteString(" ASC")
+	}
+	if o.Nulls != "" {
+		out.WriteString(" NULLS ")
+		out.WriteString(o.Nulls)
+	}
 	return out.String()
 }
 
 // JoinClause represents a JOIN operation in a SELECT statement
 // Example: INNER JOIN orders ON users.id = orders.user_id
 type JoinClause struct {
-	JoinType    string      // "INNER", "LEFT", "RIGHT", "FULL"
-	RightTable  *Identifier // Table to join with
-	OnCondition Expression  // JOIN condition (e.g., users.id = orders.user_id)
+	JoinType    string           // "INNER", "LEFT", "RIGHT", "FULL"
+	RightTable  *Identifier      // Table to join with, or the alias of the derived table
+	Derived     *SelectStatement // Optional subquery joined as a table: JOIN (SELECT ...) AS alias
+	OnCondition Expression       // JOIN condition (e.g., users.id = orders.user_id)
 }
 
 func (j *JoinClause) String() string {
 	var out bytes.Buffer
 	out.WriteString(j.JoinType)
 	out.WriteString(" JOIN ")
+	if j.Derived != nil {
+		out.WriteString("(" + j.Derived.String() + ") AS ")
+	}
 	out.WriteString(j.RightTable.String())
 	out.WriteString(" ON ")
 	out.WriteString(j.OnCondition.String())
 	return out.String()
 }
 
-// InsertStatement: INSERT INTO table (col1, col2) VALUES (val1, val2)
+// InsertStatement: INSERT INTO table (col1, col2) VALUES (val1, val2), (val3, val4)
+// or INSERT INTO table (col1, col2) SELECT ...
+// Exactly one of Rows and Query is set.
 type InsertStatement struct {
 	TableName *Identifier
 	Columns   []*Identifier
-	Values    []Expression
+	Rows      [][]Expression // One list of values per row of VALUES
+	Query     Statement      // SELECT or set operation whose rows are inserted
+	Returning []Expression   // Optional RETURNING fields (a single * for all columns)
 }
 
 func (s *InsertStatement) statementNode()       {}
@@ -76,14 +242,28 @@ func (s *InsertStatement) String() string {
 			out.WriteString(", ")
 		}
 	}
-	out.WriteString(") VALUES (")
-	for i, v := range s.Values {
-		out.WriteString(v.String())
-		if i < len(s.Values)-1 {
+	out.WriteString(")")
+	if s.Query != nil {
+		out.WriteString(" ")
+		out.WriteString(s.Query.String())
+		out.WriteString(returningString(s.Returning))
+		return out.String()
+	}
+	out.WriteString(" VALUES ")
+	for i, values := range s.Rows {
+		out.WriteString("(")
+		for j, v := range values {
+			out.WriteString(v.String())
+			if j < len(values)-1 {
+				out.WriteString(", ")
+			}
+		}
+		out.WriteString(")")
+		if i < len(s.Rows)-1 {
 			out.WriteString(", ")
 		}
 	}
-	out.WriteString(")")
+	out.WriteString(returningString(s.Returning))
 	return out.String()
 }
 
@@ -95,6 +275,7 @@ type UpdateStatement struct {
 	TableName *Identifier
 	Updates   map[string]Expression // column name -> new value expression
 	Where     Expression            // optional predicate
+	Returning []Expression          // Optional RETURNING fields (a single * for all columns)
 }
 
 func (s *UpdateStatement) statementNode()       {}
@@ -121,6 +302,7 @@ func (s *UpdateStatement) String() string {
 		out.WriteString(" WHERE ")
 		out.WriteString(s.Where.String())
 	}
+	out.WriteString(returningString(s.Returning))
 	return out.String()
 }
 
@@ -129,7 +311,8 @@ func (s *UpdateStatement) String() string {
 // WHERE clause is optional - if nil, all rows will be deleted.
 type DeleteStatement struct {
 	TableName *Identifier
-	Where     Expression // optional predicate
+	Where     Expression   // optional predicate
+	Returning []Expression // Optional RETURNING fields (a single * for all columns)
 }
 
 func (s *DeleteStatement) statementNode()       {}
@@ -142,9 +325,22 @@ func (s *DeleteStatement) String() string {
 		out.WriteString(" WHERE ")
 		out.WriteString(s.Where.String())
 	}
+	out.WriteString(returningString(s.Returning))
 	return out.String()
 }
 
+// returningString formats the RETURNING clause of a data modification statement ("" when absent)
+func returningString(fields []Expression) string {
+	if len(fields) == 0 {
+		return ""
+	}
+	parts := make([]string, len(fields))
+	for i, f := range fields {
+		parts[i] = f.String()
+	}
+	return " RETURNING " + strings.Join(parts, ", ")
+}
+
 // CreateDatabaseStatement: CREATE DATABASE name
 type CreateDatabaseStatement struct {
 	Name string
@@ -189,3 +385,181 @@ func (s *UseDatabaseStatement) TokenLiteral() string { return "USE" }
 func (s *UseDatabaseStatement) String() string {
 	return "USE " + s.Name
 }
+
+// BeginStatement: BEGIN [TRANSACTION]
+type BeginStatement struct{}
+
+func (s *BeginStatement) statementNode()       {}
+func (s *BeginStatement) TokenLiteral() string { return "BEGIN" }
+func (s *BeginStatement) String() string       { return "BEGIN" }
+
+// CommitStatement: COMMIT [TRANSACTION]
+type CommitStatement struct{}
+
+func (s *CommitStatement) statementNode()       {}
+func (s *CommitStatement) TokenLiteral() string { return "COMMIT" }
+func (s *CommitStatement) String() string       { return "COMMIT" }
+
+// RollbackStatement: ROLLBACK [TRANSACTION]
+type RollbackStatement struct{}
+
+func (s *RollbackStatement) statementNode()       {}
+func (s *RollbackStatement) TokenLiteral() string { return "ROLLBACK" }
+func (s *RollbackStatement) String() string       { return "ROLLBACK" }
+
+// CheckpointStatement: CHECKPOINT
+// Writes every table with unsaved changes to disk and truncates the write-ahead logs
+type CheckpointStatement struct{}
+
+func (s *CheckpointStatement) statementNode()       {}
+func (s *CheckpointStatement) TokenLiteral() string { return "CHECKPOINT" }
+func (s *CheckpointStatement) String() string       { return "CHECKPOINT" }
+
+// ColumnDefinition describes a single column in a CREATE TABLE statement
+// Example: id INT PRIMARY KEY AUTO_INCREMENT
+type ColumnDefinition struct {
+	Name          string
+	Type          string // Type name as written (e.g. "INT", "TEXT")
+	PrimaryKey    bool
+	Unique        bool
+	NotNull       bool
+	AutoIncrement bool
+	Default       Expression // Optional DEFAULT value (nil if none)
+}
+
+func (c *ColumnDefinition) String() string {
+	var out bytes.Buffer
+	out.WriteString(c.Name)
+	out.WriteString(" ")
+	out.WriteString(c.Type)
+	if c.PrimaryKey {
+		out.WriteString(" PRIMARY KEY")
+	}
+	if c.Unique {
+		out.WriteString(" UNIQUE")
+	}
+	if c.NotNull {
+		out.WriteString(" NOT NULL")
+	}
+	if c.AutoIncrement {
+		out.WriteString(" AUTO_INCREMENT")
+	}
+	if c.Default != nil {
+		out.WriteString(" DEFAULT ")
+		out.WriteString(c.Default.String())
+	}
+	return out.String()
+}
+
+// CreateTableStatement: CREATE TABLE name (col TYPE [constraints], ...)
+type CreateTableStatement struct {
+	TableName *Identifier
+	Columns   []*ColumnDefinition
+}
+
+func (s *CreateTableStatement) statementNode()       {}
+func (s *CreateTableStatement) TokenLiteral() string { return "CREATE" }
+func (s *CreateTableStatement) String() string {
+	var out bytes.Buffer
+	out.WriteString("CREATE TABLE ")
+	out.WriteString(s.TableName.String())
+	out.WriteString(" (")
+	for i, c := range s.Columns {
+		if i > 0 {
+			out.WriteString(", ")
+		}
+		out.WriteString(c.String())
+	}
+	out.WriteString(")")
+	return out.String()
+}
+
+// DropTableStatement: DROP TABLE [IF EXISTS] name
+type DropTableStatement struct {
+	TableName *Identifier
+	IfExists  bool
+}
+
+func (s *DropTableStatement) statementNode()       {}
+func (s *DropTableStatement) TokenLiteral() string { return "DROP" }
+func (s *DropTableStatement) String() string {
+	if s.IfExists {
+		return "DROP TABLE IF EXISTS " + s.TableName.String()
+	}
+	return "DROP TABLE " + s.TableName.String()
+}
+
+// AlterTableAction identifies the kind of change made by an ALTER TABLE statement
+type AlterTableAction string
+
+const (
+	AlterAddColumn    AlterTableAction = "ADD COLUMN"
+	AlterDropColumn   AlterTableAction = "DROP COLUMN"
+	AlterRenameColumn AlterTableAction = "RENAME COLUMN"
+	AlterRenameTable  AlterTableAction = "RENAME TO"
+)
+
+// AlterTableStatement: ALTER TABLE name <action>
+// Examples:
+//   - ALTER TABLE users ADD COLUMN age INT DEFAULT 0
+//   - ALTER TABLE users DROP COLUMN age
+//   - ALTER TABLE users RENAME COLUMN name TO full_name
+//   - ALTER TABLE users RENAME TO customers
+type AlterTableStatement struct {
+	TableName  *Identifier
+	Action     AlterTableAction
+	Column     *ColumnDefinition // Column to add (ADD COLUMN)
+	ColumnName string            // Column to drop or rename (DROP/RENAME COLUMN)
+	NewName    string            // New column or table name (RENAME COLUMN/RENAME TO)
+}
+
+func (s *AlterTableStatement) statementNode()       {}
+func (s *AlterTableStatement) TokenLiteral() string { return "ALTER" }
+func (s *AlterTableStatement) String() string {
+	prefix := "ALTER TABLE " + s.TableName.String() + " "
+	switch s.Action {
+	case AlterAddColumn:
+		return prefix + "ADD COLUMN " + s.Column.String()
+	case AlterDropColumn:
+		return prefix + "DROP COLUMN " + s.ColumnName
+	case AlterRenameColumn:
+		return prefix + "RENAME COLUMN " + s.ColumnName + " TO " + s.NewName
+	case AlterRenameTable:
+		return prefix + "RENAME TO " + s.NewName
+	default:
+		return prefix + string(s.Action)
+	}
+}
+
+// CreateIndexStatement: CREATE [UNIQUE] INDEX name ON table (col, ...)
+type CreateIndexStatement struct {
+	Name      string
+	TableName *Identifier
+	Columns   []string
+	Unique    bool
+}
+
+func (s *CreateIndexStatement) statementNode()       {}
+func (s *CreateIndexStatement) TokenLiteral() string { return "CREATE" }
+func (s *CreateIndexStatement) String() string {
+	kind := "INDEX "
+	if s.Unique {
+		kind = "UNIQUE INDEX "
+	}
+	return "CREATE " + kind + s.Name + " ON " + s.TableName.String() + " (" + strings.Join(s.Columns, ", ") + ")"
+}
+
+// DropIndexStatement: DROP INDEX [IF EXISTS] name
+type DropIndexStatement struct {
+	Name     string
+	IfExists bool
+}
+
+func (s *DropIndexStatement) statementNode()       {}
+func (s *DropIndexStatement) TokenLiteral() string { return "DROP" }
+func (s *DropIndexStatement) String() string {
+	if s.IfExists {
+		return "DROP INDEX IF EXISTS " + s.Name
+	}
+	return "DROP INDEX " + s.Name
+}
@@ -2,13 +2,14 @@ package parser
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/leengari/mini-rdbms/internal/parser/ast"
 	"github.com/leengari/mini-rdbms/internal/parser/lexer"
 )
 
-// parseExpression parses expressions with logical operators (AND, OR) and comparisons
-// Implements precedence: OR (lowest) < AND < Comparison operators (highest)
+// parseExpression parses expressions with logical operators (AND, OR, NOT) and comparisons
+// Implements precedence: OR (lowest) < AND < NOT < Comparison operators < scalar operators (highest)
 // Examples: 
 //   - age > 18 AND active = true
 //   - status = 'pending' OR status = 'processing'
@@ -40,7 +41,7 @@ func (p *Parser) parseOrExpression() (ast.Expression, error) {
 
 // parseAndExpression handles AND operations (higher precedence than OR)
 func (p *Parser) parseAndExpression() (ast.Expression, error) {
-	left, err := p.parseComparisonExpression()
+	left, err := p.parseNotExpression()
 	if err != nil {
 		return nil, err
 	}
@@ -49,7 +50,7 @@ func (p *Parser) parseAndExpression() (ast.Expression, error) {
 	for p.curTok.Type == lexer.AND {
 		op := p.curTok.Literal
 		p.nextToken()
-		right, err := p.parseComparisonExpression()
+		right, err := p.parseNotExpression()
 		if err != nil {
 			return nil, err
 		}
@@ -59,40 +60,300 @@ func (p *Parser) parseAndExpression() (ast.Expression, error) {
 	return left, nil
 }
 
-// parseComparisonExpression handles comparison operations (highest precedence)
-// Supports: =, <, >, <=, >=, !=, <>
-// Also handles parenthesized expressions for grouping
+// parseNotExpression handles prefix NOT (binds tighter than AND, looser than comparisons)
+// Example: NOT active = true AND age > 18  →  (NOT (active = true)) AND (age > 18)
+func (p *Parser) parseNotExpression() (ast.Expression, error) {
+	if p.curTok.Type != lexer.NOT {
+		return p.parseComparisonExpression()
+	}
+	p.nextToken()
+
+	expr, err := p.parseNotExpression()
+	if err != nil {
+		return nil, err
+	}
+	return &ast.NotExpression{Expr: expr}, nil
+}
+
+// parseComparisonExpression handles comparison operations (binds tighter than NOT)
+// Supports: =, <, >, <=, >=, !=, <>, [NOT] IN (value, ... | SELECT ...), [NOT] BETWEEN low AND high,
+// [NOT] LIKE/ILIKE pattern [ESCAPE char] and IS [NOT] NULL
+// Operands are scalar expressions, so arithmetic binds tighter than comparisons
 func (p *Parser) parseComparisonExpression() (ast.Expression, error) {
-	// Handle parentheses for grouping
-	if p.curTok.Type == lexer.PAREN_OPEN {
+	// Parse left side (column, literal, arithmetic or parenthesized expression)
+	left, err := p.parseScalarExpression()
+	if err != nil {
+		return nil, err
+	}
+
+	// Check for comparison operator
+	if isComparisonOperator(p.curTok.Type) {
+		op := p.curTok.Literal
 		p.nextToken()
-		expr, err := p.parseExpression() // Recursive: allows nested logical expressions
+		right, err := p.parseScalarExpression()
 		if err != nil {
 			return nil, err
 		}
-		if p.curTok.Type != lexer.PAREN_CLOSE {
-			return nil, fmt.Errorf("expected ), got %s", p.curTok.Literal)
+		return &ast.BinaryExpression{Left: left, Operator: op, Right: right}, nil
+	}
+
+	// Check for [NOT] IN / BETWEEN / LIKE / ILIKE
+	not := false
+	if p.curTok.Type == lexer.NOT && isNegatableOperator(p.peekTok.Type) {
+		not = true
+		p.nextToken()
+	}
+	switch p.curTok.Type {
+	case lexer.IN:
+		return p.parseInList(left, not)
+	case lexer.BETWEEN:
+		return p.parseBetween(left, not)
+	case lexer.LIKE, lexer.ILIKE:
+		return p.parseLike(left, not)
+	}
+
+	// Check for NULL test
+	if p.curTok.Type == lexer.IS {
+		return p.parseIsNull(left)
+	}
+
+	return left, nil
+}
+
+// parseScalarExpression parses a value expression such as price * quantity or first || ' ' || last
+// Implements precedence: || (lowest) < + - < * / % < unary - + < atoms and parentheses
+func (p *Parser) parseScalarExpression() (ast.Expression, error) {
+	return p.parseConcatExpression()
+}
+
+// parseConcatExpression handles string concatenation (||)
+func (p *Parser) parseConcatExpression() (ast.Expression, error) {
+	left, err := p.parseAdditiveExpression()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.curTok.Type == lexer.CONCAT {
+		op := p.curTok.Literal
+		p.nextToken()
+		right, err := p.parseAdditiveExpression()
+		if err != nil {
+			return nil, err
 		}
+		left = &ast.BinaryExpression{Left: left, Operator: op, Right: right}
+	}
+
+	return left, nil
+}
+
+// parseAdditiveExpression handles + and - (left-associative)
+func (p *Parser) parseAdditiveExpression() (ast.Expression, error) {
+	left, err := p.parseMultiplicativeExpression()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.curTok.Type == lexer.PLUS || p.curTok.Type == lexer.MINUS {
+		op := p.curTok.Literal
 		p.nextToken()
-		return expr, nil
+		right, err := p.parseMultiplicativeExpression()
+		if err != nil {
+			return nil, err
+		}
+		left = &ast.BinaryExpression{Left: left, Operator: op, Right: right}
 	}
 
-	// Parse left side (identifier or literal)
-	left, err := p.parseAtom()
+	return left, nil
+}
+
+// parseMultiplicativeExpression handles *, / and % (left-associative)
+func (p *Parser) parseMultiplicativeExpression() (ast.Expression, error) {
+	left, err := p.parseUnaryExpression()
 	if err != nil {
 		return nil, err
 	}
 
-	// Check for comparison operator
-	if isComparisonOperator(p.curTok.Type) {
+	for isMultiplicativeOperator(p.curTok.Type) {
 		op := p.curTok.Literal
 		p.nextToken()
-		right, err := p.parseAtom()
+		right, err := p.parseUnaryExpression()
 		if err != nil {
 			return nil, err
 		}
-		return &ast.BinaryExpression{Left: left, Operator: op, Right: right}, nil
+		left = &ast.BinaryExpression{Left: left, Operator: op, Right: right}
 	}
 
 	return left, nil
 }
+
+// parseUnaryExpression handles prefix - and +
+// A sign applied to a number literal is folded into the literal (-5 is the literal -5).
+func (p *Parser) parseUnaryExpression() (ast.Expression, error) {
+	if p.curTok.Type != lexer.MINUS && p.curTok.Type != lexer.PLUS {
+		return p.parsePrimaryExpression()
+	}
+	op := p.curTok.Literal
+	p.nextToken()
+
+	operand, err := p.parseUnaryExpression()
+	if err != nil {
+		return nil, err
+	}
+
+	if lit, ok := operand.(*ast.Literal); ok && (lit.Kind == ast.LiteralInt || lit.Kind == ast.LiteralFloat) {
+		if op == "+" {
+			return lit, nil
+		}
+		return negateNumber(lit), nil
+	}
+	return &ast.UnaryExpression{Operator: op, Operand: operand}, nil
+}
+
+// parsePrimaryExpression parses an atom, a parenthesized expression or a scalar subquery
+// Parentheses may group arithmetic or whole conditions: (price + tax) * 2, (a = 1 OR b = 2)
+func (p *Parser) parsePrimaryExpression() (ast.Expression, error) {
+	if p.curTok.Type != lexer.PAREN_OPEN {
+		return p.parseAtom()
+	}
+	if p.peekTok.Type == lexer.SELECT {
+		query, err := p.parseSubquery()
+		if err != nil {
+			return nil, err
+		}
+		return &ast.SubqueryExpression{Query: query}, nil
+	}
+	p.nextToken()
+
+	expr, err := p.parseExpression() // Recursive: allows nested logical expressions
+	if err != nil {
+		return nil, err
+	}
+	if p.curTok.Type != lexer.PAREN_CLOSE {
+		return nil, fmt.Errorf("expected ), got %s", p.curTok.Literal)
+	}
+	p.nextToken()
+	return expr, nil
+}
+
+// negateNumber returns the negation of a numeric literal
+func negateNumber(lit *ast.Literal) *ast.Literal {
+	text := "-" + lit.TokenLiteralValue
+	if strings.HasPrefix(lit.TokenLiteralValue, "-") {
+		text = strings.TrimPrefix(lit.TokenLiteralValue, "-")
+	}
+
+	switch v := lit.Value.(type) {
+	case int:
+		return &ast.Literal{TokenLiteralValue: text, Value: -v, Kind: lit.Kind}
+	case float64:
+		return &ast.Literal{TokenLiteralValue: text, Value: -v, Kind: lit.Kind}
+	default:
+		return lit
+	}
+}
+
+// parseBetween parses a range test
+// Grammar: left [NOT] BETWEEN low AND high
+// The AND belongs to BETWEEN, so the bounds are scalar expressions rather than full conditions.
+func (p *Parser) parseBetween(left ast.Expression, not bool) (ast.Expression, error) {
+	p.nextToken() // BETWEEN
+
+	low, err := p.parseScalarExpression()
+	if err != nil {
+		return nil, fmt.Errorf("invalid BETWEEN lower bound: %w", err)
+	}
+
+	if p.curTok.Type != lexer.AND {
+		return nil, fmt.Errorf("expected AND in BETWEEN, got %s", p.curTok.Literal)
+	}
+	p.nextToken()
+
+	high, err := p.parseScalarExpression()
+	if err != nil {
+		return nil, fmt.Errorf("invalid BETWEEN upper bound: %w", err)
+	}
+
+	return &ast.BetweenExpression{Expr: left, Low: low, High: high, Not: not}, nil
+}
+
+// parseLike parses a pattern match
+// Grammar: left [NOT] LIKE|ILIKE pattern [ESCAPE char]
+func (p *Parser) parseLike(left ast.Expression, not bool) (ast.Expression, error) {
+	expr := &ast.LikeExpression{Expr: left, Not: not, CaseInsensitive: p.curTok.Type == lexer.ILIKE}
+	p.nextToken() // LIKE / ILIKE
+
+	pattern, err := p.parseScalarExpression()
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s pattern: %w", expr.TokenLiteral(), err)
+	}
+	expr.Pattern = pattern
+
+	if p.curTok.Type == lexer.ESCAPE {
+		p.nextToken()
+		escape, err := p.parseAtom()
+		if err != nil {
+			return nil, fmt.Errorf("invalid ESCAPE character: %w", err)
+		}
+		expr.Escape = escape
+	}
+
+	return expr, nil
+}
+
+// parseIsNull parses a NULL test
+// Grammar: left IS [NOT] NULL
+func (p *Parser) parseIsNull(left ast.Expression) (ast.Expression, error) {
+	p.nextToken() // IS
+
+	expr := &ast.IsNullExpression{Expr: left}
+	if p.curTok.Type == lexer.NOT {
+		expr.Not = true
+		p.nextToken()
+	}
+
+	if p.curTok.Type != lexer.NULL {
+		return nil, fmt.Errorf("expected NULL after IS, got %s", p.curTok.Literal)
+	}
+	p.nextToken()
+
+	return expr, nil
+}
+
+// parseInList parses the value list or subquery of an IN expression
+// Grammar: left [NOT] IN ( value [, value ...] ) | left [NOT] IN ( SELECT ... )
+func (p *Parser) parseInList(left ast.Expression, not bool) (ast.Expression, error) {
+	p.nextToken() // IN
+
+	if p.curTok.Type != lexer.PAREN_OPEN {
+		return nil, fmt.Errorf("expected ( after IN, got %s", p.curTok.Literal)
+	}
+	if p.peekTok.Type == lexer.SELECT {
+		query, err := p.parseSubquery()
+		if err != nil {
+			return nil, err
+		}
+		return &ast.InExpression{Left: left, Subquery: query, Not: not}, nil
+	}
+	p.nextToken()
+
+	expr := &ast.InExpression{Left: left, Not: not}
+	for {
+		value, err := p.parseScalarExpression()
+		if err != nil {
+			return nil, fmt.Errorf("invalid IN value: %w", err)
+		}
+		expr.Values = append(expr.Values, value)
+
+		if p.curTok.Type != lexer.COMMA {
+			break
+		}
+		p.nextToken()
+	}
+
+	if p.curTok.Type != lexer.PAREN_CLOSE {
+		return nil, fmt.Errorf("expected ) after IN list, got %s", p.curTok.Literal)
+	}
+	p.nextToken()
+
+	return expr, nil
+}
@@ -0,0 +1,88 @@
+package parser
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/leengari/mini-rdbms/internal/parser/ast"
+	"github.com/leengari/mini-rdbms/internal/parser/lexer"
+)
+
+// niladicFunctions are called by bare name, without parentheses (SQL standard)
+var niladicFunctions = map[string]bool{
+	"CURRENT_DATE": true,
+	"CURRENT_TIME": true,
+}
+
+// parseFunctionCall parses a function call
+// Grammar: name ( * | [DISTINCT] expression [, expression ...] | )
+// The current token is the function name and the next token is '('.
+func (p *Parser) parseFunctionCall() (*ast.FunctionCall, error) {
+	call := &ast.FunctionCall{Name: strings.ToUpper(p.curTok.Literal)}
+	p.nextToken() // function name
+	p.nextToken() // (
+
+	switch {
+	case p.curTok.Type == lexer.ASTERISK:
+		call.Star = true
+		p.nextToken()
+
+	case p.curTok.Type != lexer.PAREN_CLOSE:
+		if p.curTok.Type == lexer.DISTINCT {
+			call.Distinct = true
+			p.nextToken()
+		}
+
+		for {
+			arg, err := p.parseExpression()
+			if err != nil {
+				return nil, fmt.Errorf("invalid argument to %s: %w", call.Name, err)
+			}
+			call.Args = append(call.Args, arg)
+
+			if p.curTok.Type != lexer.COMMA {
+				break
+			}
+			p.nextToken()
+		}
+	}
+
+	if p.curTok.Type != lexer.PAREN_CLOSE {
+		return nil, fmt.Errorf("expected ) after %s arguments, got %s", call.Name, p.curTok.Literal)
+	}
+	p.nextToken()
+
+	return call, nil
+}
+
+// parseCast parses a type conversion
+// Grammar: CAST ( expression AS type )
+// The current token is CAST and the next token is '('.
+func (p *Parser) parseCast() (*ast.CastExpression, error) {
+	p.nextToken() // CAST
+	p.nextToken() // (
+
+	expr, err := p.parseScalarExpression()
+	if err != nil {
+		return nil, fmt.Errorf("invalid argument to CAST: %w", err)
+	}
+
+	if p.curTok.Type != lexer.AS {
+		return nil, fmt.Errorf("expected AS in CAST, got %s", p.curTok.Literal)
+	}
+	p.nextToken()
+
+	// DATE, TIME and EMAIL are lexed as keywords
+	if !isIdentifierOrKeyword(p.curTok.Type) {
+		return nil, fmt.Errorf("expected type name in CAST, got %s", p.curTok.Literal)
+	}
+	cast := &ast.CastExpression{Expr: expr, Type: strings.ToUpper(p.curTok.Literal)}
+	p.nextToken()
+
+	if p.curTok.Type != lexer.PAREN_CLOSE {
+		return nil, fmt.Errorf("expected ) after CAST type, got %s", p.curTok.Literal)
+	}
+	p.nextToken()
+
+	return cast, nil
+}
@@ -34,3 +34,13 @@ func isComparisonOperator(t lexer.TokenType) bool {
 func isLogicalOperator(t lexer.TokenType) bool {
 	return t == lexer.AND || t == lexer.OR
 }
+
+// isNegatableOperator checks if a token type is an operator that may follow NOT (IN, BETWEEN, LIKE, ILIKE)
+func isNegatableOperator(t lexer.TokenType) bool {
+	return t == lexer.IN || t == lexer.BETWEEN || t == lexer.LIKE || t == lexer.ILIKE
+}
+
+// isMultiplicativeOperator checks if a token type is *, / or %
+func isMultiplicativeOperator(t lexer.TokenType) bool {
+	return t == lexer.ASTERISK || t == lexer.SLASH || t == lexer.PERCENT
+}
@@ -51,6 +51,81 @@ const (
 	USE
 	RENAME
 	TO
+	TABLE
+	IF
+	EXISTS
+	ADD
+	COLUMN
+	INDEX
+
+	// Column Constraints
+	PRIMARY
+	KEY
+	UNIQUE
+	NOT
+	NULL
+	AUTO_INCREMENT
+	DEFAULT
+
+	// Transaction Control
+	BEGIN
+	COMMIT
+	ROLLBACK
+	TRANSACTION
+	CHECKPOINT
+
+	// Ordering & Paging
+	ORDER
+	BY
+	ASC
+	DESC
+	NULLS
+	FIRST
+	LAST
+	LIMIT
+	OFFSET
+
+	// Aggregation
+	GROUP
+	HAVING
+	DISTINCT
+
+	// Set Membership
+	IN
+
+	// Null Tests
+	IS
+
+	// Range & Pattern Matching
+	BETWEEN
+	LIKE
+	ILIKE
+	ESCAPE
+
+	// Aliases
+	AS
+
+	// Common Table Expressions & Set Operations
+	WITH
+	RECURSIVE
+	UNION
+	INTERSECT
+	EXCEPT
+	ALL
+
+	// Window Functions
+	OVER
+	PARTITION
+	ROWS
+	RANGE
+	UNBOUNDED
+	PRECEDING
+	FOLLOWING
+	CURRENT
+	ROW
+
+	// Data Modification
+	RETURNING
 
 	// Operators & Punctuation
 	ASTERISK    // *
@@ -65,6 +140,11 @@ const (
 	NOT_EQUAL    // != or <>
 	DOT          // .
 	SEMICOLON   // ;
+	PLUS        // +
+	MINUS       // -
+	SLASH       // /
+	PERCENT     // %
+	CONCAT      // ||
 )
 
 var keywords = map[string]TokenType{
@@ -98,6 +178,59 @@ var keywords = map[string]TokenType{
 	"USE":    USE,
 	"RENAME": RENAME,
 	"TO":     TO,
+	"TABLE":  TABLE,
+	"IF":     IF,
+	"EXISTS": EXISTS,
+	"ADD":    ADD,
+	"COLUMN": COLUMN,
+	"INDEX":  INDEX,
+	"PRIMARY": PRIMARY,
+	"KEY":    KEY,
+	"UNIQUE": UNIQUE,
+	"NOT":    NOT,
+	"NULL":   NULL,
+	"AUTO_INCREMENT": AUTO_INCREMENT,
+	"DEFAULT": DEFAULT,
+	"BEGIN":  BEGIN,
+	"COMMIT": COMMIT,
+	"ROLLBACK": ROLLBACK,
+	"TRANSACTION": TRANSACTION,
+	"CHECKPOINT": CHECKPOINT,
+	"ORDER":  ORDER,
+	"BY":     BY,
+	"ASC":    ASC,
+	"DESC":   DESC,
+	"NULLS":  NULLS,
+	"FIRST":  FIRST,
+	"LAST":   LAST,
+	"LIMIT":  LIMIT,
+	"OFFSET": OFFSET,
+	"GROUP":  GROUP,
+	"HAVING": HAVING,
+	"DISTINCT": DISTINCT,
+	"IN":     IN,
+	"IS":     IS,
+	"BETWEEN": BETWEEN,
+	"LIKE":   LIKE,
+	"ILIKE":  ILIKE,
+	"ESCAPE": ESCAPE,
+	"AS":     AS,
+	"WITH":   WITH,
+	"RECURSIVE": RECURSIVE,
+	"UNION":  UNION,
+	"INTERSECT": INTERSECT,
+	"EXCEPT": EXCEPT,
+	"ALL":    ALL,
+	"OVER":   OVER,
+	"PARTITION": PARTITION,
+	"ROWS":   ROWS,
+	"RANGE":  RANGE,
+	"UNBOUNDED": UNBOUNDED,
+	"PRECEDING": PRECEDING,
+	"FOLLOWING": FOLLOWING,
+	"CURRENT": CURRENT,
+	"ROW":    ROW,
+	"RETURNING": RETURNING,
 }
 
 type Token struct {
@@ -203,6 +336,25 @@ func (l *Lexer) NextToken() Token {
 		tok = newToken(DOT, l.ch, l.line, l.column)
 	case ';':
 		tok = newToken(SEMICOLON, l.ch, l.line, l.column)
+	case '+':
+		tok = newToken(PLUS, l.ch, l.line, l.column)
+	case '-':
+		tok = newToken(MINUS, l.ch, l.line, l.column)
+	case '/':
+		tok = newToken(SLASH, l.ch, l.line, l.column)
+	case '%':
+		tok = newToken(PERCENT, l.ch, l.line, l.column)
+	case '|':
+		// Check for ||
+		if l.peekChar() == '|' {
+			ch := l.ch
+			col := l.column
+			l.readChar()
+			tok = Token{Type: CONCAT, Literal: string(ch) + string(l.ch), Line: l.line, Column: col}
+		} else {
+			// | by itself is illegal in SQL
+			tok = newToken(ILLEGAL, l.ch, l.line, l.column)
+		}
 	case '\'':
 		tok.Type = STRING
 		tok.Literal = l.readString()
@@ -261,22 +413,31 @@ func (l *Lexer) readNumber() string {
 	return l.input[position:l.position]
 }
 
+// readString reads a single-quoted string literal
+// A doubled quote inside the literal ('it''s') stands for one quote character.
 func (l *Lexer) readString() string {
+	var lit strings.Builder
 	position := l.position + 1
 	for {
 		l.readChar()
+		if l.ch == '\'' && l.peekChar() == '\'' {
+			lit.WriteString(l.input[position:l.position+1])
+			l.readChar()
+			position = l.position + 1
+			continue
+		}
 		if l.ch == '\'' || l.ch == 0 {
 			break
 		}
 	}
-	lit := l.input[position:l.position]
+	lit.WriteString(l.input[position:l.position])
 	
 	// Consume the closing quote
 	if l.ch == '\'' {
 		l.readChar()
 	}
 	
-	return lit
+	return lit.String()
 }
 
 func newToken(tokenType TokenType, ch byte, line, col int) Token {
@@ -55,3 +55,66 @@ INSERT INTO items (name, price) VALUES ('apple', 1.23);`
 		}
 	}
 }
+
+func TestStringEscapedQuote(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`'it''s'`, "it's"},
+		{`''''`, "'"},
+		{`''`, ""},
+		{`'a''b''c'`, "a'b'c"},
+	}
+
+	for _, tt := range tests {
+		l := New(tt.input + " x")
+		tok := l.NextToken()
+		if tok.Type != STRING || tok.Literal != tt.expected {
+			t.Errorf("%s: expected STRING %q, got %v %q", tt.input, tt.expected, tok.Type, tok.Literal)
+		}
+		if next := l.NextToken(); next.Type != IDENTIFIER || next.Literal != "x" {
+			t.Errorf("%s: expected identifier after string, got %v %q", tt.input, next.Type, next.Literal)
+		}
+	}
+}
+
+func TestArithmeticOperators(t *testing.T) {
+	input := `SELECT -price * 2 + 1 / 3 % 4 - x || 'a' AS total`
+
+	expected := []struct {
+		expectedType    TokenType
+		expectedLiteral string
+	}{
+		{SELECT, "SELECT"},
+		{MINUS, "-"},
+		{IDENTIFIER, "price"},
+		{ASTERISK, "*"},
+		{NUMBER, "2"},
+		{PLUS, "+"},
+		{NUMBER, "1"},
+		{SLASH, "/"},
+		{NUMBER, "3"},
+		{PERCENT, "%"},
+		{NUMBER, "4"},
+		{MINUS, "-"},
+		{IDENTIFIER, "x"},
+		{CONCAT, "||"},
+		{STRING, "a"},
+		{AS, "AS"},
+		{IDENTIFIER, "total"},
+		{EOF, ""},
+	}
+
+	l := New(input)
+	for i, tt := range expected {
+		tok := l.NextToken()
+		if tok.Type != tt.expectedType || tok.Literal != tt.expectedLiteral {
+			t.Fatalf("tests[%d] - expected %v %q, got %v %q", i, tt.expectedType, tt.expectedLiteral, tok.Type, tok.Literal)
+		}
+	}
+
+	if _, err := Tokenize("a | b"); err == nil {
+		t.Error("Expected single | to be illegal")
+	}
+}
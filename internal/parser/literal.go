@@ -14,16 +14,42 @@ import (
 func (p *Parser) parseAtom() (ast.Expression, error) {
 	switch p.curTok.Type {
 	case lexer.IDENTIFIER:
+		// Function call (e.g. COUNT(*) in HAVING, UPPER(name)), window function or CAST(expr AS type)
+		if p.peekTok.Type == lexer.PAREN_OPEN {
+			if strings.EqualFold(p.curTok.Literal, "CAST") {
+				return p.parseCast()
+			}
+			call, err := p.parseFunctionCall()
+			if err != nil {
+				return nil, err
+			}
+			// Window function (e.g. ROW_NUMBER() OVER (ORDER BY id))
+			if p.curTok.Type == lexer.OVER {
+				return p.parseOver(call)
+			}
+			return call, nil
+		}
+
+		// Functions called without parentheses (CURRENT_DATE, CURRENT_TIME)
+		if name := strings.ToUpper(p.curTok.Literal); niladicFunctions[name] && p.peekTok.Type != lexer.DOT {
+			p.nextToken()
+			return &ast.FunctionCall{Name: name}, nil
+		}
+
 		val := p.curTok.Literal
 		p.nextToken()
 		
 		// Check for qualified identifier (table.column)
 		if p.curTok.Type == lexer.DOT {
 			p.nextToken()
-			if p.curTok.Type != lexer.IDENTIFIER {
+			if !isIdentifierOrKeyword(p.curTok.Type) {
 				return nil, fmt.Errorf("expected column name after '.', got %s", p.curTok.Literal)
 			}
 			colName := p.curTok.Literal
+			if p.curTok.Type != lexer.IDENTIFIER {
+				// EMAIL, DATE, TIME used as column names
+				colName = strings.ToLower(colName)
+			}
 			p.nextToken()
 			return &ast.Identifier{
 				TokenLiteralValue: val + "." + colName,
@@ -93,6 +119,9 @@ func (p *Parser) parseAtom() (ast.Expression, error) {
 			TokenLiteralValue: strings.ToLower(keyword),
 			Value:             strings.ToLower(keyword),
 		}, nil
+	case lexer.EXISTS:
+		// EXISTS (SELECT ...)
+		return p.parseExists()
 	case lexer.STRING:
 		val := p.curTok.Literal
 		p.nextToken()
@@ -115,6 +144,9 @@ func (p *Parser) parseAtom() (ast.Expression, error) {
 	case lexer.FALSE:
 		p.nextToken()
 		return &ast.Literal{TokenLiteralValue: "false", Value: false, Kind: ast.LiteralBool}, nil
+	case lexer.NULL:
+		p.nextToken()
+		return &ast.Literal{TokenLiteralValue: "NULL", Value: nil, Kind: ast.LiteralNull}, nil
 	default:
 		return nil, fmt.Errorf("unexpected token in expression: %s", p.curTok.Literal)
 	}
@@ -41,13 +41,15 @@
 	func (p *Parser) Parse() (ast.Statement, error) {
 		switch p.curTok.Type {
 		case lexer.SELECT:
-			return p.parseSelect()
+			return p.expectEnd(p.parseQuery())
+		case lexer.WITH:
+			return p.expectEnd(p.parseWith())
 		case lexer.INSERT:
-			return p.parseInsert()
+			return p.expectEnd(p.parseInsert())
 		case lexer.UPDATE:
-			return p.parseUpdate()
+			return p.expectEnd(p.parseUpdate())
 		case lexer.DELETE:
-			return p.parseDelete()
+			return p.expectEnd(p.parseDelete())
 		case lexer.CREATE:
 			return p.parseCreate()
 		case lexer.DROP:
@@ -56,11 +58,24 @@
 			return p.parseAlter()
 		case lexer.USE:
 			return p.parseUse()
+		case lexer.BEGIN, lexer.COMMIT, lexer.ROLLBACK:
+			return p.parseTransactionControl()
+		case lexer.CHECKPOINT:
+			return p.parseCheckpoint()
 		default:
-			return nil, fmt.Errorf("unexpected token %v, expected a valid SQL statement (SELECT, INSERT, UPDATE, DELETE, CREATE, DROP, ALTER, USE)", p.curTok.Type)
+			return nil, fmt.Errorf("unexpected token %v, expected a valid SQL statement (SELECT, WITH, INSERT, UPDATE, DELETE, CREATE, DROP, ALTER, USE, BEGIN, COMMIT, ROLLBACK, CHECKPOINT)", p.curTok.Type)
 		}
 	}
 
+	// expectEnd checks that a parsed statement used every token, so unsupported trailing
+	// syntax (such as a table alias) is an error instead of being silently dropped
+	func (p *Parser) expectEnd(stmt ast.Statement, err error) (ast.Statement, error) {
+		if err == nil && p.curTok.Type != lexer.EOF {
+			return nil, fmt.Errorf("unexpected %s after end of statement", p.curTok.Literal)
+		}
+		return stmt, err
+	}
+
 	// expectPeek checks if the next token is of 	the expected type
 	// If it is, it advances the parser and returns true
 	// If not, it returns false (without advancing)
@@ -0,0 +1,204 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/leengari/mini-rdbms/internal/parser/ast"
+	"github.com/leengari/mini-rdbms/internal/parser/lexer"
+)
+
+// parseStatement tokenizes and parses a single SQL statement
+func parseStatement(t *testing.T, input string) ast.Statement {
+	t.Helper()
+
+	tokens, err := lexer.Tokenize(input)
+	if err != nil {
+		t.Fatalf("Lexer error: %v", err)
+	}
+
+	stmt, err := New(tokens).Parse()
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	return stmt
+}
+
+func TestParseCreateTable(t *testing.T) {
+	input := "CREATE TABLE products (id INT PRIMARY KEY AUTO_INCREMENT, name TEXT NOT NULL UNIQUE, price FLOAT, date DATE);"
+	stmt := parseStatement(t, input)
+
+	create, ok := stmt.(*ast.CreateTableStatement)
+	if !ok {
+		t.Fatalf("Expected CreateTableStatement, got %T", stmt)
+	}
+
+	if create.TableName.Value != "products" {
+		t.Errorf("Expected table products, got %s", create.TableName.Value)
+	}
+
+	expected := []ast.ColumnDefinition{
+		{Name: "id", Type: "INT", PrimaryKey: true, AutoIncrement: true},
+		{Name: "name", Type: "TEXT", NotNull: true, Unique: true},
+		{Name: "price", Type: "FLOAT"},
+		{Name: "date", Type: "DATE"},
+	}
+
+	if len(create.Columns) != len(expected) {
+		t.Fatalf("Expected %d columns, got %d", len(expected), len(create.Columns))
+	}
+
+	for i, want := range expected {
+		if got := *create.Columns[i]; got != want {
+			t.Errorf("Column %d: expected %+v, got %+v", i, want, got)
+		}
+	}
+}
+
+func TestParseTableDDLErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{name: "missing column list", input: "CREATE TABLE t"},
+		{name: "missing type", input: "CREATE TABLE t (id)"},
+		{name: "PRIMARY without KEY", input: "CREATE TABLE t (id INT PRIMARY)"},
+		{name: "NOT without NULL", input: "CREATE TABLE t (id INT NOT)"},
+		{name: "unclosed column list", input: "CREATE TABLE t (id INT, name TEXT"},
+		{name: "non-literal DEFAULT", input: "CREATE TABLE t (id INT DEFAULT other)"},
+		{name: "ALTER without action", input: "ALTER TABLE t"},
+		{name: "RENAME COLUMN without TO", input: "ALTER TABLE t RENAME COLUMN a b"},
+		{name: "index without ON", input: "CREATE INDEX i t (a)"},
+		{name: "index without columns", input: "CREATE INDEX i ON t ()"},
+		{name: "UNIQUE without INDEX", input: "CREATE UNIQUE i ON t (a)"},
+		{name: "DROP INDEX without name", input: "DROP INDEX"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tokens, err := lexer.Tokenize(tt.input)
+			if err != nil {
+				t.Fatalf("Lexer error: %v", err)
+			}
+
+			if _, err := New(tokens).Parse(); err == nil {
+				t.Errorf("Expected parse error for %q", tt.input)
+			}
+		})
+	}
+}
+
+func TestParseDropTable(t *testing.T) {
+	tests := []struct {
+		input         string
+		expectedTable string
+		ifExists      bool
+	}{
+		{input: "DROP TABLE users;", expectedTable: "users", ifExists: false},
+		{input: "DROP TABLE IF EXISTS temp_data", expectedTable: "temp_data", ifExists: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			stmt := parseStatement(t, tt.input)
+
+			drop, ok := stmt.(*ast.DropTableStatement)
+			if !ok {
+				t.Fatalf("Expected DropTableStatement, got %T", stmt)
+			}
+
+			if drop.TableName.Value != tt.expectedTable {
+				t.Errorf("Expected table %s, got %s", tt.expectedTable, drop.TableName.Value)
+			}
+			if drop.IfExists != tt.ifExists {
+				t.Errorf("Expected IfExists=%v, got %v", tt.ifExists, drop.IfExists)
+			}
+		})
+	}
+}
+
+func TestParseIndexDDL(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{input: "CREATE INDEX by_price ON products (price);", expected: "CREATE INDEX by_price ON products (price)"},
+		{input: "create unique index by_city_name on people (city, name)", expected: "CREATE UNIQUE INDEX by_city_name ON people (city, name)"},
+		{input: "CREATE INDEX by_day ON events (Date, time, email)", expected: "CREATE INDEX by_day ON events (date, time, email)"},
+		{input: "DROP INDEX by_price", expected: "DROP INDEX by_price"},
+		{input: "DROP INDEX IF EXISTS by_price;", expected: "DROP INDEX IF EXISTS by_price"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			stmt := parseStatement(t, tt.input)
+			if got := stmt.String(); got != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+
+	create := parseStatement(t, "CREATE UNIQUE INDEX by_city_name ON people (city, name)").(*ast.CreateIndexStatement)
+	if !create.Unique || create.TableName.Value != "people" || len(create.Columns) != 2 {
+		t.Errorf("Unexpected statement %+v", create)
+	}
+}
+
+func TestParseAlterTable(t *testing.T) {
+	tests := []struct {
+		input      string
+		action     ast.AlterTableAction
+		columnName string
+		newName    string
+	}{
+		{input: "ALTER TABLE users ADD COLUMN age INT NOT NULL DEFAULT 0;", action: ast.AlterAddColumn},
+		{input: "ALTER TABLE users ADD age INT", action: ast.AlterAddColumn},
+		{input: "ALTER TABLE users DROP COLUMN age;", action: ast.AlterDropColumn, columnName: "age"},
+		{input: "ALTER TABLE users DROP email", action: ast.AlterDropColumn, columnName: "email"},
+		{input: "ALTER TABLE users RENAME COLUMN name TO full_name;", action: ast.AlterRenameColumn, columnName: "name", newName: "full_name"},
+		{input: "ALTER TABLE users RENAME TO customers;", action: ast.AlterRenameTable, newName: "customers"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			stmt := parseStatement(t, tt.input)
+
+			alter, ok := stmt.(*ast.AlterTableStatement)
+			if !ok {
+				t.Fatalf("Expected AlterTableStatement, got %T", stmt)
+			}
+
+			if alter.TableName.Value != "users" {
+				t.Errorf("Expected table users, got %s", alter.TableName.Value)
+			}
+			if alter.Action != tt.action {
+				t.Errorf("Expected action %s, got %s", tt.action, alter.Action)
+			}
+			if alter.ColumnName != tt.columnName {
+				t.Errorf("Expected column %q, got %q", tt.columnName, alter.ColumnName)
+			}
+			if alter.NewName != tt.newName {
+				t.Errorf("Expected new name %q, got %q", tt.newName, alter.NewName)
+			}
+			if tt.action == ast.AlterAddColumn && (alter.Column == nil || alter.Column.Name != "age" || alter.Column.Type != "INT") {
+				t.Errorf("Expected column definition age INT, got %+v", alter.Column)
+			}
+		})
+	}
+}
+
+func TestParseColumnDefault(t *testing.T) {
+	stmt := parseStatement(t, "ALTER TABLE users ADD COLUMN active BOOL NOT NULL DEFAULT true")
+
+	col := stmt.(*ast.AlterTableStatement).Column
+	if !col.NotNull {
+		t.Error("Expected NOT NULL constraint")
+	}
+
+	lit, ok := col.Default.(*ast.Literal)
+	if !ok {
+		t.Fatalf("Expected literal DEFAULT, got %T", col.Default)
+	}
+	if lit.Value != true {
+		t.Errorf("Expected DEFAULT true, got %v", lit.Value)
+	}
+}
@@ -219,13 +219,13 @@ func TestParseTypedLiterals(t *testing.T) {
 					t.Fatalf("Expected Literal on right side, got %T", binExpr.Right)
 				}
 			case *ast.InsertStatement:
-				if len(s.Values) < 2 {
+				if len(s.Rows[0]) < 2 {
 					t.Fatal("Expected at least 2 values")
 				}
 				var ok bool
-				lit, ok = s.Values[1].(*ast.Literal)
+				lit, ok = s.Rows[0][1].(*ast.Literal)
 				if !ok {
-					t.Fatalf("Expected Literal, got %T", s.Values[1])
+					t.Fatalf("Expected Literal, got %T", s.Rows[0][1])
 				}
 			default:
 				t.Fatalf("Unexpected statement type: %T", stmt)
@@ -241,3 +241,474 @@ func TestParseTypedLiterals(t *testing.T) {
 		})
 	}
 }
+
+// TestParseNullExpressions tests parsing of NULL literals, IS [NOT] NULL and NOT
+func TestParseNullExpressions(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string // String() of the WHERE clause
+	}{
+		{name: "IS NULL", input: "SELECT * FROM users WHERE email IS NULL;", expected: "(email IS NULL)"},
+		{name: "IS NOT NULL", input: "SELECT * FROM users WHERE users.age IS NOT NULL;", expected: "(users.age IS NOT NULL)"},
+		{name: "Compare with NULL", input: "SELECT * FROM users WHERE email = NULL;", expected: "(email = NULL)"},
+		{name: "NOT binds tighter than AND", input: "SELECT * FROM users WHERE NOT active = true AND age > 18;", expected: "((NOT (active = true)) AND (age > 18))"},
+		{name: "NOT with parentheses", input: "SELECT * FROM users WHERE NOT (age > 18 OR email IS NULL);", expected: "(NOT ((age > 18) OR (email IS NULL)))"},
+		{name: "Double NOT", input: "SELECT * FROM users WHERE NOT NOT active = true;", expected: "(NOT (NOT (active = true)))"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tokens, err := lexer.Tokenize(tt.input)
+			if err != nil {
+				t.Fatalf("Lexer error: %v", err)
+			}
+
+			stmt, err := New(tokens).Parse()
+			if err != nil {
+				t.Fatalf("Parser error: %v", err)
+			}
+
+			sel, ok := stmt.(*ast.SelectStatement)
+			if !ok {
+				t.Fatalf("Expected SelectStatement, got %T", stmt)
+			}
+			if sel.Where == nil {
+				t.Fatal("Expected WHERE clause, got nil")
+			}
+			if got := sel.Where.String(); got != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, got)
+			}
+		})
+	}
+
+	t.Run("NULL literal in INSERT and UPDATE", func(t *testing.T) {
+		tokens, _ := lexer.Tokenize("INSERT INTO users (name, email) VALUES ('ann', NULL);")
+		stmt, err := New(tokens).Parse()
+		if err != nil {
+			t.Fatalf("Parser error: %v", err)
+		}
+		ins := stmt.(*ast.InsertStatement)
+		lit, ok := ins.Rows[0][1].(*ast.Literal)
+		if !ok || lit.Kind != ast.LiteralNull || lit.Value != nil {
+			t.Errorf("Expected NULL literal, got %#v", ins.Rows[0][1])
+		}
+
+		tokens, _ = lexer.Tokenize("UPDATE users SET email = NULL WHERE id = 1;")
+		stmt, err = New(tokens).Parse()
+		if err != nil {
+			t.Fatalf("Parser error: %v", err)
+		}
+		upd := stmt.(*ast.UpdateStatement)
+		lit, ok = upd.Updates["email"].(*ast.Literal)
+		if !ok || lit.Kind != ast.LiteralNull {
+			t.Errorf("Expected NULL literal, got %#v", upd.Updates["email"])
+		}
+	})
+
+	t.Run("IS without NULL", func(t *testing.T) {
+		tokens, _ := lexer.Tokenize("SELECT * FROM users WHERE email IS 5;")
+		if _, err := New(tokens).Parse(); err == nil {
+			t.Error("Expected error for IS without NULL")
+		}
+	})
+}
+
+// TestParsePredicateOperators tests parsing of [NOT] IN, BETWEEN and LIKE/ILIKE
+func TestParsePredicateOperators(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string // String() of the WHERE clause
+	}{
+		{name: "NOT IN", input: "SELECT * FROM users WHERE id NOT IN (1, 2);", expected: "(id NOT IN (1, 2))"},
+		{name: "BETWEEN", input: "SELECT * FROM users WHERE age BETWEEN 18 AND 65;", expected: "(age BETWEEN 18 AND 65)"},
+		{name: "BETWEEN inside AND", input: "SELECT * FROM users WHERE age BETWEEN 18 AND 65 AND active = true;", expected: "((age BETWEEN 18 AND 65) AND (active = true))"},
+		{name: "NOT BETWEEN", input: "SELECT * FROM users WHERE age NOT BETWEEN 18 AND 65;", expected: "(age NOT BETWEEN 18 AND 65)"},
+		{name: "LIKE", input: "SELECT * FROM users WHERE name LIKE 'jo%';", expected: "(name LIKE jo%)"},
+		{name: "NOT ILIKE", input: "SELECT * FROM users WHERE name NOT ILIKE '%BOB%';", expected: "(name NOT ILIKE %BOB%)"},
+		{name: "LIKE with ESCAPE", input: "SELECT * FROM users WHERE code LIKE 'a!_%' ESCAPE '!';", expected: "(code LIKE a!_% ESCAPE !)"},
+		{name: "Prefix NOT with LIKE", input: "SELECT * FROM users WHERE NOT name LIKE 'a%' OR age > 3;", expected: "((NOT (name LIKE a%)) OR (age > 3))"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tokens, err := lexer.Tokenize(tt.input)
+			if err != nil {
+				t.Fatalf("Lexer error: %v", err)
+			}
+
+			stmt, err := New(tokens).Parse()
+			if err != nil {
+				t.Fatalf("Parser error: %v", err)
+			}
+
+			sel, ok := stmt.(*ast.SelectStatement)
+			if !ok {
+				t.Fatalf("Expected SelectStatement, got %T", stmt)
+			}
+			if got := sel.Where.String(); got != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, got)
+			}
+		})
+	}
+
+	errorInputs := []string{
+		"SELECT * FROM users WHERE age BETWEEN 18;",
+		"SELECT * FROM users WHERE age BETWEEN 18 OR 65;",
+		"SELECT * FROM users WHERE name LIKE;",
+		"SELECT * FROM users WHERE name LIKE 'a' ESCAPE;",
+	}
+	for _, input := range errorInputs {
+		tokens, err := lexer.Tokenize(input)
+		if err != nil {
+			t.Fatalf("Lexer error: %v", err)
+		}
+		if _, err := New(tokens).Parse(); err == nil {
+			t.Errorf("Expected parse error for %q", input)
+		}
+	}
+}
+
+func TestParseArithmeticExpressions(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string // String() of the WHERE clause
+	}{
+		{name: "Multiplication before addition", input: "SELECT * FROM t WHERE a + b * 2 > 10;", expected: "((a + (b * 2)) > 10)"},
+		{name: "Left associative", input: "SELECT * FROM t WHERE a - b - c = 0;", expected: "(((a - b) - c) = 0)"},
+		{name: "Parentheses", input: "SELECT * FROM t WHERE (a + b) * 2 > 10;", expected: "(((a + b) * 2) > 10)"},
+		{name: "Concatenation binds loosest", input: "SELECT * FROM t WHERE fname || ' ' || lname = 'a b';", expected: "(((fname ||  ) || lname) = a b)"},
+		{name: "Column to column", input: "SELECT * FROM t WHERE t.a < t.b % 3;", expected: "(t.a < (t.b % 3))"},
+		{name: "Negative literal", input: "SELECT * FROM t WHERE a > -5;", expected: "(a > -5)"},
+		{name: "Unary minus on column", input: "SELECT * FROM t WHERE -a / 2 < 1;", expected: "(((-a) / 2) < 1)"},
+		{name: "Arithmetic in BETWEEN", input: "SELECT * FROM t WHERE a BETWEEN b - 1 AND b + 1;", expected: "(a BETWEEN (b - 1) AND (b + 1))"},
+		{name: "Parenthesized condition", input: "SELECT * FROM t WHERE (a = 1 OR b = 2) AND c = 3;", expected: "(((a = 1) OR (b = 2)) AND (c = 3))"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tokens, err := lexer.Tokenize(tt.input)
+			if err != nil {
+				t.Fatalf("Lexer error: %v", err)
+			}
+
+			stmt, err := New(tokens).Parse()
+			if err != nil {
+				t.Fatalf("Parser error: %v", err)
+			}
+
+			sel, ok := stmt.(*ast.SelectStatement)
+			if !ok {
+				t.Fatalf("Expected SelectStatement, got %T", stmt)
+			}
+			if got := sel.Where.String(); got != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, got)
+			}
+		})
+	}
+
+	t.Run("Negative literal is folded", func(t *testing.T) {
+		tokens, _ := lexer.Tokenize("SELECT * FROM t WHERE a = -2.5;")
+		stmt, err := New(tokens).Parse()
+		if err != nil {
+			t.Fatalf("Parser error: %v", err)
+		}
+		lit, ok := stmt.(*ast.SelectStatement).Where.(*ast.BinaryExpression).Right.(*ast.Literal)
+		if !ok || lit.Value != -2.5 || lit.Kind != ast.LiteralFloat {
+			t.Errorf("Expected FLOAT literal -2.5, got %#v", stmt.(*ast.SelectStatement).Where.(*ast.BinaryExpression).Right)
+		}
+	})
+
+	errorInputs := []string{
+		"SELECT * FROM t WHERE a + > 1;",
+		"SELECT * FROM t WHERE (a + 1 > 1;",
+		"SELECT * FROM t WHERE a * = 2;",
+	}
+	for _, input := range errorInputs {
+		tokens, err := lexer.Tokenize(input)
+		if err != nil {
+			t.Fatalf("Lexer error: %v", err)
+		}
+		if _, err := New(tokens).Parse(); err == nil {
+			t.Errorf("Expected parse error for %q", input)
+		}
+	}
+}
+
+func TestParseScalarFunctions(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string // String() of the WHERE clause
+	}{
+		{name: "Function call", input: "SELECT * FROM t WHERE UPPER(name) = 'ANN';", expected: "(UPPER(name) = ANN)"},
+		{name: "Lower-case name", input: "SELECT * FROM t WHERE length(name) > 3;", expected: "(LENGTH(name) > 3)"},
+		{name: "Several arguments", input: "SELECT * FROM t WHERE SUBSTR(name, 1, 2) = 'an';", expected: "(SUBSTR(name, 1, 2) = an)"},
+		{name: "No arguments", input: "SELECT * FROM t WHERE d < NOW();", expected: "(d < NOW())"},
+		{name: "Without parentheses", input: "SELECT * FROM t WHERE d = CURRENT_DATE;", expected: "(d = CURRENT_DATE())"},
+		{name: "Nested in arithmetic", input: "SELECT * FROM t WHERE ABS(a - b) * 2 > 1;", expected: "((ABS((a - b)) * 2) > 1)"},
+		{name: "CAST", input: "SELECT * FROM t WHERE CAST(price AS INT) = 3;", expected: "(CAST(price AS INT) = 3)"},
+		{name: "CAST to keyword type", input: "SELECT * FROM t WHERE CAST(s AS date) > DATE '2024-01-01';", expected: "(CAST(s AS DATE) > DATE '2024-01-01')"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tokens, err := lexer.Tokenize(tt.input)
+			if err != nil {
+				t.Fatalf("Lexer error: %v", err)
+			}
+
+			stmt, err := New(tokens).Parse()
+			if err != nil {
+				t.Fatalf("Parser error: %v", err)
+			}
+
+			if got := stmt.(*ast.SelectStatement).Where.String(); got != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, got)
+			}
+		})
+	}
+
+	errorInputs := []string{
+		"SELECT * FROM t WHERE CAST(a) = 1;",
+		"SELECT * FROM t WHERE CAST(a AS) = 1;",
+		"SELECT * FROM t WHERE CAST(a AS INT = 1;",
+		"SELECT * FROM t WHERE UPPER(a = 'x';",
+	}
+	for _, input := range errorInputs {
+		tokens, err := lexer.Tokenize(input)
+		if err != nil {
+			t.Fatalf("Lexer error: %v", err)
+		}
+		if _, err := New(tokens).Parse(); err == nil {
+			t.Errorf("Expected parse error for %q", input)
+		}
+	}
+}
+
+// TestParseSubqueries tests subqueries in WHERE, the SELECT list, FROM and JOIN
+func TestParseSubqueries(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string // String() of the statement
+	}{
+		{name: "IN subquery", input: "SELECT * FROM users WHERE id IN (SELECT user_id FROM orders);", expected: "SELECT * FROM users WHERE (id IN (SELECT user_id FROM orders))"},
+		{name: "NOT IN subquery", input: "SELECT * FROM users WHERE id NOT IN (SELECT user_id FROM orders WHERE amount > 10);", expected: "SELECT * FROM users WHERE (id NOT IN (SELECT user_id FROM orders WHERE (amount > 10)))"},
+		{name: "EXISTS", input: "SELECT * FROM users WHERE EXISTS (SELECT * FROM orders WHERE orders.user_id = users.id);", expected: "SELECT * FROM users WHERE EXISTS (SELECT * FROM orders WHERE (orders.user_id = users.id))"},
+		{name: "NOT EXISTS", input: "SELECT * FROM users WHERE NOT EXISTS (SELECT id FROM orders);", expected: "SELECT * FROM users WHERE (NOT EXISTS (SELECT id FROM orders))"},
+		{name: "Scalar in comparison", input: "SELECT * FROM orders WHERE amount > (SELECT AVG(amount) FROM orders);", expected: "SELECT * FROM orders WHERE (amount > (SELECT AVG(amount) FROM orders))"},
+		{name: "Scalar in SELECT list", input: "SELECT name, (SELECT COUNT(*) FROM orders) AS n FROM users;", expected: "SELECT name, (SELECT COUNT(*) FROM orders) AS n FROM users"},
+		{name: "Derived table", input: "SELECT * FROM (SELECT id FROM users) AS u;", expected: "SELECT * FROM (SELECT id FROM users) AS u"},
+		{name: "Derived table without AS", input: "SELECT * FROM (SELECT id FROM users) u;", expected: "SELECT * FROM (SELECT id FROM users) AS u"},
+		{name: "Derived table in JOIN", input: "SELECT * FROM users JOIN (SELECT user_id FROM orders) AS o ON users.id = o.user_id;", expected: "SELECT * FROM users INNER JOIN (SELECT user_id FROM orders) AS o ON (users.id = o.user_id)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tokens, err := lexer.Tokenize(tt.input)
+			if err != nil {
+				t.Fatalf("Lexer error: %v", err)
+			}
+
+			stmt, err := New(tokens).Parse()
+			if err != nil {
+				t.Fatalf("Parser error: %v", err)
+			}
+
+			if got := stmt.String(); got != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, got)
+			}
+		})
+	}
+
+	errorInputs := []string{
+		"SELECT * FROM (SELECT id FROM users);",
+		"SELECT * FROM users WHERE id IN (SELECT id FROM users;",
+		"SELECT * FROM users WHERE EXISTS (1);",
+		"SELECT * FROM users WHERE EXISTS SELECT id FROM users;",
+	}
+	for _, input := range errorInputs {
+		tokens, err := lexer.Tokenize(input)
+		if err != nil {
+			t.Fatalf("Lexer error: %v", err)
+		}
+		if _, err := New(tokens).Parse(); err == nil {
+			t.Errorf("Expected parse error for %q", input)
+		}
+	}
+}
+
+func TestParseCommonTableExpressions(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string // String() of the statement
+	}{
+		{name: "Simple CTE", input: "WITH big AS (SELECT id FROM orders WHERE amount > 10) SELECT * FROM big;", expected: "WITH big AS (SELECT id FROM orders WHERE (amount > 10)) SELECT * FROM big"},
+		{name: "Column names", input: "WITH t (A, b) AS (SELECT id, name FROM users) SELECT a FROM t;", expected: "WITH t (a, b) AS (SELECT id, name FROM users) SELECT a FROM t"},
+		{name: "Several CTEs", input: "WITH a AS (SELECT id FROM users), b AS (SELECT id FROM a) SELECT * FROM b;", expected: "WITH a AS (SELECT id FROM users), b AS (SELECT id FROM a) SELECT * FROM b"},
+		{name: "Recursive", input: "WITH RECURSIVE n (x) AS (SELECT 1 FROM one UNION ALL SELECT x + 1 FROM n WHERE x < 5) SELECT x FROM n;", expected: "WITH RECURSIVE n (x) AS (SELECT 1 FROM one UNION ALL SELECT (x + 1) FROM n WHERE (x < 5)) SELECT x FROM n"},
+		{name: "UNION", input: "WITH RECURSIVE r AS (SELECT id FROM a UNION SELECT id FROM r) SELECT id FROM r;", expected: "WITH RECURSIVE r AS (SELECT id FROM a UNION SELECT id FROM r) SELECT id FROM r"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tokens, err := lexer.Tokenize(tt.input)
+			if err != nil {
+				t.Fatalf("Lexer error: %v", err)
+			}
+
+			stmt, err := New(tokens).Parse()
+			if err != nil {
+				t.Fatalf("Parser error: %v", err)
+			}
+
+			if got := stmt.String(); got != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, got)
+			}
+		})
+	}
+
+	errorInputs := []string{
+		"WITH AS (SELECT id FROM users) SELECT * FROM t;",
+		"WITH t (SELECT id FROM users) SELECT * FROM t;",
+		"WITH t AS (SELECT id FROM users SELECT * FROM t;",
+		"WITH t AS (SELECT id FROM users);",
+		"WITH t AS (SELECT id FROM users UNION) SELECT * FROM t;",
+		"WITH t () AS (SELECT id FROM users) SELECT * FROM t;",
+	}
+	for _, input := range errorInputs {
+		tokens, err := lexer.Tokenize(input)
+		if err != nil {
+			t.Fatalf("Lexer error: %v", err)
+		}
+		if _, err := New(tokens).Parse(); err == nil {
+			t.Errorf("Expected parse error for %q", input)
+		}
+	}
+}
+
+func TestParseSetOperations(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string // String() of the statement
+	}{
+		{name: "UNION", input: "SELECT id FROM a UNION SELECT id FROM b;", expected: "SELECT id FROM a UNION SELECT id FROM b"},
+		{name: "UNION ALL", input: "SELECT id FROM a UNION ALL SELECT id FROM b;", expected: "SELECT id FROM a UNION ALL SELECT id FROM b"},
+		{name: "ORDER BY and LIMIT apply to the result", input: "SELECT id FROM a EXCEPT SELECT id FROM b ORDER BY id DESC LIMIT 2;", expected: "SELECT id FROM a EXCEPT SELECT id FROM b ORDER BY id DESC LIMIT 2"},
+		{name: "With CTE", input: "WITH c AS (SELECT id FROM a) SELECT id FROM c INTERSECT ALL SELECT id FROM b;", expected: "WITH c AS (SELECT id FROM a) SELECT id FROM c INTERSECT ALL SELECT id FROM b"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tokens, err := lexer.Tokenize(tt.input)
+			if err != nil {
+				t.Fatalf("Lexer error: %v", err)
+			}
+
+			stmt, err := New(tokens).Parse()
+			if err != nil {
+				t.Fatalf("Parser error: %v", err)
+			}
+
+			if got := stmt.String(); got != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, got)
+			}
+		})
+	}
+
+	// INTERSECT binds tighter than UNION and EXCEPT, which associate to the left
+	tokens, err := lexer.Tokenize("SELECT id FROM a UNION SELECT id FROM b INTERSECT SELECT id FROM c EXCEPT SELECT id FROM d ORDER BY id")
+	if err != nil {
+		t.Fatalf("Lexer error: %v", err)
+	}
+	stmt, err := New(tokens).Parse()
+	if err != nil {
+		t.Fatalf("Parser error: %v", err)
+	}
+	except, ok := stmt.(*ast.SetOperationStatement)
+	if !ok || except.Operator != "EXCEPT" || len(except.OrderBy) != 1 {
+		t.Fatalf("Expected EXCEPT with ORDER BY at the top, got %s", stmt.String())
+	}
+	union, ok := except.Left.(*ast.SetOperationStatement)
+	if !ok || union.Operator != "UNION" {
+		t.Fatalf("Expected UNION on the left of EXCEPT, got %s", except.Left.String())
+	}
+	if intersect, ok := union.Right.(*ast.SetOperationStatement); !ok || intersect.Operator != "INTERSECT" {
+		t.Errorf("Expected INTERSECT on the right of UNION, got %s", union.Right.String())
+	}
+	if last := except.Right.(*ast.SelectStatement); len(last.OrderBy) != 0 {
+		t.Errorf("Expected ORDER BY to move off the last SELECT")
+	}
+
+	errorInputs := []string{
+		"SELECT id FROM a ORDER BY id UNION SELECT id FROM b;",
+		"SELECT id FROM a LIMIT 1 INTERSECT SELECT id FROM b;",
+		"SELECT id FROM a UNION;",
+		"SELECT id FROM a UNION ALL id FROM b;",
+		"SELECT id FROM a EXCEPT (SELECT id FROM b);",
+	}
+	for _, input := range errorInputs {
+		tokens, err := lexer.Tokenize(input)
+		if err != nil {
+			t.Fatalf("Lexer error: %v", err)
+		}
+		if _, err := New(tokens).Parse(); err == nil {
+			t.Errorf("Expected parse error for %q", input)
+		}
+	}
+}
+
+// TestParseWindowFunctions verifies OVER clauses with partitions, ordering and frames
+func TestParseWindowFunctions(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string // String() of the first SELECT field
+	}{
+		{name: "Empty window", input: "SELECT COUNT(*) OVER () FROM t;", expected: "COUNT(*) OVER ()"},
+		{name: "Partition and order", input: "SELECT ROW_NUMBER() OVER (PARTITION BY User_Id ORDER BY created_at DESC) FROM t;", expected: "ROW_NUMBER() OVER (PARTITION BY user_id ORDER BY created_at DESC)"},
+		{name: "Function arguments", input: "SELECT LAG(amount, 2, 0) OVER (ORDER BY id) AS prev FROM t;", expected: "LAG(amount, 2, 0) OVER (ORDER BY id ASC) AS prev"},
+		{name: "ROWS frame", input: "SELECT SUM(amount) OVER (ORDER BY id ROWS BETWEEN 2 PRECEDING AND 1 FOLLOWING) FROM t;", expected: "SUM(amount) OVER (ORDER BY id ASC ROWS BETWEEN 2 PRECEDING AND 1 FOLLOWING)"},
+		{name: "Single bound ends at the current row", input: "SELECT SUM(amount) OVER (ORDER BY id RANGE UNBOUNDED PRECEDING) FROM t;", expected: "SUM(amount) OVER (ORDER BY id ASC RANGE BETWEEN UNBOUNDED PRECEDING AND CURRENT ROW)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tokens, err := lexer.Tokenize(tt.input)
+			if err != nil {
+				t.Fatalf("Lexer error: %v", err)
+			}
+
+			stmt, err := New(tokens).Parse()
+			if err != nil {
+				t.Fatalf("Parser error: %v", err)
+			}
+
+			sel := stmt.(*ast.SelectStatement)
+			if got := sel.Fields[0].String(); got != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, got)
+			}
+		})
+	}
+
+	for _, input := range []string{
+		"SELECT ROW_NUMBER() OVER FROM t;",
+		"SELECT ROW_NUMBER() OVER (PARTITION id) FROM t;",
+		"SELECT SUM(amount) OVER (ROWS BETWEEN 1 FOLLOWING AND CURRENT ROW) FROM t;",
+		"SELECT SUM(amount) OVER (ROWS UNBOUNDED FOLLOWING) FROM t;",
+		"SELECT SUM(amount) OVER (ROWS BETWEEN CURRENT AND 1 FOLLOWING) FROM t;",
+	} {
+		tokens, err := lexer.Tokenize(input)
+		if err != nil {
+			t.Fatalf("Lexer error: %v", err)
+		}
+		if _, err := New(tokens).Parse(); err == nil {
+			t.Errorf("Expected parse error for %s", input)
+		}
+	}
+}
@@ -28,11 +28,11 @@ func TestParseSelect(t *testing.T) {
 	if len(sel.Fields) != 2 {
 		t.Fatalf("Expected 2 fields, got %d", len(sel.Fields))
 	}
-	if sel.Fields[0].Value != "id" {
-		t.Errorf("Expected field 0 to be id, got %s", sel.Fields[0].Value)
+	if sel.Fields[0].String() != "id" {
+		t.Errorf("Expected field 0 to be id, got %s", sel.Fields[0].String())
 	}
-	if sel.Fields[1].Value != "name" {
-		t.Errorf("Expected field 1 to be name, got %s", sel.Fields[1].Value)
+	if sel.Fields[1].String() != "name" {
+		t.Errorf("Expected field 1 to be name, got %s", sel.Fields[1].String())
 	}
 
 	if sel.TableName.Value != "users" {
@@ -59,6 +59,27 @@ func TestParseSelect(t *testing.T) {
 	}
 }
 
+func TestParseTrailingTokens(t *testing.T) {
+	// Tokens left after a statement are an error, not silently dropped (e.g. a WHERE clause
+	// after an unsupported table alias)
+	for _, input := range []string{
+		"SELECT name FROM users u WHERE u.id = 1",
+		"SELECT id FROM users; DROP TABLE users",
+		"WITH t AS (SELECT id FROM users) SELECT id FROM t x",
+		"INSERT INTO items (name) VALUES ('a') extra",
+		"UPDATE items SET qty = 1 WHERE id = 1 2",
+		"DELETE FROM items i WHERE i.id = 1",
+	} {
+		tokens, err := lexer.Tokenize(input)
+		if err != nil {
+			t.Fatalf("Lexer error: %v", err)
+		}
+		if _, err := New(tokens).Parse(); err == nil {
+			t.Errorf("Expected error for %s", input)
+		}
+	}
+}
+
 func TestParseInsert(t *testing.T) {
 	input := "INSERT INTO items (name, price) VALUES ('apple', 1.23);"
 	tokens, err := lexer.Tokenize(input)
@@ -88,18 +109,101 @@ func TestParseInsert(t *testing.T) {
 		t.Errorf("Expected col 0 to be name, got %s", ins.Columns[0].Value)
 	}
 
-	if len(ins.Values) != 2 {
-		t.Fatalf("Expected 2 values, got %d", len(ins.Values))
+	if len(ins.Rows[0]) != 2 {
+		t.Fatalf("Expected 2 values, got %d", len(ins.Rows[0]))
 	}
 	
-	val1, ok := ins.Values[0].(*ast.Literal)
+	val1, ok := ins.Rows[0][0].(*ast.Literal)
 	if !ok || val1.Value != "apple" {
-		t.Errorf("Expected value 0 to be 'apple', got %v", ins.Values[0])
+		t.Errorf("Expected value 0 to be 'apple', got %v", ins.Rows[0][0])
 	}
 
-	val2, ok := ins.Values[1].(*ast.Literal)
+	val2, ok := ins.Rows[0][1].(*ast.Literal)
 	if !ok || val2.Value != 1.23 {
-		t.Errorf("Expected value 1 to be 1.23, got %v", ins.Values[1])
+		t.Errorf("Expected value 1 to be 1.23, got %v", ins.Rows[0][1])
+	}
+}
+
+func TestParseMultiRowInsert(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		rows     int
+		query    bool
+		expected string
+	}{
+		{"VALUES rows", "INSERT INTO items (name, price) VALUES ('apple', 1.23), ('pear', 2 * 3);", 2, false,
+			"INSERT INTO items (name, price) VALUES (apple, 1.23), (pear, (2 * 3))"},
+		{"SELECT", "INSERT INTO archive (name) SELECT name FROM items WHERE price > 1", 0, true,
+			"INSERT INTO archive (name) SELECT name FROM items WHERE (price > 1)"},
+		{"WITH", "INSERT INTO archive (name) WITH cheap AS (SELECT name FROM items) SELECT name FROM cheap", 0, true, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tokens, err := lexer.Tokenize(tt.input)
+			if err != nil {
+				t.Fatalf("Lexer error: %v", err)
+			}
+			stmt, err := New(tokens).Parse()
+			if err != nil {
+				t.Fatalf("Parse error: %v", err)
+			}
+			ins, ok := stmt.(*ast.InsertStatement)
+			if !ok {
+				t.Fatalf("Expected InsertStatement, got %T", stmt)
+			}
+			if len(ins.Rows) != tt.rows {
+				t.Errorf("Expected %d rows, got %d", tt.rows, len(ins.Rows))
+			}
+			if (ins.Query != nil) != tt.query {
+				t.Errorf("Expected query %v, got %v", tt.query, ins.Query)
+			}
+			if tt.expected != "" && ins.String() != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, ins.String())
+			}
+		})
+	}
+
+	for _, input := range []string{
+		"INSERT INTO items (name) VALUES ('a'),",
+		"INSERT INTO items (name) UPDATE items",
+	} {
+		tokens, _ := lexer.Tokenize(input)
+		if _, err := New(tokens).Parse(); err == nil {
+			t.Errorf("Expected error for %s", input)
+		}
+	}
+}
+
+func TestParseReturning(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"INSERT INTO items (name) VALUES ('a') RETURNING id;", "INSERT INTO items (name) VALUES (a) RETURNING id"},
+		{"INSERT INTO items (name) SELECT name FROM old RETURNING *", "INSERT INTO items (name) SELECT name FROM old RETURNING *"},
+		{"UPDATE items SET price = 2 WHERE id = 1 RETURNING id, price AS p", "UPDATE items SET price = 2 WHERE (id = 1) RETURNING id, price AS p"},
+		{"DELETE FROM items WHERE id = 1 RETURNING UPPER(Name)", "DELETE FROM items WHERE (id = 1) RETURNING UPPER(name)"},
+	}
+
+	for _, tt := range tests {
+		tokens, err := lexer.Tokenize(tt.input)
+		if err != nil {
+			t.Fatalf("Lexer error: %v", err)
+		}
+		stmt, err := New(tokens).Parse()
+		if err != nil {
+			t.Fatalf("Parse error for %s: %v", tt.input, err)
+		}
+		if stmt.String() != tt.expected {
+			t.Errorf("Expected %q, got %q", tt.expected, stmt.String())
+		}
+	}
+
+	tokens, _ := lexer.Tokenize("DELETE FROM items RETURNING")
+	if _, err := New(tokens).Parse(); err == nil {
+		t.Error("Expected error for RETURNING without fields")
 	}
 }
 
@@ -228,3 +332,283 @@ func TestParseDelete(t *testing.T) {
 		})
 	}
 }
+
+func TestParseTransactionControl(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"BEGIN", "BEGIN"},
+		{"BEGIN TRANSACTION;", "BEGIN"},
+		{"commit", "COMMIT"},
+		{"COMMIT TRANSACTION;", "COMMIT"},
+		{"ROLLBACK;", "ROLLBACK"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			tokens, err := lexer.Tokenize(tt.input)
+			if err != nil {
+				t.Fatalf("Lexer error: %v", err)
+			}
+
+			stmt, err := New(tokens).Parse()
+			if err != nil {
+				t.Fatalf("Parse error: %v", err)
+			}
+
+			switch stmt.(type) {
+			case *ast.BeginStatement, *ast.CommitStatement, *ast.RollbackStatement:
+			default:
+				t.Fatalf("Expected transaction control statement, got %T", stmt)
+			}
+			if stmt.String() != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, stmt.String())
+			}
+		})
+	}
+
+	tokens, err := lexer.Tokenize("COMMIT users")
+	if err != nil {
+		t.Fatalf("Lexer error: %v", err)
+	}
+	if _, err := New(tokens).Parse(); err == nil {
+		t.Error("Expected error for trailing tokens after COMMIT")
+	}
+}
+
+func TestParseCheckpoint(t *testing.T) {
+	for _, input := range []string{"CHECKPOINT", "checkpoint;"} {
+		tokens, err := lexer.Tokenize(input)
+		if err != nil {
+			t.Fatalf("Lexer error: %v", err)
+		}
+		stmt, err := New(tokens).Parse()
+		if err != nil {
+			t.Fatalf("Parse error for %q: %v", input, err)
+		}
+		if _, ok := stmt.(*ast.CheckpointStatement); !ok {
+			t.Errorf("Expected CheckpointStatement for %q, got %T", input, stmt)
+		}
+	}
+
+	tokens, err := lexer.Tokenize("CHECKPOINT users")
+	if err != nil {
+		t.Fatalf("Lexer error: %v", err)
+	}
+	if _, err := New(tokens).Parse(); err == nil {
+		t.Error("Expected error for trailing tokens after CHECKPOINT")
+	}
+}
+
+func TestParseSelectOrderByLimit(t *testing.T) {
+	input := "SELECT id, name FROM users ORDER BY users.name DESC NULLS LAST, id LIMIT 10 OFFSET 20;"
+	tokens, err := lexer.Tokenize(input)
+	if err != nil {
+		t.Fatalf("Lexer error: %v", err)
+	}
+
+	stmt, err := New(tokens).Parse()
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+
+	sel, ok := stmt.(*ast.SelectStatement)
+	if !ok {
+		t.Fatalf("Expected SelectStatement, got %T", stmt)
+	}
+
+	if len(sel.OrderBy) != 2 {
+		t.Fatalf("Expected 2 ORDER BY keys, got %d", len(sel.OrderBy))
+	}
+	first := sel.OrderBy[0]
+	if first.Expr.String() != "users.name" || !first.Descending || first.Nulls != "LAST" {
+		t.Errorf("Unexpected first key: %s", first.String())
+	}
+	second := sel.OrderBy[1]
+	if second.Expr.String() != "id" || second.Descending || second.Nulls != "" {
+		t.Errorf("Unexpected second key: %s", second.String())
+	}
+
+	if sel.Limit == nil || *sel.Limit != 10 {
+		t.Errorf("Expected LIMIT 10, got %v", sel.Limit)
+	}
+	if sel.Offset == nil || *sel.Offset != 20 {
+		t.Errorf("Expected OFFSET 20, got %v", sel.Offset)
+	}
+
+	errorInputs := []string{
+		"SELECT * FROM users ORDER id",
+		"SELECT * FROM users ORDER BY id NULLS",
+		"SELECT * FROM users LIMIT abc",
+		"SELECT * FROM users LIMIT 1 LIMIT 2",
+	}
+	for _, input := range errorInputs {
+		tokens, err := lexer.Tokenize(input)
+		if err != nil {
+			t.Fatalf("Lexer error: %v", err)
+		}
+		if _, err := New(tokens).Parse(); err == nil {
+			t.Errorf("Expected parse error for %q", input)
+		}
+	}
+}
+
+func TestParseSelectAggregates(t *testing.T) {
+	input := "SELECT user_id, COUNT(*), SUM(DISTINCT orders.amount) FROM orders GROUP BY user_id, orders.status HAVING COUNT(*) > 1 ORDER BY COUNT(*) DESC"
+	tokens, err := lexer.Tokenize(input)
+	if err != nil {
+		t.Fatalf("Lexer error: %v", err)
+	}
+
+	stmt, err := New(tokens).Parse()
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+
+	sel, ok := stmt.(*ast.SelectStatement)
+	if !ok {
+		t.Fatalf("Expected SelectStatement, got %T", stmt)
+	}
+
+	if len(sel.Fields) != 3 {
+		t.Fatalf("Expected 3 fields, got %d", len(sel.Fields))
+	}
+	count, ok := sel.Fields[1].(*ast.FunctionCall)
+	if !ok || count.Name != "COUNT" || !count.Star {
+		t.Errorf("Expected COUNT(*), got %s", sel.Fields[1].String())
+	}
+	sum, ok := sel.Fields[2].(*ast.FunctionCall)
+	if !ok || sum.Name != "SUM" || !sum.Distinct || len(sum.Args) != 1 {
+		t.Fatalf("Expected SUM(DISTINCT orders.amount), got %s", sel.Fields[2].String())
+	}
+	if sum.String() != "SUM(DISTINCT orders.amount)" {
+		t.Errorf("Unexpected SUM string: %s", sum.String())
+	}
+
+	if len(sel.GroupBy) != 2 || sel.GroupBy[1].Table != "orders" || sel.GroupBy[1].Value != "status" {
+		t.Errorf("Unexpected GROUP BY: %v", sel.GroupBy)
+	}
+
+	having, ok := sel.Having.(*ast.BinaryExpression)
+	if !ok {
+		t.Fatalf("Expected HAVING comparison, got %T", sel.Having)
+	}
+	if _, ok := having.Left.(*ast.FunctionCall); !ok {
+		t.Errorf("Expected aggregate on left of HAVING, got %T", having.Left)
+	}
+
+	if len(sel.OrderBy) != 1 || sel.OrderBy[0].Expr.String() != "COUNT(*)" || !sel.OrderBy[0].Descending {
+		t.Errorf("Unexpected ORDER BY: %v", sel.OrderBy)
+	}
+}
+
+func TestParseInList(t *testing.T) {
+	input := "SELECT * FROM users WHERE id IN (1, 2, 3) AND name = 'bob'"
+	tokens, err := lexer.Tokenize(input)
+	if err != nil {
+		t.Fatalf("Lexer error: %v", err)
+	}
+
+	stmt, err := New(tokens).Parse()
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+
+	sel, ok := stmt.(*ast.SelectStatement)
+	if !ok {
+		t.Fatalf("Expected SelectStatement, got %T", stmt)
+	}
+
+	and, ok := sel.Where.(*ast.LogicalExpression)
+	if !ok {
+		t.Fatalf("Expected AND expression, got %T", sel.Where)
+	}
+	in, ok := and.Left.(*ast.InExpression)
+	if !ok {
+		t.Fatalf("Expected IN expression, got %T", and.Left)
+	}
+	if len(in.Values) != 3 {
+		t.Errorf("Expected 3 values, got %d", len(in.Values))
+	}
+	if in.String() != "(id IN (1, 2, 3))" {
+		t.Errorf("Unexpected IN string: %s", in.String())
+	}
+
+	errorInputs := []string{
+		"SELECT * FROM users WHERE id IN 1",
+		"SELECT * FROM users WHERE id IN ()",
+		"SELECT * FROM users WHERE id IN (1, 2",
+	}
+	for _, input := range errorInputs {
+		tokens, err := lexer.Tokenize(input)
+		if err != nil {
+			t.Fatalf("Lexer error: %v", err)
+		}
+		if _, err := New(tokens).Parse(); err == nil {
+			t.Errorf("Expected parse error for %q", input)
+		}
+	}
+}
+
+func TestParseSelectExpressionsAndAliases(t *testing.T) {
+	input := "SELECT Price * Qty AS Total, users.email, name AS who, COUNT(*) AS n, 'x' || name FROM t;"
+	tokens, err := lexer.Tokenize(input)
+	if err != nil {
+		t.Fatalf("Lexer error: %v", err)
+	}
+
+	stmt, err := New(tokens).Parse()
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	sel := stmt.(*ast.SelectStatement)
+
+	expected := []string{
+		"(price * qty) AS total",
+		"users.email",
+		"name AS who",
+		"COUNT(*) AS n",
+		"(x || name)",
+	}
+	if len(sel.Fields) != len(expected) {
+		t.Fatalf("Expected %d fields, got %d", len(expected), len(sel.Fields))
+	}
+	for i, want := range expected {
+		if got := sel.Fields[i].String(); got != want {
+			t.Errorf("Field %d: expected %s, got %s", i, want, got)
+		}
+	}
+
+	if _, ok := sel.Fields[0].(*ast.AliasExpression); !ok {
+		t.Errorf("Expected AliasExpression, got %T", sel.Fields[0])
+	}
+
+	tokens, _ = lexer.Tokenize("SELECT id AS FROM t;")
+	if _, err := New(tokens).Parse(); err == nil {
+		t.Error("Expected parse error for missing alias")
+	}
+}
+
+func TestParseUpdateExpression(t *testing.T) {
+	tokens, err := lexer.Tokenize("UPDATE accounts SET balance = balance - 10, note = 'n' || id WHERE id = 1;")
+	if err != nil {
+		t.Fatalf("Lexer error: %v", err)
+	}
+
+	stmt, err := New(tokens).Parse()
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	upd := stmt.(*ast.UpdateStatement)
+
+	if got := upd.Updates["balance"].String(); got != "(balance - 10)" {
+		t.Errorf("Expected (balance - 10), got %s", got)
+	}
+	if got := upd.Updates["note"].String(); got != "(n || id)" {
+		t.Errorf("Expected (n || id), got %s", got)
+	}
+	if upd.Where == nil {
+		t.Error("Expected WHERE clause, got nil")
+	}
+}
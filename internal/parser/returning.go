@@ -0,0 +1,18 @@
+package parser
+
+import (
+	"github.com/leengari/mini-rdbms/internal/parser/ast"
+	"github.com/leengari/mini-rdbms/internal/parser/lexer"
+)
+
+// parseReturning parses the optional RETURNING clause of INSERT, UPDATE and DELETE
+// Grammar: RETURNING * | RETURNING expression [AS alias] [, ...]
+// Returns nil when the current token is not RETURNING.
+func (p *Parser) parseReturning() ([]ast.Expression, error) {
+	if p.curTok.Type != lexer.RETURNING {
+		return nil, nil
+	}
+	p.nextToken()
+
+	return p.parseSelectList()
+}
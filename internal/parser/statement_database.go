@@ -7,8 +7,15 @@ import (
 	"github.com/leengari/mini-rdbms/internal/parser/lexer"
 )
 
-// parseCreate parses CREATE DATABASE statement
+// parseCreate parses CREATE DATABASE, CREATE TABLE and CREATE INDEX statements
 func (p *Parser) parseCreate() (ast.Statement, error) {
+	if p.peekTok.Type == lexer.TABLE {
+		return p.parseCreateTable()
+	}
+	if p.peekTok.Type == lexer.INDEX || p.peekTok.Type == lexer.UNIQUE {
+		return p.parseCreateIndex()
+	}
+
 	// Expect DATABASE token
 	if !p.expectPeek(lexer.DATABASE) {
 		return nil, fmt.Errorf("expected DATABASE after CREATE, got %s", p.peekTok.Literal)
@@ -31,8 +38,15 @@ func (p *Parser) parseCreate() (ast.Statement, error) {
 	return stmt, nil
 }
 
-// parseDrop parses DROP DATABASE statement
+// parseDrop parses DROP DATABASE, DROP TABLE and DROP INDEX statements
 func (p *Parser) parseDrop() (ast.Statement, error) {
+	if p.peekTok.Type == lexer.TABLE {
+		return p.parseDropTable()
+	}
+	if p.peekTok.Type == lexer.INDEX {
+		return p.parseDropIndex()
+	}
+
 	// Expect DATABASE token
 	if !p.expectPeek(lexer.DATABASE) {
 		return nil, fmt.Errorf("expected DATABASE after DROP, got %s", p.peekTok.Literal)
@@ -74,8 +88,12 @@ func (p *Parser) parseUse() (ast.Statement, error) {
 	return stmt, nil
 }
 
-// parseAlter parses ALTER DATABASE statement
+// parseAlter parses ALTER DATABASE and ALTER TABLE statements
 func (p *Parser) parseAlter() (ast.Statement, error) {
+	if p.peekTok.Type == lexer.TABLE {
+		return p.parseAlterTable()
+	}
+
 	// Expect DATABASE token
 	if !p.expectPeek(lexer.DATABASE) {
 		return nil, fmt.Errorf("expected DATABASE after ALTER, got %s", p.peekTok.Literal)
@@ -8,7 +8,7 @@ import (
 )
 
 // parseDelete parses a DELETE statement
-// Grammar: DELETE FROM table_name [WHERE condition]
+// Grammar: DELETE FROM table_name [WHERE condition] [RETURNING fields]
 // Example: DELETE FROM users WHERE active = false
 func (p *Parser) parseDelete() (*ast.DeleteStatement, error) {
 	stmt := &ast.DeleteStatement{}
@@ -39,6 +39,13 @@ func (p *Parser) parseDelete() (*ast.DeleteStatement, error) {
 		stmt.Where = expr
 	}
 
+	// RETURNING clause (optional)
+	returning, err := p.parseReturning()
+	if err != nil {
+		return nil, err
+	}
+	stmt.Returning = returning
+
 	// Semicolon (optional)
 	if p.curTok.Type == lexer.SEMICOLON {
 		p.nextToken()
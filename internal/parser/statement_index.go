@@ -0,0 +1,118 @@
+package parser
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/leengari/mini-rdbms/internal/parser/ast"
+	"github.com/leengari/mini-rdbms/internal/parser/lexer"
+)
+
+// parseCreateIndex parses a CREATE INDEX statement
+// Grammar: CREATE [UNIQUE] INDEX index_name ON table_name (column [, column ...])
+func (p *Parser) parseCreateIndex() (*ast.CreateIndexStatement, error) {
+	stmt := &ast.CreateIndexStatement{}
+
+	// CREATE keyword - already consumed by Parse()
+	p.nextToken()
+
+	// UNIQUE (optional)
+	if p.curTok.Type == lexer.UNIQUE {
+		stmt.Unique = true
+		p.nextToken()
+	}
+
+	// INDEX keyword
+	if p.curTok.Type != lexer.INDEX {
+		return nil, fmt.Errorf("expected INDEX after CREATE UNIQUE, got %s", p.curTok.Literal)
+	}
+	p.nextToken()
+
+	// Index name
+	if p.curTok.Type != lexer.IDENTIFIER {
+		return nil, fmt.Errorf("expected index name after CREATE INDEX, got %s", p.curTok.Literal)
+	}
+	stmt.Name = p.curTok.Literal
+	p.nextToken()
+
+	// ON table_name
+	if p.curTok.Type != lexer.ON {
+		return nil, fmt.Errorf("expected ON after index name, got %s", p.curTok.Literal)
+	}
+	p.nextToken()
+	if p.curTok.Type != lexer.IDENTIFIER {
+		return nil, fmt.Errorf("expected table name after ON, got %s", p.curTok.Literal)
+	}
+	stmt.TableName = &ast.Identifier{TokenLiteralValue: p.curTok.Literal, Value: p.curTok.Literal}
+	p.nextToken()
+
+	// (column, ...)
+	if p.curTok.Type != lexer.PAREN_OPEN {
+		return nil, fmt.Errorf("expected ( after table name, got %s", p.curTok.Literal)
+	}
+	p.nextToken()
+	for {
+		// Column names may be keywords (DATE, TIME, EMAIL), as in CREATE TABLE
+		if !isIdentifierOrKeyword(p.curTok.Type) {
+			return nil, fmt.Errorf("expected column name in index, got %s", p.curTok.Literal)
+		}
+		stmt.Columns = append(stmt.Columns, strings.ToLower(p.curTok.Literal))
+		p.nextToken()
+
+		if p.curTok.Type == lexer.COMMA {
+			p.nextToken()
+			continue
+		}
+		if p.curTok.Type != lexer.PAREN_CLOSE {
+			return nil, fmt.Errorf("expected , or ) in index column list, got %s", p.curTok.Literal)
+		}
+		p.nextToken()
+		break
+	}
+
+	// Semicolon (optional)
+	if p.curTok.Type == lexer.SEMICOLON {
+		p.nextToken()
+	}
+
+	return stmt, nil
+}
+
+// parseDropIndex parses a DROP INDEX statement
+// Grammar: DROP INDEX [IF EXISTS] index_name
+func (p *Parser) parseDropIndex() (*ast.DropIndexStatement, error) {
+	stmt := &ast.DropIndexStatement{}
+
+	// DROP keyword - already consumed by Parse()
+	p.nextToken()
+
+	// INDEX keyword
+	if p.curTok.Type != lexer.INDEX {
+		return nil, fmt.Errorf("expected INDEX after DROP, got %s", p.curTok.Literal)
+	}
+	p.nextToken()
+
+	// IF EXISTS (optional)
+	if p.curTok.Type == lexer.IF {
+		p.nextToken()
+		if p.curTok.Type != lexer.EXISTS {
+			return nil, fmt.Errorf("expected EXISTS after IF, got %s", p.curTok.Literal)
+		}
+		stmt.IfExists = true
+		p.nextToken()
+	}
+
+	// Index name
+	if p.curTok.Type != lexer.IDENTIFIER {
+		return nil, fmt.Errorf("expected index name after DROP INDEX, got %s", p.curTok.Literal)
+	}
+	stmt.Name = p.curTok.Literal
+	p.nextToken()
+
+	// Semicolon (optional)
+	if p.curTok.Type == lexer.SEMICOLON {
+		p.nextToken()
+	}
+
+	return stmt, nil
+}
@@ -8,7 +8,9 @@ import (
 )
 
 // parseInsert parses an INSERT statement
-// Grammar: INSERT INTO table (columns) VALUES (values)
+// Grammar: INSERT INTO table (columns) VALUES (values) [, (values) ...]
+//          INSERT INTO table (columns) query
+// Either form may end with RETURNING fields.
 func (p *Parser) parseInsert() (*ast.InsertStatement, error) {
 	stmt := &ast.InsertStatement{}
 
@@ -38,23 +40,50 @@ func (p *Parser) parseInsert() (*ast.InsertStatement, error) {
 		stmt.Columns = cols
 	}
 
-	// VALUES
-	if p.curTok.Type != lexer.VALUES {
-		return nil, fmt.Errorf("expected VALUES, got %s", p.curTok.Literal)
-	}
-	p.nextToken()
+	// SELECT ... (or WITH ... SELECT ...)
+	if p.curTok.Type == lexer.SELECT || p.curTok.Type == lexer.WITH {
+		var query ast.Statement
+		var err error
+		if p.curTok.Type == lexer.WITH {
+			query, err = p.parseWith()
+		} else {
+			query, err = p.parseQuery()
+		}
+		if err != nil {
+			return nil, err
+		}
+		stmt.Query = query
+	} else {
+		// VALUES
+		if p.curTok.Type != lexer.VALUES {
+			return nil, fmt.Errorf("expected VALUES or SELECT, got %s", p.curTok.Literal)
+		}
+		p.nextToken()
+
+		// One parenthesized list of values per row, separated by commas
+		for {
+			if p.curTok.Type != lexer.PAREN_OPEN {
+				return nil, fmt.Errorf("expected (, got %s", p.curTok.Literal)
+			}
+			values, err := p.parseExpressionList()
+			if err != nil {
+				return nil, err
+			}
+			stmt.Rows = append(stmt.Rows, values)
 
-	// (
-	if p.curTok.Type != lexer.PAREN_OPEN {
-		return nil, fmt.Errorf("expected (, got %s", p.curTok.Literal)
+			if p.curTok.Type != lexer.COMMA {
+				break
+			}
+			p.nextToken()
+		}
 	}
-	
-	// Parse Values List
-	values, err := p.parseExpressionList()
+
+	// RETURNING (Optional)
+	returning, err := p.parseReturning()
 	if err != nil {
 		return nil, err
 	}
-	stmt.Values = values
+	stmt.Returning = returning
 
 	// Semicolon (Optional)
 	if p.curTok.Type == lexer.SEMICOLON {
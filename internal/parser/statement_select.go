@@ -2,13 +2,16 @@ package parser
 
 import (
 	"fmt"
+	"strconv"
+	"strings"
 
 	"github.com/leengari/mini-rdbms/internal/parser/ast"
 	"github.com/leengari/mini-rdbms/internal/parser/lexer"
 )
 
 // parseSelect parses a SELECT statement
-// Grammar: SELECT fields FROM table [JOIN ...] [WHERE condition]
+// Grammar: SELECT fields FROM table|(subquery) AS alias [JOIN ...] [WHERE condition] [GROUP BY columns] [HAVING condition]
+// [ORDER BY keys] [LIMIT n] [OFFSET m]
 func (p *Parser) parseSelect() (*ast.SelectStatement, error) {
 	stmt := &ast.SelectStatement{}
 
@@ -16,7 +19,7 @@ func (p *Parser) parseSelect() (*ast.SelectStatement, error) {
 	p.nextToken()
 
 	// Fields
-	fields, err := p.parseIdentifierList()
+	fields, err := p.parseSelectList()
 	if err != nil {
 		return nil, err
 	}
@@ -28,12 +31,21 @@ func (p *Parser) parseSelect() (*ast.SelectStatement, error) {
 	}
 	p.nextToken()
 
-	// Table Name
-	if p.curTok.Type != lexer.IDENTIFIER {
-		return nil, fmt.Errorf("expected table name, got %s", p.curTok.Literal)
+	// Table Name, or a derived table: (SELECT ...) AS alias
+	if p.curTok.Type == lexer.PAREN_OPEN {
+		derived, alias, err := p.parseDerivedTable()
+		if err != nil {
+			return nil, err
+		}
+		stmt.Derived = derived
+		stmt.TableName = alias
+	} else {
+		if p.curTok.Type != lexer.IDENTIFIER {
+			return nil, fmt.Errorf("expected table name, got %s", p.curTok.Literal)
+		}
+		stmt.TableName = &ast.Identifier{TokenLiteralValue: p.curTok.Literal, Value: p.curTok.Literal}
+		p.nextToken()
 	}
-	stmt.TableName = &ast.Identifier{TokenLiteralValue: p.curTok.Literal, Value: p.curTok.Literal}
-	p.nextToken()
 
 	// JOINs (Optional, can have multiple)
 	for isJoinKeyword(p.curTok.Type) {
@@ -54,6 +66,67 @@ func (p *Parser) parseSelect() (*ast.SelectStatement, error) {
 		stmt.Where = expr
 	}
 
+	// GROUP BY (Optional)
+	if p.curTok.Type == lexer.GROUP {
+		p.nextToken()
+		if p.curTok.Type != lexer.BY {
+			return nil, fmt.Errorf("expected BY after GROUP, got %s", p.curTok.Literal)
+		}
+		p.nextToken()
+
+		for {
+			col, err := p.parseQualifiedIdentifier()
+			if err != nil {
+				return nil, fmt.Errorf("invalid GROUP BY column: %w", err)
+			}
+			stmt.GroupBy = append(stmt.GroupBy, col)
+
+			if p.curTok.Type != lexer.COMMA {
+				break
+			}
+			p.nextToken()
+		}
+	}
+
+	// HAVING (Optional)
+	if p.curTok.Type == lexer.HAVING {
+		p.nextToken()
+		expr, err := p.parseExpression()
+		if err != nil {
+			return nil, err
+		}
+		stmt.Having = expr
+	}
+
+	// ORDER BY (Optional)
+	if p.curTok.Type == lexer.ORDER {
+		orderBy, err := p.parseOrderBy()
+		if err != nil {
+			return nil, err
+		}
+		stmt.OrderBy = orderBy
+	}
+
+	// LIMIT / OFFSET (Optional, in either order)
+	for p.curTok.Type == lexer.LIMIT || p.curTok.Type == lexer.OFFSET {
+		clause := p.curTok.Literal
+		isLimit := p.curTok.Type == lexer.LIMIT
+		if (isLimit && stmt.Limit != nil) || (!isLimit && stmt.Offset != nil) {
+			return nil, fmt.Errorf("duplicate %s clause", strings.ToUpper(clause))
+		}
+		p.nextToken()
+
+		n, err := p.parseNonNegativeInt(clause)
+		if err != nil {
+			return nil, err
+		}
+		if isLimit {
+			stmt.Limit = &n
+		} else {
+			stmt.Offset = &n
+		}
+	}
+
 	// Semicolon (Optional)
 	if p.curTok.Type == lexer.SEMICOLON {
 		p.nextToken()
@@ -62,10 +135,98 @@ func (p *Parser) parseSelect() (*ast.SelectStatement, error) {
 	return stmt, nil
 }
 
+// parseSelectList parses the SELECT field list
+// Each field is a scalar expression (a column, a function call such as COUNT(*), or arithmetic
+// over them) with an optional AS alias; * selects all columns
+func (p *Parser) parseSelectList() ([]ast.Expression, error) {
+	if p.curTok.Type == lexer.ASTERISK {
+		p.nextToken()
+		return []ast.Expression{&ast.Identifier{TokenLiteralValue: "*", Value: "*"}}, nil
+	}
+
+	var fields []ast.Expression
+	for {
+		field, err := p.parseSelectField()
+		if err != nil {
+			return nil, err
+		}
+		fields = append(fields, field)
+
+		if p.curTok.Type != lexer.COMMA {
+			break
+		}
+		p.nextToken()
+	}
+
+	return fields, nil
+}
+
+// parseSelectField parses one SELECT field
+// Grammar: expression [AS alias]
+// Column names and the alias are lower-cased, like the rest of the field list.
+func (p *Parser) parseSelectField() (ast.Expression, error) {
+	expr, err := p.parseScalarExpression()
+	if err != nil {
+		return nil, err
+	}
+	expr = lowerColumnNames(expr)
+
+	if p.curTok.Type != lexer.AS {
+		return expr, nil
+	}
+	p.nextToken()
+
+	if !isIdentifierOrKeyword(p.curTok.Type) {
+		return nil, fmt.Errorf("expected alias after AS, got %s", p.curTok.Literal)
+	}
+	alias := strings.ToLower(p.curTok.Literal)
+	p.nextToken()
+
+	return &ast.AliasExpression{Expr: expr, Alias: alias}, nil
+}
+
+// lowerColumnNames lower-cases the column references of a scalar expression
+func lowerColumnNames(expr ast.Expression) ast.Expression {
+	switch e := expr.(type) {
+	case *ast.Identifier:
+		table := strings.ToLower(e.Table)
+		value := strings.ToLower(e.Value)
+		literal := value
+		if table != "" {
+			literal = table + "." + value
+		}
+		return &ast.Identifier{TokenLiteralValue: literal, Table: table, Value: value}
+	case *ast.BinaryExpression:
+		return &ast.BinaryExpression{Left: lowerColumnNames(e.Left), Operator: e.Operator, Right: lowerColumnNames(e.Right)}
+	case *ast.UnaryExpression:
+		return &ast.UnaryExpression{Operator: e.Operator, Operand: lowerColumnNames(e.Operand)}
+	case *ast.FunctionCall:
+		call := &ast.FunctionCall{Name: e.Name, Star: e.Star, Distinct: e.Distinct}
+		for _, arg := range e.Args {
+			call.Args = append(call.Args, lowerColumnNames(arg))
+		}
+		return call
+	case *ast.CastExpression:
+		return &ast.CastExpression{Expr: lowerColumnNames(e.Expr), Type: e.Type}
+	case *ast.WindowExpression:
+		window := &ast.WindowExpression{Function: lowerColumnNames(e.Function).(*ast.FunctionCall), Frame: e.Frame}
+		for _, key := range e.PartitionBy {
+			window.PartitionBy = append(window.PartitionBy, lowerColumnNames(key))
+		}
+		for _, item := range e.OrderBy {
+			window.OrderBy = append(window.OrderBy, &ast.OrderByItem{Expr: lowerColumnNames(item.Expr), Descending: item.Descending, Nulls: item.Nulls})
+		}
+		return window
+	default:
+		return expr
+	}
+}
+
 // parseJoin parses a JOIN clause
-// Grammar: [INNER|LEFT|RIGHT|FULL] [OUTER] JOIN table ON condition
+// Grammar: [INNER|LEFT|RIGHT|FULL] [OUTER] JOIN table|(subquery) [AS] alias ON condition
 // Examples:
 //   - INNER JOIN orders ON users.id = orders.user_id
+//   - JOIN (SELECT user_id, COUNT(*) AS n FROM orders GROUP BY user_id) AS c ON users.id = c.user_id
 //   - LEFT OUTER JOIN orders ON users.id = orders.user_id
 func (p *Parser) parseJoin() (*ast.JoinClause, error) {
 	join := &ast.JoinClause{}
@@ -102,12 +263,21 @@ func (p *Parser) parseJoin() (*ast.JoinClause, error) {
 	}
 	p.nextToken()
 
-	// Right table name
-	if p.curTok.Type != lexer.IDENTIFIER {
-		return nil, fmt.Errorf("expected table name after JOIN, got %s", p.curTok.Literal)
+	// Right table name, or a derived table: (SELECT ...) AS alias
+	if p.curTok.Type == lexer.PAREN_OPEN {
+		derived, alias, err := p.parseDerivedTable()
+		if err != nil {
+			return nil, err
+		}
+		join.Derived = derived
+		join.RightTable = alias
+	} else {
+		if p.curTok.Type != lexer.IDENTIFIER {
+			return nil, fmt.Errorf("expected table name after JOIN, got %s", p.curTok.Literal)
+		}
+		join.RightTable = &ast.Identifier{TokenLiteralValue: p.curTok.Literal, Value: p.curTok.Literal}
+		p.nextToken()
 	}
-	join.RightTable = &ast.Identifier{TokenLiteralValue: p.curTok.Literal, Value: p.curTok.Literal}
-	p.nextToken()
 
 	// ON keyword
 	if p.curTok.Type != lexer.ON {
@@ -129,3 +299,76 @@ func (p *Parser) parseJoin() (*ast.JoinClause, error) {
 func isJoinKeyword(t lexer.TokenType) bool {
 	return t == lexer.INNER || t == lexer.LEFT || t == lexer.RIGHT || t == lexer.FULL || t == lexer.JOIN
 }
+
+// parseOrderBy parses an ORDER BY clause
+// Grammar: ORDER BY (column | aggregate) [ASC|DESC] [NULLS FIRST|LAST] [, ...]
+func (p *Parser) parseOrderBy() ([]*ast.OrderByItem, error) {
+	// ORDER keyword
+	p.nextToken()
+
+	// BY keyword
+	if p.curTok.Type != lexer.BY {
+		return nil, fmt.Errorf("expected BY after ORDER, got %s", p.curTok.Literal)
+	}
+	p.nextToken()
+
+	var items []*ast.OrderByItem
+	for {
+		var key ast.Expression
+		var err error
+		if p.curTok.Type == lexer.IDENTIFIER && p.peekTok.Type == lexer.PAREN_OPEN {
+			key, err = p.parseFunctionCall()
+		} else {
+			key, err = p.parseQualifiedIdentifier()
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid ORDER BY key: %w", err)
+		}
+		item := &ast.OrderByItem{Expr: key}
+
+		// Direction (optional)
+		switch p.curTok.Type {
+		case lexer.ASC:
+			p.nextToken()
+		case lexer.DESC:
+			item.Descending = true
+			p.nextToken()
+		}
+
+		// NULLS FIRST | NULLS LAST (optional)
+		if p.curTok.Type == lexer.NULLS {
+			p.nextToken()
+			switch p.curTok.Type {
+			case lexer.FIRST:
+				item.Nulls = "FIRST"
+			case lexer.LAST:
+				item.Nulls = "LAST"
+			default:
+				return nil, fmt.Errorf("expected FIRST or LAST after NULLS, got %s", p.curTok.Literal)
+			}
+			p.nextToken()
+		}
+
+		items = append(items, item)
+
+		if p.curTok.Type != lexer.COMMA {
+			break
+		}
+		p.nextToken()
+	}
+
+	return items, nil
+}
+
+// parseNonNegativeInt parses the integer argument of a LIMIT or OFFSET clause
+func (p *Parser) parseNonNegativeInt(clause string) (int, error) {
+	if p.curTok.Type != lexer.NUMBER {
+		return 0, fmt.Errorf("expected number after %s, got %s", strings.ToUpper(clause), p.curTok.Literal)
+	}
+	n, err := strconv.Atoi(p.curTok.Literal)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("%s must be a non-negative integer, got %s", strings.ToUpper(clause), p.curTok.Literal)
+	}
+	p.nextToken()
+	return n, nil
+}
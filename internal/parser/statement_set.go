@@ -0,0 +1,83 @@
+package parser
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/leengari/mini-rdbms/internal/parser/ast"
+	"github.com/leengari/mini-rdbms/internal/parser/lexer"
+)
+
+// parseQuery parses a SELECT, or SELECTs combined by set operations
+// Grammar: intersection { UNION|EXCEPT [ALL] intersection } [ORDER BY keys] [LIMIT n] [OFFSET m]
+// The ORDER BY, LIMIT and OFFSET written after the last SELECT apply to the combined rows.
+func (p *Parser) parseQuery() (ast.Statement, error) {
+	query, last, err := p.parseIntersection()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.curTok.Type == lexer.UNION || p.curTok.Type == lexer.EXCEPT {
+		op, all, err := p.parseSetOperator(last)
+		if err != nil {
+			return nil, err
+		}
+		var right ast.Statement
+		if right, last, err = p.parseIntersection(); err != nil {
+			return nil, err
+		}
+		query = &ast.SetOperationStatement{Left: query, Operator: op, All: all, Right: right}
+	}
+
+	if set, ok := query.(*ast.SetOperationStatement); ok {
+		set.OrderBy, set.Limit, set.Offset = last.OrderBy, last.Limit, last.Offset
+		last.OrderBy, last.Limit, last.Offset = nil, nil, nil
+	}
+	return query, nil
+}
+
+// parseIntersection parses SELECTs combined by INTERSECT, which binds tighter than UNION and EXCEPT
+// Grammar: SELECT ... { INTERSECT [ALL] SELECT ... }
+// Returns the last SELECT parsed along with the query.
+func (p *Parser) parseIntersection() (ast.Statement, *ast.SelectStatement, error) {
+	if p.curTok.Type != lexer.SELECT {
+		return nil, nil, fmt.Errorf("expected SELECT, got %s", p.curTok.Literal)
+	}
+	last, err := p.parseSelect()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var query ast.Statement = last
+	for p.curTok.Type == lexer.INTERSECT {
+		op, all, err := p.parseSetOperator(last)
+		if err != nil {
+			return nil, nil, err
+		}
+		if p.curTok.Type != lexer.SELECT {
+			return nil, nil, fmt.Errorf("expected SELECT after %s, got %s", op, p.curTok.Literal)
+		}
+		if last, err = p.parseSelect(); err != nil {
+			return nil, nil, err
+		}
+		query = &ast.SetOperationStatement{Left: query, Operator: op, All: all, Right: last}
+	}
+	return query, last, nil
+}
+
+// parseSetOperator parses UNION, INTERSECT or EXCEPT and an optional ALL
+// preceding is the SELECT before the operator, which may not have its own ORDER BY or LIMIT.
+func (p *Parser) parseSetOperator(preceding *ast.SelectStatement) (string, bool, error) {
+	op := strings.ToUpper(p.curTok.Literal)
+	if len(preceding.OrderBy) > 0 || preceding.Limit != nil || preceding.Offset != nil {
+		return "", false, fmt.Errorf("ORDER BY, LIMIT and OFFSET must follow the last query of a %s", op)
+	}
+	p.nextToken()
+
+	all := false
+	if p.curTok.Type == lexer.ALL {
+		all = true
+		p.nextToken()
+	}
+	return op, all, nil
+}
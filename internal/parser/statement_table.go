@@ -0,0 +1,261 @@
+package parser
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/leengari/mini-rdbms/internal/parser/ast"
+	"github.com/leengari/mini-rdbms/internal/parser/lexer"
+)
+
+// parseCreateTable parses a CREATE TABLE statement
+// Grammar: CREATE TABLE table_name (column_def [, column_def ...])
+// Example: CREATE TABLE users (id INT PRIMARY KEY AUTO_INCREMENT, name TEXT NOT NULL)
+func (p *Parser) parseCreateTable() (*ast.CreateTableStatement, error) {
+	stmt := &ast.CreateTableStatement{}
+
+	// CREATE keyword - already consumed by Parse()
+	p.nextToken()
+
+	// TABLE keyword
+	if p.curTok.Type != lexer.TABLE {
+		return nil, fmt.Errorf("expected TABLE after CREATE, got %s", p.curTok.Literal)
+	}
+	p.nextToken()
+
+	// Table name
+	if p.curTok.Type != lexer.IDENTIFIER {
+		return nil, fmt.Errorf("expected table name after CREATE TABLE, got %s", p.curTok.Literal)
+	}
+	stmt.TableName = &ast.Identifier{TokenLiteralValue: p.curTok.Literal, Value: p.curTok.Literal}
+	p.nextToken()
+
+	// (
+	if p.curTok.Type != lexer.PAREN_OPEN {
+		return nil, fmt.Errorf("expected ( after table name, got %s", p.curTok.Literal)
+	}
+	p.nextToken()
+
+	// Column definitions
+	for {
+		col, err := p.parseColumnDefinition()
+		if err != nil {
+			return nil, err
+		}
+		stmt.Columns = append(stmt.Columns, col)
+
+		if p.curTok.Type == lexer.COMMA {
+			p.nextToken()
+			continue
+		}
+		break
+	}
+
+	// )
+	if p.curTok.Type != lexer.PAREN_CLOSE {
+		return nil, fmt.Errorf("expected ) after column definitions, got %s", p.curTok.Literal)
+	}
+	p.nextToken()
+
+	// Semicolon (optional)
+	if p.curTok.Type == lexer.SEMICOLON {
+		p.nextToken()
+	}
+
+	return stmt, nil
+}
+
+// parseColumnDefinition parses a single column definition
+// Grammar: column_name TYPE [PRIMARY KEY] [UNIQUE] [NOT NULL] [AUTO_INCREMENT] [DEFAULT literal]
+// Constraints may appear in any order
+func (p *Parser) parseColumnDefinition() (*ast.ColumnDefinition, error) {
+	// Column name (can be IDENTIFIER or keywords like EMAIL, DATE, TIME)
+	if !isIdentifierOrKeyword(p.curTok.Type) {
+		return nil, fmt.Errorf("expected column name, got %s", p.curTok.Literal)
+	}
+	col := &ast.ColumnDefinition{Name: strings.ToLower(p.curTok.Literal)}
+	p.nextToken()
+
+	// Column type (DATE, TIME and EMAIL are lexed as keywords)
+	if !isIdentifierOrKeyword(p.curTok.Type) {
+		return nil, fmt.Errorf("expected type for column '%s', got %s", col.Name, p.curTok.Literal)
+	}
+	col.Type = strings.ToUpper(p.curTok.Literal)
+	p.nextToken()
+
+	// Constraints
+	for {
+		switch p.curTok.Type {
+		case lexer.PRIMARY:
+			p.nextToken()
+			if p.curTok.Type != lexer.KEY {
+				return nil, fmt.Errorf("expected KEY after PRIMARY, got %s", p.curTok.Literal)
+			}
+			col.PrimaryKey = true
+		case lexer.UNIQUE:
+			col.Unique = true
+		case lexer.NOT:
+			p.nextToken()
+			if p.curTok.Type != lexer.NULL {
+				return nil, fmt.Errorf("expected NULL after NOT, got %s", p.curTok.Literal)
+			}
+			col.NotNull = true
+		case lexer.AUTO_INCREMENT:
+			col.AutoIncrement = true
+		case lexer.DEFAULT:
+			p.nextToken()
+			val, err := p.parseUnaryExpression()
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse DEFAULT for column '%s': %w", col.Name, err)
+			}
+			lit, ok := val.(*ast.Literal)
+			if !ok {
+				return nil, fmt.Errorf("DEFAULT for column '%s' must be a literal value", col.Name)
+			}
+			col.Default = lit
+			// parseUnaryExpression already advanced past the value
+			continue
+		default:
+			return col, nil
+		}
+		p.nextToken()
+	}
+}
+
+// parseDropTable parses a DROP TABLE statement
+// Grammar: DROP TABLE [IF EXISTS] table_name
+func (p *Parser) parseDropTable() (*ast.DropTableStatement, error) {
+	stmt := &ast.DropTableStatement{}
+
+	// DROP keyword - already consumed by Parse()
+	p.nextToken()
+
+	// TABLE keyword
+	if p.curTok.Type != lexer.TABLE {
+		return nil, fmt.Errorf("expected TABLE after DROP, got %s", p.curTok.Literal)
+	}
+	p.nextToken()
+
+	// IF EXISTS (optional)
+	if p.curTok.Type == lexer.IF {
+		p.nextToken()
+		if p.curTok.Type != lexer.EXISTS {
+			return nil, fmt.Errorf("expected EXISTS after IF, got %s", p.curTok.Literal)
+		}
+		stmt.IfExists = true
+		p.nextToken()
+	}
+
+	// Table name
+	if p.curTok.Type != lexer.IDENTIFIER {
+		return nil, fmt.Errorf("expected table name after DROP TABLE, got %s", p.curTok.Literal)
+	}
+	stmt.TableName = &ast.Identifier{TokenLiteralValue: p.curTok.Literal, Value: p.curTok.Literal}
+	p.nextToken()
+
+	// Semicolon (optional)
+	if p.curTok.Type == lexer.SEMICOLON {
+		p.nextToken()
+	}
+
+	return stmt, nil
+}
+
+// parseAlterTable parses an ALTER TABLE statement
+// Grammar:
+//
+//	ALTER TABLE table_name ADD [COLUMN] column_def
+//	ALTER TABLE table_name DROP [COLUMN] column_name
+//	ALTER TABLE table_name RENAME COLUMN old_name TO new_name
+//	ALTER TABLE table_name RENAME TO new_table_name
+func (p *Parser) parseAlterTable() (*ast.AlterTableStatement, error) {
+	stmt := &ast.AlterTableStatement{}
+
+	// ALTER keyword - already consumed by Parse()
+	p.nextToken()
+
+	// TABLE keyword
+	if p.curTok.Type != lexer.TABLE {
+		return nil, fmt.Errorf("expected TABLE after ALTER, got %s", p.curTok.Literal)
+	}
+	p.nextToken()
+
+	// Table name
+	if p.curTok.Type != lexer.IDENTIFIER {
+		return nil, fmt.Errorf("expected table name after ALTER TABLE, got %s", p.curTok.Literal)
+	}
+	stmt.TableName = &ast.Identifier{TokenLiteralValue: p.curTok.Literal, Value: p.curTok.Literal}
+	p.nextToken()
+
+	switch p.curTok.Type {
+	case lexer.ADD:
+		p.nextToken()
+		if p.curTok.Type == lexer.COLUMN {
+			p.nextToken()
+		}
+		col, err := p.parseColumnDefinition()
+		if err != nil {
+			return nil, err
+		}
+		stmt.Action = ast.AlterAddColumn
+		stmt.Column = col
+
+	case lexer.DROP:
+		p.nextToken()
+		if p.curTok.Type == lexer.COLUMN {
+			p.nextToken()
+		}
+		if !isIdentifierOrKeyword(p.curTok.Type) {
+			return nil, fmt.Errorf("expected column name after DROP COLUMN, got %s", p.curTok.Literal)
+		}
+		stmt.Action = ast.AlterDropColumn
+		stmt.ColumnName = strings.ToLower(p.curTok.Literal)
+		p.nextToken()
+
+	case lexer.RENAME:
+		p.nextToken()
+		if p.curTok.Type == lexer.TO {
+			// RENAME TO new_table_name
+			p.nextToken()
+			if p.curTok.Type != lexer.IDENTIFIER {
+				return nil, fmt.Errorf("expected new table name after RENAME TO, got %s", p.curTok.Literal)
+			}
+			stmt.Action = ast.AlterRenameTable
+			stmt.NewName = p.curTok.Literal
+			p.nextToken()
+			break
+		}
+
+		// RENAME COLUMN old TO new
+		if p.curTok.Type != lexer.COLUMN {
+			return nil, fmt.Errorf("expected COLUMN or TO after RENAME, got %s", p.curTok.Literal)
+		}
+		p.nextToken()
+		if !isIdentifierOrKeyword(p.curTok.Type) {
+			return nil, fmt.Errorf("expected column name after RENAME COLUMN, got %s", p.curTok.Literal)
+		}
+		stmt.ColumnName = strings.ToLower(p.curTok.Literal)
+		p.nextToken()
+
+		if p.curTok.Type != lexer.TO {
+			return nil, fmt.Errorf("expected TO after column name, got %s", p.curTok.Literal)
+		}
+		p.nextToken()
+		if !isIdentifierOrKeyword(p.curTok.Type) {
+			return nil, fmt.Errorf("expected new column name after TO, got %s", p.curTok.Literal)
+		}
+		stmt.Action = ast.AlterRenameColumn
+		stmt.NewName = strings.ToLower(p.curTok.Literal)
+		p.nextToken()
+
+	default:
+		return nil, fmt.Errorf("expected ADD, DROP or RENAME after table name, got %s", p.curTok.Literal)
+	}
+
+	// Semicolon (optional)
+	if p.curTok.Type == lexer.SEMICOLON {
+		p.nextToken()
+	}
+
+	return stmt, nil
+}
@@ -0,0 +1,58 @@
+package parser
+
+import (
+	"fmt"
+
+	"github.com/leengari/mini-rdbms/internal/parser/ast"
+	"github.com/leengari/mini-rdbms/internal/parser/lexer"
+)
+
+// parseTransactionControl parses BEGIN, COMMIT and ROLLBACK statements
+// Grammar: (BEGIN | COMMIT | ROLLBACK) [TRANSACTION] [;]
+func (p *Parser) parseTransactionControl() (ast.Statement, error) {
+	var stmt ast.Statement
+	switch p.curTok.Type {
+	case lexer.BEGIN:
+		stmt = &ast.BeginStatement{}
+	case lexer.COMMIT:
+		stmt = &ast.CommitStatement{}
+	case lexer.ROLLBACK:
+		stmt = &ast.RollbackStatement{}
+	default:
+		return nil, fmt.Errorf("expected BEGIN, COMMIT or ROLLBACK, got %s", p.curTok.Literal)
+	}
+	p.nextToken()
+
+	// TRANSACTION keyword (optional)
+	if p.curTok.Type == lexer.TRANSACTION {
+		p.nextToken()
+	}
+
+	// Semicolon (optional)
+	if p.curTok.Type == lexer.SEMICOLON {
+		p.nextToken()
+	}
+
+	if p.curTok.Type != lexer.EOF {
+		return nil, fmt.Errorf("unexpected token after %s: %s", stmt.TokenLiteral(), p.curTok.Literal)
+	}
+
+	return stmt, nil
+}
+
+// parseCheckpoint parses a CHECKPOINT statement
+// Grammar: CHECKPOINT [;]
+func (p *Parser) parseCheckpoint() (ast.Statement, error) {
+	p.nextToken()
+
+	// Semicolon (optional)
+	if p.curTok.Type == lexer.SEMICOLON {
+		p.nextToken()
+	}
+
+	if p.curTok.Type != lexer.EOF {
+		return nil, fmt.Errorf("unexpected token after CHECKPOINT: %s", p.curTok.Literal)
+	}
+
+	return &ast.CheckpointStatement{}, nil
+}
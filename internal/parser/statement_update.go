@@ -9,8 +9,8 @@ import (
 )
 
 // parseUpdate parses an UPDATE statement
-// Grammar: UPDATE table_name SET col1 = val1, col2 = val2 [WHERE condition]
-// Example: UPDATE users SET email = 'new@test.com', active = true WHERE id = 5
+// Grammar: UPDATE table_name SET col1 = expr1, col2 = expr2 [WHERE condition] [RETURNING fields]
+// Example: UPDATE users SET email = 'new@test.com', visits = visits + 1 WHERE id = 5
 func (p *Parser) parseUpdate() (*ast.UpdateStatement, error) {
 	stmt := &ast.UpdateStatement{
 		Updates: make(map[string]ast.Expression),
@@ -52,16 +52,12 @@ func (p *Parser) parseUpdate() (*ast.UpdateStatement, error) {
 		}
 		p.nextToken()
 
-		// Value (literal)
-		val, err := p.parseAtom()
+		// Value (literal or expression over the row's current values)
+		val, err := p.parseScalarExpression()
 		if err != nil {
 			return nil, fmt.Errorf("failed to parse value in SET clause: %w", err)
 		}
-		lit, ok := val.(*ast.Literal)
-		if !ok {
-			return nil, fmt.Errorf("expected literal value in SET clause")
-		}
-		stmt.Updates[colName] = lit
+		stmt.Updates[colName] = val
 
 		// Check for comma (more updates) or end of SET clause
 		if p.curTok.Type == lexer.COMMA {
@@ -83,6 +79,13 @@ func (p *Parser) parseUpdate() (*ast.UpdateStatement, error) {
 		stmt.Where = expr
 	}
 
+	// RETURNING clause (optional)
+	returning, err := p.parseReturning()
+	if err != nil {
+		return nil, err
+	}
+	stmt.Returning = returning
+
 	// Semicolon (optional)
 	if p.curTok.Type == lexer.SEMICOLON {
 		p.nextToken()
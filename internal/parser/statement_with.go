@@ -0,0 +1,124 @@
+package parser
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/leengari/mini-rdbms/internal/parser/ast"
+	"github.com/leengari/mini-rdbms/internal/parser/lexer"
+)
+
+// parseWith parses a query preceded by common table expressions
+// Grammar: WITH [RECURSIVE] name [(columns)] AS ( SELECT ... [UNION [ALL] SELECT ...] ) [, ...] query
+func (p *Parser) parseWith() (ast.Statement, error) {
+	with := &ast.WithClause{}
+
+	// WITH keyword - already consumed by Parse()
+	p.nextToken()
+	if p.curTok.Type == lexer.RECURSIVE {
+		with.Recursive = true
+		p.nextToken()
+	}
+
+	for {
+		cte, err := p.parseCommonTableExpression()
+		if err != nil {
+			return nil, err
+		}
+		with.Tables = append(with.Tables, cte)
+
+		if p.curTok.Type != lexer.COMMA {
+			break
+		}
+		p.nextToken()
+	}
+
+	if p.curTok.Type != lexer.SELECT {
+		return nil, fmt.Errorf("expected SELECT after WITH clause, got %s", p.curTok.Literal)
+	}
+	query, err := p.parseQuery()
+	if err != nil {
+		return nil, err
+	}
+	switch q := query.(type) {
+	case *ast.SelectStatement:
+		q.With = with
+	case *ast.SetOperationStatement:
+		q.With = with
+	}
+
+	return query, nil
+}
+
+// parseCommonTableExpression parses one named query of a WITH clause
+// Grammar: name [(column, ...)] AS ( SELECT ... [UNION [ALL] SELECT ...] )
+func (p *Parser) parseCommonTableExpression() (*ast.CommonTableExpression, error) {
+	if p.curTok.Type != lexer.IDENTIFIER {
+		return nil, fmt.Errorf("expected common table expression name, got %s", p.curTok.Literal)
+	}
+	cte := &ast.CommonTableExpression{
+		Name: &ast.Identifier{TokenLiteralValue: p.curTok.Literal, Value: p.curTok.Literal},
+	}
+	p.nextToken()
+
+	// Optional column names
+	if p.curTok.Type == lexer.PAREN_OPEN {
+		p.nextToken()
+		for {
+			if !isIdentifierOrKeyword(p.curTok.Type) {
+				return nil, fmt.Errorf("expected column name in %s, got %s", cte.Name.Value, p.curTok.Literal)
+			}
+			cte.Columns = append(cte.Columns, strings.ToLower(p.curTok.Literal))
+			p.nextToken()
+
+			if p.curTok.Type != lexer.COMMA {
+				break
+			}
+			p.nextToken()
+		}
+		if p.curTok.Type != lexer.PAREN_CLOSE {
+			return nil, fmt.Errorf("expected ) after column names of %s, got %s", cte.Name.Value, p.curTok.Literal)
+		}
+		p.nextToken()
+	}
+
+	if p.curTok.Type != lexer.AS {
+		return nil, fmt.Errorf("expected AS after %s, got %s", cte.Name.Value, p.curTok.Literal)
+	}
+	p.nextToken()
+
+	if p.curTok.Type != lexer.PAREN_OPEN || p.peekTok.Type != lexer.SELECT {
+		return nil, fmt.Errorf("expected (SELECT ...) after AS, got %s", p.curTok.Literal)
+	}
+	p.nextToken() // (
+
+	query, err := p.parseSelect()
+	if err != nil {
+		return nil, fmt.Errorf("invalid query for %s: %w", cte.Name.Value, err)
+	}
+	cte.Query = query
+
+	// Optional second term: the recursive term of a WITH RECURSIVE query
+	if p.curTok.Type == lexer.UNION {
+		p.nextToken()
+		if p.curTok.Type == lexer.ALL {
+			cte.UnionAll = true
+			p.nextToken()
+		}
+		if p.curTok.Type != lexer.SELECT {
+			return nil, fmt.Errorf("expected SELECT after UNION, got %s", p.curTok.Literal)
+		}
+		recursive, err := p.parseSelect()
+		if err != nil {
+			return nil, fmt.Errorf("invalid query for %s: %w", cte.Name.Value, err)
+		}
+		cte.Recursive = recursive
+	}
+
+	if p.curTok.Type != lexer.PAREN_CLOSE {
+		return nil, fmt.Errorf("expected ) after query for %s, got %s", cte.Name.Value, p.curTok.Literal)
+	}
+	p.nextToken()
+
+	return cte, nil
+}
@@ -0,0 +1,67 @@
+package parser
+
+import (
+	"fmt"
+
+	"github.com/leengari/mini-rdbms/internal/parser/ast"
+	"github.com/leengari/mini-rdbms/internal/parser/lexer"
+)
+
+// parseSubquery parses a parenthesized SELECT
+// Grammar: ( SELECT ... )
+// The current token is '(' and the next token is SELECT.
+func (p *Parser) parseSubquery() (*ast.SelectStatement, error) {
+	p.nextToken() // (
+
+	query, err := p.parseSelect()
+	if err != nil {
+		return nil, fmt.Errorf("invalid subquery: %w", err)
+	}
+
+	if p.curTok.Type != lexer.PAREN_CLOSE {
+		return nil, fmt.Errorf("expected ) after subquery, got %s", p.curTok.Literal)
+	}
+	p.nextToken()
+
+	return query, nil
+}
+
+// parseExists parses an existence test
+// Grammar: EXISTS ( SELECT ... )
+func (p *Parser) parseExists() (ast.Expression, error) {
+	p.nextToken() // EXISTS
+
+	if p.curTok.Type != lexer.PAREN_OPEN || p.peekTok.Type != lexer.SELECT {
+		return nil, fmt.Errorf("expected (SELECT ...) after EXISTS, got %s", p.curTok.Literal)
+	}
+	query, err := p.parseSubquery()
+	if err != nil {
+		return nil, err
+	}
+
+	return &ast.ExistsExpression{Query: query}, nil
+}
+
+// parseDerivedTable parses a subquery read as a table in FROM or JOIN
+// Grammar: ( SELECT ... ) [AS] alias
+// The alias is required: it names the table in column references (e.g. t.total).
+func (p *Parser) parseDerivedTable() (*ast.SelectStatement, *ast.Identifier, error) {
+	if p.peekTok.Type != lexer.SELECT {
+		return nil, nil, fmt.Errorf("expected SELECT after (, got %s", p.peekTok.Literal)
+	}
+	query, err := p.parseSubquery()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if p.curTok.Type == lexer.AS {
+		p.nextToken()
+	}
+	if p.curTok.Type != lexer.IDENTIFIER {
+		return nil, nil, fmt.Errorf("subquery in FROM must have an alias, got %s", p.curTok.Literal)
+	}
+	alias := &ast.Identifier{TokenLiteralValue: p.curTok.Literal, Value: p.curTok.Literal}
+	p.nextToken()
+
+	return query, alias, nil
+}
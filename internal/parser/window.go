@@ -0,0 +1,178 @@
+package parser
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/leengari/mini-rdbms/internal/parser/ast"
+	"github.com/leengari/mini-rdbms/internal/parser/lexer"
+)
+
+// parseOver parses the window of a window function call
+// Grammar: OVER ( [PARTITION BY column [, ...]] [ORDER BY keys] [frame] )
+// The current token is OVER, following the function call.
+func (p *Parser) parseOver(call *ast.FunctionCall) (*ast.WindowExpression, error) {
+	window := &ast.WindowExpression{Function: call}
+	p.nextToken() // OVER
+
+	if p.curTok.Type != lexer.PAREN_OPEN {
+		return nil, fmt.Errorf("expected ( after OVER, got %s", p.curTok.Literal)
+	}
+	p.nextToken()
+
+	// PARTITION BY (optional)
+	if p.curTok.Type == lexer.PARTITION {
+		p.nextToken()
+		if p.curTok.Type != lexer.BY {
+			return nil, fmt.Errorf("expected BY after PARTITION, got %s", p.curTok.Literal)
+		}
+		p.nextToken()
+
+		for {
+			col, err := p.parseQualifiedIdentifier()
+			if err != nil {
+				return nil, fmt.Errorf("invalid PARTITION BY column: %w", err)
+			}
+			window.PartitionBy = append(window.PartitionBy, col)
+
+			if p.curTok.Type != lexer.COMMA {
+				break
+			}
+			p.nextToken()
+		}
+	}
+
+	// ORDER BY (optional)
+	if p.curTok.Type == lexer.ORDER {
+		orderBy, err := p.parseOrderBy()
+		if err != nil {
+			return nil, err
+		}
+		window.OrderBy = orderBy
+	}
+
+	// Frame (optional)
+	if p.curTok.Type == lexer.ROWS || p.curTok.Type == lexer.RANGE {
+		frame, err := p.parseWindowFrame()
+		if err != nil {
+			return nil, err
+		}
+		window.Frame = frame
+	}
+
+	if p.curTok.Type != lexer.PAREN_CLOSE {
+		return nil, fmt.Errorf("expected ) after window of %s, got %s", call.Name, p.curTok.Literal)
+	}
+	p.nextToken()
+
+	return window, nil
+}
+
+// parseWindowFrame parses the frame of a window
+// Grammar: ROWS|RANGE BETWEEN bound AND bound | ROWS|RANGE bound
+// A frame with a single bound ends at the current row.
+func (p *Parser) parseWindowFrame() (*ast.WindowFrame, error) {
+	frame := &ast.WindowFrame{Mode: "RANGE"}
+	if p.curTok.Type == lexer.ROWS {
+		frame.Mode = "ROWS"
+	}
+	p.nextToken()
+
+	between := p.curTok.Type == lexer.BETWEEN
+	if between {
+		p.nextToken()
+	}
+
+	start, err := p.parseFrameBound()
+	if err != nil {
+		return nil, err
+	}
+	frame.Start = start
+	frame.End = ast.FrameBound{Kind: ast.CurrentRow}
+
+	if between {
+		if p.curTok.Type != lexer.AND {
+			return nil, fmt.Errorf("expected AND in window frame, got %s", p.curTok.Literal)
+		}
+		p.nextToken()
+
+		end, err := p.parseFrameBound()
+		if err != nil {
+			return nil, err
+		}
+		frame.End = end
+	}
+
+	// The frame cannot start after it ends
+	if frame.Start.Kind == ast.UnboundedFollowing {
+		return nil, fmt.Errorf("window frame cannot start at UNBOUNDED FOLLOWING")
+	}
+	if frame.End.Kind == ast.UnboundedPreceding {
+		return nil, fmt.Errorf("window frame cannot end at UNBOUNDED PRECEDING")
+	}
+	if boundPosition(frame.Start.Kind) > boundPosition(frame.End.Kind) {
+		return nil, fmt.Errorf("window frame cannot start at %s and end at %s", frame.Start, frame.End)
+	}
+
+	return frame, nil
+}
+
+// parseFrameBound parses one bound of a window frame
+// Grammar: UNBOUNDED PRECEDING | UNBOUNDED FOLLOWING | CURRENT ROW | n PRECEDING | n FOLLOWING
+func (p *Parser) parseFrameBound() (ast.FrameBound, error) {
+	switch p.curTok.Type {
+	case lexer.UNBOUNDED:
+		p.nextToken()
+		switch p.curTok.Type {
+		case lexer.PRECEDING:
+			p.nextToken()
+			return ast.FrameBound{Kind: ast.UnboundedPreceding}, nil
+		case lexer.FOLLOWING:
+			p.nextToken()
+			return ast.FrameBound{Kind: ast.UnboundedFollowing}, nil
+		}
+		return ast.FrameBound{}, fmt.Errorf("expected PRECEDING or FOLLOWING after UNBOUNDED, got %s", p.curTok.Literal)
+
+	case lexer.CURRENT:
+		p.nextToken()
+		if p.curTok.Type != lexer.ROW {
+			return ast.FrameBound{}, fmt.Errorf("expected ROW after CURRENT, got %s", p.curTok.Literal)
+		}
+		p.nextToken()
+		return ast.FrameBound{Kind: ast.CurrentRow}, nil
+
+	case lexer.NUMBER:
+		offset, err := strconv.Atoi(p.curTok.Literal)
+		if err != nil || offset < 0 {
+			return ast.FrameBound{}, fmt.Errorf("window frame offset must be a non-negative integer, got %s", p.curTok.Literal)
+		}
+		p.nextToken()
+		switch p.curTok.Type {
+		case lexer.PRECEDING:
+			p.nextToken()
+			return ast.FrameBound{Kind: ast.Preceding, Offset: offset}, nil
+		case lexer.FOLLOWING:
+			p.nextToken()
+			return ast.FrameBound{Kind: ast.Following, Offset: offset}, nil
+		}
+		return ast.FrameBound{}, fmt.Errorf("expected PRECEDING or FOLLOWING after %d, got %s", offset, p.curTok.Literal)
+	}
+
+	return ast.FrameBound{}, fmt.Errorf("expected window frame bound, got %s", p.curTok.Literal)
+}
+
+// boundPosition orders frame bound kinds from the start of a partition to its end
+func boundPosition(kind ast.FrameBoundKind) int {
+	switch kind {
+	case ast.UnboundedPreceding:
+		return 0
+	case ast.Preceding:
+		return 1
+	case ast.CurrentRow:
+		return 2
+	case ast.Following:
+		return 3
+	default:
+		return 4
+	}
+}
@@ -0,0 +1,93 @@
+package plan
+
+import (
+	"fmt"
+
+	"github.com/leengari/mini-rdbms/internal/domain/data"
+	"github.com/leengari/mini-rdbms/internal/domain/schema"
+)
+
+// CommonTable is a query named in a WITH clause (a common table expression)
+// The planner builds it once; every CTEScanNode that reads it shares one result,
+// which the executor computes the first time the CTE is read. A recursive CTE
+// also has a recursive term, run over the rows of the previous iteration (the
+// working table) until it produces no new rows.
+type CommonTable struct {
+	Name          string
+	Columns       []schema.Column // Output columns, named by the CTE's column list or the anchor's SELECT list
+	Anchor        *SelectNode     // The query, or the non-recursive term of a UNION
+	AnchorKeys    []string        // Key of each output column in Anchor's projected rows
+	Recursive     *SelectNode     // Term after UNION [ALL] (nil when there is none)
+	RecursiveKeys []string        // Key of each output column in Recursive's projected rows
+	UnionAll      bool            // Keep duplicate rows (UNION ALL) instead of discarding them (UNION)
+	SelfReference bool            // The recursive term reads the working table
+
+	run     func() ([]data.Row, error)
+	rows    []data.Row
+	done    bool
+	working []data.Row
+}
+
+// Bind sets the function that computes the CTE's rows
+// Any rows cached from an earlier execution are discarded.
+func (c *CommonTable) Bind(run func() ([]data.Row, error)) {
+	c.run = run
+	c.rows = nil
+	c.done = false
+	c.working = nil
+}
+
+// Rows returns the CTE's rows, keyed by output column name, computing them on first use
+func (c *CommonTable) Rows() ([]data.Row, error) {
+	if c.run == nil {
+		return nil, fmt.Errorf("common table expression %s was not bound for execution", c.Name)
+	}
+	if !c.done {
+		rows, err := c.run()
+		if err != nil {
+			return nil, err
+		}
+		c.rows = rows
+		c.done = true
+	}
+	return c.rows, nil
+}
+
+// Working returns the rows produced by the previous iteration of a recursive CTE
+func (c *CommonTable) Working() []data.Row {
+	return c.working
+}
+
+// SetWorking sets the rows the next iteration of the recursive term reads
+func (c *CommonTable) SetWorking(rows []data.Row) {
+	c.working = rows
+}
+
+// CTEScanNode reads the rows of a common table expression by name
+// Inside the CTE's own recursive term it reads the working table instead.
+type CTEScanNode struct {
+	Name    string // Name the query uses for the CTE
+	Table   *CommonTable
+	Working bool // Read the working table (a self-reference in the recursive term)
+
+	metadata map[string]any
+}
+
+func NewCTEScanNode(name string, table *CommonTable, working bool) *CTEScanNode {
+	return &CTEScanNode{Name: name, Table: table, Working: working}
+}
+
+func (n *CTEScanNode) Children() []Node {
+	return nil
+}
+
+func (n *CTEScanNode) Metadata() map[string]any {
+	if n.metadata == nil {
+		n.metadata = make(map[string]any)
+	}
+	return n.metadata
+}
+
+func (n *CTEScanNode) NodeType() string {
+	return "CTE_SCAN"
+}
@@ -2,6 +2,7 @@ package plan
 
 import (
 	"github.com/leengari/mini-rdbms/internal/domain/data"
+	"github.com/leengari/mini-rdbms/internal/domain/schema"
 	"github.com/leengari/mini-rdbms/internal/domain/transaction"
 	"github.com/leengari/mini-rdbms/internal/query/operations/join"
 	"github.com/leengari/mini-rdbms/internal/query/operations/projection"
@@ -22,7 +23,7 @@ type Node interface {
 // ScanNode represents a table scan operation (leaf node)
 type ScanNode struct {
 	TableName   string
-	Predicate   func(data.Row) bool
+	Predicate   func(data.Row) (bool, error)
 	Transaction *transaction.Transaction
 	
 	metadata map[string]any
@@ -43,11 +44,91 @@ func (n *ScanNode) NodeType() string {
 	return "SCAN"
 }
 
+// IndexScanNode reads the rows whose indexed column equals one of Values, or lies
+// within Range when it is set (leaf node)
+// Used for equality, IN-list and BETWEEN predicates on indexed columns. Predicate is
+// the full WHERE clause and is re-checked on every row fetched through the index.
+//
+// When Index is set the scan reads that ordered index instead (CREATE INDEX): the
+// entries in IndexRange, returned in key order. Ordered is set when the planner relies
+// on that order in place of an ORDER BY sort.
+type IndexScanNode struct {
+	TableName   string
+	Column      string          // Indexed column
+	Values      []interface{}   // Lookup keys (one for =, several for IN)
+	Range       *KeyRange       // Key range for BETWEEN (Values is unused when set)
+	Index       string          // Ordered index to read (Column, Values and Range are unused when set)
+	IndexRange  data.IndexRange // Entries of the ordered index to read
+	Descending  bool            // Read the ordered index from its largest key down
+	Ordered     bool            // The rows must come out in index order
+	Predicate   func(data.Row) (bool, error)
+	Transaction *transaction.Transaction
+
+	metadata map[string]any
+}
+
+// KeyRange is an inclusive range of index keys (column BETWEEN Low AND High)
+type KeyRange struct {
+	Low  interface{}
+	High interface{}
+}
+
+func (n *IndexScanNode) Children() []Node {
+	return nil // Leaf node has no children
+}
+
+func (n *IndexScanNode) Metadata() map[string]any {
+	if n.metadata == nil {
+		n.metadata = make(map[string]any)
+	}
+	return n.metadata
+}
+
+func (n *IndexScanNode) NodeType() string {
+	return "INDEX_SCAN"
+}
+
+// SubqueryScanNode reads the rows of a SELECT as a table (a derived table in FROM or JOIN)
+// Its child is the planned subquery; each output row holds the child's columns under
+// the names in Columns.
+type SubqueryScanNode struct {
+	Alias   string          // Name of the derived table
+	Columns []schema.Column // Output columns, in the subquery's SELECT-list order
+	Keys    []string        // Key of each output column in the child's projected rows
+
+	child    *SelectNode
+	metadata map[string]any
+}
+
+func NewSubqueryScanNode(alias string, child *SelectNode, columns []schema.Column, keys []string) *SubqueryScanNode {
+	return &SubqueryScanNode{Alias: alias, Columns: columns, Keys: keys, child: child}
+}
+
+func (n *SubqueryScanNode) Child() *SelectNode {
+	return n.child
+}
+
+func (n *SubqueryScanNode) Children() []Node {
+	return []Node{n.child}
+}
+
+func (n *SubqueryScanNode) Metadata() map[string]any {
+	if n.metadata == nil {
+		n.metadata = make(map[string]any)
+	}
+	return n.metadata
+}
+
+func (n *SubqueryScanNode) NodeType() string {
+	return "SUBQUERY_SCAN"
+}
+
 // JoinNode represents a JOIN operation (composite node with two children)
 type JoinNode struct {
 	JoinType    join.JoinType
 	LeftOnCol   string
 	RightOnCol  string
+	Algorithm   join.Algorithm // Chosen by the planner; ExecutionConfig.JoinAlgorithm overrides it
 	
 	// Tree structure - JOIN has two children
 	left  Node
@@ -63,6 +144,7 @@ func NewJoinNode(left, right Node, joinType join.JoinType, leftCol, rightCol str
 		JoinType:   joinType,
 		LeftOnCol:  leftCol,
 		RightOnCol: rightCol,
+		Algorithm:  join.AlgorithmHash,
 	}
 }
 
@@ -89,15 +171,84 @@ func (n *JoinNode) NodeType() string {
 	return "JOIN"
 }
 
+// SetOperator is the operation a SetOperationNode applies
+type SetOperator string
+
+const (
+	SetUnion     SetOperator = "UNION"     // Rows of either query
+	SetIntersect SetOperator = "INTERSECT" // Rows of the left query that the right query also returns
+	SetExcept    SetOperator = "EXCEPT"    // Rows of the left query that the right query does not return
+)
+
+// SetOperationNode combines the rows of two queries (composite node with two children)
+// Each child is a planned SELECT (or another set operation); the output rows hold the
+// children's columns, by position, under the names in Columns. Without All the output
+// rows are distinct; with All duplicates are kept (INTERSECT ALL and EXCEPT ALL match
+// rows one for one).
+type SetOperationNode struct {
+	Operator  SetOperator
+	All       bool
+	Columns   []schema.Column // Output columns, named after the left query's SELECT list
+	LeftKeys  []string        // Key of each output column in the left child's projected rows
+	RightKeys []string        // Key of each output column in the right child's projected rows
+
+	left     *SelectNode
+	right    *SelectNode
+	metadata map[string]any
+}
+
+func NewSetOperationNode(op SetOperator, all bool, left, right *SelectNode, columns []schema.Column, leftKeys, rightKeys []string) *SetOperationNode {
+	return &SetOperationNode{
+		Operator:  op,
+		All:       all,
+		Columns:   columns,
+		LeftKeys:  leftKeys,
+		RightKeys: rightKeys,
+		left:      left,
+		right:     right,
+	}
+}
+
+func (n *SetOperationNode) Left() *SelectNode {
+	return n.left
+}
+
+func (n *SetOperationNode) Right() *SelectNode {
+	return n.right
+}
+
+func (n *SetOperationNode) Children() []Node {
+	return []Node{n.left, n.right}
+}
+
+func (n *SetOperationNode) Metadata() map[string]any {
+	if n.metadata == nil {
+		n.metadata = make(map[string]any)
+	}
+	return n.metadata
+}
+
+// NodeType names the operation, e.g. UNION or EXCEPT_ALL
+func (n *SetOperationNode) NodeType() string {
+	if n.All {
+		return string(n.Operator) + "_ALL"
+	}
+	return string(n.Operator)
+}
+
 // SelectNode represents a SELECT operation
 type SelectNode struct {
 	TableName string
 	// Predicate filters rows. If nil, all rows are selected.
-	Predicate func(data.Row) bool
+	Predicate func(data.Row) (bool, error)
 	// Projection defines which columns to return.
 	Projection *projection.Projection
 	// Transaction context
 	Transaction *transaction.Transaction
+	// Subqueries used in the WHERE clause, SELECT list or HAVING
+	Subqueries []*Subquery
+	// Common table expressions defined by the statement's WITH clause
+	CommonTables []*CommonTable
 	
 	// Tree structure - children are JOINs or other operations
 	children []Node
@@ -125,11 +276,15 @@ func (n *SelectNode) NodeType() string {
 }
 
 // InsertNode represents an INSERT operation
+// The rows come from VALUES (Rows), or from its child: a SubqueryScanNode reading
+// INSERT ... SELECT's query under the names and types of the target columns.
 type InsertNode struct {
 	TableName string
-	Row       data.Row // The row to insert (already parsed/converted)
+	Rows      []data.Row // The rows to insert (already parsed/converted)
 	// Transaction context
 	Transaction *transaction.Transaction
+	// RETURNING fields, evaluated over each inserted row (empty without RETURNING)
+	Returning []ComputedColumn
 	
 	children []Node
 	metadata map[string]any
@@ -139,6 +294,10 @@ func (n *InsertNode) Children() []Node {
 	return n.children
 }
 
+func (n *InsertNode) AddChild(child Node) {
+	n.children = append(n.children, child)
+}
+
 func (n *InsertNode) Metadata() map[string]any {
 	if n.metadata == nil {
 		n.metadata = make(map[string]any)
@@ -153,10 +312,14 @@ func (n *InsertNode) NodeType() string {
 // UpdateNode represents an UPDATE operation
 type UpdateNode struct {
 	TableName string
-	Predicate func(data.Row) bool
-	Updates   data.Row // Map of columns to update
+	Predicate func(data.Row) (bool, error)
+	Updates   schema.Assignments // New value of each updated column, computed from the old row
 	// Transaction context
 	Transaction *transaction.Transaction
+	// Subqueries used in the WHERE clause or SET expressions
+	Subqueries []*Subquery
+	// RETURNING fields, evaluated over each updated row's new values (empty without RETURNING)
+	Returning []ComputedColumn
 	
 	children []Node
 	metadata map[string]any
@@ -166,6 +329,10 @@ func (n *UpdateNode) Children() []Node {
 	return n.children
 }
 
+func (n *UpdateNode) AddChild(child Node) {
+	n.children = append(n.children, child)
+}
+
 func (n *UpdateNode) Metadata() map[string]any {
 	if n.metadata == nil {
 		n.metadata = make(map[string]any)
@@ -180,9 +347,13 @@ func (n *UpdateNode) NodeType() string {
 // DeleteNode represents a DELETE operation
 type DeleteNode struct {
 	TableName string
-	Predicate func(data.Row) bool
+	Predicate func(data.Row) (bool, error)
 	// Transaction context
 	Transaction *transaction.Transaction
+	// Subqueries used in the WHERE clause
+	Subqueries []*Subquery
+	// RETURNING fields, evaluated over each deleted row (empty without RETURNING)
+	Returning []ComputedColumn
 	
 	children []Node
 	metadata map[string]any
@@ -192,6 +363,10 @@ func (n *DeleteNode) Children() []Node {
 	return n.children
 }
 
+func (n *DeleteNode) AddChild(child Node) {
+	n.children = append(n.children, child)
+}
+
 func (n *DeleteNode) Metadata() map[string]any {
 	if n.metadata == nil {
 		n.metadata = make(map[string]any)
@@ -202,3 +377,341 @@ func (n *DeleteNode) Metadata() map[string]any {
 func (n *DeleteNode) NodeType() string {
 	return "DELETE"
 }
+
+// CreateTableNode represents a CREATE TABLE operation
+type CreateTableNode struct {
+	TableName string
+	Schema    *schema.TableSchema // Validated schema for the new table
+	// Transaction context
+	Transaction *transaction.Transaction
+
+	metadata map[string]any
+}
+
+func (n *CreateTableNode) Children() []Node {
+	return nil
+}
+
+func (n *CreateTableNode) Metadata() map[string]any {
+	if n.metadata == nil {
+		n.metadata = make(map[string]any)
+	}
+	return n.metadata
+}
+
+func (n *CreateTableNode) NodeType() string {
+	return "CREATE_TABLE"
+}
+
+// DropTableNode represents a DROP TABLE operation
+type DropTableNode struct {
+	TableName string
+	IfExists  bool // Don't fail if the table is missing
+	// Transaction context
+	Transaction *transaction.Transaction
+
+	metadata map[string]any
+}
+
+func (n *DropTableNode) Children() []Node {
+	return nil
+}
+
+func (n *DropTableNode) Metadata() map[string]any {
+	if n.metadata == nil {
+		n.metadata = make(map[string]any)
+	}
+	return n.metadata
+}
+
+func (n *DropTableNode) NodeType() string {
+	return "DROP_TABLE"
+}
+
+// AlterTableNode represents an ALTER TABLE operation
+type AlterTableNode struct {
+	TableName string
+	Action    string         // "ADD COLUMN", "DROP COLUMN", "RENAME COLUMN" or "RENAME TO"
+	Column    *schema.Column // Column to add (ADD COLUMN only)
+	OldName   string         // Column to drop or rename
+	NewName   string         // New column name (RENAME COLUMN) or table name (RENAME TO)
+	// Transaction context
+	Transaction *transaction.Transaction
+
+	metadata map[string]any
+}
+
+func (n *AlterTableNode) Children() []Node {
+	return nil
+}
+
+func (n *AlterTableNode) Metadata() map[string]any {
+	if n.metadata == nil {
+		n.metadata = make(map[string]any)
+	}
+	return n.metadata
+}
+
+func (n *AlterTableNode) NodeType() string {
+	return "ALTER_TABLE"
+}
+
+// CreateIndexNode represents a CREATE INDEX operation
+type CreateIndexNode struct {
+	IndexName string
+	TableName string
+	Columns   []string // Key columns, most significant first
+	Unique    bool
+	// Transaction context
+	Transaction *transaction.Transaction
+
+	metadata map[string]any
+}
+
+func (n *CreateIndexNode) Children() []Node {
+	return nil
+}
+
+func (n *CreateIndexNode) Metadata() map[string]any {
+	if n.metadata == nil {
+		n.metadata = make(map[string]any)
+	}
+	return n.metadata
+}
+
+func (n *CreateIndexNode) NodeType() string {
+	return "CREATE_INDEX"
+}
+
+// DropIndexNode represents a DROP INDEX operation
+type DropIndexNode struct {
+	IndexName string
+	TableName string // Table the index belongs to (empty when IfExists and it is missing)
+	IfExists  bool   // Don't fail if the index is missing
+	// Transaction context
+	Transaction *transaction.Transaction
+
+	metadata map[string]any
+}
+
+func (n *DropIndexNode) Children() []Node {
+	return nil
+}
+
+func (n *DropIndexNode) Metadata() map[string]any {
+	if n.metadata == nil {
+		n.metadata = make(map[string]any)
+	}
+	return n.metadata
+}
+
+func (n *DropIndexNode) NodeType() string {
+	return "DROP_INDEX"
+}
+
+// SortKey is one ORDER BY key
+type SortKey struct {
+	Table      string // Optional table qualifier (for joined rows)
+	Column     string
+	Descending bool
+	NullsFirst bool // Resolved NULLS FIRST/LAST (default: LAST for ASC, FIRST for DESC)
+}
+
+// SortNode orders the rows produced by its child
+type SortNode struct {
+	Keys []SortKey // Keys in priority order
+
+	child    Node
+	metadata map[string]any
+}
+
+func NewSortNode(child Node, keys []SortKey) *SortNode {
+	return &SortNode{child: child, Keys: keys}
+}
+
+func (n *SortNode) Child() Node {
+	return n.child
+}
+
+func (n *SortNode) Children() []Node {
+	return []Node{n.child}
+}
+
+func (n *SortNode) Metadata() map[string]any {
+	if n.metadata == nil {
+		n.metadata = make(map[string]any)
+	}
+	return n.metadata
+}
+
+func (n *SortNode) NodeType() string {
+	return "SORT"
+}
+
+// LimitNode skips Offset rows of its child and returns at most Limit rows
+type LimitNode struct {
+	Limit  int // Maximum rows to return; -1 means no limit
+	Offset int // Rows to skip
+
+	child    Node
+	metadata map[string]any
+}
+
+func NewLimitNode(child Node, limit, offset int) *LimitNode {
+	return &LimitNode{child: child, Limit: limit, Offset: offset}
+}
+
+func (n *LimitNode) Child() Node {
+	return n.child
+}
+
+func (n *LimitNode) Children() []Node {
+	return []Node{n.child}
+}
+
+func (n *LimitNode) Metadata() map[string]any {
+	if n.metadata == nil {
+		n.metadata = make(map[string]any)
+	}
+	return n.metadata
+}
+
+func (n *LimitNode) NodeType() string {
+	return "LIMIT"
+}
+
+// FilterNode keeps the rows of its child that match Predicate
+// Used when the WHERE clause must run below another operation (e.g. before LIMIT over a JOIN).
+type FilterNode struct {
+	Predicate func(data.Row) (bool, error)
+
+	child    Node
+	metadata map[string]any
+}
+
+func NewFilterNode(child Node, predicate func(data.Row) (bool, error)) *FilterNode {
+	return &FilterNode{child: child, Predicate: predicate}
+}
+
+func (n *FilterNode) Child() Node {
+	return n.child
+}
+
+func (n *FilterNode) Children() []Node {
+	return []Node{n.child}
+}
+
+func (n *FilterNode) Metadata() map[string]any {
+	if n.metadata == nil {
+		n.metadata = make(map[string]any)
+	}
+	return n.metadata
+}
+
+func (n *FilterNode) NodeType() string {
+	return "FILTER"
+}
+
+// GroupKey is one GROUP BY column
+// Output rows hold the group value under both "column" and "table.column".
+type GroupKey struct {
+	Table  string            // Table that defines the column
+	Column string
+	Type   schema.ColumnType // Column type from the source table
+}
+
+// AggregateSpec describes one aggregate function computed per group
+type AggregateSpec struct {
+	Func     string                              // COUNT, SUM, AVG, MIN or MAX
+	Arg      func(data.Row) (interface{}, error) // Computes the argument from a row (nil for COUNT(*))
+	Distinct bool                                // Only aggregate distinct argument values
+	Name     string                              // Output column name (e.g. "COUNT(*)", "SUM(orders.amount)")
+	ArgType  schema.ColumnType                   // Type of the argument ("" for COUNT(*))
+}
+
+// ResultType returns the column type of the aggregate's result
+func (s AggregateSpec) ResultType() schema.ColumnType {
+	switch s.Func {
+	case "COUNT":
+		return schema.ColumnTypeInt
+	case "AVG":
+		return schema.ColumnTypeFloat
+	default:
+		return s.ArgType
+	}
+}
+
+// AggregateNode groups the rows of its child and computes aggregates per group
+// Output rows contain the group columns and one column per aggregate, keyed by Name.
+// Without GROUP BY, all rows form a single group.
+type AggregateNode struct {
+	GroupBy    []GroupKey
+	Aggregates []AggregateSpec
+	// Having filters the aggregated rows. If nil, all groups are returned.
+	Having func(data.Row) (bool, error)
+
+	child    Node
+	metadata map[string]any
+}
+
+func NewAggregateNode(child Node, groupBy []GroupKey, aggregates []AggregateSpec, having func(data.Row) (bool, error)) *AggregateNode {
+	return &AggregateNode{child: child, GroupBy: groupBy, Aggregates: aggregates, Having: having}
+}
+
+func (n *AggregateNode) Child() Node {
+	return n.child
+}
+
+func (n *AggregateNode) Children() []Node {
+	return []Node{n.child}
+}
+
+func (n *AggregateNode) Metadata() map[string]any {
+	if n.metadata == nil {
+		n.metadata = make(map[string]any)
+	}
+	return n.metadata
+}
+
+func (n *AggregateNode) NodeType() string {
+	return "AGGREGATE"
+}
+
+// ComputedColumn is a SELECT-list expression evaluated for every row
+type ComputedColumn struct {
+	Name string                              // Output column name (the alias, or the expression text)
+	Type schema.ColumnType                   // Inferred result type
+	Eval func(data.Row) (interface{}, error) // Computes the value from the row
+}
+
+// ComputeNode adds computed columns to every row of its child
+// Runs after any aggregate and before ORDER BY, so sort keys may name computed columns.
+type ComputeNode struct {
+	Columns []ComputedColumn
+
+	child    Node
+	metadata map[string]any
+}
+
+func NewComputeNode(child Node, columns []ComputedColumn) *ComputeNode {
+	return &ComputeNode{child: child, Columns: columns}
+}
+
+func (n *ComputeNode) Child() Node {
+	return n.child
+}
+
+func (n *ComputeNode) Children() []Node {
+	return []Node{n.child}
+}
+
+func (n *ComputeNode) Metadata() map[string]any {
+	if n.metadata == nil {
+		n.metadata = make(map[string]any)
+	}
+	return n.metadata
+}
+
+func (n *ComputeNode) NodeType() string {
+	return "COMPUTE"
+}
@@ -0,0 +1,51 @@
+package plan
+
+import (
+	"fmt"
+
+	"github.com/leengari/mini-rdbms/internal/domain/data"
+	"github.com/leengari/mini-rdbms/internal/domain/schema"
+)
+
+// Subquery is a SELECT nested in an expression (IN, EXISTS or a scalar subquery)
+// The planner builds Root as a subtree of its own; the executor binds a runner
+// before the statement executes. An uncorrelated subquery runs once and its rows
+// are reused; a correlated one runs for every outer row it is evaluated against.
+type Subquery struct {
+	Root       *SelectNode
+	Columns    []schema.Column // Output columns, in SELECT-list order
+	Keys       []string        // Key of each output column in Root's projected rows
+	Correlated bool            // References columns of an enclosing query
+	OuterTable string          // Qualifies the bare column names of the outer row (correlated only)
+
+	run  func(outer data.Row) ([]data.Row, error)
+	rows []data.Row
+	done bool
+}
+
+// Bind sets the function that executes the subquery for an outer row
+// Any rows cached from an earlier execution are discarded.
+func (s *Subquery) Bind(run func(outer data.Row) ([]data.Row, error)) {
+	s.run = run
+	s.rows = nil
+	s.done = false
+}
+
+// Rows returns the subquery's result for an outer row, keyed by output column name
+func (s *Subquery) Rows(outer data.Row) ([]data.Row, error) {
+	if s.run == nil {
+		return nil, fmt.Errorf("subquery was not bound for execution")
+	}
+	if s.Correlated {
+		return s.run(outer)
+	}
+	if !s.done {
+		rows, err := s.run(data.Row{})
+		if err != nil {
+			return nil, err
+		}
+		s.rows = rows
+		s.done = true
+	}
+	return s.rows, nil
+}
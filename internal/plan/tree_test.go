@@ -148,11 +148,17 @@ func TestNodeType(t *testing.T) {
 		expected string
 	}{
 		{&ScanNode{}, "SCAN"},
+		{&IndexScanNode{}, "INDEX_SCAN"},
 		{&JoinNode{}, "JOIN"},
 		{&SelectNode{}, "SELECT"},
 		{&InsertNode{}, "INSERT"},
 		{&UpdateNode{}, "UPDATE"},
 		{&DeleteNode{}, "DELETE"},
+		{&SortNode{}, "SORT"},
+		{&LimitNode{}, "LIMIT"},
+		{&FilterNode{}, "FILTER"},
+		{&AggregateNode{}, "AGGREGATE"},
+		{&ComputeNode{}, "COMPUTE"},
 	}
 	
 	for _, tt := range tests {
@@ -161,3 +167,43 @@ func TestNodeType(t *testing.T) {
 		}
 	}
 }
+
+// TestPrintTreeSortLimit verifies Sort and Limit nodes appear in the printed tree
+func TestPrintTreeSortLimit(t *testing.T) {
+	tx := transaction.NewTransaction()
+
+	scan := &ScanNode{TableName: "users", Transaction: tx}
+	sortNode := NewSortNode(scan, []SortKey{{Column: "name", Descending: true}})
+	limitNode := NewLimitNode(sortNode, 10, 0)
+	selectNode := &SelectNode{TableName: "users", Transaction: tx}
+	selectNode.AddChild(limitNode)
+
+	expected := "SELECT\n  LIMIT\n    SORT\n      SCAN\n"
+	if output := PrintTree(selectNode); output != expected {
+		t.Errorf("Expected tree:\n%s\ngot:\n%s", expected, output)
+	}
+}
+
+// TestSetOperationTree verifies set operation nodes are walked and printed with both inputs
+func TestSetOperationTree(t *testing.T) {
+	tx := transaction.NewTransaction()
+
+	left := &SelectNode{TableName: "users", Transaction: tx}
+	right := &SelectNode{TableName: "admins", Transaction: tx}
+	union := NewSetOperationNode(SetUnion, false, left, right, nil, nil, nil)
+	except := NewSetOperationNode(SetExcept, true, &SelectNode{}, &SelectNode{}, nil, nil, nil)
+	root := &SelectNode{}
+	root.AddChild(NewSortNode(union, nil))
+
+	if count := CountNodes(root); count != 5 {
+		t.Errorf("Expected 5 nodes, got %d", count)
+	}
+
+	expected := "SELECT\n  SORT\n    UNION\n      SELECT\n      SELECT\n"
+	if output := PrintTree(root); output != expected {
+		t.Errorf("Expected tree:\n%s\ngot:\n%s", expected, output)
+	}
+	if except.NodeType() != "EXCEPT_ALL" {
+		t.Errorf("Expected EXCEPT_ALL, got %s", except.NodeType())
+	}
+}
@@ -0,0 +1,74 @@
+package plan
+
+import (
+	"github.com/leengari/mini-rdbms/internal/domain/data"
+	"github.com/leengari/mini-rdbms/internal/domain/schema"
+)
+
+// WindowFunction describes one window function computed for every row
+// The rows are split into partitions by PartitionBy and ordered within each
+// partition by OrderBy; the function reads the rows of the current row's partition.
+type WindowFunction struct {
+	Name        string                              // Output column name (the alias, or the expression text)
+	Func        string                              // ROW_NUMBER, RANK, DENSE_RANK, LAG, LEAD, FIRST_VALUE, LAST_VALUE or an aggregate
+	Arg         func(data.Row) (interface{}, error) // Computes the argument from a row (nil for none, and for COUNT(*))
+	ArgType     schema.ColumnType                   // Type of the argument
+	Type        schema.ColumnType                   // Type of the result
+	Offset      int                                 // Rows back (LAG) or ahead (LEAD) of the current row
+	Default     interface{}                         // LAG/LEAD result when the offset row is outside the partition
+	PartitionBy []GroupKey
+	OrderBy     []SortKey
+	Frame       WindowFrame // Rows read by FIRST_VALUE, LAST_VALUE and aggregates
+}
+
+// WindowFrame is the range of partition rows, around the current row, that a window
+// function reads
+// ROWS frames count rows from the current row. RANGE frames compare the ORDER BY value
+// with the current row's, so rows with equal ORDER BY values (peers) share a frame;
+// a RANGE bound with a non-zero offset requires a single numeric ORDER BY key.
+type WindowFrame struct {
+	Range bool // RANGE (true) or ROWS (false)
+	Start FrameBound
+	End   FrameBound
+}
+
+// FrameBound is one end of a window frame
+type FrameBound struct {
+	// Unbounded extends the frame to the start (Start) or end (End) of the partition
+	Unbounded bool
+	// Offset from the current row: negative for PRECEDING, positive for FOLLOWING, 0 for CURRENT ROW
+	Offset int
+}
+
+// WindowNode adds the results of window functions to every row of its child
+// Runs after filtering and joins and before computed columns and ORDER BY, so sort
+// keys may name window results. Rows keep their order.
+type WindowNode struct {
+	Functions []WindowFunction
+
+	child    Node
+	metadata map[string]any
+}
+
+func NewWindowNode(child Node, functions []WindowFunction) *WindowNode {
+	return &WindowNode{child: child, Functions: functions}
+}
+
+func (n *WindowNode) Child() Node {
+	return n.child
+}
+
+func (n *WindowNode) Children() []Node {
+	return []Node{n.child}
+}
+
+func (n *WindowNode) Metadata() map[string]any {
+	if n.metadata == nil {
+		n.metadata = make(map[string]any)
+	}
+	return n.metadata
+}
+
+func (n *WindowNode) NodeType() string {
+	return "WINDOW"
+}
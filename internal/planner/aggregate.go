@@ -0,0 +1,392 @@
+package planner
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/leengari/mini-rdbms/internal/domain/data"
+	"github.com/leengari/mini-rdbms/internal/domain/schema"
+	"github.com/leengari/mini-rdbms/internal/parser/ast"
+	"github.com/leengari/mini-rdbms/internal/plan"
+	"github.com/leengari/mini-rdbms/internal/planner/expression"
+	"github.com/leengari/mini-rdbms/internal/planner/predicate"
+)
+
+// isAggregateQuery reports whether the SELECT groups rows
+// True when it has GROUP BY or HAVING, or uses an aggregate in the field list or ORDER BY.
+func isAggregateQuery(stmt *ast.SelectStatement) bool {
+	if len(stmt.GroupBy) > 0 || stmt.Having != nil {
+		return true
+	}
+	for _, f := range stmt.Fields {
+		expr, _ := unwrapAlias(f)
+		if containsAggregate(expr) {
+			return true
+		}
+	}
+	for _, item := range stmt.OrderBy {
+		if containsAggregate(item.Expr) {
+			return true
+		}
+	}
+	return false
+}
+
+// containsAggregate reports whether a scalar expression calls an aggregate function
+func containsAggregate(expr ast.Expression) bool {
+	switch e := expr.(type) {
+	case *ast.FunctionCall:
+		if expression.IsAggregate(e.Name) {
+			return true
+		}
+		for _, arg := range e.Args {
+			if containsAggregate(arg) {
+				return true
+			}
+		}
+		return false
+	case *ast.CastExpression:
+		return containsAggregate(e.Expr)
+	case *ast.BinaryExpression:
+		return containsAggregate(e.Left) || containsAggregate(e.Right)
+	case *ast.UnaryExpression:
+		return containsAggregate(e.Operand)
+	default:
+		return false
+	}
+}
+
+// planAggregate builds the AggregateNode for GROUP BY, aggregate functions and HAVING
+// Computed SELECT fields are rewritten in place to read the aggregated columns.
+func planAggregate(stmt *ast.SelectStatement, db *schema.Database, source plan.Node, computed []computedField) (*plan.AggregateNode, error) {
+	tables := queryTables(stmt)
+
+	// 1. Resolve GROUP BY columns
+	groupBy := make([]plan.GroupKey, len(stmt.GroupBy))
+	for i, g := range stmt.GroupBy {
+		col, tableName, err := resolveColumn(g, tables, db)
+		if err != nil {
+			return nil, fmt.Errorf("invalid GROUP BY column: %w", err)
+		}
+		groupBy[i] = plan.GroupKey{
+			Table:  tableName,
+			Column: g.Value,
+			Type:   col.Type,
+		}
+	}
+
+	// 2. Collect aggregates from the field list, HAVING and ORDER BY (each computed once)
+	var aggregates []plan.AggregateSpec
+	seen := make(map[string]bool)
+	addAggregate := func(call *ast.FunctionCall) error {
+		spec, err := buildAggregateSpec(call, tables, db)
+		if err != nil {
+			return err
+		}
+		if !seen[spec.Name] {
+			seen[spec.Name] = true
+			aggregates = append(aggregates, spec)
+		}
+		return nil
+	}
+
+	for _, field := range stmt.Fields {
+		expr, _ := unwrapAlias(field)
+		switch f := expr.(type) {
+		case *ast.FunctionCall:
+			if !expression.IsAggregate(f.Name) {
+				continue // scalar functions are rewritten with the computed fields
+			}
+			if err := addAggregate(f); err != nil {
+				return nil, err
+			}
+		case *ast.Identifier:
+			if !matchesGroupKey(f, groupBy) {
+				return nil, fmt.Errorf("column %s must appear in the GROUP BY clause or be used in an aggregate function", f.String())
+			}
+		}
+	}
+	for i := range computed {
+		rewritten, err := rewriteAggregates(computed[i].Expr, "column", groupBy, addAggregate)
+		if err != nil {
+			return nil, err
+		}
+		computed[i].Expr = rewritten
+	}
+
+	for _, item := range stmt.OrderBy {
+		if call, ok := item.Expr.(*ast.FunctionCall); ok && expression.IsAggregate(call.Name) {
+			if err := addAggregate(call); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	// 3. HAVING runs over the aggregated rows, where aggregates are columns named by aggregateName
+	var having func(row data.Row) (bool, error)
+	if stmt.Having != nil {
+		rewritten, err := rewriteAggregates(stmt.Having, "HAVING column", groupBy, addAggregate)
+		if err != nil {
+			return nil, err
+		}
+		having, err = predicate.Build(rewritten)
+		if err != nil {
+			return nil, fmt.Errorf("invalid HAVING clause: %w", err)
+		}
+	}
+
+	node := plan.NewAggregateNode(source, groupBy, aggregates, having)
+	node.Metadata()["group_by"] = len(groupBy)
+	node.Metadata()["aggregates"] = len(aggregates)
+	node.Metadata()["has_having"] = having != nil
+
+	return node, nil
+}
+
+// buildAggregateSpec validates an aggregate call and compiles its argument
+func buildAggregateSpec(call *ast.FunctionCall, tables []string, db *schema.Database) (plan.AggregateSpec, error) {
+	spec := plan.AggregateSpec{Func: call.Name, Distinct: call.Distinct, Name: aggregateName(call)}
+
+	if !expression.IsAggregate(call.Name) {
+		return spec, fmt.Errorf("unsupported aggregate function: %s", call.Name)
+	}
+
+	if call.Star {
+		if call.Name != "COUNT" {
+			return spec, fmt.Errorf("%s(*) is not supported, only COUNT(*)", call.Name)
+		}
+		return spec, nil
+	}
+
+	if len(call.Args) != 1 {
+		return spec, fmt.Errorf("%s takes exactly one argument", call.Name)
+	}
+	arg, argType, err := buildArgument(call, call.Args[0], tables, db)
+	if err != nil {
+		return spec, err
+	}
+	if (call.Name == "SUM" || call.Name == "AVG") &&
+		argType != schema.ColumnTypeInt && argType != schema.ColumnTypeFloat {
+		return spec, fmt.Errorf("%s requires a numeric argument, %s is %s", call.Name, call.Args[0].String(), argType)
+	}
+
+	spec.Arg = arg
+	spec.ArgType = argType
+	return spec, nil
+}
+
+// buildArgument compiles the argument of an aggregate or window function call
+// The argument is a scalar expression over the query's columns (e.g. SUM(qty * 2)) and
+// must not call an aggregate itself. Returns the compiled argument and its type.
+func buildArgument(call *ast.FunctionCall, arg ast.Expression, tables []string, db *schema.Database) (func(data.Row) (interface{}, error), schema.ColumnType, error) {
+	if ident, ok := arg.(*ast.Identifier); ok {
+		arg = normalizeIdentifier(ident)
+	}
+	if containsAggregate(arg) {
+		return nil, "", fmt.Errorf("%s argument must not contain an aggregate function, got %s", call.Name, arg.String())
+	}
+	for _, ident := range expression.Columns(arg) {
+		if _, _, err := resolveColumn(ident, tables, db); err != nil {
+			return nil, "", fmt.Errorf("invalid %s argument: %w", call.Name, err)
+		}
+	}
+
+	argType, err := expression.Type(arg, columnTypes(tables, db))
+	if err != nil {
+		return nil, "", fmt.Errorf("invalid %s argument %s: %w", call.Name, arg.String(), err)
+	}
+	eval, err := expression.Build(arg)
+	if err != nil {
+		return nil, "", fmt.Errorf("invalid %s argument %s: %w", call.Name, arg.String(), err)
+	}
+
+	if argType == "" {
+		argType = schema.ColumnTypeText
+	}
+	return eval, argType, nil
+}
+
+// rewriteAggregates replaces aggregate calls in a HAVING condition or computed SELECT field
+// with references to the aggregated columns, registering any aggregate not already computed.
+// Other column references must be GROUP BY columns; subject names them in errors.
+func rewriteAggregates(expr ast.Expression, subject string, groupBy []plan.GroupKey, addAggregate func(*ast.FunctionCall) error) (ast.Expression, error) {
+	rewrite := func(e ast.Expression) (ast.Expression, error) {
+		return rewriteAggregates(e, subject, groupBy, addAggregate)
+	}
+	rewriteAll := func(exprs []ast.Expression) ([]ast.Expression, error) {
+		out := make([]ast.Expression, len(exprs))
+		for i, e := range exprs {
+			r, err := rewrite(e)
+			if err != nil {
+				return nil, err
+			}
+			out[i] = r
+		}
+		return out, nil
+	}
+
+	switch e := expr.(type) {
+	case *ast.FunctionCall:
+		if !expression.IsAggregate(e.Name) {
+			args, err := rewriteAll(e.Args)
+			if err != nil {
+				return nil, err
+			}
+			return &ast.FunctionCall{Name: e.Name, Args: args, Star: e.Star, Distinct: e.Distinct}, nil
+		}
+		if err := addAggregate(e); err != nil {
+			return nil, err
+		}
+		name := aggregateName(e)
+		return &ast.Identifier{TokenLiteralValue: name, Value: name}, nil
+
+	case *ast.CastExpression:
+		inner, err := rewrite(e.Expr)
+		if err != nil {
+			return nil, err
+		}
+		return &ast.CastExpression{Expr: inner, Type: e.Type}, nil
+
+	case *ast.Identifier:
+		ident := normalizeIdentifier(e)
+		if !matchesGroupKey(ident, groupBy) {
+			return nil, fmt.Errorf("%s %s must appear in the GROUP BY clause or be used in an aggregate function", subject, e.String())
+		}
+		return ident, nil
+
+	case *ast.BinaryExpression:
+		left, err := rewrite(e.Left)
+		if err != nil {
+			return nil, err
+		}
+		right, err := rewrite(e.Right)
+		if err != nil {
+			return nil, err
+		}
+		return &ast.BinaryExpression{Left: left, Operator: e.Operator, Right: right}, nil
+
+	case *ast.UnaryExpression:
+		operand, err := rewrite(e.Operand)
+		if err != nil {
+			return nil, err
+		}
+		return &ast.UnaryExpression{Operator: e.Operator, Operand: operand}, nil
+
+	case *ast.LogicalExpression:
+		left, err := rewrite(e.Left)
+		if err != nil {
+			return nil, err
+		}
+		right, err := rewrite(e.Right)
+		if err != nil {
+			return nil, err
+		}
+		return &ast.LogicalExpression{Left: left, Operator: e.Operator, Right: right}, nil
+
+	case *ast.NotExpression:
+		inner, err := rewrite(e.Expr)
+		if err != nil {
+			return nil, err
+		}
+		return &ast.NotExpression{Expr: inner}, nil
+
+	case *ast.IsNullExpression:
+		inner, err := rewrite(e.Expr)
+		if err != nil {
+			return nil, err
+		}
+		return &ast.IsNullExpression{Expr: inner, Not: e.Not}, nil
+
+	case *ast.InExpression:
+		left, err := rewrite(e.Left)
+		if err != nil {
+			return nil, err
+		}
+		values, err := rewriteAll(e.Values)
+		if err != nil {
+			return nil, err
+		}
+		return &ast.InExpression{Left: left, Values: values, Not: e.Not}, nil
+
+	case *ast.BetweenExpression:
+		bounds, err := rewriteAll([]ast.Expression{e.Expr, e.Low, e.High})
+		if err != nil {
+			return nil, err
+		}
+		return &ast.BetweenExpression{Expr: bounds[0], Low: bounds[1], High: bounds[2], Not: e.Not}, nil
+
+	case *ast.LikeExpression:
+		inner, err := rewrite(e.Expr)
+		if err != nil {
+			return nil, err
+		}
+		rewritten := *e
+		rewritten.Expr = inner
+		return &rewritten, nil
+
+	case *expression.Subquery:
+		if e.Left == nil {
+			return expr, nil
+		}
+		left, err := rewrite(e.Left)
+		if err != nil {
+			return nil, err
+		}
+		rewritten := *e
+		rewritten.Left = left
+		return &rewritten, nil
+
+	case *expression.SemiJoin:
+		rewritten := *e
+		if e.Value != nil {
+			value, err := rewrite(e.Value)
+			if err != nil {
+				return nil, err
+			}
+			rewritten.Value = value
+		}
+		keys, err := rewriteAll(e.Keys)
+		if err != nil {
+			return nil, err
+		}
+		rewritten.Keys = keys
+		return &rewritten, nil
+
+	default:
+		return expr, nil
+	}
+}
+
+// aggregateName returns the canonical output column name of an aggregate call
+// Column names are lower-cased so SUM(Amount) and sum(amount) name the same column.
+func aggregateName(call *ast.FunctionCall) string {
+	normalized := &ast.FunctionCall{Name: strings.ToUpper(call.Name), Star: call.Star, Distinct: call.Distinct}
+	for _, arg := range call.Args {
+		if ident, ok := arg.(*ast.Identifier); ok {
+			arg = normalizeIdentifier(ident)
+		}
+		normalized.Args = append(normalized.Args, arg)
+	}
+	return normalized.String()
+}
+
+// normalizeIdentifier lower-cases an identifier, matching how the SELECT list is parsed
+func normalizeIdentifier(ident *ast.Identifier) *ast.Identifier {
+	table := strings.ToLower(ident.Table)
+	value := strings.ToLower(ident.Value)
+	literal := value
+	if table != "" {
+		literal = table + "." + value
+	}
+	return &ast.Identifier{TokenLiteralValue: literal, Table: table, Value: value}
+}
+
+// matchesGroupKey reports whether a column reference names one of the GROUP BY columns
+func matchesGroupKey(ident *ast.Identifier, groupBy []plan.GroupKey) bool {
+	for _, key := range groupBy {
+		if ident.Value == key.Column && (ident.Table == "" || ident.Table == key.Table) {
+			return true
+		}
+	}
+	return false
+}
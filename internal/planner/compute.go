@@ -0,0 +1,79 @@
+package planner
+
+import (
+	"fmt"
+
+	"github.com/leengari/mini-rdbms/internal/domain/schema"
+	"github.com/leengari/mini-rdbms/internal/parser/ast"
+	"github.com/leengari/mini-rdbms/internal/plan"
+	"github.com/leengari/mini-rdbms/internal/planner/expression"
+)
+
+// computedField is a SELECT field that is neither a plain column nor an aggregate
+// (e.g. price * quantity AS total), evaluated by a ComputeNode
+type computedField struct {
+	Name string         // Output column name: the alias, or the expression text
+	Expr ast.Expression // Rewritten to reference aggregate columns in aggregate queries
+}
+
+// unwrapAlias splits a SELECT field into its expression and alias ("" when it has none)
+func unwrapAlias(field ast.Expression) (ast.Expression, string) {
+	if a, ok := field.(*ast.AliasExpression); ok {
+		return a.Expr, a.Alias
+	}
+	return field, ""
+}
+
+// planCompute wraps source in a ComputeNode that evaluates the computed SELECT fields
+// When agg is non-nil the fields have been rewritten over the aggregated rows.
+func planCompute(stmt *ast.SelectStatement, db *schema.Database, source plan.Node, agg *plan.AggregateNode, fields []computedField) (plan.Node, error) {
+	tables := queryTables(stmt)
+
+	// Type of a column reference in the rows the ComputeNode receives
+	columnType := func(ident *ast.Identifier) schema.ColumnType {
+		if agg == nil {
+			return columnTypes(tables, db)(ident)
+		}
+		for _, spec := range agg.Aggregates {
+			if ident.Table == "" && ident.Value == spec.Name {
+				return spec.ResultType()
+			}
+		}
+		for _, key := range agg.GroupBy {
+			if ident.Value == key.Column && (ident.Table == "" || ident.Table == key.Table) {
+				return key.Type
+			}
+		}
+		return ""
+	}
+
+	columns := make([]plan.ComputedColumn, len(fields))
+	for i, f := range fields {
+		if agg == nil {
+			for _, ident := range expression.Columns(f.Expr) {
+				if _, _, err := resolveColumn(ident, tables, db); err != nil {
+					return nil, fmt.Errorf("invalid SELECT expression %s: %w", f.Expr.String(), err)
+				}
+			}
+		}
+
+		colType, err := expression.Type(f.Expr, columnType)
+		if err != nil {
+			return nil, fmt.Errorf("invalid SELECT expression %s: %w", f.Expr.String(), err)
+		}
+		eval, err := expression.Build(f.Expr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid SELECT expression %s: %w", f.Expr.String(), err)
+		}
+
+		if colType == "" {
+			colType = schema.ColumnTypeText
+		}
+
+		columns[i] = plan.ComputedColumn{Name: f.Name, Type: colType, Eval: eval}
+	}
+
+	node := plan.NewComputeNode(source, columns)
+	node.Metadata()["computed_columns"] = len(columns)
+	return node, nil
+}
@@ -1,6 +1,9 @@
 package planner
 
-import "github.com/leengari/mini-rdbms/internal/plan"
+import (
+	"github.com/leengari/mini-rdbms/internal/domain/schema"
+	"github.com/leengari/mini-rdbms/internal/plan"
+)
 
 // estimateCost estimates the cost of executing a plan node
 // Scaffold: Always returns 1.0 (naive implementation)
@@ -22,8 +25,30 @@ func attachCostEstimate(node plan.Node) {
 }
 
 // estimateRowCount estimates the number of rows a node will return
-// Scaffold: Always returns 1000 (naive implementation)
-func estimateRowCount(node plan.Node) int64 {
-	// Future: Implement real row count estimation
+// Scans return the table size (index scans at most one row per lookup value, as
+// indexes are unique, and a third of the table for a range); JOINs are assumed to
+// follow a foreign key, returning about as many rows as their larger input.
+// Other nodes default to 1000.
+func estimateRowCount(node plan.Node, db *schema.Database) int64 {
+	switch n := node.(type) {
+	case *plan.ScanNode:
+		if table, ok := db.Tables[n.TableName]; ok {
+			table.RLock()
+			defer table.RUnlock()
+			return int64(len(table.Rows))
+		}
+	case *plan.IndexScanNode:
+		if n.Range != nil {
+			// Ranges are assumed to select about a third of the table
+			if table, ok := db.Tables[n.TableName]; ok {
+				table.RLock()
+				defer table.RUnlock()
+				return int64(len(table.Rows))/3 + 1
+			}
+		}
+		return int64(len(n.Values))
+	case *plan.JoinNode:
+		return max(estimateRowCount(n.Left(), db), estimateRowCount(n.Right(), db))
+	}
 	return 1000
 }
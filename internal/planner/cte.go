@@ -0,0 +1,158 @@
+package planner
+
+import (
+	"fmt"
+
+	"github.com/leengari/mini-rdbms/internal/domain/schema"
+	"github.com/leengari/mini-rdbms/internal/domain/transaction"
+	"github.com/leengari/mini-rdbms/internal/parser/ast"
+	"github.com/leengari/mini-rdbms/internal/plan"
+)
+
+// cteRef is a common table expression visible to a query
+type cteRef struct {
+	table   *plan.CommonTable // nil for a self-reference that cannot read the working table
+	working bool              // Self-reference in the CTE's recursive term: reads the working table
+}
+
+// withTables maps the names of the CTEs visible to a query to their definitions
+type withTables map[string]cteRef
+
+// with returns a copy of the CTEs with one more added
+func (w withTables) with(name string, ref cteRef) withTables {
+	out := make(withTables, len(w)+1)
+	for n, r := range w {
+		out[n] = r
+	}
+	out[name] = ref
+	return out
+}
+
+// nested returns the CTEs visible to the subqueries and derived tables of a query
+// A recursive term may read its working table only at the top level, where each
+// iteration rescans it; elsewhere the reference is an error.
+func (w withTables) nested() withTables {
+	out := make(withTables, len(w))
+	for name, ref := range w {
+		if ref.working {
+			ref = cteRef{}
+		}
+		out[name] = ref
+	}
+	return out
+}
+
+// scan returns the node reading the CTE called name (ok is false when there is none)
+func (w withTables) scan(name string) (scan *plan.CTEScanNode, ok bool, err error) {
+	ref, ok := w[name]
+	if !ok {
+		return nil, false, nil
+	}
+	if ref.table == nil {
+		return nil, true, fmt.Errorf("recursive reference to %s must not appear within a subquery", name)
+	}
+	if ref.working {
+		ref.table.SelfReference = true
+	}
+
+	scan = plan.NewCTEScanNode(name, ref.table, ref.working)
+	scan.Metadata()["scan_type"] = "cte"
+	scan.Metadata()["table"] = name
+	return scan, true, nil
+}
+
+// planCommonTables plans the CTEs of a WITH clause, in definition order
+// Each CTE sees the ones defined before it (and, inside WITH RECURSIVE, its recursive
+// term sees the CTE itself). Returns the planned CTEs, the CTEs visible to the query,
+// and a copy of db in which each CTE is also a (row-less) table, so the rest of the
+// query can resolve its columns.
+func planCommonTables(with *ast.WithClause, db *schema.Database, tx *transaction.Transaction, ctes withTables) (*schema.Database, withTables, []*plan.CommonTable, error) {
+	shadow := shadowDatabase(db)
+	var planned []*plan.CommonTable
+	defined := make(map[string]bool, len(with.Tables))
+
+	for _, cte := range with.Tables {
+		name := cte.Name.Value
+		if defined[name] {
+			return nil, nil, nil, fmt.Errorf("common table expression %s is defined more than once", name)
+		}
+		defined[name] = true
+
+		root, out, err := planSelect(cte.Query, shadow, tx, nil, ctes)
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("invalid common table expression %s: %w", name, err)
+		}
+		columns, err := cteColumns(cte, out.Columns)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+
+		ct := &plan.CommonTable{
+			Name:       name,
+			Columns:    columns,
+			Anchor:     root,
+			AnchorKeys: out.Keys,
+			UnionAll:   cte.UnionAll,
+		}
+		table := &schema.Table{
+			Name:   name,
+			Schema: &schema.TableSchema{TableName: name, Columns: columns},
+		}
+
+		if cte.Recursive != nil {
+			termDB, termCTEs := shadow, ctes
+			if with.Recursive {
+				termDB = shadowDatabase(shadow)
+				termDB.Tables[name] = table
+				termCTEs = ctes.with(name, cteRef{table: ct, working: true})
+			}
+			root, out, err := planSelect(cte.Recursive, termDB, tx, nil, termCTEs)
+			if err != nil {
+				return nil, nil, nil, fmt.Errorf("invalid common table expression %s: %w", name, err)
+			}
+			if len(out.Columns) != len(columns) {
+				return nil, nil, nil, fmt.Errorf("common table expression %s: each UNION query must have the same number of columns", name)
+			}
+			ct.Recursive = root
+			ct.RecursiveKeys = out.Keys
+		}
+
+		shadow.Tables[name] = table
+		ctes = ctes.with(name, cteRef{table: ct})
+		planned = append(planned, ct)
+	}
+	return shadow, ctes, planned, nil
+}
+
+// cteColumns names a CTE's output columns: by its column list when it has one,
+// otherwise by its query's SELECT list
+func cteColumns(cte *ast.CommonTableExpression, output []schema.Column) ([]schema.Column, error) {
+	columns := make([]schema.Column, len(output))
+	copy(columns, output)
+	if len(cte.Columns) > 0 {
+		if len(cte.Columns) != len(output) {
+			return nil, fmt.Errorf("common table expression %s has %d columns but its query returns %d", cte.Name.Value, len(cte.Columns), len(output))
+		}
+		for i, name := range cte.Columns {
+			columns[i].Name = name
+		}
+	}
+
+	seen := make(map[string]bool, len(columns))
+	for _, col := range columns {
+		if seen[col.Name] {
+			return nil, fmt.Errorf("common table expression %s has more than one column named %s", cte.Name.Value, col.Name)
+		}
+		seen[col.Name] = true
+	}
+	return columns, nil
+}
+
+// shadowDatabase returns a copy of db whose table map can be extended without changing db
+func shadowDatabase(db *schema.Database) *schema.Database {
+	shadow := &schema.Database{Name: db.Name, Path: db.Path, Tables: make(map[string]*schema.Table, len(db.Tables))}
+	for name, table := range db.Tables {
+		shadow.Tables[name] = table
+	}
+	return shadow
+}
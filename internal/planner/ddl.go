@@ -0,0 +1,298 @@
+package planner
+
+import (
+	"fmt"
+
+	"github.com/leengari/mini-rdbms/internal/domain/errors"
+	"github.com/leengari/mini-rdbms/internal/domain/schema"
+	"github.com/leengari/mini-rdbms/internal/domain/transaction"
+	"github.com/leengari/mini-rdbms/internal/parser/ast"
+	"github.com/leengari/mini-rdbms/internal/plan"
+	"github.com/leengari/mini-rdbms/internal/util/types"
+)
+
+func planCreateTable(stmt *ast.CreateTableStatement, db *schema.Database, tx *transaction.Transaction) (plan.Node, error) {
+	tableName := stmt.TableName.Value
+	if _, exists := db.Tables[tableName]; exists {
+		return nil, fmt.Errorf("table '%s' already exists", tableName)
+	}
+
+	if len(stmt.Columns) == 0 {
+		return nil, fmt.Errorf("table '%s' must have at least one column", tableName)
+	}
+
+	tableSchema := &schema.TableSchema{
+		TableName: tableName,
+		Columns:   make([]schema.Column, 0, len(stmt.Columns)),
+	}
+
+	seen := make(map[string]bool)
+	hasPrimaryKey := false
+	for _, def := range stmt.Columns {
+		col, err := buildColumn(def)
+		if err != nil {
+			return nil, err
+		}
+
+		if seen[col.Name] {
+			return nil, fmt.Errorf("duplicate column name: %s", col.Name)
+		}
+		seen[col.Name] = true
+
+		if col.PrimaryKey {
+			if hasPrimaryKey {
+				return nil, fmt.Errorf("table '%s' can only have one PRIMARY KEY", tableName)
+			}
+			hasPrimaryKey = true
+		}
+
+		tableSchema.Columns = append(tableSchema.Columns, col)
+	}
+
+	node := &plan.CreateTableNode{
+		TableName:   tableName,
+		Schema:      tableSchema,
+		Transaction: tx,
+	}
+	node.Metadata()["table"] = tableName
+	node.Metadata()["column_count"] = len(tableSchema.Columns)
+
+	return node, nil
+}
+
+func planDropTable(stmt *ast.DropTableStatement, db *schema.Database, tx *transaction.Transaction) (plan.Node, error) {
+	tableName := stmt.TableName.Value
+	if _, exists := db.Tables[tableName]; !exists && !stmt.IfExists {
+		return nil, errors.NewTableNotFoundError(tableName)
+	}
+
+	node := &plan.DropTableNode{
+		TableName:   tableName,
+		IfExists:    stmt.IfExists,
+		Transaction: tx,
+	}
+	node.Metadata()["table"] = tableName
+
+	return node, nil
+}
+
+func planAlterTable(stmt *ast.AlterTableStatement, db *schema.Database, tx *transaction.Transaction) (plan.Node, error) {
+	tableName := stmt.TableName.Value
+	table, exists := db.Tables[tableName]
+	if !exists {
+		return nil, errors.NewTableNotFoundError(tableName)
+	}
+
+	node := &plan.AlterTableNode{
+		TableName:   tableName,
+		Action:      string(stmt.Action),
+		Transaction: tx,
+	}
+
+	switch stmt.Action {
+	case ast.AlterAddColumn:
+		col, err := buildColumn(stmt.Column)
+		if err != nil {
+			return nil, err
+		}
+		if hasColumn(table.Schema, col.Name) {
+			return nil, fmt.Errorf("column '%s' already exists in table '%s'", col.Name, tableName)
+		}
+		if col.PrimaryKey && table.Schema.GetPrimaryKeyColumn() != nil {
+			return nil, fmt.Errorf("table '%s' can only have one PRIMARY KEY", tableName)
+		}
+		node.Column = &col
+		node.Metadata()["column"] = col.Name
+
+	case ast.AlterDropColumn:
+		if !hasColumn(table.Schema, stmt.ColumnName) {
+			return nil, fmt.Errorf("column '%s' not found in table '%s'", stmt.ColumnName, tableName)
+		}
+		node.OldName = stmt.ColumnName
+		node.Metadata()["column"] = stmt.ColumnName
+
+	case ast.AlterRenameColumn:
+		if !hasColumn(table.Schema, stmt.ColumnName) {
+			return nil, fmt.Errorf("column '%s' not found in table '%s'", stmt.ColumnName, tableName)
+		}
+		if hasColumn(table.Schema, stmt.NewName) {
+			return nil, fmt.Errorf("column '%s' already exists in table '%s'", stmt.NewName, tableName)
+		}
+		if err := checkColumnName(stmt.NewName); err != nil {
+			return nil, err
+		}
+		node.OldName = stmt.ColumnName
+		node.NewName = stmt.NewName
+		node.Metadata()["column"] = stmt.ColumnName
+		node.Metadata()["new_name"] = stmt.NewName
+
+	case ast.AlterRenameTable:
+		if _, exists := db.Tables[stmt.NewName]; exists {
+			return nil, fmt.Errorf("table '%s' already exists", stmt.NewName)
+		}
+		node.NewName = stmt.NewName
+		node.Metadata()["new_name"] = stmt.NewName
+
+	default:
+		return nil, fmt.Errorf("unsupported ALTER TABLE action: %s", stmt.Action)
+	}
+
+	node.Metadata()["table"] = tableName
+	node.Metadata()["action"] = node.Action
+
+	return node, nil
+}
+
+// planCreateIndex plans CREATE [UNIQUE] INDEX
+// Index names are unique within a database, so DROP INDEX can find the table by name.
+func planCreateIndex(stmt *ast.CreateIndexStatement, db *schema.Database, tx *transaction.Transaction) (plan.Node, error) {
+	tableName := stmt.TableName.Value
+	table, exists := db.Tables[tableName]
+	if !exists {
+		return nil, errors.NewTableNotFoundError(tableName)
+	}
+	if owner := indexTable(db, stmt.Name); owner != nil {
+		return nil, fmt.Errorf("index '%s' already exists on table '%s'", stmt.Name, owner.Name)
+	}
+
+	seen := make(map[string]bool)
+	for _, col := range stmt.Columns {
+		if !hasColumn(table.Schema, col) {
+			return nil, fmt.Errorf("column '%s' not found in table '%s'", col, tableName)
+		}
+		if seen[col] {
+			return nil, fmt.Errorf("column '%s' appears more than once in index '%s'", col, stmt.Name)
+		}
+		seen[col] = true
+	}
+
+	node := &plan.CreateIndexNode{
+		IndexName:   stmt.Name,
+		TableName:   tableName,
+		Columns:     stmt.Columns,
+		Unique:      stmt.Unique,
+		Transaction: tx,
+	}
+	node.Metadata()["index"] = stmt.Name
+	node.Metadata()["table"] = tableName
+	node.Metadata()["columns"] = len(stmt.Columns)
+
+	return node, nil
+}
+
+func planDropIndex(stmt *ast.DropIndexStatement, db *schema.Database, tx *transaction.Transaction) (plan.Node, error) {
+	node := &plan.DropIndexNode{
+		IndexName:   stmt.Name,
+		IfExists:    stmt.IfExists,
+		Transaction: tx,
+	}
+
+	table := indexTable(db, stmt.Name)
+	if table == nil {
+		if !stmt.IfExists {
+			return nil, fmt.Errorf("index not found: %s", stmt.Name)
+		}
+	} else {
+		node.TableName = table.Name
+		node.Metadata()["table"] = table.Name
+	}
+	node.Metadata()["index"] = stmt.Name
+
+	return node, nil
+}
+
+// indexTable returns the table that has the ordered index with the given name, or nil
+func indexTable(db *schema.Database, name string) *schema.Table {
+	for _, table := range db.Tables {
+		if _, ok := table.OrderedIndex(name); ok {
+			return table
+		}
+	}
+	return nil
+}
+
+// hasColumn reports whether the schema contains a column with the given name
+func hasColumn(tableSchema *schema.TableSchema, name string) bool {
+	for _, col := range tableSchema.Columns {
+		if col.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// checkColumnName rejects names a column cannot have
+// schema.RowIDColumn is where each row's hidden row id is stored on disk.
+func checkColumnName(name string) error {
+	if name == schema.RowIDColumn {
+		return fmt.Errorf("column name '%s' is reserved", name)
+	}
+	return nil
+}
+
+// buildColumn converts a parsed column definition into a schema column
+// PRIMARY KEY implies UNIQUE and NOT NULL, matching the on-disk meta.json convention
+func buildColumn(def *ast.ColumnDefinition) (schema.Column, error) {
+	if err := checkColumnName(def.Name); err != nil {
+		return schema.Column{}, err
+	}
+
+	colType, err := schema.ParseColumnType(def.Type)
+	if err != nil {
+		return schema.Column{}, fmt.Errorf("column '%s': %w", def.Name, err)
+	}
+
+	col := schema.Column{
+		Name:          def.Name,
+		Type:          colType,
+		PrimaryKey:    def.PrimaryKey,
+		Unique:        def.Unique || def.PrimaryKey,
+		NotNull:       def.NotNull || def.PrimaryKey,
+		AutoIncrement: def.AutoIncrement,
+	}
+
+	if def.Default != nil {
+		value, err := buildDefault(def.Default, colType)
+		if err != nil {
+			return schema.Column{}, fmt.Errorf("column '%s': invalid DEFAULT: %w", col.Name, err)
+		}
+		col.Default = value
+	}
+
+	if col.AutoIncrement {
+		if col.Type != schema.ColumnTypeInt {
+			return schema.Column{}, fmt.Errorf("column '%s': AUTO_INCREMENT requires INT type", col.Name)
+		}
+		if !col.PrimaryKey {
+			return schema.Column{}, fmt.Errorf("column '%s': AUTO_INCREMENT requires PRIMARY KEY", col.Name)
+		}
+	}
+
+	return col, nil
+}
+
+// buildDefault converts a DEFAULT literal into the value stored in rows
+// INT defaults are stored as int64 and FLOAT defaults as float64, matching loaded data
+func buildDefault(expr ast.Expression, colType schema.ColumnType) (interface{}, error) {
+	lit, ok := expr.(*ast.Literal)
+	if !ok {
+		return nil, fmt.Errorf("DEFAULT must be a literal value")
+	}
+
+	converted, err := types.ConvertLiteralToSchemaType(lit, colType)
+	if err != nil {
+		return nil, err
+	}
+
+	switch colType {
+	case schema.ColumnTypeInt:
+		if n, ok := types.NormalizeToInt64(converted.Value); ok {
+			return n, nil
+		}
+	case schema.ColumnTypeFloat:
+		if f, ok := types.NormalizeToFloat(converted.Value); ok {
+			return f, nil
+		}
+	}
+	return converted.Value, nil
+}
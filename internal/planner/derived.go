@@ -0,0 +1,166 @@
+package planner
+
+import (
+	"fmt"
+
+	"github.com/leengari/mini-rdbms/internal/domain/schema"
+	"github.com/leengari/mini-rdbms/internal/domain/transaction"
+	"github.com/leengari/mini-rdbms/internal/parser/ast"
+	"github.com/leengari/mini-rdbms/internal/plan"
+	"github.com/leengari/mini-rdbms/internal/planner/expression"
+	"github.com/leengari/mini-rdbms/internal/query/operations/projection"
+)
+
+// selectOutput describes the rows a planned SELECT returns
+type selectOutput struct {
+	Columns []schema.Column // Output columns, in SELECT-list order
+	Keys    []string        // Key of each output column in the projected rows
+	Untyped []bool          // Columns whose type could not be determined (e.g. NULL), reported as TEXT
+}
+
+// planDerivedTables plans the subqueries in FROM and JOIN as SubqueryScanNodes
+// Returns the scan of each derived table by alias, and a copy of db in which each
+// derived table is also a (row-less) table, so the rest of the query can resolve its columns.
+func planDerivedTables(stmt *ast.SelectStatement, db *schema.Database, tx *transaction.Transaction, ctes withTables) (*schema.Database, map[string]*plan.SubqueryScanNode, error) {
+	type derivedTable struct {
+		alias string
+		query *ast.SelectStatement
+	}
+	var tables []derivedTable
+	if stmt.Derived != nil {
+		tables = append(tables, derivedTable{stmt.TableName.Value, stmt.Derived})
+	}
+	for _, j := range stmt.Joins {
+		if j.Derived != nil {
+			tables = append(tables, derivedTable{j.RightTable.Value, j.Derived})
+		}
+	}
+	if len(tables) == 0 {
+		return db, nil, nil
+	}
+
+	shadow := shadowDatabase(db)
+
+	scans := make(map[string]*plan.SubqueryScanNode, len(tables))
+	for _, d := range tables {
+		if _, exists := shadow.Tables[d.alias]; exists {
+			return nil, nil, fmt.Errorf("derived table %s conflicts with an existing table", d.alias)
+		}
+
+		// A derived table cannot reference the query it appears in
+		root, out, err := planSelect(d.query, db, tx, nil, ctes)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid derived table %s: %w", d.alias, err)
+		}
+		seen := make(map[string]bool, len(out.Columns))
+		for _, col := range out.Columns {
+			if seen[col.Name] {
+				return nil, nil, fmt.Errorf("derived table %s has more than one column named %s", d.alias, col.Name)
+			}
+			seen[col.Name] = true
+		}
+
+		scan := plan.NewSubqueryScanNode(d.alias, root, out.Columns, out.Keys)
+		scan.Metadata()["scan_type"] = "subquery"
+		scan.Metadata()["table"] = d.alias
+		scans[d.alias] = scan
+
+		shadow.Tables[d.alias] = &schema.Table{
+			Name:   d.alias,
+			Schema: &schema.TableSchema{TableName: d.alias, Columns: out.Columns},
+		}
+	}
+	return shadow, scans, nil
+}
+
+// describeOutput returns the columns a SELECT produces and their keys in its projected rows
+// Column types come from the tables read, the aggregates computed (agg), the window
+// functions (window) and the computed columns (compute); a type that cannot be determined (e.g. of NULL) is
+// reported as TEXT and marked untyped.
+func describeOutput(stmt *ast.SelectStatement, db *schema.Database, proj *projection.Projection, agg *plan.AggregateNode, window *plan.WindowNode, compute *plan.ComputeNode) *selectOutput {
+	out := &selectOutput{}
+	tables := queryTables(stmt)
+
+	if proj.SelectAll {
+		for _, name := range tables {
+			table, ok := db.Tables[name]
+			if !ok {
+				continue
+			}
+			for _, col := range table.Schema.Columns {
+				key := col.Name
+				if len(stmt.Joins) > 0 {
+					key = name + "." + col.Name
+				}
+				out.Columns = append(out.Columns, col)
+				out.Keys = append(out.Keys, key)
+				out.Untyped = append(out.Untyped, false)
+			}
+		}
+		return out
+	}
+
+	for i, ref := range proj.Columns {
+		key := ref.Column
+		if ref.Alias != "" {
+			key = ref.Alias
+		} else if ref.Table != "" {
+			key = ref.Table + "." + ref.Column
+		}
+
+		name := ref.Column
+		if ref.Alias != "" {
+			name = ref.Alias
+		}
+
+		var colType schema.ColumnType
+		expr, _ := unwrapAlias(stmt.Fields[i])
+		switch e := expr.(type) {
+		case *ast.Identifier:
+			colType = columnTypes(tables, db)(e)
+			if agg != nil {
+				for _, k := range agg.GroupBy {
+					if k.Column == e.Value && (e.Table == "" || e.Table == k.Table) {
+						colType = k.Type
+					}
+				}
+			}
+		case *ast.FunctionCall:
+			if expression.IsAggregate(e.Name) && agg != nil {
+				for _, spec := range agg.Aggregates {
+					if spec.Name == ref.Column {
+						colType = spec.ResultType()
+					}
+				}
+				break
+			}
+			colType = computedType(compute, ref.Column)
+		case *ast.WindowExpression:
+			colType = windowType(window, ref.Column)
+		default:
+			colType = computedType(compute, ref.Column)
+		}
+		lit, isLiteral := expr.(*ast.Literal)
+		out.Untyped = append(out.Untyped, colType == "" || (isLiteral && lit.Kind == ast.LiteralNull))
+		if colType == "" {
+			colType = schema.ColumnTypeText
+		}
+
+		out.Columns = append(out.Columns, schema.Column{Name: name, Type: colType})
+		out.Keys = append(out.Keys, key)
+	}
+	return out
+}
+
+// computedType returns the type of a computed column ("" when there is none)
+func computedType(compute *plan.ComputeNode, name string) schema.ColumnType {
+	if compute == nil {
+		return ""
+	}
+	for _, col := range compute.Columns {
+		if col.Name == name {
+			return col.Type
+		}
+	}
+	return ""
+}
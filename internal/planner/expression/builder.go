@@ -0,0 +1,432 @@
+package expression
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/leengari/mini-rdbms/internal/domain/data"
+	"github.com/leengari/mini-rdbms/internal/domain/schema"
+	"github.com/leengari/mini-rdbms/internal/parser/ast"
+	"github.com/leengari/mini-rdbms/internal/util/types"
+)
+
+// Func evaluates a scalar expression against a row
+// A nil result is SQL NULL.
+type Func func(data.Row) (interface{}, error)
+
+// Build converts an AST expression into a function that computes its value for a row
+// Supports:
+//   - Literals and column references (qualified or bare)
+//   - Arithmetic: +, -, *, /, % and unary -, +
+//   - String concatenation: ||
+//   - Built-in scalar functions (see functions.go) and CAST(expr AS type)
+//   - Planned subqueries: scalar, EXISTS and IN (see subquery.go)
+//   - Nested expressions with parentheses
+//
+// Integer operands give integer results (division truncates); any float operand
+// makes the result a float. NULL operands make the result NULL.
+func Build(expr ast.Expression) (Func, error) {
+	switch e := expr.(type) {
+	case *ast.Literal:
+		value := e.Value
+		return func(data.Row) (interface{}, error) {
+			return value, nil
+		}, nil
+
+	case *ast.Identifier:
+		ident := e
+		return func(row data.Row) (interface{}, error) {
+			return ColumnValue(row, ident), nil
+		}, nil
+
+	case *ast.UnaryExpression:
+		return buildUnary(e)
+
+	case *ast.BinaryExpression:
+		return buildBinary(e)
+
+	case *ast.FunctionCall:
+		return buildFunction(e)
+
+	case *ast.CastExpression:
+		return buildCast(e)
+
+	case *Subquery:
+		return buildSubquery(e)
+
+	case *SemiJoin:
+		return buildSemiJoin(e)
+
+	default:
+		return nil, fmt.Errorf("unsupported scalar expression: %s", expr.String())
+	}
+}
+
+// IsScalarOperator reports whether op is an arithmetic or concatenation operator
+func IsScalarOperator(op string) bool {
+	switch op {
+	case "+", "-", "*", "/", "%", "||":
+		return true
+	default:
+		return false
+	}
+}
+
+// buildUnary builds the function for a prefix - or +
+func buildUnary(unary *ast.UnaryExpression) (Func, error) {
+	operand, err := Build(unary.Operand)
+	if err != nil {
+		return nil, err
+	}
+
+	op := unary.Operator
+	return func(row data.Row) (interface{}, error) {
+		val, err := operand(row)
+		if err != nil || val == nil {
+			return nil, err
+		}
+
+		if i, ok := integer(val); ok {
+			if op == "-" {
+				return -i, nil
+			}
+			return i, nil
+		}
+		if f, ok := types.NormalizeToFloat(val); ok {
+			if op == "-" {
+				return -f, nil
+			}
+			return f, nil
+		}
+		return nil, fmt.Errorf("operator %s requires a numeric operand, got %s", op, describe(val))
+	}, nil
+}
+
+// buildBinary builds the function for an arithmetic or concatenation operator
+func buildBinary(binExpr *ast.BinaryExpression) (Func, error) {
+	if !IsScalarOperator(binExpr.Operator) {
+		return nil, fmt.Errorf("unsupported scalar expression: %s", binExpr.String())
+	}
+
+	left, err := Build(binExpr.Left)
+	if err != nil {
+		return nil, err
+	}
+	right, err := Build(binExpr.Right)
+	if err != nil {
+		return nil, err
+	}
+
+	op := binExpr.Operator
+	return func(row data.Row) (interface{}, error) {
+		l, err := left(row)
+		if err != nil {
+			return nil, err
+		}
+		r, err := right(row)
+		if err != nil {
+			return nil, err
+		}
+		if l == nil || r == nil {
+			return nil, nil
+		}
+
+		if op == "||" {
+			return toText(l) + toText(r), nil
+		}
+		return arithmetic(op, l, r)
+	}, nil
+}
+
+// arithmetic applies +, -, *, / or % to two non-NULL values
+func arithmetic(op string, l, r interface{}) (interface{}, error) {
+	if li, ok := integer(l); ok {
+		if ri, ok := integer(r); ok {
+			switch op {
+			case "+":
+				return li + ri, nil
+			case "-":
+				return li - ri, nil
+			case "*":
+				return li * ri, nil
+			case "/":
+				if ri == 0 {
+					return nil, fmt.Errorf("division by zero")
+				}
+				return li / ri, nil
+			case "%":
+				if ri == 0 {
+					return nil, fmt.Errorf("division by zero")
+				}
+				return li % ri, nil
+			}
+		}
+	}
+
+	lf, lok := types.NormalizeToFloat(l)
+	rf, rok := types.NormalizeToFloat(r)
+	if !lok || !rok {
+		return nil, fmt.Errorf("operator %s requires numeric operands, got %s and %s", op, describe(l), describe(r))
+	}
+
+	switch op {
+	case "+":
+		return lf + rf, nil
+	case "-":
+		return lf - rf, nil
+	case "*":
+		return lf * rf, nil
+	case "/":
+		if rf == 0 {
+			return nil, fmt.Errorf("division by zero")
+		}
+		return lf / rf, nil
+	case "%":
+		if rf == 0 {
+			return nil, fmt.Errorf("division by zero")
+		}
+		return math.Mod(lf, rf), nil
+	default:
+		return nil, fmt.Errorf("unsupported operator: %s", op)
+	}
+}
+
+// integer returns an int or int64 value as int64 (floats are not integers here)
+func integer(val interface{}) (int64, bool) {
+	switch v := val.(type) {
+	case int:
+		return int64(v), true
+	case int64:
+		return v, true
+	default:
+		return 0, false
+	}
+}
+
+// toText formats a value for string concatenation
+func toText(val interface{}) string {
+	if s, ok := val.(string); ok {
+		return s
+	}
+	return fmt.Sprint(val)
+}
+
+// describe names a value's kind for error messages
+func describe(val interface{}) string {
+	switch val.(type) {
+	case string:
+		return fmt.Sprintf("text '%v'", val)
+	case bool:
+		return fmt.Sprintf("boolean %v", val)
+	default:
+		return fmt.Sprintf("%v", val)
+	}
+}
+
+// ColumnValue returns the row's value for a column, or nil when it is NULL or absent
+// Tries the qualified name first (e.g. "orders.amount"), then the bare column name, then a
+// single joined column ending in ".column" (JOIN rows key columns by table).
+func ColumnValue(row data.Row, ident *ast.Identifier) interface{} {
+	if ident.Table != "" {
+		if val, ok := row.Data[ident.Table+"."+ident.Value]; ok {
+			return val
+		}
+	}
+	if val, ok := row.Data[ident.Value]; ok {
+		return val
+	}
+
+	suffix := "." + ident.Value
+	if ident.Table != "" {
+		suffix = "." + ident.Table + suffix
+	}
+	var found interface{}
+	matches := 0
+	for key, val := range row.Data {
+		if strings.HasSuffix(key, suffix) {
+			found = val
+			matches++
+		}
+	}
+	if matches == 1 {
+		return found
+	}
+	return nil
+}
+
+// Type infers the column type of an expression's result and checks its operand types
+// columnType reports the type of a column reference ("" when unknown); the result is ""
+// when the type cannot be determined (e.g. NULL). Returns an error when a function is
+// unknown, is called with the wrong number of arguments, or an operand has the wrong type.
+func Type(expr ast.Expression, columnType func(*ast.Identifier) schema.ColumnType) (schema.ColumnType, error) {
+	switch e := expr.(type) {
+	case *ast.Literal:
+		switch e.Kind {
+		case ast.LiteralInt:
+			return schema.ColumnTypeInt, nil
+		case ast.LiteralFloat:
+			return schema.ColumnTypeFloat, nil
+		case ast.LiteralBool:
+			return schema.ColumnTypeBool, nil
+		case ast.LiteralDate:
+			return schema.ColumnTypeDate, nil
+		case ast.LiteralTime:
+			return schema.ColumnTypeTime, nil
+		case ast.LiteralEmail:
+			return schema.ColumnTypeEmail, nil
+		case ast.LiteralString:
+			return schema.ColumnTypeText, nil
+		default:
+			return "", nil
+		}
+
+	case *ast.Identifier:
+		return columnType(e), nil
+
+	case *ast.UnaryExpression:
+		operand, err := Type(e.Operand, columnType)
+		if err != nil {
+			return "", err
+		}
+		if !argKind(numericArg).accepts(operand) {
+			return "", fmt.Errorf("operator %s requires a numeric operand, got %s", e.Operator, operand)
+		}
+		return operand, nil
+
+	case *ast.BinaryExpression:
+		if !IsScalarOperator(e.Operator) {
+			return "", nil
+		}
+		left, err := Type(e.Left, columnType)
+		if err != nil {
+			return "", err
+		}
+		right, err := Type(e.Right, columnType)
+		if err != nil {
+			return "", err
+		}
+		if e.Operator == "||" {
+			return schema.ColumnTypeText, nil
+		}
+		if !argKind(numericArg).accepts(left) || !argKind(numericArg).accepts(right) {
+			return "", fmt.Errorf("operator %s requires numeric operands, got %s and %s", e.Operator, typeName(left), typeName(right))
+		}
+		if left == schema.ColumnTypeFloat || right == schema.ColumnTypeFloat {
+			return schema.ColumnTypeFloat, nil
+		}
+		if left == schema.ColumnTypeInt && right == schema.ColumnTypeInt {
+			return schema.ColumnTypeInt, nil
+		}
+		return "", nil
+
+	case *ast.FunctionCall:
+		return functionType(e, columnType)
+
+	case *ast.CastExpression:
+		if _, err := Type(e.Expr, columnType); err != nil {
+			return "", err
+		}
+		return schema.ParseColumnType(e.Type)
+
+	case *Subquery:
+		if e.Kind != ScalarSubquery {
+			if e.Left != nil {
+				if _, err := Type(e.Left, columnType); err != nil {
+					return "", err
+				}
+			}
+			return schema.ColumnTypeBool, nil
+		}
+		if len(e.Plan.Columns) != 1 {
+			return "", fmt.Errorf("subquery must return one column, got %d", len(e.Plan.Columns))
+		}
+		return e.Plan.Columns[0].Type, nil
+
+	case *SemiJoin:
+		return schema.ColumnTypeBool, nil
+
+	default:
+		return "", nil
+	}
+}
+
+// Check verifies the scalar expressions inside a condition (e.g. a WHERE clause)
+// with Type, so unknown functions and mistyped arguments are reported when planning.
+func Check(expr ast.Expression, columnType func(*ast.Identifier) schema.ColumnType) error {
+	check := func(exprs ...ast.Expression) error {
+		for _, e := range exprs {
+			if err := Check(e, columnType); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	switch e := expr.(type) {
+	case *ast.LogicalExpression:
+		return check(e.Left, e.Right)
+	case *ast.NotExpression:
+		return check(e.Expr)
+	case *ast.InExpression:
+		return check(append([]ast.Expression{e.Left}, e.Values...)...)
+	case *ast.BetweenExpression:
+		return check(e.Expr, e.Low, e.High)
+	case *ast.LikeExpression:
+		return check(e.Expr, e.Pattern)
+	case *ast.IsNullExpression:
+		return check(e.Expr)
+	case *ast.BinaryExpression:
+		if !IsScalarOperator(e.Operator) {
+			return check(e.Left, e.Right)
+		}
+	}
+
+	_, err := Type(expr, columnType)
+	return err
+}
+
+// typeName formats a possibly unknown type for error messages
+func typeName(t schema.ColumnType) string {
+	if t == "" {
+		return "unknown"
+	}
+	return string(t)
+}
+
+// Columns returns the column references in an expression, in order of appearance
+func Columns(expr ast.Expression) []*ast.Identifier {
+	switch e := expr.(type) {
+	case *ast.Identifier:
+		return []*ast.Identifier{e}
+	case *ast.UnaryExpression:
+		return Columns(e.Operand)
+	case *ast.CastExpression:
+		return Columns(e.Expr)
+	case *ast.BinaryExpression:
+		return append(Columns(e.Left), Columns(e.Right)...)
+	case *ast.FunctionCall:
+		var cols []*ast.Identifier
+		for _, arg := range e.Args {
+			cols = append(cols, Columns(arg)...)
+		}
+		return cols
+	case *Subquery:
+		// Columns of the outer query referenced inside a correlated subquery are not included
+		if e.Left == nil {
+			return nil
+		}
+		return Columns(e.Left)
+	case *SemiJoin:
+		var cols []*ast.Identifier
+		if e.Value != nil {
+			cols = Columns(e.Value)
+		}
+		for _, key := range e.Keys {
+			cols = append(cols, Columns(key)...)
+		}
+		return cols
+	default:
+		return nil
+	}
+}
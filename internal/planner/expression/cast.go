@@ -0,0 +1,117 @@
+package expression
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/leengari/mini-rdbms/internal/domain/data"
+	"github.com/leengari/mini-rdbms/internal/domain/schema"
+	"github.com/leengari/mini-rdbms/internal/parser/ast"
+	"github.com/leengari/mini-rdbms/internal/util/types"
+)
+
+// buildCast builds the function for CAST(expr AS type)
+func buildCast(cast *ast.CastExpression) (Func, error) {
+	target, err := schema.ParseColumnType(cast.Type)
+	if err != nil {
+		return nil, err
+	}
+
+	value, err := Build(cast.Expr)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(row data.Row) (interface{}, error) {
+		val, err := value(row)
+		if err != nil {
+			return nil, err
+		}
+		return Cast(val, target)
+	}, nil
+}
+
+// Cast converts a value to a column type
+// Floats cast to INT are rounded; text is parsed as the target type (DATE, TIME and
+// EMAIL are validated, and a timestamp such as NOW() casts to its date or time part).
+// Every value casts to TEXT. NULL stays NULL.
+func Cast(val interface{}, target schema.ColumnType) (interface{}, error) {
+	if val == nil {
+		return nil, nil
+	}
+
+	switch target {
+	case schema.ColumnTypeText:
+		return toText(val), nil
+
+	case schema.ColumnTypeInt:
+		switch v := val.(type) {
+		case string:
+			i, err := strconv.ParseInt(strings.TrimSpace(v), 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("cannot cast '%s' to INT", v)
+			}
+			return i, nil
+		case bool:
+			if v {
+				return int64(1), nil
+			}
+			return int64(0), nil
+		}
+		if i, ok := integer(val); ok {
+			return i, nil
+		}
+		if f, ok := types.NormalizeToFloat(val); ok {
+			return int64(math.Round(f)), nil
+		}
+
+	case schema.ColumnTypeFloat:
+		if s, ok := val.(string); ok {
+			f, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+			if err != nil {
+				return nil, fmt.Errorf("cannot cast '%s' to FLOAT", s)
+			}
+			return f, nil
+		}
+		if f, ok := types.NormalizeToFloat(val); ok {
+			return f, nil
+		}
+
+	case schema.ColumnTypeBool:
+		switch v := val.(type) {
+		case bool:
+			return v, nil
+		case string:
+			switch strings.ToLower(strings.TrimSpace(v)) {
+			case "true", "t", "yes", "1":
+				return true, nil
+			case "false", "f", "no", "0":
+				return false, nil
+			}
+			return nil, fmt.Errorf("cannot cast '%s' to BOOL", v)
+		}
+		if i, ok := integer(val); ok {
+			return i != 0, nil
+		}
+
+	case schema.ColumnTypeDate, schema.ColumnTypeTime:
+		if s, ok := val.(string); ok {
+			if t, layout, err := temporalValue(s); err == nil && layout == timestampLayout {
+				if target == schema.ColumnTypeDate {
+					return t.Format(dateLayout), nil
+				}
+				return t.Format(timeLayout), nil
+			}
+			return types.ConvertValueToSchemaType(s, target)
+		}
+
+	case schema.ColumnTypeEmail:
+		if s, ok := val.(string); ok {
+			return types.ConvertValueToSchemaType(s, target)
+		}
+	}
+
+	return nil, fmt.Errorf("cannot cast %s to %s", describe(val), target)
+}
@@ -0,0 +1,552 @@
+package expression
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/leengari/mini-rdbms/internal/domain/data"
+	"github.com/leengari/mini-rdbms/internal/domain/schema"
+	"github.com/leengari/mini-rdbms/internal/parser/ast"
+	"github.com/leengari/mini-rdbms/internal/util/types"
+)
+
+// argKind is the kind of value a function parameter accepts
+type argKind int
+
+const (
+	anyArg      argKind = iota
+	textArg             // TEXT, or DATE/TIME/EMAIL (stored as text)
+	numericArg          // INT or FLOAT
+	intArg              // INT
+	temporalArg         // DATE, TIME, or TEXT holding a date/time
+)
+
+// accepts reports whether a value of type t can be passed as this kind
+// Unknown types ("") are accepted and checked when the function runs.
+func (k argKind) accepts(t schema.ColumnType) bool {
+	if t == "" {
+		return true
+	}
+	switch k {
+	case textArg:
+		return t == schema.ColumnTypeText || t == schema.ColumnTypeEmail ||
+			t == schema.ColumnTypeDate || t == schema.ColumnTypeTime
+	case numericArg:
+		return t == schema.ColumnTypeInt || t == schema.ColumnTypeFloat
+	case intArg:
+		return t == schema.ColumnTypeInt
+	case temporalArg:
+		return t == schema.ColumnTypeDate || t == schema.ColumnTypeTime || t == schema.ColumnTypeText
+	default:
+		return true
+	}
+}
+
+func (k argKind) String() string {
+	switch k {
+	case textArg:
+		return "TEXT"
+	case numericArg:
+		return "numeric"
+	case intArg:
+		return "INT"
+	case temporalArg:
+		return "DATE or TIME"
+	default:
+		return "any type"
+	}
+}
+
+// function describes a built-in scalar function
+type function struct {
+	MinArgs int
+	MaxArgs int       // -1 for any number of arguments
+	Args    []argKind // Parameter kinds; the last one applies to any further arguments
+
+	// Result returns the result type for the argument types ("" when unknown)
+	Result func(args []schema.ColumnType) (schema.ColumnType, error)
+
+	// Call computes the result. Unless NullAware is set, a NULL argument makes the
+	// result NULL without calling it.
+	Call      func(args []interface{}) (interface{}, error)
+	NullAware bool
+}
+
+// argKind returns the kind of the i-th parameter
+func (f *function) argKind(i int) argKind {
+	if len(f.Args) == 0 {
+		return anyArg
+	}
+	if i >= len(f.Args) {
+		return f.Args[len(f.Args)-1]
+	}
+	return f.Args[i]
+}
+
+// returns is a Result for functions with a fixed result type
+func returns(t schema.ColumnType) func([]schema.ColumnType) (schema.ColumnType, error) {
+	return func([]schema.ColumnType) (schema.ColumnType, error) {
+		return t, nil
+	}
+}
+
+// firstArgType is a Result for functions whose result has the type of their first argument
+func firstArgType(args []schema.ColumnType) (schema.ColumnType, error) {
+	return args[0], nil
+}
+
+// now returns the current time (a variable so tests can fix the clock)
+var now = time.Now
+
+// Date and time layouts, matching the DATE and TIME column formats
+const (
+	dateLayout      = "2006-01-02"
+	timeLayout      = "15:04:05"
+	timestampLayout = dateLayout + " " + timeLayout
+)
+
+// functions is the registry of built-in scalar functions, keyed by upper-cased name
+var functions = map[string]*function{
+	// String functions
+	"UPPER": {MinArgs: 1, MaxArgs: 1, Args: []argKind{textArg}, Result: returns(schema.ColumnTypeText),
+		Call: func(args []interface{}) (interface{}, error) {
+			s, err := textValue(args[0])
+			return strings.ToUpper(s), err
+		}},
+	"LOWER": {MinArgs: 1, MaxArgs: 1, Args: []argKind{textArg}, Result: returns(schema.ColumnTypeText),
+		Call: func(args []interface{}) (interface{}, error) {
+			s, err := textValue(args[0])
+			return strings.ToLower(s), err
+		}},
+	"LENGTH": {MinArgs: 1, MaxArgs: 1, Args: []argKind{textArg}, Result: returns(schema.ColumnTypeInt),
+		Call: func(args []interface{}) (interface{}, error) {
+			s, err := textValue(args[0])
+			return int64(len([]rune(s))), err
+		}},
+	"SUBSTR": {MinArgs: 2, MaxArgs: 3, Args: []argKind{textArg, intArg, intArg}, Result: returns(schema.ColumnTypeText),
+		Call: substr},
+	"TRIM":  {MinArgs: 1, MaxArgs: 2, Args: []argKind{textArg}, Result: returns(schema.ColumnTypeText), Call: trim(strings.Trim)},
+	"LTRIM": {MinArgs: 1, MaxArgs: 2, Args: []argKind{textArg}, Result: returns(schema.ColumnTypeText), Call: trim(strings.TrimLeft)},
+	"RTRIM": {MinArgs: 1, MaxArgs: 2, Args: []argKind{textArg}, Result: returns(schema.ColumnTypeText), Call: trim(strings.TrimRight)},
+	"REPLACE": {MinArgs: 3, MaxArgs: 3, Args: []argKind{textArg}, Result: returns(schema.ColumnTypeText),
+		Call: func(args []interface{}) (interface{}, error) {
+			s, err := textValues(args)
+			if err != nil {
+				return nil, err
+			}
+			if s[1] == "" {
+				return s[0], nil
+			}
+			return strings.ReplaceAll(s[0], s[1], s[2]), nil
+		}},
+
+	// Numeric functions
+	"ABS": {MinArgs: 1, MaxArgs: 1, Args: []argKind{numericArg}, Result: firstArgType,
+		Call: func(args []interface{}) (interface{}, error) {
+			if i, ok := integer(args[0]); ok {
+				if i < 0 {
+					return -i, nil
+				}
+				return i, nil
+			}
+			f, err := numberValue(args[0])
+			return math.Abs(f), err
+		}},
+	"ROUND": {MinArgs: 1, MaxArgs: 2, Args: []argKind{numericArg, intArg}, Result: firstArgType, Call: round},
+
+	// Conditional functions
+	"COALESCE": {MinArgs: 1, MaxArgs: -1, Result: commonType, NullAware: true,
+		Call: func(args []interface{}) (interface{}, error) {
+			for _, arg := range args {
+				if arg != nil {
+					return arg, nil
+				}
+			}
+			return nil, nil
+		}},
+	"NULLIF": {MinArgs: 2, MaxArgs: 2, NullAware: true,
+		Result: func(args []schema.ColumnType) (schema.ColumnType, error) {
+			if _, err := commonType(args); err != nil {
+				return "", err
+			}
+			return args[0], nil
+		},
+		Call: func(args []interface{}) (interface{}, error) {
+			if args[0] != nil && args[1] != nil && types.CompareValues(args[0], "=", args[1]) {
+				return nil, nil
+			}
+			return args[0], nil
+		}},
+
+	// Date and time functions
+	"NOW": {MinArgs: 0, MaxArgs: 0, Result: returns(schema.ColumnTypeText),
+		Call: func([]interface{}) (interface{}, error) {
+			return now().Format(timestampLayout), nil
+		}},
+	"CURRENT_DATE": {MinArgs: 0, MaxArgs: 0, Result: returns(schema.ColumnTypeDate),
+		Call: func([]interface{}) (interface{}, error) {
+			return now().Format(dateLayout), nil
+		}},
+	"CURRENT_TIME": {MinArgs: 0, MaxArgs: 0, Result: returns(schema.ColumnTypeTime),
+		Call: func([]interface{}) (interface{}, error) {
+			return now().Format(timeLayout), nil
+		}},
+	"DATE_PART": {MinArgs: 2, MaxArgs: 2, Args: []argKind{textArg, temporalArg}, Result: returns(schema.ColumnTypeInt),
+		Call: datePart},
+	"DATE_ADD": {MinArgs: 2, MaxArgs: 3, Args: []argKind{temporalArg, intArg, textArg}, Result: firstArgType,
+		Call: dateAdd},
+	"DATE_DIFF": {MinArgs: 2, MaxArgs: 2, Args: []argKind{temporalArg}, Result: returns(schema.ColumnTypeInt),
+		Call: func(args []interface{}) (interface{}, error) {
+			end, _, err := temporalValue(args[0])
+			if err != nil {
+				return nil, err
+			}
+			start, _, err := temporalValue(args[1])
+			if err != nil {
+				return nil, err
+			}
+			return int64(math.Floor(end.Sub(start).Hours() / 24)), nil
+		}},
+}
+
+// lookupFunction finds the scalar function a call names and checks its argument count
+func lookupFunction(call *ast.FunctionCall) (*function, error) {
+	fn, ok := functions[call.Name]
+	if !ok {
+		if IsAggregate(call.Name) {
+			return nil, fmt.Errorf("aggregate function %s is not allowed here", call.Name)
+		}
+		return nil, fmt.Errorf("unknown function: %s", call.Name)
+	}
+	if call.Star || call.Distinct {
+		return nil, fmt.Errorf("%s does not accept * or DISTINCT", call.Name)
+	}
+
+	n := len(call.Args)
+	if n < fn.MinArgs || (fn.MaxArgs >= 0 && n > fn.MaxArgs) {
+		switch {
+		case fn.MinArgs == fn.MaxArgs:
+			return nil, fmt.Errorf("%s takes %d argument(s), got %d", call.Name, fn.MinArgs, n)
+		case fn.MaxArgs < 0:
+			return nil, fmt.Errorf("%s takes at least %d argument(s), got %d", call.Name, fn.MinArgs, n)
+		default:
+			return nil, fmt.Errorf("%s takes %d to %d arguments, got %d", call.Name, fn.MinArgs, fn.MaxArgs, n)
+		}
+	}
+	return fn, nil
+}
+
+// aggregateFuncs lists the supported aggregate functions
+// They are computed by the AggregateNode, which the planner rewrites into column references.
+var aggregateFuncs = map[string]bool{
+	"COUNT": true,
+	"SUM":   true,
+	"AVG":   true,
+	"MIN":   true,
+	"MAX":   true,
+}
+
+// IsAggregate reports whether name (upper-cased) is an aggregate function
+func IsAggregate(name string) bool {
+	return aggregateFuncs[name]
+}
+
+// buildFunction builds the function for a scalar function call
+func buildFunction(call *ast.FunctionCall) (Func, error) {
+	fn, err := lookupFunction(call)
+	if err != nil {
+		return nil, err
+	}
+
+	args := make([]Func, len(call.Args))
+	for i, arg := range call.Args {
+		if args[i], err = Build(arg); err != nil {
+			return nil, err
+		}
+	}
+
+	name := call.Name
+	return func(row data.Row) (interface{}, error) {
+		values := make([]interface{}, len(args))
+		for i, arg := range args {
+			val, err := arg(row)
+			if err != nil {
+				return nil, err
+			}
+			if val == nil && !fn.NullAware {
+				return nil, nil
+			}
+			values[i] = val
+		}
+
+		result, err := fn.Call(values)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		return result, nil
+	}, nil
+}
+
+// functionType checks a call's argument types and returns its result type
+func functionType(call *ast.FunctionCall, columnType func(*ast.Identifier) schema.ColumnType) (schema.ColumnType, error) {
+	fn, err := lookupFunction(call)
+	if err != nil {
+		return "", err
+	}
+
+	argTypes := make([]schema.ColumnType, len(call.Args))
+	for i, arg := range call.Args {
+		if argTypes[i], err = Type(arg, columnType); err != nil {
+			return "", err
+		}
+		if kind := fn.argKind(i); !kind.accepts(argTypes[i]) {
+			return "", fmt.Errorf("%s argument %d must be %s, got %s", call.Name, i+1, kind, argTypes[i])
+		}
+	}
+
+	result, err := fn.Result(argTypes)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", call.Name, err)
+	}
+	return result, nil
+}
+
+// commonType is the type shared by values that may be used interchangeably (e.g. COALESCE
+// arguments): INT and FLOAT mix as FLOAT, and text mixes with the types stored as text
+func commonType(args []schema.ColumnType) (schema.ColumnType, error) {
+	var result schema.ColumnType
+	for _, t := range args {
+		switch {
+		case t == "" || t == result:
+		case result == "":
+			result = t
+		case isNumeric(t) && isNumeric(result):
+			result = schema.ColumnTypeFloat
+		case t == schema.ColumnTypeText && argKind(textArg).accepts(result):
+			result = schema.ColumnTypeText
+		case result == schema.ColumnTypeText && argKind(textArg).accepts(t):
+		default:
+			return "", fmt.Errorf("arguments have incompatible types %s and %s", result, t)
+		}
+	}
+	return result, nil
+}
+
+// isNumeric reports whether t is INT or FLOAT
+func isNumeric(t schema.ColumnType) bool {
+	return t == schema.ColumnTypeInt || t == schema.ColumnTypeFloat
+}
+
+// textValue returns a string argument
+func textValue(val interface{}) (string, error) {
+	s, ok := val.(string)
+	if !ok {
+		return "", fmt.Errorf("expected text, got %s", describe(val))
+	}
+	return s, nil
+}
+
+// textValues returns string arguments
+func textValues(vals []interface{}) ([]string, error) {
+	out := make([]string, len(vals))
+	for i, val := range vals {
+		s, err := textValue(val)
+		if err != nil {
+			return nil, err
+		}
+		out[i] = s
+	}
+	return out, nil
+}
+
+// numberValue returns a numeric argument as a float
+func numberValue(val interface{}) (float64, error) {
+	f, ok := types.NormalizeToFloat(val)
+	if !ok {
+		return 0, fmt.Errorf("expected a number, got %s", describe(val))
+	}
+	return f, nil
+}
+
+// intValue returns an integral argument
+func intValue(val interface{}) (int64, error) {
+	i, ok := types.NormalizeToInt64(val)
+	if !ok {
+		return 0, fmt.Errorf("expected an integer, got %s", describe(val))
+	}
+	return i, nil
+}
+
+// substr implements SUBSTR(text, start [, length]) with 1-based positions
+// Positions before the start of the string count towards the length, as in PostgreSQL.
+func substr(args []interface{}) (interface{}, error) {
+	s, err := textValue(args[0])
+	if err != nil {
+		return nil, err
+	}
+	start, err := intValue(args[1])
+	if err != nil {
+		return nil, err
+	}
+
+	runes := []rune(s)
+	end := int64(len(runes)) + 1
+	if len(args) == 3 {
+		length, err := intValue(args[2])
+		if err != nil {
+			return nil, err
+		}
+		if length < 0 {
+			return nil, fmt.Errorf("negative substring length %d", length)
+		}
+		if start+length < end {
+			end = start + length
+		}
+	}
+	if start < 1 {
+		start = 1
+	}
+	if end <= start {
+		return "", nil
+	}
+	return string(runes[start-1 : end-1]), nil
+}
+
+// trim builds TRIM/LTRIM/RTRIM(text [, characters]); spaces are trimmed by default
+func trim(cut func(s, cutset string) string) func([]interface{}) (interface{}, error) {
+	return func(args []interface{}) (interface{}, error) {
+		s, err := textValues(args)
+		if err != nil {
+			return nil, err
+		}
+		cutset := " "
+		if len(s) == 2 {
+			cutset = s[1]
+		}
+		return cut(s[0], cutset), nil
+	}
+}
+
+// round implements ROUND(number [, digits]), rounding half away from zero
+// Integers stay integers; negative digits round to tens, hundreds, ...
+func round(args []interface{}) (interface{}, error) {
+	var digits int64
+	if len(args) == 2 {
+		var err error
+		if digits, err = intValue(args[1]); err != nil {
+			return nil, err
+		}
+	}
+
+	i, isInt := integer(args[0])
+	if isInt && digits >= 0 {
+		return i, nil
+	}
+
+	f, err := numberValue(args[0])
+	if err != nil {
+		return nil, err
+	}
+	scale := math.Pow(10, float64(digits))
+	rounded := math.Round(f*scale) / scale
+	if isInt {
+		return int64(rounded), nil
+	}
+	return rounded, nil
+}
+
+// temporalValue parses a date, time or timestamp string
+// Returns the parsed time and the layout it was written in.
+func temporalValue(val interface{}) (time.Time, string, error) {
+	s, err := textValue(val)
+	if err != nil {
+		return time.Time{}, "", err
+	}
+	for _, layout := range []string{dateLayout, timestampLayout, timeLayout, "15:04"} {
+		if t, err := time.Parse(layout, s); err == nil {
+			if layout == "15:04" {
+				layout = timeLayout
+			}
+			return t, layout, nil
+		}
+	}
+	return time.Time{}, "", fmt.Errorf("invalid date or time '%s'", s)
+}
+
+// datePart implements DATE_PART(field, value)
+// Fields: year, quarter, month, day, dow (0 = Sunday), doy, hour, minute, second.
+func datePart(args []interface{}) (interface{}, error) {
+	field, err := textValue(args[0])
+	if err != nil {
+		return nil, err
+	}
+	t, _, err := temporalValue(args[1])
+	if err != nil {
+		return nil, err
+	}
+
+	switch strings.ToLower(field) {
+	case "year":
+		return int64(t.Year()), nil
+	case "quarter":
+		return int64((t.Month()-1)/3 + 1), nil
+	case "month":
+		return int64(t.Month()), nil
+	case "day":
+		return int64(t.Day()), nil
+	case "dow":
+		return int64(t.Weekday()), nil
+	case "doy":
+		return int64(t.YearDay()), nil
+	case "hour":
+		return int64(t.Hour()), nil
+	case "minute":
+		return int64(t.Minute()), nil
+	case "second":
+		return int64(t.Second()), nil
+	default:
+		return nil, fmt.Errorf("unknown date part '%s'", field)
+	}
+}
+
+// dateAdd implements DATE_ADD(value, amount [, unit]); the unit defaults to 'day'
+// Units: year, month, week, day, hour, minute, second. The result keeps the value's format.
+func dateAdd(args []interface{}) (interface{}, error) {
+	t, layout, err := temporalValue(args[0])
+	if err != nil {
+		return nil, err
+	}
+	amount, err := intValue(args[1])
+	if err != nil {
+		return nil, err
+	}
+	unit := "day"
+	if len(args) == 3 {
+		if unit, err = textValue(args[2]); err != nil {
+			return nil, err
+		}
+	}
+
+	n := int(amount)
+	switch strings.ToLower(unit) {
+	case "year":
+		t = t.AddDate(n, 0, 0)
+	case "month":
+		t = t.AddDate(0, n, 0)
+	case "week":
+		t = t.AddDate(0, 0, 7*n)
+	case "day":
+		t = t.AddDate(0, 0, n)
+	case "hour":
+		t = t.Add(time.Duration(amount) * time.Hour)
+	case "minute":
+		t = t.Add(time.Duration(amount) * time.Minute)
+	case "second":
+		t = t.Add(time.Duration(amount) * time.Second)
+	default:
+		return nil, fmt.Errorf("unknown date unit '%s'", unit)
+	}
+	return t.Format(layout), nil
+}
@@ -0,0 +1,249 @@
+package expression
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/leengari/mini-rdbms/internal/domain/data"
+	"github.com/leengari/mini-rdbms/internal/parser/ast"
+	"github.com/leengari/mini-rdbms/internal/plan"
+	"github.com/leengari/mini-rdbms/internal/util/types"
+)
+
+// SubqueryKind is the way an expression uses a subquery's rows
+type SubqueryKind int
+
+const (
+	ScalarSubquery SubqueryKind = iota // (SELECT ...) used as a value
+	ExistsSubquery                     // EXISTS (SELECT ...)
+	InSubquery                         // value [NOT] IN (SELECT ...)
+)
+
+// Subquery is a planned subquery inside an expression
+// The planner replaces each parsed subquery with a Subquery (or a SemiJoin); the
+// embedded expression is the original, so the node still prints as written.
+// The subquery runs through Plan, once per outer row when it is correlated.
+type Subquery struct {
+	ast.Expression
+	Kind SubqueryKind
+	Left ast.Expression // Value tested by IN
+	Not  bool           // NOT IN
+	Plan *plan.Subquery
+}
+
+// SemiJoin evaluates IN (SELECT ...) or EXISTS (SELECT ...) against a hash table
+// The subquery runs once, uncorrelated: a correlated subquery whose only references to the
+// outer query are equalities (inner = outer) is rewritten to also return the inner side of
+// each equality, and Keys holds the outer sides. Its rows are grouped by those columns, so
+// each outer row costs a lookup instead of an execution. With NOT (or NOT EXISTS) it is an
+// anti-join.
+type SemiJoin struct {
+	ast.Expression
+	Value ast.Expression   // Value tested by IN; nil for EXISTS
+	Not   bool             // NOT IN
+	Keys  []ast.Expression // Outer side of each correlation equality
+	Plan  *plan.Subquery   // Returns the IN value column (if any), then one column per key
+}
+
+// buildSubquery builds the function for a scalar subquery, EXISTS or IN (SELECT ...)
+// A scalar subquery yields NULL when it returns no rows and fails when it returns more than one.
+func buildSubquery(sub *Subquery) (Func, error) {
+	if len(sub.Plan.Columns) == 0 {
+		return nil, fmt.Errorf("subquery returns no columns")
+	}
+	column := sub.Plan.Columns[0].Name
+
+	switch sub.Kind {
+	case ScalarSubquery:
+		return func(row data.Row) (interface{}, error) {
+			rows, err := sub.Plan.Rows(row)
+			if err != nil {
+				return nil, err
+			}
+			switch len(rows) {
+			case 0:
+				return nil, nil
+			case 1:
+				return rows[0].Data[column], nil
+			default:
+				return nil, fmt.Errorf("more than one row returned by a subquery used as an expression")
+			}
+		}, nil
+
+	case ExistsSubquery:
+		return func(row data.Row) (interface{}, error) {
+			rows, err := sub.Plan.Rows(row)
+			if err != nil {
+				return nil, err
+			}
+			return len(rows) > 0, nil
+		}, nil
+	}
+
+	left, err := Build(sub.Left)
+	if err != nil {
+		return nil, err
+	}
+	not := sub.Not
+	return func(row data.Row) (interface{}, error) {
+		val, err := left(row)
+		if err != nil {
+			return nil, err
+		}
+		rows, err := sub.Plan.Rows(row)
+		if err != nil {
+			return nil, err
+		}
+
+		set := newValueSet()
+		for _, r := range rows {
+			set.add(r.Data[column])
+		}
+		return set.in(val, not), nil
+	}, nil
+}
+
+// buildSemiJoin builds the function for a SemiJoin
+// The hash table is built the first time the function runs.
+func buildSemiJoin(semi *SemiJoin) (Func, error) {
+	var value Func
+	if semi.Value != nil {
+		var err error
+		if value, err = Build(semi.Value); err != nil {
+			return nil, err
+		}
+	}
+	keys := make([]Func, len(semi.Keys))
+	for i, k := range semi.Keys {
+		var err error
+		if keys[i], err = Build(k); err != nil {
+			return nil, err
+		}
+	}
+
+	// Key columns follow the IN value column
+	columns := semi.Plan.Columns
+	keyColumns := columns
+	if value != nil {
+		if len(columns) == 0 {
+			return nil, fmt.Errorf("subquery returns no columns")
+		}
+		keyColumns = columns[1:]
+	}
+
+	var groups map[string]*valueSet
+	not := semi.Not
+	return func(row data.Row) (interface{}, error) {
+		if groups == nil {
+			rows, err := semi.Plan.Rows(row)
+			if err != nil {
+				return nil, err
+			}
+			groups = make(map[string]*valueSet)
+			for _, r := range rows {
+				values := make([]interface{}, len(keyColumns))
+				for i, col := range keyColumns {
+					values[i] = r.Data[col.Name]
+				}
+				key, ok := tupleKey(values)
+				if !ok {
+					continue // A NULL key never equals an outer value
+				}
+				if groups[key] == nil {
+					groups[key] = newValueSet()
+				}
+				if value != nil {
+					groups[key].add(r.Data[columns[0].Name])
+				}
+			}
+		}
+
+		values := make([]interface{}, len(keys))
+		for i, k := range keys {
+			val, err := k(row)
+			if err != nil {
+				return nil, err
+			}
+			values[i] = val
+		}
+		key, ok := tupleKey(values)
+		group := groups[key]
+		if !ok {
+			group = nil
+		}
+
+		if value == nil {
+			return group != nil, nil
+		}
+		if group == nil {
+			// IN over no rows is false, whatever the value
+			return not, nil
+		}
+		val, err := value(row)
+		if err != nil {
+			return nil, err
+		}
+		return group.in(val, not), nil
+	}, nil
+}
+
+// valueSet is the set of values an IN subquery returned, hashed for lookup
+type valueSet struct {
+	values  map[string][]interface{} // Values by hash key
+	hasNull bool
+}
+
+func newValueSet() *valueSet {
+	return &valueSet{values: make(map[string][]interface{})}
+}
+
+// add inserts a value into the set
+func (s *valueSet) add(val interface{}) {
+	if val == nil {
+		s.hasNull = true
+		return
+	}
+	key := hashKey(val)
+	s.values[key] = append(s.values[key], val)
+}
+
+// in evaluates val [NOT] IN the set with SQL semantics
+// True when val equals a member; NULL (unknown) when it doesn't but val or a member is NULL.
+func (s *valueSet) in(val interface{}, not bool) interface{} {
+	if val == nil {
+		if len(s.values) == 0 && !s.hasNull {
+			return not
+		}
+		return nil
+	}
+	for _, member := range s.values[hashKey(val)] {
+		if types.CompareValues(val, "=", member) {
+			return !not
+		}
+	}
+	if s.hasNull {
+		return nil
+	}
+	return not
+}
+
+// hashKey returns a key under which equal values hash alike
+// Numbers hash by value, so 2, int64(2) and 2.0 share a key.
+func hashKey(val interface{}) string {
+	if f, ok := types.NormalizeToFloat(val); ok {
+		return fmt.Sprintf("n:%v", f)
+	}
+	return fmt.Sprintf("%T:%v", val, val)
+}
+
+// tupleKey returns the hash key of a tuple of values; false when any of them is NULL
+func tupleKey(values []interface{}) (string, bool) {
+	parts := make([]string, len(values))
+	for i, val := range values {
+		if val == nil {
+			return "", false
+		}
+		parts[i] = hashKey(val)
+	}
+	return strings.Join(parts, "\x00"), true
+}
@@ -0,0 +1,234 @@
+package planner
+
+import (
+	"math"
+
+	"github.com/leengari/mini-rdbms/internal/domain/data"
+	"github.com/leengari/mini-rdbms/internal/domain/schema"
+	"github.com/leengari/mini-rdbms/internal/parser/ast"
+	"github.com/leengari/mini-rdbms/internal/plan"
+)
+
+// orderedLookup returns the lookup an ordered index can answer for the WHERE conditions:
+// equality on its leading columns, then an optional range (=, <, <=, >, >=, BETWEEN)
+// on the next one. Returns nil if no condition restricts the first column.
+func orderedLookup(table *schema.Table, idx *data.OrderedIndex, conjuncts []ast.Expression) *indexLookup {
+	var r data.IndexRange
+	for _, col := range idx.Columns {
+		if value, ok := equalityOn(table, col, conjuncts); ok {
+			r.Equal = append(r.Equal, value)
+			continue
+		}
+		r.Low, r.High = boundsOn(table, col, conjuncts)
+		break
+	}
+
+	if len(r.Equal) == 0 && r.Low == nil && r.High == nil {
+		return nil
+	}
+	return &indexLookup{Index: idx, IndexRange: r}
+}
+
+// orderedLookupCost ranks a lookup through an ordered index: a full key of a unique index
+// finds at most one row; otherwise the more columns are bound the better, always after
+// any list of values and before a range over a hash index
+func orderedLookupCost(lookup *indexLookup) int {
+	r := lookup.IndexRange
+	if lookup.Index.Unique && len(r.Equal) == len(lookup.Index.Columns) {
+		return 1
+	}
+	bound := 2 * len(r.Equal)
+	if r.Low != nil || r.High != nil {
+		bound++
+	}
+	return math.MaxInt - 1 - bound
+}
+
+// sortedLookup picks the scan that returns rows already in the ORDER BY order, if any
+// The lookup chosen for the WHERE clause is kept when it reads an ordered index in that
+// order, or when it is a list of values (which beats reading an index for its order).
+// Otherwise the cheapest ordered index whose columns give the order is read instead,
+// through its WHERE lookup if it has one or from end to end.
+// Returns the lookup to use, whether to read it descending and whether it is sorted.
+func sortedLookup(table *schema.Table, conjuncts []ast.Expression, best *indexLookup, order []plan.SortKey) (*indexLookup, bool, bool) {
+	descending, ok := indexOrderDirection(table, order)
+	if !ok {
+		return best, false, false
+	}
+	if best != nil && best.Index != nil && providesOrder(best, order) {
+		return best, descending, true
+	}
+	if best != nil && best.Range == nil {
+		return best, false, false
+	}
+
+	var choice *indexLookup
+	for _, idx := range table.OrderedIndexList() {
+		lookup := orderedLookup(table, idx, conjuncts)
+		if lookup == nil {
+			lookup = &indexLookup{Index: idx}
+		}
+		if providesOrder(lookup, order) && (choice == nil || lookupCost(lookup) < lookupCost(choice)) {
+			choice = lookup
+		}
+	}
+	if choice == nil {
+		return best, false, false
+	}
+	return choice, descending, true
+}
+
+// indexOrderDirection reports whether ORDER BY keys could be served by an ordered index
+// of the table, and in which direction
+// Every key must be a column of the table (not BOOL, whose values the sort leaves
+// unordered), all in the same direction with the default NULL placement, which sorts
+// NULLs as the largest value like the index does.
+func indexOrderDirection(table *schema.Table, order []plan.SortKey) (bool, bool) {
+	if len(order) == 0 {
+		return false, false
+	}
+	descending := order[0].Descending
+	for _, key := range order {
+		if key.Descending != descending || key.NullsFirst != key.Descending {
+			return false, false
+		}
+		if key.Table != "" && key.Table != table.Name {
+			return false, false
+		}
+		col := findColumnInSchema(table, key.Column)
+		if col == nil || col.Type == schema.ColumnTypeBool {
+			return false, false
+		}
+	}
+	return descending, true
+}
+
+// providesOrder reports whether reading a lookup's ordered index returns rows in the
+// order of the ORDER BY keys, exactly as the stable sort would
+// The keys must be the index's trailing columns, and any index column before them must
+// be fixed by equality; rows with equal keys then come in storage order either way.
+func providesOrder(lookup *indexLookup, order []plan.SortKey) bool {
+	cols := lookup.Index.Columns
+	skip := len(cols) - len(order)
+	if skip < 0 || skip > len(lookup.IndexRange.Equal) {
+		return false
+	}
+	for i, key := range order {
+		if cols[skip+i] != key.Column {
+			return false
+		}
+	}
+	return true
+}
+
+// equalityOn returns the value of a "column = literal" condition on the column
+func equalityOn(table *schema.Table, column string, conjuncts []ast.Expression) (interface{}, bool) {
+	for _, conjunct := range conjuncts {
+		if col, op, value, ok := columnComparison(table, conjunct); ok && col == column && op == "=" {
+			return value, true
+		}
+	}
+	return nil, false
+}
+
+// boundsOn returns the tightest range the conditions place on the column (nil for an open end)
+func boundsOn(table *schema.Table, column string, conjuncts []ast.Expression) (low, high *data.Bound) {
+	for _, conjunct := range conjuncts {
+		if between, ok := conjunct.(*ast.BetweenExpression); ok {
+			ident, ok := between.Expr.(*ast.Identifier)
+			if !ok || between.Not || ident.Value != column || !inTable(table, ident) {
+				continue
+			}
+			lowLit, ok := between.Low.(*ast.Literal)
+			if !ok || lowLit.Value == nil {
+				continue
+			}
+			highLit, ok := between.High.(*ast.Literal)
+			if !ok || highLit.Value == nil {
+				continue
+			}
+			low = tighterLow(low, data.Bound{Value: lowLit.Value, Inclusive: true})
+			high = tighterHigh(high, data.Bound{Value: highLit.Value, Inclusive: true})
+			continue
+		}
+
+		col, op, value, ok := columnComparison(table, conjunct)
+		if !ok || col != column {
+			continue
+		}
+		switch op {
+		case ">":
+			low = tighterLow(low, data.Bound{Value: value})
+		case ">=":
+			low = tighterLow(low, data.Bound{Value: value, Inclusive: true})
+		case "<":
+			high = tighterHigh(high, data.Bound{Value: value})
+		case "<=":
+			high = tighterHigh(high, data.Bound{Value: value, Inclusive: true})
+		}
+	}
+	return low, high
+}
+
+// tighterLow returns the more restrictive of two lower bounds
+func tighterLow(cur *data.Bound, b data.Bound) *data.Bound {
+	if cur == nil {
+		return &b
+	}
+	if c := data.CompareKeyValues(b.Value, cur.Value); c > 0 || (c == 0 && !b.Inclusive) {
+		return &b
+	}
+	return cur
+}
+
+// tighterHigh returns the more restrictive of two upper bounds
+func tighterHigh(cur *data.Bound, b data.Bound) *data.Bound {
+	if cur == nil {
+		return &b
+	}
+	if c := data.CompareKeyValues(b.Value, cur.Value); c < 0 || (c == 0 && !b.Inclusive) {
+		return &b
+	}
+	return cur
+}
+
+// columnComparison splits "column op literal" (or "literal op column", with op flipped)
+// into its parts, for the comparison operators an ordered index can answer
+func columnComparison(table *schema.Table, expr ast.Expression) (string, string, interface{}, bool) {
+	bin, ok := expr.(*ast.BinaryExpression)
+	if !ok {
+		return "", "", nil, false
+	}
+
+	op := bin.Operator
+	ident, identOk := bin.Left.(*ast.Identifier)
+	lit, litOk := bin.Right.(*ast.Literal)
+	if !identOk || !litOk {
+		ident, identOk = bin.Right.(*ast.Identifier)
+		lit, litOk = bin.Left.(*ast.Literal)
+		op = flippedComparisons[op]
+	}
+	if !identOk || !litOk || lit.Value == nil || !inTable(table, ident) {
+		return "", "", nil, false
+	}
+
+	switch op {
+	case "=", "<", "<=", ">", ">=":
+		return ident.Value, op, lit.Value, true
+	}
+	return "", "", nil, false
+}
+
+// flippedComparisons maps a comparison operator to the one that holds with its operands swapped
+var flippedComparisons = map[string]string{
+	"=":  "=",
+	"<":  ">",
+	"<=": ">=",
+	">":  "<",
+	">=": "<=",
+}
+
+// inTable reports whether a column reference may name a column of the table
+func inTable(table *schema.Table, ident *ast.Identifier) bool {
+	return ident.Table == "" || ident.Table == table.Name
+}
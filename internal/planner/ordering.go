@@ -0,0 +1,111 @@
+package planner
+
+import (
+	"fmt"
+
+	"github.com/leengari/mini-rdbms/internal/domain/schema"
+	"github.com/leengari/mini-rdbms/internal/parser/ast"
+	"github.com/leengari/mini-rdbms/internal/plan"
+	"github.com/leengari/mini-rdbms/internal/planner/expression"
+)
+
+// planOrdering wraps source in Sort and Limit nodes for the ORDER BY, LIMIT and OFFSET clauses
+// Resulting tree: LIMIT -> SORT -> source (either node is omitted when its clause is absent).
+// When agg is non-nil the rows being sorted are groups, so keys must be group columns or aggregates.
+// An unqualified key naming a SELECT alias sorts by that field (aliases maps alias to key).
+// presorted means source already returns rows in ORDER BY order (an ordered index scan),
+// so the Sort node is left out.
+func planOrdering(stmt *ast.SelectStatement, db *schema.Database, source plan.Node, agg *plan.AggregateNode, aliases map[string]plan.SortKey, presorted bool) (plan.Node, error) {
+	node := source
+
+	if len(stmt.OrderBy) > 0 && !presorted {
+		tables := queryTables(stmt)
+
+		keys := make([]plan.SortKey, len(stmt.OrderBy))
+		for i, item := range stmt.OrderBy {
+			var key plan.SortKey
+			switch expr := item.Expr.(type) {
+			case *ast.Identifier:
+				if aliasKey, ok := aliases[expr.Value]; ok && expr.Table == "" {
+					key = aliasKey
+					break
+				}
+				if agg != nil {
+					if !matchesGroupKey(expr, agg.GroupBy) {
+						return nil, fmt.Errorf("ORDER BY column %s must appear in the GROUP BY clause or be used in an aggregate function", expr.String())
+					}
+				} else if _, _, err := resolveColumn(expr, tables, db); err != nil {
+					return nil, fmt.Errorf("invalid ORDER BY key: %w", err)
+				}
+				key = plan.SortKey{Table: expr.Table, Column: expr.Value}
+			case *ast.FunctionCall:
+				if !expression.IsAggregate(expr.Name) {
+					return nil, fmt.Errorf("unsupported ORDER BY key: %s (select it with an alias and order by the alias)", expr.String())
+				}
+				// Computed by the AggregateNode (isAggregateQuery counts ORDER BY aggregates)
+				key = plan.SortKey{Column: aggregateName(expr)}
+			default:
+				return nil, fmt.Errorf("unsupported ORDER BY key: %s", item.Expr.String())
+			}
+
+			keys[i] = sortDirection(key, item)
+		}
+
+		sortNode := plan.NewSortNode(node, keys)
+		sortNode.Metadata()["keys"] = len(keys)
+		node = sortNode
+	}
+
+	return planLimit(node, stmt.Limit, stmt.Offset), nil
+}
+
+// scanOrder returns the ORDER BY keys when every one is a plain column reference
+// (nil otherwise), for the planner to look for an index that returns rows in that order
+func scanOrder(stmt *ast.SelectStatement, aliases map[string]plan.SortKey) []plan.SortKey {
+	var keys []plan.SortKey
+	for _, item := range stmt.OrderBy {
+		ident, ok := item.Expr.(*ast.Identifier)
+		if !ok {
+			return nil
+		}
+		if _, isAlias := aliases[ident.Value]; isAlias && ident.Table == "" {
+			return nil
+		}
+		keys = append(keys, sortDirection(plan.SortKey{Table: ident.Table, Column: ident.Value}, item))
+	}
+	return keys
+}
+
+// sortDirection sets a sort key's direction and NULL placement from its ORDER BY item
+// Default NULL placement matches PostgreSQL: NULLs sort as the largest value.
+func sortDirection(key plan.SortKey, item *ast.OrderByItem) plan.SortKey {
+	key.Descending = item.Descending
+	key.NullsFirst = item.Descending
+	switch item.Nulls {
+	case "FIRST":
+		key.NullsFirst = true
+	case "LAST":
+		key.NullsFirst = false
+	}
+	return key
+}
+
+// planLimit wraps node in a LimitNode for the LIMIT and OFFSET clauses (node is returned when both are absent)
+func planLimit(node plan.Node, limit, offset *int) plan.Node {
+	if limit == nil && offset == nil {
+		return node
+	}
+
+	n, skip := -1, 0
+	if limit != nil {
+		n = *limit
+	}
+	if offset != nil {
+		skip = *offset
+	}
+
+	limitNode := plan.NewLimitNode(node, n, skip)
+	limitNode.Metadata()["limit"] = n
+	limitNode.Metadata()["offset"] = skip
+	return limitNode
+}
@@ -4,10 +4,12 @@ import (
 	"fmt"
 
 	"github.com/leengari/mini-rdbms/internal/domain/data"
+	"github.com/leengari/mini-rdbms/internal/domain/errors"
 	"github.com/leengari/mini-rdbms/internal/domain/schema"
 	"github.com/leengari/mini-rdbms/internal/domain/transaction"
 	"github.com/leengari/mini-rdbms/internal/parser/ast"
 	"github.com/leengari/mini-rdbms/internal/plan"
+	"github.com/leengari/mini-rdbms/internal/planner/expression"
 	"github.com/leengari/mini-rdbms/internal/planner/predicate"
 	"github.com/leengari/mini-rdbms/internal/query/operations/join"
 	"github.com/leengari/mini-rdbms/internal/query/operations/projection"
@@ -18,59 +20,175 @@ import (
 func Plan(stmt ast.Statement, db *schema.Database, tx *transaction.Transaction) (plan.Node, error) {
 	switch s := stmt.(type) {
 	case *ast.SelectStatement:
-		return planSelect(s, db, tx)
+		node, _, err := planSelect(s, db, tx, nil, nil)
+		if err != nil {
+			return nil, err
+		}
+		return node, nil
+	case *ast.SetOperationStatement:
+		node, _, err := planSetOperation(s, db, tx, nil)
+		if err != nil {
+			return nil, err
+		}
+		return node, nil
 	case *ast.InsertStatement:
 		return planInsert(s, db, tx)
 	case *ast.UpdateStatement:
 		return planUpdate(s, db, tx)
 	case *ast.DeleteStatement:
 		return planDelete(s, db, tx)
+	case *ast.CreateTableStatement:
+		return planCreateTable(s, db, tx)
+	case *ast.DropTableStatement:
+		return planDropTable(s, db, tx)
+	case *ast.AlterTableStatement:
+		return planAlterTable(s, db, tx)
+	case *ast.CreateIndexStatement:
+		return planCreateIndex(s, db, tx)
+	case *ast.DropIndexStatement:
+		return planDropIndex(s, db, tx)
 	default:
 		return nil, fmt.Errorf("unsupported statement type: %T", stmt)
 	}
 }
 
-func planSelect(stmt *ast.SelectStatement, db *schema.Database, tx *transaction.Transaction) (plan.Node, error) {
-	// 1. Validate tables exist
+// planSelect plans a SELECT and describes the rows it returns
+// outer is the scope of the enclosing query when planning a subquery (nil otherwise);
+// ctes are the common table expressions defined by enclosing queries.
+func planSelect(stmt *ast.SelectStatement, db *schema.Database, tx *transaction.Transaction, outer *scope, ctes withTables) (*plan.SelectNode, *selectOutput, error) {
+	// 1. Plan common table expressions and derived tables, then validate tables exist
+	var commonTables []*plan.CommonTable
+	if stmt.With != nil {
+		var err error
+		if db, ctes, commonTables, err = planCommonTables(stmt.With, db, tx, ctes); err != nil {
+			return nil, nil, err
+		}
+	}
+	base := db
+	db, derived, err := planDerivedTables(stmt, db, tx, ctes.nested())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Derived tables and CTEs are read through their own scan nodes instead of a table scan
+	leaves := make(map[string]plan.Node)
+	for alias, scan := range derived {
+		leaves[alias] = scan
+	}
+	for _, name := range queryTables(stmt) {
+		if _, ok := leaves[name]; ok {
+			continue
+		}
+		scan, ok, err := ctes.scan(name)
+		if err != nil {
+			return nil, nil, err
+		}
+		if ok {
+			leaves[name] = scan
+		}
+	}
 	tableName := stmt.TableName.Value
-	_, ok := db.Tables[tableName]
+	table, ok := db.Tables[tableName]
 	if !ok {
-		return nil, fmt.Errorf("table not found: %s", tableName)
+		return nil, nil, errors.NewTableNotFoundError(tableName)
+	}
+
+	// Plan subqueries in the SELECT list, WHERE and HAVING as subtrees of their own
+	whereSubqueries := containsSubquery(stmt.Where)
+	sp := &subqueryPlanner{db: base, tx: tx, ctes: ctes.nested(), scope: newScope(stmt, db, outer), outerTable: tableName}
+	if stmt, err = sp.rewriteSelect(stmt); err != nil {
+		return nil, nil, err
 	}
 
 	// 2. Build Predicate
-	var pred func(data.Row) bool
+	var pred func(data.Row) (bool, error)
+	if containsWindow(stmt.Where) || containsWindow(stmt.Having) {
+		return nil, nil, fmt.Errorf("window functions are only allowed in the SELECT list")
+	}
 	if stmt.Where != nil {
+		if err := expression.Check(stmt.Where, columnTypes(queryTables(stmt), db)); err != nil {
+			return nil, nil, fmt.Errorf("invalid WHERE clause: %w", err)
+		}
 		p, err := predicate.Build(stmt.Where)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 		pred = p
 	}
 
-	// 3. Build Projection
+	// 3. Build Projection; expressions become computed columns projected by name
+	aggregating := isAggregateQuery(stmt)
 	var proj *projection.Projection
-	if len(stmt.Fields) == 1 && stmt.Fields[0].Value == "*" {
+	var computed []computedField
+	var windows []windowField
+	aliases := make(map[string]plan.SortKey) // ORDER BY keys for SELECT aliases
+	if isSelectAll(stmt) {
+		if aggregating {
+			return nil, nil, fmt.Errorf("SELECT * cannot be used with GROUP BY or aggregate functions")
+		}
 		proj = projection.NewProjection()
 	} else {
 		proj = &projection.Projection{
 			SelectAll: false,
 			Columns:   make([]projection.ColumnRef, len(stmt.Fields)),
 		}
-		for i, f := range stmt.Fields {
-			proj.Columns[i] = projection.ColumnRef{
-				Table:  f.Table,
-				Column: f.Value,
+		for i, field := range stmt.Fields {
+			expr, alias := unwrapAlias(field)
+			if window, ok := expr.(*ast.WindowExpression); ok {
+				// Window functions are computed by the WindowNode under their output name
+				name := alias
+				if name == "" {
+					name = expr.String()
+				}
+				windows = append(windows, windowField{Name: name, Expr: window})
+				proj.Columns[i] = projection.ColumnRef{Column: name}
+				aliases[alias] = plan.SortKey{Column: name}
+				continue
+			}
+			if containsWindow(expr) {
+				return nil, nil, fmt.Errorf("window function in %s must be a SELECT field of its own", expr.String())
+			}
+			if call, ok := expr.(*ast.FunctionCall); ok && expression.IsAggregate(call.Name) {
+				// Aggregates are computed by the AggregateNode under their canonical name
+				proj.Columns[i] = projection.ColumnRef{Column: aggregateName(call), Alias: alias}
+				aliases[alias] = plan.SortKey{Column: aggregateName(call)}
+				continue
+			}
+
+			switch f := expr.(type) {
+			case *ast.Identifier:
+				proj.Columns[i] = projection.ColumnRef{
+					Table:  f.Table,
+					Column: f.Value,
+					Alias:  alias,
+				}
+				aliases[alias] = plan.SortKey{Table: f.Table, Column: f.Value}
+			default:
+				// Arithmetic and scalar function calls are computed columns
+				name := alias
+				if name == "" {
+					name = expr.String()
+				}
+				computed = append(computed, computedField{Name: name, Expr: expr})
+				proj.Columns[i] = projection.ColumnRef{Column: name}
+				aliases[alias] = plan.SortKey{Column: name}
 			}
 		}
+		delete(aliases, "")
+	}
+	windowing := len(windows) > 0
+	if windowing && aggregating {
+		return nil, nil, fmt.Errorf("window functions cannot be combined with GROUP BY or aggregate functions")
 	}
 
 	// 4. Build tree structure
 	selectNode := &plan.SelectNode{
-		TableName:   tableName,
-		Predicate:   pred,
-		Projection:  proj,
-		Transaction: tx,
+		TableName:    tableName,
+		Predicate:    pred,
+		Projection:   proj,
+		Transaction:  tx,
+		Subqueries:   sp.planned,
+		CommonTables: commonTables,
 	}
 
 	// Attach metadata
@@ -79,6 +197,7 @@ func planSelect(stmt *ast.SelectStatement, db *schema.Database, tx *transaction.
 	selectNode.Metadata()["estimated_rows"] = 1000 // Scaffold: naive estimate
 
 	// 5. Build JOINs as tree children
+	var source plan.Node
 	if len(stmt.Joins) > 0 {
 		// Create base scan node for left table
 		// Note: We don't push down the full filter if there are joins,
@@ -93,31 +212,34 @@ func planSelect(stmt *ast.SelectStatement, db *schema.Database, tx *transaction.
 
 		// Build JOIN tree
 		currentNode := plan.Node(leftScan)
+		if scan, ok := leaves[tableName]; ok {
+			currentNode = scan
+		}
 
 		for _, joinClause := range stmt.Joins {
 			// Validate join table
 			joinTableName := joinClause.RightTable.Value
 			_, ok := db.Tables[joinTableName]
 			if !ok {
-				return nil, fmt.Errorf("right table not found: %s", joinTableName)
+				return nil, nil, errors.NewTableNotFoundError(joinTableName)
 			}
 
 			// Parse ON condition
 			binExpr, ok := joinClause.OnCondition.(*ast.BinaryExpression)
 			if !ok {
-				return nil, fmt.Errorf("JOIN ON condition must be a comparison expression")
+				return nil, nil, fmt.Errorf("JOIN ON condition must be a comparison expression")
 			}
 			if binExpr.Operator != "=" {
-				return nil, fmt.Errorf("JOIN ON condition must use = operator")
+				return nil, nil, fmt.Errorf("JOIN ON condition must use = operator")
 			}
 
 			leftIdent, ok := binExpr.Left.(*ast.Identifier)
 			if !ok {
-				return nil, fmt.Errorf("left side of JOIN condition must be an identifier")
+				return nil, nil, fmt.Errorf("left side of JOIN condition must be an identifier")
 			}
 			rightIdent, ok := binExpr.Right.(*ast.Identifier)
 			if !ok {
-				return nil, fmt.Errorf("right side of JOIN condition must be an identifier")
+				return nil, nil, fmt.Errorf("right side of JOIN condition must be an identifier")
 			}
 
 			// Convert string type to enum
@@ -132,7 +254,7 @@ func planSelect(stmt *ast.SelectStatement, db *schema.Database, tx *transaction.
 			case "FULL":
 				jt = join.JoinTypeFull
 			default:
-				return nil, fmt.Errorf("unsupported JOIN type: %s", joinClause.JoinType)
+				return nil, nil, fmt.Errorf("unsupported JOIN type: %s", joinClause.JoinType)
 			}
 
 			// Create scan node for right table
@@ -142,78 +264,263 @@ func planSelect(stmt *ast.SelectStatement, db *schema.Database, tx *transaction.
 			}
 			rightScan.Metadata()["scan_type"] = "sequential" // Scaffold: always sequential
 			rightScan.Metadata()["table"] = joinTableName
+			right := plan.Node(rightScan)
+			if scan, ok := leaves[joinTableName]; ok {
+				right = scan
+			}
 
 			// Create JOIN node with left and right children
 			joinNode := plan.NewJoinNode(
 				currentNode,
-				rightScan,
+				right,
 				jt,
 				leftIdent.Value,
 				rightIdent.Value,
 			)
-			joinNode.Metadata()["join_algorithm"] = "nested_loop" // Scaffold: always nested loop
+			joinNode.Algorithm = selectJoinAlgorithm(joinNode, db)
+			joinNode.Metadata()["join_algorithm"] = string(joinNode.Algorithm)
 			joinNode.Metadata()["left_table"] = tableName
 			joinNode.Metadata()["right_table"] = joinTableName
 
 			currentNode = joinNode
 		}
 
-		// Add the final JOIN tree as child of SelectNode
-		selectNode.AddChild(currentNode)
+		source = currentNode
 	}
 
-	return selectNode, nil
+	// 6. Filter below any aggregate/window/compute/sort/limit: single-table queries read through a
+	// scan node (an index scan when the WHERE clause allows it), JOINs get a filter node.
+	// A WHERE clause with subqueries is not pushed into the scan, which holds the table's
+	// lock while filtering; derived tables and CTEs are read through their own scan nodes.
+	ordering := len(stmt.OrderBy) > 0 || stmt.Limit != nil || stmt.Offset != nil
+	computing := len(computed) > 0
+	shaping := aggregating || windowing || computing || ordering
+	presorted := false // the scan returns rows in ORDER BY order, so no sort is needed
+	if len(stmt.Joins) == 0 {
+		var leaf plan.Node
+		scanPred := pred
+		if whereSubqueries {
+			scanPred = nil
+		}
+		scan, virtual := leaves[tableName]
+		if virtual {
+			leaf = scan
+		} else {
+			var order []plan.SortKey
+			if !aggregating && !windowing {
+				order = scanOrder(stmt, aliases)
+			}
+			leaf = planTableScan(table, stmt.Where, scanPred, tx, order)
+			if indexScan, ok := leaf.(*plan.IndexScanNode); ok && indexScan.Ordered {
+				presorted = true
+			}
+		}
+
+		if virtual || whereSubqueries {
+			source = leaf
+			if pred != nil && shaping {
+				source = plan.NewFilterNode(leaf, pred)
+				selectNode.Predicate = nil
+			}
+		} else if _, isIndex := leaf.(*plan.IndexScanNode); isIndex || shaping {
+			source = leaf
+			selectNode.Predicate = nil
+		}
+	} else if pred != nil && shaping {
+		source = plan.NewFilterNode(source, pred)
+		selectNode.Predicate = nil
+	}
+
+	// 7. GROUP BY / aggregates or window functions, computed columns, then ORDER BY / LIMIT / OFFSET,
+	// run before projection
+	var agg *plan.AggregateNode
+	var window *plan.WindowNode
+	var compute *plan.ComputeNode
+	if shaping {
+		if aggregating {
+			agg, err = planAggregate(stmt, db, source, computed)
+			if err != nil {
+				return nil, nil, err
+			}
+			source = agg
+		}
+
+		if windowing {
+			window, err = planWindow(stmt, db, source, windows)
+			if err != nil {
+				return nil, nil, err
+			}
+			source = window
+		}
+
+		if computing {
+			source, err = planCompute(stmt, db, source, agg, computed)
+			if err != nil {
+				return nil, nil, err
+			}
+			compute, _ = source.(*plan.ComputeNode)
+		}
+
+		if ordering {
+			source, err = planOrdering(stmt, db, source, agg, aliases, presorted)
+			if err != nil {
+				return nil, nil, err
+			}
+		}
+	}
+
+	// Add the source tree (JOINs, aggregate, sort, limit) as child of SelectNode
+	if source != nil {
+		selectNode.AddChild(source)
+	}
+
+	return selectNode, describeOutput(stmt, db, proj, agg, window, compute), nil
 }
 
+// planInsert plans INSERT ... VALUES, whose rows are computed here, or INSERT ... SELECT,
+// whose query becomes the InsertNode's child
 func planInsert(stmt *ast.InsertStatement, db *schema.Database, tx *transaction.Transaction) (plan.Node, error) {
 	tableName := stmt.TableName.Value
 	table, ok := db.Tables[tableName]
 	if !ok {
-		return nil, fmt.Errorf("table not found: %s", tableName)
+		return nil, errors.NewTableNotFoundError(tableName)
 	}
 
-	if len(stmt.Columns) != len(stmt.Values) {
-		return nil, fmt.Errorf("column count (%d) does not match value count (%d)", len(stmt.Columns), len(stmt.Values))
+	if stmt.Query != nil {
+		return planInsertQuery(stmt, table, db, tx)
+	}
+
+	rows := make([]data.Row, len(stmt.Rows))
+	for i, values := range stmt.Rows {
+		row, err := buildInsertRow(stmt.Columns, values, table)
+		if err != nil {
+			if len(stmt.Rows) > 1 {
+				return nil, fmt.Errorf("row %d: %w", i+1, err)
+			}
+			return nil, err
+		}
+		rows[i] = row
+	}
+
+	returning, err := planReturning(stmt.Returning, table)
+	if err != nil {
+		return nil, err
+	}
+
+	node := &plan.InsertNode{
+		TableName:   tableName,
+		Rows:        rows,
+		Transaction: tx,
+		Returning:   returning,
+	}
+	node.Metadata()["rows"] = len(rows)
+	return node, nil
+}
+
+// buildInsertRow converts one VALUES list to a row of the table
+func buildInsertRow(columns []*ast.Identifier, values []ast.Expression, table *schema.Table) (data.Row, error) {
+	if len(columns) != len(values) {
+		return data.Row{}, fmt.Errorf("column count (%d) does not match value count (%d)", len(columns), len(values))
 	}
 
 	row := make(map[string]interface{})
-	for i, col := range stmt.Columns {
-		lit, ok := stmt.Values[i].(*ast.Literal)
+	for i, col := range columns {
+		lit, ok := values[i].(*ast.Literal)
 		if !ok {
-			return nil, fmt.Errorf("only literals supported in VALUES")
+			// Constant expressions (e.g. 2 * 50) are evaluated once here
+			value, err := evaluateConstant(values[i], findColumnInSchema(table, col.Value))
+			if err != nil {
+				return data.Row{}, fmt.Errorf("column '%s': %w", col.Value, err)
+			}
+			row[col.Value] = value
+			continue
 		}
 
 		schemaCol := findColumnInSchema(table, col.Value)
 		if schemaCol != nil {
 			convertedLit, err := types.ConvertLiteralToSchemaType(lit, schemaCol.Type)
 			if err != nil {
-				return nil, fmt.Errorf("column '%s': %w", col.Value, err)
+				return data.Row{}, fmt.Errorf("column '%s': %w", col.Value, err)
 			}
 			row[col.Value] = convertedLit.Value
 		} else {
 			row[col.Value] = lit.Value
 		}
 	}
+	return data.NewRow(row), nil
+}
 
-	return &plan.InsertNode{
-		TableName:   tableName,
-		Row:         data.NewRow(row),
+// planInsertQuery plans INSERT ... SELECT
+// The query's columns are matched to the insert columns by position, and read through a
+// SubqueryScanNode that names them after the target columns. The query runs to completion
+// before the first row is inserted, so it may read the table being inserted into.
+func planInsertQuery(stmt *ast.InsertStatement, table *schema.Table, db *schema.Database, tx *transaction.Transaction) (plan.Node, error) {
+	query, out, err := planQuery(stmt.Query, db, tx, nil)
+	if err != nil {
+		return nil, err
+	}
+	if len(stmt.Columns) != len(out.Columns) {
+		return nil, fmt.Errorf("column count (%d) does not match query column count (%d)", len(stmt.Columns), len(out.Columns))
+	}
+
+	columns := make([]schema.Column, len(stmt.Columns))
+	for i, col := range stmt.Columns {
+		schemaCol := findColumnInSchema(table, col.Value)
+		if schemaCol == nil {
+			return nil, fmt.Errorf("column not found: %s", col.Value)
+		}
+		if !out.Untyped[i] {
+			if err := checkAssignable(out.Columns[i].Type, schemaCol); err != nil {
+				return nil, fmt.Errorf("column '%s': %w", col.Value, err)
+			}
+		}
+		columns[i] = schema.Column{Name: col.Value, Type: schemaCol.Type}
+	}
+
+	returning, err := planReturning(stmt.Returning, table)
+	if err != nil {
+		return nil, err
+	}
+
+	node := &plan.InsertNode{
+		TableName:   table.Name,
 		Transaction: tx,
-	}, nil
+		Returning:   returning,
+	}
+	node.AddChild(plan.NewSubqueryScanNode(table.Name, query, columns, out.Keys))
+	return node, nil
 }
 
 func planUpdate(stmt *ast.UpdateStatement, db *schema.Database, tx *transaction.Transaction) (plan.Node, error) {
 	tableName := stmt.TableName.Value
 	table, ok := db.Tables[tableName]
 	if !ok {
-		return nil, fmt.Errorf("table not found: %s", tableName)
+		return nil, errors.NewTableNotFoundError(tableName)
 	}
 
+	// Subqueries are planned first; uncorrelated ones run before the table is locked
+	sp := &subqueryPlanner{db: db, tx: tx, scope: tableScope(tableName, db), outerTable: tableName}
+	where, err := sp.rewrite(stmt.Where)
+	if err != nil {
+		return nil, err
+	}
+
+	// Literals are converted once; expressions are computed per row from the old values
 	updates := make(map[string]interface{})
+	computedUpdates := make(schema.Assignments)
 	for colName, valueExpr := range stmt.Updates {
+		valueExpr, err := sp.rewrite(valueExpr)
+		if err != nil {
+			return nil, fmt.Errorf("column '%s': %w", colName, err)
+		}
 		lit, ok := valueExpr.(*ast.Literal)
 		if !ok {
-			return nil, fmt.Errorf("only literals supported in SET clause")
+			assign, err := buildAssignment(valueExpr, table, findColumnInSchema(table, colName))
+			if err != nil {
+				return nil, fmt.Errorf("column '%s': %w", colName, err)
+			}
+			computedUpdates[colName] = assign
+			continue
 		}
 
 		schemaCol := findColumnInSchema(table, colName)
@@ -228,62 +535,250 @@ func planUpdate(stmt *ast.UpdateStatement, db *schema.Database, tx *transaction.
 		}
 	}
 
-	var pred func(data.Row) bool
-	if stmt.Where != nil {
-		var err error
-		pred, err = predicate.Build(stmt.Where)
+	if err := sp.checkModifiedTable(tableName); err != nil {
+		return nil, err
+	}
+
+	var pred func(data.Row) (bool, error)
+	if where != nil {
+		if err := expression.Check(where, columnTypes([]string{tableName}, db)); err != nil {
+			return nil, fmt.Errorf("invalid WHERE clause: %w", err)
+		}
+		pred, err = predicate.Build(where)
 		if err != nil {
 			return nil, err
 		}
 	} else {
-		pred = func(data.Row) bool { return true }
+		pred = func(data.Row) (bool, error) { return true, nil }
+	}
+
+	assignments := schema.ConstantAssignments(data.NewRow(updates))
+	for colName, assign := range computedUpdates {
+		assignments[colName] = assign
+	}
+
+	returning, err := planReturning(stmt.Returning, table)
+	if err != nil {
+		return nil, err
 	}
 
 	node := &plan.UpdateNode{
 		TableName:   tableName,
 		Predicate:   pred,
-		Updates:     data.NewRow(updates),
+		Updates:     assignments,
 		Transaction: tx,
+		Subqueries:  sp.planned,
+		Returning:   returning,
+	}
+
+	// Read the target rows through an index when the WHERE clause allows it
+	scan := planTableScan(table, where, pred, tx, nil)
+	if indexScan, ok := scan.(*plan.IndexScanNode); ok {
+		node.AddChild(indexScan)
 	}
 
 	// Attach metadata
 	node.Metadata()["table"] = tableName
 	node.Metadata()["has_predicate"] = pred != nil
+	node.Metadata()["scan_type"] = scan.Metadata()["scan_type"]
 
 	return node, nil
 }
 
 func planDelete(stmt *ast.DeleteStatement, db *schema.Database, tx *transaction.Transaction) (plan.Node, error) {
 	tableName := stmt.TableName.Value
-	_, ok := db.Tables[tableName]
+	table, ok := db.Tables[tableName]
 	if !ok {
-		return nil, fmt.Errorf("table not found: %s", tableName)
+		return nil, errors.NewTableNotFoundError(tableName)
 	}
 
-	var pred func(data.Row) bool
-	if stmt.Where != nil {
-		var err error
-		pred, err = predicate.Build(stmt.Where)
+	// Subqueries are planned first; uncorrelated ones run before the table is locked
+	sp := &subqueryPlanner{db: db, tx: tx, scope: tableScope(tableName, db), outerTable: tableName}
+	where, err := sp.rewrite(stmt.Where)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := sp.checkModifiedTable(tableName); err != nil {
+		return nil, err
+	}
+
+	var pred func(data.Row) (bool, error)
+	if where != nil {
+		if err := expression.Check(where, columnTypes([]string{tableName}, db)); err != nil {
+			return nil, fmt.Errorf("invalid WHERE clause: %w", err)
+		}
+		pred, err = predicate.Build(where)
 		if err != nil {
 			return nil, err
 		}
 	} else {
-		pred = func(data.Row) bool { return true }
+		pred = func(data.Row) (bool, error) { return true, nil }
+	}
+
+	returning, err := planReturning(stmt.Returning, table)
+	if err != nil {
+		return nil, err
 	}
 
 	node := &plan.DeleteNode{
 		TableName:   tableName,
 		Predicate:   pred,
 		Transaction: tx,
+		Subqueries:  sp.planned,
+		Returning:   returning,
+	}
+
+	// Read the target rows through an index when the WHERE clause allows it
+	scan := planTableScan(table, where, pred, tx, nil)
+	if indexScan, ok := scan.(*plan.IndexScanNode); ok {
+		node.AddChild(indexScan)
 	}
 
 	// Attach metadata
 	node.Metadata()["table"] = tableName
 	node.Metadata()["has_predicate"] = pred != nil
+	node.Metadata()["scan_type"] = scan.Metadata()["scan_type"]
 
 	return node, nil
 }
 
+// buildAssignment compiles a SET expression into a per-row computation of the column's new value
+// Column references must name columns of the updated table; the result is converted to the column's type.
+func buildAssignment(expr ast.Expression, table *schema.Table, col *schema.Column) (schema.Assignment, error) {
+	for _, ident := range expression.Columns(expr) {
+		if (ident.Table != "" && ident.Table != table.Name) || findColumnInSchema(table, ident.Value) == nil {
+			return nil, fmt.Errorf("column not found: %s", ident.String())
+		}
+	}
+
+	exprType, err := expression.Type(expr, func(ident *ast.Identifier) schema.ColumnType {
+		return findColumnInSchema(table, ident.Value).Type
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := checkAssignable(exprType, col); err != nil {
+		return nil, err
+	}
+
+	eval, err := expression.Build(expr)
+	if err != nil {
+		return nil, err
+	}
+	if col == nil {
+		// Unknown target column: the table reports it when the update runs
+		return schema.Assignment(eval), nil
+	}
+
+	colType := col.Type
+	return func(row data.Row) (interface{}, error) {
+		val, err := eval(row)
+		if err != nil {
+			return nil, err
+		}
+		return types.ConvertValueToSchemaType(val, colType)
+	}, nil
+}
+
+// evaluateConstant computes an expression that does not reference any column (e.g. in VALUES)
+// The result is converted to the column's type when the column is known.
+func evaluateConstant(expr ast.Expression, col *schema.Column) (interface{}, error) {
+	if cols := expression.Columns(expr); len(cols) > 0 {
+		return nil, fmt.Errorf("column reference %s is not allowed here", cols[0].String())
+	}
+
+	exprType, err := expression.Type(expr, func(*ast.Identifier) schema.ColumnType { return "" })
+	if err != nil {
+		return nil, err
+	}
+	if err := checkAssignable(exprType, col); err != nil {
+		return nil, err
+	}
+
+	eval, err := expression.Build(expr)
+	if err != nil {
+		return nil, err
+	}
+	val, err := eval(data.NewRow(nil))
+	if err != nil {
+		return nil, err
+	}
+	if col == nil {
+		return val, nil
+	}
+	return types.ConvertValueToSchemaType(val, col.Type)
+}
+
+// checkAssignable reports an error when a value of type from can never be stored in col
+// Numbers convert between INT and FLOAT, and text converts to DATE, TIME and EMAIL (and back);
+// the value itself is checked when it is converted. An unknown type or column is accepted.
+func checkAssignable(from schema.ColumnType, col *schema.Column) error {
+	if from == "" || col == nil || from == col.Type {
+		return nil
+	}
+
+	numeric := func(t schema.ColumnType) bool {
+		return t == schema.ColumnTypeInt || t == schema.ColumnTypeFloat
+	}
+	textual := func(t schema.ColumnType) bool {
+		return t == schema.ColumnTypeText || t == schema.ColumnTypeDate ||
+			t == schema.ColumnTypeTime || t == schema.ColumnTypeEmail
+	}
+	if (numeric(from) && numeric(col.Type)) ||
+		(textual(from) && textual(col.Type) && (from == schema.ColumnTypeText || col.Type == schema.ColumnTypeText)) {
+		return nil
+	}
+	return fmt.Errorf("cannot store %s value in %s column", from, col.Type)
+}
+
+// columnTypes returns a lookup of column types in the query's tables ("" when not found)
+func columnTypes(tables []string, db *schema.Database) func(*ast.Identifier) schema.ColumnType {
+	return func(ident *ast.Identifier) schema.ColumnType {
+		col, _, err := resolveColumn(ident, tables, db)
+		if err != nil {
+			return ""
+		}
+		return col.Type
+	}
+}
+
+// queryTables returns the FROM table followed by every JOINed table
+func queryTables(stmt *ast.SelectStatement) []string {
+	tables := []string{stmt.TableName.Value}
+	for _, j := range stmt.Joins {
+		tables = append(tables, j.RightTable.Value)
+	}
+	return tables
+}
+
+// resolveColumn finds a (possibly qualified) column in the query's tables
+// Returns the column and the name of the table that defines it.
+func resolveColumn(col *ast.Identifier, tables []string, db *schema.Database) (*schema.Column, string, error) {
+	for _, name := range tables {
+		if col.Table != "" && col.Table != name {
+			continue
+		}
+		table, ok := db.Tables[name]
+		if !ok {
+			continue
+		}
+		if c := findColumnInSchema(table, col.Value); c != nil {
+			return c, name, nil
+		}
+	}
+	return nil, "", fmt.Errorf("column not found: %s", col.String())
+}
+
+// isSelectAll reports whether the field list is a single *
+func isSelectAll(stmt *ast.SelectStatement) bool {
+	if len(stmt.Fields) != 1 {
+		return false
+	}
+	ident, ok := stmt.Fields[0].(*ast.Identifier)
+	return ok && ident.Value == "*"
+}
+
 func findColumnInSchema(table *schema.Table, colName string) *schema.Column {
 	for i := range table.Schema.Columns {
 		if table.Schema.Columns[i].Name == colName {
@@ -2,103 +2,323 @@ package predicate
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/leengari/mini-rdbms/internal/domain/data"
 	"github.com/leengari/mini-rdbms/internal/parser/ast"
+	"github.com/leengari/mini-rdbms/internal/planner/expression"
 	"github.com/leengari/mini-rdbms/internal/util/types"
 )
 
 // PredicateFunc is a function that tests whether a row matches certain criteria
-type PredicateFunc func(data.Row) bool
+// An error means the condition could not be evaluated for the row.
+type PredicateFunc func(data.Row) (bool, error)
 
 // Build converts an AST expression into a predicate function
 // Supports:
 //   - Comparison operators: =, <, >, <=, >=, !=, <>
-//   - Logical operators: AND, OR
+//   - Logical operators: AND, OR, NOT
+//   - IN lists: expr [NOT] IN (v1, v2, ...)
+//   - Ranges: expr [NOT] BETWEEN low AND high
+//   - Pattern matching: expr [NOT] LIKE|ILIKE 'pattern' [ESCAPE 'c']
+//   - NULL tests: expr IS [NOT] NULL
+//   - Subqueries: [NOT] EXISTS (SELECT ...), expr [NOT] IN (SELECT ...), once planned
+//     (the planner replaces them with expression.Subquery or expression.SemiJoin)
+//   - Boolean values: a BOOL column, literal, function or CAST used as a condition
 //   - Nested expressions with parentheses
+//
+// Operands are scalar expressions (see the expression package), so columns can be
+// compared with each other and with arithmetic such as price * 2 > total.
+// Conditions follow SQL three-valued logic: a missing column or NULL value makes
+// comparisons unknown, and a row only matches when the whole condition is true.
+// An operand that fails to evaluate (e.g. division by zero) makes the predicate
+// return the error, so the statement fails. AND and OR evaluate left to right and
+// skip the right side once the left side decides the result.
 // Returns a function that tests whether a row matches the condition
 func Build(expr ast.Expression) (PredicateFunc, error) {
+	cond, err := buildCondition(expr)
+	if err != nil {
+		return nil, err
+	}
+	return func(row data.Row) (bool, error) {
+		t, err := cond(row)
+		if err != nil {
+			return false, err
+		}
+		return t == truthTrue, nil
+	}, nil
+}
+
+// buildCondition converts an AST expression into a three-valued condition
+func buildCondition(expr ast.Expression) (condition, error) {
 	switch e := expr.(type) {
 	case *ast.BinaryExpression:
-		// Handle comparison expressions (col op value)
+		// Arithmetic yields a value, not a condition
+		if expression.IsScalarOperator(e.Operator) {
+			return buildValue(e)
+		}
+		// Handle comparison expressions (expr op expr)
 		return buildComparison(e)
-		
+
 	case *ast.LogicalExpression:
 		// Handle logical expressions (expr AND/OR expr)
 		return buildLogical(e)
-		
+
+	case *ast.NotExpression:
+		// Handle negation (NOT expr)
+		return buildNot(e)
+
+	case *ast.InExpression:
+		// Handle IN lists (expr IN (v1, v2, ...))
+		return buildIn(e)
+
+	case *ast.BetweenExpression:
+		// Handle ranges (expr BETWEEN low AND high)
+		return buildBetween(e)
+
+	case *ast.LikeExpression:
+		// Handle pattern matching (expr LIKE 'pattern')
+		return buildLike(e)
+
+	case *ast.IsNullExpression:
+		// Handle NULL tests (expr IS [NOT] NULL)
+		return buildIsNull(e)
+
+	case *ast.Identifier, *ast.Literal, *ast.FunctionCall, *ast.CastExpression:
+		// Handle boolean values (WHERE is_active, WHERE CAST(flag AS BOOL))
+		return buildValue(e)
+
+	case *expression.Subquery, *expression.SemiJoin:
+		// Handle planned subqueries (EXISTS, IN (SELECT ...), boolean scalar subqueries)
+		return buildValue(e)
+
 	default:
 		return nil, fmt.Errorf("unsupported expression type in WHERE clause: %T", expr)
 	}
 }
 
-// buildComparison builds a predicate for comparison expressions
-func buildComparison(binExpr *ast.BinaryExpression) (PredicateFunc, error) {
-	leftIdent, ok := binExpr.Left.(*ast.Identifier)
-	if !ok {
-		return nil, fmt.Errorf("left side of comparison must be an identifier")
+// buildValue builds a condition from a scalar expression used as a boolean
+// Unknown unless the value is a boolean
+func buildValue(expr ast.Expression) (condition, error) {
+	value, err := expression.Build(expr)
+	if err != nil {
+		return nil, err
 	}
 
-	rightLit, ok := binExpr.Right.(*ast.Literal)
-	if !ok {
-		return nil, fmt.Errorf("right side of comparison must be a literal")
+	return func(row data.Row) (truth, error) {
+		val, err := value(row)
+		if err != nil {
+			return truthUnknown, err
+		}
+		b, ok := val.(bool)
+		if !ok {
+			return truthUnknown, nil
+		}
+		return toTruth(b), nil
+	}, nil
+}
+
+// buildComparison builds a condition for comparison expressions
+// Unknown when either side is NULL
+func buildComparison(binExpr *ast.BinaryExpression) (condition, error) {
+	left, err := expression.Build(binExpr.Left)
+	if err != nil {
+		return nil, fmt.Errorf("invalid left side of comparison: %w", err)
+	}
+
+	right, err := expression.Build(binExpr.Right)
+	if err != nil {
+		return nil, fmt.Errorf("invalid right side of comparison: %w", err)
 	}
 
-	// Get column name (may be qualified like "orders.amount" or unqualified like "amount")
-	colName := leftIdent.Value
-	tableName := leftIdent.Table
 	operator := binExpr.Operator
-	targetVal := rightLit.Value
+	return func(row data.Row) (truth, error) {
+		l, err := left(row)
+		if err != nil {
+			return truthUnknown, err
+		}
+		r, err := right(row)
+		if err != nil {
+			return truthUnknown, err
+		}
+		return compare(l, operator, r), nil
+	}, nil
+}
 
-	return func(row data.Row) bool {
-		var val interface{}
-		var ok bool
+// buildIn builds a condition for [NOT] IN expressions
+// True when the value equals any listed value; if none match and the list
+// contains NULL (or the value is NULL) the result is unknown. NOT IN negates it.
+func buildIn(inExpr *ast.InExpression) (condition, error) {
+	left, err := expression.Build(inExpr.Left)
+	if err != nil {
+		return nil, fmt.Errorf("invalid left side of IN: %w", err)
+	}
 
-		// Try qualified name first if table is specified (e.g., "orders.amount")
-		if tableName != "" {
-			qualifiedName := tableName + "." + colName
-			val, ok = row.Data[qualifiedName]
+	targets := make([]expression.Func, len(inExpr.Values))
+	for i, v := range inExpr.Values {
+		targets[i], err = expression.Build(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid IN value: %w", err)
 		}
+	}
 
-		// If not found with qualified name, try unqualified (e.g., "amount")
-		if !ok {
-			val, ok = row.Data[colName]
+	in := func(row data.Row) (truth, error) {
+		val, err := left(row)
+		if err != nil || val == nil {
+			return truthUnknown, err
 		}
 
-		// If still not found, return false
-		if !ok {
-			return false
+		hasNull := false
+		for _, target := range targets {
+			t, err := target(row)
+			if err != nil {
+				return truthUnknown, err
+			}
+			if t == nil {
+				hasNull = true
+				continue
+			}
+			if types.CompareValues(val, "=", t) {
+				return truthTrue, nil
+			}
+		}
+		if hasNull {
+			return truthUnknown, nil
 		}
-		
-		// Use types.CompareValues to handle all comparison operators
-		return types.CompareValues(val, operator, targetVal)
+		return truthFalse, nil
+	}
+	return negate(in, inExpr.Not), nil
+}
+
+// buildBetween builds a condition for [NOT] BETWEEN expressions
+// Evaluated as expr >= low AND expr <= high, so a NULL value or bound makes it unknown
+func buildBetween(between *ast.BetweenExpression) (condition, error) {
+	value, err := expression.Build(between.Expr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid left side of BETWEEN: %w", err)
+	}
+
+	low, err := expression.Build(between.Low)
+	if err != nil {
+		return nil, fmt.Errorf("invalid BETWEEN lower bound: %w", err)
+	}
+	high, err := expression.Build(between.High)
+	if err != nil {
+		return nil, fmt.Errorf("invalid BETWEEN upper bound: %w", err)
+	}
+
+	inRange := func(row data.Row) (truth, error) {
+		vals, err := evaluateAll(row, value, low, high)
+		if err != nil {
+			return truthUnknown, err
+		}
+		return compare(vals[0], ">=", vals[1]).and(compare(vals[0], "<=", vals[2])), nil
+	}
+	return negate(inRange, between.Not), nil
+}
+
+// buildIsNull builds a condition for IS [NOT] NULL tests
+// A missing column counts as NULL; the result is never unknown
+func buildIsNull(isNull *ast.IsNullExpression) (condition, error) {
+	value, err := expression.Build(isNull.Expr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid left side of IS NULL: %w", err)
+	}
+
+	not := isNull.Not
+	return func(row data.Row) (truth, error) {
+		val, err := value(row)
+		if err != nil {
+			return truthUnknown, err
+		}
+		return toTruth((val == nil) != not), nil
 	}, nil
 }
 
-// buildLogical builds a predicate for logical expressions (AND/OR)
-// Recursively builds predicates for left and right sub-expressions
-func buildLogical(logExpr *ast.LogicalExpression) (PredicateFunc, error) {
-	// Recursively build predicates for left and right sides
-	leftPred, err := Build(logExpr.Left)
+// buildLogical builds a condition for logical expressions (AND/OR)
+// Recursively builds conditions for left and right sub-expressions
+func buildLogical(logExpr *ast.LogicalExpression) (condition, error) {
+	// Recursively build conditions for left and right sides
+	leftCond, err := buildCondition(logExpr.Left)
 	if err != nil {
 		return nil, fmt.Errorf("failed to build left predicate: %w", err)
 	}
 
-	rightPred, err := Build(logExpr.Right)
+	rightCond, err := buildCondition(logExpr.Right)
 	if err != nil {
 		return nil, fmt.Errorf("failed to build right predicate: %w", err)
 	}
 
-	// Combine predicates based on operator
-	if logExpr.Operator == "AND" {
-		return func(row data.Row) bool {
-			return leftPred(row) && rightPred(row)
+	// Combine conditions based on operator; the right side is skipped once the left decides
+	if strings.EqualFold(logExpr.Operator, "AND") {
+		return func(row data.Row) (truth, error) {
+			left, err := leftCond(row)
+			if err != nil || left == truthFalse {
+				return left, err
+			}
+			right, err := rightCond(row)
+			if err != nil {
+				return truthUnknown, err
+			}
+			return left.and(right), nil
 		}, nil
-	} else if logExpr.Operator == "OR" {
-		return func(row data.Row) bool {
-			return leftPred(row) || rightPred(row)
+	} else if strings.EqualFold(logExpr.Operator, "OR") {
+		return func(row data.Row) (truth, error) {
+			left, err := leftCond(row)
+			if err != nil || left == truthTrue {
+				return left, err
+			}
+			right, err := rightCond(row)
+			if err != nil {
+				return truthUnknown, err
+			}
+			return left.or(right), nil
 		}, nil
 	}
 
 	return nil, fmt.Errorf("unsupported logical operator: %s", logExpr.Operator)
 }
+
+// buildNot builds a condition for NOT expressions
+func buildNot(notExpr *ast.NotExpression) (condition, error) {
+	inner, err := buildCondition(notExpr.Expr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build NOT predicate: %w", err)
+	}
+
+	return negate(inner, true), nil
+}
+
+// negate wraps a condition in NOT when not is set
+func negate(cond condition, not bool) condition {
+	if !not {
+		return cond
+	}
+	return func(row data.Row) (truth, error) {
+		t, err := cond(row)
+		return t.not(), err
+	}
+}
+
+// compare applies a comparison operator; unknown when either value is NULL
+func compare(left interface{}, op string, right interface{}) truth {
+	if left == nil || right == nil {
+		return truthUnknown
+	}
+
+	// Use types.CompareValues to handle all comparison operators
+	return toTruth(types.CompareValues(left, op, right))
+}
+
+// evaluateAll computes scalar expressions for a row in order, stopping at the first error
+func evaluateAll(row data.Row, values ...expression.Func) ([]interface{}, error) {
+	vals := make([]interface{}, len(values))
+	for i, value := range values {
+		val, err := value(row)
+		if err != nil {
+			return nil, err
+		}
+		vals[i] = val
+	}
+	return vals, nil
+}
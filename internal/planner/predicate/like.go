@@ -0,0 +1,106 @@
+package predicate
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/leengari/mini-rdbms/internal/domain/data"
+	"github.com/leengari/mini-rdbms/internal/parser/ast"
+	"github.com/leengari/mini-rdbms/internal/planner/expression"
+)
+
+// defaultLikeEscape escapes % and _ in LIKE patterns without an ESCAPE clause
+const defaultLikeEscape = '\\'
+
+// buildLike builds a condition for [NOT] LIKE / ILIKE expressions
+// The pattern is compiled once; non-string values are matched by their text form.
+func buildLike(like *ast.LikeExpression) (condition, error) {
+	value, err := expression.Build(like.Expr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid left side of %s: %w", like.TokenLiteral(), err)
+	}
+
+	patternLit, ok := like.Pattern.(*ast.Literal)
+	if !ok {
+		return nil, fmt.Errorf("%s pattern must be a literal", like.TokenLiteral())
+	}
+
+	escape := rune(defaultLikeEscape)
+	if like.Escape != nil {
+		escLit, ok := like.Escape.(*ast.Literal)
+		if !ok {
+			return nil, fmt.Errorf("ESCAPE must be a string literal")
+		}
+		escStr, ok := escLit.Value.(string)
+		if !ok || utf8.RuneCountInString(escStr) > 1 {
+			return nil, fmt.Errorf("ESCAPE must be a single character, got %s", escLit.TokenLiteralValue)
+		}
+		escape = -1 // ESCAPE '' disables escaping
+		if escStr != "" {
+			escape, _ = utf8.DecodeRuneInString(escStr)
+		}
+	}
+
+	// A NULL pattern never matches
+	if patternLit.Value == nil {
+		return func(data.Row) (truth, error) { return truthUnknown, nil }, nil
+	}
+	pattern, ok := patternLit.Value.(string)
+	if !ok {
+		return nil, fmt.Errorf("%s pattern must be a string, got %s", like.TokenLiteral(), patternLit.Kind)
+	}
+
+	re, err := compileLike(pattern, escape, like.CaseInsensitive)
+	if err != nil {
+		return nil, err
+	}
+
+	match := func(row data.Row) (truth, error) {
+		val, err := value(row)
+		if err != nil || val == nil {
+			return truthUnknown, err
+		}
+		s, ok := val.(string)
+		if !ok {
+			s = fmt.Sprint(val)
+		}
+		return toTruth(re.MatchString(s)), nil
+	}
+	return negate(match, like.Not), nil
+}
+
+// compileLike translates a LIKE pattern into an anchored regular expression
+// % matches any sequence of characters, _ exactly one; the escape character
+// makes the next character literal.
+func compileLike(pattern string, escape rune, caseInsensitive bool) (*regexp.Regexp, error) {
+	var sb strings.Builder
+	sb.WriteString("^(?s)")
+	if caseInsensitive {
+		sb.WriteString("(?i)")
+	}
+
+	escaped := false
+	for _, r := range pattern {
+		switch {
+		case escaped:
+			sb.WriteString(regexp.QuoteMeta(string(r)))
+			escaped = false
+		case r == escape:
+			escaped = true
+		case r == '%':
+			sb.WriteString(".*")
+		case r == '_':
+			sb.WriteString(".")
+		default:
+			sb.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	if escaped {
+		return nil, fmt.Errorf("LIKE pattern must not end with the escape character")
+	}
+	sb.WriteString("$")
+
+	return regexp.Compile(sb.String())
+}
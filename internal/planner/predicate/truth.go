@@ -0,0 +1,58 @@
+package predicate
+
+import "github.com/leengari/mini-rdbms/internal/domain/data"
+
+// truth is the result of a condition under SQL three-valued logic
+// Any comparison involving NULL is unknown rather than true or false.
+type truth int
+
+const (
+	truthFalse truth = iota
+	truthTrue
+	truthUnknown
+)
+
+// condition evaluates an expression against a row
+type condition func(data.Row) (truth, error)
+
+// and combines two results: false wins, then unknown
+func (t truth) and(other truth) truth {
+	if t == truthFalse || other == truthFalse {
+		return truthFalse
+	}
+	if t == truthUnknown || other == truthUnknown {
+		return truthUnknown
+	}
+	return truthTrue
+}
+
+// or combines two results: true wins, then unknown
+func (t truth) or(other truth) truth {
+	if t == truthTrue || other == truthTrue {
+		return truthTrue
+	}
+	if t == truthUnknown || other == truthUnknown {
+		return truthUnknown
+	}
+	return truthFalse
+}
+
+// not negates a result; unknown stays unknown
+func (t truth) not() truth {
+	switch t {
+	case truthTrue:
+		return truthFalse
+	case truthFalse:
+		return truthTrue
+	default:
+		return truthUnknown
+	}
+}
+
+// toTruth converts a definite boolean result
+func toTruth(b bool) truth {
+	if b {
+		return truthTrue
+	}
+	return truthFalse
+}
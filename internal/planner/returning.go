@@ -0,0 +1,73 @@
+package planner
+
+import (
+	"fmt"
+
+	"github.com/leengari/mini-rdbms/internal/domain/schema"
+	"github.com/leengari/mini-rdbms/internal/parser/ast"
+	"github.com/leengari/mini-rdbms/internal/plan"
+	"github.com/leengari/mini-rdbms/internal/planner/expression"
+)
+
+// planReturning compiles the RETURNING fields of an INSERT, UPDATE or DELETE on table
+// Each field is evaluated over a modified row: the new row for INSERT and UPDATE, the
+// removed row for DELETE. * returns every column of the table in schema order.
+func planReturning(fields []ast.Expression, table *schema.Table) ([]plan.ComputedColumn, error) {
+	if len(fields) == 1 {
+		if ident, ok := fields[0].(*ast.Identifier); ok && ident.Value == "*" {
+			fields = make([]ast.Expression, len(table.Schema.Columns))
+			for i, col := range table.Schema.Columns {
+				fields[i] = &ast.Identifier{TokenLiteralValue: col.Name, Value: col.Name}
+			}
+		}
+	}
+
+	columnType := func(ident *ast.Identifier) schema.ColumnType {
+		return findColumnInSchema(table, ident.Value).Type
+	}
+
+	columns := make([]plan.ComputedColumn, len(fields))
+	seen := make(map[string]bool, len(fields))
+	for i, field := range fields {
+		expr, name := unwrapAlias(field)
+		if name == "" {
+			name = expr.String()
+			if ident, ok := expr.(*ast.Identifier); ok {
+				name = ident.Value
+			}
+		}
+		if seen[name] {
+			return nil, fmt.Errorf("RETURNING has more than one column named %s", name)
+		}
+		seen[name] = true
+
+		switch {
+		case containsAggregate(expr):
+			return nil, fmt.Errorf("aggregate functions are not allowed in RETURNING")
+		case containsWindow(expr):
+			return nil, fmt.Errorf("window functions are not allowed in RETURNING")
+		case containsSubquery(expr):
+			return nil, fmt.Errorf("subqueries are not allowed in RETURNING")
+		}
+		for _, ident := range expression.Columns(expr) {
+			if (ident.Table != "" && ident.Table != table.Name) || findColumnInSchema(table, ident.Value) == nil {
+				return nil, fmt.Errorf("invalid RETURNING field %s: column not found: %s", field.String(), ident.String())
+			}
+		}
+
+		colType, err := expression.Type(expr, columnType)
+		if err != nil {
+			return nil, fmt.Errorf("invalid RETURNING field %s: %w", field.String(), err)
+		}
+		eval, err := expression.Build(expr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid RETURNING field %s: %w", field.String(), err)
+		}
+		if colType == "" {
+			colType = schema.ColumnTypeText
+		}
+
+		columns[i] = plan.ComputedColumn{Name: name, Type: colType, Eval: eval}
+	}
+	return columns, nil
+}
@@ -1,40 +1,229 @@
 package planner
 
 import (
+	"math"
+	"strings"
+
 	"github.com/leengari/mini-rdbms/internal/domain/data"
 	"github.com/leengari/mini-rdbms/internal/domain/schema"
+	"github.com/leengari/mini-rdbms/internal/domain/transaction"
+	"github.com/leengari/mini-rdbms/internal/parser/ast"
 	"github.com/leengari/mini-rdbms/internal/plan"
+	"github.com/leengari/mini-rdbms/internal/query/operations/join"
+)
+
+const (
+	nestedLoopMaxPairs   = 10_000    // Largest left×right row product joined with a nested loop
+	hashJoinMaxBuildRows = 1_000_000 // Largest input a hash join builds a hash table over
 )
 
+// indexLookup is a WHERE predicate an index can answer: column = v, column IN (v, ...)
+// or column BETWEEN low AND high (Range set), or a range of an ordered index (Index set)
+type indexLookup struct {
+	Column     string
+	Values     []interface{}
+	Range      *plan.KeyRange
+	Index      *data.OrderedIndex // Ordered index to read instead (Column, Values and Range are unused)
+	IndexRange data.IndexRange
+}
+
+// planTableScan builds the leaf node that reads a single table
+// Returns an IndexScanNode when the WHERE clause allows it, otherwise a sequential ScanNode.
+// Either way pred (the compiled WHERE clause) is applied to every row read.
+// order holds the ORDER BY keys the rows will be sorted by (nil if none); when an ordered
+// index returns rows in that order the node is marked Ordered and the sort can be skipped.
+func planTableScan(table *schema.Table, where ast.Expression, pred func(data.Row) (bool, error), tx *transaction.Transaction, order []plan.SortKey) plan.Node {
+	scanType, lookup := selectScanType(table, where)
+	descending, sorted := false, false
+	if len(order) > 0 {
+		lookup, descending, sorted = sortedLookup(table, splitConjuncts(where), lookup, order)
+		if sorted {
+			scanType = "index"
+		}
+	}
+
+	if scanType == "index" {
+		node := &plan.IndexScanNode{
+			TableName:   table.Name,
+			Column:      lookup.Column,
+			Values:      lookup.Values,
+			Range:       lookup.Range,
+			Predicate:   pred,
+			Transaction: tx,
+		}
+		node.Metadata()["scan_type"] = scanType
+		node.Metadata()["table"] = table.Name
+		switch {
+		case lookup.Index != nil:
+			node.Index = lookup.Index.Name
+			node.IndexRange = lookup.IndexRange
+			node.Descending = descending
+			node.Ordered = sorted
+			node.Metadata()["index"] = lookup.Index.Name
+			node.Metadata()["lookup_equal"] = len(lookup.IndexRange.Equal)
+			node.Metadata()["lookup_bounded"] = lookup.IndexRange.Low != nil || lookup.IndexRange.High != nil
+			node.Metadata()["ordered"] = sorted
+		case lookup.Range != nil:
+			node.Metadata()["index_column"] = lookup.Column
+			node.Metadata()["lookup_range"] = []interface{}{lookup.Range.Low, lookup.Range.High}
+		default:
+			node.Metadata()["index_column"] = lookup.Column
+			node.Metadata()["lookup_values"] = len(lookup.Values)
+		}
+		return node
+	}
+
+	node := &plan.ScanNode{
+		TableName:   table.Name,
+		Predicate:   pred,
+		Transaction: tx,
+	}
+	node.Metadata()["scan_type"] = scanType
+	node.Metadata()["table"] = table.Name
+	return node
+}
+
 // selectScanType determines whether to use index or sequential scan
-// Scaffold: Always returns "sequential" (naive implementation)
-func selectScanType(tableName string, predicate func(data.Row) bool, db *schema.Database) string {
-	// Future: Implement real scan selection based on:
-	// - Index availability
-	// - Predicate selectivity
-	// - Table size
-	// - Index statistics
-	return "sequential"
+// Returns "index" and the lookup when one of the top-level AND conditions of the WHERE
+// clause compares an indexed column to literals, or the conditions restrict the leading
+// columns of an ordered index (the cheapest lookup by lookupCost wins); otherwise "sequential".
+func selectScanType(table *schema.Table, where ast.Expression) (string, *indexLookup) {
+	var best *indexLookup
+	conjuncts := splitConjuncts(where)
+	for _, conjunct := range conjuncts {
+		lookup := shouldUseIndex(table, conjunct)
+		if lookup == nil {
+			continue
+		}
+		if best == nil || lookupCost(lookup) < lookupCost(best) {
+			best = lookup
+		}
+	}
+	for _, idx := range table.OrderedIndexList() {
+		lookup := orderedLookup(table, idx, conjuncts)
+		if lookup == nil {
+			continue
+		}
+		if best == nil || lookupCost(lookup) < lookupCost(best) {
+			best = lookup
+		}
+	}
+
+	if best == nil {
+		return "sequential", nil
+	}
+	return "index", best
 }
 
 // selectJoinAlgorithm determines which join algorithm to use
-// Scaffold: Always returns "nested_loop" (naive implementation)
-func selectJoinAlgorithm(joinNode *plan.JoinNode, db *schema.Database) string {
-	// Future: Implement real join algorithm selection based on:
-	// - Table sizes
-	// - Available memory
-	// - Join selectivity
-	// - Index availability
-	// Options: "nested_loop", "hash", "merge"
-	return "nested_loop"
-}
-
-// shouldUseIndex determines if an index should be used for a predicate
-// Scaffold: Always returns false (naive implementation)
-func shouldUseIndex(tableName string, predicate func(data.Row) bool, db *schema.Database) bool {
-	// Future: Implement real index selection based on:
-	// - Index availability
-	// - Predicate analysis
-	// - Index selectivity
-	return false
+// A hash join is used when the right join column is indexed (the index serves as
+// the hash table, so there is no build phase). Otherwise small inputs (up to
+// nestedLoopMaxPairs row pairs) use a nested loop, inputs whose smaller side fits
+// in hashJoinMaxBuildRows rows a hash join, and anything larger a sort-merge join.
+func selectJoinAlgorithm(joinNode *plan.JoinNode, db *schema.Database) join.Algorithm {
+	if scan, ok := joinNode.Right().(*plan.ScanNode); ok && scan.Predicate == nil {
+		if table, ok := db.Tables[scan.TableName]; ok && table.HasIndex(joinNode.RightOnCol) {
+			return join.AlgorithmHash
+		}
+	}
+
+	leftRows := estimateRowCount(joinNode.Left(), db)
+	rightRows := estimateRowCount(joinNode.Right(), db)
+	switch {
+	case leftRows*rightRows <= nestedLoopMaxPairs:
+		return join.AlgorithmNestedLoop
+	case min(leftRows, rightRows) <= hashJoinMaxBuildRows:
+		return join.AlgorithmHash
+	default:
+		return join.AlgorithmMerge
+	}
+}
+
+// lookupCost ranks index lookups: the number of keys probed, with a range
+// (which walks every key of the index) ranked after any list of values and
+// lookups through ordered indexes ranked by orderedLookupCost
+func lookupCost(lookup *indexLookup) int {
+	if lookup.Index != nil {
+		return orderedLookupCost(lookup)
+	}
+	if lookup.Range != nil {
+		return math.MaxInt
+	}
+	return len(lookup.Values)
+}
+
+// shouldUseIndex returns the index lookup for a single condition, or nil if no index applies
+// Handles "column = literal", "column IN (literal, ...)" and
+// "column BETWEEN literal AND literal" on an indexed column.
+func shouldUseIndex(table *schema.Table, expr ast.Expression) *indexLookup {
+	switch e := expr.(type) {
+	case *ast.BinaryExpression:
+		if e.Operator != "=" {
+			return nil
+		}
+		ident, ok := e.Left.(*ast.Identifier)
+		if !ok {
+			return nil
+		}
+		lit, ok := e.Right.(*ast.Literal)
+		if !ok {
+			return nil
+		}
+		if !isIndexedColumn(table, ident) {
+			return nil
+		}
+		return &indexLookup{Column: ident.Value, Values: []interface{}{lit.Value}}
+
+	case *ast.InExpression:
+		ident, ok := e.Left.(*ast.Identifier)
+		if !ok || e.Not || !isIndexedColumn(table, ident) {
+			return nil
+		}
+		values := make([]interface{}, len(e.Values))
+		for i, v := range e.Values {
+			lit, ok := v.(*ast.Literal)
+			if !ok {
+				return nil
+			}
+			values[i] = lit.Value
+		}
+		return &indexLookup{Column: ident.Value, Values: values}
+
+	case *ast.BetweenExpression:
+		ident, ok := e.Expr.(*ast.Identifier)
+		if !ok || e.Not || !isIndexedColumn(table, ident) {
+			return nil
+		}
+		low, ok := e.Low.(*ast.Literal)
+		if !ok || low.Value == nil {
+			return nil
+		}
+		high, ok := e.High.(*ast.Literal)
+		if !ok || high.Value == nil {
+			return nil
+		}
+		return &indexLookup{Column: ident.Value, Range: &plan.KeyRange{Low: low.Value, High: high.Value}}
+	}
+
+	return nil
+}
+
+// isIndexedColumn reports whether a column reference names an indexed column of the table
+func isIndexedColumn(table *schema.Table, ident *ast.Identifier) bool {
+	if ident.Table != "" && ident.Table != table.Name {
+		return false
+	}
+	return table.HasIndex(ident.Value)
+}
+
+// splitConjuncts flattens a chain of ANDs into its conditions
+// A matching row satisfies all of them, so any one can drive an index scan.
+func splitConjuncts(expr ast.Expression) []ast.Expression {
+	if expr == nil {
+		return nil
+	}
+	if logical, ok := expr.(*ast.LogicalExpression); ok && strings.EqualFold(logical.Operator, "AND") {
+		return append(splitConjuncts(logical.Left), splitConjuncts(logical.Right)...)
+	}
+	return []ast.Expression{expr}
 }
@@ -0,0 +1,132 @@
+package planner
+
+import (
+	"fmt"
+
+	"github.com/leengari/mini-rdbms/internal/domain/schema"
+	"github.com/leengari/mini-rdbms/internal/domain/transaction"
+	"github.com/leengari/mini-rdbms/internal/parser/ast"
+	"github.com/leengari/mini-rdbms/internal/plan"
+	"github.com/leengari/mini-rdbms/internal/query/operations/projection"
+)
+
+// planQuery plans a SELECT or a set operation and describes the rows it returns
+func planQuery(stmt ast.Statement, db *schema.Database, tx *transaction.Transaction, ctes withTables) (*plan.SelectNode, *selectOutput, error) {
+	switch s := stmt.(type) {
+	case *ast.SelectStatement:
+		return planSelect(s, db, tx, nil, ctes)
+	case *ast.SetOperationStatement:
+		return planSetOperation(s, db, tx, ctes)
+	default:
+		return nil, nil, fmt.Errorf("unsupported query type: %T", stmt)
+	}
+}
+
+// planSetOperation plans UNION, INTERSECT or EXCEPT over two queries
+// Resulting tree: SELECT (projecting the output columns) -> LIMIT -> SORT -> SET_OPERATION,
+// whose children are the two queries' own plans. Both queries must return the same
+// number of columns with compatible types (see setColumnType); the output columns are
+// named after the left query's.
+func planSetOperation(stmt *ast.SetOperationStatement, db *schema.Database, tx *transaction.Transaction, ctes withTables) (*plan.SelectNode, *selectOutput, error) {
+	var commonTables []*plan.CommonTable
+	if stmt.With != nil {
+		var err error
+		if db, ctes, commonTables, err = planCommonTables(stmt.With, db, tx, ctes); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	left, leftOut, err := planQuery(stmt.Left, db, tx, ctes)
+	if err != nil {
+		return nil, nil, err
+	}
+	right, rightOut, err := planQuery(stmt.Right, db, tx, ctes)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(leftOut.Columns) != len(rightOut.Columns) {
+		return nil, nil, fmt.Errorf("each %s query must have the same number of columns, got %d and %d",
+			stmt.Operator, len(leftOut.Columns), len(rightOut.Columns))
+	}
+
+	out := &selectOutput{}
+	seen := make(map[string]bool, len(leftOut.Columns))
+	for i, col := range leftOut.Columns {
+		if seen[col.Name] {
+			return nil, nil, fmt.Errorf("%s query has more than one column named %s", stmt.Operator, col.Name)
+		}
+		seen[col.Name] = true
+
+		colType, err := setColumnType(leftOut, rightOut, i)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%s column %s: %w", stmt.Operator, col.Name, err)
+		}
+		out.Columns = append(out.Columns, schema.Column{Name: col.Name, Type: colType})
+		out.Keys = append(out.Keys, col.Name)
+		out.Untyped = append(out.Untyped, leftOut.Untyped[i] && rightOut.Untyped[i])
+	}
+
+	setNode := plan.NewSetOperationNode(plan.SetOperator(stmt.Operator), stmt.All, left, right, out.Columns, leftOut.Keys, rightOut.Keys)
+	setNode.Metadata()["columns"] = len(out.Columns)
+
+	// ORDER BY keys name output columns
+	var source plan.Node = setNode
+	if len(stmt.OrderBy) > 0 {
+		keys := make([]plan.SortKey, len(stmt.OrderBy))
+		for i, item := range stmt.OrderBy {
+			ident, ok := item.Expr.(*ast.Identifier)
+			if !ok || ident.Table != "" || !seen[ident.Value] {
+				return nil, nil, fmt.Errorf("ORDER BY key %s must name an output column of the %s", item.Expr.String(), stmt.Operator)
+			}
+			keys[i] = sortDirection(plan.SortKey{Column: ident.Value}, item)
+		}
+		sortNode := plan.NewSortNode(source, keys)
+		sortNode.Metadata()["keys"] = len(keys)
+		source = sortNode
+	}
+	source = planLimit(source, stmt.Limit, stmt.Offset)
+
+	proj := &projection.Projection{Columns: make([]projection.ColumnRef, len(out.Columns))}
+	for i, col := range out.Columns {
+		proj.Columns[i] = projection.ColumnRef{Column: col.Name}
+	}
+	selectNode := &plan.SelectNode{
+		Projection:   proj,
+		Transaction:  tx,
+		CommonTables: commonTables,
+	}
+	selectNode.Metadata()["set_operation"] = setNode.NodeType()
+	selectNode.AddChild(source)
+
+	return selectNode, out, nil
+}
+
+// setColumnType returns the type of a set operation's output column from the types of
+// the queries' columns at position i
+// The types must match, except that INT and FLOAT combine to FLOAT, EMAIL and TEXT to
+// TEXT, and a column of undetermined type (e.g. NULL) takes the other query's type.
+func setColumnType(left, right *selectOutput, i int) (schema.ColumnType, error) {
+	l, r := left.Columns[i].Type, right.Columns[i].Type
+	switch {
+	case right.Untyped[i] || l == r:
+		return l, nil
+	case left.Untyped[i]:
+		return r, nil
+	case isNumericColumn(l) && isNumericColumn(r):
+		return schema.ColumnTypeFloat, nil
+	case isTextColumn(l) && isTextColumn(r):
+		return schema.ColumnTypeText, nil
+	default:
+		return "", fmt.Errorf("types %s and %s cannot be matched", l, r)
+	}
+}
+
+// isNumericColumn reports whether a column type holds numbers
+func isNumericColumn(t schema.ColumnType) bool {
+	return t == schema.ColumnTypeInt || t == schema.ColumnTypeFloat
+}
+
+// isTextColumn reports whether a column type holds free text
+func isTextColumn(t schema.ColumnType) bool {
+	return t == schema.ColumnTypeText || t == schema.ColumnTypeEmail
+}
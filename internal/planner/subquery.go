@@ -0,0 +1,589 @@
+package planner
+
+import (
+	"fmt"
+
+	"github.com/leengari/mini-rdbms/internal/domain/schema"
+	"github.com/leengari/mini-rdbms/internal/domain/transaction"
+	"github.com/leengari/mini-rdbms/internal/parser/ast"
+	"github.com/leengari/mini-rdbms/internal/plan"
+	"github.com/leengari/mini-rdbms/internal/planner/expression"
+)
+
+// scope lists the tables a query reads, so a column reference inside a subquery can be
+// resolved to the query that defines it; parent is the enclosing query's scope (nil at the top)
+type scope struct {
+	tables  []string
+	columns map[string][]string // Column names of each table; nil when unknown
+	parent  *scope
+}
+
+// newScope builds the scope of a SELECT
+// Stored tables are looked up in db; derived tables are described by their SELECT lists.
+func newScope(stmt *ast.SelectStatement, db *schema.Database, parent *scope) *scope {
+	sc := &scope{columns: make(map[string][]string), parent: parent}
+	sc.add(stmt.TableName.Value, stmt.Derived, db)
+	for _, j := range stmt.Joins {
+		sc.add(j.RightTable.Value, j.Derived, db)
+	}
+	return sc
+}
+
+// tableScope builds the scope of an UPDATE or DELETE, which reads a single table
+func tableScope(tableName string, db *schema.Database) *scope {
+	sc := &scope{columns: make(map[string][]string)}
+	sc.add(tableName, nil, db)
+	return sc
+}
+
+// add registers a table, or a derived table when derived is set
+func (s *scope) add(name string, derived *ast.SelectStatement, db *schema.Database) {
+	s.tables = append(s.tables, name)
+	if derived != nil {
+		s.columns[name] = derivedColumnNames(derived, db)
+		return
+	}
+
+	var names []string
+	if table, ok := db.Tables[name]; ok {
+		for _, col := range table.Schema.Columns {
+			names = append(names, col.Name)
+		}
+	}
+	s.columns[name] = append([]string{}, names...) // An unknown table defines no columns
+}
+
+// defines reports whether a column reference resolves to one of the scope's own tables
+// A qualified reference matches by table name; an unqualified one by column name.
+func (s *scope) defines(ident *ast.Identifier) bool {
+	for _, name := range s.tables {
+		if ident.Table != "" {
+			if ident.Table == name {
+				return true
+			}
+			continue
+		}
+		columns := s.columns[name]
+		if columns == nil {
+			return true
+		}
+		for _, col := range columns {
+			if col == ident.Value {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// lookup returns the innermost scope that defines a column reference, or nil
+func (s *scope) lookup(ident *ast.Identifier) *scope {
+	for sc := s; sc != nil; sc = sc.parent {
+		if sc.defines(ident) {
+			return sc
+		}
+	}
+	return nil
+}
+
+// encloses reports whether s is t or one of the scopes enclosing t
+func (s *scope) encloses(t *scope) bool {
+	for sc := t; sc != nil; sc = sc.parent {
+		if sc == s {
+			return true
+		}
+	}
+	return false
+}
+
+// derivedColumnNames returns the column names a derived table's SELECT list produces
+// Returns nil (unknown) when they cannot be determined without planning it.
+func derivedColumnNames(query *ast.SelectStatement, db *schema.Database) []string {
+	if isSelectAll(query) {
+		sc := newScope(query, db, nil)
+		var names []string
+		for _, name := range sc.tables {
+			if sc.columns[name] == nil {
+				return nil
+			}
+			names = append(names, sc.columns[name]...)
+		}
+		return names
+	}
+
+	names := make([]string, len(query.Fields))
+	for i, field := range query.Fields {
+		expr, alias := unwrapAlias(field)
+		switch e := expr.(type) {
+		case *ast.Identifier:
+			names[i] = e.Value
+		case *ast.FunctionCall:
+			if expression.IsAggregate(e.Name) {
+				names[i] = aggregateName(e)
+			} else {
+				names[i] = e.String()
+			}
+		default:
+			names[i] = expr.String()
+		}
+		if alias != "" {
+			names[i] = alias
+		}
+	}
+	return names
+}
+
+// outerReferences returns the column references in a subquery (at any depth) that resolve
+// to the enclosing queries of outer rather than to tables the subquery reads itself
+// A subquery with outer references is correlated. Derived tables are not searched:
+// they cannot see the query they appear in. A reference qualified by a table that no
+// query in scope reads is an error, rather than being matched to a column by name.
+func outerReferences(query *ast.SelectStatement, db *schema.Database, outer *scope) ([]*ast.Identifier, error) {
+	var refs []*ast.Identifier
+	var unknown *ast.Identifier
+	var visit func(stmt *ast.SelectStatement, parent *scope)
+	visit = func(stmt *ast.SelectStatement, parent *scope) {
+		sc := newScope(stmt, db, parent)
+		for _, expr := range statementExpressions(stmt) {
+			walkExpression(expr, func(ident *ast.Identifier) {
+				if ident.Value == "*" {
+					return
+				}
+				found := sc.lookup(ident)
+				if found == nil && ident.Table != "" && unknown == nil {
+					unknown = ident
+				}
+				if found != nil && outer != nil && found.encloses(outer) {
+					refs = append(refs, ident)
+				}
+			}, func(sub *ast.SelectStatement) {
+				visit(sub, sc)
+			})
+		}
+	}
+	visit(query, outer)
+	if unknown != nil {
+		return nil, fmt.Errorf("column %s references table '%s', which the query does not read", unknown.String(), unknown.Table)
+	}
+	return refs, nil
+}
+
+// statementExpressions returns every expression of a SELECT that may reference columns
+func statementExpressions(stmt *ast.SelectStatement) []ast.Expression {
+	exprs := append([]ast.Expression{}, stmt.Fields...)
+	for _, j := range stmt.Joins {
+		exprs = append(exprs, j.OnCondition)
+	}
+	exprs = append(exprs, stmt.Where, stmt.Having)
+	for _, g := range stmt.GroupBy {
+		exprs = append(exprs, g)
+	}
+	for _, item := range stmt.OrderBy {
+		exprs = append(exprs, item.Expr)
+	}
+	return exprs
+}
+
+// walkExpression calls onIdent for every column reference in an expression and onQuery
+// for every subquery in it, without descending into the subqueries
+func walkExpression(expr ast.Expression, onIdent func(*ast.Identifier), onQuery func(*ast.SelectStatement)) {
+	walk := func(exprs ...ast.Expression) {
+		for _, e := range exprs {
+			if e != nil {
+				walkExpression(e, onIdent, onQuery)
+			}
+		}
+	}
+
+	switch e := expr.(type) {
+	case *ast.Identifier:
+		onIdent(e)
+	case *ast.AliasExpression:
+		walk(e.Expr)
+	case *ast.UnaryExpression:
+		walk(e.Operand)
+	case *ast.BinaryExpression:
+		walk(e.Left, e.Right)
+	case *ast.LogicalExpression:
+		walk(e.Left, e.Right)
+	case *ast.NotExpression:
+		walk(e.Expr)
+	case *ast.InExpression:
+		walk(e.Left)
+		walk(e.Values...)
+		if e.Subquery != nil {
+			onQuery(e.Subquery)
+		}
+	case *ast.BetweenExpression:
+		walk(e.Expr, e.Low, e.High)
+	case *ast.LikeExpression:
+		walk(e.Expr, e.Pattern, e.Escape)
+	case *ast.IsNullExpression:
+		walk(e.Expr)
+	case *ast.FunctionCall:
+		walk(e.Args...)
+	case *ast.WindowExpression:
+		walk(e.Function)
+		walk(e.PartitionBy...)
+		for _, item := range e.OrderBy {
+			walk(item.Expr)
+		}
+	case *ast.CastExpression:
+		walk(e.Expr)
+	case *ast.SubqueryExpression:
+		onQuery(e.Query)
+	case *ast.ExistsExpression:
+		onQuery(e.Query)
+	}
+}
+
+// containsSubquery reports whether an expression contains a subquery
+func containsSubquery(expr ast.Expression) bool {
+	found := false
+	if expr != nil {
+		walkExpression(expr, func(*ast.Identifier) {}, func(*ast.SelectStatement) { found = true })
+	}
+	return found
+}
+
+// subqueryPlanner plans the subqueries in a statement's expressions as subtrees of their own
+// and replaces them with expression.Subquery or expression.SemiJoin nodes that run them
+type subqueryPlanner struct {
+	db         *schema.Database // Tables the subqueries read
+	tx         *transaction.Transaction
+	ctes       withTables // Common table expressions the subqueries may read
+	scope      *scope     // Tables of the statement the subqueries appear in
+	outerTable string     // Table of the statement's rows, qualifying their bare column names
+	planned    []*plan.Subquery
+}
+
+// rewriteSelect returns a copy of a SELECT with the subqueries in its field list,
+// WHERE clause and HAVING clause planned
+func (sp *subqueryPlanner) rewriteSelect(stmt *ast.SelectStatement) (*ast.SelectStatement, error) {
+	rewritten := *stmt
+	rewritten.Fields = make([]ast.Expression, len(stmt.Fields))
+	for i, field := range stmt.Fields {
+		f, err := sp.rewrite(field)
+		if err != nil {
+			return nil, err
+		}
+		rewritten.Fields[i] = f
+	}
+
+	var err error
+	if rewritten.Where, err = sp.rewrite(stmt.Where); err != nil {
+		return nil, err
+	}
+	if rewritten.Having, err = sp.rewrite(stmt.Having); err != nil {
+		return nil, err
+	}
+	return &rewritten, nil
+}
+
+// rewrite returns an expression with its subqueries planned
+// Expressions without subqueries are returned unchanged.
+func (sp *subqueryPlanner) rewrite(expr ast.Expression) (ast.Expression, error) {
+	if !containsSubquery(expr) {
+		return expr, nil
+	}
+	rewriteAll := func(exprs ...ast.Expression) ([]ast.Expression, error) {
+		out := make([]ast.Expression, len(exprs))
+		for i, e := range exprs {
+			r, err := sp.rewrite(e)
+			if err != nil {
+				return nil, err
+			}
+			out[i] = r
+		}
+		return out, nil
+	}
+
+	switch e := expr.(type) {
+	case *ast.SubqueryExpression:
+		sub, err := sp.plan(e.Query)
+		if err != nil {
+			return nil, err
+		}
+		if len(sub.Columns) != 1 {
+			return nil, fmt.Errorf("subquery must return one column, got %d", len(sub.Columns))
+		}
+		return &expression.Subquery{Expression: e, Kind: expression.ScalarSubquery, Plan: sub}, nil
+
+	case *ast.ExistsExpression:
+		if semi, err := sp.semiJoin(e.Query, nil, false, e); semi != nil || err != nil {
+			return semi, err
+		}
+		sub, err := sp.plan(e.Query)
+		if err != nil {
+			return nil, err
+		}
+		return &expression.Subquery{Expression: e, Kind: expression.ExistsSubquery, Plan: sub}, nil
+
+	case *ast.InExpression:
+		if e.Subquery == nil {
+			parts, err := rewriteAll(append([]ast.Expression{e.Left}, e.Values...)...)
+			if err != nil {
+				return nil, err
+			}
+			return &ast.InExpression{Left: parts[0], Values: parts[1:], Not: e.Not}, nil
+		}
+
+		left, err := sp.rewrite(e.Left)
+		if err != nil {
+			return nil, err
+		}
+		if semi, err := sp.semiJoin(e.Subquery, left, e.Not, e); semi != nil || err != nil {
+			return semi, err
+		}
+		sub, err := sp.plan(e.Subquery)
+		if err != nil {
+			return nil, err
+		}
+		if len(sub.Columns) != 1 {
+			return nil, fmt.Errorf("subquery in IN must return one column, got %d", len(sub.Columns))
+		}
+		return &expression.Subquery{Expression: e, Kind: expression.InSubquery, Left: left, Not: e.Not, Plan: sub}, nil
+
+	case *ast.AliasExpression:
+		inner, err := sp.rewrite(e.Expr)
+		if err != nil {
+			return nil, err
+		}
+		return &ast.AliasExpression{Expr: inner, Alias: e.Alias}, nil
+
+	case *ast.UnaryExpression:
+		operand, err := sp.rewrite(e.Operand)
+		if err != nil {
+			return nil, err
+		}
+		return &ast.UnaryExpression{Operator: e.Operator, Operand: operand}, nil
+
+	case *ast.BinaryExpression:
+		parts, err := rewriteAll(e.Left, e.Right)
+		if err != nil {
+			return nil, err
+		}
+		return &ast.BinaryExpression{Left: parts[0], Operator: e.Operator, Right: parts[1]}, nil
+
+	case *ast.LogicalExpression:
+		parts, err := rewriteAll(e.Left, e.Right)
+		if err != nil {
+			return nil, err
+		}
+		return &ast.LogicalExpression{Left: parts[0], Operator: e.Operator, Right: parts[1]}, nil
+
+	case *ast.NotExpression:
+		inner, err := sp.rewrite(e.Expr)
+		if err != nil {
+			return nil, err
+		}
+		return &ast.NotExpression{Expr: inner}, nil
+
+	case *ast.BetweenExpression:
+		parts, err := rewriteAll(e.Expr, e.Low, e.High)
+		if err != nil {
+			return nil, err
+		}
+		return &ast.BetweenExpression{Expr: parts[0], Low: parts[1], High: parts[2], Not: e.Not}, nil
+
+	case *ast.LikeExpression:
+		parts, err := rewriteAll(e.Expr, e.Pattern)
+		if err != nil {
+			return nil, err
+		}
+		rewritten := *e
+		rewritten.Expr, rewritten.Pattern = parts[0], parts[1]
+		return &rewritten, nil
+
+	case *ast.IsNullExpression:
+		inner, err := sp.rewrite(e.Expr)
+		if err != nil {
+			return nil, err
+		}
+		return &ast.IsNullExpression{Expr: inner, Not: e.Not}, nil
+
+	case *ast.FunctionCall:
+		args, err := rewriteAll(e.Args...)
+		if err != nil {
+			return nil, err
+		}
+		return &ast.FunctionCall{Name: e.Name, Args: args, Star: e.Star, Distinct: e.Distinct}, nil
+
+	case *ast.CastExpression:
+		inner, err := sp.rewrite(e.Expr)
+		if err != nil {
+			return nil, err
+		}
+		return &ast.CastExpression{Expr: inner, Type: e.Type}, nil
+
+	default:
+		return nil, fmt.Errorf("subqueries are not supported in %s", expr.String())
+	}
+}
+
+// plan plans a subquery as it is written; it is correlated when it references the outer query
+func (sp *subqueryPlanner) plan(query *ast.SelectStatement) (*plan.Subquery, error) {
+	refs, err := outerReferences(query, sp.db, sp.scope)
+	if err != nil {
+		return nil, fmt.Errorf("invalid subquery: %w", err)
+	}
+	root, out, err := planSelect(query, sp.db, sp.tx, sp.scope, sp.ctes)
+	if err != nil {
+		return nil, fmt.Errorf("invalid subquery: %w", err)
+	}
+
+	sub := &plan.Subquery{
+		Root:       root,
+		Columns:    out.Columns,
+		Keys:       out.Keys,
+		Correlated: len(refs) > 0,
+		OuterTable: sp.outerTable,
+	}
+	root.Metadata()["correlated"] = sub.Correlated
+	sp.planned = append(sp.planned, sub)
+	return sub, nil
+}
+
+// semiJoin plans IN (SELECT ...) or a correlated EXISTS (SELECT ...) as a SemiJoin
+// The subquery qualifies when it does not aggregate or page its rows and its only references
+// to the outer query are top-level WHERE conditions of the form inner = outer. Those
+// conditions are removed and their inner sides are returned as extra columns, so the
+// subquery runs once (uncorrelated) and is matched per outer row through a hash table.
+// Returns nil when the subquery does not qualify; it is then run for every outer row.
+func (sp *subqueryPlanner) semiJoin(query *ast.SelectStatement, value ast.Expression, not bool, original ast.Expression) (*expression.SemiJoin, error) {
+	refs, err := outerReferences(query, sp.db, sp.scope)
+	if err != nil {
+		return nil, fmt.Errorf("invalid subquery: %w", err)
+	}
+	if len(refs) == 0 && value == nil {
+		return nil, nil // An uncorrelated EXISTS runs once anyway
+	}
+	if isAggregateQuery(query) || query.Limit != nil || query.Offset != nil {
+		return nil, nil
+	}
+	if value != nil && (len(query.Fields) != 1 || isSelectAll(query)) {
+		return nil, nil
+	}
+
+	outer := make(map[*ast.Identifier]bool, len(refs))
+	for _, ref := range refs {
+		outer[ref] = true
+	}
+
+	// Split the WHERE clause into correlation equalities and the remaining conditions
+	var remaining, innerKeys, outerKeys []ast.Expression
+	matched := 0
+	for _, cond := range splitConjuncts(query.Where) {
+		inner, outerKey, n := correlationEquality(cond, outer)
+		if inner == nil {
+			remaining = append(remaining, cond)
+			continue
+		}
+		innerKeys = append(innerKeys, inner)
+		outerKeys = append(outerKeys, outerKey)
+		matched += n
+	}
+	if matched != len(refs) {
+		return nil, nil // The outer query is referenced elsewhere
+	}
+
+	rewritten := *query
+	rewritten.Fields = nil
+	if value != nil {
+		field, _ := unwrapAlias(query.Fields[0])
+		rewritten.Fields = append(rewritten.Fields, &ast.AliasExpression{Expr: field, Alias: "in_value"})
+	}
+	for i, key := range innerKeys {
+		rewritten.Fields = append(rewritten.Fields, &ast.AliasExpression{Expr: key, Alias: fmt.Sprintf("in_key%d", i+1)})
+	}
+	rewritten.Where = joinConjuncts(remaining)
+	rewritten.OrderBy = nil // Row order does not matter to a hash table
+
+	root, out, err := planSelect(&rewritten, sp.db, sp.tx, sp.scope, sp.ctes)
+	if err != nil {
+		return nil, fmt.Errorf("invalid subquery: %w", err)
+	}
+	root.Metadata()["semi_join"] = true
+
+	sub := &plan.Subquery{Root: root, Columns: out.Columns, Keys: out.Keys, OuterTable: sp.outerTable}
+	sp.planned = append(sp.planned, sub)
+	return &expression.SemiJoin{Expression: original, Value: value, Not: not, Keys: outerKeys, Plan: sub}, nil
+}
+
+// correlationEquality splits a condition of the form inner = outer, where one side references
+// only the outer query and the other none of it (and neither contains a subquery)
+// Returns the inner side, the outer side and the number of outer references, or nil.
+func correlationEquality(cond ast.Expression, outer map[*ast.Identifier]bool) (ast.Expression, ast.Expression, int) {
+	bin, ok := cond.(*ast.BinaryExpression)
+	if !ok || bin.Operator != "=" || containsSubquery(bin.Left) || containsSubquery(bin.Right) {
+		return nil, nil, 0
+	}
+
+	count := func(expr ast.Expression) (refs, total int) {
+		walkExpression(expr, func(ident *ast.Identifier) {
+			total++
+			if outer[ident] {
+				refs++
+			}
+		}, func(*ast.SelectStatement) {})
+		return refs, total
+	}
+	leftRefs, leftTotal := count(bin.Left)
+	rightRefs, rightTotal := count(bin.Right)
+
+	switch {
+	case leftRefs > 0 && leftRefs == leftTotal && rightRefs == 0:
+		return bin.Right, bin.Left, leftRefs
+	case rightRefs > 0 && rightRefs == rightTotal && leftRefs == 0:
+		return bin.Left, bin.Right, rightRefs
+	default:
+		return nil, nil, 0
+	}
+}
+
+// joinConjuncts combines conditions with AND; nil when there are none
+func joinConjuncts(conds []ast.Expression) ast.Expression {
+	var expr ast.Expression
+	for _, cond := range conds {
+		if expr == nil {
+			expr = cond
+			continue
+		}
+		expr = &ast.LogicalExpression{Left: expr, Operator: "AND", Right: cond}
+	}
+	return expr
+}
+
+// checkModifiedTable rejects correlated subqueries that read the table an UPDATE or DELETE
+// modifies: they would run while the statement holds the table's write lock.
+// Uncorrelated subqueries are fine, as they run before the statement starts.
+func (sp *subqueryPlanner) checkModifiedTable(tableName string) error {
+	for _, sub := range sp.planned {
+		if sub.Correlated && readsTable(sub.Root, tableName) {
+			return fmt.Errorf("correlated subquery cannot read %s, the table being modified", tableName)
+		}
+	}
+	return nil
+}
+
+// readsTable reports whether a plan tree, including its subqueries, reads a stored table
+func readsTable(node plan.Node, tableName string) bool {
+	found := false
+	plan.WalkTree(node, func(n plan.Node) error {
+		switch n := n.(type) {
+		case *plan.ScanNode:
+			found = found || n.TableName == tableName
+		case *plan.IndexScanNode:
+			found = found || n.TableName == tableName
+		case *plan.SelectNode:
+			if len(n.Children()) == 0 {
+				found = found || n.TableName == tableName
+			}
+			for _, sub := range n.Subqueries {
+				found = found || readsTable(sub.Root, tableName)
+			}
+		}
+		return nil
+	})
+	return found
+}
@@ -0,0 +1,228 @@
+package planner
+
+import (
+	"fmt"
+
+	"github.com/leengari/mini-rdbms/internal/domain/schema"
+	"github.com/leengari/mini-rdbms/internal/parser/ast"
+	"github.com/leengari/mini-rdbms/internal/plan"
+	"github.com/leengari/mini-rdbms/internal/planner/expression"
+)
+
+// windowField is a SELECT field that calls a window function, evaluated by a WindowNode
+type windowField struct {
+	Name string // Output column name: the alias, or the expression text
+	Expr *ast.WindowExpression
+}
+
+// containsWindow reports whether an expression calls a window function
+func containsWindow(expr ast.Expression) bool {
+	switch e := expr.(type) {
+	case *ast.WindowExpression:
+		return true
+	case *ast.AliasExpression:
+		return containsWindow(e.Expr)
+	case *ast.FunctionCall:
+		for _, arg := range e.Args {
+			if containsWindow(arg) {
+				return true
+			}
+		}
+		return false
+	case *ast.CastExpression:
+		return containsWindow(e.Expr)
+	case *ast.UnaryExpression:
+		return containsWindow(e.Operand)
+	case *ast.BinaryExpression:
+		return containsWindow(e.Left) || containsWindow(e.Right)
+	case *ast.LogicalExpression:
+		return containsWindow(e.Left) || containsWindow(e.Right)
+	case *ast.NotExpression:
+		return containsWindow(e.Expr)
+	case *ast.IsNullExpression:
+		return containsWindow(e.Expr)
+	case *ast.BetweenExpression:
+		return containsWindow(e.Expr) || containsWindow(e.Low) || containsWindow(e.High)
+	case *ast.LikeExpression:
+		return containsWindow(e.Expr) || containsWindow(e.Pattern)
+	case *ast.InExpression:
+		if containsWindow(e.Left) {
+			return true
+		}
+		for _, v := range e.Values {
+			if containsWindow(v) {
+				return true
+			}
+		}
+		return false
+	default:
+		return false
+	}
+}
+
+// planWindow wraps source in a WindowNode that computes the window function SELECT fields
+func planWindow(stmt *ast.SelectStatement, db *schema.Database, source plan.Node, fields []windowField) (*plan.WindowNode, error) {
+	tables := queryTables(stmt)
+
+	functions := make([]plan.WindowFunction, len(fields))
+	for i, f := range fields {
+		fn, err := buildWindowFunction(f, tables, db)
+		if err != nil {
+			return nil, fmt.Errorf("invalid window function %s: %w", f.Expr.String(), err)
+		}
+		functions[i] = fn
+	}
+
+	node := plan.NewWindowNode(source, functions)
+	node.Metadata()["window_functions"] = len(functions)
+	return node, nil
+}
+
+// buildWindowFunction validates a window function call and resolves its columns and frame
+// Arguments are scalar expressions, except the offset (a non-negative integer) and default
+// (a constant) of LAG and LEAD. Only FIRST_VALUE, LAST_VALUE and aggregates read a frame.
+func buildWindowFunction(f windowField, tables []string, db *schema.Database) (plan.WindowFunction, error) {
+	call := f.Expr.Function
+	fn := plan.WindowFunction{Name: f.Name, Func: call.Name}
+	if call.Distinct {
+		return fn, fmt.Errorf("DISTINCT is not supported in window functions")
+	}
+
+	// 1. Partition and order keys
+	for _, key := range f.Expr.PartitionBy {
+		ident, ok := key.(*ast.Identifier)
+		if !ok {
+			return fn, fmt.Errorf("PARTITION BY key must be a column, got %s", key.String())
+		}
+		col, tableName, err := resolveColumn(ident, tables, db)
+		if err != nil {
+			return fn, fmt.Errorf("invalid PARTITION BY column: %w", err)
+		}
+		fn.PartitionBy = append(fn.PartitionBy, plan.GroupKey{Table: tableName, Column: ident.Value, Type: col.Type})
+	}
+	var orderTypes []schema.ColumnType
+	for _, item := range f.Expr.OrderBy {
+		ident, ok := item.Expr.(*ast.Identifier)
+		if !ok {
+			return fn, fmt.Errorf("ORDER BY key must be a column, got %s", item.Expr.String())
+		}
+		col, _, err := resolveColumn(ident, tables, db)
+		if err != nil {
+			return fn, fmt.Errorf("invalid ORDER BY key: %w", err)
+		}
+		fn.OrderBy = append(fn.OrderBy, sortDirection(plan.SortKey{Table: ident.Table, Column: ident.Value}, item))
+		orderTypes = append(orderTypes, col.Type)
+	}
+
+	// 2. Arguments and result type
+	usesFrame := false
+	switch call.Name {
+	case "ROW_NUMBER", "RANK", "DENSE_RANK":
+		if call.Star || len(call.Args) > 0 {
+			return fn, fmt.Errorf("%s takes no arguments", call.Name)
+		}
+		fn.Type = schema.ColumnTypeInt
+
+	case "LAG", "LEAD":
+		if call.Star || len(call.Args) == 0 || len(call.Args) > 3 {
+			return fn, fmt.Errorf("%s takes one to three arguments", call.Name)
+		}
+		if err := windowArgument(&fn, call, tables, db); err != nil {
+			return fn, err
+		}
+		fn.Type = fn.ArgType
+
+		fn.Offset = 1
+		if len(call.Args) > 1 {
+			lit, ok := call.Args[1].(*ast.Literal)
+			if !ok || lit.Kind != ast.LiteralInt || lit.Value.(int) < 0 {
+				return fn, fmt.Errorf("%s offset must be a non-negative integer, got %s", call.Name, call.Args[1].String())
+			}
+			fn.Offset = lit.Value.(int)
+		}
+		if len(call.Args) > 2 {
+			var err error
+			col := &schema.Column{Name: call.Args[0].String(), Type: fn.ArgType}
+			if fn.Default, err = evaluateConstant(call.Args[2], col); err != nil {
+				return fn, fmt.Errorf("invalid %s default: %w", call.Name, err)
+			}
+		}
+
+	case "FIRST_VALUE", "LAST_VALUE":
+		if call.Star || len(call.Args) != 1 {
+			return fn, fmt.Errorf("%s takes exactly one argument", call.Name)
+		}
+		if err := windowArgument(&fn, call, tables, db); err != nil {
+			return fn, err
+		}
+		fn.Type = fn.ArgType
+		usesFrame = true
+
+	default:
+		if !expression.IsAggregate(call.Name) {
+			return fn, fmt.Errorf("unsupported window function: %s", call.Name)
+		}
+		spec, err := buildAggregateSpec(call, tables, db)
+		if err != nil {
+			return fn, err
+		}
+		fn.Arg, fn.ArgType = spec.Arg, spec.ArgType
+		fn.Type = spec.ResultType()
+		usesFrame = true
+	}
+
+	// 3. Frame: by default, the rows up to the current row's last peer when the window
+	// is ordered, otherwise the whole partition
+	frame := f.Expr.Frame
+	if frame != nil && !usesFrame {
+		return fn, fmt.Errorf("%s does not accept a window frame", call.Name)
+	}
+	fn.Frame = plan.WindowFrame{Range: true, Start: plan.FrameBound{Unbounded: true}, End: plan.FrameBound{Unbounded: len(fn.OrderBy) == 0}}
+	if frame != nil {
+		fn.Frame = plan.WindowFrame{Range: frame.Mode == "RANGE", Start: frameBound(frame.Start), End: frameBound(frame.End)}
+		offset := fn.Frame.Start.Offset != 0 || fn.Frame.End.Offset != 0
+		if fn.Frame.Range && offset && (len(orderTypes) != 1 || !isNumericColumn(orderTypes[0])) {
+			return fn, fmt.Errorf("RANGE with an offset requires exactly one numeric ORDER BY key")
+		}
+	}
+
+	return fn, nil
+}
+
+// windowArgument compiles the value a LAG, LEAD, FIRST_VALUE or LAST_VALUE call reads
+// (see buildArgument)
+func windowArgument(fn *plan.WindowFunction, call *ast.FunctionCall, tables []string, db *schema.Database) error {
+	arg, argType, err := buildArgument(call, call.Args[0], tables, db)
+	if err != nil {
+		return err
+	}
+	fn.Arg, fn.ArgType = arg, argType
+	return nil
+}
+
+// frameBound converts a parsed frame bound to a signed offset from the current row
+func frameBound(b ast.FrameBound) plan.FrameBound {
+	switch b.Kind {
+	case ast.UnboundedPreceding, ast.UnboundedFollowing:
+		return plan.FrameBound{Unbounded: true}
+	case ast.Preceding:
+		return plan.FrameBound{Offset: -b.Offset}
+	case ast.Following:
+		return plan.FrameBound{Offset: b.Offset}
+	default:
+		return plan.FrameBound{}
+	}
+}
+
+// windowType returns the type of a window function's result ("" when there is none)
+func windowType(window *plan.WindowNode, name string) schema.ColumnType {
+	if window == nil {
+		return ""
+	}
+	for _, fn := range window.Functions {
+		if fn.Name == name {
+			return fn.Type
+		}
+	}
+	return ""
+}
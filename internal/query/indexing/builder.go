@@ -9,7 +9,9 @@ import (
 	"github.com/leengari/mini-rdbms/internal/domain/schema"
 )
 
-// BuildIndexes rebuilds all indexes for primary/unique columns
+// BuildIndexes rebuilds all indexes for primary/unique columns, and refills the
+// table's ordered (CREATE INDEX) indexes
+// Indexes refer to rows by row id, so the table's rows must already have theirs.
 // Returns error on constraint violation or data inconsistency
 func BuildIndexes(table *schema.Table) error {
 	// Acquire write lock for index building
@@ -26,13 +28,13 @@ func BuildIndexes(table *schema.Table) error {
 
 		idx := &data.Index{
 			Column: col.Name,
-			Data:   make(map[interface{}][]int),
+			Data:   make(map[interface{}][]int64),
 			Unique: col.PrimaryKey || col.Unique,
 		}
 
 		for rowPos, row := range table.Rows {
 			val, ok := row.Data[col.Name]
-			if !ok {
+			if !ok || val == nil {
 				if col.NotNull {
 					return errors.NewNotNullViolation(table.Name, col.Name, rowPos)
 				}
@@ -66,7 +68,7 @@ func BuildIndexes(table *schema.Table) error {
 				}
 			}
 
-			idx.Data[val] = append(idx.Data[val], rowPos)
+			idx.Data[val] = append(idx.Data[val], row.ID)
 
 			if idx.Unique && len(idx.Data[val]) > 1 {
 				return errors.NewUniqueViolation(
@@ -87,7 +89,7 @@ func BuildIndexes(table *schema.Table) error {
 			slog.Bool("unique_constraint", idx.Unique))
 	}
 
-	return nil
+	return table.RebuildOrderedIndexesUnsafe()
 }
 
 // BuildDatabaseIndexes rebuilds indexes for all tables
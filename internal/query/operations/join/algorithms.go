@@ -0,0 +1,234 @@
+package join
+
+import (
+	"cmp"
+	"fmt"
+	"sort"
+
+	"github.com/leengari/mini-rdbms/internal/domain/schema"
+	"github.com/leengari/mini-rdbms/internal/util/types"
+)
+
+// Algorithm is the strategy used to find matching rows
+type Algorithm string
+
+const (
+	AlgorithmNestedLoop Algorithm = "nested_loop" // Compares every pair of rows; no extra memory
+	AlgorithmHash       Algorithm = "hash"        // Builds a hash table on one input and probes it with the other
+	AlgorithmMerge      Algorithm = "merge"       // Sorts both inputs on the join column and merges them
+)
+
+// ParseAlgorithm converts a configuration string into an Algorithm
+func ParseAlgorithm(name string) (Algorithm, error) {
+	switch algorithm := Algorithm(name); algorithm {
+	case AlgorithmNestedLoop, AlgorithmHash, AlgorithmMerge:
+		return algorithm, nil
+	default:
+		return "", fmt.Errorf("unknown JOIN algorithm: %q (expected nested_loop, hash or merge)", name)
+	}
+}
+
+// rowPair is a pair of row positions (left table, right table) whose join columns are equal
+type rowPair struct {
+	left  int
+	right int
+}
+
+// columnType returns the type of a table's column ("" if the table has no such column)
+func columnType(table *schema.Table, column string) schema.ColumnType {
+	for _, col := range table.Schema.Columns {
+		if col.Name == column {
+			return col.Type
+		}
+	}
+	return ""
+}
+
+// joinValue returns a row's join column value; NULL or missing values never match
+func joinValue(table *schema.Table, pos int, column string) (interface{}, bool) {
+	value, ok := table.Rows[pos].Data[column]
+	if !ok || value == nil {
+		return nil, false
+	}
+	return value, true
+}
+
+// nestedLoopMatches compares every left row with every right row
+// Matches come out in left row order, then right row order.
+func nestedLoopMatches(leftTable, rightTable *schema.Table, leftColumn, rightColumn string) []rowPair {
+	var matches []rowPair
+	for leftPos := range leftTable.Rows {
+		leftValue, ok := joinValue(leftTable, leftPos, leftColumn)
+		if !ok {
+			continue
+		}
+		for rightPos := range rightTable.Rows {
+			rightValue, ok := joinValue(rightTable, rightPos, rightColumn)
+			if ok && types.CompareValues(leftValue, "=", rightValue) {
+				matches = append(matches, rowPair{left: leftPos, right: rightPos})
+			}
+		}
+	}
+	return matches
+}
+
+// hashMatches builds a hash table on the smaller input and probes it with the other
+// An existing index on the right join column is reused as the hash table.
+// Keys are values of the build column's type (see schema.StoredValue), and probe values
+// are converted to it, so an INT column matches equal values of a FLOAT one.
+// Matches come out in the order of the probing input.
+func hashMatches(leftTable, rightTable *schema.Table, leftColumn, rightColumn string) []rowPair {
+	var matches []rowPair
+
+	// Reuse the right table's index: no build phase, probe it with the left rows
+	if idx, exists := rightTable.Indexes[rightColumn]; exists {
+		keyType := columnType(rightTable, rightColumn)
+		for leftPos := range leftTable.Rows {
+			leftValue, ok := joinValue(leftTable, leftPos, leftColumn)
+			if !ok {
+				continue
+			}
+			for _, rightID := range idx.Data[schema.StoredValue(keyType, leftValue)] {
+				if rightPos, found := rightTable.RowPositionUnsafe(rightID); found {
+					matches = append(matches, rowPair{left: leftPos, right: rightPos})
+				}
+			}
+		}
+		return matches
+	}
+
+	buildLeft := len(leftTable.Rows) < len(rightTable.Rows)
+	buildTable, buildColumn := rightTable, rightColumn
+	probeTable, probeColumn := leftTable, leftColumn
+	if buildLeft {
+		buildTable, buildColumn = leftTable, leftColumn
+		probeTable, probeColumn = rightTable, rightColumn
+	}
+
+	// Build phase
+	keyType := columnType(buildTable, buildColumn)
+	hashTable := make(map[interface{}][]int)
+	for pos := range buildTable.Rows {
+		if value, ok := joinValue(buildTable, pos, buildColumn); ok {
+			key := schema.StoredValue(keyType, value)
+			hashTable[key] = append(hashTable[key], pos)
+		}
+	}
+
+	// Probe phase
+	for probePos := range probeTable.Rows {
+		value, ok := joinValue(probeTable, probePos, probeColumn)
+		if !ok {
+			continue
+		}
+		for _, buildPos := range hashTable[schema.StoredValue(keyType, value)] {
+			if buildLeft {
+				matches = append(matches, rowPair{left: buildPos, right: probePos})
+			} else {
+				matches = append(matches, rowPair{left: probePos, right: buildPos})
+			}
+		}
+	}
+	return matches
+}
+
+// mergeMatches sorts both inputs on the join column and merges the sorted runs
+// Equal keys produce every pair between the two runs. Matches come out in key order.
+func mergeMatches(leftTable, rightTable *schema.Table, leftColumn, rightColumn string) []rowPair {
+	left := sortedPositions(leftTable, leftColumn)
+	right := sortedPositions(rightTable, rightColumn)
+
+	var matches []rowPair
+	i, j := 0, 0
+	for i < len(left) && j < len(right) {
+		leftValue, _ := joinValue(leftTable, left[i], leftColumn)
+		rightValue, _ := joinValue(rightTable, right[j], rightColumn)
+
+		switch c := compareKeys(leftValue, rightValue); {
+		case c < 0:
+			i++
+		case c > 0:
+			j++
+		default:
+			// Find the end of the run of equal keys on each side
+			iEnd := i + 1
+			for iEnd < len(left) {
+				v, _ := joinValue(leftTable, left[iEnd], leftColumn)
+				if compareKeys(v, leftValue) != 0 {
+					break
+				}
+				iEnd++
+			}
+			jEnd := j + 1
+			for jEnd < len(right) {
+				v, _ := joinValue(rightTable, right[jEnd], rightColumn)
+				if compareKeys(v, rightValue) != 0 {
+					break
+				}
+				jEnd++
+			}
+
+			for _, leftPos := range left[i:iEnd] {
+				for _, rightPos := range right[j:jEnd] {
+					matches = append(matches, rowPair{left: leftPos, right: rightPos})
+				}
+			}
+			i, j = iEnd, jEnd
+		}
+	}
+	return matches
+}
+
+// sortedPositions returns the positions of rows with a non-NULL join value, sorted by that value
+func sortedPositions(table *schema.Table, column string) []int {
+	positions := make([]int, 0, len(table.Rows))
+	for pos := range table.Rows {
+		if _, ok := joinValue(table, pos, column); ok {
+			positions = append(positions, pos)
+		}
+	}
+
+	sort.SliceStable(positions, func(a, b int) bool {
+		va, _ := joinValue(table, positions[a], column)
+		vb, _ := joinValue(table, positions[b], column)
+		return compareKeys(va, vb) < 0
+	})
+	return positions
+}
+
+// compareKeys orders two non-NULL join values, returning -1, 0 or 1
+// Numbers compare numerically regardless of their Go type, strings lexically and
+// booleans false before true. Values of different kinds are ordered by kind.
+func compareKeys(a, b interface{}) int {
+	if x, ok := types.NormalizeToFloat(a); ok {
+		if y, ok := types.NormalizeToFloat(b); ok {
+			return cmp.Compare(x, y)
+		}
+	}
+	if x, ok := a.(string); ok {
+		if y, ok := b.(string); ok {
+			return cmp.Compare(x, y)
+		}
+	}
+	if x, ok := a.(bool); ok {
+		if y, ok := b.(bool); ok {
+			switch {
+			case x == y:
+				return 0
+			case !x:
+				return -1
+			default:
+				return 1
+			}
+		}
+	}
+	return cmp.Compare(keyKind(a), keyKind(b))
+}
+
+// keyKind names the kind of a join value, used to order values of different kinds
+func keyKind(v interface{}) string {
+	if _, ok := types.NormalizeToFloat(v); ok {
+		return "number"
+	}
+	return fmt.Sprintf("%T", v)
+}
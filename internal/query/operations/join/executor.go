@@ -13,7 +13,7 @@ import (
 // JoinPredicate tests whether a joined row matches certain criteria
 type JoinPredicate func(data.JoinedRow) bool
 
-// ExecuteJoin performs a JOIN operation with the specified type
+// ExecuteJoin performs a JOIN operation with the specified type using a hash join
 // This is the unified API for all JOIN types (INNER, LEFT, RIGHT, FULL)
 // Supports optional predicate filtering and column projection
 func ExecuteJoin(
@@ -25,283 +25,115 @@ func ExecuteJoin(
 	pred JoinPredicate,
 	proj *projection.Projection,
 	tx *transaction.Transaction,
+) ([]data.JoinedRow, error) {
+	return ExecuteJoinWithAlgorithm(AlgorithmHash, leftTable, rightTable, leftColumn, rightColumn, joinType, pred, proj, tx)
+}
+
+// ExecuteJoinWithAlgorithm performs a JOIN operation, matching rows with the given algorithm
+// Every algorithm supports every JOIN type and returns the same rows; only the
+// order of the matched rows may differ.
+func ExecuteJoinWithAlgorithm(
+	algorithm Algorithm,
+	leftTable *schema.Table,
+	rightTable *schema.Table,
+	leftColumn string,
+	rightColumn string,
+	joinType JoinType,
+	pred JoinPredicate,
+	proj *projection.Projection,
+	tx *transaction.Transaction,
 ) ([]data.JoinedRow, error) {
 	if tx != nil {
-		slog.Debug("ExecuteJoin operation", "type", joinType, "tx_id", tx.ID)
+		slog.Debug("ExecuteJoin operation", "type", joinType, "algorithm", algorithm, "tx_id", tx.ID)
 	}
 	// Validate join condition and resolve qualified names
 	if err := validateJoinCondition(leftTable, rightTable, &leftColumn, &rightColumn); err != nil {
 		return nil, err
 	}
 
-	var results []data.JoinedRow
-	var err error
-
-	// Execute the appropriate JOIN type
 	switch joinType {
-	case JoinTypeInner:
-		results, err = executeInnerJoin(leftTable, rightTable, leftColumn, rightColumn, pred)
-	case JoinTypeLeft:
-		results, err = executeLeftJoin(leftTable, rightTable, leftColumn, rightColumn, pred)
-	case JoinTypeRight:
-		results, err = executeRightJoin(leftTable, rightTable, leftColumn, rightColumn, pred)
-	case JoinTypeFull:
-		results, err = executeFullJoin(leftTable, rightTable, leftColumn, rightColumn, pred)
+	case JoinTypeInner, JoinTypeLeft, JoinTypeRight, JoinTypeFull:
 	default:
 		return nil, fmt.Errorf("unknown JOIN type: %v", joinType)
 	}
 
-	if err != nil {
-		return nil, err
-	}
-
-	// Apply projection if specified
-	if proj != nil && !proj.SelectAll {
-		projectedResults := make([]data.JoinedRow, len(results))
-		for i, row := range results {
-			projectedResults[i] = projection.ProjectJoinedRow(row, proj)
-		}
-		return projectedResults, nil
-	}
-
-	return results, nil
-}
-
-// executeInnerJoin performs INNER JOIN
-func executeInnerJoin(
-	leftTable *schema.Table,
-	rightTable *schema.Table,
-	leftColumn string,
-	rightColumn string,
-	pred JoinPredicate,
-) ([]data.JoinedRow, error) {
 	// Acquire read locks on both tables
 	leftTable.RLock()
 	defer leftTable.RUnlock()
-	rightTable.RLock()
-	defer rightTable.RUnlock()
+	if rightTable != leftTable {
+		rightTable.RLock()
+		defer rightTable.RUnlock()
+	}
 
-	slog.Debug("Starting INNER JOIN",
+	slog.Debug("Starting JOIN",
+		slog.String("type", joinType.String()),
+		slog.String("algorithm", string(algorithm)),
 		slog.String("left_table", leftTable.Name),
 		slog.String("right_table", rightTable.Name),
 		slog.String("left_column", leftColumn),
 		slog.String("right_column", rightColumn),
 	)
 
-	// Build hash index on right table
-	hashIndex, reusedIndex := buildJoinIndex(rightTable, rightColumn)
-	if reusedIndex {
-		slog.Debug("Reusing existing index", slog.String("column", rightColumn))
+	var matches []rowPair
+	switch algorithm {
+	case AlgorithmNestedLoop:
+		matches = nestedLoopMatches(leftTable, rightTable, leftColumn, rightColumn)
+	case AlgorithmHash:
+		matches = hashMatches(leftTable, rightTable, leftColumn, rightColumn)
+	case AlgorithmMerge:
+		matches = mergeMatches(leftTable, rightTable, leftColumn, rightColumn)
+	default:
+		return nil, fmt.Errorf("unknown JOIN algorithm: %q", algorithm)
 	}
 
-	results := make([]data.JoinedRow, 0)
-	skippedByPredicate := 0
-
-	// Probe left table and combine matches
-	for _, leftRow := range leftTable.Rows {
-		leftValue, exists := leftRow.Data[leftColumn]
-		if !exists {
-			continue // Skip rows with NULL join column
-		}
-
-		rightPositions, found := hashIndex[leftValue]
-		if !found {
-			continue // No matches (INNER JOIN excludes)
-		}
-
-		for _, rightPos := range rightPositions {
-			rightRow := rightTable.Rows[rightPos]
-			joined := combineRows(leftRow, rightRow, leftTable.Name, rightTable.Name)
-
-			if pred != nil && !pred(joined) {
-				skippedByPredicate++
-				continue
-			}
+	results := combineMatches(leftTable, rightTable, matches, joinType, pred)
 
-			results = append(results, joined)
+	// Apply projection if specified
+	if proj != nil && !proj.SelectAll {
+		projectedResults := make([]data.JoinedRow, len(results))
+		for i, row := range results {
+			projectedResults[i] = projection.ProjectJoinedRow(row, proj)
 		}
+		return projectedResults, nil
 	}
 
-	slog.Info("INNER JOIN completed",
-		slog.String("left_table", leftTable.Name),
-		slog.String("right_table", rightTable.Name),
-		slog.Int("result_rows", len(results)),
-		slog.Int("filtered_by_predicate", skippedByPredicate),
-	)
-
 	return results, nil
 }
 
-// executeLeftJoin performs LEFT OUTER JOIN
-func executeLeftJoin(
+// combineMatches builds the joined rows from the matching row pairs
+// Phase 1 emits the matched pairs; LEFT and FULL joins then add the unmatched
+// left rows, RIGHT and FULL joins the unmatched right rows, padded with NULLs.
+func combineMatches(
 	leftTable *schema.Table,
 	rightTable *schema.Table,
-	leftColumn string,
-	rightColumn string,
+	matches []rowPair,
+	joinType JoinType,
 	pred JoinPredicate,
-) ([]data.JoinedRow, error) {
-	leftTable.RLock()
-	defer leftTable.RUnlock()
-	rightTable.RLock()
-	defer rightTable.RUnlock()
-
-	slog.Debug("Starting LEFT JOIN",
-		slog.String("left_table", leftTable.Name),
-		slog.String("right_table", rightTable.Name),
-	)
-
-	hashIndex, _ := buildJoinIndex(rightTable, rightColumn)
-	results := make([]data.JoinedRow, 0)
+) []data.JoinedRow {
+	results := make([]data.JoinedRow, 0, len(matches))
 	matchedLeftRows := make(map[int]bool)
-
-	// Phase 1: INNER JOIN
-	for leftPos, leftRow := range leftTable.Rows {
-		leftValue, exists := leftRow.Data[leftColumn]
-		if !exists {
-			continue
-		}
-
-		rightPositions, found := hashIndex[leftValue]
-		if found {
-			matchedLeftRows[leftPos] = true
-			for _, rightPos := range rightPositions {
-				rightRow := rightTable.Rows[rightPos]
-				joined := combineRows(leftRow, rightRow, leftTable.Name, rightTable.Name)
-
-				if pred == nil || pred(joined) {
-					results = append(results, joined)
-				}
-			}
-		}
-	}
-
-	// Phase 2: Add unmatched left rows
-	for leftPos, leftRow := range leftTable.Rows {
-		if !matchedLeftRows[leftPos] {
-			joined := combineRowsWithNull(leftRow, data.Row{}, leftTable, rightTable)
-			if pred == nil || pred(joined) {
-				results = append(results, joined)
-			}
-		}
-	}
-
-	slog.Info("LEFT JOIN completed",
-		slog.Int("result_rows", len(results)),
-		slog.Int("unmatched_left", len(leftTable.Rows)-len(matchedLeftRows)),
-	)
-
-	return results, nil
-}
-
-// executeRightJoin performs RIGHT OUTER JOIN
-func executeRightJoin(
-	leftTable *schema.Table,
-	rightTable *schema.Table,
-	leftColumn string,
-	rightColumn string,
-	pred JoinPredicate,
-) ([]data.JoinedRow, error) {
-	leftTable.RLock()
-	defer leftTable.RUnlock()
-	rightTable.RLock()
-	defer rightTable.RUnlock()
-
-	slog.Debug("Starting RIGHT JOIN",
-		slog.String("left_table", leftTable.Name),
-		slog.String("right_table", rightTable.Name),
-	)
-
-	hashIndex, _ := buildJoinIndex(rightTable, rightColumn)
-	results := make([]data.JoinedRow, 0)
 	matchedRightRows := make(map[int]bool)
+	skippedByPredicate := 0
 
 	// Phase 1: INNER JOIN
-	for _, leftRow := range leftTable.Rows {
-		leftValue, exists := leftRow.Data[leftColumn]
-		if !exists {
-			continue
-		}
-
-		rightPositions, found := hashIndex[leftValue]
-		if found {
-			for _, rightPos := range rightPositions {
-				matchedRightRows[rightPos] = true
-				rightRow := rightTable.Rows[rightPos]
-				joined := combineRows(leftRow, rightRow, leftTable.Name, rightTable.Name)
-
-				if pred == nil || pred(joined) {
-					results = append(results, joined)
-				}
-			}
-		}
-	}
-
-	// Phase 2: Add unmatched right rows
-	for rightPos, rightRow := range rightTable.Rows {
-		if !matchedRightRows[rightPos] {
-			joined := combineRowsWithNull(data.Row{}, rightRow, leftTable, rightTable)
-			if pred == nil || pred(joined) {
-				results = append(results, joined)
-			}
-		}
-	}
-
-	slog.Info("RIGHT JOIN completed",
-		slog.Int("result_rows", len(results)),
-		slog.Int("unmatched_right", len(rightTable.Rows)-len(matchedRightRows)),
-	)
-
-	return results, nil
-}
+	for _, m := range matches {
+		matchedLeftRows[m.left] = true
+		matchedRightRows[m.right] = true
 
-// executeFullJoin performs FULL OUTER JOIN
-func executeFullJoin(
-	leftTable *schema.Table,
-	rightTable *schema.Table,
-	leftColumn string,
-	rightColumn string,
-	pred JoinPredicate,
-) ([]data.JoinedRow, error) {
-	leftTable.RLock()
-	defer leftTable.RUnlock()
-	rightTable.RLock()
-	defer rightTable.RUnlock()
-
-	slog.Debug("Starting FULL OUTER JOIN",
-		slog.String("left_table", leftTable.Name),
-		slog.String("right_table", rightTable.Name),
-	)
-
-	hashIndex, _ := buildJoinIndex(rightTable, rightColumn)
-	results := make([]data.JoinedRow, 0)
-	matchedLeftRows := make(map[int]bool)
-	matchedRightRows := make(map[int]bool)
-
-	// Phase 1: INNER JOIN
-	for leftPos, leftRow := range leftTable.Rows {
-		leftValue, exists := leftRow.Data[leftColumn]
-		if !exists {
+		joined := combineRows(leftTable.Rows[m.left], rightTable.Rows[m.right], leftTable.Name, rightTable.Name)
+		if pred != nil && !pred(joined) {
+			skippedByPredicate++
 			continue
 		}
-
-		rightPositions, found := hashIndex[leftValue]
-		if found {
-			matchedLeftRows[leftPos] = true
-			for _, rightPos := range rightPositions {
-				matchedRightRows[rightPos] = true
-				rightRow := rightTable.Rows[rightPos]
-				joined := combineRows(leftRow, rightRow, leftTable.Name, rightTable.Name)
-
-				if pred == nil || pred(joined) {
-					results = append(results, joined)
-				}
-			}
-		}
+		results = append(results, joined)
 	}
 
 	// Phase 2: Add unmatched left rows
-	for leftPos, leftRow := range leftTable.Rows {
-		if !matchedLeftRows[leftPos] {
+	if joinType == JoinTypeLeft || joinType == JoinTypeFull {
+		for leftPos, leftRow := range leftTable.Rows {
+			if matchedLeftRows[leftPos] {
+				continue
+			}
 			joined := combineRowsWithNull(leftRow, data.Row{}, leftTable, rightTable)
 			if pred == nil || pred(joined) {
 				results = append(results, joined)
@@ -310,8 +142,11 @@ func executeFullJoin(
 	}
 
 	// Phase 3: Add unmatched right rows
-	for rightPos, rightRow := range rightTable.Rows {
-		if !matchedRightRows[rightPos] {
+	if joinType == JoinTypeRight || joinType == JoinTypeFull {
+		for rightPos, rightRow := range rightTable.Rows {
+			if matchedRightRows[rightPos] {
+				continue
+			}
 			joined := combineRowsWithNull(data.Row{}, rightRow, leftTable, rightTable)
 			if pred == nil || pred(joined) {
 				results = append(results, joined)
@@ -319,11 +154,14 @@ func executeFullJoin(
 		}
 	}
 
-	slog.Info("FULL OUTER JOIN completed",
+	slog.Info(joinType.String()+" completed",
+		slog.String("left_table", leftTable.Name),
+		slog.String("right_table", rightTable.Name),
 		slog.Int("result_rows", len(results)),
+		slog.Int("filtered_by_predicate", skippedByPredicate),
 		slog.Int("unmatched_left", len(leftTable.Rows)-len(matchedLeftRows)),
 		slog.Int("unmatched_right", len(rightTable.Rows)-len(matchedRightRows)),
 	)
 
-	return results, nil
+	return results
 }
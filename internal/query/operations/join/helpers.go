@@ -97,27 +97,6 @@ func resolveJoinColumn(table *schema.Table, colName string) string {
 	return colName
 }
 
-// buildJoinIndex creates a hash index for the join column
-// Returns the index and a boolean indicating if an existing index was reused
-func buildJoinIndex(table *schema.Table, columnName string) (map[interface{}][]int, bool) {
-	// Try to reuse existing index
-	if idx, exists := table.Indexes[columnName]; exists {
-		return idx.Data, true
-	}
-
-	// Build temporary index
-	hashIndex := make(map[interface{}][]int)
-	for i, row := range table.Rows {
-		value, exists := row.Data[columnName]
-		if !exists {
-			continue // Skip NULL values
-		}
-		hashIndex[value] = append(hashIndex[value], i)
-	}
-
-	return hashIndex, false
-}
-
 // combineRows merges two rows with table-qualified column names
 func combineRows(
 	leftRow data.Row,
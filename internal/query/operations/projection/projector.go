@@ -64,6 +64,10 @@ func ProjectJoinedRow(row data.JoinedRow, proj *Projection) data.JoinedRow {
 		}
 
 		value, exists := row.Get(qualifiedName)
+		if !exists && colRef.Table != "" {
+			// A qualified column of a single-table row is stored under its bare name
+			value, exists = row.Get(colRef.Column)
+		}
 		if !exists && colRef.Table == "" {
 			// Try to find the column in any table
 			// This is a fallback for unqualified column names
@@ -44,6 +44,7 @@ func CreateUsersTable() *schema.Table {
 		},
 		Indexes: make(map[string]*data.Index),
 	}
+	table.InitRowIDs()
 	indexing.BuildIndexes(table)
 	return table
 }
@@ -69,6 +70,7 @@ func CreateOrdersTable() *schema.Table {
 		},
 		Indexes: make(map[string]*data.Index),
 	}
+	table.InitRowIDs()
 	indexing.BuildIndexes(table)
 	return table
 }
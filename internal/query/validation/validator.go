@@ -21,8 +21,8 @@ func ValidateRow(table *schema.Table, row data.Row, rowIndex int) error {
 	for _, col := range table.Schema.Columns {
 		val, exists := row.Data[col.Name]
 
-		// Handle missing value
-		if !exists {
+		// Handle missing or NULL value
+		if !exists || val == nil {
 			if col.NotNull {
 				return &errors.ConstraintError{
 					Table:      table.Name,
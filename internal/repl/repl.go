@@ -2,6 +2,7 @@ package repl
 
 import (
 	"bufio"
+	"context"
 	"fmt"
 	"io"
 	"os"
@@ -13,13 +14,17 @@ import (
 	"github.com/leengari/mini-rdbms/internal/storage/manager"
 )
 
-func Start(registry *manager.Registry) {
-	scanner := bufio.NewScanner(os.Stdin)
+// Start runs the interactive shell until the user exits, stdin ends or ctx is cancelled
+// A statement already running when ctx is cancelled finishes first; an open
+// transaction is rolled back on return.
+func Start(ctx context.Context, registry *manager.Registry) {
+	lines := readLines(os.Stdin)
 	fmt.Println("Welcome to JoyDB")
 	fmt.Println("Type 'exit' or '\\q' to quit.")
 
 	// Start with no database selected
 	eng := engine.New(nil, registry)
+	defer eng.Close() // roll back any transaction left open
 	
 	// Register logging observer for lifecycle tracing
 	loggingObserver := engine.NewLoggingObserver()
@@ -27,10 +32,17 @@ func Start(registry *manager.Registry) {
 
 	for {
 		fmt.Print("> ")
-		if !scanner.Scan() {
+		var line string
+		select {
+		case <-ctx.Done():
+			fmt.Println()
 			return
+		case l, ok := <-lines:
+			if !ok {
+				return
+			}
+			line = l
 		}
-		line := scanner.Text()
 
 		if strings.TrimSpace(line) == "" {
 			continue
@@ -82,6 +94,19 @@ func Start(registry *manager.Registry) {
 	}
 }
 
+// readLines sends each line read from r on the returned channel, closing it at the end of input
+func readLines(r io.Reader) <-chan string {
+	lines := make(chan string)
+	go func() {
+		defer close(lines)
+		scanner := bufio.NewScanner(r)
+		for scanner.Scan() {
+			lines <- scanner.Text()
+		}
+	}()
+	return lines
+}
+
 func PrintResult(w io.Writer, res *executor.Result) {
 	if res.Error != "" {
 		fmt.Fprintf(w, "Error: %s\n", res.Error)
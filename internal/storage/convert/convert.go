@@ -0,0 +1,165 @@
+package convert
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/leengari/mini-rdbms/internal/domain/schema"
+	"github.com/leengari/mini-rdbms/internal/storage/engine"
+	"github.com/leengari/mini-rdbms/internal/storage/loader"
+	"github.com/leengari/mini-rdbms/internal/storage/wal"
+)
+
+// Database rewrites a database in another storage format
+//
+// The database is loaded with the engine for its current format (read from its
+// meta.json) and its write-ahead log is replayed. Its tables are then written with
+// the target engine into a new directory, which replaces the original once it loads
+// back with the same tables and row counts. The database must not be in use.
+func Database(basePath, name, storage string) error {
+	dbPath := filepath.Join(basePath, name)
+	meta, err := loader.ReadDatabaseMeta(dbPath)
+	if err != nil {
+		return err
+	}
+	from := meta.StorageFormat()
+	if from == storage {
+		return fmt.Errorf("database '%s' already uses %s storage", name, storage)
+	}
+
+	source, err := engine.New(from)
+	if err != nil {
+		return err
+	}
+	target, err := engine.New(storage)
+	if err != nil {
+		return err
+	}
+	defer closeEngine(source)
+	defer closeEngine(target)
+
+	// 1. Load the database with every committed change
+	db, err := source.LoadDatabase(dbPath)
+	if err != nil {
+		return err
+	}
+	log, err := wal.Recover(db)
+	if err != nil {
+		return fmt.Errorf("failed to recover database '%s': %w", name, err)
+	}
+	if err := log.Close(); err != nil {
+		return fmt.Errorf("failed to close wal: %w", err)
+	}
+
+	// 2. Write a copy in the target format next to it
+	tmpName := name + ".converting"
+	tmpPath := filepath.Join(basePath, tmpName)
+	if err := os.RemoveAll(tmpPath); err != nil {
+		return fmt.Errorf("failed to remove leftover conversion: %w", err)
+	}
+	if err := copyDatabase(db, target, tmpName, basePath); err != nil {
+		os.RemoveAll(tmpPath)
+		return err
+	}
+
+	// 3. Check the copy reads back the same
+	if err := verify(db, target, tmpPath); err != nil {
+		os.RemoveAll(tmpPath)
+		return fmt.Errorf("converted database '%s' failed verification: %w", name, err)
+	}
+	if err := closeEngine(target); err != nil {
+		os.RemoveAll(tmpPath)
+		return err
+	}
+
+	// 4. Swap the copy in, keeping the original until it is in place
+	oldName := name + ".old"
+	oldPath := filepath.Join(basePath, oldName)
+	if err := os.RemoveAll(oldPath); err != nil {
+		return fmt.Errorf("failed to remove leftover backup: %w", err)
+	}
+	if err := os.Rename(dbPath, oldPath); err != nil {
+		os.RemoveAll(tmpPath)
+		return fmt.Errorf("failed to move database '%s' aside: %w", name, err)
+	}
+	if err := target.RenameDatabase(tmpName, name, basePath); err != nil {
+		if restoreErr := os.Rename(oldPath, dbPath); restoreErr != nil {
+			return fmt.Errorf("failed to move converted database into place: %w (original left at %s: %v)", err, oldPath, restoreErr)
+		}
+		os.RemoveAll(tmpPath)
+		return fmt.Errorf("failed to move converted database into place: %w", err)
+	}
+	if err := os.RemoveAll(oldPath); err != nil {
+		slog.Warn("failed to remove original database after conversion", "path", oldPath, "error", err)
+	}
+
+	slog.Info("database converted",
+		slog.String("name", name),
+		slog.String("from", from),
+		slog.String("to", storage),
+		slog.Int("table_count", len(db.Tables)))
+	return nil
+}
+
+// copyDatabase creates a database with the given name holding db's tables
+func copyDatabase(db *schema.Database, target engine.StorageEngine, name, basePath string) error {
+	if err := target.CreateDatabase(name, basePath); err != nil {
+		return err
+	}
+
+	copied := &schema.Database{
+		Name:   name,
+		Path:   filepath.Join(basePath, name),
+		Tables: make(map[string]*schema.Table),
+	}
+	for _, tableName := range tableNames(db) {
+		// CreateTable points the table at its new directory and writes its rows
+		if err := target.CreateTable(copied, db.Tables[tableName]); err != nil {
+			return fmt.Errorf("failed to convert table %s: %w", tableName, err)
+		}
+	}
+	return nil
+}
+
+// verify loads the converted database and compares it with the original
+func verify(db *schema.Database, target engine.StorageEngine, path string) error {
+	converted, err := target.LoadDatabase(path)
+	if err != nil {
+		return err
+	}
+	if len(converted.Tables) != len(db.Tables) {
+		return fmt.Errorf("expected %d tables, found %d", len(db.Tables), len(converted.Tables))
+	}
+	for _, tableName := range tableNames(db) {
+		table, ok := converted.Tables[tableName]
+		if !ok {
+			return fmt.Errorf("table %s is missing", tableName)
+		}
+		if want, got := len(db.Tables[tableName].Rows), len(table.Rows); want != got {
+			return fmt.Errorf("table %s has %d rows, expected %d", tableName, got, want)
+		}
+	}
+	return nil
+}
+
+// tableNames returns the names of a database's tables in sorted order
+func tableNames(db *schema.Database) []string {
+	names := make([]string, 0, len(db.Tables))
+	for name := range db.Tables {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// closeEngine releases the files an engine holds open, if it holds any
+func closeEngine(e engine.StorageEngine) error {
+	if closer, ok := e.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
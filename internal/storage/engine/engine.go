@@ -1,8 +1,12 @@
 package engine
 
 import (
+	"fmt"
+
 	"github.com/leengari/mini-rdbms/internal/domain/schema"
 	"github.com/leengari/mini-rdbms/internal/domain/transaction"
+	"github.com/leengari/mini-rdbms/internal/storage/metadata"
+	"github.com/leengari/mini-rdbms/internal/storage/page"
 )
 
 // StorageEngine defines the interface for all storage backends
@@ -35,4 +39,26 @@ type StorageEngine interface {
 
 	// SaveTable persists a single table to disk
 	SaveTable(table *schema.Table, tx *transaction.Transaction) error
+
+	// CreateTable creates storage for a new table, sets its Path and
+	// registers it in db.Tables
+	CreateTable(db *schema.Database, table *schema.Table) error
+
+	// DropTable removes a table's storage and unregisters it from db.Tables
+	DropTable(db *schema.Database, tableName string) error
+
+	// RenameTable moves a table's storage to a new name and re-registers it in db.Tables
+	RenameTable(db *schema.Database, oldName, newName string) error
+}
+
+// New creates the storage engine for a storage format ("json" or "page")
+func New(storage string) (StorageEngine, error) {
+	switch storage {
+	case metadata.StorageJSON:
+		return NewJSONEngine(), nil
+	case metadata.StoragePage:
+		return NewPageEngine(page.DefaultPoolPages), nil
+	default:
+		return nil, fmt.Errorf("unknown storage format %q (want %s or %s)", storage, metadata.StorageJSON, metadata.StoragePage)
+	}
 }
@@ -1,7 +1,6 @@
 package engine
 
 import (
-	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -33,129 +32,118 @@ func (e *JSONEngine) SaveDatabase(db *schema.Database, tx *transaction.Transacti
 
 // CreateDatabase creates a new database directory with JSON metadata
 func (e *JSONEngine) CreateDatabase(name, basePath string) error {
-	dbPath := filepath.Join(basePath, name)
+	return createDatabase(name, basePath, metadata.StorageJSON)
+}
+
+// DropDatabase removes a database directory
+func (e *JSONEngine) DropDatabase(name, basePath string) error {
+	return dropDatabase(name, basePath)
+}
+
+// RenameDatabase renames a database directory and updates JSON metadata
+func (e *JSONEngine) RenameDatabase(oldName, newName, basePath string) error {
+	return renameDatabase(oldName, newName, basePath)
+}
+
+// ListDatabases returns all available databases
+func (e *JSONEngine) ListDatabases(basePath string) ([]string, error) {
+	return listDatabases(basePath)
+}
+
+// LoadTable loads a single table from JSON files
+func (e *JSONEngine) LoadTable(tablePath string) (*schema.Table, error) {
+	return loader.LoadTable(tablePath)
+}
+
+// SaveTable saves a single table to JSON files
+func (e *JSONEngine) SaveTable(table *schema.Table, tx *transaction.Transaction) error {
+	return writer.SaveTable(table, tx)
+}
+
+// CreateTable creates a table directory with JSON metadata and an empty data file
+func (e *JSONEngine) CreateTable(db *schema.Database, table *schema.Table) error {
+	tablePath := filepath.Join(db.Path, table.Name)
 
 	// Check if exists
-	if _, err := os.Stat(dbPath); !os.IsNotExist(err) {
-		return fmt.Errorf("database '%s' already exists", name)
+	if _, err := os.Stat(tablePath); !os.IsNotExist(err) {
+		return fmt.Errorf("table '%s' already exists on disk", table.Name)
 	}
 
 	// Create directory
-	if err := os.MkdirAll(dbPath, 0755); err != nil {
-		return fmt.Errorf("failed to create database directory: %w", err)
+	if err := os.MkdirAll(tablePath, 0755); err != nil {
+		return fmt.Errorf("failed to create table directory: %w", err)
 	}
 
-	// Create meta.json
-	meta := metadata.DatabaseMeta{
-		Name:    name,
-		Version: 1,
-		Tables:  []string{},
+	// Write meta.json and data.json
+	table.Path = tablePath
+	if err := writer.SaveTable(table, nil); err != nil {
+		os.RemoveAll(tablePath)
+		return err
 	}
 
-	data, err := json.MarshalIndent(meta, "", "  ")
-	if err != nil {
-		return fmt.Errorf("failed to marshal metadata: %w", err)
-	}
-
-	metaPath := filepath.Join(dbPath, "meta.json")
-	if err := os.WriteFile(metaPath, data, 0644); err != nil {
-		return fmt.Errorf("failed to write meta.json: %w", err)
+	// Register the table in the database meta.json
+	db.Tables[table.Name] = table
+	if err := writer.SaveDatabaseMeta(db); err != nil {
+		delete(db.Tables, table.Name)
+		os.RemoveAll(tablePath)
+		return err
 	}
 
 	return nil
 }
 
-// DropDatabase removes a database directory
-func (e *JSONEngine) DropDatabase(name, basePath string) error {
-	dbPath := filepath.Join(basePath, name)
+// DropTable removes a table directory and updates the database metadata
+func (e *JSONEngine) DropTable(db *schema.Database, tableName string) error {
+	tablePath := filepath.Join(db.Path, tableName)
 
 	// Check if exists
-	if _, err := os.Stat(dbPath); os.IsNotExist(err) {
-		return fmt.Errorf("database '%s' does not exist", name)
+	if _, err := os.Stat(tablePath); os.IsNotExist(err) {
+		return fmt.Errorf("table '%s' does not exist on disk", tableName)
 	}
 
 	// Remove directory
-	if err := os.RemoveAll(dbPath); err != nil {
-		return fmt.Errorf("failed to remove database directory: %w", err)
+	if err := os.RemoveAll(tablePath); err != nil {
+		return fmt.Errorf("failed to remove table directory: %w", err)
 	}
 
-	return nil
+	// Unregister the table from the database meta.json
+	delete(db.Tables, tableName)
+	return writer.SaveDatabaseMeta(db)
 }
 
-// RenameDatabase renames a database directory and updates JSON metadata
-func (e *JSONEngine) RenameDatabase(oldName, newName, basePath string) error {
-	oldPath := filepath.Join(basePath, oldName)
-	newPath := filepath.Join(basePath, newName)
-
-	// Check if old exists
-	if _, err := os.Stat(oldPath); os.IsNotExist(err) {
-		return fmt.Errorf("database '%s' does not exist", oldName)
+// RenameTable renames a table directory and rewrites the table and database metadata
+func (e *JSONEngine) RenameTable(db *schema.Database, oldName, newName string) error {
+	table, exists := db.Tables[oldName]
+	if !exists {
+		return fmt.Errorf("table '%s' does not exist", oldName)
 	}
 
-	// Check if new exists
+	oldPath := filepath.Join(db.Path, oldName)
+	newPath := filepath.Join(db.Path, newName)
+
+	// Check if target exists
 	if _, err := os.Stat(newPath); !os.IsNotExist(err) {
-		return fmt.Errorf("database '%s' already exists", newName)
+		return fmt.Errorf("table '%s' already exists on disk", newName)
 	}
 
 	// Rename directory
 	if err := os.Rename(oldPath, newPath); err != nil {
-		return fmt.Errorf("failed to rename database directory: %w", err)
-	}
-
-	// Update meta.json
-	metaPath := filepath.Join(newPath, "meta.json")
-	data, err := os.ReadFile(metaPath)
-	if err != nil {
-		return fmt.Errorf("failed to read meta.json: %w", err)
+		return fmt.Errorf("failed to rename table directory: %w", err)
 	}
 
-	var meta metadata.DatabaseMeta
-	if err := json.Unmarshal(data, &meta); err != nil {
-		return fmt.Errorf("failed to parse meta.json: %w", err)
-	}
-
-	meta.Name = newName
-	newData, err := json.MarshalIndent(meta, "", "  ")
-	if err != nil {
-		return fmt.Errorf("failed to marshal metadata: %w", err)
-	}
-
-	if err := os.WriteFile(metaPath, newData, 0644); err != nil {
-		return fmt.Errorf("failed to write meta.json: %w", err)
-	}
-
-	return nil
-}
+	table.Lock()
+	table.Name = newName
+	table.Path = newPath
+	table.Schema.TableName = newName
+	table.Unlock()
 
-// ListDatabases returns all available databases
-func (e *JSONEngine) ListDatabases(basePath string) ([]string, error) {
-	entries, err := os.ReadDir(basePath)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read databases directory: %w", err)
-	}
+	delete(db.Tables, oldName)
+	db.Tables[newName] = table
 
-	var databases []string
-	for _, entry := range entries {
-		if !entry.IsDir() {
-			continue
-		}
-
-		// Check if it's a valid database (has meta.json)
-		metaPath := filepath.Join(basePath, entry.Name(), "meta.json")
-		if _, err := os.Stat(metaPath); err == nil {
-			databases = append(databases, entry.Name())
-		}
+	// Rewrite meta.json with the new table name
+	if err := writer.SaveTable(table, nil); err != nil {
+		return err
 	}
 
-	return databases, nil
-}
-
-// LoadTable loads a single table from JSON files
-func (e *JSONEngine) LoadTable(tablePath string) (*schema.Table, error) {
-	return loader.LoadTable(tablePath)
-}
-
-// SaveTable saves a single table to JSON files
-func (e *JSONEngine) SaveTable(table *schema.Table, tx *transaction.Transaction) error {
-	return writer.SaveTable(table, tx)
+	return writer.SaveDatabaseMeta(db)
 }
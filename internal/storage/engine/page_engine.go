@@ -0,0 +1,445 @@
+package engine
+
+import (
+	"encoding/binary"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/leengari/mini-rdbms/internal/domain/data"
+	"github.com/leengari/mini-rdbms/internal/domain/schema"
+	"github.com/leengari/mini-rdbms/internal/domain/transaction"
+	"github.com/leengari/mini-rdbms/internal/storage/loader"
+	"github.com/leengari/mini-rdbms/internal/storage/metadata"
+	"github.com/leengari/mini-rdbms/internal/storage/page"
+	"github.com/leengari/mini-rdbms/internal/storage/writer"
+)
+
+// pagesFile is the name of a table's data file under page storage
+const pagesFile = "data.pages"
+
+// PageEngine implements StorageEngine using binary files of slotted pages
+//
+// Each table directory holds the same meta.json as under JSON storage and a data.pages
+// heap file, read and written through a buffer pool shared by all tables. Every row is
+// one record: the row id followed by the row's binary encoding. Rows are loaded in row
+// id order, which is the table's row order.
+//
+// Saving a table writes only what changed: rows whose stored record has the same
+// encoding keep it, records of deleted or changed rows are deleted, and new and
+// changed rows are inserted. Stored records are compared by reading them back through
+// the buffer pool, so only their locations stay in memory. The page changes and the
+// new meta.json are committed together through the heap's journal.
+type PageEngine struct {
+	mu     sync.Mutex
+	pool   *page.BufferPool
+	tables map[string]*pageTable // Open tables by directory path
+}
+
+// pageTable is the open heap of a table and where its records are
+type pageTable struct {
+	mu   sync.Mutex
+	heap *page.Heap
+	rids map[int64]page.RID // Records by row id
+}
+
+// pageRow is a row id and the row's encoding
+type pageRow struct {
+	id      int64
+	encoded string
+}
+
+// NewPageEngine creates a page storage engine whose buffer pool caches up to
+// poolPages pages
+func NewPageEngine(poolPages int) *PageEngine {
+	return &PageEngine{
+		pool:   page.NewBufferPool(poolPages),
+		tables: make(map[string]*pageTable),
+	}
+}
+
+// LoadDatabase loads a database kept in page storage
+func (e *PageEngine) LoadDatabase(dbPath string) (*schema.Database, error) {
+	return loader.LoadDatabaseWith(dbPath, metadata.StoragePage, e.LoadTable)
+}
+
+// SaveDatabase saves every table, then the database metadata
+func (e *PageEngine) SaveDatabase(db *schema.Database, tx *transaction.Transaction) error {
+	if db == nil {
+		return fmt.Errorf("cannot save nil database")
+	}
+
+	for name, table := range db.Tables {
+		if err := e.SaveTable(table, tx); err != nil {
+			return fmt.Errorf("failed to save table %s: %w", name, err)
+		}
+	}
+	return writer.SaveDatabaseMetaAs(db, metadata.StoragePage)
+}
+
+// CreateDatabase creates a new database directory marked as page storage
+func (e *PageEngine) CreateDatabase(name, basePath string) error {
+	return createDatabase(name, basePath, metadata.StoragePage)
+}
+
+// DropDatabase closes the database's tables and removes its directory
+func (e *PageEngine) DropDatabase(name, basePath string) error {
+	e.closeUnder(filepath.Join(basePath, name))
+	return dropDatabase(name, basePath)
+}
+
+// RenameDatabase closes the database's tables and renames its directory
+func (e *PageEngine) RenameDatabase(oldName, newName, basePath string) error {
+	e.closeUnder(filepath.Join(basePath, oldName))
+	return renameDatabase(oldName, newName, basePath)
+}
+
+// ListDatabases returns all available databases
+func (e *PageEngine) ListDatabases(basePath string) ([]string, error) {
+	return listDatabases(basePath)
+}
+
+// LoadTable opens a table's heap, finishing any interrupted commit, and reads its
+// rows in row id order
+func (e *PageEngine) LoadTable(tablePath string) (*schema.Table, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.closeUnsafe(tablePath)
+	state, records, err := openPageTable(tablePath, e.pool)
+	if err != nil {
+		return nil, err
+	}
+
+	// The heap's journal may have just replaced meta.json, so it is read after opening
+	meta, err := loader.ReadTableMeta(tablePath)
+	if err != nil {
+		state.heap.Close()
+		return nil, err
+	}
+	columns := loadedColumns(meta)
+
+	rows := make([]data.Row, len(records))
+	for i, record := range records {
+		if rows[i], err = page.DecodeRow([]byte(record.encoded), columns); err != nil {
+			state.heap.Close()
+			return nil, fmt.Errorf("failed to decode row %d of table %s: %w", record.id, meta.Name, err)
+		}
+		rows[i].ID = record.id
+	}
+
+	table, err := loader.NewTable(tablePath, meta, rows)
+	if err != nil {
+		state.heap.Close()
+		return nil, err
+	}
+	e.tables[tablePath] = state
+	return table, nil
+}
+
+// SaveTable writes the changes to a table's rows and its metadata
+func (e *PageEngine) SaveTable(table *schema.Table, tx *transaction.Transaction) error {
+	if table == nil || table.Path == "" {
+		return fmt.Errorf("cannot save table: nil or missing path")
+	}
+
+	table.RLock()
+	defer table.RUnlock()
+
+	if tx != nil {
+		slog.Debug("SaveTable operation", "table", table.Name, "tx_id", tx.ID)
+	}
+
+	rows := make([]pageRow, len(table.Rows))
+	var buf []byte
+	for i, row := range table.Rows {
+		var err error
+		if buf, err = page.EncodeRow(buf[:0], table.Schema.Columns, row); err != nil {
+			return fmt.Errorf("failed to encode row %d of table %s: %w", row.ID, table.Name, err)
+		}
+		rows[i] = pageRow{row.ID, string(buf)}
+	}
+	metaBytes, err := writer.MarshalTableMeta(table)
+	if err != nil {
+		return err
+	}
+
+	state, err := e.open(table.Path)
+	if err != nil {
+		return err
+	}
+	state.mu.Lock()
+	inserted, deleted, err := state.save(rows, metaBytes)
+	pages := state.heap.Pages()
+	state.mu.Unlock()
+	if err != nil {
+		// The heap may hold half-applied changes; reopen it from disk next time
+		e.close(table.Path)
+		return fmt.Errorf("failed to save table %s: %w", table.Name, err)
+	}
+
+	slog.Info("Table saved successfully",
+		slog.String("table", table.Name),
+		slog.String("path", table.Path),
+		slog.Int("row_count", len(table.Rows)),
+		slog.Int("inserted", inserted),
+		slog.Int("deleted", deleted),
+		slog.Int("pages", int(pages)),
+	)
+	return nil
+}
+
+// CreateTable creates a table directory with its metadata and an empty heap
+func (e *PageEngine) CreateTable(db *schema.Database, table *schema.Table) error {
+	tablePath := filepath.Join(db.Path, table.Name)
+
+	// Check if exists
+	if _, err := os.Stat(tablePath); !os.IsNotExist(err) {
+		return fmt.Errorf("table '%s' already exists on disk", table.Name)
+	}
+
+	// Create directory
+	if err := os.MkdirAll(tablePath, 0755); err != nil {
+		return fmt.Errorf("failed to create table directory: %w", err)
+	}
+
+	// Write meta.json and data.pages
+	table.Path = tablePath
+	if err := e.SaveTable(table, nil); err != nil {
+		e.close(tablePath)
+		os.RemoveAll(tablePath)
+		return err
+	}
+
+	// Register the table in the database meta.json
+	db.Tables[table.Name] = table
+	if err := writer.SaveDatabaseMetaAs(db, metadata.StoragePage); err != nil {
+		delete(db.Tables, table.Name)
+		e.close(tablePath)
+		os.RemoveAll(tablePath)
+		return err
+	}
+
+	return nil
+}
+
+// DropTable closes a table's heap, removes its directory and updates the database metadata
+func (e *PageEngine) DropTable(db *schema.Database, tableName string) error {
+	tablePath := filepath.Join(db.Path, tableName)
+
+	// Check if exists
+	if _, err := os.Stat(tablePath); os.IsNotExist(err) {
+		return fmt.Errorf("table '%s' does not exist on disk", tableName)
+	}
+
+	e.close(tablePath)
+	if err := os.RemoveAll(tablePath); err != nil {
+		return fmt.Errorf("failed to remove table directory: %w", err)
+	}
+
+	// Unregister the table from the database meta.json
+	delete(db.Tables, tableName)
+	return writer.SaveDatabaseMetaAs(db, metadata.StoragePage)
+}
+
+// RenameTable renames a table directory and rewrites the table and database metadata
+func (e *PageEngine) RenameTable(db *schema.Database, oldName, newName string) error {
+	table, exists := db.Tables[oldName]
+	if !exists {
+		return fmt.Errorf("table '%s' does not exist", oldName)
+	}
+
+	oldPath := filepath.Join(db.Path, oldName)
+	newPath := filepath.Join(db.Path, newName)
+
+	// Check if target exists
+	if _, err := os.Stat(newPath); !os.IsNotExist(err) {
+		return fmt.Errorf("table '%s' already exists on disk", newName)
+	}
+
+	// The heap is reopened under the new path by the save below
+	e.close(oldPath)
+	if err := os.Rename(oldPath, newPath); err != nil {
+		return fmt.Errorf("failed to rename table directory: %w", err)
+	}
+
+	table.Lock()
+	table.Name = newName
+	table.Path = newPath
+	table.Schema.TableName = newName
+	table.Unlock()
+
+	delete(db.Tables, oldName)
+	db.Tables[newName] = table
+
+	// Rewrite meta.json with the new table name
+	if err := e.SaveTable(table, nil); err != nil {
+		return err
+	}
+
+	return writer.SaveDatabaseMetaAs(db, metadata.StoragePage)
+}
+
+// Close closes every open heap
+// Changes not yet saved are lost; tables are reopened when next loaded or saved.
+func (e *PageEngine) Close() error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	var firstErr error
+	for path, state := range e.tables {
+		if err := state.heap.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+		delete(e.tables, path)
+	}
+	return firstErr
+}
+
+// open returns a table's open heap, opening it if needed
+func (e *PageEngine) open(tablePath string) (*pageTable, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if state, ok := e.tables[tablePath]; ok {
+		return state, nil
+	}
+	state, _, err := openPageTable(tablePath, e.pool)
+	if err != nil {
+		return nil, err
+	}
+	e.tables[tablePath] = state
+	return state, nil
+}
+
+// close closes a table's heap, if it is open
+func (e *PageEngine) close(tablePath string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.closeUnsafe(tablePath)
+}
+
+// closeUnder closes the heaps of every table in a database directory
+func (e *PageEngine) closeUnder(dbPath string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	prefix := dbPath + string(filepath.Separator)
+	for path := range e.tables {
+		if strings.HasPrefix(path, prefix) {
+			e.closeUnsafe(path)
+		}
+	}
+}
+
+// closeUnsafe closes a table's heap
+// Must be called while holding e.mu
+func (e *PageEngine) closeUnsafe(tablePath string) {
+	state, ok := e.tables[tablePath]
+	if !ok {
+		return
+	}
+	if err := state.heap.Close(); err != nil {
+		slog.Warn("failed to close table heap", "path", tablePath, "error", err)
+	}
+	delete(e.tables, tablePath)
+}
+
+// openPageTable opens a table's heap and reads every record
+// Returns the stored rows in row id order.
+func openPageTable(tablePath string, pool *page.BufferPool) (*pageTable, []pageRow, error) {
+	heap, err := page.OpenHeap(filepath.Join(tablePath, pagesFile), pool)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	state := &pageTable{heap: heap, rids: make(map[int64]page.RID)}
+	var rows []pageRow
+	err = heap.Scan(func(rid page.RID, b []byte) error {
+		if len(b) < 8 {
+			return fmt.Errorf("record %d:%d is truncated", rid.Page, rid.Slot)
+		}
+		row := pageRow{int64(binary.LittleEndian.Uint64(b)), string(b[8:])}
+		if _, exists := state.rids[row.id]; exists {
+			return fmt.Errorf("record %d:%d repeats row id %d", rid.Page, rid.Slot, row.id)
+		}
+		state.rids[row.id] = rid
+		rows = append(rows, row)
+		return nil
+	})
+	if err != nil {
+		heap.Close()
+		return nil, nil, fmt.Errorf("failed to read %s: %w", filepath.Join(tablePath, pagesFile), err)
+	}
+	sort.Slice(rows, func(a, b int) bool { return rows[a].id < rows[b].id })
+	return state, rows, nil
+}
+
+// save brings the heap in line with the given rows and commits it along with the
+// table's meta.json
+// Returns the number of records inserted and deleted.
+func (t *pageTable) save(rows []pageRow, meta []byte) (int, int, error) {
+	// 1. Keep the records of rows stored with the same encoding
+	next := make(map[int64]page.RID, len(rows))
+	for _, row := range rows {
+		rid, ok := t.rids[row.id]
+		if !ok {
+			continue
+		}
+		record, err := t.heap.Get(rid)
+		if err != nil {
+			return 0, 0, err
+		}
+		if string(record[8:]) == row.encoded {
+			next[row.id] = rid
+		}
+	}
+
+	// 2. Delete the records of rows that are gone or changed
+	deleted := 0
+	for id, rid := range t.rids {
+		if _, kept := next[id]; kept {
+			continue
+		}
+		if err := t.heap.Delete(rid); err != nil {
+			return 0, 0, err
+		}
+		deleted++
+	}
+
+	// 3. Insert the new and changed rows
+	inserted := 0
+	var record []byte
+	for _, row := range rows {
+		if _, kept := next[row.id]; kept {
+			continue
+		}
+		record = binary.LittleEndian.AppendUint64(record[:0], uint64(row.id))
+		record = append(record, row.encoded...)
+		rid, err := t.heap.Insert(record)
+		if err != nil {
+			return 0, 0, err
+		}
+		next[row.id] = rid
+		inserted++
+	}
+	if err := t.heap.Commit(map[string][]byte{"meta.json": meta}); err != nil {
+		return 0, 0, err
+	}
+
+	t.rids = next
+	return inserted, deleted, nil
+}
+
+// loadedColumns returns the schema columns described by table metadata
+func loadedColumns(meta *metadata.TableMeta) []schema.Column {
+	columns := make([]schema.Column, len(meta.Columns))
+	for i, c := range meta.Columns {
+		columns[i] = schema.Column{Name: c.Name, Type: schema.ColumnType(c.Type)}
+	}
+	return columns
+}
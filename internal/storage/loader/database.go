@@ -13,16 +13,18 @@ import (
 
 // LoadDatabase loads the database from the given directory path
 func LoadDatabase(dbPath string) (*schema.Database, error) {
-	metaPath := filepath.Join(dbPath, "meta.json")
+	return LoadDatabaseWith(dbPath, metadata.StorageJSON, LoadTable)
+}
 
-	data, err := os.ReadFile(metaPath)
+// LoadDatabaseWith loads a database kept in the given storage format, loading
+// each table directory with loadTable
+func LoadDatabaseWith(dbPath, storage string, loadTable func(path string) (*schema.Table, error)) (*schema.Database, error) {
+	meta, err := ReadDatabaseMeta(dbPath)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read database meta: %w", err)
+		return nil, err
 	}
-
-	var meta metadata.DatabaseMeta
-	if err := json.Unmarshal(data, &meta); err != nil {
-		return nil, fmt.Errorf("failed to parse database meta: %w", err)
+	if meta.StorageFormat() != storage {
+		return nil, fmt.Errorf("database '%s' uses %s storage, not %s; convert it first", meta.Name, meta.StorageFormat(), storage)
 	}
 
 	db := &schema.Database{
@@ -45,7 +47,7 @@ func LoadDatabase(dbPath string) (*schema.Database, error) {
 		tableName := entry.Name()
 		tablePath := filepath.Join(dbPath, tableName)
 
-		table, err := LoadTable(tablePath)
+		table, err := loadTable(tablePath)
 		if err != nil {
 			return nil, fmt.Errorf("failed to load table %s: %w", tableName, err)
 		}
@@ -61,3 +63,17 @@ func LoadDatabase(dbPath string) (*schema.Database, error) {
 
 	return db, nil
 }
+
+// ReadDatabaseMeta reads the meta.json of the database at the given directory path
+func ReadDatabaseMeta(dbPath string) (*metadata.DatabaseMeta, error) {
+	data, err := os.ReadFile(filepath.Join(dbPath, "meta.json"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read database meta: %w", err)
+	}
+
+	var meta metadata.DatabaseMeta
+	if err := json.Unmarshal(data, &meta); err != nil {
+		return nil, fmt.Errorf("failed to parse database meta: %w", err)
+	}
+	return &meta, nil
+}
@@ -15,10 +15,66 @@ import (
 
 // LoadTable loads a table from the given directory path
 func LoadTable(path string) (*schema.Table, error) {
-	metaPath := filepath.Join(path, "meta.json")
+	if err := finishSave(path); err != nil {
+		return nil, err
+	}
+
+	meta, err := ReadTableMeta(path)
+	if err != nil {
+		return nil, err
+	}
+
+	rows := []data.Row{}
 	dataPath := filepath.Join(path, "data.json")
+	if _, err := os.Stat(dataPath); err == nil {
+		dataBytes, err := os.ReadFile(dataPath)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := json.Unmarshal(dataBytes, &rows); err != nil {
+			return nil, err
+		}
+		for i := range rows {
+			if err := takeRowID(&rows[i]); err != nil {
+				return nil, fmt.Errorf("row %d in %s: %w", i, dataPath, err)
+			}
+		}
+	}
+
+	return NewTable(path, meta, rows)
+}
+
+// finishSave completes or discards a writer.SaveTable cut short by a crash
+// SaveTable writes data.json.tmp and meta.json.tmp, then renames data.json into place
+// before meta.json. A leftover data.json.tmp means neither file was replaced, so the
+// temporary files are removed. A meta.json.tmp on its own means data.json is already
+// new, so the meta.json written with it is moved into place too.
+func finishSave(path string) error {
+	dataTmp := filepath.Join(path, "data.json.tmp")
+	metaTmp := filepath.Join(path, "meta.json.tmp")
+
+	if _, err := os.Stat(dataTmp); err == nil {
+		for _, tmp := range []string{dataTmp, metaTmp} {
+			if err := os.Remove(tmp); err != nil && !os.IsNotExist(err) {
+				return fmt.Errorf("failed to remove interrupted save %s: %w", tmp, err)
+			}
+		}
+		return nil
+	}
+
+	if _, err := os.Stat(metaTmp); err == nil {
+		slog.Warn("finishing interrupted table save", slog.String("path", path))
+		if err := os.Rename(metaTmp, filepath.Join(path, "meta.json")); err != nil {
+			return fmt.Errorf("failed to finish interrupted save in %s: %w", path, err)
+		}
+	}
+	return nil
+}
 
-	metaBytes, err := os.ReadFile(metaPath)
+// ReadTableMeta reads the meta.json of the table at the given directory path
+func ReadTableMeta(path string) (*metadata.TableMeta, error) {
+	metaBytes, err := os.ReadFile(filepath.Join(path, "meta.json"))
 	if err != nil {
 		return nil, err
 	}
@@ -27,7 +83,12 @@ func LoadTable(path string) (*schema.Table, error) {
 	if err := json.Unmarshal(metaBytes, &meta); err != nil {
 		return nil, err
 	}
+	return &meta, nil
+}
 
+// NewTable builds a table from its metadata and rows, validating every row
+// against the schema. Rows loaded without row ids are numbered in order.
+func NewTable(path string, meta *metadata.TableMeta, rows []data.Row) (*schema.Table, error) {
 	tableSchema := &schema.TableSchema{
 		TableName: meta.Name,
 		Columns:   make([]schema.Column, 0),
@@ -41,29 +102,29 @@ func LoadTable(path string) (*schema.Table, error) {
 			Unique:        c.Unique,
 			NotNull:       c.NotNull,
 			AutoIncrement: c.AutoIncrement,
+			Default:       decodeDefault(c.Default, schema.ColumnType(c.Type)),
 		}
 		tableSchema.Columns = append(tableSchema.Columns, col)
 	}
 
-	rows := []data.Row{}
-	if _, err := os.Stat(dataPath); err == nil {
-		dataBytes, err := os.ReadFile(dataPath)
-		if err != nil {
-			return nil, err
-		}
-
-		if err := json.Unmarshal(dataBytes, &rows); err != nil {
-			return nil, err
-		}
+	table := &schema.Table{
+		Name:           meta.Name,
+		Path:           path,
+		Schema:         tableSchema,
+		Rows:           rows,
+		Indexes:        make(map[string]*data.Index),
+		OrderedIndexes: make(map[string]*data.OrderedIndex),
+		LastInsertID:   meta.LastInsertID,
+		NextRowID:      meta.NextRowID,
+		LastLSN:        meta.LastLSN,
+	}
+	if err := table.InitRowIDs(); err != nil {
+		return nil, err
 	}
 
-	table := &schema.Table{
-		Name:         meta.Name,
-		Path:         path,
-		Schema:       tableSchema,
-		Rows:         rows,
-		Indexes:      make(map[string]*data.Index),
-		LastInsertID: meta.LastInsertID,
+	// Ordered index definitions; their entries are filled in by indexing.BuildIndexes
+	for _, im := range meta.Indexes {
+		table.OrderedIndexes[im.Name] = data.NewOrderedIndex(im.Name, im.Columns, im.Unique)
 	}
 
 	// Validate all loaded rows against schema
@@ -81,3 +142,29 @@ func LoadTable(path string) (*schema.Table, error) {
 
 	return table, nil
 }
+
+// takeRowID moves the row id stored under schema.RowIDColumn into row.ID
+// Rows written before row ids existed have none and keep ID 0.
+func takeRowID(row *data.Row) error {
+	val, exists := row.Data[schema.RowIDColumn]
+	if !exists {
+		return nil
+	}
+	delete(row.Data, schema.RowIDColumn)
+
+	f, ok := val.(float64)
+	if !ok || f < 1 || f != float64(int64(f)) {
+		return fmt.Errorf("invalid row id %v", val)
+	}
+	row.ID = int64(f)
+	return nil
+}
+
+// decodeDefault restores the Go type of a column default read from JSON
+// JSON numbers decode as float64, but INT columns hold int64 values in memory
+func decodeDefault(val interface{}, colType schema.ColumnType) interface{} {
+	if f, ok := val.(float64); ok && colType == schema.ColumnTypeInt {
+		return int64(f)
+	}
+	return val
+}
@@ -0,0 +1,170 @@
+package manager
+
+import (
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/leengari/mini-rdbms/internal/domain/transaction"
+)
+
+// CheckpointConfig controls when the background checkpointer flushes dirty tables
+type CheckpointConfig struct {
+	Interval  time.Duration // flush every loaded database this often (0 disables timed flushes)
+	DirtyRows int           // flush a database once its log holds this many row changes (0 disables)
+}
+
+// Reasons a checkpoint runs, reported in CheckpointEvent.Reason
+const (
+	ReasonInterval  = "interval"
+	ReasonThreshold = "threshold"
+	ReasonCommand   = "command"
+	ReasonShutdown  = "shutdown"
+)
+
+// CheckpointEventType represents the phases of a checkpoint
+type CheckpointEventType string
+
+const (
+	EventCheckpointStart CheckpointEventType = "checkpoint_start"
+	EventCheckpointEnd   CheckpointEventType = "checkpoint_end"
+)
+
+// CheckpointEvent describes the checkpoint of one database
+// Tables, Rows, Duration and Err are only set on EventCheckpointEnd.
+type CheckpointEvent struct {
+	Type      CheckpointEventType
+	Reason    string        // why the checkpoint ran (ReasonInterval, ReasonCommand, ...)
+	Database  string        // database being checkpointed
+	Timestamp time.Time     // when the event occurred
+	Tables    []string      // tables written to disk
+	Rows      int           // row changes removed from the write-ahead log
+	Duration  time.Duration // time spent writing tables and truncating the log
+	Err       error         // why the checkpoint failed, if it did
+}
+
+// CheckpointObserver receives an event before and after each database checkpoint
+// Events from the background checkpointer arrive on its own goroutine.
+type CheckpointObserver interface {
+	OnCheckpoint(event CheckpointEvent)
+}
+
+// checkpointer is the state of a running background checkpointer
+type checkpointer struct {
+	config CheckpointConfig
+	wake   chan struct{} // signalled when a database crosses the dirty-row threshold
+	stop   chan struct{}
+	done   chan struct{}
+}
+
+// StartCheckpointer starts a goroutine that flushes dirty tables in the background,
+// on a timer and/or once a database has logged enough row changes since its last checkpoint
+func (r *Registry) StartCheckpointer(config CheckpointConfig) error {
+	if config.Interval <= 0 && config.DirtyRows <= 0 {
+		return fmt.Errorf("checkpointer needs an interval or a dirty-row threshold")
+	}
+
+	r.ckptMu.Lock()
+	defer r.ckptMu.Unlock()
+
+	if r.ckpt != nil {
+		return fmt.Errorf("checkpointer is already running")
+	}
+	c := &checkpointer{
+		config: config,
+		wake:   make(chan struct{}, 1),
+		stop:   make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+	r.ckpt = c
+	go r.runCheckpointer(c)
+
+	slog.Info("checkpointer started",
+		slog.Duration("interval", config.Interval),
+		slog.Int("dirty_rows", config.DirtyRows))
+	return nil
+}
+
+// StopCheckpointer stops the background checkpointer and waits for a checkpoint
+// in progress to finish. Tables changed since the last checkpoint stay dirty.
+func (r *Registry) StopCheckpointer() {
+	r.ckptMu.Lock()
+	c := r.ckpt
+	r.ckpt = nil
+	r.ckptMu.Unlock()
+
+	if c == nil {
+		return
+	}
+	close(c.stop)
+	<-c.done
+	slog.Info("checkpointer stopped")
+}
+
+// runCheckpointer is the background checkpointer loop
+func (r *Registry) runCheckpointer(c *checkpointer) {
+	defer close(c.done)
+
+	var tick <-chan time.Time
+	if c.config.Interval > 0 {
+		ticker := time.NewTicker(c.config.Interval)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+
+	for {
+		select {
+		case <-c.stop:
+			return
+		case <-tick:
+			r.backgroundFlush(ReasonInterval, 0)
+		case <-c.wake:
+			r.backgroundFlush(ReasonThreshold, c.config.DirtyRows)
+		}
+	}
+}
+
+// backgroundFlush checkpoints the databases holding at least minPending logged changes
+func (r *Registry) backgroundFlush(reason string, minPending int) {
+	tx := transaction.NewTransaction()
+	defer tx.Close()
+
+	if _, err := r.flush(reason, minPending, tx); err != nil {
+		slog.Error("background checkpoint failed", "reason", reason, "error", err)
+	}
+}
+
+// committed is called by a database's log after each commit
+// Wakes the checkpointer once the log holds enough changes. Must not block.
+func (r *Registry) committed(pending int) {
+	r.ckptMu.Lock()
+	defer r.ckptMu.Unlock()
+
+	c := r.ckpt
+	if c == nil || c.config.DirtyRows <= 0 || pending < c.config.DirtyRows {
+		return
+	}
+	select {
+	case c.wake <- struct{}{}:
+	default: // a wake-up is already pending
+	}
+}
+
+// AddObserver registers an observer to receive checkpoint events
+func (r *Registry) AddObserver(observer CheckpointObserver) {
+	r.ckptMu.Lock()
+	defer r.ckptMu.Unlock()
+	r.observers = append(r.observers, observer)
+}
+
+// notify sends a checkpoint event to all registered observers
+func (r *Registry) notify(event CheckpointEvent) {
+	r.ckptMu.Lock()
+	observers := append([]CheckpointObserver(nil), r.observers...)
+	r.ckptMu.Unlock()
+
+	event.Timestamp = time.Now()
+	for _, observer := range observers {
+		observer.OnCheckpoint(event)
+	}
+}
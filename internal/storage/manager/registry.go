@@ -1,35 +1,46 @@
 package manager
 
 import (
+	"errors"
 	"fmt"
 	"log/slog"
 	"path/filepath"
+	"sort"
 	"sync"
+	"time"
 
 	"github.com/leengari/mini-rdbms/internal/domain/schema"
 	"github.com/leengari/mini-rdbms/internal/domain/transaction"
 	"github.com/leengari/mini-rdbms/internal/query/indexing"
 	"github.com/leengari/mini-rdbms/internal/storage/engine"
+	"github.com/leengari/mini-rdbms/internal/storage/wal"
 )
 
 // Registry manages loaded databases in a thread-safe way
 type Registry struct {
 	mu            sync.RWMutex
 	loaded        map[string]*schema.Database
+	logs          map[string]*wal.Log // write-ahead log per loaded database
 	basePath      string
 	storageEngine engine.StorageEngine
+
+	ckptMu    sync.Mutex           // guards ckpt and observers (never held while waiting on a log)
+	ckpt      *checkpointer        // running background checkpointer, if any
+	observers []CheckpointObserver // receive checkpoint events
 }
 
 // NewRegistry creates a new database registry with the given storage engine
 func NewRegistry(basePath string, storageEngine engine.StorageEngine) *Registry {
 	return &Registry{
 		loaded:        make(map[string]*schema.Database),
+		logs:          make(map[string]*wal.Log),
 		basePath:      basePath,
 		storageEngine: storageEngine,
 	}
 }
 
-// Get loads a database (or returns cached one) and ensures indexes are built
+// Get loads a database (or returns cached one), replays its write-ahead log
+// and ensures indexes are built
 func (r *Registry) Get(name string) (*schema.Database, error) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
@@ -46,12 +57,21 @@ func (r *Registry) Get(name string) (*schema.Database, error) {
 		return nil, err
 	}
 
+	// Replay committed changes made since the last checkpoint
+	log, err := wal.Recover(db)
+	if err != nil {
+		return nil, fmt.Errorf("failed to recover database '%s': %w", name, err)
+	}
+
 	// Build Indexes
 	if err := indexing.BuildDatabaseIndexes(db); err != nil {
+		log.Close()
 		return nil, fmt.Errorf("failed to build indexes: %w", err)
 	}
+	log.OnCommit(r.committed)
 
 	r.loaded[name] = db
+	r.logs[name] = log
 	return db, nil
 }
 
@@ -72,7 +92,7 @@ func (r *Registry) Drop(name string) error {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
-	delete(r.loaded, name)
+	r.unloadUnsafe(name)
 	return r.storageEngine.DropDatabase(name, r.basePath)
 }
 
@@ -83,29 +103,197 @@ func (r *Registry) Rename(oldName, newName string) error {
 
 	// If loaded, we must unload/save
 	if db, ok := r.loaded[oldName]; ok {
-		// Create a transaction for the save operation
 		tx := transaction.NewTransaction()
 		defer tx.Close()
 
-		if err := r.storageEngine.SaveDatabase(db, tx); err != nil {
+		if err := r.saveAndTruncate(db, r.logs[oldName], tx); err != nil {
 			return fmt.Errorf("failed to save database before rename: %w", err)
 		}
-		delete(r.loaded, oldName)
+		r.unloadUnsafe(oldName)
 	}
 
 	return r.storageEngine.RenameDatabase(oldName, newName, r.basePath)
 }
 
-// SaveAll saves all currently loaded databases
+// SaveAll writes the dirty tables of all loaded databases and truncates their write-ahead logs
+// Used at shutdown; failures are logged.
 func (r *Registry) SaveAll(tx *transaction.Transaction) {
+	if _, err := r.flush(ReasonShutdown, 0, tx); err != nil {
+		slog.Error("failed to save databases", "error", err)
+	}
+}
+
+// Flush writes the dirty tables of all loaded databases and truncates their write-ahead logs
+// Returns the number of tables written. Used by the CHECKPOINT statement.
+func (r *Registry) Flush() (int, error) {
+	tx := transaction.NewTransaction()
+	defer tx.Close()
+	return r.flush(ReasonCommand, 0, tx)
+}
+
+// Checkpoint rewrites the table files of a loaded database and truncates its write-ahead log
+// Databases that were not loaded through this registry are left untouched.
+func (r *Registry) Checkpoint(db *schema.Database) error {
+	log := r.WAL(db)
+	if log == nil {
+		return nil
+	}
+
+	tx := transaction.NewTransaction()
+	defer tx.Close()
+	return r.saveAndTruncate(db, log, tx)
+}
+
+// Close stops the background checkpointer, writes the dirty tables of every loaded
+// database, then closes their write-ahead logs and unloads them
+// Used when the registry's last user is done with it; failures to save are returned.
+func (r *Registry) Close() error {
+	r.StopCheckpointer()
+
+	tx := transaction.NewTransaction()
+	defer tx.Close()
+	_, err := r.flush(ReasonShutdown, 0, tx)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for name := range r.loaded {
+		r.unloadUnsafe(name)
+	}
+	return err
+}
+
+// WAL returns the write-ahead log of a database loaded through this registry,
+// or nil if the database is not managed by the registry
+func (r *Registry) WAL(db *schema.Database) *wal.Log {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
-	for _, db := range r.loaded {
+	if db == nil || r.loaded[db.Name] != db {
+		return nil
+	}
+	return r.logs[db.Name]
+}
+
+// flush checkpoints every loaded database whose log holds at least minPending changes
+// Databases are snapshotted first, so the registry is not locked while waiting for writers.
+func (r *Registry) flush(reason string, minPending int, tx *transaction.Transaction) (int, error) {
+	r.mu.RLock()
+	names := make([]string, 0, len(r.loaded))
+	for name := range r.loaded {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	dbs := make([]*schema.Database, len(names))
+	logs := make([]*wal.Log, len(names))
+	for i, name := range names {
+		dbs[i], logs[i] = r.loaded[name], r.logs[name]
+	}
+	r.mu.RUnlock()
+
+	flushed := 0
+	var errs []error
+	for i, db := range dbs {
+		if logs[i].Pending() < minPending {
+			continue
+		}
+		n, err := r.flushDatabase(db, logs[i], reason, tx)
+		flushed += n
+		if err != nil {
+			errs = append(errs, fmt.Errorf("database '%s': %w", db.Name, err))
+		}
+	}
+	return flushed, errors.Join(errs...)
+}
+
+// flushDatabase writes the dirty tables of a database, then truncates its log
+// Does nothing when the database has no dirty tables and nothing logged.
+func (r *Registry) flushDatabase(db *schema.Database, log *wal.Log, reason string, tx *transaction.Transaction) (int, error) {
+	if log.Pending() == 0 && len(dirtyTables(db)) == 0 {
+		return 0, nil
+	}
+
+	r.notify(CheckpointEvent{Type: EventCheckpointStart, Reason: reason, Database: db.Name})
+	start := time.Now()
+
+	var tables []string
+	rows := 0
+	err := log.Checkpoint(func() error {
+		// Writers are blocked, so the dirty set cannot change underneath us
+		rows = log.Pending()
+		for _, table := range dirtyTables(db) {
+			if err := r.storageEngine.SaveTable(table, tx); err != nil {
+				return fmt.Errorf("failed to save table %s: %w", table.Name, err)
+			}
+			table.Lock()
+			table.Dirty = false
+			table.Unlock()
+			tables = append(tables, table.Name)
+		}
+		return nil
+	})
+
+	r.notify(CheckpointEvent{
+		Type:     EventCheckpointEnd,
+		Reason:   reason,
+		Database: db.Name,
+		Tables:   tables,
+		Rows:     rows,
+		Duration: time.Since(start),
+		Err:      err,
+	})
+	return len(tables), err
+}
+
+// dirtyTables returns a database's tables with unsaved changes, sorted by name
+func dirtyTables(db *schema.Database) []*schema.Table {
+	var tables []*schema.Table
+	for _, table := range db.Tables {
+		table.RLock()
+		dirty := table.Dirty
+		table.RUnlock()
+		if dirty {
+			tables = append(tables, table)
+		}
+	}
+	sort.Slice(tables, func(i, j int) bool { return tables[i].Name < tables[j].Name })
+	return tables
+}
+
+// saveAndTruncate saves the database, then truncates its log (if any) once the table files are on disk
+func (r *Registry) saveAndTruncate(db *schema.Database, log *wal.Log, tx *transaction.Transaction) error {
+	save := func() error {
 		if err := r.storageEngine.SaveDatabase(db, tx); err != nil {
-			slog.Error("failed to save database", "name", db.Name, "error", err)
+			return err
+		}
+		for _, table := range db.Tables {
+			table.Lock()
+			table.Dirty = false
+			table.Unlock()
 		}
+		return nil
 	}
+
+	if log == nil {
+		return save()
+	}
+	return log.Checkpoint(save)
+}
+
+// unloadUnsafe removes a database from the cache and closes its log
+// Must be called while holding r.mu write lock
+func (r *Registry) unloadUnsafe(name string) {
+	if log, ok := r.logs[name]; ok {
+		if err := log.Close(); err != nil {
+			slog.Warn("failed to close wal", "database", name, "error", err)
+		}
+		delete(r.logs, name)
+	}
+	delete(r.loaded, name)
+}
+
+// StorageEngine returns the storage engine backing this registry
+func (r *Registry) StorageEngine() engine.StorageEngine {
+	return r.storageEngine
 }
 
 // List returns a list of all available databases
@@ -1,26 +1,53 @@
 package metadata
 
+// Storage formats a database can be kept in
+const (
+	StorageJSON = "json" // meta.json and data.json per table
+	StoragePage = "page" // meta.json and a data.pages file of slotted pages per table
+)
+
 // DatabaseMeta represents the database-level metadata from meta.json
 type DatabaseMeta struct {
 	Name    string   `json:"name"`
 	Version int      `json:"version"`
+	Storage string   `json:"storage,omitempty"` // Storage format; empty for JSON
 	Tables  []string `json:"tables,omitempty"`
 }
 
+// StorageFormat returns the storage format of the database
+func (m DatabaseMeta) StorageFormat() string {
+	if m.Storage == "" {
+		return StorageJSON
+	}
+	return m.Storage
+}
+
 // TableMeta represents the table-level metadata from meta.json
 type TableMeta struct {
 	Name         string       `json:"name"`
 	Columns      []ColumnMeta `json:"columns"`
 	LastInsertID int64        `json:"last_insert_id,omitempty"`
 	RowCount     int64        `json:"row_count,omitempty"`
+	NextRowID    int64        `json:"next_row_id,omitempty"` // row id the next inserted row receives
+	LastLSN      int64        `json:"last_lsn,omitempty"`    // last WAL record included in the table data
+	Indexes      []IndexMeta  `json:"indexes,omitempty"`     // ordered indexes (CREATE INDEX), by name
+}
+
+// IndexMeta represents an ordered index definition for JSON serialization
+// The index entries are not stored; they are rebuilt from the rows on load.
+type IndexMeta struct {
+	Name    string   `json:"name"`
+	Columns []string `json:"columns"`
+	Unique  bool     `json:"unique,omitempty"`
 }
 
 // ColumnMeta represents column metadata for JSON serialization
 type ColumnMeta struct {
-	Name          string `json:"name"`
-	Type          string `json:"type"`
-	PrimaryKey    bool   `json:"primary_key"`
-	Unique        bool   `json:"unique"`
-	NotNull       bool   `json:"not_null"`
-	AutoIncrement bool   `json:"auto_increment,omitempty"`
+	Name          string      `json:"name"`
+	Type          string      `json:"type"`
+	PrimaryKey    bool        `json:"primary_key"`
+	Unique        bool        `json:"unique"`
+	NotNull       bool        `json:"not_null"`
+	AutoIncrement bool        `json:"auto_increment,omitempty"`
+	Default       interface{} `json:"default,omitempty"`
 }
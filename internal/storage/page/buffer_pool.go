@@ -0,0 +1,149 @@
+package page
+
+import (
+	"container/list"
+	"sync"
+)
+
+// DefaultPoolPages is the default buffer pool capacity: 1024 pages, 8 MiB
+const DefaultPoolPages = 1024
+
+// BufferPool caches the pages of open data files in memory
+// When more than capacity clean pages are cached, the least recently used are evicted.
+// Changed (dirty) pages are never evicted: they stay in the pool until their file
+// commits, so the pool may briefly hold more pages than its capacity.
+// One pool is shared by all the heaps of a storage engine.
+type BufferPool struct {
+	mu       sync.Mutex
+	capacity int
+	frames   map[frameKey]*frame
+	lru      *list.List // Clean frames; front is the most recently used
+}
+
+type frameKey struct {
+	file *dataFile
+	page uint32
+}
+
+// frame is a page held by the pool
+type frame struct {
+	key  frameKey
+	page Page
+	el   *list.Element // Position in the LRU list; nil while the page is dirty
+}
+
+// NewBufferPool creates a buffer pool caching up to capacity clean pages
+func NewBufferPool(capacity int) *BufferPool {
+	return &BufferPool{
+		capacity: max(capacity, 1),
+		frames:   make(map[frameKey]*frame),
+		lru:      list.New(),
+	}
+}
+
+// get returns a page of a file, reading it on a miss
+// When write is set the page is marked dirty, so changes made to it are kept until
+// the file commits; otherwise the page must not be changed.
+func (b *BufferPool) get(f *dataFile, n uint32, write bool) (Page, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if fr, ok := b.frames[frameKey{f, n}]; ok {
+		switch {
+		case fr.el == nil:
+			// Already dirty
+		case write:
+			b.lru.Remove(fr.el)
+			fr.el = nil
+		default:
+			b.lru.MoveToFront(fr.el)
+		}
+		return fr.page, nil
+	}
+
+	p, err := f.readPage(n)
+	if err != nil {
+		return nil, err
+	}
+	b.add(&frame{key: frameKey{f, n}, page: p}, !write)
+	return p, nil
+}
+
+// allocate adds a new dirty page of the given kind for a page not yet in the file
+func (b *BufferPool) allocate(f *dataFile, n uint32, kind Kind) Page {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.remove(frameKey{f, n})
+	p := newPage(kind)
+	b.add(&frame{key: frameKey{f, n}, page: p}, false)
+	return p
+}
+
+// add inserts a frame, clean or dirty, and evicts clean frames over capacity
+// Must be called while holding b.mu
+func (b *BufferPool) add(fr *frame, clean bool) {
+	b.frames[fr.key] = fr
+	if clean {
+		fr.el = b.lru.PushFront(fr)
+	}
+	for b.lru.Len() > b.capacity {
+		b.remove(b.lru.Back().Value.(*frame).key)
+	}
+}
+
+// remove drops a frame
+// Must be called while holding b.mu
+func (b *BufferPool) remove(key frameKey) {
+	if fr, ok := b.frames[key]; ok {
+		if fr.el != nil {
+			b.lru.Remove(fr.el)
+		}
+		delete(b.frames, key)
+	}
+}
+
+// flush commits the dirty pages of a file below the given page count, together with
+// the side files, and marks them clean
+// Frames at or past the page count are dropped.
+func (b *BufferPool) flush(f *dataFile, pages uint32, files map[string][]byte) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	images := make(map[uint32]Page)
+	for key, fr := range b.frames {
+		if key.file == f && fr.el == nil && key.page < pages {
+			images[key.page] = fr.page
+		}
+	}
+	if err := f.commit(images, pages, files); err != nil {
+		return err
+	}
+
+	for key, fr := range b.frames {
+		switch {
+		case key.file != f:
+			// Another file's frame
+		case key.page >= pages:
+			b.remove(key)
+		case fr.el == nil:
+			fr.el = b.lru.PushFront(fr)
+		}
+	}
+	for b.lru.Len() > b.capacity {
+		b.remove(b.lru.Back().Value.(*frame).key)
+	}
+	return nil
+}
+
+// discard drops the frames of a file from the given page onwards, losing any changes
+func (b *BufferPool) discard(f *dataFile, from uint32) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for key := range b.frames {
+		if key.file == f && key.page >= from {
+			b.remove(key)
+		}
+	}
+}
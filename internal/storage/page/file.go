@@ -0,0 +1,303 @@
+package page
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// journalMagic starts every journal file
+const journalMagic = "JOYJRNL1"
+
+// dataFile is a file of PageSize pages, changed only through a redo journal
+//
+// A commit first writes every changed page, the new page count and any side files
+// (such as the table's meta.json) to <path>.journal and syncs it, then applies them.
+// A crash before the journal is complete leaves the data file untouched and the torn
+// journal is discarded on open; a crash after it is complete is repaired on open by
+// applying the journal again.
+type dataFile struct {
+	path  string
+	file  *os.File
+	pages uint32 // Committed page count
+}
+
+// openDataFile opens (or creates) a data file, first applying any complete journal
+func openDataFile(path string) (*dataFile, error) {
+	if err := recoverJournal(path); err != nil {
+		return nil, err
+	}
+
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open data file: %w", err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to stat data file: %w", err)
+	}
+	if info.Size()%PageSize != 0 {
+		file.Close()
+		return nil, fmt.Errorf("data file %s is %d bytes, not a whole number of pages", path, info.Size())
+	}
+
+	return &dataFile{path: path, file: file, pages: uint32(info.Size() / PageSize)}, nil
+}
+
+// readPage reads a committed page and checks its checksum
+func (f *dataFile) readPage(n uint32) (Page, error) {
+	p := make(Page, PageSize)
+	if _, err := f.file.ReadAt(p, int64(n)*PageSize); err != nil {
+		return nil, fmt.Errorf("failed to read page %d of %s: %w", n, f.path, err)
+	}
+	if err := p.verify(); err != nil {
+		return nil, fmt.Errorf("page %d of %s is corrupt: %w", n, f.path, err)
+	}
+	return p, nil
+}
+
+// commit durably replaces the given pages, resizes the file to the given page count
+// and writes the side files (named relative to the data file's directory)
+func (f *dataFile) commit(images map[uint32]Page, pages uint32, files map[string][]byte) error {
+	j := &journal{pages: pages, images: images, files: files}
+	for _, p := range images {
+		p.seal()
+	}
+
+	journalPath := f.path + ".journal"
+	if err := j.write(journalPath); err != nil {
+		os.Remove(journalPath)
+		return err
+	}
+	if err := j.apply(f.file, filepath.Dir(f.path)); err != nil {
+		return err
+	}
+	if err := os.Remove(journalPath); err != nil {
+		return fmt.Errorf("failed to remove journal: %w", err)
+	}
+
+	f.pages = pages
+	return nil
+}
+
+func (f *dataFile) close() error {
+	return f.file.Close()
+}
+
+// journal is one commit: the page images to write, the final page count and the
+// side files to replace
+type journal struct {
+	pages  uint32
+	images map[uint32]Page
+	files  map[string][]byte
+}
+
+// write stores the journal at path and syncs it
+// Layout: magic, page count, side files (name and contents), page images (number and
+// bytes), then a CRC-32 of everything before it.
+func (j *journal) write(path string) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create journal: %w", err)
+	}
+	defer file.Close()
+
+	hash := crc32.NewIEEE()
+	w := bufio.NewWriter(io.MultiWriter(file, hash))
+	w.WriteString(journalMagic)
+	writeUint32(w, j.pages)
+
+	names := make([]string, 0, len(j.files))
+	for name := range j.files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	writeUint32(w, uint32(len(names)))
+	for _, name := range names {
+		writeUint32(w, uint32(len(name)))
+		w.WriteString(name)
+		writeUint32(w, uint32(len(j.files[name])))
+		w.Write(j.files[name])
+	}
+
+	numbers := make([]uint32, 0, len(j.images))
+	for n := range j.images {
+		numbers = append(numbers, n)
+	}
+	sort.Slice(numbers, func(a, b int) bool { return numbers[a] < numbers[b] })
+	writeUint32(w, uint32(len(numbers)))
+	for _, n := range numbers {
+		writeUint32(w, n)
+		w.Write(j.images[n])
+	}
+
+	if err := w.Flush(); err != nil {
+		return fmt.Errorf("failed to write journal: %w", err)
+	}
+	if err := binary.Write(file, binary.LittleEndian, hash.Sum32()); err != nil {
+		return fmt.Errorf("failed to write journal: %w", err)
+	}
+	if err := file.Sync(); err != nil {
+		return fmt.Errorf("failed to sync journal: %w", err)
+	}
+	return nil
+}
+
+// apply writes the journal's pages and side files in place
+// Side files are synced before they are renamed into place and the directory after,
+// so they are on disk before the journal is removed. Applying a journal twice has the same effect
+// as applying it once.
+func (j *journal) apply(file *os.File, dir string) error {
+	for n, p := range j.images {
+		if _, err := file.WriteAt(p, int64(n)*PageSize); err != nil {
+			return fmt.Errorf("failed to write page %d: %w", n, err)
+		}
+	}
+	if err := file.Truncate(int64(j.pages) * PageSize); err != nil {
+		return fmt.Errorf("failed to resize data file: %w", err)
+	}
+	if err := file.Sync(); err != nil {
+		return fmt.Errorf("failed to sync data file: %w", err)
+	}
+
+	for name, content := range j.files {
+		path := filepath.Join(dir, name)
+		tmpPath := path + ".tmp"
+		if err := writeSynced(tmpPath, content); err != nil {
+			return fmt.Errorf("failed to write temp file %s: %w", name, err)
+		}
+		if err := os.Rename(tmpPath, path); err != nil {
+			return fmt.Errorf("failed to rename temp → %s: %w", name, err)
+		}
+	}
+	if len(j.files) == 0 {
+		return nil
+	}
+
+	d, err := os.Open(dir)
+	if err != nil {
+		return fmt.Errorf("failed to open directory %s: %w", dir, err)
+	}
+	defer d.Close()
+	if err := d.Sync(); err != nil {
+		return fmt.Errorf("failed to sync directory %s: %w", dir, err)
+	}
+	return nil
+}
+
+// writeSynced writes content to path and syncs it to disk
+func writeSynced(path string, content []byte) error {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(content); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Sync(); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// readJournal parses a journal file, failing if it is torn or corrupt
+func readJournal(content []byte) (*journal, error) {
+	if len(content) < len(journalMagic)+4 || string(content[:len(journalMagic)]) != journalMagic {
+		return nil, fmt.Errorf("missing journal header")
+	}
+	body := content[:len(content)-4]
+	if crc32.ChecksumIEEE(body) != binary.LittleEndian.Uint32(content[len(body):]) {
+		return nil, fmt.Errorf("journal checksum mismatch")
+	}
+
+	r := &journalReader{b: body[len(journalMagic):]}
+	j := &journal{pages: r.uint32(), images: make(map[uint32]Page), files: make(map[string][]byte)}
+	for i := r.uint32(); i > 0 && r.err == nil; i-- {
+		name := string(r.bytes(int(r.uint32())))
+		j.files[name] = r.bytes(int(r.uint32()))
+	}
+	for i := r.uint32(); i > 0 && r.err == nil; i-- {
+		n := r.uint32()
+		j.images[n] = Page(r.bytes(PageSize))
+	}
+	if r.err != nil {
+		return nil, r.err
+	}
+	return j, nil
+}
+
+// recoverJournal finishes the commit a crash interrupted, if its journal is complete,
+// and removes the journal
+func recoverJournal(path string) error {
+	journalPath := path + ".journal"
+	content, err := os.ReadFile(journalPath)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read journal: %w", err)
+	}
+
+	j, err := readJournal(content)
+	if err != nil {
+		slog.Warn("discarding incomplete journal", slog.String("path", journalPath), slog.Any("reason", err))
+		return os.Remove(journalPath)
+	}
+
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open data file: %w", err)
+	}
+	defer file.Close()
+	if err := j.apply(file, filepath.Dir(path)); err != nil {
+		return fmt.Errorf("failed to replay journal: %w", err)
+	}
+
+	slog.Info("journal replayed",
+		slog.String("path", journalPath),
+		slog.Int("pages", len(j.images)),
+		slog.Int("files", len(j.files)))
+	return os.Remove(journalPath)
+}
+
+func writeUint32(w io.Writer, v uint32) {
+	var b [4]byte
+	binary.LittleEndian.PutUint32(b[:], v)
+	w.Write(b[:])
+}
+
+// journalReader reads journal fields, remembering the first error
+type journalReader struct {
+	b   []byte
+	err error
+}
+
+func (r *journalReader) bytes(n int) []byte {
+	if r.err != nil {
+		return nil
+	}
+	if n < 0 || n > len(r.b) {
+		r.err = fmt.Errorf("journal is truncated")
+		return nil
+	}
+	b := r.b[:n]
+	r.b = r.b[n:]
+	return b
+}
+
+func (r *journalReader) uint32() uint32 {
+	b := r.bytes(4)
+	if b == nil {
+		return 0
+	}
+	return binary.LittleEndian.Uint32(b)
+}
@@ -0,0 +1,71 @@
+package page
+
+// fsmUnit is the granularity, in bytes, of the free space map
+const fsmUnit = 32
+
+// fsmEmpty marks a page with no records, which can also be reused whole
+const fsmEmpty = 255
+
+// freeSpaceMap records roughly how much room each page of a heap has for a new record,
+// so an insert can pick a page without reading pages
+// Each page takes one byte counting free space in fsmUnit steps, rounded down so a page
+// always has at least the space the map promises. The map is rebuilt from the page
+// headers when a heap is opened and kept up to date as records come and go.
+type freeSpaceMap struct {
+	units []uint8
+	hint  uint32 // Page the last search stopped at; appends keep landing there
+}
+
+// set records the free space of a page
+func (m *freeSpaceMap) set(page uint32, free int, empty bool) {
+	for uint32(len(m.units)) <= page {
+		m.units = append(m.units, 0)
+	}
+	if empty {
+		m.units[page] = fsmEmpty
+		return
+	}
+	m.units[page] = uint8(min(free/fsmUnit, fsmEmpty-1))
+}
+
+// find returns a page with room for a record of the given size
+func (m *freeSpaceMap) find(size int) (uint32, bool) {
+	return m.search(func(units uint8) bool {
+		return units == fsmEmpty || int(units)*fsmUnit >= size
+	})
+}
+
+// findEmpty returns a page with no records
+func (m *freeSpaceMap) findEmpty() (uint32, bool) {
+	return m.search(func(units uint8) bool {
+		return units == fsmEmpty
+	})
+}
+
+// empty reports whether a page has no records
+func (m *freeSpaceMap) empty(page uint32) bool {
+	return page < uint32(len(m.units)) && m.units[page] == fsmEmpty
+}
+
+// search scans the map for a matching page, starting where the last search stopped
+func (m *freeSpaceMap) search(match func(uint8) bool) (uint32, bool) {
+	n := uint32(len(m.units))
+	for i := uint32(0); i < n; i++ {
+		page := (m.hint + i) % n
+		if match(m.units[page]) {
+			m.hint = page
+			return page, true
+		}
+	}
+	return 0, false
+}
+
+// truncate forgets the pages from the given page onwards
+func (m *freeSpaceMap) truncate(pages uint32) {
+	if uint32(len(m.units)) > pages {
+		m.units = m.units[:pages]
+	}
+	if m.hint >= pages {
+		m.hint = 0
+	}
+}
@@ -0,0 +1,303 @@
+package page
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// Stored record tags: the first byte of every slot
+const (
+	tagInline   = 0 // The record follows
+	tagOverflow = 1 // First overflow page (4 bytes) and record length (4 bytes) follow
+)
+
+// maxInline is the largest record kept on a heap page; longer records move to an
+// overflow chain so a heap page always holds several records
+const maxInline = PageSize / 4
+
+// RID identifies a record by its page and slot
+type RID struct {
+	Page uint32
+	Slot uint16
+}
+
+// Heap is an unordered collection of records in a data file of slotted pages
+// Changes are made to pages in the buffer pool and reach the file, all at once, on
+// Commit. A Heap is not safe for concurrent use.
+type Heap struct {
+	file     *dataFile
+	pool     *BufferPool
+	pages    uint32 // Page count, including pages added since the last commit
+	fsm      freeSpaceMap
+	fsmReady bool
+}
+
+// OpenHeap opens the heap stored at path, creating an empty one if there is none,
+// and finishes any commit that a crash interrupted
+func OpenHeap(path string, pool *BufferPool) (*Heap, error) {
+	file, err := openDataFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return &Heap{file: file, pool: pool, pages: file.pages}, nil
+}
+
+// Pages returns the number of pages in the heap
+func (h *Heap) Pages() uint32 {
+	return h.pages
+}
+
+// Scan calls fn for every record, in page and slot order, and rebuilds the free
+// space map on the way
+// The record bytes are only valid during the call.
+func (h *Heap) Scan(fn func(rid RID, record []byte) error) error {
+	h.fsm.truncate(0)
+	h.fsmReady = false
+	for n := uint32(0); n < h.pages; n++ {
+		p, err := h.pool.get(h.file, n, false)
+		if err != nil {
+			return err
+		}
+		h.track(n, p)
+		if p.Kind() != KindHeap {
+			continue
+		}
+
+		for slot := 0; slot < p.Slots(); slot++ {
+			stored, ok := p.Record(slot)
+			if !ok {
+				continue
+			}
+			record, err := h.resolve(stored)
+			if err != nil {
+				return fmt.Errorf("record %d:%d: %w", n, slot, err)
+			}
+			if err := fn(RID{Page: n, Slot: uint16(slot)}, record); err != nil {
+				return err
+			}
+		}
+	}
+	h.fsmReady = true
+	return nil
+}
+
+// Get returns the record at rid
+// The record bytes are only valid until the heap next changes.
+func (h *Heap) Get(rid RID) ([]byte, error) {
+	if rid.Page >= h.pages {
+		return nil, fmt.Errorf("no record at %d:%d", rid.Page, rid.Slot)
+	}
+	p, err := h.pool.get(h.file, rid.Page, false)
+	if err != nil {
+		return nil, err
+	}
+	stored, ok := p.Record(int(rid.Slot))
+	if p.Kind() != KindHeap || !ok {
+		return nil, fmt.Errorf("no record at %d:%d", rid.Page, rid.Slot)
+	}
+	record, err := h.resolve(stored)
+	if err != nil {
+		return nil, fmt.Errorf("record %d:%d: %w", rid.Page, rid.Slot, err)
+	}
+	return record, nil
+}
+
+// Insert stores a record and returns its RID
+func (h *Heap) Insert(record []byte) (RID, error) {
+	if err := h.loadFSM(); err != nil {
+		return RID{}, err
+	}
+
+	stored := append([]byte{tagInline}, record...)
+	if len(record) > maxInline {
+		first, err := h.writeChain(record)
+		if err != nil {
+			return RID{}, err
+		}
+		stored = binary.LittleEndian.AppendUint32([]byte{tagOverflow}, first)
+		stored = binary.LittleEndian.AppendUint32(stored, uint32(len(record)))
+	}
+
+	// The free space map only promises lower bounds, so a page it picks always has room
+	n, ok := h.fsm.find(len(stored))
+	var p Page
+	if ok {
+		var err error
+		if p, err = h.pool.get(h.file, n, true); err != nil {
+			return RID{}, err
+		}
+		if p.Kind() != KindHeap {
+			p.init(KindHeap)
+		}
+	} else {
+		n, p = h.appendPage(KindHeap)
+	}
+
+	slot, ok := p.Insert(stored)
+	if !ok {
+		return RID{}, fmt.Errorf("page %d has no room for a %d byte record", n, len(stored))
+	}
+	h.track(n, p)
+	return RID{Page: n, Slot: uint16(slot)}, nil
+}
+
+// Delete removes a record, freeing its overflow pages
+func (h *Heap) Delete(rid RID) error {
+	if err := h.loadFSM(); err != nil {
+		return err
+	}
+	if rid.Page >= h.pages {
+		return fmt.Errorf("no record at %d:%d", rid.Page, rid.Slot)
+	}
+	p, err := h.pool.get(h.file, rid.Page, true)
+	if err != nil {
+		return err
+	}
+	stored, ok := p.Record(int(rid.Slot))
+	if p.Kind() != KindHeap || !ok {
+		return fmt.Errorf("no record at %d:%d", rid.Page, rid.Slot)
+	}
+
+	if stored[0] == tagOverflow {
+		for n := binary.LittleEndian.Uint32(stored[1:]); n != noPage; {
+			chained, err := h.pool.get(h.file, n, true)
+			if err != nil {
+				return err
+			}
+			next := chained.next()
+			chained.init(KindFree)
+			h.track(n, chained)
+			n = next
+		}
+	}
+
+	p.Delete(int(rid.Slot))
+	if p.Slots() == 0 {
+		p.init(KindFree)
+	}
+	h.track(rid.Page, p)
+	return nil
+}
+
+// Reset drops every record, leaving an empty heap
+func (h *Heap) Reset() {
+	h.pool.discard(h.file, 0)
+	h.pages = 0
+	h.fsm.truncate(0)
+	h.fsmReady = true
+}
+
+// Commit durably writes the changes made since the last commit, together with side
+// files (named relative to the heap's directory) that must change with them
+// Free pages at the end of the file are given back to the file system.
+func (h *Heap) Commit(files map[string][]byte) error {
+	for h.fsmReady && h.pages > 0 && h.fsm.empty(h.pages-1) {
+		h.pages--
+	}
+	h.fsm.truncate(h.pages)
+	return h.pool.flush(h.file, h.pages, files)
+}
+
+// Close closes the data file, discarding changes that were not committed
+func (h *Heap) Close() error {
+	h.pool.discard(h.file, 0)
+	return h.file.close()
+}
+
+// resolve returns the record a stored slot holds, reading its overflow chain if needed
+func (h *Heap) resolve(stored []byte) ([]byte, error) {
+	if len(stored) == 0 {
+		return nil, fmt.Errorf("empty record")
+	}
+	if stored[0] == tagInline {
+		return stored[1:], nil
+	}
+	if stored[0] != tagOverflow || len(stored) != 9 {
+		return nil, fmt.Errorf("unknown record format")
+	}
+
+	length := int(binary.LittleEndian.Uint32(stored[5:]))
+	record := make([]byte, 0, length)
+	for n := binary.LittleEndian.Uint32(stored[1:]); n != noPage; {
+		if n >= h.pages {
+			return nil, fmt.Errorf("overflow page %d is past the end of the file", n)
+		}
+		p, err := h.pool.get(h.file, n, false)
+		if err != nil {
+			return nil, err
+		}
+		if p.Kind() != KindOverflow {
+			return nil, fmt.Errorf("page %d is not an overflow page", n)
+		}
+		record = append(record, p.payload()...)
+		n = p.next()
+	}
+	if len(record) != length {
+		return nil, fmt.Errorf("overflow chain holds %d bytes, expected %d", len(record), length)
+	}
+	return record, nil
+}
+
+// writeChain stores a record across overflow pages and returns the first page
+func (h *Heap) writeChain(record []byte) (uint32, error) {
+	count := (len(record) + overflowCapacity - 1) / overflowCapacity
+	pages := make([]Page, count)
+	numbers := make([]uint32, count)
+	for i := range pages {
+		if n, ok := h.fsm.findEmpty(); ok {
+			p, err := h.pool.get(h.file, n, true)
+			if err != nil {
+				return 0, err
+			}
+			p.init(KindOverflow)
+			pages[i], numbers[i] = p, n
+		} else {
+			numbers[i], pages[i] = h.appendPage(KindOverflow)
+		}
+		h.fsm.set(numbers[i], 0, false)
+	}
+
+	for i, p := range pages {
+		end := min((i+1)*overflowCapacity, len(record))
+		p.setPayload(record[i*overflowCapacity : end])
+		if i+1 < count {
+			p.setNext(numbers[i+1])
+		}
+	}
+	return numbers[0], nil
+}
+
+// appendPage adds a page at the end of the heap
+func (h *Heap) appendPage(kind Kind) (uint32, Page) {
+	n := h.pages
+	h.pages++
+	return n, h.pool.allocate(h.file, n, kind)
+}
+
+// track records a page's free space in the free space map
+func (h *Heap) track(n uint32, p Page) {
+	switch p.Kind() {
+	case KindFree:
+		h.fsm.set(n, emptyFree, true)
+	case KindHeap:
+		h.fsm.set(n, p.FreeSpace(), false)
+	default:
+		h.fsm.set(n, 0, false)
+	}
+}
+
+// loadFSM builds the free space map from the page headers unless a scan already built it
+func (h *Heap) loadFSM() error {
+	if h.fsmReady {
+		return nil
+	}
+	for n := uint32(0); n < h.pages; n++ {
+		p, err := h.pool.get(h.file, n, false)
+		if err != nil {
+			return err
+		}
+		h.track(n, p)
+	}
+	h.fsmReady = true
+	return nil
+}
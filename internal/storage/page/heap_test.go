@@ -0,0 +1,201 @@
+package page
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// readAll returns a heap's records by RID
+func readAll(t *testing.T, h *Heap) map[RID]string {
+	t.Helper()
+	records := make(map[RID]string)
+	if err := h.Scan(func(rid RID, record []byte) error {
+		records[rid] = string(record)
+		return nil
+	}); err != nil {
+		t.Fatalf("Scan failed: %v", err)
+	}
+	return records
+}
+
+func TestSlottedPage(t *testing.T) {
+	p := newPage(KindHeap)
+	a, _ := p.Insert([]byte("alpha"))
+	b, _ := p.Insert([]byte("beta"))
+	c, _ := p.Insert([]byte("gamma"))
+
+	p.Delete(b)
+	if got, _ := p.Record(a); string(got) != "alpha" {
+		t.Errorf("Expected alpha in slot %d, got %q", a, got)
+	}
+	if _, ok := p.Record(b); ok {
+		t.Errorf("Expected slot %d to be empty", b)
+	}
+
+	// The empty slot is reused and compaction keeps slot numbers
+	d, _ := p.Insert([]byte("delta"))
+	if d != b {
+		t.Errorf("Expected slot %d to be reused, got %d", b, d)
+	}
+	p.compact()
+	for slot, want := range map[int]string{a: "alpha", c: "gamma", d: "delta"} {
+		if got, _ := p.Record(slot); string(got) != want {
+			t.Errorf("Expected %s in slot %d after compaction, got %q", want, slot, got)
+		}
+	}
+
+	// A page fills up, and accepts records again once space is freed
+	p = newPage(KindHeap)
+	record := bytes.Repeat([]byte("x"), 1000)
+	var slots []int
+	for {
+		slot, ok := p.Insert(record)
+		if !ok {
+			break
+		}
+		slots = append(slots, slot)
+	}
+	if len(slots) != 8 {
+		t.Errorf("Expected 8 records of 1000 bytes per page, got %d", len(slots))
+	}
+	p.Delete(slots[3])
+	if _, ok := p.Insert(record); !ok {
+		t.Error("Expected the freed space to be reused")
+	}
+}
+
+func TestHeap(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "data.pages")
+	pool := NewBufferPool(4)
+
+	h, err := OpenHeap(path, pool)
+	if err != nil {
+		t.Fatalf("OpenHeap failed: %v", err)
+	}
+	want := make(map[RID]string)
+	for i := 0; i < 500; i++ {
+		record := fmt.Sprintf("record %d %s", i, bytes.Repeat([]byte("."), i%50))
+		rid, err := h.Insert([]byte(record))
+		if err != nil {
+			t.Fatalf("Insert failed: %v", err)
+		}
+		want[rid] = record
+	}
+
+	// Records longer than a page are chained through overflow pages
+	large := string(bytes.Repeat([]byte("0123456789"), 3000))
+	largeRID, err := h.Insert([]byte(large))
+	if err != nil {
+		t.Fatalf("Insert failed: %v", err)
+	}
+	want[largeRID] = large
+
+	if err := h.Commit(nil); err != nil {
+		t.Fatalf("Commit failed: %v", err)
+	}
+	if err := h.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	// Reopen through a small pool, so pages are evicted and read back
+	h, err = OpenHeap(path, pool)
+	if err != nil {
+		t.Fatalf("OpenHeap failed: %v", err)
+	}
+	if got := readAll(t, h); fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Fatalf("Expected %d records back, got %d", len(want), len(got))
+	}
+	for _, rid := range []RID{{Page: 0, Slot: 0}, largeRID} {
+		if got, err := h.Get(rid); err != nil || string(got) != want[rid] {
+			t.Errorf("Expected Get(%v) to return its record, got %d bytes (%v)", rid, len(got), err)
+		}
+	}
+
+	// Deleting frees pages for reuse; uncommitted changes are lost on close
+	pages := h.Pages()
+	if err := h.Delete(largeRID); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	delete(want, largeRID)
+	if _, err := h.Insert(bytes.Repeat([]byte("y"), 9000)); err != nil {
+		t.Fatalf("Insert failed: %v", err)
+	}
+	if h.Pages() != pages {
+		t.Errorf("Expected freed overflow pages to be reused, heap grew from %d to %d pages", pages, h.Pages())
+	}
+	h.Close()
+
+	h, err = OpenHeap(path, pool)
+	if err != nil {
+		t.Fatalf("OpenHeap failed: %v", err)
+	}
+	defer h.Close()
+	if got := readAll(t, h); len(got) != len(want)+1 {
+		t.Errorf("Expected uncommitted changes to be discarded, got %d records", len(got))
+	}
+}
+
+func TestJournalRecovery(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "data.pages")
+
+	h, err := OpenHeap(path, NewBufferPool(16))
+	if err != nil {
+		t.Fatalf("OpenHeap failed: %v", err)
+	}
+	if _, err := h.Insert([]byte("committed")); err != nil {
+		t.Fatalf("Insert failed: %v", err)
+	}
+	if err := h.Commit(map[string][]byte{"meta.json": []byte("v1")}); err != nil {
+		t.Fatalf("Commit failed: %v", err)
+	}
+	h.Close()
+
+	// A complete journal left by a crash is applied on open
+	p := newPage(KindHeap)
+	p.Insert(append([]byte{tagInline}, "replayed"...))
+	p.seal()
+	j := &journal{pages: 2, images: map[uint32]Page{1: p}, files: map[string][]byte{"meta.json": []byte("v2")}}
+	if err := j.write(path + ".journal"); err != nil {
+		t.Fatalf("Failed to write journal: %v", err)
+	}
+
+	h, err = OpenHeap(path, NewBufferPool(16))
+	if err != nil {
+		t.Fatalf("OpenHeap failed: %v", err)
+	}
+	got := readAll(t, h)
+	h.Close()
+	if got[RID{Page: 0}] != "committed" || got[RID{Page: 1}] != "replayed" {
+		t.Errorf("Expected the journal to be replayed, got %v", got)
+	}
+	if meta, _ := os.ReadFile(filepath.Join(dir, "meta.json")); string(meta) != "v2" {
+		t.Errorf("Expected meta.json to be replaced by the journal, got %q", meta)
+	}
+	if _, err := os.Stat(path + ".journal"); !os.IsNotExist(err) {
+		t.Error("Expected the journal to be removed")
+	}
+
+	// A torn journal is discarded without touching the data file
+	if err := j.write(path + ".journal"); err != nil {
+		t.Fatalf("Failed to write journal: %v", err)
+	}
+	content, _ := os.ReadFile(path + ".journal")
+	os.WriteFile(path+".journal", content[:len(content)-10], 0644)
+	os.WriteFile(filepath.Join(dir, "meta.json"), []byte("v3"), 0644)
+
+	h, err = OpenHeap(path, NewBufferPool(16))
+	if err != nil {
+		t.Fatalf("OpenHeap failed: %v", err)
+	}
+	defer h.Close()
+	if got := readAll(t, h); len(got) != 2 {
+		t.Errorf("Expected 2 records, got %v", got)
+	}
+	if meta, _ := os.ReadFile(filepath.Join(dir, "meta.json")); string(meta) != "v3" {
+		t.Errorf("Expected meta.json to be untouched, got %q", meta)
+	}
+}
@@ -0,0 +1,239 @@
+package page
+
+import (
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+)
+
+// PageSize is the size in bytes of every page in a data file
+const PageSize = 8192
+
+const (
+	headerSize = 16 // checksum(4) kind(1) unused(1) slots(2) freeEnd(2) next(4) length(2)
+	slotSize   = 4  // offset(2) length(2)
+
+	// overflowCapacity is the payload an overflow page holds
+	overflowCapacity = PageSize - headerSize
+
+	// emptyFree is the space a page with no records offers a new record
+	emptyFree = PageSize - headerSize - slotSize
+)
+
+// noPage ends an overflow chain
+const noPage = ^uint32(0)
+
+// Kind identifies what a page holds
+type Kind uint8
+
+const (
+	KindFree     Kind = iota // Holds nothing; can be reused
+	KindHeap                 // Slotted page of records
+	KindOverflow             // One part of a record too large for a heap page
+)
+
+// Page is one PageSize block of a data file
+//
+// Heap pages are slotted: the header is followed by a directory of slots growing
+// towards the end of the page, and record bytes are packed from the end of the page
+// backwards. A record is addressed by its slot number, which stays the same when the
+// page is compacted; a slot with offset 0 is empty and is reused by later inserts.
+// Overflow pages hold a run of payload bytes and the number of the next page in the chain.
+type Page []byte
+
+// newPage returns a zeroed page of the given kind
+func newPage(kind Kind) Page {
+	p := make(Page, PageSize)
+	p.init(kind)
+	return p
+}
+
+// init clears the page and sets it up as an empty page of the given kind
+func (p Page) init(kind Kind) {
+	clear(p)
+	p[4] = byte(kind)
+	p.setFreeEnd(PageSize)
+	p.setNext(noPage)
+}
+
+// Kind returns what the page holds
+func (p Page) Kind() Kind {
+	return Kind(p[4])
+}
+
+// Slots returns the size of the slot directory, including empty slots
+func (p Page) Slots() int {
+	return int(binary.LittleEndian.Uint16(p[6:]))
+}
+
+func (p Page) setSlots(n int) {
+	binary.LittleEndian.PutUint16(p[6:], uint16(n))
+}
+
+func (p Page) freeEnd() int {
+	return int(binary.LittleEndian.Uint16(p[8:]))
+}
+
+func (p Page) setFreeEnd(end int) {
+	binary.LittleEndian.PutUint16(p[8:], uint16(end))
+}
+
+func (p Page) next() uint32 {
+	return binary.LittleEndian.Uint32(p[10:])
+}
+
+func (p Page) setNext(n uint32) {
+	binary.LittleEndian.PutUint32(p[10:], n)
+}
+
+func (p Page) slot(i int) (offset, length int) {
+	at := headerSize + i*slotSize
+	return int(binary.LittleEndian.Uint16(p[at:])), int(binary.LittleEndian.Uint16(p[at+2:]))
+}
+
+func (p Page) setSlot(i, offset, length int) {
+	at := headerSize + i*slotSize
+	binary.LittleEndian.PutUint16(p[at:], uint16(offset))
+	binary.LittleEndian.PutUint16(p[at+2:], uint16(length))
+}
+
+// Record returns the bytes of the record in a slot, or false if the slot is empty
+// The bytes alias the page and are only valid until it changes.
+func (p Page) Record(slot int) ([]byte, bool) {
+	if slot < 0 || slot >= p.Slots() {
+		return nil, false
+	}
+	offset, length := p.slot(slot)
+	if offset == 0 {
+		return nil, false
+	}
+	return p[offset : offset+length], true
+}
+
+// Live returns the number of records on the page
+func (p Page) Live() int {
+	live := 0
+	for i := 0; i < p.Slots(); i++ {
+		if offset, _ := p.slot(i); offset != 0 {
+			live++
+		}
+	}
+	return live
+}
+
+// used returns the bytes taken by the header, the slot directory and the records
+func (p Page) used() int {
+	used := headerSize + p.Slots()*slotSize
+	for i := 0; i < p.Slots(); i++ {
+		_, length := p.slot(i)
+		used += length
+	}
+	return used
+}
+
+// FreeSpace returns the largest record Insert is guaranteed to accept
+// It counts space freed by deletes, which Insert reclaims by compacting the page,
+// and reserves room for a new slot.
+func (p Page) FreeSpace() int {
+	return max(PageSize-p.used()-slotSize, 0)
+}
+
+// Insert stores a record on the page and returns its slot number, or false if
+// the page does not have room for it
+func (p Page) Insert(record []byte) (int, bool) {
+	// Reuse the first empty slot, or grow the directory
+	slot := p.Slots()
+	for i := 0; i < p.Slots(); i++ {
+		if offset, _ := p.slot(i); offset == 0 {
+			slot = i
+			break
+		}
+	}
+	grow := 0
+	if slot == p.Slots() {
+		grow = slotSize
+	}
+	if PageSize-p.used() < len(record)+grow {
+		return 0, false
+	}
+	if p.freeEnd()-(headerSize+p.Slots()*slotSize+grow) < len(record) {
+		p.compact()
+	}
+
+	offset := p.freeEnd() - len(record)
+	copy(p[offset:], record)
+	p.setFreeEnd(offset)
+	if slot == p.Slots() {
+		p.setSlots(slot + 1)
+	}
+	p.setSlot(slot, offset, len(record))
+	return slot, true
+}
+
+// Delete empties a slot
+// Empty slots at the end of the directory are dropped; the record bytes are
+// reclaimed when the page is next compacted.
+func (p Page) Delete(slot int) {
+	if slot < 0 || slot >= p.Slots() {
+		return
+	}
+	p.setSlot(slot, 0, 0)
+
+	n := p.Slots()
+	for n > 0 {
+		if offset, _ := p.slot(n - 1); offset != 0 {
+			break
+		}
+		n--
+	}
+	p.setSlots(n)
+	if n == 0 {
+		p.setFreeEnd(PageSize)
+	}
+}
+
+// compact packs the records against the end of the page, keeping their slot numbers
+func (p Page) compact() {
+	records := make([][]byte, p.Slots())
+	for i := range records {
+		if record, ok := p.Record(i); ok {
+			records[i] = append([]byte(nil), record...)
+		}
+	}
+
+	end := PageSize
+	for i, record := range records {
+		if record == nil {
+			continue
+		}
+		end -= len(record)
+		copy(p[end:], record)
+		p.setSlot(i, end, len(record))
+	}
+	p.setFreeEnd(end)
+}
+
+// payload returns the bytes an overflow page holds
+func (p Page) payload() []byte {
+	length := int(binary.LittleEndian.Uint16(p[14:]))
+	return p[headerSize : headerSize+length]
+}
+
+// setPayload fills an overflow page with part of a record
+func (p Page) setPayload(b []byte) {
+	n := copy(p[headerSize:], b)
+	binary.LittleEndian.PutUint16(p[14:], uint16(n))
+}
+
+// seal stores the page checksum, computed over everything after it
+func (p Page) seal() {
+	binary.LittleEndian.PutUint32(p[0:], crc32.ChecksumIEEE(p[4:]))
+}
+
+// verify checks the page checksum
+func (p Page) verify() error {
+	if binary.LittleEndian.Uint32(p[0:]) != crc32.ChecksumIEEE(p[4:]) {
+		return fmt.Errorf("checksum mismatch")
+	}
+	return nil
+}
@@ -0,0 +1,139 @@
+package page
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+
+	"github.com/leengari/mini-rdbms/internal/domain/data"
+	"github.com/leengari/mini-rdbms/internal/domain/schema"
+)
+
+// EncodeRow appends the binary form of a row to buf
+// The row is stored in schema column order: a bitmap with one bit per column set for
+// NULLs, then each non-NULL value. INT is a signed varint, FLOAT 8 bytes, BOOL one
+// byte, and TEXT, EMAIL, DATE and TIME a varint length and the bytes. Values of columns
+// outside the schema are not stored.
+func EncodeRow(buf []byte, columns []schema.Column, row data.Row) ([]byte, error) {
+	bitmap := len(buf)
+	buf = append(buf, make([]byte, (len(columns)+7)/8)...)
+
+	for i, col := range columns {
+		val := row.Data[col.Name]
+		if val == nil {
+			buf[bitmap+i/8] |= 1 << (i % 8)
+			continue
+		}
+
+		switch col.Type {
+		case schema.ColumnTypeInt:
+			n, ok := intValue(val)
+			if !ok {
+				return nil, fmt.Errorf("column %s: cannot store %T as %s", col.Name, val, col.Type)
+			}
+			buf = binary.AppendVarint(buf, n)
+		case schema.ColumnTypeFloat:
+			f, ok := floatValue(val)
+			if !ok {
+				return nil, fmt.Errorf("column %s: cannot store %T as %s", col.Name, val, col.Type)
+			}
+			buf = binary.LittleEndian.AppendUint64(buf, math.Float64bits(f))
+		case schema.ColumnTypeBool:
+			b, ok := val.(bool)
+			if !ok {
+				return nil, fmt.Errorf("column %s: cannot store %T as %s", col.Name, val, col.Type)
+			}
+			if b {
+				buf = append(buf, 1)
+			} else {
+				buf = append(buf, 0)
+			}
+		case schema.ColumnTypeText, schema.ColumnTypeEmail, schema.ColumnTypeDate, schema.ColumnTypeTime:
+			s, ok := val.(string)
+			if !ok {
+				return nil, fmt.Errorf("column %s: cannot store %T as %s", col.Name, val, col.Type)
+			}
+			buf = binary.AppendUvarint(buf, uint64(len(s)))
+			buf = append(buf, s...)
+		default:
+			return nil, fmt.Errorf("column %s: unsupported type %s", col.Name, col.Type)
+		}
+	}
+	return buf, nil
+}
+
+// DecodeRow restores a row encoded by EncodeRow with the same columns
+func DecodeRow(b []byte, columns []schema.Column) (data.Row, error) {
+	bitmapSize := (len(columns) + 7) / 8
+	if len(b) < bitmapSize {
+		return data.Row{}, fmt.Errorf("row is truncated")
+	}
+	bitmap, b := b[:bitmapSize], b[bitmapSize:]
+
+	values := make(map[string]interface{}, len(columns))
+	for i, col := range columns {
+		if bitmap[i/8]&(1<<(i%8)) != 0 {
+			values[col.Name] = nil
+			continue
+		}
+
+		switch col.Type {
+		case schema.ColumnTypeInt:
+			n, size := binary.Varint(b)
+			if size <= 0 {
+				return data.Row{}, fmt.Errorf("column %s: bad integer", col.Name)
+			}
+			values[col.Name], b = n, b[size:]
+		case schema.ColumnTypeFloat:
+			if len(b) < 8 {
+				return data.Row{}, fmt.Errorf("column %s: row is truncated", col.Name)
+			}
+			values[col.Name], b = math.Float64frombits(binary.LittleEndian.Uint64(b)), b[8:]
+		case schema.ColumnTypeBool:
+			if len(b) < 1 {
+				return data.Row{}, fmt.Errorf("column %s: row is truncated", col.Name)
+			}
+			values[col.Name], b = b[0] != 0, b[1:]
+		case schema.ColumnTypeText, schema.ColumnTypeEmail, schema.ColumnTypeDate, schema.ColumnTypeTime:
+			n, size := binary.Uvarint(b)
+			if size <= 0 || uint64(len(b)-size) < n {
+				return data.Row{}, fmt.Errorf("column %s: row is truncated", col.Name)
+			}
+			values[col.Name], b = string(b[size:size+int(n)]), b[size+int(n):]
+		default:
+			return data.Row{}, fmt.Errorf("column %s: unsupported type %s", col.Name, col.Type)
+		}
+	}
+	if len(b) != 0 {
+		return data.Row{}, fmt.Errorf("row has %d unexpected trailing bytes", len(b))
+	}
+	return data.NewRow(values), nil
+}
+
+// intValue converts an in-memory INT value to int64
+func intValue(val interface{}) (int64, bool) {
+	switch v := val.(type) {
+	case int64:
+		return v, true
+	case int:
+		return int64(v), true
+	case float64:
+		return int64(v), v == math.Trunc(v)
+	default:
+		return 0, false
+	}
+}
+
+// floatValue converts an in-memory FLOAT value to float64
+func floatValue(val interface{}) (float64, bool) {
+	switch v := val.(type) {
+	case float64:
+		return v, true
+	case int64:
+		return float64(v), true
+	case int:
+		return float64(v), true
+	default:
+		return 0, false
+	}
+}
@@ -0,0 +1,294 @@
+package wal
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/leengari/mini-rdbms/internal/domain/schema"
+	"github.com/leengari/mini-rdbms/internal/domain/transaction"
+)
+
+// FileName is the name of the log file inside a database directory
+const FileName = "wal.log"
+
+// RecordVersion is the version of the records this build writes and replays
+// Version 1 changes refer to rows by row id. Records of any other version
+// (including those without one) fail recovery.
+const RecordVersion = 1
+
+// Record is one committed statement (or transaction) in the log
+// Stored as a single JSON line; LSNs increase monotonically per database
+type Record struct {
+	LSN     int64                `json:"lsn"`
+	TxID    string               `json:"tx_id"`
+	Version int                  `json:"version,omitempty"`
+	Changes []transaction.Change `json:"changes"`
+}
+
+// Log is an append-only write-ahead log for a single database
+//
+// Writers call BeginWrite before mutating tables and EndWrite once their changes
+// are committed or rolled back. This allows one writing transaction per database
+// at a time (so the log holds changes in the order they were applied), and
+// a checkpoint never runs between a change and its log record.
+type Log struct {
+	mu      sync.Mutex // serializes appends and truncation
+	writeMu sync.Mutex // held by the active writer or a checkpoint
+	path    string
+	file    *os.File
+	lastLSN int64
+	pending int               // changes logged since the last checkpoint
+	notify  func(pending int) // called after each commit (see OnCommit)
+}
+
+// Recover replays the database's log over its loaded snapshot and opens the log for appending
+// Records already reflected in a table's data.json (LSN <= table.LastLSN) are skipped.
+// A torn final record (crash mid-write) is discarded and cut from the file.
+func Recover(db *schema.Database) (*Log, error) {
+	path := filepath.Join(db.Path, FileName)
+
+	records, validSize, err := readRecords(path)
+	if err != nil {
+		return nil, err
+	}
+
+	lastLSN := int64(0)
+	for _, table := range db.Tables {
+		if table.LastLSN > lastLSN {
+			lastLSN = table.LastLSN
+		}
+	}
+
+	replayed, pending := 0, 0
+	for _, rec := range records {
+		if rec.Version != RecordVersion {
+			return nil, fmt.Errorf("wal record at lsn %d has unsupported version %d", rec.LSN, rec.Version)
+		}
+		pending += len(rec.Changes)
+		if rec.LSN > lastLSN {
+			lastLSN = rec.LSN
+		}
+
+		// Group the record's changes by table, preserving order
+		byTable := make(map[string][]transaction.Change)
+		for _, change := range rec.Changes {
+			byTable[change.Table] = append(byTable[change.Table], change)
+		}
+
+		for tableName, changes := range byTable {
+			table, ok := db.Tables[tableName]
+			if !ok {
+				slog.Warn("wal record for unknown table skipped",
+					slog.String("database", db.Name),
+					slog.String("table", tableName),
+					slog.Int64("lsn", rec.LSN))
+				continue
+			}
+			if rec.LSN <= table.LastLSN {
+				continue
+			}
+			if err := table.ApplyChanges(changes); err != nil {
+				return nil, fmt.Errorf("wal replay failed at lsn %d: %w", rec.LSN, err)
+			}
+			table.LastLSN = rec.LSN
+		}
+		replayed++
+	}
+
+	if replayed > 0 {
+		slog.Info("wal replayed",
+			slog.String("database", db.Name),
+			slog.Int("records", replayed),
+			slog.Int64("last_lsn", lastLSN))
+	}
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open wal: %w", err)
+	}
+
+	// Drop any torn tail so new records start on a clean line
+	if err := file.Truncate(validSize); err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to truncate wal: %w", err)
+	}
+	if _, err := file.Seek(validSize, io.SeekStart); err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to seek wal: %w", err)
+	}
+
+	return &Log{path: path, file: file, lastLSN: lastLSN, pending: pending}, nil
+}
+
+// readRecords reads every complete record in the log file
+// Returns the records and the byte size of the valid prefix of the file
+func readRecords(path string) ([]Record, int64, error) {
+	content, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, 0, nil
+	}
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to read wal: %w", err)
+	}
+
+	var records []Record
+	offset := int64(0)
+	reader := bufio.NewReader(bytes.NewReader(content))
+	for {
+		line, err := reader.ReadBytes('\n')
+		if err == io.EOF {
+			if len(line) > 0 {
+				slog.Warn("discarding torn wal record", slog.String("path", path), slog.Int64("offset", offset))
+			}
+			break
+		}
+
+		var rec Record
+		if jsonErr := json.Unmarshal(line, &rec); jsonErr != nil {
+			// Only the final record may be incomplete
+			if offset+int64(len(line)) == int64(len(content)) {
+				slog.Warn("discarding torn wal record", slog.String("path", path), slog.Int64("offset", offset))
+				break
+			}
+			return nil, 0, fmt.Errorf("corrupt wal record at offset %d: %w", offset, jsonErr)
+		}
+
+		records = append(records, rec)
+		offset += int64(len(line))
+	}
+
+	return records, offset, nil
+}
+
+// BeginWrite waits for exclusive write access to the database
+func (l *Log) BeginWrite() {
+	l.writeMu.Lock()
+}
+
+// EndWrite releases write access acquired with BeginWrite
+func (l *Log) EndWrite() {
+	l.writeMu.Unlock()
+}
+
+// Commit durably appends the transaction's changes as one record
+// and stamps the new LSN on every table it touched.
+// The caller must hold write access (BeginWrite).
+func (l *Log) Commit(db *schema.Database, tx *transaction.Transaction) error {
+	if len(tx.Changes) == 0 {
+		return nil
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.file == nil {
+		return fmt.Errorf("wal is closed")
+	}
+
+	rec := Record{LSN: l.lastLSN + 1, TxID: tx.ID, Version: RecordVersion, Changes: tx.Changes}
+	line, err := json.Marshal(rec)
+	if err != nil {
+		return fmt.Errorf("failed to encode wal record: %w", err)
+	}
+	line = append(line, '\n')
+
+	if _, err := l.file.Write(line); err != nil {
+		return fmt.Errorf("failed to write wal record: %w", err)
+	}
+	if err := l.file.Sync(); err != nil {
+		return fmt.Errorf("failed to sync wal: %w", err)
+	}
+	l.lastLSN = rec.LSN
+	l.pending += len(tx.Changes)
+
+	for _, change := range tx.Changes {
+		if table, ok := db.Tables[change.Table]; ok {
+			table.Lock()
+			table.LastLSN = rec.LSN
+			table.Unlock()
+		}
+	}
+
+	if l.notify != nil {
+		l.notify(l.pending)
+	}
+	return nil
+}
+
+// OnCommit registers a function called after each commit with the number of
+// changes logged since the last checkpoint. It runs while the log is locked,
+// so it must not block or use the log.
+func (l *Log) OnCommit(fn func(pending int)) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.notify = fn
+}
+
+// Pending returns the number of changes logged since the last checkpoint
+func (l *Log) Pending() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.pending
+}
+
+// Checkpoint runs save (which must rewrite the table files) and then truncates the log
+// Waits for the active writer to finish and blocks new ones until done. Does
+// nothing once the log is closed (the database was dropped or renamed meanwhile).
+func (l *Log) Checkpoint(save func() error) error {
+	l.writeMu.Lock()
+	defer l.writeMu.Unlock()
+
+	if l.closed() {
+		return nil
+	}
+	if err := save(); err != nil {
+		return err
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.file == nil {
+		return nil
+	}
+	if err := l.file.Truncate(0); err != nil {
+		return fmt.Errorf("failed to truncate wal: %w", err)
+	}
+	if _, err := l.file.Seek(0, io.SeekStart); err != nil {
+		return fmt.Errorf("failed to seek wal: %w", err)
+	}
+	if err := l.file.Sync(); err != nil {
+		return fmt.Errorf("failed to sync wal: %w", err)
+	}
+	l.pending = 0
+
+	slog.Debug("wal checkpoint complete", slog.String("path", l.path), slog.Int64("last_lsn", l.lastLSN))
+	return nil
+}
+
+// closed reports whether the log has been closed
+func (l *Log) closed() bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.file == nil
+}
+
+// Close closes the log file
+func (l *Log) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.file == nil {
+		return nil
+	}
+	err := l.file.Close()
+	l.file = nil
+	return err
+}
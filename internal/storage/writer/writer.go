@@ -8,6 +8,7 @@ import (
 	"path/filepath"
 	"sort"
 
+	"github.com/leengari/mini-rdbms/internal/domain/data"
 	"github.com/leengari/mini-rdbms/internal/domain/schema"
 	"github.com/leengari/mini-rdbms/internal/domain/transaction"
 	"github.com/leengari/mini-rdbms/internal/storage/metadata"
@@ -30,11 +31,71 @@ func SaveTable(t *schema.Table, tx *transaction.Transaction) error {
 		slog.Debug("SaveTable operation", "table", tableName, "tx_id", tx.ID)
 	}
 
-	// 1. Prepare meta (updated from current in-memory state)
+	// 1. Marshal meta (updated from current in-memory state)
+	metaBytes, err := MarshalTableMeta(t)
+	if err != nil {
+		return err
+	}
+
+	// 2. Marshal data (rows, each with its row id)
+	dataBytes, err := json.MarshalIndent(storedRows(t.Rows), "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal rows for %s: %w", tableName, err)
+	}
+
+	// 3. Write both files using temp + atomic rename
+	// data.json goes first: meta.json carries last_lsn, so it must never be newer than
+	// the rows it describes. Both are synced before the rename that replaces the old
+	// file, and the directory after it, so the log is only truncated once they are on
+	// disk. loader.LoadTable finishes a save cut short between the two renames.
+	dataPath := filepath.Join(basePath, "data.json")
+	metaPath := filepath.Join(basePath, "meta.json")
+	if err := writeTempFile(dataPath, dataBytes); err != nil {
+		return fmt.Errorf("failed to write data.json for table %s: %w", tableName, err)
+	}
+	if err := writeTempFile(metaPath, metaBytes); err != nil {
+		return fmt.Errorf("failed to write meta.json for table %s: %w", tableName, err)
+	}
+	for _, path := range []string{dataPath, metaPath} {
+		if err := replaceWithTempFile(path); err != nil {
+			return fmt.Errorf("failed to save %s for table %s: %w", filepath.Base(path), tableName, err)
+		}
+	}
+
+	slog.Info("Table saved successfully",
+		slog.String("table", tableName),
+		slog.String("path", basePath),
+		slog.Int64("last_insert_id", t.LastInsertID),
+		slog.Int("row_count", len(t.Rows)),
+	)
+
+	return nil
+}
+
+// storedRows returns the data.json form of rows: each row's values plus its
+// row id under schema.RowIDColumn
+func storedRows(rows []data.Row) []map[string]interface{} {
+	stored := make([]map[string]interface{}, len(rows))
+	for i, row := range rows {
+		values := make(map[string]interface{}, len(row.Data)+1)
+		for k, v := range row.Data {
+			values[k] = v
+		}
+		values[schema.RowIDColumn] = row.ID
+		stored[i] = values
+	}
+	return stored
+}
+
+// MarshalTableMeta builds the meta.json contents of a table from its in-memory state
+// The caller must hold the table lock.
+func MarshalTableMeta(t *schema.Table) ([]byte, error) {
 	meta := metadata.TableMeta{
-		Name:         tableName,
+		Name:         t.Name,
 		LastInsertID: t.LastInsertID,
-		RowCount:     int64(len(t.Rows)), 
+		LastLSN:      t.LastLSN,
+		RowCount:     int64(len(t.Rows)),
+		NextRowID:    t.NextRowID,
 		Columns:      make([]metadata.ColumnMeta, len(t.Schema.Columns)),
 	}
 
@@ -46,53 +107,24 @@ func SaveTable(t *schema.Table, tx *transaction.Transaction) error {
 			Unique:        col.Unique,
 			NotNull:       col.NotNull,
 			AutoIncrement: col.AutoIncrement,
+			Default:       col.Default,
 		}
 	}
 
-	// 2. Marshal meta
-	metaBytes, err := json.MarshalIndent(meta, "", "  ")
-	if err != nil {
-		return fmt.Errorf("failed to marshal table meta for %s: %w", tableName, err)
+	for _, idx := range t.OrderedIndexes {
+		meta.Indexes = append(meta.Indexes, metadata.IndexMeta{
+			Name:    idx.Name,
+			Columns: idx.Columns,
+			Unique:  idx.Unique,
+		})
 	}
+	sort.Slice(meta.Indexes, func(i, j int) bool { return meta.Indexes[i].Name < meta.Indexes[j].Name })
 
-	// 3. Marshal data (rows)
-	dataBytes, err := json.MarshalIndent(t.Rows, "", "  ")
+	metaBytes, err := json.MarshalIndent(meta, "", "  ")
 	if err != nil {
-		return fmt.Errorf("failed to marshal rows for %s: %w", tableName, err)
-	}
-
-	// 4. Write both files using temp + atomic rename
-	files := []struct {
-		path string
-		data []byte
-		name string
-	}{
-		{filepath.Join(basePath, "meta.json"), metaBytes, "meta.json"},
-		{filepath.Join(basePath, "data.json"), dataBytes, "data.json"},
+		return nil, fmt.Errorf("failed to marshal table meta for %s: %w", t.Name, err)
 	}
-
-	for _, f := range files {
-		tmpPath := f.path + ".tmp"
-
-		// Write to temp
-		if err := os.WriteFile(tmpPath, f.data, 0644); err != nil {
-			return fmt.Errorf("failed to write temp file %s for table %s: %w", f.name, tableName, err)
-		}
-
-		// Atomic replace
-		if err := os.Rename(tmpPath, f.path); err != nil {
-			return fmt.Errorf("failed to rename temp → %s for table %s: %w", f.name, tableName, err)
-		}
-	}
-
-	slog.Info("Table saved successfully",
-		slog.String("table", tableName),
-		slog.String("path", basePath),
-		slog.Int64("last_insert_id", t.LastInsertID),
-		slog.Int("row_count", len(t.Rows)),
-	)
-
-	return nil
+	return metaBytes, nil
 }
 
 // SaveDatabase saves all tables and database metadata
@@ -116,66 +148,96 @@ func SaveDatabase(db *schema.Database, tx *transaction.Transaction) error {
 		}
 	}
 
-	// 2. Build table list from current state
+	// 2. Save database meta.json
+	if err := SaveDatabaseMeta(db); err != nil {
+		return err
+	}
+
+	slog.Info("Database saved successfully",
+		slog.String("name", db.Name),
+		slog.String("path", db.Path),
+		slog.Int("table_count", len(db.Tables)),
+	)
+
+	return nil
+}
+
+// SaveDatabaseMeta rewrites the database meta.json from the current table list
+// Used on its own when tables are created or dropped
+func SaveDatabaseMeta(db *schema.Database) error {
+	return SaveDatabaseMetaAs(db, metadata.StorageJSON)
+}
+
+// SaveDatabaseMetaAs rewrites the database meta.json, recording the storage format
+// the database is kept in
+func SaveDatabaseMetaAs(db *schema.Database, storage string) error {
+	if db == nil {
+		return fmt.Errorf("cannot save meta for nil database")
+	}
+
+	// 1. Build table list from current state
 	tableNames := make([]string, 0, len(db.Tables))
 	for name := range db.Tables {
 		tableNames = append(tableNames, name)
 	}
 	sort.Strings(tableNames) 
 
-	// 3. Create database metadata
+	// 2. Create database metadata
 	dbMeta := metadata.DatabaseMeta{
 		Name:    db.Name,
 		Version: 1, 
 		Tables:  tableNames,
 	}
+	if storage != metadata.StorageJSON {
+		dbMeta.Storage = storage
+	}
 
-	// 4. Marshal database metadata
+	// 3. Marshal database metadata
 	metaBytes, err := json.MarshalIndent(dbMeta, "", "  ")
 	if err != nil {
 		return fmt.Errorf("failed to marshal database meta: %w", err)
 	}
 
-	// 5. Save database meta.json atomically
+	// 4. Save database meta.json atomically
 	dbMetaPath := filepath.Join(db.Path, "meta.json")
-	tmpPath := dbMetaPath + ".tmp"
-
-	if err := os.WriteFile(tmpPath, metaBytes, 0644); err != nil {
-		return fmt.Errorf("failed to write temp database meta: %w", err)
+	if err := writeTempFile(dbMetaPath, metaBytes); err != nil {
+		return fmt.Errorf("failed to write database meta.json: %w", err)
 	}
-
-	if err := os.Rename(tmpPath, dbMetaPath); err != nil {
-		return fmt.Errorf("failed to rename temp → database meta.json: %w", err)
+	if err := replaceWithTempFile(dbMetaPath); err != nil {
+		return fmt.Errorf("failed to save database meta.json: %w", err)
 	}
 
-	slog.Info("Database saved successfully",
-		slog.String("name", db.Name),
-		slog.String("path", db.Path),
-		slog.Int("table_count", len(db.Tables)),
-	)
-
 	return nil
 }
 
-// FlushTableIfDirty saves the table only if it has unsaved changes
-func FlushTableIfDirty(table *schema.Table, tx *transaction.Transaction) error {
-	// Check dirty flag (needs lock)
-	table.RLock()
-	isDirty := table.Dirty
-	table.RUnlock()
-
-	if !isDirty {
-		return nil
+// writeTempFile writes content to path + ".tmp" and syncs it to disk
+func writeTempFile(path string, content []byte) error {
+	file, err := os.OpenFile(path+".tmp", os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
 	}
-
-	if err := SaveTable(table, tx); err != nil {
+	if _, err := file.Write(content); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Sync(); err != nil {
+		file.Close()
 		return err
 	}
+	return file.Close()
+}
 
-	// Clear dirty flag
-	table.Lock()
-	table.Dirty = false
-	table.Unlock()
+// replaceWithTempFile renames path + ".tmp" over path and syncs the directory, so
+// the rename is on disk before any later write
+func replaceWithTempFile(path string) error {
+	if err := os.Rename(path+".tmp", path); err != nil {
+		return err
+	}
 
-	return nil
-}
\ No newline at end of file
+	dir, err := os.Open(filepath.Dir(path))
+	if err != nil {
+		return err
+	}
+	defer dir.Close()
+	return dir.Sync()
+}
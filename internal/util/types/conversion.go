@@ -13,6 +13,11 @@ import (
 // If literal is STRING and schema expects DATE/TIME/EMAIL, validates and converts.
 // This enables implicit type detection based on schema.
 func ConvertLiteralToSchemaType(lit *ast.Literal, schemaType schema.ColumnType) (*ast.Literal, error) {
+	// NULL is valid for every type (NOT NULL is enforced by the table)
+	if lit.Kind == ast.LiteralNull {
+		return lit, nil
+	}
+
 	// If types already match, no conversion needed
 	if TypesMatch(lit.Kind, schemaType) {
 		return lit, nil
@@ -79,6 +84,44 @@ func ConvertLiteralToSchemaType(lit *ast.Literal, schemaType schema.ColumnType)
 	}
 }
 
+// ConvertValueToSchemaType converts a computed value (e.g. the result of price * 2) to a column's type.
+// Integral numbers fit INT columns and any number fits FLOAT; strings are validated like
+// string literals, so DATE/TIME/EMAIL columns accept well-formed text. NULL passes through.
+func ConvertValueToSchemaType(value interface{}, schemaType schema.ColumnType) (interface{}, error) {
+	if value == nil {
+		return nil, nil
+	}
+
+	switch v := value.(type) {
+	case string:
+		lit, err := ConvertLiteralToSchemaType(&ast.Literal{TokenLiteralValue: v, Value: v, Kind: ast.LiteralString}, schemaType)
+		if err != nil {
+			return nil, err
+		}
+		return lit.Value, nil
+	case bool:
+		if schemaType != schema.ColumnTypeBool {
+			return nil, fmt.Errorf("expected %s, got BOOL", schemaType)
+		}
+		return v, nil
+	}
+
+	switch schemaType {
+	case schema.ColumnTypeInt:
+		if i, ok := NormalizeToInt64(value); ok {
+			return i, nil
+		}
+		return nil, fmt.Errorf("expected INT, got %v", value)
+	case schema.ColumnTypeFloat:
+		if f, ok := NormalizeToFloat(value); ok {
+			return f, nil
+		}
+		return nil, fmt.Errorf("expected FLOAT, got %v", value)
+	default:
+		return nil, fmt.Errorf("expected %s, got %v", schemaType, value)
+	}
+}
+
 // TypesMatch checks if a literal kind matches a schema column type
 func TypesMatch(kind ast.LiteralKind, schemaType schema.ColumnType) bool {
 	switch schemaType {
@@ -0,0 +1,127 @@
+package joydb
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// countPlaceholders counts the ? placeholders outside quoted strings and identifiers
+func countPlaceholders(query string) int {
+	n := 0
+	scanPlaceholders(query, func(int) { n++ })
+	return n
+}
+
+// scanPlaceholders calls fn with the byte offset of each ? outside quotes
+func scanPlaceholders(query string, fn func(offset int)) {
+	var quote byte
+	for i := 0; i < len(query); i++ {
+		c := query[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0 // a doubled quote closes and reopens, which is equivalent
+			}
+		case c == '\'' || c == '"':
+			quote = c
+		case c == '?':
+			fn(i)
+		}
+	}
+}
+
+// bindArgs substitutes the arguments for the query's ? placeholders as SQL literals
+// Only positional arguments are supported.
+func bindArgs(query string, args []driver.NamedValue) (string, error) {
+	if len(args) == 0 {
+		return query, nil
+	}
+	for _, arg := range args {
+		if arg.Name != "" {
+			return "", fmt.Errorf("joydb: named argument %q is not supported", arg.Name)
+		}
+	}
+
+	var b strings.Builder
+	last := 0
+	i := 0
+	var err error
+	scanPlaceholders(query, func(offset int) {
+		if err != nil {
+			return
+		}
+		if i >= len(args) {
+			err = fmt.Errorf("joydb: query has more placeholders than the %d arguments given", len(args))
+			return
+		}
+		var literal string
+		literal, err = sqlLiteral(args[i].Value)
+		b.WriteString(query[last:offset])
+		b.WriteString(literal)
+		last = offset + 1
+		i++
+	})
+	if err != nil {
+		return "", err
+	}
+	if i != len(args) {
+		return "", fmt.Errorf("joydb: got %d arguments for %d placeholders", len(args), i)
+	}
+	b.WriteString(query[last:])
+	return b.String(), nil
+}
+
+// sqlLiteral renders a driver value as a JoyDB literal
+func sqlLiteral(value driver.Value) (string, error) {
+	switch v := value.(type) {
+	case nil:
+		return "NULL", nil
+	case int64:
+		return strconv.FormatInt(v, 10), nil
+	case float64:
+		s := strconv.FormatFloat(v, 'f', -1, 64)
+		if !strings.Contains(s, ".") {
+			s += ".0" // keep FLOAT literals distinguishable from INT
+		}
+		return s, nil
+	case bool:
+		if v {
+			return "true", nil
+		}
+		return "false", nil
+	case string:
+		return quoteString(v), nil
+	case []byte:
+		return quoteString(string(v)), nil
+	case time.Time:
+		return timeLiteral(v)
+	default:
+		return "", fmt.Errorf("joydb: unsupported argument type %T", value)
+	}
+}
+
+// timeLiteral renders a time as a DATE or TIME literal, the two kinds of time JoyDB stores.
+// A time at midnight is a date, and a time on the zero date (as time.Parse returns for
+// "15:04:05") is a time of day. A time with both a date and a time of day, or with
+// fractions of a second, would lose part of its value and is rejected.
+func timeLiteral(t time.Time) (string, error) {
+	noDate := t.Year() <= 1 && t.YearDay() == 1
+	switch {
+	case t.Nanosecond() != 0:
+		return "", fmt.Errorf("joydb: time argument %v has fractions of a second, which DATE and TIME do not hold", t)
+	case noDate:
+		return quoteString(t.Format("15:04:05")), nil
+	case t.Hour() == 0 && t.Minute() == 0 && t.Second() == 0:
+		return quoteString(t.Format("2006-01-02")), nil
+	default:
+		return "", fmt.Errorf("joydb: time argument %v has both a date and a time of day; pass a date at midnight or a time on the zero date", t)
+	}
+}
+
+// quoteString quotes a string literal, doubling embedded single quotes
+func quoteString(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
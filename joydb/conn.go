@@ -0,0 +1,161 @@
+package joydb
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+
+	"github.com/leengari/mini-rdbms/internal/executor"
+)
+
+// conn implements driver.Conn for one session
+type conn struct {
+	session        session
+	closeConnector func() error // releases the connector of a connection opened by Driver.Open
+}
+
+var (
+	_ driver.Conn               = (*conn)(nil)
+	_ driver.ConnBeginTx        = (*conn)(nil)
+	_ driver.ExecerContext      = (*conn)(nil)
+	_ driver.QueryerContext     = (*conn)(nil)
+	_ driver.ConnPrepareContext = (*conn)(nil)
+	_ driver.Validator          = (*conn)(nil)
+)
+
+func (c *conn) Prepare(query string) (driver.Stmt, error) {
+	return c.PrepareContext(context.Background(), query)
+}
+
+// PrepareContext returns a statement bound on the client side; nothing is sent to the server
+func (c *conn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	return &stmt{conn: c, query: query, numInput: countPlaceholders(query)}, nil
+}
+
+func (c *conn) Close() error {
+	err := c.session.close()
+	if c.closeConnector != nil {
+		err = errors.Join(err, c.closeConnector())
+	}
+	return err
+}
+
+func (c *conn) Begin() (driver.Tx, error) {
+	return c.BeginTx(context.Background(), driver.TxOptions{})
+}
+
+// BeginTx starts a transaction; only the default isolation level is supported
+func (c *conn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	if opts.Isolation != driver.IsolationLevel(0) {
+		return nil, fmt.Errorf("joydb: isolation level %d is not supported", opts.Isolation)
+	}
+	if opts.ReadOnly {
+		return nil, errors.New("joydb: read-only transactions are not supported")
+	}
+	if _, err := c.run(ctx, "BEGIN"); err != nil {
+		return nil, err
+	}
+	return &tx{conn: c}, nil
+}
+
+func (c *conn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	sql, err := bindArgs(query, args)
+	if err != nil {
+		return nil, err
+	}
+	res, err := c.run(ctx, sql)
+	if err != nil {
+		return nil, err
+	}
+	return driver.RowsAffected(res.RowsAffected), nil
+}
+
+func (c *conn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	sql, err := bindArgs(query, args)
+	if err != nil {
+		return nil, err
+	}
+	res, err := c.run(ctx, sql)
+	if err != nil {
+		return nil, err
+	}
+	return newRows(res), nil
+}
+
+// IsValid reports whether the pool may reuse the connection
+func (c *conn) IsValid() bool {
+	if ns, ok := c.session.(*networkSession); ok {
+		return ns.isValid()
+	}
+	return true
+}
+
+// run executes one statement unless the context is already done
+// Statements cannot be interrupted once started.
+func (c *conn) run(ctx context.Context, sql string) (*executor.Result, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return c.session.execute(sql)
+}
+
+// stmt implements driver.Stmt; arguments are substituted into the query text
+type stmt struct {
+	conn     *conn
+	query    string
+	numInput int
+}
+
+var (
+	_ driver.StmtExecContext  = (*stmt)(nil)
+	_ driver.StmtQueryContext = (*stmt)(nil)
+)
+
+func (s *stmt) Close() error {
+	return nil
+}
+
+func (s *stmt) NumInput() int {
+	return s.numInput
+}
+
+func (s *stmt) Exec(args []driver.Value) (driver.Result, error) {
+	return s.ExecContext(context.Background(), namedValues(args))
+}
+
+func (s *stmt) Query(args []driver.Value) (driver.Rows, error) {
+	return s.QueryContext(context.Background(), namedValues(args))
+}
+
+func (s *stmt) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	return s.conn.ExecContext(ctx, s.query, args)
+}
+
+func (s *stmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	return s.conn.QueryContext(ctx, s.query, args)
+}
+
+// namedValues converts positional arguments from the legacy Stmt interface
+func namedValues(args []driver.Value) []driver.NamedValue {
+	named := make([]driver.NamedValue, len(args))
+	for i, v := range args {
+		named[i] = driver.NamedValue{Ordinal: i + 1, Value: v}
+	}
+	return named
+}
+
+// tx implements driver.Tx with COMMIT and ROLLBACK statements
+type tx struct {
+	conn *conn
+}
+
+func (t *tx) Commit() error {
+	_, err := t.conn.run(context.Background(), "COMMIT")
+	return err
+}
+
+func (t *tx) Rollback() error {
+	_, err := t.conn.run(context.Background(), "ROLLBACK")
+	return err
+}
@@ -0,0 +1,268 @@
+// Package joydb is a database/sql driver for JoyDB
+//
+// Importing it registers the "joydb" driver:
+//
+//	import _ "github.com/leengari/mini-rdbms/joydb"
+//
+//	db, err := sql.Open("joydb", "file:./databases?database=main") // embedded, in-process
+//	db, err := sql.Open("joydb", "tcp://localhost:4444/main")      // JoyDB server (--server)
+//
+// DSN formats:
+//   - file:<base path>[?database=<name>][&storage=json|page] or a bare base path: opens the
+//     databases stored under the base path inside this process, in the given storage format
+//     (default json, like the server's --storage flag). All connections to the same base path
+//     share one registry, so they see each other's changes, and must use the same format.
+//   - tcp://<host>:<port>[/<database>]: connects to a JoyDB server's JSON protocol.
+//
+// Embedded databases are checkpointed in the background while a sql.DB is open, and
+// their changed tables are written to disk when the last sql.DB for the base path closes.
+//
+// Every pooled connection is its own session, so select the database in the DSN rather
+// than with USE, and use db.Begin() rather than executing BEGIN for transactions.
+// Query arguments are bound to ? placeholders on the client side.
+package joydb
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	storageEngine "github.com/leengari/mini-rdbms/internal/storage/engine"
+	"github.com/leengari/mini-rdbms/internal/storage/manager"
+	"github.com/leengari/mini-rdbms/internal/storage/metadata"
+)
+
+// DriverName is the name the driver is registered under
+const DriverName = "joydb"
+
+func init() {
+	sql.Register(DriverName, &Driver{})
+}
+
+// Driver implements driver.Driver and driver.DriverContext
+type Driver struct{}
+
+// Open opens a new connection for the DSN
+// The connection owns its connector, and releases it when closed.
+func (d *Driver) Open(dsn string) (driver.Conn, error) {
+	dc, err := d.OpenConnector(dsn)
+	if err != nil {
+		return nil, err
+	}
+	c, err := dc.Connect(context.Background())
+	if err != nil {
+		dc.(io.Closer).Close()
+		return nil, err
+	}
+	c.(*conn).closeConnector = dc.(io.Closer).Close
+	return c, nil
+}
+
+// OpenConnector parses the DSN once for all connections of a sql.DB
+func (d *Driver) OpenConnector(dsn string) (driver.Connector, error) {
+	cfg, err := parseDSN(dsn)
+	if err != nil {
+		return nil, err
+	}
+	return &connector{driver: d, cfg: cfg}, nil
+}
+
+// config is a parsed DSN
+type config struct {
+	network  bool   // true: tcp://, false: embedded
+	address  string // host:port of the server (network mode)
+	basePath string // databases directory (embedded mode)
+	storage  string // storage format of the databases (embedded mode)
+	database string // database selected on connect (optional)
+}
+
+// parseDSN parses "file:<path>?database=<name>&storage=<format>", a bare path, or "tcp://host:port/<name>"
+func parseDSN(dsn string) (*config, error) {
+	if rest, ok := strings.CutPrefix(dsn, "tcp://"); ok {
+		address, database, _ := strings.Cut(rest, "/")
+		if address == "" {
+			return nil, fmt.Errorf("joydb: missing host:port in DSN %q", dsn)
+		}
+		return &config{network: true, address: address, database: database}, nil
+	}
+
+	path := strings.TrimPrefix(dsn, "file:")
+	path, rawQuery, _ := strings.Cut(path, "?")
+	if path == "" {
+		return nil, fmt.Errorf("joydb: missing base path in DSN %q", dsn)
+	}
+	query, err := url.ParseQuery(rawQuery)
+	if err != nil {
+		return nil, fmt.Errorf("joydb: invalid DSN options %q: %w", rawQuery, err)
+	}
+	storage := query.Get("storage")
+	switch storage {
+	case "":
+		storage = metadata.StorageJSON
+	case metadata.StorageJSON, metadata.StoragePage:
+	default:
+		return nil, fmt.Errorf("joydb: unknown storage format %q (want %s or %s)", storage, metadata.StorageJSON, metadata.StoragePage)
+	}
+	return &config{basePath: path, storage: storage, database: query.Get("database")}, nil
+}
+
+// connector implements driver.Connector and io.Closer
+type connector struct {
+	driver *Driver
+	cfg    *config
+
+	mu       sync.Mutex
+	registry *manager.Registry // shared registry held by this connector (embedded mode, after the first Connect)
+	closed   bool
+}
+
+var _ io.Closer = (*connector)(nil)
+
+// Connect opens a session and selects the DSN's database
+func (c *connector) Connect(ctx context.Context) (driver.Conn, error) {
+	var s session
+	var err error
+	if c.cfg.network {
+		s, err = dialSession(ctx, c.cfg.address)
+	} else {
+		var registry *manager.Registry
+		registry, err = c.embeddedRegistry()
+		if err == nil {
+			s = newEmbeddedSession(registry)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if c.cfg.database != "" {
+		if _, err := s.execute("USE " + c.cfg.database); err != nil {
+			s.close()
+			return nil, err
+		}
+	}
+	return &conn{session: s}, nil
+}
+
+func (c *connector) Driver() driver.Driver {
+	return c.driver
+}
+
+// embeddedRegistry returns the base path's shared registry, acquiring it on first use
+func (c *connector) embeddedRegistry() (*manager.Registry, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.closed {
+		return nil, fmt.Errorf("joydb: connector is closed")
+	}
+	if c.registry == nil {
+		registry, err := acquireRegistry(c.cfg.basePath, c.cfg.storage)
+		if err != nil {
+			return nil, err
+		}
+		c.registry = registry
+	}
+	return c.registry, nil
+}
+
+// Close releases the connector's shared registry (called by sql.DB.Close)
+// Closing the last connector of a base path writes its changed tables to disk.
+func (c *connector) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.closed = true
+	if c.registry == nil {
+		return nil
+	}
+	c.registry = nil
+	return releaseRegistry(c.cfg.basePath)
+}
+
+// Background checkpoints of embedded databases, as with the server's defaults
+const (
+	checkpointInterval  = 30 * time.Second
+	checkpointDirtyRows = 10000
+)
+
+// sharedBase is the registry of one base path, shared by every embedded connection to it
+type sharedBase struct {
+	registry *manager.Registry
+	storage  string // storage format the registry was opened with
+	refs     int    // connectors holding the registry
+}
+
+var (
+	registriesMu sync.Mutex
+	registries   = make(map[string]*sharedBase)
+)
+
+// acquireRegistry returns the process-wide registry for a base path, opening it
+// (and starting its checkpointer) for the first connector
+// A database must be loaded (and its write-ahead log opened) only once per process,
+// so every connection to the base path must ask for the same storage format.
+func acquireRegistry(basePath, storage string) (*manager.Registry, error) {
+	absPath, err := filepath.Abs(basePath)
+	if err != nil {
+		return nil, fmt.Errorf("joydb: invalid base path %q: %w", basePath, err)
+	}
+
+	registriesMu.Lock()
+	defer registriesMu.Unlock()
+
+	if shared, ok := registries[absPath]; ok {
+		if shared.storage != storage {
+			return nil, fmt.Errorf("joydb: %s is already open with %s storage, not %s", basePath, shared.storage, storage)
+		}
+		shared.refs++
+		return shared.registry, nil
+	}
+
+	engine, err := storageEngine.New(storage)
+	if err != nil {
+		return nil, fmt.Errorf("joydb: %w", err)
+	}
+	registry := manager.NewRegistry(absPath, engine)
+	if err := registry.StartCheckpointer(manager.CheckpointConfig{
+		Interval:  checkpointInterval,
+		DirtyRows: checkpointDirtyRows,
+	}); err != nil {
+		return nil, fmt.Errorf("joydb: %w", err)
+	}
+	registries[absPath] = &sharedBase{registry: registry, storage: storage, refs: 1}
+	return registry, nil
+}
+
+// releaseRegistry drops a connector's hold on a base path's registry
+// The last release stops the checkpointer, writes changed tables and closes the registry.
+func releaseRegistry(basePath string) error {
+	absPath, err := filepath.Abs(basePath)
+	if err != nil {
+		return fmt.Errorf("joydb: invalid base path %q: %w", basePath, err)
+	}
+
+	registriesMu.Lock()
+	defer registriesMu.Unlock()
+
+	shared, ok := registries[absPath]
+	if !ok {
+		return nil
+	}
+	shared.refs--
+	if shared.refs > 0 {
+		return nil
+	}
+	delete(registries, absPath)
+	if err := shared.registry.Close(); err != nil {
+		return fmt.Errorf("joydb: failed to save databases in %s: %w", basePath, err)
+	}
+	return nil
+}
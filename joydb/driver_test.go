@@ -0,0 +1,397 @@
+package joydb
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/leengari/mini-rdbms/internal/network"
+	storageEngine "github.com/leengari/mini-rdbms/internal/storage/engine"
+	"github.com/leengari/mini-rdbms/internal/storage/manager"
+	"github.com/leengari/mini-rdbms/internal/storage/wal"
+)
+
+func TestParseDSN(t *testing.T) {
+	tests := []struct {
+		dsn      string
+		expected config
+	}{
+		{"file:/data/dbs?database=main", config{basePath: "/data/dbs", storage: "json", database: "main"}},
+		{"./databases", config{basePath: "./databases", storage: "json"}},
+		{"file:/data/dbs?database=main&storage=page", config{basePath: "/data/dbs", storage: "page", database: "main"}},
+		{"tcp://localhost:4444/shop", config{network: true, address: "localhost:4444", database: "shop"}},
+		{"tcp://db.internal:4444", config{network: true, address: "db.internal:4444"}},
+	}
+	for _, tt := range tests {
+		cfg, err := parseDSN(tt.dsn)
+		if err != nil {
+			t.Errorf("%s: %v", tt.dsn, err)
+			continue
+		}
+		if *cfg != tt.expected {
+			t.Errorf("%s: expected %+v, got %+v", tt.dsn, tt.expected, *cfg)
+		}
+	}
+
+	for _, dsn := range []string{"", "file:", "tcp://", "file:x?%zz", "file:x?storage=xml"} {
+		if _, err := parseDSN(dsn); err == nil {
+			t.Errorf("Expected error for DSN %q", dsn)
+		}
+	}
+}
+
+func TestBindArgs(t *testing.T) {
+	arg := func(values ...driver.Value) []driver.NamedValue {
+		return namedValues(values)
+	}
+
+	tests := []struct {
+		query    string
+		args     []driver.NamedValue
+		expected string
+	}{
+		{"SELECT * FROM t WHERE id = ?", arg(int64(5)), "SELECT * FROM t WHERE id = 5"},
+		{"INSERT INTO t VALUES (?, ?, ?, ?)", arg("it's", 2.0, true, nil), "INSERT INTO t VALUES ('it''s', 2.0, true, NULL)"},
+		{"SELECT '?' FROM t WHERE a = ?", arg([]byte("x")), "SELECT '?' FROM t WHERE a = 'x'"},
+		{"SELECT 1", nil, "SELECT 1"},
+		{"INSERT INTO t VALUES (?, ?)", arg(time.Date(2024, 3, 9, 0, 0, 0, 0, time.UTC), time.Date(0, 1, 1, 14, 30, 5, 0, time.UTC)), "INSERT INTO t VALUES ('2024-03-09', '14:30:05')"},
+	}
+	for _, tt := range tests {
+		got, err := bindArgs(tt.query, tt.args)
+		if err != nil {
+			t.Errorf("%s: %v", tt.query, err)
+			continue
+		}
+		if got != tt.expected {
+			t.Errorf("%s: expected %q, got %q", tt.query, tt.expected, got)
+		}
+	}
+
+	if _, err := bindArgs("SELECT ?", arg(int64(1), int64(2))); err == nil {
+		t.Error("Expected error for extra arguments")
+	}
+	if _, err := bindArgs("SELECT ?, ?", arg(int64(1))); err == nil {
+		t.Error("Expected error for missing arguments")
+	}
+	if _, err := bindArgs("SELECT ?", []driver.NamedValue{{Name: "id", Ordinal: 1, Value: int64(1)}}); err == nil {
+		t.Error("Expected error for named arguments")
+	}
+	if _, err := bindArgs("SELECT ?", arg(time.Date(2024, 3, 9, 14, 30, 0, 0, time.UTC))); err == nil {
+		t.Error("Expected error for a time with both a date and a time of day")
+	}
+	if countPlaceholders("SELECT ? FROM t WHERE a = '?' AND b = ?") != 2 {
+		t.Error("Expected 2 placeholders")
+	}
+}
+
+// exerciseDriver runs the same checks against an embedded or network connection to a fresh 'shop' database
+func exerciseDriver(t *testing.T, db *sql.DB) {
+	t.Helper()
+
+	if err := db.Ping(); err != nil {
+		t.Fatalf("Ping failed: %v", err)
+	}
+	if _, err := db.Exec("CREATE TABLE items (id INT PRIMARY KEY AUTO_INCREMENT, name TEXT NOT NULL, price FLOAT, active BOOL)"); err != nil {
+		t.Fatalf("CREATE TABLE failed: %v", err)
+	}
+	if _, err := db.Exec("CREATE TABLE events (id INT PRIMARY KEY AUTO_INCREMENT, day DATE, at TIME)"); err != nil {
+		t.Fatalf("CREATE TABLE failed: %v", err)
+	}
+
+	for _, item := range []struct {
+		name  string
+		price float64
+	}{{"apple", 1.5}, {"pear's", 2}, {"plum", 3.25}} {
+		res, err := db.Exec("INSERT INTO items (name, price, active) VALUES (?, ?, ?)", item.name, item.price, true)
+		if err != nil {
+			t.Fatalf("INSERT failed: %v", err)
+		}
+		if n, _ := res.RowsAffected(); n != 1 {
+			t.Errorf("Expected 1 row affected, got %d", n)
+		}
+	}
+
+	// time.Time arguments bind as DATE and TIME literals
+	day := time.Date(2024, 3, 9, 0, 0, 0, 0, time.UTC)
+	at, _ := time.Parse("15:04:05", "14:30:05")
+	if _, err := db.Exec("INSERT INTO events (day, at) VALUES (?, ?)", day, at); err != nil {
+		t.Fatalf("INSERT time values failed: %v", err)
+	}
+	var events int
+	if err := db.QueryRow("SELECT COUNT(*) FROM events WHERE day = ? AND at = ?", day, at).Scan(&events); err != nil {
+		t.Fatalf("SELECT by time values failed: %v", err)
+	}
+	if events != 1 {
+		t.Errorf("Expected 1 event, got %d", events)
+	}
+
+	// Typed scanning and column metadata
+	rows, err := db.Query("SELECT id, name, price, active FROM items WHERE price > ? ORDER BY id", 1.75)
+	if err != nil {
+		t.Fatalf("Query failed: %v", err)
+	}
+	colTypes, err := rows.ColumnTypes()
+	if err != nil {
+		t.Fatalf("ColumnTypes failed: %v", err)
+	}
+	typeNames := ""
+	for _, ct := range colTypes {
+		typeNames += ct.DatabaseTypeName() + " "
+	}
+	if typeNames != "INT TEXT FLOAT BOOL " {
+		t.Errorf("Unexpected column types: %s", typeNames)
+	}
+
+	var got []string
+	for rows.Next() {
+		var id int64
+		var name string
+		var price float64
+		var active bool
+		if err := rows.Scan(&id, &name, &price, &active); err != nil {
+			t.Fatalf("Scan failed: %v", err)
+		}
+		got = append(got, fmt.Sprintf("%d:%s:%v:%v", id, name, price, active))
+	}
+	if err := rows.Err(); err != nil {
+		t.Fatalf("Rows error: %v", err)
+	}
+	rows.Close()
+	if fmt.Sprint(got) != "[2:pear's:2:true 3:plum:3.25:true]" {
+		t.Errorf("Unexpected rows: %v", got)
+	}
+
+	// Prepared statement reused with different arguments
+	stmt, err := db.Prepare("SELECT name FROM items WHERE id = ?")
+	if err != nil {
+		t.Fatalf("Prepare failed: %v", err)
+	}
+	defer stmt.Close()
+	for id, expected := range map[int]string{1: "apple", 3: "plum"} {
+		var name string
+		if err := stmt.QueryRow(id).Scan(&name); err != nil {
+			t.Fatalf("QueryRow(%d) failed: %v", id, err)
+		}
+		if name != expected {
+			t.Errorf("id %d: expected %s, got %s", id, expected, name)
+		}
+	}
+
+	// Rolled back transaction leaves no trace, committed one persists
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatalf("Begin failed: %v", err)
+	}
+	if _, err := tx.Exec("DELETE FROM items WHERE id = ?", 1); err != nil {
+		t.Fatalf("DELETE in tx failed: %v", err)
+	}
+	if err := tx.Rollback(); err != nil {
+		t.Fatalf("Rollback failed: %v", err)
+	}
+
+	tx, err = db.BeginTx(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("BeginTx failed: %v", err)
+	}
+	if _, err := tx.Exec("UPDATE items SET price = ? WHERE id = ?", 9.5, 2); err != nil {
+		t.Fatalf("UPDATE in tx failed: %v", err)
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatalf("Commit failed: %v", err)
+	}
+
+	var count int
+	if err := db.QueryRow("SELECT COUNT(*) FROM items").Scan(&count); err != nil {
+		t.Fatalf("COUNT failed: %v", err)
+	}
+	if count != 3 {
+		t.Errorf("Expected 3 items after rollback, got %d", count)
+	}
+	var price float64
+	if err := db.QueryRow("SELECT price FROM items WHERE id = 2").Scan(&price); err != nil {
+		t.Fatalf("SELECT price failed: %v", err)
+	}
+	if price != 9.5 {
+		t.Errorf("Expected committed price 9.5, got %v", price)
+	}
+
+	if _, err := db.BeginTx(context.Background(), &sql.TxOptions{Isolation: sql.LevelSerializable}); err == nil {
+		t.Error("Expected error for unsupported isolation level")
+	}
+
+	// Errors from the engine surface as query errors
+	if _, err := db.Query("SELECT * FROM missing"); err == nil {
+		t.Error("Expected error for missing table")
+	}
+}
+
+func TestEmbeddedDriver(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "joydb_driver_test")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	// A DSN without a database can create one
+	admin, err := sql.Open(DriverName, "file:"+tmpDir)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	defer admin.Close()
+	if _, err := admin.Exec("CREATE DATABASE shop"); err != nil {
+		t.Fatalf("CREATE DATABASE failed: %v", err)
+	}
+
+	db, err := sql.Open(DriverName, "file:"+tmpDir+"?database=shop")
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	defer db.Close()
+	db.SetMaxOpenConns(4)
+
+	exerciseDriver(t, db)
+
+	// Connections to the same base path share the loaded database
+	other, err := sql.Open(DriverName, tmpDir+"?database=shop")
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	defer other.Close()
+	var count int
+	if err := other.QueryRow("SELECT COUNT(*) FROM items").Scan(&count); err != nil {
+		t.Fatalf("COUNT failed: %v", err)
+	}
+	if count != 3 {
+		t.Errorf("Expected 3 items through a second sql.DB, got %d", count)
+	}
+
+	if bad, err := sql.Open(DriverName, "file:"+tmpDir+"?database=nope"); err == nil {
+		if err := bad.Ping(); err == nil {
+			t.Error("Expected error connecting to a missing database")
+		}
+		bad.Close()
+	}
+}
+
+func TestEmbeddedDriverClose(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "joydb_driver_close_test")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	db, err := sql.Open(DriverName, "file:"+tmpDir)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	db.SetMaxOpenConns(1) // keep the session that runs USE
+	for _, query := range []string{
+		"CREATE DATABASE shop",
+		"USE shop",
+		"CREATE TABLE items (id INT PRIMARY KEY, name TEXT)",
+		"INSERT INTO items (id, name) VALUES (1, 'apple')",
+	} {
+		if _, err := db.Exec(query); err != nil {
+			t.Fatalf("%s failed: %v", query, err)
+		}
+	}
+
+	walPath := filepath.Join(tmpDir, "shop", wal.FileName)
+	if info, err := os.Stat(walPath); err != nil || info.Size() == 0 {
+		t.Fatalf("Expected the INSERT in the write-ahead log (err %v)", err)
+	}
+
+	// Closing the last sql.DB for the base path checkpoints its databases
+	if err := db.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	if info, err := os.Stat(walPath); err != nil || info.Size() != 0 {
+		t.Errorf("Expected an empty write-ahead log after Close (err %v)", err)
+	}
+
+	reopened, err := sql.Open(DriverName, "file:"+tmpDir+"?database=shop")
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	defer reopened.Close()
+	var name string
+	if err := reopened.QueryRow("SELECT name FROM items WHERE id = 1").Scan(&name); err != nil {
+		t.Fatalf("SELECT after reopen failed: %v", err)
+	}
+	if name != "apple" {
+		t.Errorf("Expected apple, got %s", name)
+	}
+}
+
+func TestEmbeddedDriverPageStorage(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "joydb_driver_page_test")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	admin, err := sql.Open(DriverName, "file:"+tmpDir+"?storage=page")
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	defer admin.Close()
+	if _, err := admin.Exec("CREATE DATABASE shop"); err != nil {
+		t.Fatalf("CREATE DATABASE failed: %v", err)
+	}
+
+	db, err := sql.Open(DriverName, "file:"+tmpDir+"?database=shop&storage=page")
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	defer db.Close()
+
+	exerciseDriver(t, db)
+	if _, err := db.Exec("CHECKPOINT"); err != nil {
+		t.Fatalf("CHECKPOINT failed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(tmpDir, "shop", "items", "data.pages")); err != nil {
+		t.Errorf("Expected page-format table file: %v", err)
+	}
+
+	// The base path is open in page format; JSON connections to it are refused
+	jsonDB, err := sql.Open(DriverName, "file:"+tmpDir+"?database=shop")
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	defer jsonDB.Close()
+	if err := jsonDB.Ping(); err == nil {
+		t.Error("Expected error connecting with a different storage format")
+	}
+}
+
+func TestNetworkDriver(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "joydb_driver_net_test")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	registry := manager.NewRegistry(tmpDir, storageEngine.NewJSONEngine())
+	if err := registry.Create("shop"); err != nil {
+		t.Fatalf("Failed to create database: %v", err)
+	}
+
+	port := 54323
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go network.Start(ctx, port, registry)
+	time.Sleep(100 * time.Millisecond)
+
+	db, err := sql.Open(DriverName, fmt.Sprintf("tcp://localhost:%d/shop", port))
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	defer db.Close()
+
+	exerciseDriver(t, db)
+}
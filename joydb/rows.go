@@ -0,0 +1,107 @@
+package joydb
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"reflect"
+
+	"github.com/leengari/mini-rdbms/internal/domain/data"
+	"github.com/leengari/mini-rdbms/internal/domain/schema"
+	"github.com/leengari/mini-rdbms/internal/executor"
+)
+
+// rows implements driver.Rows over a fully materialized result
+type rows struct {
+	columns  []string
+	metadata []executor.ColumnMetadata
+	data     []data.Row
+	pos      int
+}
+
+var (
+	_ driver.RowsColumnTypeDatabaseTypeName = (*rows)(nil)
+	_ driver.RowsColumnTypeScanType         = (*rows)(nil)
+	_ driver.RowsColumnTypeNullable         = (*rows)(nil)
+)
+
+func newRows(res *executor.Result) *rows {
+	return &rows{columns: res.Columns, metadata: res.Metadata, data: res.Rows}
+}
+
+func (r *rows) Columns() []string {
+	return r.columns
+}
+
+func (r *rows) Close() error {
+	r.pos = len(r.data)
+	return nil
+}
+
+func (r *rows) Next(dest []driver.Value) error {
+	if r.pos >= len(r.data) {
+		return io.EOF
+	}
+	row := r.data[r.pos]
+	r.pos++
+
+	for i, name := range r.columns {
+		value, err := convertValue(row.Data[name], r.columnType(i))
+		if err != nil {
+			return fmt.Errorf("joydb: column %s: %w", name, err)
+		}
+		dest[i] = value
+	}
+	return nil
+}
+
+// ColumnTypeDatabaseTypeName returns the JoyDB type of a column, e.g. "INT"
+func (r *rows) ColumnTypeDatabaseTypeName(index int) string {
+	return string(r.columnType(index))
+}
+
+// ColumnTypeScanType returns the Go type values of a column are returned as
+func (r *rows) ColumnTypeScanType(index int) reflect.Type {
+	switch r.columnType(index) {
+	case schema.ColumnTypeInt:
+		return reflect.TypeOf(int64(0))
+	case schema.ColumnTypeFloat:
+		return reflect.TypeOf(float64(0))
+	case schema.ColumnTypeBool:
+		return reflect.TypeOf(false)
+	default:
+		return reflect.TypeOf("")
+	}
+}
+
+// ColumnTypeNullable reports that any column may hold NULL (e.g. from an outer JOIN)
+func (r *rows) ColumnTypeNullable(index int) (nullable, ok bool) {
+	return true, true
+}
+
+func (r *rows) columnType(index int) schema.ColumnType {
+	if index < len(r.metadata) {
+		return schema.ColumnType(r.metadata[index].Type)
+	}
+	return schema.ColumnTypeText
+}
+
+// convertValue converts a stored value to a driver.Value of the column's type
+// Numbers may arrive as float64 (JSON) or int/int64 (computed); INT columns are returned as int64.
+func convertValue(value interface{}, columnType schema.ColumnType) (driver.Value, error) {
+	switch v := value.(type) {
+	case nil:
+		return nil, nil
+	case bool, string, int64:
+		return v, nil
+	case int:
+		return int64(v), nil
+	case float64:
+		if columnType == schema.ColumnTypeInt && v == float64(int64(v)) {
+			return int64(v), nil
+		}
+		return v, nil
+	default:
+		return nil, fmt.Errorf("unsupported value type %T", value)
+	}
+}
@@ -0,0 +1,101 @@
+package joydb
+
+import (
+	"context"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+
+	"github.com/leengari/mini-rdbms/internal/engine"
+	"github.com/leengari/mini-rdbms/internal/executor"
+	"github.com/leengari/mini-rdbms/internal/network"
+	"github.com/leengari/mini-rdbms/internal/storage/manager"
+)
+
+// session runs SQL statements one at a time for a single connection
+type session interface {
+	execute(sql string) (*executor.Result, error)
+	close() error
+}
+
+// embeddedSession runs statements on an in-process engine
+type embeddedSession struct {
+	mu  sync.Mutex
+	eng *engine.Engine
+}
+
+func newEmbeddedSession(registry *manager.Registry) *embeddedSession {
+	return &embeddedSession{eng: engine.New(nil, registry)}
+}
+
+func (s *embeddedSession) execute(sql string) (*executor.Result, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.eng.Execute(sql)
+}
+
+// close rolls back any transaction left open
+func (s *embeddedSession) close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.eng.Close()
+}
+
+// networkSession sends statements to a JoyDB server over its JSON protocol
+type networkSession struct {
+	mu      sync.Mutex
+	conn    net.Conn
+	encoder *json.Encoder
+	decoder *json.Decoder
+	broken  bool // set after an I/O error; the connection is no longer usable
+}
+
+func dialSession(ctx context.Context, address string) (*networkSession, error) {
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", address)
+	if err != nil {
+		return nil, fmt.Errorf("joydb: failed to connect to %s: %w", address, err)
+	}
+	return &networkSession{
+		conn:    conn,
+		encoder: json.NewEncoder(conn),
+		decoder: json.NewDecoder(conn),
+	}, nil
+}
+
+func (s *networkSession) execute(sql string) (*executor.Result, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.broken {
+		return nil, driver.ErrBadConn
+	}
+	if err := s.encoder.Encode(network.Request{Query: sql}); err != nil {
+		s.broken = true
+		return nil, fmt.Errorf("joydb: failed to send query: %w", err)
+	}
+
+	var result executor.Result
+	if err := s.decoder.Decode(&result); err != nil {
+		s.broken = true
+		return nil, fmt.Errorf("joydb: failed to read response: %w", err)
+	}
+	if result.Error != "" {
+		return nil, errors.New(result.Error)
+	}
+	return &result, nil
+}
+
+func (s *networkSession) close() error {
+	return s.conn.Close()
+}
+
+// isValid reports whether the connection can still be used (driver.Validator)
+func (s *networkSession) isValid() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return !s.broken
+}